@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/firdanbash/go-clean-boiler/internal/scaffold"
+	"github.com/spf13/cobra"
+)
+
+func newGenCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "gen",
+		Short: "Generate code",
+		// No config or logger needed
+		PersistentPreRun:  func(cmd *cobra.Command, args []string) {},
+		PersistentPostRun: func(cmd *cobra.Command, args []string) {},
+	}
+	cmd.AddCommand(newGenResourceCmd())
+	return cmd
+}
+
+func newGenResourceCmd() *cobra.Command {
+	opts := scaffold.Options{}
+
+	cmd := &cobra.Command{
+		Use:   "resource <Name>",
+		Short: "Generate a CRUD resource and wire it into the server",
+		Long: `Generates the domain model, repository interface and Postgres implementation,
+service, DTOs, handler, routes and migrations of a new resource, and registers
+them in the dependency injection modules. Run it from the repository root.`,
+		Example: `  api gen resource Product --fields "name:string,price:float64,in_stock:bool"`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Name = args[0]
+			result, err := scaffold.Generate(opts)
+			if result != nil {
+				for _, path := range result.Created {
+					fmt.Fprintf(cmd.OutOrStdout(), "created %s\n", path)
+				}
+				for _, path := range result.Wired {
+					fmt.Fprintf(cmd.OutOrStdout(), "updated %s\n", path)
+				}
+			}
+			return err
+		},
+	}
+	cmd.Flags().StringVar(&opts.Fields, "fields", "name:string", "comma separated name:type pairs (string, text, int, int64, uint, float64, bool, time)")
+	cmd.Flags().StringVar(&opts.Plural, "plural", "", "plural name, for nouns not ending in a regular s")
+	cmd.Flags().StringVar(&opts.Dir, "dir", ".", "repository root")
+	cmd.Flags().BoolVar(&opts.Force, "force", false, "overwrite existing files")
+	return cmd
+}
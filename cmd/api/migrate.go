@@ -0,0 +1,105 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/firdanbash/go-clean-boiler/migrations"
+	"github.com/firdanbash/go-clean-boiler/pkg/database"
+	"github.com/spf13/cobra"
+)
+
+func newMigrateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Apply or roll back the versioned SQL migrations",
+		Long: "Apply or roll back the SQL migrations embedded in the binary (migrations/<driver>).\n" +
+			"SQLite has no SQL migrations; its schema is created by auto migration on serve.",
+	}
+
+	var all bool
+	var steps int
+	down := &cobra.Command{
+		Use:   "down",
+		Short: "Roll back the latest migration, or more with --steps/--all",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !all && steps < 1 {
+				return errors.New("--steps must be at least 1")
+			}
+			if all {
+				steps = 0
+			}
+			return withMigrator(func(m *database.Migrator) error {
+				if err := m.Down(steps); err != nil {
+					return err
+				}
+				return printVersion(cmd, m)
+			})
+		},
+	}
+	down.Flags().IntVar(&steps, "steps", 1, "number of migrations to roll back")
+	down.Flags().BoolVar(&all, "all", false, "roll back every migration")
+
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "up",
+			Short: "Apply all pending migrations",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return withMigrator(func(m *database.Migrator) error {
+					if err := m.Up(); err != nil {
+						return err
+					}
+					return printVersion(cmd, m)
+				})
+			},
+		},
+		down,
+		&cobra.Command{
+			Use:   "status",
+			Short: "List migrations and whether they are applied",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return withMigrator(func(m *database.Migrator) error {
+					status, err := m.Status()
+					if err != nil {
+						return err
+					}
+					for _, s := range status {
+						state := "pending"
+						if s.Applied {
+							state = "applied"
+						}
+						fmt.Fprintf(cmd.OutOrStdout(), "%06d  %-8s %s\n", s.Version, state, s.Name)
+					}
+					return printVersion(cmd, m)
+				})
+			},
+		},
+	)
+	return cmd
+}
+
+// withMigrator runs fn with a migrator for the configured database
+func withMigrator(fn func(m *database.Migrator) error) error {
+	m, err := database.NewMigrator(cfg.Database, migrations.FS)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+	return fn(m)
+}
+
+func printVersion(cmd *cobra.Command, m *database.Migrator) error {
+	version, dirty, err := m.Version()
+	if err != nil {
+		return err
+	}
+	if dirty {
+		fmt.Fprintf(cmd.OutOrStdout(), "schema version: %d (dirty, fix the failed migration and force the version)\n", version)
+		return nil
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "schema version: %d\n", version)
+	return nil
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/firdanbash/go-clean-boiler/pkg/authz"
+	"github.com/firdanbash/go-clean-boiler/pkg/database"
+	"github.com/spf13/cobra"
+)
+
+func newPolicyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "policy",
+		Short: "Manage the authorization policies checked by middleware.Authorize",
+		Long: "Manage the casbin policies stored in the casbin_rule table. Subjects are user:<id>\n" +
+			"and role:<built-in role>, or any name given to other subjects with assign.\n" +
+			"Running servers pick up changes within authorization.refresh_interval.",
+	}
+
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "list",
+			Short: "List the policies and role assignments",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return withEnforcer(func(enforcer *casbin.SyncedEnforcer) error {
+					policies, err := enforcer.GetPolicy()
+					if err != nil {
+						return err
+					}
+					groupings, err := enforcer.GetGroupingPolicy()
+					if err != nil {
+						return err
+					}
+					for _, p := range policies {
+						fmt.Fprintf(cmd.OutOrStdout(), "p, %s\n", strings.Join(p, ", "))
+					}
+					for _, g := range groupings {
+						fmt.Fprintf(cmd.OutOrStdout(), "g, %s\n", strings.Join(g, ", "))
+					}
+					return nil
+				})
+			},
+		},
+		&cobra.Command{
+			Use:   "add <subject> <object> <action>",
+			Short: "Allow a subject an action on an object",
+			Long: "Allow a subject an action on an object. The action * allows every action and an\n" +
+				"object ending in * every object with that prefix.",
+			Args: cobra.ExactArgs(3),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return withEnforcer(func(enforcer *casbin.SyncedEnforcer) error {
+					// Adding an existing policy reports a change, so check first
+					exists, err := enforcer.HasPolicy(args[0], args[1], args[2])
+					if err != nil {
+						return err
+					}
+					if exists {
+						return errors.New("policy already exists")
+					}
+					return report(cmd, "added", "policy already exists")(enforcer.AddPolicy(args[0], args[1], args[2]))
+				})
+			},
+		},
+		&cobra.Command{
+			Use:   "remove <subject> <object> <action>",
+			Short: "Remove a policy",
+			Args:  cobra.ExactArgs(3),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return withEnforcer(func(enforcer *casbin.SyncedEnforcer) error {
+					return report(cmd, "removed", "policy not found")(enforcer.RemovePolicy(args[0], args[1], args[2]))
+				})
+			},
+		},
+		&cobra.Command{
+			Use:   "assign <subject> <role>",
+			Short: "Give a subject the policies of a role, e.g. assign user:7 editor",
+			Args:  cobra.ExactArgs(2),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return withEnforcer(func(enforcer *casbin.SyncedEnforcer) error {
+					// Adding an existing policy reports a change, so check first
+					exists, err := enforcer.HasGroupingPolicy(args[0], args[1])
+					if err != nil {
+						return err
+					}
+					if exists {
+						return errors.New("role already assigned")
+					}
+					return report(cmd, "assigned", "role already assigned")(enforcer.AddGroupingPolicy(args[0], args[1]))
+				})
+			},
+		},
+		&cobra.Command{
+			Use:   "unassign <subject> <role>",
+			Short: "Take a role away from a subject",
+			Args:  cobra.ExactArgs(2),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return withEnforcer(func(enforcer *casbin.SyncedEnforcer) error {
+					return report(cmd, "unassigned", "role not assigned")(enforcer.RemoveGroupingPolicy(args[0], args[1]))
+				})
+			},
+		},
+	)
+	return cmd
+}
+
+// withEnforcer runs fn with the policy enforcer over the configured database
+func withEnforcer(fn func(enforcer *casbin.SyncedEnforcer) error) error {
+	db, err := openDatabase()
+	if err != nil {
+		return err
+	}
+	defer database.Close(db)
+
+	enforcer, err := authz.New(cfg.Authz, db)
+	if err != nil {
+		return err
+	}
+	return fn(enforcer)
+}
+
+// report prints done when a policy change had an effect and fails with
+// unchanged when it had none
+func report(cmd *cobra.Command, done, unchanged string) func(changed bool, err error) error {
+	return func(changed bool, err error) error {
+		if err != nil {
+			return err
+		}
+		if !changed {
+			return errors.New(unchanged)
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), done)
+		return nil
+	}
+}
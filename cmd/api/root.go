@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/firdanbash/go-clean-boiler/pkg/config"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"github.com/spf13/cobra"
+)
+
+// cfg is loaded by the root command before any subcommand runs
+var cfg *config.Config
+
+// newRootCmd builds the command tree. Without a subcommand the API server starts,
+// so existing deployments running the bare binary keep working.
+func newRootCmd() *cobra.Command {
+	var env string
+
+	root := &cobra.Command{
+		Use:          "go-clean-boiler",
+		Short:        "Clean Architecture REST API",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return bootstrap(env)
+		},
+		PersistentPostRun: func(cmd *cobra.Command, args []string) {
+			logger.Sync()
+		},
+		RunE: runServe,
+	}
+	root.PersistentFlags().StringVar(&env, "env", "", "environment profile to load, overrides APP_ENV")
+
+	root.AddCommand(
+		newServeCmd(),
+		newMigrateCmd(),
+		newSeedCmd(),
+		newTenantCmd(),
+		newPolicyCmd(),
+		newSearchCmd(),
+		newGenCmd(),
+		newVersionCmd(),
+	)
+	return root
+}
+
+// bootstrap loads and validates the configuration and initializes the logger
+func bootstrap(env string) error {
+	if env != "" {
+		if err := os.Setenv("APP_ENV", env); err != nil {
+			return err
+		}
+	}
+
+	var err error
+	cfg, err = config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	if err := logger.Init(cfg.Log.Level, cfg.Log.Encoding); err != nil {
+		return fmt.Errorf("failed to initialize logger: %w", err)
+	}
+	return nil
+}
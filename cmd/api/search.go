@@ -0,0 +1,58 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/firdanbash/go-clean-boiler/internal/repository/postgres"
+	"github.com/firdanbash/go-clean-boiler/internal/service"
+	"github.com/firdanbash/go-clean-boiler/pkg/database"
+	"github.com/firdanbash/go-clean-boiler/pkg/httpclient"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"github.com/firdanbash/go-clean-boiler/pkg/search"
+	"github.com/spf13/cobra"
+)
+
+func newSearchCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "search",
+		Short: "Manage the search indexes in Elasticsearch or OpenSearch",
+	}
+
+	var recreate bool
+	reindex := &cobra.Command{
+		Use:   "reindex",
+		Short: "Index every user",
+		Long: "Index every user of every tenant, once search is enabled or to catch up with\n" +
+			"changes missed while the cluster was unreachable. With --recreate the index is\n" +
+			"dropped first, applying a changed definition and dropping deleted users, but\n" +
+			"searches miss results until the reindex is done.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if cfg.Search.URL == "" {
+				return errors.New("search is not enabled, set search.url")
+			}
+			client, err := search.New(cfg.Search, httpclient.New(cfg.HTTPClient, logger.Default()))
+			if err != nil {
+				return err
+			}
+			db, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			defer database.Close(db)
+
+			searchService := service.NewSearchService(client, postgres.NewUserRepository(db), logger.Default())
+			indexed, err := searchService.ReindexUsers(cmd.Context(), recreate)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "indexed %d users\n", indexed)
+			return nil
+		},
+	}
+	reindex.Flags().BoolVar(&recreate, "recreate", false, "drop the index before indexing")
+
+	cmd.AddCommand(reindex)
+	return cmd
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+
+	"github.com/firdanbash/go-clean-boiler/internal/app"
+	"github.com/firdanbash/go-clean-boiler/internal/repository/postgres"
+	"github.com/firdanbash/go-clean-boiler/internal/seeder"
+	"github.com/firdanbash/go-clean-boiler/internal/service"
+	"github.com/firdanbash/go-clean-boiler/pkg/database"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"github.com/firdanbash/go-clean-boiler/pkg/reqctx"
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+)
+
+func newSeedCmd() *cobra.Command {
+	var email, password, name, tenant string
+
+	cmd := &cobra.Command{
+		Use:   "seed",
+		Short: "Create the initial admin account",
+		Long: "Create an admin account unless a user with the email already exists.\n" +
+			"Without --admin-password (or SEED_ADMIN_PASSWORD) a random password is generated and printed.\n" +
+			"With --tenant the admin belongs to that tenant, which must exist (see the tenant command).",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			generated := password == ""
+			if generated {
+				b := make([]byte, 12)
+				if _, err := rand.Read(b); err != nil {
+					return err
+				}
+				password = hex.EncodeToString(b)
+			}
+
+			db, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			defer database.Close(db)
+
+			ctx := cmd.Context()
+			if tenant != "" {
+				tenantID, err := service.NewTenantService(postgres.NewTenantRepository(db)).Resolve(ctx, tenant)
+				if err != nil {
+					return fmt.Errorf("tenant %s: %w", tenant, err)
+				}
+				ctx = reqctx.WithTenantID(ctx, tenantID)
+			}
+
+			hasher, err := app.NewPasswordHasher(cfg)
+			if err != nil {
+				return err
+			}
+			created, err := seeder.New(postgres.NewUserRepository(db), hasher).Admin(ctx, email, password, name)
+			if err != nil {
+				return err
+			}
+			if !created {
+				fmt.Fprintf(cmd.OutOrStdout(), "admin %s already exists, nothing to do\n", email)
+				return nil
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "created admin %s\n", email)
+			if generated {
+				fmt.Fprintf(cmd.OutOrStdout(), "generated password: %s\n", password)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&email, "admin-email", envOr("SEED_ADMIN_EMAIL", "admin@example.com"), "admin email")
+	cmd.Flags().StringVar(&password, "admin-password", os.Getenv("SEED_ADMIN_PASSWORD"), "admin password, generated when empty")
+	cmd.Flags().StringVar(&name, "admin-name", envOr("SEED_ADMIN_NAME", "Administrator"), "admin display name")
+	cmd.Flags().StringVar(&tenant, "tenant", os.Getenv("SEED_TENANT"), "slug of the tenant the admin belongs to")
+	return cmd
+}
+
+// openDatabase connects to the database, brings its schema up to date and
+// scopes statements to the tenant of their context, as the server does
+func openDatabase() (*gorm.DB, error) {
+	db, err := database.New(cfg, logger.Default())
+	if err != nil {
+		return nil, err
+	}
+	if err := app.AutoMigrate(db); err != nil {
+		database.Close(db)
+		return nil, fmt.Errorf("failed to run migrations: %w", err)
+	}
+	if err := postgres.RegisterTenantScope(db); err != nil {
+		database.Close(db)
+		return nil, err
+	}
+	if err := postgres.RegisterAuditable(db); err != nil {
+		database.Close(db)
+		return nil, err
+	}
+	return db, nil
+}
+
+// envOr returns the environment variable, or fallback when it is unset
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
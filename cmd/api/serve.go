@@ -0,0 +1,24 @@
+package main
+
+import (
+	"github.com/firdanbash/go-clean-boiler/internal/app"
+	"github.com/spf13/cobra"
+)
+
+func newServeCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "serve",
+		Short: "Start the HTTP API server",
+		Args:  cobra.NoArgs,
+		RunE:  runServe,
+	}
+}
+
+// runServe starts the API and blocks until it is shut down by a signal
+func runServe(cmd *cobra.Command, args []string) error {
+	a, err := app.New(cfg)
+	if err != nil {
+		return err
+	}
+	return a.Run(cmd.Context())
+}
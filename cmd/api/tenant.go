@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/firdanbash/go-clean-boiler/internal/repository/postgres"
+	"github.com/firdanbash/go-clean-boiler/internal/service"
+	"github.com/firdanbash/go-clean-boiler/pkg/database"
+	"github.com/spf13/cobra"
+)
+
+func newTenantCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tenant",
+		Short: "Create and list the tenants served with tenancy enabled",
+	}
+
+	var name string
+	create := &cobra.Command{
+		Use:   "create <slug>",
+		Short: "Create a tenant",
+		Long: "Create a tenant. The slug names it in the tenant header and as the subdomain of\n" +
+			"tenancy.base_domain, so it is made of lowercase letters, digits and hyphens.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withTenants(func(tenants service.TenantService) error {
+				tenant, err := tenants.Create(cmd.Context(), args[0], name)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "created tenant %s (id %d)\n", tenant.Slug, tenant.ID)
+				return nil
+			})
+		},
+	}
+	create.Flags().StringVar(&name, "name", "", "display name, the slug when empty")
+
+	cmd.AddCommand(
+		create,
+		&cobra.Command{
+			Use:   "list",
+			Short: "List the tenants",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return withTenants(func(tenants service.TenantService) error {
+					list, err := tenants.List(cmd.Context())
+					if err != nil {
+						return err
+					}
+					for _, tenant := range list {
+						fmt.Fprintf(cmd.OutOrStdout(), "%6d  %-20s %s\n", tenant.ID, tenant.Slug, tenant.Name)
+					}
+					return nil
+				})
+			},
+		},
+	)
+	return cmd
+}
+
+// withTenants runs fn with the tenant service over the configured database
+func withTenants(fn func(tenants service.TenantService) error) error {
+	db, err := openDatabase()
+	if err != nil {
+		return err
+	}
+	defer database.Close(db)
+	return fn(service.NewTenantService(postgres.NewTenantRepository(db)))
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/firdanbash/go-clean-boiler/pkg/version"
+	"github.com/spf13/cobra"
+)
+
+func newVersionCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "version",
+		Short: "Print build information",
+		Args:  cobra.NoArgs,
+		// No config or logger needed
+		PersistentPreRun:  func(cmd *cobra.Command, args []string) {},
+		PersistentPostRun: func(cmd *cobra.Command, args []string) {},
+		Run: func(cmd *cobra.Command, args []string) {
+			info := version.Get()
+			fmt.Fprintf(cmd.OutOrStdout(), "version:    %s\ncommit:     %s\nbuilt:      %s\ngo version: %s %s/%s\n",
+				info.Version, info.Commit, info.BuildTime, info.GoVersion, info.OS, info.Arch)
+		},
+	}
+}
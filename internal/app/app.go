@@ -0,0 +1,187 @@
+// Package app assembles the API from its modules. An App owns its configuration,
+// logger, database connection and router, so several instances can run in one
+// process and tests can build one without touching package state.
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/firdanbash/go-clean-boiler/internal/event"
+	"github.com/firdanbash/go-clean-boiler/internal/graph"
+	"github.com/firdanbash/go-clean-boiler/internal/handler"
+	"github.com/firdanbash/go-clean-boiler/internal/job"
+	"github.com/firdanbash/go-clean-boiler/internal/notification"
+	"github.com/firdanbash/go-clean-boiler/internal/outbox"
+	"github.com/firdanbash/go-clean-boiler/internal/repository/postgres"
+	"github.com/firdanbash/go-clean-boiler/internal/router"
+	"github.com/firdanbash/go-clean-boiler/internal/rpc"
+	"github.com/firdanbash/go-clean-boiler/internal/service"
+	"github.com/firdanbash/go-clean-boiler/internal/webhook"
+	"github.com/firdanbash/go-clean-boiler/internal/ws"
+	"github.com/firdanbash/go-clean-boiler/pkg/config"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"github.com/firdanbash/go-clean-boiler/pkg/version"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// App is a configured instance of the API
+type App struct {
+	cfg    *config.Config
+	log    logger.Logger
+	db     *gorm.DB
+	router *gin.Engine
+	fx     *fx.App
+}
+
+type options struct {
+	logger      logger.Logger
+	autoMigrate bool
+	fxOptions   []fx.Option
+}
+
+// Option customizes New
+type Option func(*options)
+
+// WithLogger sets the logger of the app instead of the default logger
+func WithLogger(l logger.Logger) Option {
+	return func(o *options) { o.logger = l }
+}
+
+// WithoutAutoMigrate skips auto migration, for databases migrated with SQL migrations
+func WithoutAutoMigrate() Option {
+	return func(o *options) { o.autoMigrate = false }
+}
+
+// WithFxOptions adds options to the dependency graph, e.g. fx.Decorate to
+// replace a dependency in tests
+func WithFxOptions(opts ...fx.Option) Option {
+	return func(o *options) { o.fxOptions = append(o.fxOptions, opts...) }
+}
+
+// New builds the application: it connects to the database, runs auto migration
+// and builds the router. Nothing listens until Run is called.
+func New(cfg *config.Config, opts ...Option) (*App, error) {
+	o := options{logger: logger.Default(), autoMigrate: true}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	a := &App{cfg: cfg, log: o.logger}
+
+	invokes := []interface{}{initTracing, configureValidator}
+	if o.autoMigrate {
+		invokes = append(invokes, migrateModels)
+	}
+	invokes = append(invokes, watchConfig, startServer)
+	if cfg.GRPC.Enabled {
+		invokes = append(invokes, startGRPCServer)
+	}
+	if cfg.Outbox.Enabled {
+		invokes = append(invokes, startOutboxRelay)
+	}
+	if cfg.Scheduler.Enabled {
+		invokes = append(invokes, startScheduler)
+	}
+
+	a.fx = fx.New(
+		fx.Supply(cfg, fx.Annotate(a.log, fx.As(new(logger.Logger)))),
+		fx.WithLogger(newFxLogger),
+		fx.StopTimeout(cfg.Server.ShutdownTimeout),
+
+		infraModule,
+		postgres.Module,
+		event.Module,
+		notification.Module,
+		service.Module,
+		handler.Module,
+		graph.Module,
+		ws.Module,
+		webhook.Module,
+		router.Module,
+		rpc.Module,
+		outbox.Module,
+		job.Module,
+
+		fx.Options(o.fxOptions...),
+		fx.Invoke(invokes...),
+		fx.Populate(&a.db, &a.router),
+	)
+	if err := a.fx.Err(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Config returns the configuration of the app
+func (a *App) Config() *config.Config {
+	return a.cfg
+}
+
+// Logger returns the logger of the app
+func (a *App) Logger() logger.Logger {
+	return a.log
+}
+
+// DB returns the database connection of the app
+func (a *App) DB() *gorm.DB {
+	return a.db
+}
+
+// Router returns the HTTP handler of the app, e.g. to serve it with httptest
+func (a *App) Router() *gin.Engine {
+	return a.router
+}
+
+// Run starts the HTTP server and blocks until ctx is cancelled, a shutdown signal
+// is received or the server fails, then shuts the app down
+func (a *App) Run(ctx context.Context) error {
+	build := version.Get()
+	a.log.Info("Starting application",
+		zap.String("app", a.cfg.App.Name),
+		zap.String("env", a.cfg.App.Env),
+		zap.String("version", build.Version),
+		zap.String("commit", build.Commit),
+		zap.String("build_time", build.BuildTime),
+		zap.String("go_version", build.GoVersion),
+		zap.String("platform", build.OS+"/"+build.Arch),
+	)
+
+	startCtx, cancel := context.WithTimeout(ctx, fx.DefaultTimeout)
+	defer cancel()
+	if err := a.fx.Start(startCtx); err != nil {
+		return err
+	}
+
+	// Wait for cancellation, an interrupt signal or a server failure
+	exitCode := 0
+	select {
+	case <-ctx.Done():
+		a.log.Info("Shutdown requested")
+	case sig := <-a.fx.Wait():
+		a.log.Info("Shutdown signal received", zap.String("signal", sig.String()))
+		exitCode = sig.ExitCode
+	}
+
+	// Drain in-flight requests before closing resources
+	stopCtx, cancel := context.WithTimeout(context.Background(), a.cfg.Server.ShutdownTimeout)
+	defer cancel()
+	if err := a.Shutdown(stopCtx); err != nil {
+		a.log.Error("Failed to stop cleanly", zap.Error(err))
+	}
+
+	a.log.Info("Server stopped")
+	if exitCode != 0 {
+		return fmt.Errorf("server exited with code %d", exitCode)
+	}
+	return nil
+}
+
+// Shutdown stops the HTTP server and closes the database and other connections.
+// It also releases an app that was built but never run.
+func (a *App) Shutdown(ctx context.Context) error {
+	return a.fx.Stop(ctx)
+}
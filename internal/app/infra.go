@@ -0,0 +1,532 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/firdanbash/go-clean-boiler/docs"
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/middleware"
+	"github.com/firdanbash/go-clean-boiler/internal/outbox"
+	"github.com/firdanbash/go-clean-boiler/internal/repository"
+	"github.com/firdanbash/go-clean-boiler/internal/repository/cached"
+	"github.com/firdanbash/go-clean-boiler/internal/service"
+	"github.com/firdanbash/go-clean-boiler/pkg/authz"
+	"github.com/firdanbash/go-clean-boiler/pkg/bruteforce"
+	"github.com/firdanbash/go-clean-boiler/pkg/cache"
+	"github.com/firdanbash/go-clean-boiler/pkg/config"
+	"github.com/firdanbash/go-clean-boiler/pkg/database"
+	"github.com/firdanbash/go-clean-boiler/pkg/featureflag"
+	"github.com/firdanbash/go-clean-boiler/pkg/health"
+	"github.com/firdanbash/go-clean-boiler/pkg/httpclient"
+	"github.com/firdanbash/go-clean-boiler/pkg/i18n"
+	"github.com/firdanbash/go-clean-boiler/pkg/jwt"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"github.com/firdanbash/go-clean-boiler/pkg/mailer"
+	"github.com/firdanbash/go-clean-boiler/pkg/messaging"
+	"github.com/firdanbash/go-clean-boiler/pkg/password"
+	"github.com/firdanbash/go-clean-boiler/pkg/ratelimit"
+	"github.com/firdanbash/go-clean-boiler/pkg/scheduler"
+	"github.com/firdanbash/go-clean-boiler/pkg/search"
+	"github.com/firdanbash/go-clean-boiler/pkg/server"
+	"github.com/firdanbash/go-clean-boiler/pkg/storage"
+	"github.com/firdanbash/go-clean-boiler/pkg/tracing"
+	"github.com/firdanbash/go-clean-boiler/pkg/validator"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxevent"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"gorm.io/gorm"
+)
+
+// infraModule provides the infrastructure the application layers depend on and
+// ties its startup and shutdown to the application lifecycle
+var infraModule = fx.Module("infra",
+	fx.Provide(
+		newDatabase,
+		newRedisClient,
+		newMailer,
+		newMailTemplates,
+		newMessagePublisher,
+		newI18nBundle,
+		newStorage,
+		newHTTPClient,
+		newSearchEngine,
+		newFeatureFlags,
+		newEnforcer,
+		newJWTManager,
+		newHealthChecker,
+		newRateLimiter,
+		newResponseCache,
+		newCacheInvalidator,
+		newLoginGuard,
+		newMFAAttempts,
+		NewPasswordHasher,
+		newOpenAPIValidator,
+		newDenylist,
+	),
+	fx.Decorate(cacheUserRepository),
+)
+
+// newFxLogger logs dependency injection events at debug level only
+func newFxLogger(cfg *config.Config, log logger.Logger) fxevent.Logger {
+	zl, ok := logger.Zap(log)
+	if cfg.Log.Level != "debug" || !ok {
+		return fxevent.NopLogger
+	}
+	return &fxevent.ZapLogger{Logger: zl.Named("fx")}
+}
+
+// initTracing installs the tracer provider and flushes pending spans on stop
+func initTracing(lc fx.Lifecycle, cfg *config.Config) error {
+	shutdown, err := tracing.Init(cfg.Tracing, cfg.App.Name, cfg.App.Env)
+	if err != nil {
+		return fmt.Errorf("failed to initialize tracing: %w", err)
+	}
+	lc.Append(fx.Hook{OnStop: shutdown})
+	return nil
+}
+
+// configureValidator applies the configured password policy to the "password"
+// validation tag
+func configureValidator(cfg *config.Config) {
+	p := cfg.Auth.PasswordPolicy
+	validator.SetPasswordPolicy(validator.PasswordPolicy{
+		MinLength:     p.MinLength,
+		RequireUpper:  p.RequireUpper,
+		RequireLower:  p.RequireLower,
+		RequireDigit:  p.RequireDigit,
+		RequireSymbol: p.RequireSymbol,
+	})
+}
+
+func newDatabase(lc fx.Lifecycle, cfg *config.Config, log logger.Logger) (*gorm.DB, error) {
+	db, err := database.New(cfg, log)
+	if err != nil {
+		return nil, err
+	}
+	lc.Append(fx.Hook{OnStop: func(context.Context) error {
+		return database.Close(db)
+	}})
+	return db, nil
+}
+
+// newRedisClient connects to Redis, or returns nil when it is not configured
+func newRedisClient(lc fx.Lifecycle, cfg *config.Config, log logger.Logger) (*redis.Client, error) {
+	if cfg.Redis.Addr == "" {
+		return nil, nil
+	}
+	client, err := cache.NewRedisClient(cfg.Redis)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+	}
+	lc.Append(fx.Hook{OnStop: func(context.Context) error {
+		return client.Close()
+	}})
+	log.Info("Redis connected successfully", zap.String("addr", cfg.Redis.Addr))
+	return client, nil
+}
+
+func newMailer(cfg *config.Config, log logger.Logger) (mailer.Mailer, error) {
+	return mailer.New(cfg.Mail, log)
+}
+
+// newMailTemplates parses the email templates, signed with the app name
+func newMailTemplates(cfg *config.Config) (*mailer.Templates, error) {
+	return mailer.NewTemplates(cfg.App.Name)
+}
+
+// newMessagePublisher connects to the configured message broker and closes the
+// connection on shutdown
+func newMessagePublisher(lc fx.Lifecycle, cfg *config.Config, log logger.Logger) (messaging.Publisher, error) {
+	publisher, err := messaging.NewPublisher(cfg.Messaging, log)
+	if err != nil {
+		return nil, err
+	}
+	lc.Append(fx.Hook{OnStop: func(context.Context) error {
+		return publisher.Close()
+	}})
+	return publisher, nil
+}
+
+// newI18nBundle loads the built-in translations and any overrides from i18n.dir
+func newI18nBundle(cfg *config.Config) (*i18n.Bundle, error) {
+	bundle, err := i18n.Load(cfg.I18n.DefaultLocale, cfg.I18n.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load translations: %w", err)
+	}
+	return bundle, nil
+}
+
+func newStorage(cfg *config.Config) (storage.Storage, error) {
+	return storage.New(cfg.Storage)
+}
+
+// newHTTPClient creates the client services use to call external APIs
+func newHTTPClient(cfg *config.Config, log logger.Logger) *http.Client {
+	return httpclient.New(cfg.HTTPClient, log)
+}
+
+// newSearchEngine creates the client of the search cluster, or returns nil when
+// search is not configured
+func newSearchEngine(cfg *config.Config, httpClient *http.Client) (service.SearchEngine, error) {
+	if cfg.Search.URL == "" {
+		return nil, nil
+	}
+	client, err := search.New(cfg.Search, httpClient)
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// newFeatureFlags creates the feature flags, kept in the configured store and
+// refreshed in the background while the app runs
+func newFeatureFlags(
+	lc fx.Lifecycle,
+	cfg *config.Config,
+	repo repository.FeatureFlagRepository,
+	redisClient *redis.Client,
+	log logger.Logger,
+) *featureflag.Flags {
+	var store featureflag.Store
+	switch cfg.FeatureFlags.Store {
+	case "database":
+		store = featureFlagStore{repo: repo}
+	case "redis":
+		store = featureflag.NewRedisStore(redisClient, cfg.Cache.KeyPrefix+"feature_flags")
+	}
+
+	flags := featureflag.New(cfg.FeatureFlags, store, log)
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			flags.Start(ctx)
+			return nil
+		},
+		OnStop: flags.Stop,
+	})
+	return flags
+}
+
+// featureFlagStore keeps feature flags in the feature_flags table
+type featureFlagStore struct {
+	repo repository.FeatureFlagRepository
+}
+
+func (s featureFlagStore) Load(ctx context.Context) (map[string]bool, error) {
+	stored, err := s.repo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	flags := make(map[string]bool, len(stored))
+	for _, flag := range stored {
+		flags[flag.Name] = flag.Enabled
+	}
+	return flags, nil
+}
+
+func (s featureFlagStore) Save(ctx context.Context, name string, enabled bool) error {
+	return s.repo.Upsert(ctx, &domain.FeatureFlag{Name: name, Enabled: enabled})
+}
+
+func newJWTManager(cfg *config.Config) (*jwt.Manager, error) {
+	return jwt.NewManager(cfg.JWT)
+}
+
+// newEnforcer creates the casbin enforcer of the authorization policies, which
+// reloads them from the database in the background while the app runs
+func newEnforcer(lc fx.Lifecycle, cfg *config.Config, db *gorm.DB) (*casbin.SyncedEnforcer, error) {
+	enforcer, err := authz.New(cfg.Authz, db)
+	if err != nil {
+		return nil, err
+	}
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			enforcer.StartAutoLoadPolicy(cfg.Authz.RefreshInterval)
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			enforcer.StopAutoLoadPolicy()
+			return nil
+		},
+	})
+	return enforcer, nil
+}
+
+// newDenylist exposes the revoked token repository to the auth middleware
+func newDenylist(repo repository.RevokedTokenRepository) jwt.Denylist {
+	return repo
+}
+
+// newHealthChecker registers the readiness checks of the configured backends
+func newHealthChecker(cfg *config.Config, db *gorm.DB, redisClient *redis.Client, searchEngine service.SearchEngine) *health.Checker {
+	checker := health.NewChecker(cfg.Server.HealthCheckTimeout)
+	checker.Register("database", func(ctx context.Context) error {
+		return database.Ping(ctx, db)
+	})
+	// SQLite keeps a single connection, so queries waiting for it is normal
+	if cfg.Database.PoolSaturationThreshold > 0 && cfg.Database.Driver != database.DriverSQLite {
+		checker.Register("database_pool", database.CheckPool(db, cfg.Database.PoolSaturationThreshold))
+	}
+	if redisClient != nil {
+		checker.Register("redis", func(ctx context.Context) error {
+			return redisClient.Ping(ctx).Err()
+		})
+	}
+	if searchEngine != nil {
+		checker.Register("search", searchEngine.Ping)
+	}
+	return checker
+}
+
+// newRateLimiter shares limits across replicas through Redis, or keeps them per
+// instance without it
+func newRateLimiter(cfg *config.Config, redisClient *redis.Client, log logger.Logger) (*middleware.RateLimiter, error) {
+	var limiter ratelimit.Limiter
+	if cfg.RateLimit.Enabled {
+		if redisClient != nil {
+			limiter = ratelimit.NewRedisLimiter(redisClient, cfg.Cache.KeyPrefix+"ratelimit:")
+		} else {
+			limiter = ratelimit.NewMemoryLimiter()
+		}
+	}
+	return middleware.NewRateLimiter(limiter, cfg.RateLimit.Policies, log)
+}
+
+// newResponseCache caches the responses of the routes opting in, in memory or
+// in Redis to share them between replicas. It caches nothing when disabled.
+func newResponseCache(cfg *config.Config, redisClient *redis.Client, log logger.Logger) *middleware.ResponseCache {
+	responses := cfg.Cache.Responses
+	var store cache.Cache
+	if responses.Enabled {
+		if responses.Store == "redis" {
+			store = cache.NewRedisCache(redisClient, cfg.Cache.KeyPrefix)
+		} else {
+			store = cache.NewMemoryCache()
+		}
+	}
+	return middleware.NewResponseCache(store, responses.TTL, log)
+}
+
+// newCacheInvalidator lets the services drop the cached responses they change
+func newCacheInvalidator(responses *middleware.ResponseCache) service.CacheInvalidator {
+	return responses
+}
+
+// NewPasswordHasher hashes passwords with the configured algorithm and cost,
+// and pepper when one is configured.
+// It is exported for the commands creating users outside the app.
+func NewPasswordHasher(cfg *config.Config) (password.Hasher, error) {
+	hash := cfg.Auth.PasswordHash
+	hasher, err := password.New(hash.Algorithm, hash.BcryptCost, password.Argon2idParams{
+		Memory:      uint32(hash.Argon2id.Memory),
+		Iterations:  uint32(hash.Argon2id.Iterations),
+		Parallelism: uint8(hash.Argon2id.Parallelism),
+		SaltLength:  uint32(hash.Argon2id.SaltLength),
+		KeyLength:   uint32(hash.Argon2id.KeyLength),
+	})
+	if err != nil || len(hash.Peppers) == 0 {
+		return hasher, err
+	}
+
+	peppers := make(map[string][]byte, len(hash.Peppers))
+	for version, pepper := range hash.Peppers {
+		peppers[version] = []byte(pepper)
+	}
+	return password.WithPepper(hasher, peppers, hash.PepperVersion)
+}
+
+// newMFAAttempts counts the codes rejected per MFA challenge, in Redis to
+// share the count across instances or in memory without it
+func newMFAAttempts(cfg *config.Config, redisClient *redis.Client) service.MFAAttempts {
+	var store bruteforce.Store
+	if redisClient != nil {
+		store = bruteforce.NewRedisStore(redisClient, cfg.Cache.KeyPrefix+"mfa:")
+	} else {
+		store = bruteforce.NewMemoryStore()
+	}
+	return bruteforce.NewCounter(store, cfg.Auth.MFAChallengeExpiration)
+}
+
+// newLoginGuard throttles failed logins per account, counting them in Redis to
+// share them between replicas, or per instance without it
+func newLoginGuard(cfg *config.Config, redisClient *redis.Client) service.LoginGuard {
+	bf := cfg.Auth.BruteForce
+	var store bruteforce.Store
+	if bf.Enabled {
+		if redisClient != nil {
+			store = bruteforce.NewRedisStore(redisClient, cfg.Cache.KeyPrefix+"login:")
+		} else {
+			store = bruteforce.NewMemoryStore()
+		}
+	}
+	return bruteforce.NewGuard(store, bruteforce.Policy{
+		FreeAttempts: bf.FreeAttempts,
+		BaseDelay:    bf.BaseDelay,
+		MaxDelay:     bf.MaxDelay,
+		Window:       bf.Window,
+		CaptchaAfter: bf.CaptchaAfter,
+	})
+}
+
+// cacheUserRepository serves user lookups from Redis, when enabled
+func cacheUserRepository(repo repository.UserRepository, redisClient *redis.Client, cfg *config.Config, log logger.Logger) repository.UserRepository {
+	if !cfg.Cache.Users.Enabled {
+		return repo
+	}
+	if redisClient == nil {
+		log.Warn("User cache is enabled but Redis is not configured, skipping")
+		return repo
+	}
+	return cached.NewUserRepository(repo, cache.NewRedisCache(redisClient, cfg.Cache.KeyPrefix), cfg.Cache.Users.TTL, log)
+}
+
+// newOpenAPIValidator checks traffic against the generated spec when enabled,
+// otherwise it returns nil. Responses are never checked in production.
+func newOpenAPIValidator(cfg *config.Config, log logger.Logger) (*middleware.OpenAPIValidator, error) {
+	openapi := cfg.OpenAPI
+	if cfg.App.Env == "production" {
+		openapi.ValidateResponses = false
+	}
+	if !openapi.ValidateRequests && !openapi.ValidateResponses {
+		return nil, nil
+	}
+	return middleware.NewOpenAPIValidator([]byte(docs.SwaggerInfo.ReadDoc()), openapi, log)
+}
+
+// migrateModels creates or updates the tables before the server starts
+func migrateModels(db *gorm.DB, log logger.Logger) error {
+	if err := AutoMigrate(db); err != nil {
+		return fmt.Errorf("failed to run migrations: %w", err)
+	}
+	log.Info("Database migrations completed successfully")
+	return nil
+}
+
+// watchConfig applies tunable settings when the config file changes
+func watchConfig(cfg *config.Config, rateLimiter *middleware.RateLimiter, log logger.Logger) {
+	if !cfg.App.WatchConfig {
+		return
+	}
+
+	config.OnChange(func(old, next *config.Config) {
+		if next.Log.Level != old.Log.Level {
+			if err := logger.SetLevel(next.Log.Level); err != nil {
+				log.Error("Invalid log level in reloaded config", zap.String("level", next.Log.Level), zap.Error(err))
+			} else {
+				log.Info("Log level changed", zap.String("level", next.Log.Level))
+			}
+		}
+
+		policies := next.RateLimit.Policies
+		if !next.RateLimit.Enabled {
+			policies = nil
+		}
+		if err := rateLimiter.Update(policies); err != nil {
+			log.Error("Invalid rate limit policies in reloaded config", zap.Error(err))
+		}
+	})
+	config.Watch(cfg)
+}
+
+// startServer serves the router once the application has started and drains
+// in-flight requests when it stops. A server failure stops the application.
+func startServer(lc fx.Lifecycle, shutdowner fx.Shutdowner, r *gin.Engine, cfg *config.Config, log logger.Logger) error {
+	srv, err := server.New(r, cfg.App.Port, cfg.Server)
+	if err != nil {
+		return fmt.Errorf("failed to configure server: %w", err)
+	}
+
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go func() {
+				log.Info("Server starting", zap.String("address", srv.Addr()), zap.Bool("tls", srv.TLS()))
+				if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					log.Error("Server failed", zap.Error(err))
+					_ = shutdowner.Shutdown(fx.ExitCode(1))
+				}
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			if err := srv.Shutdown(ctx); err != nil {
+				log.Error("Server forced to shutdown", zap.Error(err))
+			}
+			return nil
+		},
+	})
+	return nil
+}
+
+func startGRPCServer(lc fx.Lifecycle, shutdowner fx.Shutdowner, srv *grpc.Server, cfg *config.Config, log logger.Logger) {
+	addr := ":" + cfg.GRPC.Port
+
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			lis, err := net.Listen("tcp", addr)
+			if err != nil {
+				return fmt.Errorf("failed to listen for gRPC on %s: %w", addr, err)
+			}
+			go func() {
+				log.Info("gRPC server starting", zap.String("address", addr))
+				if err := srv.Serve(lis); err != nil {
+					log.Error("gRPC server failed", zap.Error(err))
+					_ = shutdowner.Shutdown(fx.ExitCode(1))
+				}
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			stopped := make(chan struct{})
+			go func() {
+				srv.GracefulStop()
+				close(stopped)
+			}()
+			select {
+			case <-stopped:
+			case <-ctx.Done():
+				log.Error("gRPC server forced to shutdown", zap.Error(ctx.Err()))
+				srv.Stop()
+			}
+			return nil
+		},
+	})
+}
+
+func startScheduler(lc fx.Lifecycle, s *scheduler.Scheduler, log logger.Logger) {
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			log.Info("Scheduler starting", zap.Int("jobs", s.Len()))
+			s.Start()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			if err := s.Stop(ctx); err != nil {
+				log.Error("Scheduler stopped before jobs finished", zap.Error(err))
+			}
+			return nil
+		},
+	})
+}
+
+func startOutboxRelay(lc fx.Lifecycle, relay *outbox.Relay, log logger.Logger) {
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			log.Info("Outbox relay starting")
+			relay.Start()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			if err := relay.Stop(ctx); err != nil {
+				log.Error("Outbox relay stopped before the batch finished", zap.Error(err))
+			}
+			return nil
+		},
+	})
+}
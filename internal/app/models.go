@@ -0,0 +1,48 @@
+package app
+
+import (
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/repository/postgres"
+	"github.com/firdanbash/go-clean-boiler/pkg/authz"
+	"github.com/firdanbash/go-clean-boiler/pkg/database"
+	"gorm.io/gorm"
+)
+
+// Models lists the models whose tables auto migration creates or updates
+func Models() []interface{} {
+	return []interface{}{
+		&domain.User{},
+		&domain.PasswordResetToken{},
+		&domain.MFARecoveryCode{},
+		&domain.PasswordHistory{},
+		&domain.RevokedToken{},
+		&domain.Session{},
+		&domain.AuditLog{},
+		&domain.LoginEvent{},
+		&domain.OutboxMessage{},
+		&domain.NotificationPreference{},
+		&domain.File{},
+		&domain.DataExport{},
+		&domain.Task{},
+		&domain.WebhookDelivery{},
+		&domain.FeatureFlag{},
+		&domain.Tenant{},
+		&domain.Organization{},
+		&domain.Membership{},
+		&domain.Role{},
+		&domain.Permission{},
+		&domain.RolePermission{},
+		&domain.UserRole{},
+		&authz.Rule{},
+		// gen:models
+	}
+}
+
+// AutoMigrate creates or updates the tables of all models, and the user
+// search index auto migration cannot declare
+func AutoMigrate(db *gorm.DB) error {
+	if err := database.AutoMigrate(db, Models()...); err != nil {
+		return err
+	}
+	return postgres.MigrateUserSearch(db)
+}
@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// Audit actions
+const (
+	AuditActionCreate      = "create"
+	AuditActionUpdate      = "update"
+	AuditActionDelete      = "delete"
+	AuditActionRestore     = "restore"
+	AuditActionHardDelete  = "hard_delete"
+	AuditActionImpersonate = "impersonate"
+)
+
+// AuditLog records a mutating operation: who changed which entity, and how.
+// ImpersonatorID is set when the actor was impersonated by an admin.
+type AuditLog struct {
+	ID             uint           `gorm:"primarykey" json:"id"`
+	TenantID       uint           `gorm:"not null;default:0;index" json:"-"`
+	ActorID        *uint          `gorm:"index" json:"actor_id"`
+	ImpersonatorID *uint          `gorm:"index" json:"impersonator_id,omitempty"`
+	Action         string         `gorm:"not null;index" json:"action"`
+	EntityType     string         `gorm:"not null;index:idx_audit_logs_entity" json:"entity_type"`
+	EntityID       uint           `gorm:"not null;index:idx_audit_logs_entity" json:"entity_id"`
+	Before         datatypes.JSON `json:"before"`
+	After          datatypes.JSON `json:"after"`
+	IP             string         `json:"ip"`
+	UserAgent      string         `json:"user_agent"`
+	CreatedAt      time.Time      `gorm:"index" json:"created_at"`
+}
+
+// TableName specifies the table name for AuditLog model
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
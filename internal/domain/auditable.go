@@ -0,0 +1,9 @@
+package domain
+
+// Auditable records who created a row and who last updated it. Models
+// embedding it are stamped with the user of the request context by the
+// repository callbacks; rows written outside a request keep nil.
+type Auditable struct {
+	CreatedBy *uint `gorm:"index" json:"created_by,omitempty"`
+	UpdatedBy *uint `json:"updated_by,omitempty"`
+}
@@ -0,0 +1,30 @@
+package domain
+
+import "time"
+
+// Data export statuses
+const (
+	DataExportPending = "pending"
+	DataExportReady   = "ready"
+	DataExportFailed  = "failed"
+)
+
+// DataExport is an archive of the personal data of a user, built in the
+// background on request and deleted once it expires
+type DataExport struct {
+	ID          uint       `gorm:"primarykey" json:"id"`
+	UserID      uint       `gorm:"not null;index" json:"user_id"`
+	Status      string     `gorm:"not null" json:"status"`
+	Key         string     `json:"-"` // storage key of the archive, once ready
+	Size        int64      `json:"size"`
+	Error       string     `json:"error,omitempty"`
+	CompletedAt *time.Time `json:"completed_at"`
+	ExpiresAt   *time.Time `gorm:"index" json:"expires_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for DataExport model
+func (DataExport) TableName() string {
+	return "data_exports"
+}
@@ -0,0 +1,31 @@
+package domain
+
+import "time"
+
+// Domain event types
+const (
+	EventUserCreated = "user.created"
+	EventUserUpdated = "user.updated"
+	EventUserDeleted = "user.deleted"
+)
+
+// Event is a change made to an entity, delivered to the parties interested
+// in it, such as clients connected over WebSocket
+type Event struct {
+	Type       string      `json:"type"`
+	UserID     uint        `json:"-"` // the user the entity belongs to
+	TenantID   uint        `json:"-"` // the tenant of that user
+	Data       interface{} `json:"data"`
+	OccurredAt time.Time   `json:"occurred_at"`
+}
+
+// NewEvent creates an event of type for a change to an entity of user
+func NewEvent(eventType string, user *User, data interface{}) Event {
+	return Event{
+		Type:       eventType,
+		UserID:     user.ID,
+		TenantID:   user.TenantID,
+		Data:       data,
+		OccurredAt: time.Now().UTC(),
+	}
+}
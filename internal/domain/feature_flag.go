@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+// FeatureFlag is the state of a feature flag set at runtime, overriding its
+// default from the configuration
+type FeatureFlag struct {
+	Name      string    `gorm:"primaryKey;size:100" json:"name"`
+	Enabled   bool      `gorm:"not null" json:"enabled"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for FeatureFlag model
+func (FeatureFlag) TableName() string {
+	return "feature_flags"
+}
@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+// File is an uploaded file. Its content lives in the storage backend under Key.
+type File struct {
+	ID          uint      `gorm:"primarykey" json:"id"`
+	OwnerID     uint      `gorm:"not null;index" json:"owner_id"`
+	Key         string    `gorm:"size:255;not null;uniqueIndex" json:"key"`
+	Name        string    `gorm:"size:255;not null" json:"name"`
+	ContentType string    `gorm:"size:100;not null" json:"content_type"`
+	Size        int64     `gorm:"not null" json:"size"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for File model
+func (File) TableName() string {
+	return "files"
+}
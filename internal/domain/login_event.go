@@ -0,0 +1,30 @@
+package domain
+
+import "time"
+
+// Login failure reasons
+const (
+	LoginFailureUnknownEmail    = "unknown_email"
+	LoginFailureInvalidPassword = "invalid_password"
+	LoginFailureInvalidMFACode  = "invalid_mfa_code"
+	LoginFailureThrottled       = "throttled"
+	LoginFailureCaptcha         = "captcha_required"
+)
+
+// LoginEvent records a single sign-in attempt.
+// UserID is nil when the attempt used an email that is not registered.
+type LoginEvent struct {
+	ID            uint      `gorm:"primarykey" json:"id"`
+	UserID        *uint     `gorm:"index:idx_login_events_user_created" json:"user_id"`
+	Email         string    `gorm:"not null;index" json:"email"`
+	Success       bool      `gorm:"not null" json:"success"`
+	FailureReason string    `json:"failure_reason,omitempty"`
+	IP            string    `json:"ip"`
+	UserAgent     string    `json:"user_agent"`
+	CreatedAt     time.Time `gorm:"index:idx_login_events_user_created" json:"created_at"`
+}
+
+// TableName specifies the table name for LoginEvent model
+func (LoginEvent) TableName() string {
+	return "login_events"
+}
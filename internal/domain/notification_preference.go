@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+// NotificationPreference is a user's choice to receive, or not, a type of
+// notification on a channel. Without one the defaults of the type apply.
+type NotificationPreference struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_notification_preferences_user_type_channel" json:"user_id"`
+	Type      string    `gorm:"size:50;not null;uniqueIndex:idx_notification_preferences_user_type_channel" json:"type"`
+	Channel   string    `gorm:"size:20;not null;uniqueIndex:idx_notification_preferences_user_type_channel" json:"channel"`
+	Enabled   bool      `gorm:"not null" json:"enabled"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for NotificationPreference model
+func (NotificationPreference) TableName() string {
+	return "notification_preferences"
+}
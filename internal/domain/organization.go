@@ -0,0 +1,42 @@
+package domain
+
+import "time"
+
+// Organization roles, from the most to the least privileged. Owners and
+// admins manage the members; only owners manage other owners.
+const (
+	OrgRoleOwner  = "owner"
+	OrgRoleAdmin  = "admin"
+	OrgRoleMember = "member"
+)
+
+// Organization groups users, who belong to it through a membership
+type Organization struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	TenantID  uint      `gorm:"not null;default:0;index" json:"-"`
+	Name      string    `gorm:"size:255;not null" json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Auditable
+}
+
+// TableName specifies the table name for Organization model
+func (Organization) TableName() string {
+	return "organizations"
+}
+
+// Membership makes a user a member of an organization with a role
+type Membership struct {
+	ID             uint      `gorm:"primarykey" json:"id"`
+	OrganizationID uint      `gorm:"not null;uniqueIndex:idx_memberships_organization_user,priority:1" json:"organization_id"`
+	UserID         uint      `gorm:"not null;uniqueIndex:idx_memberships_organization_user,priority:2;index" json:"user_id"`
+	Role           string    `gorm:"size:20;not null" json:"role"`
+	User           *User     `json:"-"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for Membership model
+func (Membership) TableName() string {
+	return "memberships"
+}
@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// OutboxMessage is a domain event stored in the same transaction as the change
+// it describes, waiting for the relay to publish it to the message broker
+type OutboxMessage struct {
+	ID          uint           `gorm:"primarykey" json:"id"`
+	Topic       string         `gorm:"size:100;not null" json:"topic"`
+	Key         string         `gorm:"size:100" json:"key"`
+	Payload     datatypes.JSON `gorm:"not null" json:"payload"`
+	Attempts    int            `gorm:"not null;default:0" json:"attempts"`
+	LastError   string         `json:"last_error"`
+	CreatedAt   time.Time      `json:"created_at"`
+	PublishedAt *time.Time     `gorm:"index" json:"published_at"`
+}
+
+// TableName specifies the table name for OutboxMessage model
+func (OutboxMessage) TableName() string {
+	return "outbox"
+}
@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+// RevokedToken represents a JWT that has been revoked before its expiry
+type RevokedToken struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	TokenID   string    `gorm:"uniqueIndex;not null" json:"token_id"`
+	ExpiresAt time.Time `gorm:"not null;index" json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for RevokedToken model
+func (RevokedToken) TableName() string {
+	return "revoked_tokens"
+}
@@ -0,0 +1,66 @@
+package domain
+
+import "time"
+
+// Permissions checked by the API itself; other permissions are defined by
+// admins and checked with RequirePermission on the routes they guard.
+const (
+	PermissionRolesManage = "roles:manage"
+)
+
+// Role is a named set of permissions, granted to the users it is assigned to
+// on top of their built-in role
+type Role struct {
+	ID          uint         `gorm:"primarykey" json:"id"`
+	TenantID    uint         `gorm:"not null;default:0;uniqueIndex:idx_roles_tenant_name,priority:1" json:"-"`
+	Name        string       `gorm:"size:100;not null;uniqueIndex:idx_roles_tenant_name,priority:2" json:"name"`
+	Description string       `gorm:"size:255" json:"description"`
+	Permissions []Permission `gorm:"many2many:role_permissions" json:"permissions"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+	Auditable
+}
+
+// TableName specifies the table name for Role model
+func (Role) TableName() string {
+	return "roles"
+}
+
+// Permission is the right to perform an action, named "resource:action"
+type Permission struct {
+	ID          uint      `gorm:"primarykey" json:"id"`
+	TenantID    uint      `gorm:"not null;default:0;uniqueIndex:idx_permissions_tenant_name,priority:1" json:"-"`
+	Name        string    `gorm:"size:100;not null;uniqueIndex:idx_permissions_tenant_name,priority:2" json:"name"`
+	Description string    `gorm:"size:255" json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Auditable
+}
+
+// TableName specifies the table name for Permission model
+func (Permission) TableName() string {
+	return "permissions"
+}
+
+// RolePermission grants a permission to a role
+type RolePermission struct {
+	RoleID       uint `gorm:"primaryKey"`
+	PermissionID uint `gorm:"primaryKey;index"`
+}
+
+// TableName specifies the table name for RolePermission model
+func (RolePermission) TableName() string {
+	return "role_permissions"
+}
+
+// UserRole assigns a role to a user
+type UserRole struct {
+	UserID    uint `gorm:"primaryKey"`
+	RoleID    uint `gorm:"primaryKey;index"`
+	CreatedAt time.Time
+}
+
+// TableName specifies the table name for UserRole model
+func (UserRole) TableName() string {
+	return "user_roles"
+}
@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+// Session records an access token issued to a user, with the device it was
+// issued to. A session ends when its token expires or is revoked.
+type Session struct {
+	ID           uint      `gorm:"primarykey" json:"id"`
+	UserID       uint      `gorm:"not null;index" json:"user_id"`
+	TokenID      string    `gorm:"uniqueIndex;not null" json:"token_id"`
+	TokenVersion uint      `gorm:"not null;default:0" json:"token_version"`
+	IP           string    `json:"ip"`
+	UserAgent    string    `json:"user_agent"`
+	ExpiresAt    time.Time `gorm:"not null;index" json:"expires_at"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for Session model
+func (Session) TableName() string {
+	return "sessions"
+}
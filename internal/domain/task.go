@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// Task statuses
+const (
+	TaskPending   = "pending"
+	TaskRunning   = "running"
+	TaskSucceeded = "succeeded"
+	TaskFailed    = "failed"
+)
+
+// Task is a long-running operation, such as a bulk deletion, run in the
+// background after its request was answered with 202 Accepted. Its owner
+// polls it for progress and, once it completes, its result or error.
+type Task struct {
+	ID          uint           `gorm:"primarykey" json:"id"`
+	UserID      uint           `gorm:"not null;index" json:"user_id"`
+	Type        string         `gorm:"size:50;not null" json:"type"`
+	Status      string         `gorm:"size:20;not null" json:"status"`
+	Progress    int            `gorm:"not null;default:0" json:"progress"` // percent done
+	Payload     datatypes.JSON `json:"-"`                                  // input of the task, as its runner reads it
+	Result      datatypes.JSON `json:"result"`
+	Error       string         `json:"error,omitempty"`
+	StartedAt   *time.Time     `json:"started_at"`
+	CompletedAt *time.Time     `gorm:"index" json:"completed_at"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
+
+// TableName specifies the table name for Task model
+func (Task) TableName() string {
+	return "tasks"
+}
+
+// Done reports whether the task has completed, successfully or not
+func (t *Task) Done() bool {
+	return t.Status == TaskSucceeded || t.Status == TaskFailed
+}
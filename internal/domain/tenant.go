@@ -0,0 +1,18 @@
+package domain
+
+import "time"
+
+// Tenant is an organization served by the deployment in isolation from the
+// others. Data of a tenant carries its ID in a tenant_id column.
+type Tenant struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	Slug      string    `gorm:"size:63;uniqueIndex;not null" json:"slug"`
+	Name      string    `gorm:"not null" json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for Tenant model
+func (Tenant) TableName() string {
+	return "tenants"
+}
@@ -6,15 +6,29 @@ import (
 	"gorm.io/gorm"
 )
 
+// User roles
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
 // User represents the user entity
 type User struct {
-	ID        uint           `gorm:"primarykey" json:"id"`
-	Email     string         `gorm:"uniqueIndex;not null" json:"email"`
-	Password  string         `gorm:"not null" json:"-"`
-	Name      string         `gorm:"not null" json:"name"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+	ID           uint           `gorm:"primarykey" json:"id"`
+	TenantID     uint           `gorm:"not null;default:0;uniqueIndex:idx_users_tenant_email,priority:1" json:"-"`
+	Email        string         `gorm:"not null;uniqueIndex:idx_users_tenant_email,priority:2" json:"email"`
+	Password     string         `gorm:"not null" json:"-"`
+	Name         string         `gorm:"not null" json:"name"`
+	Role         string         `gorm:"not null;default:user" json:"role"`
+	MFAEnabled   bool           `gorm:"not null;default:false" json:"mfa_enabled"`
+	MFASecret    string         `json:"-"`
+	AvatarKey    string         `json:"-"`
+	AvatarURL    string         `json:"avatar_url"`
+	TokenVersion uint           `gorm:"not null;default:0" json:"-"` // bumped to invalidate every token of the user
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
+	Auditable
 }
 
 // TableName specifies the table name for User model
@@ -0,0 +1,18 @@
+package domain
+
+import "time"
+
+// WebhookDelivery records an incoming webhook that was processed, so
+// redeliveries of it are acknowledged without processing it again
+type WebhookDelivery struct {
+	ID         uint      `gorm:"primarykey" json:"id"`
+	Provider   string    `gorm:"size:50;not null;uniqueIndex:idx_webhook_deliveries_provider_delivery" json:"provider"`
+	DeliveryID string    `gorm:"size:255;not null;uniqueIndex:idx_webhook_deliveries_provider_delivery" json:"delivery_id"`
+	EventType  string    `gorm:"size:100;not null" json:"event_type"`
+	CreatedAt  time.Time `gorm:"index" json:"created_at"`
+}
+
+// TableName specifies the table name for WebhookDelivery model
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
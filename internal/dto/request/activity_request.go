@@ -0,0 +1,7 @@
+package request
+
+// ListActivityRequest represents list login activity query parameters
+type ListActivityRequest struct {
+	Page    int `form:"page"`
+	PerPage int `form:"per_page"`
+}
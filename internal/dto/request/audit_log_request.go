@@ -0,0 +1,15 @@
+package request
+
+import "time"
+
+// ListAuditLogsRequest represents list audit logs query parameters
+type ListAuditLogsRequest struct {
+	Page       int       `form:"page"`
+	PerPage    int       `form:"per_page"`
+	ActorID    uint      `form:"actor_id"`
+	Action     string    `form:"action" validate:"omitempty,oneof=create update delete restore hard_delete impersonate"`
+	EntityType string    `form:"entity_type" validate:"omitempty,max=100"`
+	EntityID   uint      `form:"entity_id"`
+	From       time.Time `form:"from" time_format:"2006-01-02"`
+	To         time.Time `form:"to" time_format:"2006-01-02"`
+}
@@ -2,13 +2,38 @@ package request
 
 // RegisterRequest represents registration request
 type RegisterRequest struct {
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required,min=6"`
-	Name     string `json:"name" validate:"required,min=2"`
+	Email    string `json:"email" form:"email" xml:"email" validate:"required,email"`
+	Password string `json:"password" form:"password" xml:"password" validate:"required,password"`
+	Name     string `json:"name" form:"name" xml:"name" validate:"required,min=2"`
 }
 
 // LoginRequest represents login request
 type LoginRequest struct {
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required"`
+	Email        string `json:"email" form:"email" xml:"email" validate:"required,email"`
+	Password     string `json:"password" form:"password" xml:"password" validate:"required"`
+	CaptchaToken string `json:"captcha_token,omitempty" form:"captcha_token" xml:"captcha_token"` // required after repeated failed logins, when a captcha verifier is set up
+	RememberMe   bool   `json:"remember_me,omitempty" form:"remember_me" xml:"remember_me"`       // issue a long-lived token, when jwt.remember_me_expiration allows it
+}
+
+// ForgotPasswordRequest represents forgot password request
+type ForgotPasswordRequest struct {
+	Email string `json:"email" form:"email" xml:"email" validate:"required,email"`
+}
+
+// ResetPasswordRequest represents reset password request
+type ResetPasswordRequest struct {
+	Token    string `json:"token" form:"token" xml:"token" validate:"required"`
+	Password string `json:"password" form:"password" xml:"password" validate:"required,password"`
+}
+
+// MFACodeRequest represents a request carrying a TOTP code
+type MFACodeRequest struct {
+	Code string `json:"code" form:"code" xml:"code" validate:"required"`
+}
+
+// MFAVerifyRequest represents the second step of an MFA login.
+// Code accepts either a TOTP code or an unused recovery code.
+type MFAVerifyRequest struct {
+	MFAToken string `json:"mfa_token" form:"mfa_token" xml:"mfa_token" validate:"required"`
+	Code     string `json:"code" form:"code" xml:"code" validate:"required"`
 }
@@ -0,0 +1,6 @@
+package request
+
+// SetFeatureFlagRequest turns a feature flag on or off
+type SetFeatureFlagRequest struct {
+	Enabled *bool `json:"enabled" form:"enabled" xml:"enabled" validate:"required"`
+}
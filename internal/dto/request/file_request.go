@@ -0,0 +1,20 @@
+package request
+
+// PresignUploadRequest represents a request for a URL to upload a file directly to storage
+type PresignUploadRequest struct {
+	ContentType string `json:"content_type" form:"content_type" xml:"content_type" validate:"required,max=100"`
+	Size        int64  `json:"size" form:"size" xml:"size" validate:"required,gt=0"`
+}
+
+// CompleteUploadRequest represents the registration of a file uploaded with a presigned URL
+type CompleteUploadRequest struct {
+	Key  string `json:"key" form:"key" xml:"key" validate:"required,max=255"`
+	Name string `json:"name" form:"name" xml:"name" validate:"required,max=255"`
+}
+
+// ListFilesRequest represents list files query parameters
+type ListFilesRequest struct {
+	Page    int    `form:"page"`
+	PerPage int    `form:"per_page"`
+	Sort    string `form:"sort"`
+}
@@ -0,0 +1,6 @@
+package request
+
+// SetLogLevelRequest changes the minimum log level of the instance
+type SetLogLevelRequest struct {
+	Level string `json:"level" form:"level" xml:"level" validate:"required,oneof=debug info warn error"`
+}
@@ -0,0 +1,13 @@
+package request
+
+// UpdateNotificationPreferencesRequest represents the notification preferences to change
+type UpdateNotificationPreferencesRequest struct {
+	Preferences []NotificationPreferenceRequest `json:"preferences" form:"preferences" xml:"preferences>item" validate:"required,min=1,dive"`
+}
+
+// NotificationPreferenceRequest turns a type of notification on or off on a channel
+type NotificationPreferenceRequest struct {
+	Type    string `json:"type" form:"type" xml:"type" validate:"required"`
+	Channel string `json:"channel" form:"channel" xml:"channel" validate:"required,oneof=email sms push"`
+	Enabled *bool  `json:"enabled" form:"enabled" xml:"enabled" validate:"required"`
+}
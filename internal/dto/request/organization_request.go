@@ -0,0 +1,23 @@
+package request
+
+// CreateOrganizationRequest represents create organization request payload
+type CreateOrganizationRequest struct {
+	Name string `json:"name" form:"name" xml:"name" validate:"required,min=2,max=255"`
+}
+
+// InviteMemberRequest adds an existing user to an organization by email
+type InviteMemberRequest struct {
+	Email string `json:"email" form:"email" xml:"email" validate:"required,email"`
+	Role  string `json:"role" form:"role" xml:"role" validate:"required,oneof=owner admin member"`
+}
+
+// UpdateMemberRoleRequest changes the role of a member
+type UpdateMemberRoleRequest struct {
+	Role string `json:"role" form:"role" xml:"role" validate:"required,oneof=owner admin member"`
+}
+
+// ListOrganizationsRequest represents list organizations query parameters
+type ListOrganizationsRequest struct {
+	Page    int `form:"page"`
+	PerPage int `form:"per_page"`
+}
@@ -0,0 +1,28 @@
+package request
+
+// CreateRoleRequest represents create role request payload. Permissions are
+// named and must exist.
+type CreateRoleRequest struct {
+	Name        string   `json:"name" form:"name" xml:"name" validate:"required,min=2,max=100"`
+	Description string   `json:"description" form:"description" xml:"description" validate:"max=255"`
+	Permissions []string `json:"permissions" form:"permissions" xml:"permissions>item" validate:"dive,required"`
+}
+
+// UpdateRoleRequest represents update role request payload. Omitted fields
+// are left unchanged; a permissions list replaces the current one.
+type UpdateRoleRequest struct {
+	Name        string   `json:"name" form:"name" xml:"name" validate:"omitempty,min=2,max=100"`
+	Description string   `json:"description" form:"description" xml:"description" validate:"max=255"`
+	Permissions []string `json:"permissions" form:"permissions" xml:"permissions>item" validate:"omitempty,dive,required"`
+}
+
+// CreatePermissionRequest represents create permission request payload
+type CreatePermissionRequest struct {
+	Name        string `json:"name" form:"name" xml:"name" validate:"required,max=100"`
+	Description string `json:"description" form:"description" xml:"description" validate:"max=255"`
+}
+
+// AssignRoleRequest assigns a role to a user
+type AssignRoleRequest struct {
+	RoleID uint `json:"role_id" form:"role_id" xml:"role_id" validate:"required"`
+}
@@ -0,0 +1,13 @@
+package request
+
+import "time"
+
+// SearchUsersRequest represents search users query parameters
+type SearchUsersRequest struct {
+	Page        int       `form:"page"`
+	PerPage     int       `form:"per_page"`
+	Query       string    `form:"q" validate:"omitempty,max=100"`
+	Role        string    `form:"role" validate:"omitempty,oneof=user admin"`
+	CreatedFrom time.Time `form:"created_from" time_format:"2006-01-02"`
+	CreatedTo   time.Time `form:"created_to" time_format:"2006-01-02"`
+}
@@ -0,0 +1,6 @@
+package request
+
+// TaskIDRequest represents the task ID path parameter
+type TaskIDRequest struct {
+	ID uint `uri:"id" validate:"required"`
+}
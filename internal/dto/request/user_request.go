@@ -1,14 +1,55 @@
 package request
 
+import "time"
+
 // CreateUserRequest represents create user request
 type CreateUserRequest struct {
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required,min=6"`
-	Name     string `json:"name" validate:"required,min=2"`
+	Email    string `json:"email" form:"email" xml:"email" validate:"required,email"`
+	Password string `json:"password" form:"password" xml:"password" validate:"required,password"`
+	Name     string `json:"name" form:"name" xml:"name" validate:"required,min=2"`
+	Role     string `json:"role" form:"role" xml:"role" validate:"omitempty,oneof=user admin"`
+}
+
+// UserIDRequest represents the user ID path parameter
+type UserIDRequest struct {
+	ID uint `uri:"id" validate:"required"`
 }
 
-// UpdateUserRequest represents update user request
+// UpdateUserRequest represents update user request.
+// Omitted or null fields are left unchanged; set fields are validated even when empty.
 type UpdateUserRequest struct {
-	Email string `json:"email" validate:"omitempty,email"`
-	Name  string `json:"name" validate:"omitempty,min=2"`
+	Email *string `json:"email" form:"email" xml:"email" validate:"omitnil,email"`
+	Name  *string `json:"name" form:"name" xml:"name" validate:"omitnil,min=2"`
+}
+
+// BulkDeleteUsersRequest represents bulk delete users request
+type BulkDeleteUsersRequest struct {
+	IDs []uint `json:"ids" form:"ids" xml:"ids>item" validate:"required,min=1,max=1000,dive,required"`
+}
+
+// ChangePasswordRequest represents change password request
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" form:"current_password" xml:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" form:"new_password" xml:"new_password" validate:"required,password,nefield=CurrentPassword"`
+}
+
+// ListUsersRequest represents list users query parameters
+type ListUsersRequest struct {
+	Page           int       `form:"page"`
+	PerPage        int       `form:"per_page"`
+	IDs            string    `form:"ids"`
+	Search         string    `form:"search" validate:"omitempty,max=100"`
+	Query          string    `form:"q" validate:"omitempty,max=100"`
+	Email          string    `form:"email" validate:"omitempty,email"`
+	CreatedFrom    time.Time `form:"created_from" time_format:"2006-01-02"`
+	CreatedTo      time.Time `form:"created_to" time_format:"2006-01-02"`
+	IncludeDeleted bool      `form:"include_deleted"`
+	Sort           string    `form:"sort"`
+	Fields         string    `form:"fields"`
+}
+
+// ExportUsersRequest represents export users query parameters
+type ExportUsersRequest struct {
+	ListUsersRequest
+	Format string `form:"format" validate:"omitempty,oneof=csv xlsx"`
 }
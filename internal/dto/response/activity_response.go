@@ -0,0 +1,13 @@
+package response
+
+import "time"
+
+// LoginEventResponse represents a login attempt in response
+type LoginEventResponse struct {
+	ID            uint      `json:"id"`
+	Success       bool      `json:"success"`
+	FailureReason string    `json:"failure_reason,omitempty"`
+	IP            string    `json:"ip,omitempty"`
+	UserAgent     string    `json:"user_agent,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
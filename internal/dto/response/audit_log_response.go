@@ -0,0 +1,21 @@
+package response
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditLogResponse represents an audit log entry in response
+type AuditLogResponse struct {
+	ID             uint            `json:"id"`
+	ActorID        *uint           `json:"actor_id"`
+	ImpersonatorID *uint           `json:"impersonator_id,omitempty"`
+	Action         string          `json:"action"`
+	EntityType     string          `json:"entity_type"`
+	EntityID       uint            `json:"entity_id"`
+	Before         json.RawMessage `json:"before,omitempty"`
+	After          json.RawMessage `json:"after,omitempty"`
+	IP             string          `json:"ip,omitempty"`
+	UserAgent      string          `json:"user_agent,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
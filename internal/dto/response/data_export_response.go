@@ -0,0 +1,16 @@
+package response
+
+import "time"
+
+// DataExportResponse represents a personal data export in response. The
+// download URL is set once the archive is ready and expires before it does.
+type DataExportResponse struct {
+	ID                uint       `json:"id"`
+	Status            string     `json:"status"`
+	Size              int64      `json:"size,omitempty"`
+	DownloadURL       string     `json:"download_url,omitempty"`
+	DownloadExpiresAt *time.Time `json:"download_expires_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	CompletedAt       *time.Time `json:"completed_at,omitempty"`
+	ExpiresAt         *time.Time `json:"expires_at,omitempty"`
+}
@@ -0,0 +1,9 @@
+package response
+
+// FeatureFlagResponse represents a feature flag and its state
+type FeatureFlagResponse struct {
+	Name       string `json:"name"`
+	Enabled    bool   `json:"enabled"`
+	Default    bool   `json:"default"`
+	Overridden bool   `json:"overridden"`
+}
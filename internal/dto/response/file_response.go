@@ -0,0 +1,28 @@
+package response
+
+import "time"
+
+// FileResponse represents uploaded file data in response
+type FileResponse struct {
+	ID          uint      `json:"id"`
+	Name        string    `json:"name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// PresignedUploadResponse tells the client how to upload a file directly to storage.
+// The request must send the headers as given.
+type PresignedUploadResponse struct {
+	Key       string            `json:"key"`
+	Method    string            `json:"method"`
+	URL       string            `json:"url"`
+	Headers   map[string]string `json:"headers"`
+	ExpiresAt time.Time         `json:"expires_at"`
+}
+
+// PresignedDownloadResponse represents a short-lived URL to download a file
+type PresignedDownloadResponse struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
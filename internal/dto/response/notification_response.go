@@ -0,0 +1,9 @@
+package response
+
+// NotificationPreferenceResponse represents whether a type of notification is sent on a channel
+type NotificationPreferenceResponse struct {
+	Type     string `json:"type"`
+	Channel  string `json:"channel"`
+	Enabled  bool   `json:"enabled"`
+	Required bool   `json:"required"`
+}
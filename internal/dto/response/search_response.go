@@ -0,0 +1,10 @@
+package response
+
+// UserSearchHit represents a user matching a search. Highlight holds the
+// fragments of the name and email matching the query, HTML escaped, with the
+// matches wrapped in <em> tags.
+type UserSearchHit struct {
+	User      UserResponse        `json:"user"`
+	Score     float64             `json:"score"`
+	Highlight map[string][]string `json:"highlight,omitempty"`
+}
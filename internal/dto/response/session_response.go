@@ -0,0 +1,13 @@
+package response
+
+import "time"
+
+// SessionResponse represents a signed-in device in response
+type SessionResponse struct {
+	ID        uint      `json:"id"`
+	IP        string    `json:"ip,omitempty"`
+	UserAgent string    `json:"user_agent,omitempty"`
+	Current   bool      `json:"current"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
@@ -0,0 +1,33 @@
+package response
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// TaskResponse represents a background task in response. Progress is in
+// percent; the result is set once the task succeeded and the error once it
+// failed.
+type TaskResponse struct {
+	ID          uint            `json:"id"`
+	Type        string          `json:"type"`
+	Status      string          `json:"status" enums:"pending,running,succeeded,failed"`
+	Progress    int             `json:"progress"`
+	Result      json.RawMessage `json:"result,omitempty" swaggertype:"object"`
+	Error       string          `json:"error,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	StartedAt   *time.Time      `json:"started_at,omitempty"`
+	CompletedAt *time.Time      `json:"completed_at,omitempty"`
+}
+
+// BulkDeleteUsersResult represents the result of a bulk deletion of users
+type BulkDeleteUsersResult struct {
+	Deleted int                 `json:"deleted"`
+	Failed  []BulkDeleteFailure `json:"failed"`
+}
+
+// BulkDeleteFailure represents a user a bulk deletion could not delete
+type BulkDeleteFailure struct {
+	ID    uint   `json:"id"`
+	Error string `json:"error"`
+}
@@ -0,0 +1,11 @@
+package response
+
+import "time"
+
+// TenantResponse represents a tenant
+type TenantResponse struct {
+	ID        uint      `json:"id"`
+	Slug      string    `json:"slug"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
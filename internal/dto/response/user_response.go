@@ -4,15 +4,26 @@ import "time"
 
 // UserResponse represents user data in response
 type UserResponse struct {
-	ID        uint      `json:"id"`
-	Email     string    `json:"email"`
-	Name      string    `json:"name"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID         uint       `json:"id"`
+	Email      string     `json:"email"`
+	Name       string     `json:"name"`
+	Role       string     `json:"role"`
+	AvatarURL  string     `json:"avatar_url,omitempty"`
+	MFAEnabled bool       `json:"mfa_enabled"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	DeletedAt  *time.Time `json:"deleted_at,omitempty"`
 }
 
-// AuthResponse represents authentication response with token
+// AuthResponse represents authentication response with token.
+// When MFA is required only MFARequired and MFAToken are set.
+// RememberMe tells whether the token got the long remember me lifetime.
 type AuthResponse struct {
-	User  UserResponse `json:"user"`
-	Token string       `json:"token"`
+	User        *UserResponse `json:"user,omitempty"`
+	Token       string        `json:"token,omitempty"`
+	ExpiresAt   *time.Time    `json:"expires_at,omitempty"`
+	ExpiresIn   int64         `json:"expires_in,omitempty"` // seconds
+	RememberMe  bool          `json:"remember_me,omitempty"`
+	MFARequired bool          `json:"mfa_required,omitempty"`
+	MFAToken    string        `json:"mfa_token,omitempty"`
 }
@@ -0,0 +1,144 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"github.com/firdanbash/go-clean-boiler/pkg/tracing"
+	"go.uber.org/zap"
+)
+
+// Dispatcher delivers events to their handlers. Dispatch never fails the
+// caller: handler errors are logged.
+type Dispatcher interface {
+	Dispatch(ctx context.Context, e Event)
+}
+
+// Handler reacts to an event
+type Handler func(ctx context.Context, e Event) error
+
+// subscription is a handler registered for an event name
+type subscription struct {
+	handler Handler
+	async   bool
+}
+
+// Bus is an in-process Dispatcher. Synchronous handlers run in registration
+// order before Dispatch returns; asynchronous handlers run in the background,
+// detached from the cancellation of the dispatching context.
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[string][]subscription
+	closed   bool
+	running  sync.WaitGroup
+	log      logger.Logger
+}
+
+// NewBus creates a bus without handlers
+func NewBus(log logger.Logger) *Bus {
+	return &Bus{handlers: make(map[string][]subscription), log: log}
+}
+
+// Subscribe registers h to run synchronously for the events named name
+func (b *Bus) Subscribe(name string, h Handler) {
+	b.subscribe(name, subscription{handler: h})
+}
+
+// SubscribeAsync registers h to run in the background for the events named name
+func (b *Bus) SubscribeAsync(name string, h Handler) {
+	b.subscribe(name, subscription{handler: h, async: true})
+}
+
+func (b *Bus) subscribe(name string, s subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[name] = append(b.handlers[name], s)
+}
+
+// On registers a synchronous handler for the events of type E
+func On[E Event](b *Bus, h func(ctx context.Context, e E) error) {
+	var zero E
+	b.Subscribe(zero.EventName(), typed(h))
+}
+
+// OnAsync registers an asynchronous handler for the events of type E
+func OnAsync[E Event](b *Bus, h func(ctx context.Context, e E) error) {
+	var zero E
+	b.SubscribeAsync(zero.EventName(), typed(h))
+}
+
+// typed adapts a handler of E to a Handler
+func typed[E Event](h func(ctx context.Context, e E) error) Handler {
+	return func(ctx context.Context, e Event) error {
+		typedEvent, ok := e.(E)
+		if !ok {
+			return fmt.Errorf("unexpected event type %T", e)
+		}
+		return h(ctx, typedEvent)
+	}
+}
+
+// Dispatch runs the handlers of e. Once the bus is closed, asynchronous
+// handlers run synchronously so that no event is dropped during shutdown.
+func (b *Bus) Dispatch(ctx context.Context, e Event) {
+	b.mu.RLock()
+	subscriptions := b.handlers[e.EventName()]
+	closed := b.closed
+	if !closed {
+		for _, s := range subscriptions {
+			if s.async {
+				b.running.Add(1)
+			}
+		}
+	}
+	b.mu.RUnlock()
+
+	for _, s := range subscriptions {
+		if !s.async || closed {
+			b.call(ctx, e, s.handler)
+			continue
+		}
+		go func(h Handler) {
+			defer b.running.Done()
+			b.call(context.WithoutCancel(ctx), e, h)
+		}(s.handler)
+	}
+}
+
+// Close waits for the running asynchronous handlers to return or for ctx to be done
+func (b *Bus) Close(ctx context.Context) error {
+	b.mu.Lock()
+	b.closed = true
+	b.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		b.running.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// call runs h, logging its error or panic
+func (b *Bus) call(ctx context.Context, e Event, h Handler) {
+	ctx, span := tracing.Start(ctx, "event."+e.EventName())
+	defer span.End()
+
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Ctx(ctx, b.log).Error("Event handler panicked", zap.String("event", e.EventName()), zap.Any("panic", r))
+		}
+	}()
+
+	if err := h(ctx, e); err != nil {
+		logger.Ctx(ctx, b.log).Error("Event handler failed", zap.String("event", e.EventName()), zap.Error(err))
+	}
+}
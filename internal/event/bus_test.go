@@ -0,0 +1,97 @@
+package event_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/firdanbash/go-clean-boiler/internal/dto/response"
+	"github.com/firdanbash/go-clean-boiler/internal/event"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+)
+
+func TestBusRunsSynchronousHandlersInOrder(t *testing.T) {
+	bus := event.NewBus(logger.Nop())
+
+	var calls []string
+	event.On(bus, func(_ context.Context, e event.UserRegistered) error {
+		calls = append(calls, "first:"+e.User.Email)
+		return errors.New("mailbox full")
+	})
+	event.On(bus, func(_ context.Context, e event.UserRegistered) error {
+		calls = append(calls, "second:"+e.User.Email)
+		return nil
+	})
+	event.On(bus, func(_ context.Context, _ event.UserDeleted) error {
+		calls = append(calls, "deleted")
+		return nil
+	})
+
+	bus.Dispatch(context.Background(), event.UserRegistered{User: &response.UserResponse{Email: "jane@example.com"}})
+
+	want := []string{"first:jane@example.com", "second:jane@example.com"}
+	if len(calls) != len(want) || calls[0] != want[0] || calls[1] != want[1] {
+		t.Fatalf("handlers ran as %v, want %v", calls, want)
+	}
+}
+
+func TestBusRecoversFromPanics(t *testing.T) {
+	bus := event.NewBus(logger.Nop())
+
+	called := false
+	event.On(bus, func(context.Context, event.UserDeleted) error { panic("boom") })
+	event.On(bus, func(context.Context, event.UserDeleted) error {
+		called = true
+		return nil
+	})
+
+	bus.Dispatch(context.Background(), event.UserDeleted{User: &response.UserResponse{ID: 1}})
+
+	if !called {
+		t.Fatal("the handler after the panicking one did not run")
+	}
+}
+
+func TestBusRunsAsynchronousHandlersDetached(t *testing.T) {
+	bus := event.NewBus(logger.Nop())
+
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var ctxErr error
+	handled := 0
+	event.OnAsync(bus, func(ctx context.Context, _ event.UserRegistered) error {
+		<-release
+		mu.Lock()
+		defer mu.Unlock()
+		ctxErr = ctx.Err()
+		handled++
+		return nil
+	})
+
+	// The request finishes before the handler runs
+	ctx, cancel := context.WithCancel(context.Background())
+	bus.Dispatch(ctx, event.UserRegistered{User: &response.UserResponse{ID: 1}})
+	cancel()
+
+	closeCtx, stop := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer stop()
+	if err := bus.Close(closeCtx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Close() error = %v, want %v while a handler runs", err, context.DeadlineExceeded)
+	}
+
+	close(release)
+	if err := bus.Close(context.Background()); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if handled != 1 || ctxErr != nil {
+		t.Fatalf("handled %d times with context error %v, want once without error", handled, ctxErr)
+	}
+
+	// Once closed, asynchronous handlers run before Dispatch returns
+	bus.Dispatch(context.Background(), event.UserRegistered{User: &response.UserResponse{ID: 2}})
+	if handled != 2 {
+		t.Fatalf("handled %d times after close, want 2", handled)
+	}
+}
@@ -0,0 +1,66 @@
+// Package event dispatches typed domain events to the handlers registered for
+// them at bootstrap, so reactions to a change such as a welcome email or an
+// audit entry live outside the service that made it.
+package event
+
+import "github.com/firdanbash/go-clean-boiler/internal/dto/response"
+
+// Event names
+const (
+	NameUserRegistered      = "user.registered"
+	NameUserUpdated         = "user.updated"
+	NameUserDeleted         = "user.deleted"
+	NameDataExportRequested = "data_export.requested"
+	NameTaskQueued          = "task.queued"
+)
+
+// Event is a typed domain event
+type Event interface {
+	EventName() string
+}
+
+// UserRegistered is dispatched once a user account is created, by sign up or by an admin
+type UserRegistered struct {
+	User        *response.UserResponse
+	SelfService bool // the user signed up
+}
+
+// EventName returns the name of the event
+func (UserRegistered) EventName() string { return NameUserRegistered }
+
+// UserUpdated is dispatched once the profile of a user is changed, or a
+// deleted user is restored
+type UserUpdated struct {
+	User *response.UserResponse // the user after the change
+}
+
+// EventName returns the name of the event
+func (UserUpdated) EventName() string { return NameUserUpdated }
+
+// UserDeleted is dispatched once a user is deleted
+type UserDeleted struct {
+	User      *response.UserResponse // the user before deletion
+	Permanent bool                   // the user was hard deleted
+}
+
+// EventName returns the name of the event
+func (UserDeleted) EventName() string { return NameUserDeleted }
+
+// DataExportRequested is dispatched once a user asked for an export of their
+// personal data, for the archive to be built in the background
+type DataExportRequested struct {
+	ExportID uint
+	UserID   uint
+}
+
+// EventName returns the name of the event
+func (DataExportRequested) EventName() string { return NameDataExportRequested }
+
+// TaskQueued is dispatched once a task was created, for it to run in the background
+type TaskQueued struct {
+	TaskID uint
+	Type   string
+}
+
+// EventName returns the name of the event
+func (TaskQueued) EventName() string { return NameTaskQueued }
@@ -0,0 +1,31 @@
+package event
+
+import (
+	"context"
+
+	"go.uber.org/fx"
+)
+
+// Module provides the bus, as the Dispatcher of the services. Handlers are
+// registered on the bus by fx.Invoke functions of the modules reacting to events.
+var Module = fx.Module("event",
+	fx.Provide(
+		NewBus,
+		newDispatcher,
+	),
+	fx.Invoke(closeOnStop),
+)
+
+// newDispatcher dispatches the events of the services on the bus
+func newDispatcher(bus *Bus) Dispatcher {
+	return bus
+}
+
+// closeOnStop waits for the asynchronous handlers on shutdown
+func closeOnStop(lc fx.Lifecycle, bus *Bus) {
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			return bus.Close(ctx)
+		},
+	})
+}
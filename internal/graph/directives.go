@@ -0,0 +1,104 @@
+package graph
+
+import (
+	"context"
+	"errors"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/pkg/authz"
+	"github.com/firdanbash/go-clean-boiler/pkg/jwt"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+)
+
+type claimsKey struct{}
+
+type enforcerKey struct{}
+
+// withClaims returns a copy of ctx carrying the claims of the access token
+func withClaims(ctx context.Context, claims *jwt.Claims) context.Context {
+	return context.WithValue(ctx, claimsKey{}, claims)
+}
+
+// claimsFrom returns the claims of the access token of the request, if any
+func claimsFrom(ctx context.Context) (*jwt.Claims, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(*jwt.Claims)
+	return claims, ok
+}
+
+// withEnforcer returns a copy of ctx carrying the enforcer of the
+// authorization policies
+func withEnforcer(ctx context.Context, enforcer authz.Enforcer) context.Context {
+	return context.WithValue(ctx, enforcerKey{}, enforcer)
+}
+
+// newDirectives implements the directives declared in the schema
+func newDirectives(log logger.Logger) DirectiveRoot {
+	return DirectiveRoot{
+		Auth:      authDirective,
+		HasRole:   hasRoleDirective,
+		Authorize: authorizeDirective(log),
+	}
+}
+
+// authDirective resolves the field only for requests with an access token
+func authDirective(ctx context.Context, _ interface{}, next graphql.Resolver) (interface{}, error) {
+	if _, ok := claimsFrom(ctx); !ok {
+		return nil, newError(ctx, CodeUnauthenticated, "Authorization header required")
+	}
+	return next(ctx)
+}
+
+// hasRoleDirective resolves the field only for users with one of roles
+func hasRoleDirective(ctx context.Context, obj interface{}, next graphql.Resolver, roles []string) (interface{}, error) {
+	return authDirective(ctx, obj, func(ctx context.Context) (interface{}, error) {
+		if err := requireRole(ctx, roles...); err != nil {
+			return nil, err
+		}
+		return next(ctx)
+	})
+}
+
+// authorizeDirective resolves the field only for users the authorization
+// policies allow action on object, like the Authorize middleware of the REST
+// routes
+func authorizeDirective(log logger.Logger) func(ctx context.Context, obj interface{}, next graphql.Resolver, object, action string) (interface{}, error) {
+	return func(ctx context.Context, obj interface{}, next graphql.Resolver, object, action string) (interface{}, error) {
+		return authDirective(ctx, obj, func(ctx context.Context) (interface{}, error) {
+			enforcer, ok := ctx.Value(enforcerKey{}).(authz.Enforcer)
+			if !ok {
+				return nil, serviceError(ctx, log, "Failed to check permissions", errors.New("no policy enforcer"))
+			}
+			claims, _ := claimsFrom(ctx)
+			allowed, err := authz.Allowed(enforcer, claims.UserID, claims.Role, object, action)
+			if err != nil {
+				return nil, serviceError(ctx, log, "Failed to check permissions", err)
+			}
+			if !allowed {
+				return nil, newError(ctx, CodeForbidden, "You do not have permission to access this resource")
+			}
+			return next(ctx)
+		})
+	}
+}
+
+// requireRole allows the operation only if the caller has one of roles
+func requireRole(ctx context.Context, roles ...string) error {
+	if claims, ok := claimsFrom(ctx); ok {
+		for _, role := range roles {
+			if claims.Role == role {
+				return nil
+			}
+		}
+	}
+	return newError(ctx, CodeForbidden, "You do not have permission to access this resource")
+}
+
+// requireSelfOrAdmin allows the operation only if the caller is the user with
+// id or an admin
+func requireSelfOrAdmin(ctx context.Context, id uint) error {
+	if claims, ok := claimsFrom(ctx); ok && claims.UserID == id {
+		return nil
+	}
+	return requireRole(ctx, domain.RoleAdmin)
+}
@@ -0,0 +1,71 @@
+package graph
+
+import (
+	"context"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/firdanbash/go-clean-boiler/pkg/apperror"
+	"github.com/firdanbash/go-clean-boiler/pkg/i18n"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"github.com/firdanbash/go-clean-boiler/pkg/validator"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"go.uber.org/zap"
+)
+
+// Error codes set in the "code" extension of errors
+const (
+	CodeBadUserInput    = "BAD_USER_INPUT"
+	CodeUnauthenticated = "UNAUTHENTICATED"
+	CodeForbidden       = "FORBIDDEN"
+	CodeNotFound        = "NOT_FOUND"
+	CodeConflict        = "CONFLICT"
+	CodeTooManyRequests = "TOO_MANY_REQUESTS"
+	CodeInternal        = "INTERNAL_SERVER_ERROR"
+)
+
+// errorCodes maps the kinds of application errors to error codes
+var errorCodes = map[apperror.Kind]string{
+	apperror.KindValidation:      CodeBadUserInput,
+	apperror.KindUnauthorized:    CodeUnauthenticated,
+	apperror.KindForbidden:       CodeForbidden,
+	apperror.KindNotFound:        CodeNotFound,
+	apperror.KindConflict:        CodeConflict,
+	apperror.KindTooManyRequests: CodeTooManyRequests,
+}
+
+// newError returns an error for the field being resolved with message
+// translated and code in its extensions
+func newError(ctx context.Context, code, message string) *gqlerror.Error {
+	return &gqlerror.Error{
+		Message:    i18n.T(ctx, message),
+		Path:       graphql.GetPath(ctx),
+		Extensions: map[string]interface{}{"code": code},
+	}
+}
+
+// serviceError converts a service error to a GraphQL error carrying its
+// message, and its apperror code in the "reason" extension. Other errors are
+// logged and reported with message only.
+func serviceError(ctx context.Context, log logger.Logger, message string, err error) error {
+	if code, ok := errorCodes[apperror.KindOf(err)]; ok {
+		return &gqlerror.Error{
+			Message:    i18n.FromContext(ctx).Error(err),
+			Path:       graphql.GetPath(ctx),
+			Extensions: map[string]interface{}{"code": code, "reason": apperror.CodeOf(err)},
+		}
+	}
+	logger.Ctx(ctx, log).Error(message, zap.Error(err))
+	return newError(ctx, CodeInternal, message)
+}
+
+// validate checks input with its validate tags and reports the invalid fields
+// in the "fields" extension
+func validate(ctx context.Context, input interface{}) error {
+	err := validator.ValidateStructCtx(ctx, input)
+	if err == nil {
+		return nil
+	}
+	gqlErr := newError(ctx, CodeBadUserInput, "Validation failed")
+	gqlErr.Extensions["fields"] = validator.FormatValidationErrors(ctx, err)
+	return gqlErr
+}
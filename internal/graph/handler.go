@@ -0,0 +1,73 @@
+package graph
+
+import (
+	"context"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/99designs/gqlgen/graphql/playground"
+	"github.com/firdanbash/go-clean-boiler/internal/middleware"
+	"github.com/firdanbash/go-clean-boiler/internal/service"
+	"github.com/firdanbash/go-clean-boiler/pkg/config"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Handler serves the GraphQL endpoint and, outside production, its playground
+type Handler struct {
+	server      *handler.Server
+	playground  http.HandlerFunc
+	userService service.UserService
+}
+
+// NewHandler creates the GraphQL handler, or returns nil when GraphQL is
+// disabled. The playground and introspection are only served when enabled
+// and not in production.
+func NewHandler(resolver *Resolver, userService service.UserService, cfg *config.Config, log logger.Logger) *Handler {
+	if !cfg.GraphQL.Enabled {
+		return nil
+	}
+
+	srv := handler.New(NewExecutableSchema(Config{Resolvers: resolver, Directives: newDirectives(log)}))
+	srv.AddTransport(transport.GET{})
+	srv.AddTransport(transport.POST{})
+	srv.Use(extension.FixedComplexityLimit(cfg.GraphQL.ComplexityLimit))
+	srv.SetRecoverFunc(func(ctx context.Context, r interface{}) error {
+		logger.Ctx(ctx, log).Error("GraphQL resolver panicked", zap.Any("panic", r), zap.ByteString("stack", debug.Stack()))
+		return newError(ctx, CodeInternal, "An error occurred")
+	})
+
+	h := &Handler{server: srv, userService: userService}
+	if cfg.GraphQL.Playground && cfg.App.Env != "production" {
+		srv.Use(extension.Introspection{})
+		h.playground = playground.Handler("GraphQL playground", "/graphql")
+	}
+	return h
+}
+
+// PlaygroundEnabled reports whether the playground is served
+func (h *Handler) PlaygroundEnabled() bool {
+	return h.playground != nil
+}
+
+// Query executes a GraphQL operation. The claims of the access token, when
+// the request has one, and the policy enforcer are available to the resolvers.
+func (h *Handler) Query(c *gin.Context) {
+	ctx := withLoaders(c.Request.Context(), newLoaders(h.userService))
+	if claims, ok := middleware.GetClaims(c); ok {
+		ctx = withClaims(ctx, claims)
+	}
+	if enforcer, ok := middleware.GetEnforcer(c); ok {
+		ctx = withEnforcer(ctx, enforcer)
+	}
+	h.server.ServeHTTP(c.Writer, c.Request.WithContext(ctx))
+}
+
+// Playground serves the GraphQL playground
+func (h *Handler) Playground(c *gin.Context) {
+	h.playground(c.Writer, c.Request)
+}
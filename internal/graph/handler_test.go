@@ -0,0 +1,199 @@
+package graph_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/firdanbash/go-clean-boiler/internal/dto/response"
+	"github.com/firdanbash/go-clean-boiler/internal/graph"
+	"github.com/firdanbash/go-clean-boiler/internal/middleware"
+	"github.com/firdanbash/go-clean-boiler/internal/mocks"
+	"github.com/firdanbash/go-clean-boiler/internal/service"
+	"github.com/firdanbash/go-clean-boiler/internal/testutil"
+	"github.com/firdanbash/go-clean-boiler/pkg/apperror"
+	"github.com/firdanbash/go-clean-boiler/pkg/config"
+	"github.com/firdanbash/go-clean-boiler/pkg/jwt"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"go.uber.org/mock/gomock"
+)
+
+type graphQLMocks struct {
+	users    *mocks.MockUserService
+	audit    *mocks.MockAuditService
+	policies policies
+}
+
+// policies allows the "<subject> <object> <action>" requests it holds, and
+// every request of admins like the casbin model does
+type policies map[string]bool
+
+func (p policies) Enforce(rvals ...interface{}) (bool, error) {
+	return rvals[0] == "role:admin" || p[fmt.Sprintf("%v %v %v", rvals...)], nil
+}
+
+// newGraphQLClient serves /graphql with mocked services
+func newGraphQLClient(t *testing.T) (*testutil.Client, graphQLMocks, *jwt.Manager) {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+	m := graphQLMocks{
+		users:    mocks.NewMockUserService(ctrl),
+		audit:    mocks.NewMockAuditService(ctrl),
+		policies: policies{},
+	}
+	cfg := &config.Config{GraphQL: config.GraphQLConfig{Enabled: true, ComplexityLimit: 200}}
+	resolver := graph.NewResolver(m.users, mocks.NewMockAuthService(ctrl), m.audit, logger.Nop())
+	h := graph.NewHandler(resolver, m.users, cfg, logger.Nop())
+	jwtManager := testutil.JWTManager(t)
+
+	r, _ := testutil.Router(jwtManager)
+	r.Use(middleware.EnforcerMiddleware(m.policies))
+	r.POST("/graphql", middleware.OptionalAuthMiddleware(jwtManager, nil), h.Query)
+
+	return testutil.NewClient(t, r), m, jwtManager
+}
+
+// gqlErrors decodes the errors of a GraphQL response
+func gqlErrors(t *testing.T, resp *testutil.Response) []struct {
+	Message    string                 `json:"message"`
+	Extensions map[string]interface{} `json:"extensions"`
+} {
+	t.Helper()
+	var body struct {
+		Errors []struct {
+			Message    string                 `json:"message"`
+			Extensions map[string]interface{} `json:"extensions"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(resp.Body, &body); err != nil {
+		t.Fatalf("decode %s: %v", resp.Body, err)
+	}
+	return body.Errors
+}
+
+// assertErrorCode checks that the response has a single error with code
+func assertErrorCode(t *testing.T, resp *testutil.Response, code string) {
+	t.Helper()
+	errs := gqlErrors(t, resp)
+	if len(errs) != 1 || errs[0].Extensions["code"] != code {
+		t.Fatalf("errors = %s, want one %s error", resp.Body, code)
+	}
+}
+
+func query(q string) map[string]string {
+	return map[string]string{"query": q}
+}
+
+func TestGraphQLAccess(t *testing.T) {
+	client, m, jwtManager := newGraphQLClient(t)
+
+	assertErrorCode(t, client.Post("/graphql", query("{ me { id } }")).AssertStatus(http.StatusOK), graph.CodeUnauthenticated)
+	client.WithToken("not-a-token").Post("/graphql", query("{ me { id } }")).AssertError(http.StatusUnauthorized)
+
+	user := client.AsUser(jwtManager, testutil.NewUser(testutil.WithID(5)))
+	assertErrorCode(t, user.Post("/graphql", query("{ users { total } }")), graph.CodeForbidden)
+	assertErrorCode(t, user.Post("/graphql", query("{ user(id: 6) { id } }")), graph.CodeForbidden)
+
+	m.users.EXPECT().GetByID(gomock.Any(), uint(5)).Return(&response.UserResponse{ID: 5, Email: "me@example.com"}, nil)
+	var got struct {
+		Me struct {
+			ID    string `json:"id"`
+			Email string `json:"email"`
+		} `json:"me"`
+	}
+	user.Post("/graphql", query("{ me { id email } }")).AssertStatus(http.StatusOK).Decode(&got)
+	if got.Me.ID != "5" || got.Me.Email != "me@example.com" {
+		t.Errorf("me = %+v, want user 5", got.Me)
+	}
+}
+
+func TestGraphQLAuthorizesAuditLogs(t *testing.T) {
+	client, m, jwtManager := newGraphQLClient(t)
+	user := client.AsUser(jwtManager, testutil.NewUser(testutil.WithID(5)))
+
+	assertErrorCode(t, user.Post("/graphql", query("{ auditLogs { total } }")), graph.CodeForbidden)
+
+	m.policies["user:5 audit_logs read"] = true
+	m.audit.EXPECT().List(gomock.Any(), gomock.Any()).Return(nil, int64(0), nil)
+	resp := user.Post("/graphql", query("{ auditLogs { total } }")).AssertStatus(http.StatusOK)
+	if errs := gqlErrors(t, resp); len(errs) != 0 {
+		t.Fatalf("errors = %s, want none with the audit_logs read policy", resp.Body)
+	}
+}
+
+func TestGraphQLReportsErrorReasons(t *testing.T) {
+	client, m, jwtManager := newGraphQLClient(t)
+	admin := client.AsUser(jwtManager, testutil.NewUser(testutil.WithID(1), testutil.AsAdmin()))
+
+	m.users.EXPECT().GetByID(gomock.Any(), uint(9)).Return(nil, service.ErrUserNotFound)
+
+	resp := admin.Post("/graphql", query("{ user(id: 9) { id } }"))
+	assertErrorCode(t, resp, graph.CodeNotFound)
+	if reason := gqlErrors(t, resp)[0].Extensions["reason"]; reason != string(apperror.CodeUserNotFound) {
+		t.Errorf("reason = %v, want %s", reason, apperror.CodeUserNotFound)
+	}
+}
+
+func TestGraphQLValidatesInput(t *testing.T) {
+	client, _, jwtManager := newGraphQLClient(t)
+	admin := client.AsUser(jwtManager, testutil.NewUser(testutil.WithID(1), testutil.AsAdmin()))
+
+	resp := admin.Post("/graphql", query(`mutation { createUser(input: {email: "not-an-email", password: "x", name: "A"}) { id } }`))
+	assertErrorCode(t, resp, graph.CodeBadUserInput)
+
+	fields, _ := gqlErrors(t, resp)[0].Extensions["fields"].(map[string]interface{})
+	for _, field := range []string{"email", "password", "name"} {
+		if _, ok := fields[field]; !ok {
+			t.Errorf("no violation for %s in %v", field, fields)
+		}
+	}
+}
+
+func TestGraphQLBatchesAuditActors(t *testing.T) {
+	client, m, jwtManager := newGraphQLClient(t)
+	admin := client.AsUser(jwtManager, testutil.NewUser(testutil.WithID(1), testutil.AsAdmin()))
+
+	one, two := uint(1), uint(2)
+	m.audit.EXPECT().List(gomock.Any(), gomock.Any()).Return([]response.AuditLogResponse{
+		{ID: 1, ActorID: &one, Action: "create"},
+		{ID: 2, ActorID: &two, Action: "update"},
+		{ID: 3, ActorID: &one, Action: "delete"},
+		{ID: 4, Action: "create"},
+	}, int64(4), nil)
+	// One lookup for every actor on the page
+	m.users.EXPECT().GetByIDs(gomock.Any(), gomock.InAnyOrder([]uint{1, 2})).Return(map[uint]*response.UserResponse{
+		1: {ID: 1, Email: "one@example.com"},
+		2: {ID: 2, Email: "two@example.com"},
+	}, nil).Times(1)
+
+	var got struct {
+		AuditLogs struct {
+			Items []struct {
+				Actor *struct {
+					Email string `json:"email"`
+				} `json:"actor"`
+			} `json:"items"`
+		} `json:"auditLogs"`
+	}
+	admin.Post("/graphql", query("{ auditLogs { items { actor { email } } } }")).AssertStatus(http.StatusOK).Decode(&got)
+
+	var emails []string
+	for _, item := range got.AuditLogs.Items {
+		if item.Actor == nil {
+			emails = append(emails, "")
+			continue
+		}
+		emails = append(emails, item.Actor.Email)
+	}
+	want := []string{"one@example.com", "two@example.com", "one@example.com", ""}
+	if len(emails) != len(want) {
+		t.Fatalf("actors = %q, want %q", emails, want)
+	}
+	for i := range want {
+		if emails[i] != want[i] {
+			t.Errorf("actors = %q, want %q", emails, want)
+			break
+		}
+	}
+}
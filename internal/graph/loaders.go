@@ -0,0 +1,51 @@
+package graph
+
+import (
+	"context"
+	"time"
+
+	"github.com/firdanbash/go-clean-boiler/internal/dto/response"
+	"github.com/firdanbash/go-clean-boiler/internal/service"
+	"github.com/vikstrous/dataloadgen"
+)
+
+// loaderWait is how long a loader collects keys before fetching them in one
+// batch
+const loaderWait = 2 * time.Millisecond
+
+type loadersKey struct{}
+
+// Loaders batch and cache the lookups made while resolving one request, so a
+// list of audit log entries fetches its actors in one query rather than one
+// per entry
+type Loaders struct {
+	users *dataloadgen.Loader[uint, *response.UserResponse]
+}
+
+// newLoaders creates the loaders of one request
+func newLoaders(userService service.UserService) *Loaders {
+	return &Loaders{
+		users: dataloadgen.NewLoader(func(ctx context.Context, ids []uint) ([]*response.UserResponse, []error) {
+			users, err := userService.GetByIDs(ctx, ids)
+			if err != nil {
+				return nil, []error{err}
+			}
+			// Missing users resolve to nil, in the order of ids
+			result := make([]*response.UserResponse, len(ids))
+			for i, id := range ids {
+				result[i] = users[id]
+			}
+			return result, nil
+		}, dataloadgen.WithWait(loaderWait)),
+	}
+}
+
+// withLoaders returns a copy of ctx carrying loaders
+func withLoaders(ctx context.Context, loaders *Loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, loaders)
+}
+
+// loadersFrom returns the loaders of the request
+func loadersFrom(ctx context.Context) *Loaders {
+	return ctx.Value(loadersKey{}).(*Loaders)
+}
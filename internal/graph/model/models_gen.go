@@ -0,0 +1,27 @@
+// Code generated by github.com/99designs/gqlgen, DO NOT EDIT.
+
+package model
+
+import (
+	"github.com/firdanbash/go-clean-boiler/internal/dto/response"
+)
+
+type AuditLogPage struct {
+	Items   []*response.AuditLogResponse `json:"items"`
+	Total   int                          `json:"total"`
+	Page    int                          `json:"page"`
+	PerPage int                          `json:"perPage"`
+}
+
+type Mutation struct {
+}
+
+type Query struct {
+}
+
+type UserPage struct {
+	Items   []*response.UserResponse `json:"items"`
+	Total   int                      `json:"total"`
+	Page    int                      `json:"page"`
+	PerPage int                      `json:"perPage"`
+}
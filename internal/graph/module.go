@@ -0,0 +1,11 @@
+package graph
+
+import "go.uber.org/fx"
+
+// Module provides the GraphQL handler and its resolvers
+var Module = fx.Module("graph",
+	fx.Provide(
+		NewResolver,
+		NewHandler,
+	),
+)
@@ -0,0 +1,46 @@
+package graph
+
+//go:generate go run github.com/99designs/gqlgen@v0.17.49 generate
+
+import (
+	"encoding/json"
+
+	"github.com/firdanbash/go-clean-boiler/internal/service"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+)
+
+// Resolver resolves the GraphQL schema with the services shared with the REST
+// handlers
+type Resolver struct {
+	userService  service.UserService
+	authService  service.AuthService
+	auditService service.AuditService
+	log          logger.Logger
+}
+
+// NewResolver creates a new root resolver
+func NewResolver(userService service.UserService, authService service.AuthService, auditService service.AuditService, log logger.Logger) *Resolver {
+	return &Resolver{userService: userService, authService: authService, auditService: auditService, log: log}
+}
+
+// pageBounds returns the requested page and page size, falling back to the
+// first page and 10 per page like the REST endpoints
+func pageBounds(page, perPage *int) (int, int) {
+	p, size := 1, 10
+	if page != nil && *page > 0 {
+		p = *page
+	}
+	if perPage != nil && *perPage > 0 && *perPage <= 100 {
+		size = *perPage
+	}
+	return p, size
+}
+
+// snapshot returns an audit snapshot as a JSON string, or nil when there is none
+func snapshot(raw json.RawMessage) *string {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+	s := string(raw)
+	return &s
+}
@@ -0,0 +1,217 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen
+
+import (
+	"context"
+
+	"github.com/firdanbash/go-clean-boiler/internal/dto/request"
+	"github.com/firdanbash/go-clean-boiler/internal/dto/response"
+	"github.com/firdanbash/go-clean-boiler/internal/graph/model"
+)
+
+// Actor is the resolver for the actor field.
+func (r *auditLogResolver) Actor(ctx context.Context, obj *response.AuditLogResponse) (*response.UserResponse, error) {
+	if obj.ActorID == nil {
+		return nil, nil
+	}
+	user, err := loadersFrom(ctx).users.Load(ctx, *obj.ActorID)
+	if err != nil {
+		return nil, serviceError(ctx, r.log, "Failed to fetch user", err)
+	}
+	return user, nil
+}
+
+// Impersonator is the resolver for the impersonator field.
+func (r *auditLogResolver) Impersonator(ctx context.Context, obj *response.AuditLogResponse) (*response.UserResponse, error) {
+	if obj.ImpersonatorID == nil {
+		return nil, nil
+	}
+	user, err := loadersFrom(ctx).users.Load(ctx, *obj.ImpersonatorID)
+	if err != nil {
+		return nil, serviceError(ctx, r.log, "Failed to fetch user", err)
+	}
+	return user, nil
+}
+
+// Before is the resolver for the before field.
+func (r *auditLogResolver) Before(ctx context.Context, obj *response.AuditLogResponse) (*string, error) {
+	return snapshot(obj.Before), nil
+}
+
+// After is the resolver for the after field.
+func (r *auditLogResolver) After(ctx context.Context, obj *response.AuditLogResponse) (*string, error) {
+	return snapshot(obj.After), nil
+}
+
+// Register is the resolver for the register field.
+func (r *mutationResolver) Register(ctx context.Context, input request.RegisterRequest) (*response.AuthResponse, error) {
+	if err := validate(ctx, &input); err != nil {
+		return nil, err
+	}
+	auth, err := r.authService.Register(ctx, &input)
+	if err != nil {
+		return nil, serviceError(ctx, r.log, "Failed to register", err)
+	}
+	return auth, nil
+}
+
+// Login is the resolver for the login field.
+func (r *mutationResolver) Login(ctx context.Context, input request.LoginRequest) (*response.AuthResponse, error) {
+	if err := validate(ctx, &input); err != nil {
+		return nil, err
+	}
+	auth, err := r.authService.Login(ctx, &input)
+	if err != nil {
+		return nil, serviceError(ctx, r.log, "Failed to login", err)
+	}
+	return auth, nil
+}
+
+// VerifyMfa is the resolver for the verifyMfa field.
+func (r *mutationResolver) VerifyMfa(ctx context.Context, input request.MFAVerifyRequest) (*response.AuthResponse, error) {
+	if err := validate(ctx, &input); err != nil {
+		return nil, err
+	}
+	auth, err := r.authService.VerifyMFA(ctx, &input)
+	if err != nil {
+		return nil, serviceError(ctx, r.log, "Failed to verify MFA", err)
+	}
+	return auth, nil
+}
+
+// Logout is the resolver for the logout field.
+func (r *mutationResolver) Logout(ctx context.Context) (bool, error) {
+	claims, _ := claimsFrom(ctx)
+	if err := r.authService.Logout(ctx, claims.ID, claims.ExpiresAt.Time); err != nil {
+		return false, serviceError(ctx, r.log, "Failed to logout", err)
+	}
+	return true, nil
+}
+
+// LogoutAll is the resolver for the logoutAll field.
+func (r *mutationResolver) LogoutAll(ctx context.Context) (bool, error) {
+	claims, _ := claimsFrom(ctx)
+	if err := r.authService.LogoutAll(ctx, claims.UserID); err != nil {
+		return false, serviceError(ctx, r.log, "Failed to logout everywhere", err)
+	}
+	return true, nil
+}
+
+// CreateUser is the resolver for the createUser field.
+func (r *mutationResolver) CreateUser(ctx context.Context, input request.CreateUserRequest) (*response.UserResponse, error) {
+	if err := validate(ctx, &input); err != nil {
+		return nil, err
+	}
+	user, err := r.userService.Create(ctx, &input)
+	if err != nil {
+		return nil, serviceError(ctx, r.log, "Failed to create user", err)
+	}
+	return user, nil
+}
+
+// UpdateUser is the resolver for the updateUser field.
+func (r *mutationResolver) UpdateUser(ctx context.Context, id uint, input request.UpdateUserRequest) (*response.UserResponse, error) {
+	if err := requireSelfOrAdmin(ctx, id); err != nil {
+		return nil, err
+	}
+	if err := validate(ctx, &input); err != nil {
+		return nil, err
+	}
+	user, err := r.userService.Update(ctx, id, &input)
+	if err != nil {
+		return nil, serviceError(ctx, r.log, "Failed to update user", err)
+	}
+	return user, nil
+}
+
+// DeleteUser is the resolver for the deleteUser field.
+func (r *mutationResolver) DeleteUser(ctx context.Context, id uint) (bool, error) {
+	if err := r.userService.Delete(ctx, id); err != nil {
+		return false, serviceError(ctx, r.log, "Failed to delete user", err)
+	}
+	return true, nil
+}
+
+// Me is the resolver for the me field.
+func (r *queryResolver) Me(ctx context.Context) (*response.UserResponse, error) {
+	claims, _ := claimsFrom(ctx)
+	user, err := r.userService.GetByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, serviceError(ctx, r.log, "Failed to fetch user", err)
+	}
+	return user, nil
+}
+
+// User is the resolver for the user field.
+func (r *queryResolver) User(ctx context.Context, id uint) (*response.UserResponse, error) {
+	if err := requireSelfOrAdmin(ctx, id); err != nil {
+		return nil, err
+	}
+	user, err := r.userService.GetByID(ctx, id)
+	if err != nil {
+		return nil, serviceError(ctx, r.log, "Failed to fetch user", err)
+	}
+	return user, nil
+}
+
+// Users is the resolver for the users field.
+func (r *queryResolver) Users(ctx context.Context, filter *request.ListUsersRequest, page *int, perPage *int) (*model.UserPage, error) {
+	req := request.ListUsersRequest{}
+	if filter != nil {
+		req = *filter
+	}
+	req.Page, req.PerPage = pageBounds(page, perPage)
+	if err := validate(ctx, &req); err != nil {
+		return nil, err
+	}
+
+	users, total, err := r.userService.GetAll(ctx, &req)
+	if err != nil {
+		return nil, serviceError(ctx, r.log, "Failed to fetch users", err)
+	}
+
+	result := &model.UserPage{Total: int(total), Page: req.Page, PerPage: req.PerPage, Items: make([]*response.UserResponse, len(users))}
+	for i := range users {
+		result.Items[i] = &users[i]
+	}
+	return result, nil
+}
+
+// AuditLogs is the resolver for the auditLogs field.
+func (r *queryResolver) AuditLogs(ctx context.Context, filter *request.ListAuditLogsRequest, page *int, perPage *int) (*model.AuditLogPage, error) {
+	req := request.ListAuditLogsRequest{}
+	if filter != nil {
+		req = *filter
+	}
+	req.Page, req.PerPage = pageBounds(page, perPage)
+	if err := validate(ctx, &req); err != nil {
+		return nil, err
+	}
+
+	logs, total, err := r.auditService.List(ctx, &req)
+	if err != nil {
+		return nil, serviceError(ctx, r.log, "Failed to fetch audit logs", err)
+	}
+
+	result := &model.AuditLogPage{Total: int(total), Page: req.Page, PerPage: req.PerPage, Items: make([]*response.AuditLogResponse, len(logs))}
+	for i := range logs {
+		result.Items[i] = &logs[i]
+	}
+	return result, nil
+}
+
+// AuditLog returns AuditLogResolver implementation.
+func (r *Resolver) AuditLog() AuditLogResolver { return &auditLogResolver{r} }
+
+// Mutation returns MutationResolver implementation.
+func (r *Resolver) Mutation() MutationResolver { return &mutationResolver{r} }
+
+// Query returns QueryResolver implementation.
+func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
+
+type auditLogResolver struct{ *Resolver }
+type mutationResolver struct{ *Resolver }
+type queryResolver struct{ *Resolver }
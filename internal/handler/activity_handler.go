@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"github.com/firdanbash/go-clean-boiler/internal/dto/request"
+	"github.com/firdanbash/go-clean-boiler/internal/middleware"
+	"github.com/firdanbash/go-clean-boiler/internal/service"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"github.com/firdanbash/go-clean-boiler/pkg/response"
+	"github.com/firdanbash/go-clean-boiler/pkg/validator"
+	"github.com/gin-gonic/gin"
+)
+
+type ActivityHandler struct {
+	activityService service.ActivityService
+	log             logger.Logger
+}
+
+// NewActivityHandler creates a new activity handler
+func NewActivityHandler(activityService service.ActivityService, log logger.Logger) *ActivityHandler {
+	return &ActivityHandler{activityService: activityService, log: log}
+}
+
+// GetMyActivity godoc
+// @Summary Get the current user's login history
+// @Tags users
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param per_page query int false "Items per page" default(10)
+// @Success 200 {object} response.PaginatedResponse
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Security BearerAuth
+// @Router /api/v1/users/me/activity [get]
+func (h *ActivityHandler) GetMyActivity(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	var req request.ListActivityRequest
+	if !validator.BindQueryAndValidate(c, &req) {
+		return
+	}
+
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.PerPage < 1 || req.PerPage > 100 {
+		req.PerPage = 10
+	}
+
+	events, total, err := h.activityService.ListForUser(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondError(c, h.log, "Failed to fetch activity", err)
+		return
+	}
+
+	totalPages := int(total) / req.PerPage
+	if int(total)%req.PerPage > 0 {
+		totalPages++
+	}
+
+	pagination := response.PaginationMeta{
+		CurrentPage: req.Page,
+		PerPage:     req.PerPage,
+		Total:       total,
+		TotalPages:  totalPages,
+	}
+
+	response.Paginated(c, "Activity retrieved successfully", events, pagination)
+}
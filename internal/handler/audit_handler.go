@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"github.com/firdanbash/go-clean-boiler/internal/dto/request"
+	"github.com/firdanbash/go-clean-boiler/internal/service"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"github.com/firdanbash/go-clean-boiler/pkg/response"
+	"github.com/firdanbash/go-clean-boiler/pkg/validator"
+	"github.com/gin-gonic/gin"
+)
+
+type AuditHandler struct {
+	auditService service.AuditService
+	log          logger.Logger
+}
+
+// NewAuditHandler creates a new audit handler
+func NewAuditHandler(auditService service.AuditService, log logger.Logger) *AuditHandler {
+	return &AuditHandler{auditService: auditService, log: log}
+}
+
+// List godoc
+// @Summary List audit log entries
+// @Tags admin
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param per_page query int false "Items per page" default(10)
+// @Param actor_id query int false "Filter by acting user ID"
+// @Param action query string false "Filter by action (create, update, delete, restore, hard_delete, impersonate)"
+// @Param entity_type query string false "Filter by entity type (e.g. user)"
+// @Param entity_id query int false "Filter by entity ID"
+// @Param from query string false "Created on or after date (YYYY-MM-DD)"
+// @Param to query string false "Created on or before date (YYYY-MM-DD)"
+// @Success 200 {object} response.PaginatedResponse
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Security BearerAuth
+// @Router /api/v1/admin/audit-logs [get]
+func (h *AuditHandler) List(c *gin.Context) {
+	var req request.ListAuditLogsRequest
+	if !validator.BindQueryAndValidate(c, &req) {
+		return
+	}
+
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.PerPage < 1 || req.PerPage > 100 {
+		req.PerPage = 10
+	}
+
+	logs, total, err := h.auditService.List(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, h.log, "Failed to fetch audit logs", err)
+		return
+	}
+
+	totalPages := int(total) / req.PerPage
+	if int(total)%req.PerPage > 0 {
+		totalPages++
+	}
+
+	pagination := response.PaginationMeta{
+		CurrentPage: req.Page,
+		PerPage:     req.PerPage,
+		Total:       total,
+		TotalPages:  totalPages,
+	}
+
+	response.Paginated(c, "Audit logs retrieved successfully", logs, pagination)
+}
@@ -1,8 +1,14 @@
 package handler
 
 import (
+	"errors"
+	"math"
+	"strconv"
+
 	"github.com/firdanbash/go-clean-boiler/internal/dto/request"
+	"github.com/firdanbash/go-clean-boiler/internal/middleware"
 	"github.com/firdanbash/go-clean-boiler/internal/service"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
 	"github.com/firdanbash/go-clean-boiler/pkg/response"
 	"github.com/firdanbash/go-clean-boiler/pkg/validator"
 	"github.com/gin-gonic/gin"
@@ -10,11 +16,12 @@ import (
 
 type AuthHandler struct {
 	authService service.AuthService
+	log         logger.Logger
 }
 
 // NewAuthHandler creates a new auth handler
-func NewAuthHandler(authService service.AuthService) *AuthHandler {
-	return &AuthHandler{authService: authService}
+func NewAuthHandler(authService service.AuthService, log logger.Logger) *AuthHandler {
+	return &AuthHandler{authService: authService, log: log}
 }
 
 // Register godoc
@@ -25,7 +32,8 @@ func NewAuthHandler(authService service.AuthService) *AuthHandler {
 // @Param request body request.RegisterRequest true "Registration request"
 // @Success 201 {object} response.Response
 // @Failure 400 {object} response.Response
-// @Router /auth/register [post]
+// @Failure 409 {object} response.Response
+// @Router /api/v1/auth/register [post]
 func (h *AuthHandler) Register(c *gin.Context) {
 	var req request.RegisterRequest
 
@@ -33,9 +41,9 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
-	result, err := h.authService.Register(&req)
+	result, err := h.authService.Register(c.Request.Context(), &req)
 	if err != nil {
-		response.BadRequest(c, err.Error(), nil)
+		respondError(c, h.log, "Failed to register", err)
 		return
 	}
 
@@ -50,7 +58,9 @@ func (h *AuthHandler) Register(c *gin.Context) {
 // @Param request body request.LoginRequest true "Login request"
 // @Success 200 {object} response.Response
 // @Failure 400 {object} response.Response
-// @Router /auth/login [post]
+// @Failure 401 {object} response.Response
+// @Failure 429 {object} response.Response
+// @Router /api/v1/auth/login [post]
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req request.LoginRequest
 
@@ -58,11 +68,269 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	result, err := h.authService.Login(&req)
+	result, err := h.authService.Login(c.Request.Context(), &req)
+	if err != nil {
+		var throttled *service.LoginThrottledError
+		if errors.As(err, &throttled) {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+		}
+		respondError(c, h.log, "Failed to login", err)
+		return
+	}
+
+	if result.MFARequired {
+		response.Success(c, "MFA verification required", result)
+		return
+	}
+
+	response.Success(c, "Login successful", result)
+}
+
+// ForgotPassword godoc
+// @Summary Request a password reset email
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body request.ForgotPasswordRequest true "Forgot password request"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Router /api/v1/auth/forgot-password [post]
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+	var req request.ForgotPasswordRequest
+
+	if !validator.BindAndValidate(c, &req) {
+		return
+	}
+
+	if err := h.authService.ForgotPassword(c.Request.Context(), &req); err != nil {
+		respondError(c, h.log, "Failed to process password reset request", err)
+		return
+	}
+
+	response.Success(c, "If the email is registered, a password reset link has been sent", nil)
+}
+
+// ResetPassword godoc
+// @Summary Reset password using a reset token
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body request.ResetPasswordRequest true "Reset password request"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Router /api/v1/auth/reset-password [post]
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req request.ResetPasswordRequest
+
+	if !validator.BindAndValidate(c, &req) {
+		return
+	}
+
+	if err := h.authService.ResetPassword(c.Request.Context(), &req); err != nil {
+		respondError(c, h.log, "Failed to reset password", err)
+		return
+	}
+
+	response.Success(c, "Password reset successfully", nil)
+}
+
+// EnableMFA godoc
+// @Summary Start MFA enrollment
+// @Description Generates a TOTP secret and provisioning URI to be scanned by an authenticator app
+// @Tags auth
+// @Produce json
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Security BearerAuth
+// @Router /api/v1/auth/mfa/enable [post]
+func (h *AuthHandler) EnableMFA(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	result, err := h.authService.EnableMFA(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, h.log, "Failed to enable MFA", err)
+		return
+	}
+
+	response.Success(c, "Scan the provisioning URI and confirm with a code to enable MFA", result)
+}
+
+// ConfirmMFA godoc
+// @Summary Confirm MFA enrollment
+// @Description Activates MFA and returns single-use recovery codes
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body request.MFACodeRequest true "TOTP code"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Security BearerAuth
+// @Router /api/v1/auth/mfa/confirm [post]
+func (h *AuthHandler) ConfirmMFA(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	var req request.MFACodeRequest
+	if !validator.BindAndValidate(c, &req) {
+		return
+	}
+
+	result, err := h.authService.ConfirmMFA(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondError(c, h.log, "Failed to confirm MFA", err)
+		return
+	}
+
+	response.Success(c, "MFA enabled successfully", result)
+}
+
+// DisableMFA godoc
+// @Summary Disable MFA
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body request.MFACodeRequest true "TOTP code"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Security BearerAuth
+// @Router /api/v1/auth/mfa/disable [post]
+func (h *AuthHandler) DisableMFA(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	var req request.MFACodeRequest
+	if !validator.BindAndValidate(c, &req) {
+		return
+	}
+
+	if err := h.authService.DisableMFA(c.Request.Context(), userID, &req); err != nil {
+		respondError(c, h.log, "Failed to disable MFA", err)
+		return
+	}
+
+	response.Success(c, "MFA disabled successfully", nil)
+}
+
+// VerifyMFA godoc
+// @Summary Complete login with an MFA code
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body request.MFAVerifyRequest true "MFA verification request"
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Router /api/v1/auth/mfa/verify [post]
+func (h *AuthHandler) VerifyMFA(c *gin.Context) {
+	var req request.MFAVerifyRequest
+
+	if !validator.BindAndValidate(c, &req) {
+		return
+	}
+
+	result, err := h.authService.VerifyMFA(c.Request.Context(), &req)
 	if err != nil {
-		response.BadRequest(c, err.Error(), nil)
+		respondError(c, h.log, "Failed to verify MFA", err)
 		return
 	}
 
 	response.Success(c, "Login successful", result)
 }
+
+// Logout godoc
+// @Summary Logout and revoke the current token
+// @Tags auth
+// @Produce json
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Security BearerAuth
+// @Router /api/v1/auth/logout [post]
+func (h *AuthHandler) Logout(c *gin.Context) {
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	if err := h.authService.Logout(c.Request.Context(), claims.ID, claims.ExpiresAt.Time); err != nil {
+		respondError(c, h.log, "Failed to logout", err)
+		return
+	}
+
+	response.Success(c, "Logout successful", nil)
+}
+
+// LogoutAll godoc
+// @Summary Sign out everywhere by revoking every token of the current user
+// @Tags auth
+// @Produce json
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Security BearerAuth
+// @Router /api/v1/auth/logout-all [post]
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	if err := h.authService.LogoutAll(c.Request.Context(), userID); err != nil {
+		respondError(c, h.log, "Failed to logout everywhere", err)
+		return
+	}
+
+	response.Success(c, "Logged out everywhere", nil)
+}
+
+// Impersonate godoc
+// @Summary Get a short-lived token acting as a user, for support (users impersonate policy)
+// @Description The token names the caller as impersonator and every change made with it is audited
+// @Description with both. Admins cannot be impersonated, nor can users with permissions the caller
+// @Description lacks, and the token cannot change the password, email or MFA of the user, sign them
+// @Description out or delete them.
+// @Tags admin
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Security BearerAuth
+// @Router /api/v1/admin/users/{id}/impersonate [post]
+func (h *AuthHandler) Impersonate(c *gin.Context) {
+	impersonatorID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid user ID", nil)
+		return
+	}
+
+	auth, err := h.authService.Impersonate(c.Request.Context(), impersonatorID, uint(id))
+	if err != nil {
+		respondError(c, h.log, "Failed to impersonate user", err)
+		return
+	}
+
+	response.Success(c, "Impersonation started", auth)
+}
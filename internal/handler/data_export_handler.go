@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/middleware"
+	"github.com/firdanbash/go-clean-boiler/internal/service"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"github.com/firdanbash/go-clean-boiler/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+type DataExportHandler struct {
+	dataExportService service.DataExportService
+	log               logger.Logger
+}
+
+// NewDataExportHandler creates a new data export handler
+func NewDataExportHandler(dataExportService service.DataExportService, log logger.Logger) *DataExportHandler {
+	return &DataExportHandler{dataExportService: dataExportService, log: log}
+}
+
+// ExportMyData godoc
+// @Summary Export the personal data of the current user
+// @Description Starts building a ZIP archive of the profile, login activity and audit entries of the user in the background and returns 202 until it is ready. Once ready the response holds an expiring download URL.
+// @Tags users
+// @Produce json
+// @Success 200 {object} response.Response{data=response.DataExportResponse}
+// @Success 202 {object} response.Response{data=response.DataExportResponse}
+// @Failure 401 {object} response.Response
+// @Security BearerAuth
+// @Router /api/v1/users/me/export [get]
+func (h *DataExportHandler) ExportMyData(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	export, err := h.dataExportService.Export(c.Request.Context(), userID, apiPath(c, "/data-exports/download"))
+	if err != nil {
+		respondError(c, h.log, "Failed to export data", err)
+		return
+	}
+
+	if export.Status != domain.DataExportReady {
+		response.Accepted(c, "Data export is being prepared", export)
+		return
+	}
+
+	response.Success(c, "Data export is ready", export)
+}
+
+// Download godoc
+// @Summary Download a personal data export
+// @Description Streams the archive of a data export. The token comes from the download URL of the export.
+// @Tags users
+// @Produce application/zip
+// @Param token query string true "Download token"
+// @Success 200 {file} binary
+// @Failure 404 {object} response.Response
+// @Router /api/v1/data-exports/download [get]
+func (h *DataExportHandler) Download(c *gin.Context) {
+	download, err := h.dataExportService.Open(c.Request.Context(), c.Query("token"))
+	if err != nil {
+		respondError(c, h.log, "Failed to download data export", err)
+		return
+	}
+	defer download.Content.Close()
+
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Length", strconv.FormatInt(download.Size, 10))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, download.Name))
+	c.Header("Cache-Control", "no-store")
+	if _, err := io.Copy(c.Writer, download.Content); err != nil {
+		_ = c.Error(err)
+	}
+}
@@ -0,0 +1,19 @@
+package handler
+
+import (
+	"github.com/firdanbash/go-clean-boiler/pkg/apperror"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"github.com/firdanbash/go-clean-boiler/pkg/response"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// respondError maps a service error to its response. Application errors get
+// the status of their kind; anything else is logged and answered with a 500
+// carrying message.
+func respondError(c *gin.Context, log logger.Logger, message string, err error) {
+	if apperror.KindOf(err) == apperror.KindInternal {
+		logger.Ctx(c.Request.Context(), log).Error(message, zap.Error(err))
+	}
+	response.Error(c, err, message)
+}
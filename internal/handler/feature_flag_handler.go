@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"github.com/firdanbash/go-clean-boiler/internal/dto/request"
+	"github.com/firdanbash/go-clean-boiler/internal/service"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"github.com/firdanbash/go-clean-boiler/pkg/response"
+	"github.com/firdanbash/go-clean-boiler/pkg/validator"
+	"github.com/gin-gonic/gin"
+)
+
+type FeatureFlagHandler struct {
+	featureFlagService service.FeatureFlagService
+	log                logger.Logger
+}
+
+// NewFeatureFlagHandler creates a new feature flag handler
+func NewFeatureFlagHandler(featureFlagService service.FeatureFlagService, log logger.Logger) *FeatureFlagHandler {
+	return &FeatureFlagHandler{featureFlagService: featureFlagService, log: log}
+}
+
+// List godoc
+// @Summary List the feature flags
+// @Description Every flag declared under feature_flags.flags, with its default and current state
+// @Tags admin
+// @Produce json
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Security BearerAuth
+// @Router /api/v1/admin/feature-flags [get]
+func (h *FeatureFlagHandler) List(c *gin.Context) {
+	flags := h.featureFlagService.List(c.Request.Context())
+	response.Success(c, "Feature flags retrieved successfully", flags)
+}
+
+// Update godoc
+// @Summary Turn a feature flag on or off
+// @Description Requires feature_flags.store. Other instances apply the change within feature_flags.refresh_interval.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param name path string true "Flag name"
+// @Param request body request.SetFeatureFlagRequest true "New state"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Security BearerAuth
+// @Router /api/v1/admin/feature-flags/{name} [put]
+func (h *FeatureFlagHandler) Update(c *gin.Context) {
+	var req request.SetFeatureFlagRequest
+	if !validator.BindAndValidate(c, &req) {
+		return
+	}
+
+	flag, err := h.featureFlagService.Set(c.Request.Context(), c.Param("name"), &req)
+	if err != nil {
+		respondError(c, h.log, "Failed to update feature flag", err)
+		return
+	}
+
+	response.Success(c, "Feature flag updated successfully", flag)
+}
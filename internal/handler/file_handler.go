@@ -0,0 +1,326 @@
+package handler
+
+import (
+	"io"
+	"mime"
+	"strconv"
+
+	"github.com/firdanbash/go-clean-boiler/internal/dto/request"
+	"github.com/firdanbash/go-clean-boiler/internal/middleware"
+	"github.com/firdanbash/go-clean-boiler/internal/service"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"github.com/firdanbash/go-clean-boiler/pkg/response"
+	"github.com/firdanbash/go-clean-boiler/pkg/validator"
+	"github.com/gin-gonic/gin"
+)
+
+type FileHandler struct {
+	fileService service.FileService
+	log         logger.Logger
+}
+
+// NewFileHandler creates a new file handler
+func NewFileHandler(fileService service.FileService, log logger.Logger) *FileHandler {
+	return &FileHandler{fileService: fileService, log: log}
+}
+
+// Upload godoc
+// @Summary Upload a file
+// @Description The content type is detected from the content and must be one of storage.allowed_file_types
+// @Tags files
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "File to upload"
+// @Success 201 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Security BearerAuth
+// @Router /api/v1/files [post]
+func (h *FileHandler) Upload(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		response.BadRequest(c, "File is required", err.Error())
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		response.BadRequest(c, "Invalid file", err.Error())
+		return
+	}
+	defer file.Close()
+
+	result, err := h.fileService.Upload(c.Request.Context(), userID, fileHeader.Filename, file, fileHeader.Size)
+	if err != nil {
+		respondError(c, h.log, "Failed to upload file", err)
+		return
+	}
+
+	response.Created(c, "File uploaded successfully", result)
+}
+
+// PresignUpload godoc
+// @Summary Get a URL to upload a file directly to storage
+// @Description Requires the s3 storage driver. Upload the file with the returned method, URL and headers, then register it with POST /files/uploads/complete.
+// @Tags files
+// @Accept json
+// @Produce json
+// @Param request body request.PresignUploadRequest true "Content type and size of the file"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Security BearerAuth
+// @Router /api/v1/files/uploads [post]
+func (h *FileHandler) PresignUpload(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	var req request.PresignUploadRequest
+	if !validator.BindAndValidate(c, &req) {
+		return
+	}
+
+	upload, err := h.fileService.PresignUpload(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondError(c, h.log, "Failed to create upload URL", err)
+		return
+	}
+
+	response.Success(c, "Upload URL created successfully", upload)
+}
+
+// CompleteUpload godoc
+// @Summary Register a file uploaded with a presigned URL
+// @Description The content is checked like a direct upload and removed when rejected
+// @Tags files
+// @Accept json
+// @Produce json
+// @Param request body request.CompleteUploadRequest true "Key of the upload and name of the file"
+// @Success 201 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Security BearerAuth
+// @Router /api/v1/files/uploads/complete [post]
+func (h *FileHandler) CompleteUpload(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	var req request.CompleteUploadRequest
+	if !validator.BindAndValidate(c, &req) {
+		return
+	}
+
+	file, err := h.fileService.CompleteUpload(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondError(c, h.log, "Failed to complete upload", err)
+		return
+	}
+
+	response.Created(c, "File uploaded successfully", file)
+}
+
+// GetAll godoc
+// @Summary Get the current user's files
+// @Tags files
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param per_page query int false "Items per page" default(10)
+// @Param sort query string false "Sort fields (id, name, size, created_at), prefix with - for descending"
+// @Success 200 {object} response.PaginatedResponse
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Security BearerAuth
+// @Router /api/v1/files [get]
+func (h *FileHandler) GetAll(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	var req request.ListFilesRequest
+	if !validator.BindQueryAndValidate(c, &req) {
+		return
+	}
+
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.PerPage < 1 || req.PerPage > 100 {
+		req.PerPage = 10
+	}
+
+	files, total, err := h.fileService.GetAll(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondError(c, h.log, "Failed to fetch files", err)
+		return
+	}
+
+	totalPages := int(total) / req.PerPage
+	if int(total)%req.PerPage > 0 {
+		totalPages++
+	}
+
+	pagination := response.PaginationMeta{
+		CurrentPage: req.Page,
+		PerPage:     req.PerPage,
+		Total:       total,
+		TotalPages:  totalPages,
+	}
+
+	response.Paginated(c, "Files retrieved successfully", files, pagination)
+}
+
+// GetByID godoc
+// @Summary Get a file of the current user by ID
+// @Tags files
+// @Produce json
+// @Param id path int true "File ID"
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Security BearerAuth
+// @Router /api/v1/files/{id} [get]
+func (h *FileHandler) GetByID(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid file ID", nil)
+		return
+	}
+
+	file, err := h.fileService.GetByID(c.Request.Context(), userID, uint(id))
+	if err != nil {
+		respondError(c, h.log, "Failed to fetch file", err)
+		return
+	}
+
+	response.Success(c, "File retrieved successfully", file)
+}
+
+// GetDownloadURL godoc
+// @Summary Get a short-lived URL to download a file of the current user
+// @Description Requires the s3 storage driver
+// @Tags files
+// @Produce json
+// @Param id path int true "File ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Security BearerAuth
+// @Router /api/v1/files/{id}/download-url [get]
+func (h *FileHandler) GetDownloadURL(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid file ID", nil)
+		return
+	}
+
+	download, err := h.fileService.PresignDownload(c.Request.Context(), userID, uint(id))
+	if err != nil {
+		respondError(c, h.log, "Failed to create download URL", err)
+		return
+	}
+
+	response.Success(c, "Download URL created successfully", download)
+}
+
+// Download godoc
+// @Summary Download a file of the current user
+// @Description Streams the content of the file, whatever the storage driver
+// @Tags files
+// @Produce octet-stream
+// @Param id path int true "File ID"
+// @Success 200 {file} binary
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Security BearerAuth
+// @Router /api/v1/files/{id}/download [get]
+func (h *FileHandler) Download(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid file ID", nil)
+		return
+	}
+
+	download, err := h.fileService.Open(c.Request.Context(), userID, uint(id))
+	if err != nil {
+		respondError(c, h.log, "Failed to download file", err)
+		return
+	}
+	defer download.Content.Close()
+
+	// Served as an attachment of its detected type, so browsers never render it
+	c.Header("Content-Type", download.ContentType)
+	c.Header("Content-Length", strconv.FormatInt(download.Size, 10))
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": download.Name}))
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Cache-Control", "private, no-store")
+	if _, err := io.Copy(c.Writer, download.Content); err != nil {
+		_ = c.Error(err)
+	}
+}
+
+// Delete godoc
+// @Summary Delete a file of the current user
+// @Tags files
+// @Produce json
+// @Param id path int true "File ID"
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Security BearerAuth
+// @Router /api/v1/files/{id} [delete]
+func (h *FileHandler) Delete(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid file ID", nil)
+		return
+	}
+
+	if err := h.fileService.Delete(c.Request.Context(), userID, uint(id)); err != nil {
+		respondError(c, h.log, "Failed to delete file", err)
+		return
+	}
+
+	response.Success(c, "File deleted successfully", nil)
+}
@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/firdanbash/go-clean-boiler/pkg/health"
+	"github.com/firdanbash/go-clean-boiler/pkg/version"
+	"github.com/gin-gonic/gin"
+)
+
+type HealthHandler struct {
+	checker *health.Checker
+}
+
+// NewHealthHandler creates a new health handler
+func NewHealthHandler(checker *health.Checker) *HealthHandler {
+	return &HealthHandler{checker: checker}
+}
+
+// Live godoc
+// @Summary Liveness probe
+// @Description Reports that the process is running, without touching dependencies
+// @Tags health
+// @Produce json
+// @Success 200 {object} map[string]string
+// @Router /health/live [get]
+func (h *HealthHandler) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "ok",
+		"message": "Server is running",
+	})
+}
+
+// Ready godoc
+// @Summary Readiness probe
+// @Description Checks every dependency and reports per-dependency status and latency
+// @Tags health
+// @Produce json
+// @Success 200 {object} health.Report
+// @Failure 503 {object} health.Report
+// @Router /health/ready [get]
+func (h *HealthHandler) Ready(c *gin.Context) {
+	// Served without the response envelope so orchestrators can read the status directly
+	report := h.checker.Run(c.Request.Context())
+	if !report.Healthy() {
+		c.JSON(http.StatusServiceUnavailable, report)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// Version godoc
+// @Summary Build information
+// @Description Reports the version, git commit and build time of the running binary and the Go runtime it runs on
+// @Tags health
+// @Produce json
+// @Success 200 {object} version.Info
+// @Router /version [get]
+func (h *HealthHandler) Version(c *gin.Context) {
+	// Served without the response envelope, like the probes
+	c.JSON(http.StatusOK, version.Get())
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"github.com/firdanbash/go-clean-boiler/internal/dto/request"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"github.com/firdanbash/go-clean-boiler/pkg/response"
+	"github.com/firdanbash/go-clean-boiler/pkg/validator"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type LogLevelHandler struct {
+	log logger.Logger
+}
+
+// NewLogLevelHandler creates a new log level handler
+func NewLogLevelHandler(log logger.Logger) *LogLevelHandler {
+	return &LogLevelHandler{log: log}
+}
+
+// Get godoc
+// @Summary Get the log level
+// @Description The minimum level logged by this instance
+// @Tags admin
+// @Produce json
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Security BearerAuth
+// @Router /api/v1/admin/log-level [get]
+func (h *LogLevelHandler) Get(c *gin.Context) {
+	response.Success(c, "Log level retrieved successfully", gin.H{"level": logger.Level()})
+}
+
+// Update godoc
+// @Summary Change the log level
+// @Description Changes the minimum level logged by this instance, and which queries the database logs, until it restarts or log.level is reloaded. Other instances keep their level.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body request.SetLogLevelRequest true "New level"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Security BearerAuth
+// @Router /api/v1/admin/log-level [put]
+func (h *LogLevelHandler) Update(c *gin.Context) {
+	var req request.SetLogLevelRequest
+	if !validator.BindAndValidate(c, &req) {
+		return
+	}
+
+	previous := logger.Level()
+	if err := logger.SetLevel(req.Level); err != nil {
+		respondError(c, h.log, "Failed to change log level", err)
+		return
+	}
+
+	// Logged as a warning so that it is kept whatever the new level; the
+	// request log fields name the admin who changed it
+	logger.Ctx(c.Request.Context(), h.log).Warn("Log level changed",
+		zap.String("from", previous),
+		zap.String("to", req.Level),
+	)
+
+	response.Success(c, "Log level updated successfully", gin.H{"level": logger.Level()})
+}
@@ -0,0 +1,26 @@
+package handler
+
+import "go.uber.org/fx"
+
+// Module provides the HTTP handlers
+var Module = fx.Module("handlers",
+	fx.Provide(
+		NewAuthHandler,
+		NewUserHandler,
+		NewJWKSHandler,
+		NewAuditHandler,
+		NewActivityHandler,
+		NewSessionHandler,
+		NewHealthHandler,
+		NewNotificationHandler,
+		NewFileHandler,
+		NewFeatureFlagHandler,
+		NewOrganizationHandler,
+		NewRoleHandler,
+		NewDataExportHandler,
+		NewTaskHandler,
+		NewSearchHandler,
+		NewLogLevelHandler,
+		// gen:handlers
+	),
+)
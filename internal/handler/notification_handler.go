@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"github.com/firdanbash/go-clean-boiler/internal/dto/request"
+	"github.com/firdanbash/go-clean-boiler/internal/middleware"
+	"github.com/firdanbash/go-clean-boiler/internal/service"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"github.com/firdanbash/go-clean-boiler/pkg/response"
+	"github.com/firdanbash/go-clean-boiler/pkg/validator"
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationHandler struct {
+	notificationService service.NotificationService
+	log                 logger.Logger
+}
+
+// NewNotificationHandler creates a new notification handler
+func NewNotificationHandler(notificationService service.NotificationService, log logger.Logger) *NotificationHandler {
+	return &NotificationHandler{notificationService: notificationService, log: log}
+}
+
+// GetMyPreferences godoc
+// @Summary Get the current user's notification preferences
+// @Tags users
+// @Produce json
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Security BearerAuth
+// @Router /api/v1/users/me/notification-preferences [get]
+func (h *NotificationHandler) GetMyPreferences(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	preferences, err := h.notificationService.GetPreferences(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, h.log, "Failed to fetch notification preferences", err)
+		return
+	}
+
+	response.Success(c, "Notification preferences retrieved successfully", preferences)
+}
+
+// UpdateMyPreferences godoc
+// @Summary Turn notifications on or off for the current user
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body request.UpdateNotificationPreferencesRequest true "Preferences to change"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Security BearerAuth
+// @Router /api/v1/users/me/notification-preferences [put]
+func (h *NotificationHandler) UpdateMyPreferences(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	var req request.UpdateNotificationPreferencesRequest
+	if !validator.BindAndValidate(c, &req) {
+		return
+	}
+
+	preferences, err := h.notificationService.UpdatePreferences(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondError(c, h.log, "Failed to update notification preferences", err)
+		return
+	}
+
+	response.Success(c, "Notification preferences updated successfully", preferences)
+}
@@ -0,0 +1,265 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/firdanbash/go-clean-boiler/internal/dto/request"
+	"github.com/firdanbash/go-clean-boiler/internal/middleware"
+	"github.com/firdanbash/go-clean-boiler/internal/service"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"github.com/firdanbash/go-clean-boiler/pkg/response"
+	"github.com/firdanbash/go-clean-boiler/pkg/validator"
+	"github.com/gin-gonic/gin"
+)
+
+type OrganizationHandler struct {
+	organizationService service.OrganizationService
+	log                 logger.Logger
+}
+
+// NewOrganizationHandler creates a new organization handler
+func NewOrganizationHandler(organizationService service.OrganizationService, log logger.Logger) *OrganizationHandler {
+	return &OrganizationHandler{organizationService: organizationService, log: log}
+}
+
+// Create godoc
+// @Summary Create an organization
+// @Description The current user becomes its owner
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param request body request.CreateOrganizationRequest true "Organization"
+// @Success 201 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Security BearerAuth
+// @Router /api/v1/organizations [post]
+func (h *OrganizationHandler) Create(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	var req request.CreateOrganizationRequest
+	if !validator.BindAndValidate(c, &req) {
+		return
+	}
+
+	org, err := h.organizationService.Create(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondError(c, h.log, "Failed to create organization", err)
+		return
+	}
+
+	response.Created(c, "Organization created successfully", org)
+}
+
+// GetAll godoc
+// @Summary Get the organizations of the current user
+// @Tags organizations
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param per_page query int false "Items per page" default(10)
+// @Success 200 {object} response.PaginatedResponse
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Security BearerAuth
+// @Router /api/v1/organizations [get]
+func (h *OrganizationHandler) GetAll(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	var req request.ListOrganizationsRequest
+	if !validator.BindQueryAndValidate(c, &req) {
+		return
+	}
+
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.PerPage < 1 || req.PerPage > 100 {
+		req.PerPage = 10
+	}
+
+	orgs, total, err := h.organizationService.GetAll(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondError(c, h.log, "Failed to fetch organizations", err)
+		return
+	}
+
+	totalPages := int(total) / req.PerPage
+	if int(total)%req.PerPage > 0 {
+		totalPages++
+	}
+
+	pagination := response.PaginationMeta{
+		CurrentPage: req.Page,
+		PerPage:     req.PerPage,
+		Total:       total,
+		TotalPages:  totalPages,
+	}
+
+	response.Paginated(c, "Organizations retrieved successfully", orgs, pagination)
+}
+
+// GetByID godoc
+// @Summary Get an organization of the current user by ID
+// @Tags organizations
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Security BearerAuth
+// @Router /api/v1/organizations/{id} [get]
+func (h *OrganizationHandler) GetByID(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid organization ID", nil)
+		return
+	}
+
+	org, err := h.organizationService.GetByID(c.Request.Context(), userID, uint(id))
+	if err != nil {
+		respondError(c, h.log, "Failed to fetch organization", err)
+		return
+	}
+
+	response.Success(c, "Organization retrieved successfully", org)
+}
+
+// GetMembers godoc
+// @Summary Get the members of an organization
+// @Tags organizations
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Security BearerAuth
+// @Router /api/v1/organizations/{id}/members [get]
+func (h *OrganizationHandler) GetMembers(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid organization ID", nil)
+		return
+	}
+
+	members, err := h.organizationService.ListMembers(c.Request.Context(), userID, uint(id))
+	if err != nil {
+		respondError(c, h.log, "Failed to fetch members", err)
+		return
+	}
+
+	response.Success(c, "Members retrieved successfully", members)
+}
+
+// InviteMember godoc
+// @Summary Add a user to an organization
+// @Description Owners and admins add existing users by email, who are notified; only owners add owners
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param request body request.InviteMemberRequest true "Email of the user and role"
+// @Success 201 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Security BearerAuth
+// @Router /api/v1/organizations/{id}/members [post]
+func (h *OrganizationHandler) InviteMember(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid organization ID", nil)
+		return
+	}
+
+	var req request.InviteMemberRequest
+	if !validator.BindAndValidate(c, &req) {
+		return
+	}
+
+	member, err := h.organizationService.InviteMember(c.Request.Context(), userID, uint(id), &req)
+	if err != nil {
+		respondError(c, h.log, "Failed to invite member", err)
+		return
+	}
+
+	response.Created(c, "Member invited successfully", member)
+}
+
+// UpdateMemberRole godoc
+// @Summary Change the role of a member of an organization
+// @Description Owners and admins change the roles of admins and members; only owners promote to or demote from owner, and the last owner cannot be demoted
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param userId path int true "User ID of the member"
+// @Param request body request.UpdateMemberRoleRequest true "New role"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Security BearerAuth
+// @Router /api/v1/organizations/{id}/members/{userId} [put]
+func (h *OrganizationHandler) UpdateMemberRole(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid organization ID", nil)
+		return
+	}
+	memberID, err := strconv.ParseUint(c.Param("userId"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid user ID", nil)
+		return
+	}
+
+	var req request.UpdateMemberRoleRequest
+	if !validator.BindAndValidate(c, &req) {
+		return
+	}
+
+	member, err := h.organizationService.UpdateMemberRole(c.Request.Context(), userID, uint(id), uint(memberID), &req)
+	if err != nil {
+		respondError(c, h.log, "Failed to update member", err)
+		return
+	}
+
+	response.Success(c, "Member updated successfully", member)
+}
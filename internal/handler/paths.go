@@ -0,0 +1,23 @@
+package handler
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// apiPath returns path under the API version of the route serving c, e.g.
+// /api/v2/tasks/7 for a request to /api/v2/users/bulk-delete, so links in
+// responses stay on the version the client uses
+func apiPath(c *gin.Context, path string) string {
+	route := c.FullPath()
+	if route == "" {
+		route = c.Request.URL.Path
+	}
+	rest, ok := strings.CutPrefix(route, "/api/")
+	if !ok {
+		return path
+	}
+	version, _, _ := strings.Cut(rest, "/")
+	return "/api/" + version + path
+}
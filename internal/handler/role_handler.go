@@ -0,0 +1,342 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/firdanbash/go-clean-boiler/internal/dto/request"
+	"github.com/firdanbash/go-clean-boiler/internal/service"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"github.com/firdanbash/go-clean-boiler/pkg/response"
+	"github.com/firdanbash/go-clean-boiler/pkg/validator"
+	"github.com/gin-gonic/gin"
+)
+
+type RoleHandler struct {
+	roleService service.RoleService
+	log         logger.Logger
+}
+
+// NewRoleHandler creates a new role handler
+func NewRoleHandler(roleService service.RoleService, log logger.Logger) *RoleHandler {
+	return &RoleHandler{roleService: roleService, log: log}
+}
+
+// ListRoles godoc
+// @Summary List the roles
+// @Description Requires the roles:manage permission
+// @Tags roles
+// @Produce json
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Security BearerAuth
+// @Router /api/v1/admin/roles [get]
+func (h *RoleHandler) ListRoles(c *gin.Context) {
+	roles, err := h.roleService.GetRoles(c.Request.Context())
+	if err != nil {
+		respondError(c, h.log, "Failed to fetch roles", err)
+		return
+	}
+
+	response.Success(c, "Roles retrieved successfully", roles)
+}
+
+// CreateRole godoc
+// @Summary Create a role
+// @Description Requires the roles:manage permission. The granted permissions must exist.
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Param request body request.CreateRoleRequest true "Role"
+// @Success 201 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Security BearerAuth
+// @Router /api/v1/admin/roles [post]
+func (h *RoleHandler) CreateRole(c *gin.Context) {
+	var req request.CreateRoleRequest
+	if !validator.BindAndValidate(c, &req) {
+		return
+	}
+
+	role, err := h.roleService.CreateRole(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, h.log, "Failed to create role", err)
+		return
+	}
+
+	response.Created(c, "Role created successfully", role)
+}
+
+// GetRole godoc
+// @Summary Get a role by ID
+// @Description Requires the roles:manage permission
+// @Tags roles
+// @Produce json
+// @Param id path int true "Role ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Security BearerAuth
+// @Router /api/v1/admin/roles/{id} [get]
+func (h *RoleHandler) GetRole(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid role ID", nil)
+		return
+	}
+
+	role, err := h.roleService.GetRole(c.Request.Context(), uint(id))
+	if err != nil {
+		respondError(c, h.log, "Failed to fetch role", err)
+		return
+	}
+
+	response.Success(c, "Role retrieved successfully", role)
+}
+
+// UpdateRole godoc
+// @Summary Update a role
+// @Description Requires the roles:manage permission. A permissions list replaces the permissions of the role.
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Param id path int true "Role ID"
+// @Param request body request.UpdateRoleRequest true "Role fields to update"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Security BearerAuth
+// @Router /api/v1/admin/roles/{id} [put]
+func (h *RoleHandler) UpdateRole(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid role ID", nil)
+		return
+	}
+
+	var req request.UpdateRoleRequest
+	if !validator.BindAndValidate(c, &req) {
+		return
+	}
+
+	role, err := h.roleService.UpdateRole(c.Request.Context(), uint(id), &req)
+	if err != nil {
+		respondError(c, h.log, "Failed to update role", err)
+		return
+	}
+
+	response.Success(c, "Role updated successfully", role)
+}
+
+// DeleteRole godoc
+// @Summary Delete a role
+// @Description Requires the roles:manage permission. The role is taken away from its users.
+// @Tags roles
+// @Produce json
+// @Param id path int true "Role ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Security BearerAuth
+// @Router /api/v1/admin/roles/{id} [delete]
+func (h *RoleHandler) DeleteRole(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid role ID", nil)
+		return
+	}
+
+	if err := h.roleService.DeleteRole(c.Request.Context(), uint(id)); err != nil {
+		respondError(c, h.log, "Failed to delete role", err)
+		return
+	}
+
+	response.Success(c, "Role deleted successfully", nil)
+}
+
+// ListPermissions godoc
+// @Summary List the permissions
+// @Description Requires the roles:manage permission
+// @Tags roles
+// @Produce json
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Security BearerAuth
+// @Router /api/v1/admin/permissions [get]
+func (h *RoleHandler) ListPermissions(c *gin.Context) {
+	permissions, err := h.roleService.GetPermissions(c.Request.Context())
+	if err != nil {
+		respondError(c, h.log, "Failed to fetch permissions", err)
+		return
+	}
+
+	response.Success(c, "Permissions retrieved successfully", permissions)
+}
+
+// CreatePermission godoc
+// @Summary Create a permission
+// @Description Requires the roles:manage permission. Names look like resource:action.
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Param request body request.CreatePermissionRequest true "Permission"
+// @Success 201 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Security BearerAuth
+// @Router /api/v1/admin/permissions [post]
+func (h *RoleHandler) CreatePermission(c *gin.Context) {
+	var req request.CreatePermissionRequest
+	if !validator.BindAndValidate(c, &req) {
+		return
+	}
+
+	permission, err := h.roleService.CreatePermission(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, h.log, "Failed to create permission", err)
+		return
+	}
+
+	response.Created(c, "Permission created successfully", permission)
+}
+
+// DeletePermission godoc
+// @Summary Delete a permission
+// @Description Requires the roles:manage permission. The permission is taken away from every role.
+// @Tags roles
+// @Produce json
+// @Param id path int true "Permission ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Security BearerAuth
+// @Router /api/v1/admin/permissions/{id} [delete]
+func (h *RoleHandler) DeletePermission(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid permission ID", nil)
+		return
+	}
+
+	if err := h.roleService.DeletePermission(c.Request.Context(), uint(id)); err != nil {
+		respondError(c, h.log, "Failed to delete permission", err)
+		return
+	}
+
+	response.Success(c, "Permission deleted successfully", nil)
+}
+
+// GetUserRoles godoc
+// @Summary List the roles assigned to a user
+// @Description Requires the roles:manage permission
+// @Tags roles
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Security BearerAuth
+// @Router /api/v1/users/{id}/roles [get]
+func (h *RoleHandler) GetUserRoles(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid user ID", nil)
+		return
+	}
+
+	roles, err := h.roleService.GetUserRoles(c.Request.Context(), uint(id))
+	if err != nil {
+		respondError(c, h.log, "Failed to fetch roles", err)
+		return
+	}
+
+	response.Success(c, "Roles retrieved successfully", roles)
+}
+
+// AssignRole godoc
+// @Summary Assign a role to a user
+// @Description Requires the roles:manage permission. Returns the roles of the user.
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param request body request.AssignRoleRequest true "Role to assign"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Security BearerAuth
+// @Router /api/v1/users/{id}/roles [post]
+func (h *RoleHandler) AssignRole(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid user ID", nil)
+		return
+	}
+
+	var req request.AssignRoleRequest
+	if !validator.BindAndValidate(c, &req) {
+		return
+	}
+
+	roles, err := h.roleService.AssignRole(c.Request.Context(), uint(id), req.RoleID)
+	if err != nil {
+		respondError(c, h.log, "Failed to assign role", err)
+		return
+	}
+
+	response.Success(c, "Role assigned successfully", roles)
+}
+
+// UnassignRole godoc
+// @Summary Take a role away from a user
+// @Description Requires the roles:manage permission. Returns the roles left to the user.
+// @Tags roles
+// @Produce json
+// @Param id path int true "User ID"
+// @Param roleId path int true "Role ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Security BearerAuth
+// @Router /api/v1/users/{id}/roles/{roleId} [delete]
+func (h *RoleHandler) UnassignRole(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid user ID", nil)
+		return
+	}
+	roleID, err := strconv.ParseUint(c.Param("roleId"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid role ID", nil)
+		return
+	}
+
+	roles, err := h.roleService.UnassignRole(c.Request.Context(), uint(id), uint(roleID))
+	if err != nil {
+		respondError(c, h.log, "Failed to unassign role", err)
+		return
+	}
+
+	response.Success(c, "Role unassigned successfully", roles)
+}
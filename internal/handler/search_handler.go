@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"github.com/firdanbash/go-clean-boiler/internal/dto/request"
+	"github.com/firdanbash/go-clean-boiler/internal/service"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"github.com/firdanbash/go-clean-boiler/pkg/response"
+	"github.com/firdanbash/go-clean-boiler/pkg/validator"
+	"github.com/gin-gonic/gin"
+)
+
+type SearchHandler struct {
+	searchService service.SearchService
+	log           logger.Logger
+}
+
+// NewSearchHandler creates a new search handler
+func NewSearchHandler(searchService service.SearchService, log logger.Logger) *SearchHandler {
+	return &SearchHandler{searchService: searchService, log: log}
+}
+
+// SearchUsers godoc
+// @Summary Search users
+// @Description Searches the name and email of users in Elasticsearch or OpenSearch, best matches first. Every word of q must match, the last one as a prefix. The highlight of each hit holds the matching fragments, HTML escaped, with the matches wrapped in <em> tags. Returns 404 when search is not enabled.
+// @Tags search
+// @Produce json
+// @Param q query string false "Words to search for in name and email"
+// @Param role query string false "Filter by role" Enums(user, admin)
+// @Param created_from query string false "Created on or after date (YYYY-MM-DD)"
+// @Param created_to query string false "Created on or before date (YYYY-MM-DD)"
+// @Param page query int false "Page number" default(1)
+// @Param per_page query int false "Items per page" default(10)
+// @Success 200 {object} response.PaginatedResponse{data=[]response.UserSearchHit}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Security BearerAuth
+// @Router /api/v1/admin/search/users [get]
+func (h *SearchHandler) SearchUsers(c *gin.Context) {
+	var req request.SearchUsersRequest
+	if !validator.BindQueryAndValidate(c, &req) {
+		return
+	}
+
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.PerPage < 1 || req.PerPage > 100 {
+		req.PerPage = 10
+	}
+
+	hits, total, err := h.searchService.SearchUsers(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, h.log, "Failed to search users", err)
+		return
+	}
+
+	totalPages := int(total) / req.PerPage
+	if int(total)%req.PerPage > 0 {
+		totalPages++
+	}
+
+	pagination := response.PaginationMeta{
+		CurrentPage: req.Page,
+		PerPage:     req.PerPage,
+		Total:       total,
+		TotalPages:  totalPages,
+	}
+
+	response.Paginated(c, "Users retrieved successfully", hits, pagination)
+}
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/firdanbash/go-clean-boiler/internal/middleware"
+	"github.com/firdanbash/go-clean-boiler/internal/service"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"github.com/firdanbash/go-clean-boiler/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+type SessionHandler struct {
+	sessionService service.SessionService
+	log            logger.Logger
+}
+
+// NewSessionHandler creates a new session handler
+func NewSessionHandler(sessionService service.SessionService, log logger.Logger) *SessionHandler {
+	return &SessionHandler{sessionService: sessionService, log: log}
+}
+
+// GetMySessions godoc
+// @Summary List the devices the current user is signed in on
+// @Tags users
+// @Produce json
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Security BearerAuth
+// @Router /api/v1/users/me/sessions [get]
+func (h *SessionHandler) GetMySessions(c *gin.Context) {
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	sessions, err := h.sessionService.ListForUser(c.Request.Context(), claims.UserID, claims.ID)
+	if err != nil {
+		respondError(c, h.log, "Failed to fetch sessions", err)
+		return
+	}
+
+	response.Success(c, "Sessions retrieved successfully", sessions)
+}
+
+// RevokeMySession godoc
+// @Summary Sign the current user out of one device
+// @Tags users
+// @Produce json
+// @Param id path int true "Session ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Security BearerAuth
+// @Router /api/v1/users/me/sessions/{id} [delete]
+func (h *SessionHandler) RevokeMySession(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid session ID", nil)
+		return
+	}
+
+	if err := h.sessionService.Revoke(c.Request.Context(), userID, uint(id)); err != nil {
+		respondError(c, h.log, "Failed to revoke session", err)
+		return
+	}
+
+	response.Success(c, "Session revoked successfully", nil)
+}
@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/firdanbash/go-clean-boiler/internal/dto/request"
+	"github.com/firdanbash/go-clean-boiler/internal/middleware"
+	"github.com/firdanbash/go-clean-boiler/internal/service"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"github.com/firdanbash/go-clean-boiler/pkg/response"
+	"github.com/firdanbash/go-clean-boiler/pkg/validator"
+	"github.com/gin-gonic/gin"
+)
+
+type TaskHandler struct {
+	taskService service.TaskService
+	log         logger.Logger
+}
+
+// NewTaskHandler creates a new task handler
+func NewTaskHandler(taskService service.TaskService, log logger.Logger) *TaskHandler {
+	return &TaskHandler{taskService: taskService, log: log}
+}
+
+// Get godoc
+// @Summary Get the status of a background task
+// @Description Reports the progress of a task started by a request answered with 202 Accepted and, once it completed, its result or error. Users only see their own tasks.
+// @Tags tasks
+// @Produce json
+// @Param id path int true "Task ID"
+// @Success 200 {object} response.Response{data=response.TaskResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Security BearerAuth
+// @Router /api/v1/tasks/{id} [get]
+func (h *TaskHandler) Get(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	var uri request.TaskIDRequest
+	if !validator.BindUriAndValidate(c, &uri) {
+		return
+	}
+
+	task, err := h.taskService.Get(c.Request.Context(), userID, uri.ID)
+	if err != nil {
+		respondError(c, h.log, "Failed to fetch task", err)
+		return
+	}
+
+	response.Success(c, "Task retrieved successfully", task)
+}
+
+// respondTaskAccepted answers a request started as a background task with
+// 202 Accepted, linking the status of the task in the Location header
+func respondTaskAccepted(c *gin.Context, taskID uint, task interface{}) {
+	c.Header("Location", apiPath(c, "/tasks/"+strconv.FormatUint(uint64(taskID), 10)))
+	response.Accepted(c, "Task accepted", task)
+}
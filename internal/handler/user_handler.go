@@ -1,10 +1,17 @@
 package handler
 
 import (
+	"fmt"
+	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/firdanbash/go-clean-boiler/internal/dto/request"
+	"github.com/firdanbash/go-clean-boiler/internal/middleware"
 	"github.com/firdanbash/go-clean-boiler/internal/service"
+	"github.com/firdanbash/go-clean-boiler/pkg/export"
+	"github.com/firdanbash/go-clean-boiler/pkg/fieldset"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
 	"github.com/firdanbash/go-clean-boiler/pkg/response"
 	"github.com/firdanbash/go-clean-boiler/pkg/validator"
 	"github.com/gin-gonic/gin"
@@ -12,11 +19,13 @@ import (
 
 type UserHandler struct {
 	userService service.UserService
+	taskService service.TaskService
+	log         logger.Logger
 }
 
 // NewUserHandler creates a new user handler
-func NewUserHandler(userService service.UserService) *UserHandler {
-	return &UserHandler{userService: userService}
+func NewUserHandler(userService service.UserService, taskService service.TaskService, log logger.Logger) *UserHandler {
+	return &UserHandler{userService: userService, taskService: taskService, log: log}
 }
 
 // Create godoc
@@ -27,8 +36,10 @@ func NewUserHandler(userService service.UserService) *UserHandler {
 // @Param request body request.CreateUserRequest true "Create user request"
 // @Success 201 {object} response.Response
 // @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 409 {object} response.Response
 // @Security BearerAuth
-// @Router /users [post]
+// @Router /api/v1/users [post]
 func (h *UserHandler) Create(c *gin.Context) {
 	var req request.CreateUserRequest
 
@@ -36,9 +47,9 @@ func (h *UserHandler) Create(c *gin.Context) {
 		return
 	}
 
-	result, err := h.userService.Create(&req)
+	result, err := h.userService.Create(c.Request.Context(), &req)
 	if err != nil {
-		response.BadRequest(c, err.Error(), nil)
+		respondError(c, h.log, "Failed to create user", err)
 		return
 	}
 
@@ -50,40 +61,69 @@ func (h *UserHandler) Create(c *gin.Context) {
 // @Tags users
 // @Produce json
 // @Param page query int false "Page number" default(1)
-// @Param per_page query int false "Items per page" default(10)
+// @Param per_page query int false "Items per page, 100 when ids is set" default(10)
+// @Param ids query string false "Comma separated IDs of the users to get, at most 100 (e.g. 1,2,3)"
+// @Param search query string false "Search in name and email"
+// @Param q query string false "Full-text search in name and email, best matches first unless sorted"
+// @Param email query string false "Filter by exact email"
+// @Param created_from query string false "Created on or after date (YYYY-MM-DD)"
+// @Param created_to query string false "Created on or before date (YYYY-MM-DD)"
+// @Param include_deleted query bool false "Include soft deleted users"
+// @Param sort query string false "Sort fields, prefix with - for descending (e.g. name,-created_at)"
+// @Param fields query string false "Comma separated fields to return (id,email,name,role,avatar_url,mfa_enabled,created_at,updated_at,deleted_at)"
 // @Success 200 {object} response.PaginatedResponse
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
 // @Security BearerAuth
-// @Router /users [get]
+// @Router /api/v1/users [get]
 func (h *UserHandler) GetAll(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "10"))
+	var req request.ListUsersRequest
+	if !validator.BindQueryAndValidate(c, &req) {
+		return
+	}
 
-	if page < 1 {
-		page = 1
+	fields, err := fieldset.Parse(req.Fields, service.UserFields)
+	if err != nil {
+		respondError(c, h.log, "Failed to fetch users", err)
+		return
 	}
-	if perPage < 1 || perPage > 100 {
-		perPage = 10
+
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.PerPage < 1 || req.PerPage > 100 {
+		req.PerPage = 10
+		// Users listed by ID all fit in one page
+		if req.IDs != "" {
+			req.PerPage = service.MaxUserIDs
+		}
 	}
 
-	users, total, err := h.userService.GetAll(page, perPage)
+	users, total, err := h.userService.GetAll(c.Request.Context(), &req)
 	if err != nil {
-		response.InternalServerError(c, "Failed to fetch users", err.Error())
+		respondError(c, h.log, "Failed to fetch users", err)
 		return
 	}
 
-	totalPages := int(total) / perPage
-	if int(total)%perPage > 0 {
+	totalPages := int(total) / req.PerPage
+	if int(total)%req.PerPage > 0 {
 		totalPages++
 	}
 
 	pagination := response.PaginationMeta{
-		CurrentPage: page,
-		PerPage:     perPage,
+		CurrentPage: req.Page,
+		PerPage:     req.PerPage,
 		Total:       total,
 		TotalPages:  totalPages,
 	}
 
-	response.Paginated(c, "Users retrieved successfully", users, pagination)
+	data, err := fields.Apply(users)
+	if err != nil {
+		respondError(c, h.log, "Failed to fetch users", err)
+		return
+	}
+
+	response.Paginated(c, "Users retrieved successfully", data, pagination)
 }
 
 // GetByID godoc
@@ -91,24 +131,26 @@ func (h *UserHandler) GetAll(c *gin.Context) {
 // @Tags users
 // @Produce json
 // @Param id path int true "User ID"
+// @Param fields query string false "Comma separated fields to return (id,email,name,role,avatar_url,mfa_enabled,created_at,updated_at,deleted_at)"
 // @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
 // @Failure 404 {object} response.Response
 // @Security BearerAuth
-// @Router /users/{id} [get]
+// @Router /api/v1/users/{id} [get]
 func (h *UserHandler) GetByID(c *gin.Context) {
-	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
-	if err != nil {
-		response.BadRequest(c, "Invalid user ID", nil)
+	var uri request.UserIDRequest
+	if !validator.BindUriAndValidate(c, &uri) {
 		return
 	}
 
-	user, err := h.userService.GetByID(uint(id))
+	user, err := h.userService.GetByID(c.Request.Context(), uri.ID)
 	if err != nil {
-		response.NotFound(c, err.Error())
+		respondError(c, h.log, "Failed to fetch user", err)
 		return
 	}
 
-	response.Success(c, "User retrieved successfully", user)
+	h.respondUser(c, user)
 }
 
 // Update godoc
@@ -120,13 +162,14 @@ func (h *UserHandler) GetByID(c *gin.Context) {
 // @Param request body request.UpdateUserRequest true "Update user request"
 // @Success 200 {object} response.Response
 // @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
 // @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
 // @Security BearerAuth
-// @Router /users/{id} [put]
+// @Router /api/v1/users/{id} [put]
 func (h *UserHandler) Update(c *gin.Context) {
-	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
-	if err != nil {
-		response.BadRequest(c, "Invalid user ID", nil)
+	var uri request.UserIDRequest
+	if !validator.BindUriAndValidate(c, &uri) {
 		return
 	}
 
@@ -135,9 +178,9 @@ func (h *UserHandler) Update(c *gin.Context) {
 		return
 	}
 
-	user, err := h.userService.Update(uint(id), &req)
+	user, err := h.userService.Update(c.Request.Context(), uri.ID, &req)
 	if err != nil {
-		response.BadRequest(c, err.Error(), nil)
+		respondError(c, h.log, "Failed to update user", err)
 		return
 	}
 
@@ -150,20 +193,360 @@ func (h *UserHandler) Update(c *gin.Context) {
 // @Produce json
 // @Param id path int true "User ID"
 // @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
 // @Failure 404 {object} response.Response
 // @Security BearerAuth
-// @Router /users/{id} [delete]
+// @Router /api/v1/users/{id} [delete]
 func (h *UserHandler) Delete(c *gin.Context) {
-	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	var uri request.UserIDRequest
+	if !validator.BindUriAndValidate(c, &uri) {
+		return
+	}
+
+	if err := h.userService.Delete(c.Request.Context(), uri.ID); err != nil {
+		respondError(c, h.log, "Failed to delete user", err)
+		return
+	}
+
+	response.Success(c, "User deleted successfully", nil)
+}
+
+// BulkDelete godoc
+// @Summary Delete several users
+// @Description Soft deletes the users in the background and answers with 202 and the task, whose status is linked in the Location header. The result of the task counts the deleted users and lists those that could not be deleted.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body request.BulkDeleteUsersRequest true "Bulk delete users request"
+// @Success 202 {object} response.Response{data=response.TaskResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Security BearerAuth
+// @Router /api/v1/users/bulk-delete [post]
+func (h *UserHandler) BulkDelete(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	var req request.BulkDeleteUsersRequest
+	if !validator.BindAndValidate(c, &req) {
+		return
+	}
+
+	task, err := h.taskService.Enqueue(c.Request.Context(), userID, service.TaskDeleteUsers, &req)
+	if err != nil {
+		respondError(c, h.log, "Failed to delete users", err)
+		return
+	}
+
+	respondTaskAccepted(c, task.ID, task)
+}
+
+// ChangePassword godoc
+// @Summary Change the current user's password
+// @Description Verifies the current password and signs out all existing sessions
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body request.ChangePasswordRequest true "Change password request"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Security BearerAuth
+// @Router /api/v1/users/me/password [put]
+func (h *UserHandler) ChangePassword(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	var req request.ChangePasswordRequest
+	if !validator.BindAndValidate(c, &req) {
+		return
+	}
+
+	if err := h.userService.ChangePassword(c.Request.Context(), userID, req.CurrentPassword, req.NewPassword); err != nil {
+		respondError(c, h.log, "Failed to change password", err)
+		return
+	}
+
+	response.Success(c, "Password changed successfully, please login again", nil)
+}
+
+// Restore godoc
+// @Summary Restore a soft deleted user
+// @Tags users
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Security BearerAuth
+// @Router /api/v1/users/{id}/restore [post]
+func (h *UserHandler) Restore(c *gin.Context) {
+	var uri request.UserIDRequest
+	if !validator.BindUriAndValidate(c, &uri) {
+		return
+	}
+
+	user, err := h.userService.Restore(c.Request.Context(), uri.ID)
 	if err != nil {
-		response.BadRequest(c, "Invalid user ID", nil)
+		respondError(c, h.log, "Failed to restore user", err)
 		return
 	}
 
-	if err := h.userService.Delete(uint(id)); err != nil {
-		response.NotFound(c, err.Error())
+	response.Success(c, "User restored successfully", user)
+}
+
+// HardDelete godoc
+// @Summary Permanently delete user
+// @Tags users
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Security BearerAuth
+// @Router /api/v1/users/{id}/permanent [delete]
+func (h *UserHandler) HardDelete(c *gin.Context) {
+	var uri request.UserIDRequest
+	if !validator.BindUriAndValidate(c, &uri) {
+		return
+	}
+
+	if err := h.userService.HardDelete(c.Request.Context(), uri.ID); err != nil {
+		respondError(c, h.log, "Failed to delete user", err)
+		return
+	}
+
+	response.Success(c, "User permanently deleted", nil)
+}
+
+// GetMe godoc
+// @Summary Get the current user
+// @Tags users
+// @Produce json
+// @Param fields query string false "Comma separated fields to return (id,email,name,role,avatar_url,mfa_enabled,created_at,updated_at,deleted_at)"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Security BearerAuth
+// @Router /api/v1/users/me [get]
+func (h *UserHandler) GetMe(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	user, err := h.userService.GetByID(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, h.log, "Failed to fetch user", err)
+		return
+	}
+
+	h.respondUser(c, user)
+}
+
+// UpdateMe godoc
+// @Summary Update the current user
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body request.UpdateUserRequest true "Update user request"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Security BearerAuth
+// @Router /api/v1/users/me [put]
+func (h *UserHandler) UpdateMe(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	var req request.UpdateUserRequest
+	if !validator.BindAndValidate(c, &req) {
+		return
+	}
+
+	user, err := h.userService.Update(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondError(c, h.log, "Failed to update user", err)
+		return
+	}
+
+	response.Success(c, "User updated successfully", user)
+}
+
+// DeleteMe godoc
+// @Summary Delete the current user
+// @Tags users
+// @Produce json
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Security BearerAuth
+// @Router /api/v1/users/me [delete]
+func (h *UserHandler) DeleteMe(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	if err := h.userService.Delete(c.Request.Context(), userID); err != nil {
+		respondError(c, h.log, "Failed to delete user", err)
 		return
 	}
 
 	response.Success(c, "User deleted successfully", nil)
 }
+
+// UploadAvatar godoc
+// @Summary Upload the current user's avatar
+// @Tags users
+// @Accept multipart/form-data
+// @Produce json
+// @Param avatar formData file true "Avatar image (JPEG, PNG or GIF)"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Security BearerAuth
+// @Router /api/v1/users/me/avatar [post]
+func (h *UserHandler) UploadAvatar(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	fileHeader, err := c.FormFile("avatar")
+	if err != nil {
+		response.BadRequest(c, "Avatar file is required", err.Error())
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		response.BadRequest(c, "Invalid avatar file", err.Error())
+		return
+	}
+	defer file.Close()
+
+	user, err := h.userService.UpdateAvatar(c.Request.Context(), userID, file, fileHeader.Size)
+	if err != nil {
+		respondError(c, h.log, "Failed to upload avatar", err)
+		return
+	}
+
+	response.Success(c, "Avatar uploaded successfully", user)
+}
+
+// GetAvatar godoc
+// @Summary Get a user's avatar image
+// @Tags users
+// @Produce image/jpeg,image/png,image/gif
+// @Param id path int true "User ID"
+// @Param size query int false "Resize so the longest side is at most this many pixels (16-1024)"
+// @Success 200 {file} binary
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Security BearerAuth
+// @Router /api/v1/users/{id}/avatar [get]
+func (h *UserHandler) GetAvatar(c *gin.Context) {
+	var uri request.UserIDRequest
+	if !validator.BindUriAndValidate(c, &uri) {
+		return
+	}
+
+	size, _ := strconv.Atoi(c.DefaultQuery("size", "0"))
+	if size != 0 && (size < 16 || size > 1024) {
+		response.BadRequest(c, "Size must be between 16 and 1024", nil)
+		return
+	}
+
+	data, contentType, err := h.userService.GetAvatar(c.Request.Context(), uri.ID, size)
+	if err != nil {
+		respondError(c, h.log, "Failed to fetch avatar", err)
+		return
+	}
+
+	c.Header("Cache-Control", "private, max-age=3600")
+	c.Data(http.StatusOK, contentType, data)
+}
+
+// Export godoc
+// @Summary Export users as CSV or Excel
+// @Tags users
+// @Produce text/csv,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param format query string false "Export format (csv or xlsx)" default(csv)
+// @Param fields query string false "Comma separated columns (id,email,name,avatar_url,mfa_enabled,created_at,updated_at)"
+// @Param ids query string false "Comma separated IDs of the users to export, at most 100"
+// @Param search query string false "Search in name and email"
+// @Param q query string false "Full-text search in name and email"
+// @Param email query string false "Filter by exact email"
+// @Param created_from query string false "Created on or after date (YYYY-MM-DD)"
+// @Param created_to query string false "Created on or before date (YYYY-MM-DD)"
+// @Param include_deleted query bool false "Include soft deleted users"
+// @Success 200 {file} binary
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Security BearerAuth
+// @Router /api/v1/users/export [get]
+func (h *UserHandler) Export(c *gin.Context) {
+	var req request.ExportUsersRequest
+	if !validator.BindQueryAndValidate(c, &req) {
+		return
+	}
+
+	if req.Format == "" {
+		req.Format = export.FormatCSV
+	}
+
+	writer, err := export.NewWriter(req.Format, c.Writer)
+	if err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	filename := fmt.Sprintf("users-%s.%s", time.Now().Format("20060102-150405"), req.Format)
+	c.Header("Content-Type", export.ContentType(req.Format))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	if err := h.userService.Export(c.Request.Context(), &req, writer); err != nil {
+		// Once streaming has started the status line is already sent
+		if c.Writer.Written() {
+			_ = c.Error(err)
+			return
+		}
+
+		c.Writer.Header().Del("Content-Type")
+		c.Writer.Header().Del("Content-Disposition")
+		respondError(c, h.log, "Failed to export users", err)
+	}
+}
+
+// respondUser sends a retrieved user, trimmed to the fields query parameter.
+// The user is read whole, by ID and through the cache, so only the response
+// is trimmed.
+func (h *UserHandler) respondUser(c *gin.Context, user interface{}) {
+	fields, err := fieldset.Parse(c.Query("fields"), service.UserFields)
+	if err != nil {
+		respondError(c, h.log, "Failed to fetch user", err)
+		return
+	}
+	data, err := fields.Apply(user)
+	if err != nil {
+		respondError(c, h.log, "Failed to fetch user", err)
+		return
+	}
+	response.Success(c, "User retrieved successfully", data)
+}
@@ -0,0 +1,406 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/dto/request"
+	"github.com/firdanbash/go-clean-boiler/internal/dto/response"
+	"github.com/firdanbash/go-clean-boiler/internal/handler"
+	"github.com/firdanbash/go-clean-boiler/internal/middleware"
+	"github.com/firdanbash/go-clean-boiler/internal/mocks"
+	"github.com/firdanbash/go-clean-boiler/internal/service"
+	"github.com/firdanbash/go-clean-boiler/internal/testutil"
+	"github.com/firdanbash/go-clean-boiler/pkg/apperror"
+	"github.com/firdanbash/go-clean-boiler/pkg/jwt"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"go.uber.org/mock/gomock"
+)
+
+// newUserHandlerClient serves the user routes with a mocked service
+func newUserHandlerClient(t *testing.T) (*testutil.Client, *mocks.MockUserService, *jwt.Manager) {
+	t.Helper()
+	return newUserHandlerClientWithLogger(t, logger.Nop())
+}
+
+// newUserHandlerClientWithLogger is newUserHandlerClient with the handler logging to log
+func newUserHandlerClientWithLogger(t *testing.T, log logger.Logger) (*testutil.Client, *mocks.MockUserService, *jwt.Manager) {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+	svc := mocks.NewMockUserService(ctrl)
+	h := handler.NewUserHandler(svc, mocks.NewMockTaskService(ctrl), log)
+	m := testutil.JWTManager(t)
+
+	r, authed := testutil.Router(m)
+	authed.GET("/users/me", h.GetMe)
+	authed.GET("/users/:id", h.GetByID)
+	admin := authed.Group("", middleware.RequireRole(domain.RoleAdmin))
+	admin.GET("/users", h.GetAll)
+	admin.POST("/users", h.Create)
+	admin.PUT("/users/:id", h.Update)
+
+	return testutil.NewClient(t, r), svc, m
+}
+
+func TestUserHandlerGetMe(t *testing.T) {
+	client, svc, m := newUserHandlerClient(t)
+	user := testutil.NewUser(testutil.WithID(5))
+
+	svc.EXPECT().GetByID(gomock.Any(), uint(5)).Return(&response.UserResponse{ID: 5, Email: user.Email}, nil)
+
+	var got response.UserResponse
+	client.AsUser(m, user).Get("/users/me").
+		AssertStatus(http.StatusOK).
+		AssertSuccess().
+		Decode(&got)
+	if got.Email != user.Email {
+		t.Errorf("email = %q, want %q", got.Email, user.Email)
+	}
+}
+
+func TestUserHandlerRequiresToken(t *testing.T) {
+	client, _, _ := newUserHandlerClient(t)
+
+	client.Get("/users/me").AssertError(http.StatusUnauthorized)
+	client.WithToken("not-a-token").Get("/users/me").AssertError(http.StatusUnauthorized)
+}
+
+func TestUserHandlerGetByID(t *testing.T) {
+	client, svc, m := newUserHandlerClient(t)
+	client = client.AsUser(m, testutil.NewUser(testutil.WithID(1)))
+
+	client.Get("/users/abc").AssertError(http.StatusBadRequest).AssertMessage("Invalid path parameters")
+	client.Get("/users/0").AssertError(http.StatusBadRequest).AssertCode(apperror.CodeValidationFailed)
+
+	svc.EXPECT().GetByID(gomock.Any(), uint(9)).Return(nil, service.ErrUserNotFound)
+	client.Get("/users/9").AssertError(http.StatusNotFound).AssertMessage("user not found")
+
+	svc.EXPECT().GetByID(gomock.Any(), uint(10)).Return(nil, errors.New("connection refused"))
+	client.Get("/users/10").AssertError(http.StatusInternalServerError).AssertMessage("Failed to fetch user")
+}
+
+func TestUserHandlerUpdateMapsErrors(t *testing.T) {
+	client, svc, m := newUserHandlerClient(t)
+	admin := client.AsUser(m, testutil.NewUser(testutil.WithID(1), testutil.AsAdmin()))
+	body := map[string]string{"name": "Renamed"}
+
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   apperror.Code
+	}{
+		{"missing user", service.ErrUserNotFound, http.StatusNotFound, apperror.CodeUserNotFound},
+		{"taken email", service.ErrEmailExists, http.StatusConflict, apperror.CodeUserEmailTaken},
+		{"wrapped application error", fmt.Errorf("update: %w", service.ErrUserNotFound), http.StatusNotFound, apperror.CodeUserNotFound},
+		{"error without a code", apperror.Conflict("version mismatch"), http.StatusConflict, apperror.CodeConflict},
+		{"unexpected failure", errors.New("deadlock detected"), http.StatusInternalServerError, apperror.CodeInternal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc.EXPECT().Update(gomock.Any(), uint(2), gomock.Any()).Return(nil, tt.err)
+			admin.Put("/users/2", body).AssertError(tt.status).AssertCode(tt.code)
+		})
+	}
+}
+
+func TestUserHandlerUpdateTellsAbsentFromEmpty(t *testing.T) {
+	client, svc, m := newUserHandlerClient(t)
+	admin := client.AsUser(m, testutil.NewUser(testutil.WithID(1), testutil.AsAdmin()))
+
+	t.Run("validates a field set to an empty value", func(t *testing.T) {
+		admin.Put("/users/2", map[string]string{"name": ""}).
+			AssertError(http.StatusBadRequest).
+			AssertCode(apperror.CodeValidationFailed).
+			AssertMessage("Validation failed")
+	})
+
+	t.Run("leaves omitted and null fields unset", func(t *testing.T) {
+		svc.EXPECT().Update(gomock.Any(), uint(2), gomock.Any()).DoAndReturn(
+			func(_ context.Context, _ uint, req *request.UpdateUserRequest) (*response.UserResponse, error) {
+				if req.Email != nil || req.Name == nil || *req.Name != "Renamed" {
+					t.Errorf("Update() request = %+v, want only the name set", req)
+				}
+				return &response.UserResponse{ID: 2, Name: "Renamed"}, nil
+			})
+		admin.Put("/users/2", map[string]interface{}{"email": nil, "name": "Renamed"}).AssertSuccess()
+	})
+}
+
+func TestUserHandlerCreate(t *testing.T) {
+	client, svc, m := newUserHandlerClient(t)
+	admin := client.AsUser(m, testutil.NewUser(testutil.WithID(1), testutil.AsAdmin()))
+
+	t.Run("validates the body", func(t *testing.T) {
+		admin.Post("/users", map[string]string{"email": "not-an-email"}).
+			AssertError(http.StatusBadRequest).
+			AssertMessage("Validation failed")
+	})
+
+	t.Run("rejects a weak password", func(t *testing.T) {
+		req := request.CreateUserRequest{Email: "new@example.com", Password: "letmein", Name: "New User"}
+		resp := admin.Post("/users", req).AssertError(http.StatusBadRequest)
+
+		var errs map[string]string
+		if err := json.Unmarshal(resp.Error, &errs); err != nil {
+			t.Fatalf("decode errors: %v", err)
+		}
+		if want := "password must be at least 8 characters long and contain a lowercase letter, a digit"; errs["password"] != want {
+			t.Errorf("password error = %q, want %q", errs["password"], want)
+		}
+	})
+
+	t.Run("creates the user", func(t *testing.T) {
+		req := request.CreateUserRequest{Email: "new@example.com", Password: "secret123", Name: "New User"}
+		svc.EXPECT().Create(gomock.Any(), &req).Return(&response.UserResponse{ID: 2, Email: req.Email, Name: req.Name}, nil)
+
+		admin.Post("/users", req).
+			AssertStatus(http.StatusCreated).
+			AssertMessage("User created successfully")
+	})
+
+	t.Run("is admin only", func(t *testing.T) {
+		client.AsUser(m, testutil.NewUser(testutil.WithID(3))).
+			Post("/users", map[string]string{"email": "new@example.com"}).
+			AssertError(http.StatusForbidden)
+	})
+}
+
+func TestUserHandlerGetAllPaginates(t *testing.T) {
+	client, svc, m := newUserHandlerClient(t)
+	admin := client.AsUser(m, testutil.NewUser(testutil.WithID(1), testutil.AsAdmin()))
+
+	svc.EXPECT().GetAll(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, req *request.ListUsersRequest) ([]response.UserResponse, int64, error) {
+			if req.Page != 2 || req.PerPage != 10 {
+				t.Errorf("page %d per page %d, want 2 and 10", req.Page, req.PerPage)
+			}
+			return make([]response.UserResponse, 10), 25, nil
+		})
+
+	resp := admin.Get("/users?page=2").AssertStatus(http.StatusOK).AssertTotal(25)
+	if resp.Pagination.TotalPages != 3 {
+		t.Errorf("total pages = %d, want 3", resp.Pagination.TotalPages)
+	}
+}
+
+func TestUserHandlerGetAllValidatesQuery(t *testing.T) {
+	client, _, m := newUserHandlerClient(t)
+	admin := client.AsUser(m, testutil.NewUser(testutil.WithID(1), testutil.AsAdmin()))
+
+	admin.Get("/users?page=two").AssertError(http.StatusBadRequest).AssertMessage("Invalid query parameters")
+	admin.Get("/users?email=not-an-email").
+		AssertError(http.StatusBadRequest).
+		AssertCode(apperror.CodeValidationFailed)
+}
+
+func TestUserHandlerGetAllByIDsFitsOnePage(t *testing.T) {
+	client, svc, m := newUserHandlerClient(t)
+	admin := client.AsUser(m, testutil.NewUser(testutil.WithID(1), testutil.AsAdmin()))
+
+	svc.EXPECT().GetAll(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, req *request.ListUsersRequest) ([]response.UserResponse, int64, error) {
+			if req.IDs != "1,2,3" || req.PerPage != service.MaxUserIDs {
+				t.Errorf("ids %q per page %d, want 1,2,3 and %d", req.IDs, req.PerPage, service.MaxUserIDs)
+			}
+			return make([]response.UserResponse, 3), 3, nil
+		})
+
+	admin.Get("/users?ids=1,2,3").AssertStatus(http.StatusOK).AssertTotal(3)
+}
+
+func TestUserHandlerTrimsToFields(t *testing.T) {
+	client, svc, m := newUserHandlerClient(t)
+	admin := client.AsUser(m, testutil.NewUser(testutil.WithID(1), testutil.AsAdmin()))
+	user := response.UserResponse{ID: 2, Email: "jane@example.com", Name: "Jane", Role: domain.RoleUser}
+
+	t.Run("lists", func(t *testing.T) {
+		svc.EXPECT().GetAll(gomock.Any(), gomock.Any()).Return([]response.UserResponse{user}, int64(1), nil)
+
+		var got []map[string]interface{}
+		admin.Get("/users?fields=id,name").AssertSuccess().Decode(&got)
+		if len(got) != 1 || len(got[0]) != 2 || got[0]["id"] != float64(2) || got[0]["name"] != "Jane" {
+			t.Errorf("data = %v, want only the id and name", got)
+		}
+	})
+
+	t.Run("details", func(t *testing.T) {
+		svc.EXPECT().GetByID(gomock.Any(), uint(2)).Return(&user, nil)
+
+		var got map[string]interface{}
+		admin.Get("/users/2?fields=email").AssertSuccess().Decode(&got)
+		if len(got) != 1 || got["email"] != user.Email {
+			t.Errorf("data = %v, want only the email", got)
+		}
+	})
+
+	t.Run("rejects an unknown field", func(t *testing.T) {
+		svc.EXPECT().GetByID(gomock.Any(), uint(2)).Return(&user, nil)
+
+		admin.Get("/users/2?fields=password").AssertError(http.StatusBadRequest).AssertMessage("unknown field")
+	})
+}
+
+func TestUserHandlerLinksPages(t *testing.T) {
+	client, svc, m := newUserHandlerClient(t)
+	admin := client.AsUser(m, testutil.NewUser(testutil.WithID(1), testutil.AsAdmin()))
+
+	svc.EXPECT().GetAll(gomock.Any(), gomock.Any()).Return(make([]response.UserResponse, 10), int64(42), nil)
+
+	resp := admin.Get("/users?page=2&search=jo").AssertSuccess().AssertTotal(42)
+	if got := resp.Header.Get("X-Total-Count"); got != "42" {
+		t.Errorf("X-Total-Count = %q, want 42", got)
+	}
+	want := `</users?page=1&search=jo>; rel="first", </users?page=1&search=jo>; rel="prev", ` +
+		`</users?page=3&search=jo>; rel="next", </users?page=5&search=jo>; rel="last"`
+	if got := resp.Header.Get("Link"); got != want {
+		t.Errorf("Link = %s, want %s", got, want)
+	}
+}
+
+func TestUserHandlerWithoutEnvelope(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	svc := mocks.NewMockUserService(ctrl)
+	h := handler.NewUserHandler(svc, mocks.NewMockTaskService(ctrl), logger.Nop())
+	m := testutil.JWTManager(t)
+
+	r, authed := testutil.Router(m)
+	authed.Use(middleware.RawResponses())
+	authed.GET("/users", h.GetAll)
+	authed.GET("/users/:id", h.GetByID)
+	client := testutil.NewClient(t, r).AsUser(m, testutil.NewUser(testutil.WithID(1)))
+
+	t.Run("sends a list as an array with pagination headers", func(t *testing.T) {
+		svc.EXPECT().GetAll(gomock.Any(), gomock.Any()).Return([]response.UserResponse{{ID: 11}, {ID: 12}}, int64(42), nil)
+
+		resp := client.Get("/users?page=2&per_page=2").AssertStatus(http.StatusOK)
+		var got []response.UserResponse
+		if err := json.Unmarshal(resp.Body, &got); err != nil || len(got) != 2 {
+			t.Fatalf("body = %s, want an array of 2 users", resp.Body)
+		}
+		for header, want := range map[string]string{"X-Total-Count": "42", "X-Page": "2", "X-Per-Page": "2", "X-Total-Pages": "21"} {
+			if resp.Header.Get(header) != want {
+				t.Errorf("%s = %q, want %q", header, resp.Header.Get(header), want)
+			}
+		}
+	})
+
+	t.Run("sends a resource as is", func(t *testing.T) {
+		svc.EXPECT().GetByID(gomock.Any(), uint(11)).Return(&response.UserResponse{ID: 11, Email: "jane@example.com"}, nil)
+
+		resp := client.Get("/users/11").AssertStatus(http.StatusOK)
+		var got response.UserResponse
+		if err := json.Unmarshal(resp.Body, &got); err != nil || got.Email != "jane@example.com" {
+			t.Errorf("body = %s, want the user", resp.Body)
+		}
+	})
+
+	t.Run("keeps the body of errors", func(t *testing.T) {
+		svc.EXPECT().GetByID(gomock.Any(), uint(9)).Return(nil, service.ErrUserNotFound)
+
+		client.Get("/users/9").AssertError(http.StatusNotFound).AssertMessage("user not found")
+	})
+}
+
+func TestUserHandlerNegotiatesFormats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	svc := mocks.NewMockUserService(ctrl)
+	h := handler.NewUserHandler(svc, mocks.NewMockTaskService(ctrl), logger.Nop())
+	m := testutil.JWTManager(t)
+
+	r, authed := testutil.Router(m)
+	authed.Use(middleware.FormatMiddleware(true))
+	authed.POST("/users", h.Create)
+	client := testutil.NewClient(t, r).AsUser(m, testutil.NewUser(testutil.WithID(1), testutil.AsAdmin()))
+	want := request.CreateUserRequest{Email: "new@example.com", Password: "secret123", Name: "New User"}
+
+	t.Run("binds a form and answers in XML", func(t *testing.T) {
+		svc.EXPECT().Create(gomock.Any(), &want).Return(&response.UserResponse{ID: 2, Email: want.Email}, nil)
+
+		form := strings.NewReader("email=new%40example.com&password=secret123&name=New+User")
+		resp := client.WithHeader("Content-Type", "application/x-www-form-urlencoded").
+			WithHeader("Accept", "application/xml").
+			Post("/users", form).
+			AssertStatus(http.StatusCreated)
+		if got := resp.Header.Get("Content-Type"); !strings.HasPrefix(got, "application/xml") {
+			t.Errorf("Content-Type = %q, want application/xml", got)
+		}
+		if !strings.Contains(string(resp.Body), "<data><id>2</id><email>new@example.com</email>") {
+			t.Errorf("body = %s, want the user in XML", resp.Body)
+		}
+	})
+
+	t.Run("binds XML", func(t *testing.T) {
+		svc.EXPECT().Create(gomock.Any(), &want).Return(&response.UserResponse{ID: 2}, nil)
+
+		body := strings.NewReader("<user><email>new@example.com</email><password>secret123</password><name>New User</name></user>")
+		client.WithHeader("Content-Type", "application/xml").
+			Post("/users", body).
+			AssertStatus(http.StatusCreated).
+			AssertSuccess()
+	})
+
+	t.Run("sends errors in XML", func(t *testing.T) {
+		resp := client.WithHeader("Accept", "application/xml").
+			Post("/users", map[string]string{"email": "not-an-email"}).
+			AssertStatus(http.StatusBadRequest)
+		if !strings.Contains(string(resp.Body), "<code>VALIDATION_FAILED</code>") {
+			t.Errorf("body = %s, want the error code in XML", resp.Body)
+		}
+	})
+}
+
+func TestUserHandlerBulkDeleteStartsTask(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	tasks := mocks.NewMockTaskService(ctrl)
+	h := handler.NewUserHandler(mocks.NewMockUserService(ctrl), tasks, logger.Nop())
+	m := testutil.JWTManager(t)
+
+	r, authed := testutil.Router(m)
+	authed.Group("/api/v2").POST("/users/bulk-delete", h.BulkDelete)
+	client := testutil.NewClient(t, r).AsUser(m, testutil.NewUser(testutil.WithID(1), testutil.AsAdmin()))
+
+	client.Post("/api/v2/users/bulk-delete", map[string][]uint{"ids": {}}).
+		AssertError(http.StatusBadRequest).
+		AssertCode(apperror.CodeValidationFailed)
+
+	req := &request.BulkDeleteUsersRequest{IDs: []uint{2, 3}}
+	tasks.EXPECT().Enqueue(gomock.Any(), uint(1), service.TaskDeleteUsers, req).
+		Return(&response.TaskResponse{ID: 7, Type: service.TaskDeleteUsers, Status: "pending"}, nil)
+
+	var task response.TaskResponse
+	resp := client.Post("/api/v2/users/bulk-delete", req).
+		AssertStatus(http.StatusAccepted).
+		Decode(&task)
+	if task.ID != 7 || task.Status != "pending" {
+		t.Errorf("task = %+v, want pending task 7", task)
+	}
+	// The task is linked on the API version of the request
+	if got := resp.Header.Get("Location"); got != "/api/v2/tasks/7" {
+		t.Errorf("Location = %q, want /api/v2/tasks/7", got)
+	}
+}
+
+func TestUserHandlerGetAllLogsFailures(t *testing.T) {
+	log, logs := testutil.Logger()
+	client, svc, m := newUserHandlerClientWithLogger(t, log)
+	admin := client.AsUser(m, testutil.NewUser(testutil.WithID(1), testutil.AsAdmin()))
+
+	svc.EXPECT().GetAll(gomock.Any(), gomock.Any()).Return(nil, int64(0), errors.New("connection refused"))
+
+	admin.Get("/users").AssertError(http.StatusInternalServerError)
+
+	entries := logs.FilterMessage("Failed to fetch users").All()
+	if len(entries) != 1 {
+		t.Fatalf("logged %d failure entries, want 1", len(entries))
+	}
+	if got := entries[0].ContextMap()["error"]; got != "connection refused" {
+		t.Errorf("logged error = %v, want connection refused", got)
+	}
+}
@@ -0,0 +1,229 @@
+// Package job defines the recurring jobs of the API. Jobs are registered here
+// in code; their schedules come from scheduler.jobs in the configuration.
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/firdanbash/go-clean-boiler/internal/repository"
+	"github.com/firdanbash/go-clean-boiler/internal/service"
+	"github.com/firdanbash/go-clean-boiler/pkg/config"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"github.com/firdanbash/go-clean-boiler/pkg/scheduler"
+	"go.uber.org/zap"
+)
+
+// Job names, as used under scheduler.jobs in the configuration
+const (
+	PurgePasswordResetTokens = "purge_password_reset_tokens"
+	PurgeRevokedTokens       = "purge_revoked_tokens"
+	PurgeSessions            = "purge_sessions"
+	PurgeDataExports         = "purge_data_exports"
+	PurgeDeletedUsers        = "purge_deleted_users"
+	PurgeOutbox              = "purge_outbox"
+	PurgeWebhookDeliveries   = "purge_webhook_deliveries"
+	PurgeTasks               = "purge_tasks"
+	FailStuckTasks           = "fail_stuck_tasks"
+	PrunePasswordHistory     = "prune_password_history"
+)
+
+// jobs holds the dependencies of the job functions
+type jobs struct {
+	users       service.UserService
+	passwords   service.PasswordHistoryService
+	exports     service.DataExportService
+	tasks       service.TaskService
+	resetTokens repository.PasswordResetTokenRepository
+	revoked     repository.RevokedTokenRepository
+	sessions    repository.SessionRepository
+	outbox      repository.OutboxRepository
+	deliveries  repository.WebhookDeliveryRepository
+	cfg         config.SchedulerConfig
+	log         logger.Logger
+}
+
+// NewScheduler creates a scheduler with every job that has a schedule configured
+func NewScheduler(
+	users service.UserService,
+	passwords service.PasswordHistoryService,
+	exports service.DataExportService,
+	tasks service.TaskService,
+	resetTokens repository.PasswordResetTokenRepository,
+	revoked repository.RevokedTokenRepository,
+	sessions repository.SessionRepository,
+	outbox repository.OutboxRepository,
+	deliveries repository.WebhookDeliveryRepository,
+	cfg *config.Config,
+	log logger.Logger,
+) (*scheduler.Scheduler, error) {
+	j := &jobs{
+		users:       users,
+		passwords:   passwords,
+		exports:     exports,
+		tasks:       tasks,
+		resetTokens: resetTokens,
+		revoked:     revoked,
+		sessions:    sessions,
+		outbox:      outbox,
+		deliveries:  deliveries,
+		cfg:         cfg.Scheduler,
+		log:         log,
+	}
+	registry := map[string]scheduler.JobFunc{
+		PurgePasswordResetTokens: j.purgePasswordResetTokens,
+		PurgeRevokedTokens:       j.purgeRevokedTokens,
+		PurgeSessions:            j.purgeSessions,
+		PurgeDataExports:         j.purgeDataExports,
+		PurgeDeletedUsers:        j.purgeDeletedUsers,
+		PurgeOutbox:              j.purgeOutbox,
+		PurgeWebhookDeliveries:   j.purgeWebhookDeliveries,
+		PurgeTasks:               j.purgeTasks,
+		FailStuckTasks:           j.failStuckTasks,
+		PrunePasswordHistory:     j.prunePasswordHistory,
+	}
+
+	s := scheduler.New(log)
+	for name, jobCfg := range cfg.Scheduler.Jobs {
+		fn, ok := registry[name]
+		if !ok {
+			log.Warn("Ignoring schedule of unknown job", zap.String("job", name))
+			continue
+		}
+		if jobCfg.Schedule == "" {
+			log.Info("Job disabled", zap.String("job", name))
+			continue
+		}
+		if err := s.Add(name, jobCfg.Schedule, fn); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// purgePasswordResetTokens deletes expired and used password reset tokens
+func (j *jobs) purgePasswordResetTokens(ctx context.Context) error {
+	deleted, err := j.resetTokens.DeleteExpired(ctx)
+	if err != nil {
+		return err
+	}
+	j.log.Info("Purged password reset tokens", zap.Int64("deleted", deleted))
+	return nil
+}
+
+// purgeRevokedTokens deletes denylist entries of tokens that have expired anyway
+func (j *jobs) purgeRevokedTokens(ctx context.Context) error {
+	deleted, err := j.revoked.DeleteExpired(ctx)
+	if err != nil {
+		return err
+	}
+	j.log.Info("Purged revoked tokens", zap.Int64("deleted", deleted))
+	return nil
+}
+
+// purgeSessions deletes the sessions of tokens that have expired
+func (j *jobs) purgeSessions(ctx context.Context) error {
+	deleted, err := j.sessions.DeleteExpired(ctx)
+	if err != nil {
+		return err
+	}
+	j.log.Info("Purged sessions", zap.Int64("deleted", deleted))
+	return nil
+}
+
+// purgeDataExports deletes expired personal data exports and their archives
+func (j *jobs) purgeDataExports(ctx context.Context) error {
+	deleted, err := j.exports.PurgeExpired(ctx)
+	if err != nil {
+		return err
+	}
+	j.log.Info("Purged data exports", zap.Int("deleted", deleted))
+	return nil
+}
+
+// purgeDeletedUsers permanently deletes users soft deleted longer than the retention
+func (j *jobs) purgeDeletedUsers(ctx context.Context) error {
+	retention := j.cfg.Jobs[PurgeDeletedUsers].Retention
+	if retention <= 0 {
+		j.log.Warn("Skipping purge of deleted users, no retention configured", zap.String("job", PurgeDeletedUsers))
+		return nil
+	}
+
+	purged, err := j.users.PurgeDeleted(ctx, time.Now().Add(-retention))
+	if err != nil {
+		return err
+	}
+	j.log.Info("Purged deleted users", zap.Int("deleted", purged), zap.Duration("retention", retention))
+	return nil
+}
+
+// purgeOutbox deletes outbox messages published longer than the retention
+func (j *jobs) purgeOutbox(ctx context.Context) error {
+	retention := j.cfg.Jobs[PurgeOutbox].Retention
+	if retention <= 0 {
+		j.log.Warn("Skipping purge of the outbox, no retention configured", zap.String("job", PurgeOutbox))
+		return nil
+	}
+
+	deleted, err := j.outbox.DeletePublishedBefore(ctx, time.Now().Add(-retention))
+	if err != nil {
+		return err
+	}
+	j.log.Info("Purged outbox messages", zap.Int64("deleted", deleted), zap.Duration("retention", retention))
+	return nil
+}
+
+// purgeWebhookDeliveries deletes the records of webhook deliveries received
+// longer than the retention ago. Providers stop retrying well before, so a
+// redelivery after the purge is not expected.
+func (j *jobs) purgeWebhookDeliveries(ctx context.Context) error {
+	retention := j.cfg.Jobs[PurgeWebhookDeliveries].Retention
+	if retention <= 0 {
+		j.log.Warn("Skipping purge of webhook deliveries, no retention configured", zap.String("job", PurgeWebhookDeliveries))
+		return nil
+	}
+
+	deleted, err := j.deliveries.DeleteBefore(ctx, time.Now().Add(-retention))
+	if err != nil {
+		return err
+	}
+	j.log.Info("Purged webhook deliveries", zap.Int64("deleted", deleted), zap.Duration("retention", retention))
+	return nil
+}
+
+// purgeTasks deletes the background tasks completed longer than the retention ago
+func (j *jobs) purgeTasks(ctx context.Context) error {
+	retention := j.cfg.Jobs[PurgeTasks].Retention
+	if retention <= 0 {
+		j.log.Warn("Skipping purge of tasks, no retention configured", zap.String("job", PurgeTasks))
+		return nil
+	}
+
+	deleted, err := j.tasks.PurgeCompleted(ctx, time.Now().Add(-retention))
+	if err != nil {
+		return err
+	}
+	j.log.Info("Purged tasks", zap.Int64("deleted", deleted), zap.Duration("retention", retention))
+	return nil
+}
+
+// failStuckTasks fails the background tasks left pending or running by an
+// instance that stopped, so their owners stop waiting for them
+func (j *jobs) failStuckTasks(ctx context.Context) error {
+	failed, err := j.tasks.FailStuck(ctx)
+	if err != nil {
+		return err
+	}
+	j.log.Info("Failed stuck tasks", zap.Int64("failed", failed))
+	return nil
+}
+
+// prunePasswordHistory deletes the passwords beyond the history size of every user
+func (j *jobs) prunePasswordHistory(ctx context.Context) error {
+	deleted, err := j.passwords.Prune(ctx)
+	if err != nil {
+		return err
+	}
+	j.log.Info("Pruned password history", zap.Int64("deleted", deleted))
+	return nil
+}
@@ -0,0 +1,60 @@
+package job_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/firdanbash/go-clean-boiler/internal/job"
+	"github.com/firdanbash/go-clean-boiler/internal/mocks"
+	"github.com/firdanbash/go-clean-boiler/pkg/config"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"go.uber.org/mock/gomock"
+)
+
+func newScheduler(t *testing.T, jobs map[string]config.JobConfig) (int, error) {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+	cfg := &config.Config{Scheduler: config.SchedulerConfig{Enabled: true, Jobs: jobs}}
+
+	s, err := job.NewScheduler(
+		mocks.NewMockUserService(ctrl),
+		mocks.NewMockPasswordHistoryService(ctrl),
+		mocks.NewMockDataExportService(ctrl),
+		mocks.NewMockTaskService(ctrl),
+		mocks.NewMockPasswordResetTokenRepository(ctrl),
+		mocks.NewMockRevokedTokenRepository(ctrl),
+		mocks.NewMockSessionRepository(ctrl),
+		mocks.NewMockOutboxRepository(ctrl),
+		mocks.NewMockWebhookDeliveryRepository(ctrl),
+		cfg,
+		logger.Nop(),
+	)
+	if err != nil {
+		return 0, err
+	}
+	return s.Len(), nil
+}
+
+func TestNewSchedulerRegistersScheduledJobs(t *testing.T) {
+	count, err := newScheduler(t, map[string]config.JobConfig{
+		job.PurgePasswordResetTokens: {Schedule: "@hourly"},
+		job.PurgeRevokedTokens:       {Schedule: ""},
+		job.PurgeDeletedUsers:        {Schedule: "0 3 * * *", Retention: 720 * time.Hour},
+		"send_newsletter":            {Schedule: "@daily"},
+	})
+	if err != nil {
+		t.Fatalf("NewScheduler() error = %v", err)
+	}
+	if count != 2 {
+		t.Errorf("registered %d jobs, want 2 (disabled and unknown jobs skipped)", count)
+	}
+}
+
+func TestNewSchedulerRejectsInvalidSchedule(t *testing.T) {
+	_, err := newScheduler(t, map[string]config.JobConfig{
+		job.PurgeRevokedTokens: {Schedule: "every hour"},
+	})
+	if err == nil {
+		t.Fatal("NewScheduler() error = nil, want an invalid schedule error")
+	}
+}
@@ -0,0 +1,8 @@
+package job
+
+import "go.uber.org/fx"
+
+// Module provides the scheduler with the jobs registered
+var Module = fx.Module("job",
+	fx.Provide(NewScheduler),
+)
@@ -1,47 +1,92 @@
 package middleware
 
 import (
+	"errors"
 	"strings"
 
+	"github.com/firdanbash/go-clean-boiler/pkg/apperror"
 	"github.com/firdanbash/go-clean-boiler/pkg/jwt"
+	"github.com/firdanbash/go-clean-boiler/pkg/reqctx"
 	"github.com/firdanbash/go-clean-boiler/pkg/response"
 	"github.com/gin-gonic/gin"
 )
 
-// AuthMiddleware validates JWT token
-func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
+// Errors of requests without a valid token, told apart by their code
+var (
+	ErrTokenMissing     = apperror.Unauthorized("Authorization header required").WithCode(apperror.CodeAuthTokenMissing)
+	ErrTokenMalformed   = apperror.Unauthorized("Invalid authorization header format").WithCode(apperror.CodeAuthTokenMalformed)
+	ErrTokenRevoked     = apperror.Unauthorized("Token has been revoked").WithCode(apperror.CodeAuthTokenRevoked)
+	ErrTokenWrongTenant = apperror.Unauthorized("Token is not valid for this tenant").WithCode(apperror.CodeAuthTokenWrongTenant)
+	ErrTokenInvalid     = apperror.Unauthorized("Invalid or expired token").WithCode(apperror.CodeAuthTokenInvalid)
+)
+
+// AuthMiddleware validates JWT token and rejects tokens present in the denylist
+func AuthMiddleware(jwtManager *jwt.Manager, denylist jwt.Denylist) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			response.Unauthorized(c, "Authorization header required")
+		if c.GetHeader("Authorization") == "" {
+			response.Error(c, ErrTokenMissing, "")
 			c.Abort()
 			return
 		}
-
-		// Extract token from "Bearer <token>"
-		parts := strings.SplitN(authHeader, " ", 2)
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			response.Unauthorized(c, "Invalid authorization header format")
-			c.Abort()
+		if !authenticate(c, jwtManager, denylist) {
 			return
 		}
+		c.Next()
+	}
+}
 
-		token := parts[1]
-
-		// Validate token
-		claims, err := jwt.ValidateToken(token, jwtSecret)
-		if err != nil {
-			response.Unauthorized(c, "Invalid or expired token")
-			c.Abort()
+// OptionalAuthMiddleware authenticates requests that carry a token like
+// AuthMiddleware and lets requests without one through anonymously, for
+// endpoints that check access per operation
+func OptionalAuthMiddleware(jwtManager *jwt.Manager, denylist jwt.Denylist) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") != "" && !authenticate(c, jwtManager, denylist) {
 			return
 		}
+		c.Next()
+	}
+}
 
-		// Set user info in context
-		c.Set("user_id", claims.UserID)
-		c.Set("user_email", claims.Email)
+// authenticate validates the bearer token of the request and stores its claims
+// in the context. It aborts with a 401 and returns false when the token is
+// malformed, invalid, revoked or issued for another tenant than the request's.
+func authenticate(c *gin.Context, jwtManager *jwt.Manager, denylist jwt.Denylist) bool {
+	// Extract token from "Bearer <token>"
+	parts := strings.SplitN(c.GetHeader("Authorization"), " ", 2)
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		response.Error(c, ErrTokenMalformed, "")
+		c.Abort()
+		return false
+	}
 
-		c.Next()
+	token := parts[1]
+
+	// Validate token
+	claims, err := jwtManager.ValidateTokenWithDenylist(c.Request.Context(), token, denylist)
+	if err != nil {
+		switch {
+		case errors.Is(err, jwt.ErrRevokedToken):
+			response.Error(c, ErrTokenRevoked, "")
+		case errors.Is(err, jwt.ErrWrongTenant):
+			response.Error(c, ErrTokenWrongTenant, "")
+		default:
+			response.Error(c, ErrTokenInvalid, "")
+		}
+		c.Abort()
+		return false
 	}
+
+	// Set user info in context
+	c.Set("user_id", claims.UserID)
+	c.Set("user_email", claims.Email)
+	c.Set("user_role", claims.Role)
+	c.Set("claims", claims)
+	ctx := reqctx.WithUserID(c.Request.Context(), claims.UserID)
+	if claims.ImpersonatorID != 0 {
+		ctx = reqctx.WithImpersonatorID(ctx, claims.ImpersonatorID)
+	}
+	c.Request = c.Request.WithContext(ctx)
+	return true
 }
 
 // GetUserID retrieves user ID from context
@@ -52,3 +97,21 @@ func GetUserID(c *gin.Context) (uint, bool) {
 	}
 	return userID.(uint), true
 }
+
+// GetUserRole retrieves user role from context
+func GetUserRole(c *gin.Context) (string, bool) {
+	role, exists := c.Get("user_role")
+	if !exists {
+		return "", false
+	}
+	return role.(string), true
+}
+
+// GetClaims retrieves the validated token claims from context
+func GetClaims(c *gin.Context) (*jwt.Claims, bool) {
+	claims, exists := c.Get("claims")
+	if !exists {
+		return nil, false
+	}
+	return claims.(*jwt.Claims), true
+}
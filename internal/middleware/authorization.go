@@ -0,0 +1,146 @@
+package middleware
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/firdanbash/go-clean-boiler/pkg/authz"
+	"github.com/firdanbash/go-clean-boiler/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+// RequireRole allows the request only if the authenticated user has one of the given roles.
+// It must run after AuthMiddleware.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, _ := GetUserRole(c)
+		if !hasRole(role, roles) {
+			response.Forbidden(c, "You do not have permission to access this resource")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireSelfOrRole allows the request if the ":id" path param matches the authenticated
+// user's ID, or if the user has one of the given roles. It must run after AuthMiddleware.
+func RequireSelfOrRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, _ := GetUserRole(c)
+		if hasRole(role, roles) {
+			c.Next()
+			return
+		}
+
+		userID, ok := GetUserID(c)
+		targetID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+		if !ok || err != nil || uint(targetID) != userID {
+			response.Forbidden(c, "You do not have permission to access this resource")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// PermissionChecker reports whether the roles assigned to a user grant a permission
+type PermissionChecker interface {
+	HasPermission(ctx context.Context, userID uint, permission string) (bool, error)
+}
+
+// RequirePermission allows the request only if one of the roles assigned to the
+// authenticated user grants the permission. Users with one of the given
+// built-in roles are allowed without a check. It must run after AuthMiddleware.
+func RequirePermission(checker PermissionChecker, permission string, roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, _ := GetUserRole(c)
+		if hasRole(role, roles) {
+			c.Next()
+			return
+		}
+
+		userID, ok := GetUserID(c)
+		if !ok {
+			response.Forbidden(c, "You do not have permission to access this resource")
+			c.Abort()
+			return
+		}
+
+		allowed, err := checker.HasPermission(c.Request.Context(), userID, permission)
+		if err != nil {
+			response.Error(c, err, "Failed to check permissions")
+			c.Abort()
+			return
+		}
+		if !allowed {
+			response.Forbidden(c, "You do not have permission to access this resource")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// Enforcer decides whether a subject may perform an action on an object
+type Enforcer interface {
+	Enforce(rvals ...interface{}) (bool, error)
+}
+
+// EnforcerMiddleware makes the policy enforcer available to Authorize
+func EnforcerMiddleware(enforcer Enforcer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("enforcer", enforcer)
+		c.Next()
+	}
+}
+
+// GetEnforcer returns the policy enforcer set by EnforcerMiddleware
+func GetEnforcer(c *gin.Context) (Enforcer, bool) {
+	value, _ := c.Get("enforcer")
+	enforcer, ok := value.(Enforcer)
+	return enforcer, ok
+}
+
+// Authorize allows the request only if the casbin policies let the
+// authenticated user, as user:<id> or as role:<built-in role>, perform act on
+// obj. Policies are stored in the database, so they change without a deploy.
+// It must run after AuthMiddleware.
+func Authorize(obj, act string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		enforcer, ok := GetEnforcer(c)
+		if !ok {
+			response.InternalServerError(c, "Failed to check permissions", "no policy enforcer")
+			c.Abort()
+			return
+		}
+
+		role, _ := GetUserRole(c)
+		userID, _ := GetUserID(c)
+		allowed, err := authz.Allowed(enforcer, userID, role, obj, act)
+		if err != nil {
+			response.Error(c, err, "Failed to check permissions")
+			c.Abort()
+			return
+		}
+		if !allowed {
+			response.Forbidden(c, "You do not have permission to access this resource")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func hasRole(role string, roles []string) bool {
+	for _, r := range roles {
+		if role == r {
+			return true
+		}
+	}
+	return false
+}
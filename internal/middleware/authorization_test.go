@@ -0,0 +1,73 @@
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/firdanbash/go-clean-boiler/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// stubEnforcer allows the "<subject> <object> <action>" requests in allowed,
+// or fails every request with err
+type stubEnforcer struct {
+	allowed map[string]bool
+	err     error
+}
+
+func (e stubEnforcer) Enforce(rvals ...interface{}) (bool, error) {
+	if e.err != nil {
+		return false, e.err
+	}
+	return e.allowed[fmt.Sprintf("%v %v %v", rvals...)], nil
+}
+
+func TestAuthorize(t *testing.T) {
+	tests := []struct {
+		name       string
+		enforcer   middleware.Enforcer
+		wantStatus int
+	}{
+		{"allowed by a policy of the role", stubEnforcer{allowed: map[string]bool{"role:editor reports read": true}}, http.StatusOK},
+		{"allowed by a policy of the user", stubEnforcer{allowed: map[string]bool{"user:7 reports read": true}}, http.StatusOK},
+		{"denied without a policy", stubEnforcer{}, http.StatusForbidden},
+		{"denied by a policy of another action", stubEnforcer{allowed: map[string]bool{"role:editor reports delete": true}}, http.StatusForbidden},
+		{"denied by a policy of another user", stubEnforcer{allowed: map[string]bool{"user:8 reports read": true}}, http.StatusForbidden},
+		{"failing enforcer", stubEnforcer{err: errors.New("policy store unavailable")}, http.StatusInternalServerError},
+		{"enforcer out of time", stubEnforcer{err: context.DeadlineExceeded}, http.StatusGatewayTimeout},
+		{"missing enforcer", nil, http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			if tt.enforcer != nil {
+				r.Use(middleware.EnforcerMiddleware(tt.enforcer))
+			}
+			// Authenticated as user 7 with the editor role
+			r.Use(func(c *gin.Context) {
+				c.Set("user_id", uint(7))
+				c.Set("user_role", "editor")
+			})
+			called := false
+			r.GET("/reports", middleware.Authorize("reports", "read"), func(c *gin.Context) {
+				called = true
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/reports", nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if called != (tt.wantStatus == http.StatusOK) {
+				t.Errorf("handler called = %v, want %v", called, tt.wantStatus == http.StatusOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,272 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"expvar"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/firdanbash/go-clean-boiler/pkg/config"
+	"github.com/gin-gonic/gin"
+)
+
+// compressionMetrics counts the compressed responses and their bytes before
+// and after compression, served at /debug/vars
+var compressionMetrics = expvar.NewMap("compression")
+
+// encoder is a compressor that can be reused for another response
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// compressor holds the settings and the pooled encoders of CompressionMiddleware
+type compressor struct {
+	minSize      int
+	contentTypes []string
+	gzip         sync.Pool
+	brotli       *sync.Pool
+}
+
+// CompressionMiddleware compresses responses with gzip, or brotli when
+// enabled and preferred by the client, as negotiated with Accept-Encoding.
+// Only responses of the configured media types reaching MinSize bytes are
+// compressed; the rest, and responses that already have a Content-Encoding,
+// are sent as they are. The access log reports the uncompressed size.
+func CompressionMiddleware(cfg config.CompressionConfig) gin.HandlerFunc {
+	if !cfg.Enabled {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	comp := &compressor{minSize: cfg.MinSize, contentTypes: cfg.ContentTypes}
+	comp.gzip.New = func() interface{} {
+		w, _ := gzip.NewWriterLevel(io.Discard, cfg.GzipLevel)
+		return w
+	}
+	if cfg.Brotli {
+		comp.brotli = &sync.Pool{New: func() interface{} {
+			return brotli.NewWriterLevel(io.Discard, cfg.BrotliLevel)
+		}}
+	}
+
+	return func(c *gin.Context) {
+		if c.Request.Method == http.MethodHead || strings.EqualFold(c.GetHeader("Upgrade"), "websocket") {
+			c.Next()
+			return
+		}
+
+		c.Writer.Header().Add("Vary", "Accept-Encoding")
+		encoding := comp.negotiate(c.GetHeader("Accept-Encoding"))
+		if encoding == "" {
+			c.Next()
+			return
+		}
+
+		w := &compressWriter{ResponseWriter: c.Writer, comp: comp, encoding: encoding}
+		c.Writer = w
+		defer func() {
+			w.finish()
+			c.Writer = w.ResponseWriter
+		}()
+
+		c.Next()
+	}
+}
+
+// negotiate picks the encoding of the response from Accept-Encoding, or
+// returns "" when the client accepts neither br nor gzip
+func (comp *compressor) negotiate(acceptEncoding string) string {
+	gzipOK, brotliOK := false, false
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if weight, err := strconv.ParseFloat(q, 64); err == nil && weight == 0 {
+				continue
+			}
+		}
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "gzip", "*":
+			gzipOK = true
+		case "br":
+			brotliOK = true
+		}
+	}
+
+	switch {
+	case brotliOK && comp.brotli != nil:
+		return "br"
+	case gzipOK:
+		return "gzip"
+	}
+	return ""
+}
+
+// compressible reports whether responses of contentType are compressed
+func (comp *compressor) compressible(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, pattern := range comp.contentTypes {
+		if prefix, ok := strings.CutSuffix(pattern, "/*"); ok {
+			if strings.HasPrefix(mediaType, prefix+"/") {
+				return true
+			}
+		} else if mediaType == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// compressWriter holds back the start of the response until it is known
+// whether it is worth compressing, then writes it compressed or as it is
+type compressWriter struct {
+	gin.ResponseWriter
+	comp     *compressor
+	encoding string
+	buf      []byte
+	enc      encoder
+	decided  bool
+	size     int
+}
+
+func (w *compressWriter) Write(b []byte) (int, error) {
+	w.size += len(b)
+	if !w.decided {
+		if len(w.buf)+len(b) < w.comp.minSize && w.worthWaiting() {
+			w.buf = append(w.buf, b...)
+			return len(b), nil
+		}
+		buffered := append(w.buf, b...)
+		w.buf = nil
+		w.start(len(buffered) >= w.comp.minSize)
+		if _, err := w.write(buffered); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	}
+	return w.write(b)
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// WriteHeaderNow sends the buffered start of the response before the header,
+// e.g. for responses without a body
+func (w *compressWriter) WriteHeaderNow() {
+	if !w.decided {
+		w.flushBuffer()
+	}
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+// Written reports whether the handler wrote the response, even if it is still
+// held back
+func (w *compressWriter) Written() bool {
+	return w.decided || len(w.buf) > 0 || w.ResponseWriter.Written()
+}
+
+// Size is the number of uncompressed bytes the handler wrote
+func (w *compressWriter) Size() int {
+	if !w.Written() {
+		return -1
+	}
+	return w.size
+}
+
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		w.flushBuffer()
+	}
+	if w.enc != nil {
+		_ = w.enc.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// worthWaiting reports whether the response may still be compressed, so it is
+// buffered until it reaches the minimum size
+func (w *compressWriter) worthWaiting() bool {
+	header := w.Header()
+	return header.Get("Content-Encoding") == "" && w.comp.compressible(header.Get("Content-Type"))
+}
+
+// start decides how the response is written, compressing it when it is big
+// enough and of a compressible type
+func (w *compressWriter) start(bigEnough bool) {
+	w.decided = true
+	if !bigEnough || !w.worthWaiting() || !bodyAllowed(w.Status()) {
+		return
+	}
+
+	header := w.Header()
+	header.Set("Content-Encoding", w.encoding)
+	header.Del("Content-Length")
+	if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		header.Set("ETag", "W/"+etag)
+	}
+
+	if w.encoding == "br" {
+		w.enc = w.comp.brotli.Get().(encoder)
+	} else {
+		w.enc = w.comp.gzip.Get().(encoder)
+	}
+	w.enc.Reset(w.ResponseWriter)
+}
+
+// flushBuffer writes the held back start of the response uncompressed
+func (w *compressWriter) flushBuffer() {
+	buffered := w.buf
+	w.buf = nil
+	w.start(len(buffered) >= w.comp.minSize)
+	if len(buffered) > 0 {
+		_, _ = w.write(buffered)
+	}
+}
+
+func (w *compressWriter) write(b []byte) (int, error) {
+	if w.enc != nil {
+		return w.enc.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// finish writes what is still held back, completes the compressed stream and
+// returns the encoder to its pool
+func (w *compressWriter) finish() {
+	if !w.decided {
+		if len(w.buf) == 0 {
+			return
+		}
+		w.flushBuffer()
+	}
+	if w.enc == nil {
+		return
+	}
+
+	_ = w.enc.Close()
+	compressionMetrics.Add(w.encoding, 1)
+	compressionMetrics.Add("bytes_in", int64(w.size))
+	compressionMetrics.Add("bytes_out", int64(w.ResponseWriter.Size()))
+
+	w.enc.Reset(io.Discard)
+	if w.encoding == "br" {
+		w.comp.brotli.Put(w.enc)
+	} else {
+		w.comp.gzip.Put(w.enc)
+	}
+	w.enc = nil
+}
+
+// bodyAllowed reports whether a response with status may have a body
+func bodyAllowed(status int) bool {
+	return status >= 200 && status != http.StatusNoContent && status != http.StatusNotModified
+}
@@ -1,16 +1,29 @@
 package middleware
 
 import (
+	"github.com/firdanbash/go-clean-boiler/pkg/config"
+	"github.com/firdanbash/go-clean-boiler/pkg/response"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 )
 
-// CORSMiddleware configures CORS
-func CORSMiddleware() gin.HandlerFunc {
-	config := cors.DefaultConfig()
-	config.AllowAllOrigins = true
-	config.AllowHeaders = []string{"Origin", "Content-Length", "Content-Type", "Authorization"}
-	config.AllowMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+// CORSMiddleware answers preflight requests and adds the CORS headers for the
+// configured origins. Without origins it adds none, so browsers only allow
+// same-origin calls.
+func CORSMiddleware(cfg config.CORSConfig) gin.HandlerFunc {
+	if len(cfg.AllowedOrigins) == 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
 
-	return cors.New(config)
+	corsConfig := cors.Config{
+		AllowOrigins:     cfg.AllowedOrigins,
+		AllowWildcard:    true,
+		AllowCredentials: cfg.AllowCredentials,
+		AllowHeaders:     []string{"Origin", "Content-Length", "Content-Type", "Authorization", RequestIDHeader},
+		ExposeHeaders:    append(append([]string{RequestIDHeader}, response.PaginationHeaders...), cfg.ExposedHeaders...),
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		MaxAge:           cfg.MaxAge,
+	}
+
+	return cors.New(corsConfig)
 }
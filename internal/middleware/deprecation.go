@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/firdanbash/go-clean-boiler/pkg/config"
+	"github.com/firdanbash/go-clean-boiler/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+// DeprecationMiddleware announces the retirement schedule of an API version
+// with the Deprecation (RFC 9745) and Sunset (RFC 8594) headers, and answers
+// 410 Gone once the sunset date has passed
+func DeprecationMiddleware(cfg config.APIVersionConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if cfg.Successor != "" {
+			c.Writer.Header().Add("Link", fmt.Sprintf(`<%s>; rel="successor-version"`, cfg.Successor))
+		}
+		if !cfg.SunsetAt.IsZero() && !time.Now().Before(cfg.SunsetAt) {
+			response.Gone(c, "This API version has been retired")
+			c.Abort()
+			return
+		}
+
+		if !cfg.DeprecatedAt.IsZero() {
+			c.Header("Deprecation", fmt.Sprintf("@%d", cfg.DeprecatedAt.Unix()))
+		}
+		if !cfg.SunsetAt.IsZero() {
+			c.Header("Sunset", cfg.SunsetAt.UTC().Format(http.TimeFormat))
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"github.com/firdanbash/go-clean-boiler/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+// EnvelopeMiddleware tells whether successful responses are wrapped in the
+// standard {success,message,data} envelope, as api.envelope configures for
+// every route
+func EnvelopeMiddleware(enabled bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		response.SetEnvelope(c, enabled)
+		c.Next()
+	}
+}
+
+// RawResponses sends the successful responses of a route without the
+// envelope, whatever api.envelope is, e.g. for a route consumed by generic
+// REST tooling. Lists then carry their pagination in headers.
+func RawResponses() gin.HandlerFunc {
+	return EnvelopeMiddleware(false)
+}
@@ -1,8 +1,6 @@
 package middleware
 
 import (
-	"net/http"
-
 	"github.com/firdanbash/go-clean-boiler/pkg/logger"
 	"github.com/firdanbash/go-clean-boiler/pkg/response"
 	"github.com/gin-gonic/gin"
@@ -10,11 +8,11 @@ import (
 )
 
 // ErrorMiddleware handles panics and errors
-func ErrorMiddleware() gin.HandlerFunc {
+func ErrorMiddleware(log logger.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		defer func() {
 			if err := recover(); err != nil {
-				logger.Error("Panic recovered",
+				logger.Ctx(c.Request.Context(), log).Error("Panic recovered",
 					zap.Any("error", err),
 					zap.String("path", c.Request.URL.Path),
 				)
@@ -29,14 +27,14 @@ func ErrorMiddleware() gin.HandlerFunc {
 		// Check if there were any errors during request processing
 		if len(c.Errors) > 0 {
 			err := c.Errors.Last()
-			logger.Error("Request error",
+			logger.Ctx(c.Request.Context(), log).Error("Request error",
 				zap.Error(err.Err),
 				zap.String("path", c.Request.URL.Path),
 			)
 
-			// If response hasn't been written yet
-			if c.Writer.Status() == http.StatusOK {
-				response.InternalServerError(c, "An error occurred", err.Error())
+			// If response hasn't been written yet, map the error to its status
+			if !c.Writer.Written() {
+				response.Error(c, err.Err, "An error occurred")
 			}
 		}
 	}
@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"github.com/firdanbash/go-clean-boiler/pkg/featureflag"
+	"github.com/firdanbash/go-clean-boiler/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+// RequireFeature hides routes behind a feature flag: while the flag is off
+// they answer 404, as if they did not exist
+func RequireFeature(flags *featureflag.Flags, name string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !flags.Enabled(name) {
+			response.NotFound(c, "Not found")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"github.com/firdanbash/go-clean-boiler/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+// FormatMiddleware renders the responses as XML to clients preferring it in
+// their Accept header when xml is enabled, as api.xml configures, and as JSON
+// otherwise. Clients accepting any format get JSON.
+func FormatMiddleware(xml bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if xml {
+			c.Writer.Header().Add("Vary", "Accept")
+			switch c.NegotiateFormat(gin.MIMEJSON, gin.MIMEXML, gin.MIMEXML2) {
+			case gin.MIMEXML, gin.MIMEXML2:
+				response.SetFormat(c, response.FormatXML)
+			}
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// forwardedHeader is the standard proxy header of RFC 7239
+const forwardedHeader = "Forwarded"
+
+// forwardedChainHeader carries the client addresses of the Forwarded header
+// in the comma-separated form gin reads from X-Forwarded-For. Values sent by
+// clients are discarded.
+const forwardedChainHeader = "X-Forwarded-Chain"
+
+// RemoteIPHeaders returns the headers gin reads the client IP from, standing
+// in for Forwarded the header ForwardedMiddleware fills
+func RemoteIPHeaders(headers []string) []string {
+	mapped := make([]string, len(headers))
+	for i, header := range headers {
+		if http.CanonicalHeaderKey(header) == forwardedHeader {
+			header = forwardedChainHeader
+		}
+		mapped[i] = header
+	}
+	return mapped
+}
+
+// ForwardedMiddleware makes the addresses of the Forwarded header readable by
+// gin, so c.ClientIP() honors it when it is one of the remote IP headers
+func ForwardedMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request.Header.Del(forwardedChainHeader)
+		if forwarded := c.Request.Header.Values(forwardedHeader); len(forwarded) > 0 {
+			if chain := forwardedFor(forwarded); len(chain) > 0 {
+				c.Request.Header.Set(forwardedChainHeader, strings.Join(chain, ", "))
+			}
+		}
+		c.Next()
+	}
+}
+
+// forwardedFor returns the for= addresses of Forwarded header values, without
+// quotes, brackets and ports, nearest proxy last
+func forwardedFor(values []string) []string {
+	var chain []string
+	for _, value := range values {
+		for _, element := range strings.Split(value, ",") {
+			for _, pair := range strings.Split(element, ";") {
+				key, node, ok := strings.Cut(strings.TrimSpace(pair), "=")
+				if !ok || !strings.EqualFold(key, "for") {
+					continue
+				}
+				chain = append(chain, forwardedNode(strings.Trim(node, `"`)))
+			}
+		}
+	}
+	return chain
+}
+
+// forwardedNode strips the port of a node, e.g. 192.0.2.43:47011 or
+// [2001:db8:cafe::17]:4711. Obfuscated and unknown nodes are kept as they are
+// and make gin fall back to the address of the peer.
+func forwardedNode(node string) string {
+	if host, _, err := net.SplitHostPort(node); err == nil {
+		return host
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(node, "["), "]")
+}
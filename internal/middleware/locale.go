@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"github.com/firdanbash/go-clean-boiler/pkg/i18n"
+	"github.com/gin-gonic/gin"
+)
+
+// LocaleMiddleware picks the locale of the response messages from the
+// Accept-Language header, falling back to the default locale of the bundle,
+// and reports the choice in the Content-Language header
+func LocaleMiddleware(bundle *i18n.Bundle) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		l := bundle.Localizer(bundle.Match(c.GetHeader("Accept-Language")))
+		c.Header("Content-Language", l.Locale())
+		c.Writer.Header().Add("Vary", "Accept-Language")
+
+		c.Request = c.Request.WithContext(i18n.WithLocalizer(c.Request.Context(), l))
+		c.Next()
+	}
+}
@@ -1,34 +1,207 @@
 package middleware
 
 import (
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
 	"time"
 
+	"github.com/firdanbash/go-clean-boiler/pkg/config"
 	"github.com/firdanbash/go-clean-boiler/pkg/logger"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
-// LoggerMiddleware logs HTTP requests
-func LoggerMiddleware() gin.HandlerFunc {
+const (
+	redactedValue        = "[REDACTED]"
+	defaultMaxLoggedBody = 2048
+)
+
+// jsonFieldPattern matches a JSON key and its scalar value. The closing quote
+// of a string value is optional so values cut off by truncation still match.
+var jsonFieldPattern = regexp.MustCompile(`"((?:[^"\\]|\\.)*)"(\s*:\s*)("(?:[^"\\]|\\.)*"?|[^,{}\[\]\s]+)`)
+
+// bodyLogWriter keeps a copy of the first bytes written to the response
+type bodyLogWriter struct {
+	gin.ResponseWriter
+	body  *bytes.Buffer
+	limit int
+}
+
+func (w *bodyLogWriter) Write(b []byte) (int, error) {
+	w.capture(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyLogWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *bodyLogWriter) capture(b []byte) {
+	if room := w.limit + 1 - w.body.Len(); room > 0 {
+		if len(b) > room {
+			b = b[:room]
+		}
+		w.body.Write(b)
+	}
+}
+
+// LoggerMiddleware logs one structured entry per HTTP request, optionally with
+// truncated request/response bodies whose sensitive fields are redacted
+func LoggerMiddleware(log logger.Logger, cfg config.AccessLogConfig) gin.HandlerFunc {
+	redact := newRedactor(cfg.RedactFields)
+	if cfg.MaxBodySize <= 0 {
+		cfg.MaxBodySize = defaultMaxLoggedBody
+	}
+
 	return func(c *gin.Context) {
 		start := time.Now()
 		path := c.Request.URL.Path
-		query := c.Request.URL.RawQuery
+		query := redact.query(c.Request.URL.RawQuery)
+
+		var reqBody []byte
+		var respWriter *bodyLogWriter
+		if cfg.LogBodies {
+			reqBody = peekRequestBody(c, cfg.MaxBodySize)
+			respWriter = &bodyLogWriter{ResponseWriter: c.Writer, body: &bytes.Buffer{}, limit: cfg.MaxBodySize}
+			c.Writer = respWriter
+		}
 
 		c.Next()
 
-		latency := time.Since(start)
 		statusCode := c.Writer.Status()
-		method := c.Request.Method
-		clientIP := c.ClientIP()
-
-		logger.Info("HTTP Request",
-			zap.String("method", method),
+		fields := []logger.Field{
+			zap.String("method", c.Request.Method),
 			zap.String("path", path),
+			zap.String("route", c.FullPath()),
 			zap.String("query", query),
 			zap.Int("status", statusCode),
-			zap.Duration("latency", latency),
-			zap.String("ip", clientIP),
-		)
+			zap.Duration("latency", time.Since(start)),
+			zap.Int("size", max(c.Writer.Size(), 0)),
+			zap.String("ip", c.ClientIP()),
+			zap.String("user_agent", c.Request.UserAgent()),
+		}
+		if cfg.LogBodies {
+			fields = append(fields,
+				zap.String("request_body", redact.body(reqBody, c.ContentType(), cfg.MaxBodySize)),
+				zap.String("response_body", redact.body(respWriter.body.Bytes(), respWriter.Header().Get("Content-Type"), cfg.MaxBodySize)),
+			)
+		}
+
+		// The request context carries the request, user and trace IDs
+		reqLog := logger.Ctx(c.Request.Context(), log)
+		switch {
+		case statusCode >= 500:
+			reqLog.Error("HTTP Request", fields...)
+		case statusCode >= 400:
+			reqLog.Warn("HTTP Request", fields...)
+		default:
+			reqLog.Info("HTTP Request", fields...)
+		}
+	}
+}
+
+// peekRequestBody reads up to limit+1 bytes of the request body and puts them
+// back in front of the unread remainder, so handlers still see the full body
+func peekRequestBody(c *gin.Context, limit int) []byte {
+	if c.Request.Body == nil || c.Request.Body == http.NoBody {
+		return nil
+	}
+	buf, _ := io.ReadAll(io.LimitReader(c.Request.Body, int64(limit)+1))
+	c.Request.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(buf), c.Request.Body), Closer: c.Request.Body}
+	return buf
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// redactor masks the values of fields whose names contain any of the configured names
+type redactor struct {
+	fields []string
+}
+
+func newRedactor(fields []string) redactor {
+	lowered := make([]string, 0, len(fields))
+	for _, f := range fields {
+		if f = strings.ToLower(strings.TrimSpace(f)); f != "" {
+			lowered = append(lowered, f)
+		}
+	}
+	return redactor{fields: lowered}
+}
+
+func (r redactor) sensitive(name string) bool {
+	name = strings.ToLower(name)
+	for _, f := range r.fields {
+		if strings.Contains(name, f) {
+			return true
+		}
+	}
+	return false
+}
+
+// query redacts sensitive parameters of a raw query string
+func (r redactor) query(raw string) string {
+	if raw == "" {
+		return raw
+	}
+	values, err := url.ParseQuery(raw)
+	if err != nil {
+		return raw
+	}
+	changed := false
+	for key := range values {
+		if r.sensitive(key) {
+			values[key] = []string{redactedValue}
+			changed = true
+		}
+	}
+	if !changed {
+		return raw
+	}
+	return values.Encode()
+}
+
+// body renders a captured body for the log, redacted and truncated to limit
+func (r redactor) body(b []byte, contentType string, limit int) string {
+	if len(b) == 0 {
+		return ""
+	}
+	truncated := len(b) > limit
+	if truncated {
+		b = b[:limit]
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	var out string
+	switch {
+	case strings.Contains(mediaType, "json"):
+		out = jsonFieldPattern.ReplaceAllStringFunc(string(b), func(m string) string {
+			parts := jsonFieldPattern.FindStringSubmatch(m)
+			if !r.sensitive(parts[1]) {
+				return m
+			}
+			return `"` + parts[1] + `"` + parts[2] + `"` + redactedValue + `"`
+		})
+	case mediaType == "application/x-www-form-urlencoded":
+		out = r.query(string(b))
+	case strings.HasPrefix(mediaType, "text/"):
+		out = string(b)
+	case mediaType == "":
+		return "[body omitted]"
+	default:
+		return "[" + mediaType + " body omitted]"
+	}
+
+	if truncated {
+		out += "...(truncated)"
 	}
+	return out
 }
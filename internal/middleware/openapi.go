@@ -0,0 +1,170 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/firdanbash/go-clean-boiler/pkg/config"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"github.com/firdanbash/go-clean-boiler/pkg/response"
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/getkin/kin-openapi/openapi2conv"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// maxValidatedResponse is the largest response body checked against the spec;
+// larger bodies, such as exports, are skipped
+const maxValidatedResponse = 1 << 20
+
+// OpenAPIValidator checks requests, and optionally responses, against the
+// OpenAPI spec generated from the handler annotations
+type OpenAPIValidator struct {
+	router routers.Router
+	cfg    config.OpenAPIConfig
+	log    logger.Logger
+}
+
+// NewOpenAPIValidator builds a validator from a Swagger 2.0 JSON spec
+func NewOpenAPIValidator(spec []byte, cfg config.OpenAPIConfig, log logger.Logger) (*OpenAPIValidator, error) {
+	// Point references at their OpenAPI 3 location up front; the converter
+	// misses those nested in additionalProperties, such as map values
+	spec = bytes.ReplaceAll(spec, []byte(`"#/definitions/`), []byte(`"#/components/schemas/`))
+
+	var v2 openapi2.T
+	if err := json.Unmarshal(spec, &v2); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI spec: %w", err)
+	}
+	doc, err := openapi2conv.ToV3(&v2)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert OpenAPI spec: %w", err)
+	}
+	// Match requests on any host the API is served from
+	doc.Servers = nil
+
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to route OpenAPI spec: %w", err)
+	}
+	return &OpenAPIValidator{router: router, cfg: cfg, log: log}, nil
+}
+
+// Middleware rejects requests that do not match the spec with a 400 listing
+// every violation, and logs responses that do not match it. Routes missing
+// from the spec are not checked.
+func (v *OpenAPIValidator) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Skip routes that are not served, like the JWKS without asymmetric keys
+		if c.FullPath() == "" {
+			c.Next()
+			return
+		}
+		route, pathParams, err := v.router.FindRoute(c.Request)
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		ctx := c.Request.Context()
+		input := &openapi3filter.RequestValidationInput{
+			Request:    c.Request,
+			PathParams: pathParams,
+			Route:      route,
+			Options: &openapi3filter.Options{
+				MultiError: true,
+				// Authentication is enforced by AuthMiddleware
+				AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+			},
+		}
+		if !v.cfg.ValidateRequests {
+			// Only the response is checked, so skip the request body
+			input.Options.ExcludeRequestBody = true
+		} else if err := openapi3filter.ValidateRequest(ctx, input); err != nil {
+			response.BadRequest(c, "Request does not match the API schema", schemaViolations(err))
+			c.Abort()
+			return
+		}
+
+		if !v.cfg.ValidateResponses {
+			c.Next()
+			return
+		}
+
+		w := &bodyLogWriter{ResponseWriter: c.Writer, body: &bytes.Buffer{}, limit: maxValidatedResponse}
+		c.Writer = w
+		c.Next()
+
+		if w.body.Len() > maxValidatedResponse {
+			return
+		}
+		err = openapi3filter.ValidateResponse(ctx, &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: input,
+			Status:                 w.Status(),
+			Header:                 w.Header(),
+			Body:                   io.NopCloser(bytes.NewReader(w.body.Bytes())),
+			Options:                &openapi3filter.Options{MultiError: true, IncludeResponseStatus: true},
+		})
+		if err != nil {
+			logger.Ctx(ctx, v.log).Warn("Response does not match the API schema",
+				zap.String("method", c.Request.Method),
+				zap.String("route", route.Path),
+				zap.Int("status", w.Status()),
+				zap.Any("violations", schemaViolations(err)),
+			)
+		}
+	}
+}
+
+// schemaViolations maps where each violation is, such as "body.email" or
+// "query.page", to what is wrong there
+func schemaViolations(err error) map[string]string {
+	violations := make(map[string]string)
+	collectViolations(violations, "request", err)
+	return violations
+}
+
+func collectViolations(violations map[string]string, location string, err error) {
+	switch e := err.(type) {
+	case openapi3.MultiError:
+		for _, inner := range e {
+			collectViolations(violations, location, inner)
+		}
+	case *openapi3filter.RequestError:
+		switch {
+		case e.Parameter != nil:
+			location = e.Parameter.In + "." + e.Parameter.Name
+		case e.RequestBody != nil:
+			location = "body"
+		}
+		if e.Err == nil {
+			violations[location] = e.Reason
+			return
+		}
+		collectViolations(violations, location, e.Err)
+	case *openapi3filter.ResponseError:
+		if e.Err == nil {
+			violations["response"] = e.Reason
+			return
+		}
+		collectViolations(violations, "response", e.Err)
+	case *openapi3.SchemaError:
+		if pointer := e.JSONPointer(); len(pointer) > 0 {
+			location += "." + strings.Join(pointer, ".")
+		}
+		violations[location] = e.Reason
+	default:
+		if inner := errors.Unwrap(err); inner != nil {
+			collectViolations(violations, location, inner)
+			return
+		}
+		violations[location] = err.Error()
+	}
+}
@@ -0,0 +1,163 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/firdanbash/go-clean-boiler/pkg/config"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"github.com/firdanbash/go-clean-boiler/pkg/ratelimit"
+	"github.com/firdanbash/go-clean-boiler/pkg/response"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Rate limit identity types
+const (
+	RateLimitKeyIP     = "ip"
+	RateLimitKeyUser   = "user"
+	RateLimitKeyAPIKey = "api_key"
+)
+
+// defaultAPIKeyHeader is read when an api_key policy does not name a header
+const defaultAPIKeyHeader = "X-API-Key"
+
+// KeyFunc returns the identity a request is counted against
+type KeyFunc func(c *gin.Context) string
+
+// KeyByIP identifies requests by client IP
+func KeyByIP(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// KeyByUserID identifies requests by authenticated user, falling back to client IP.
+// It must run after AuthMiddleware to see the user.
+func KeyByUserID(c *gin.Context) string {
+	if userID, ok := GetUserID(c); ok {
+		return "user:" + strconv.FormatUint(uint64(userID), 10)
+	}
+	return KeyByIP(c)
+}
+
+// KeyByHeader identifies requests by an API key header, falling back to client IP
+// when the header is missing, or holds a key other than the known ones, given as
+// SHA-256 hex digests. Without known keys any value counts as a key, which is only
+// safe behind a gateway validating them: a client could send a new key with every
+// request. The key is hashed so raw credentials never reach the limiter store.
+func KeyByHeader(header string, known []string) KeyFunc {
+	knownKeys := make(map[string]bool, len(known))
+	for _, digest := range known {
+		knownKeys[strings.ToLower(digest)] = true
+	}
+
+	return func(c *gin.Context) string {
+		value := c.GetHeader(header)
+		if value == "" {
+			return KeyByIP(c)
+		}
+		sum := sha256.Sum256([]byte(value))
+		digest := hex.EncodeToString(sum[:])
+		if len(knownKeys) > 0 && !knownKeys[digest] {
+			return KeyByIP(c)
+		}
+		return "key:" + digest
+	}
+}
+
+// RateLimit limits requests per identity, setting X-RateLimit-* headers on every response.
+// If the limiter fails the request is let through so an outage does not take the API down.
+func RateLimit(limiter ratelimit.Limiter, name string, limit ratelimit.Limit, keyFunc KeyFunc, log logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		result, err := limiter.Allow(c.Request.Context(), name+":"+keyFunc(c), limit)
+		if err != nil {
+			logger.Ctx(c.Request.Context(), log).Error("Rate limiter failed", zap.String("policy", name), zap.Error(err))
+			c.Next()
+			return
+		}
+
+		resetSeconds := strconv.Itoa(int(math.Ceil(result.ResetAfter.Seconds())))
+		c.Header("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("X-RateLimit-Reset", resetSeconds)
+
+		if !result.Allowed {
+			c.Header("Retry-After", resetSeconds)
+			response.TooManyRequests(c, "Too many requests, please try again later")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RateLimiter builds rate limit middleware from the named policies in config.
+// Policies can be replaced at runtime with Update.
+type RateLimiter struct {
+	limiter  ratelimit.Limiter
+	log      logger.Logger
+	handlers atomic.Pointer[map[string]gin.HandlerFunc]
+}
+
+// NewRateLimiter validates the configured policies. A nil limiter disables rate limiting.
+func NewRateLimiter(limiter ratelimit.Limiter, policies map[string]config.RateLimitPolicyConfig, log logger.Logger) (*RateLimiter, error) {
+	rl := &RateLimiter{limiter: limiter, log: log}
+	if err := rl.Update(policies); err != nil {
+		return nil, err
+	}
+	return rl, nil
+}
+
+// Update validates and swaps in a new set of policies. Requests in flight finish
+// under the old policies; on error the current policies stay in place.
+func (rl *RateLimiter) Update(policies map[string]config.RateLimitPolicyConfig) error {
+	handlers := make(map[string]gin.HandlerFunc, len(policies))
+	for name, policy := range policies {
+		if policy.Requests <= 0 || policy.Window <= 0 {
+			return fmt.Errorf("rate limit policy %q needs positive requests and window", name)
+		}
+
+		keyFunc := KeyByIP
+		switch policy.Key {
+		case "", RateLimitKeyIP:
+		case RateLimitKeyUser:
+			keyFunc = KeyByUserID
+		case RateLimitKeyAPIKey:
+			header := policy.Header
+			if header == "" {
+				header = defaultAPIKeyHeader
+			}
+			keyFunc = KeyByHeader(header, policy.Keys)
+		default:
+			return fmt.Errorf("rate limit policy %q has unknown key %q", name, policy.Key)
+		}
+
+		limit := ratelimit.Limit{Requests: policy.Requests, Window: policy.Window}
+		handlers[name] = RateLimit(rl.limiter, name, limit, keyFunc, rl.log)
+	}
+
+	rl.handlers.Store(&handlers)
+	return nil
+}
+
+// Policy returns middleware enforcing the named policy as currently configured.
+// Unconfigured policies, or a disabled limiter, let every request through.
+func (rl *RateLimiter) Policy(name string) gin.HandlerFunc {
+	if rl.limiter == nil {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	return func(c *gin.Context) {
+		handler, ok := (*rl.handlers.Load())[name]
+		if !ok {
+			c.Next()
+			return
+		}
+		handler(c)
+	}
+}
@@ -0,0 +1,50 @@
+package middleware_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/firdanbash/go-clean-boiler/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// digest returns the SHA-256 hex digest of key
+func digest(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestKeyByHeader(t *testing.T) {
+	tests := []struct {
+		name   string
+		known  []string
+		header string
+		want   string
+	}{
+		{"missing key", nil, "", "ip:192.0.2.1"},
+		{"any key without known keys", nil, "partner-key", "key:" + digest("partner-key")},
+		{"known key", []string{digest("partner-key")}, "partner-key", "key:" + digest("partner-key")},
+		{"known key in upper case hex", []string{strings.ToUpper(digest("partner-key"))}, "partner-key", "key:" + digest("partner-key")},
+		{"unknown key", []string{digest("partner-key")}, "made-up-key", "ip:192.0.2.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			// Sent from 192.0.2.1, the address of httptest requests
+			c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				c.Request.Header.Set("X-API-Key", tt.header)
+			}
+
+			got := middleware.KeyByHeader("X-API-Key", tt.known)(c)
+			if got != tt.want {
+				t.Errorf("key = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
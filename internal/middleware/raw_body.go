@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/firdanbash/go-clean-boiler/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+// RawBodyMiddleware reads the request body, up to limit bytes, and keeps the
+// exact bytes for handlers that need them, such as webhook signature checks.
+// The body can still be bound afterwards. Larger bodies are rejected.
+func RawBodyMiddleware(limit int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, limit+1))
+		if err != nil {
+			response.BadRequest(c, "Failed to read request body", nil)
+			c.Abort()
+			return
+		}
+		if int64(len(body)) > limit {
+			response.RequestEntityTooLarge(c, "Request body is too large")
+			c.Abort()
+			return
+		}
+
+		c.Set("raw_body", body)
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		c.Next()
+	}
+}
+
+// GetRawBody retrieves the body captured by RawBodyMiddleware
+func GetRawBody(c *gin.Context) ([]byte, bool) {
+	body, exists := c.Get("raw_body")
+	if !exists {
+		return nil, false
+	}
+	return body.([]byte), true
+}
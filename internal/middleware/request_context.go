@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"github.com/firdanbash/go-clean-boiler/pkg/reqctx"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader carries the request ID in both directions
+const RequestIDHeader = "X-Request-ID"
+
+const maxRequestIDLength = 128
+
+// RequestContextMiddleware copies request metadata (request ID, client IP, user agent)
+// into the request context so services and repositories can read it without depending
+// on gin. An incoming X-Request-ID is reused, otherwise a new one is generated; either
+// way it is echoed back in the response.
+func RequestContextMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = uuid.NewString()
+		}
+		c.Header(RequestIDHeader, requestID)
+
+		ctx := reqctx.WithRequestID(c.Request.Context(), requestID)
+		ctx = reqctx.WithClient(ctx, c.ClientIP(), c.Request.UserAgent())
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
+
+// validRequestID accepts client-supplied IDs of printable ASCII only, so they
+// can be logged and echoed back safely
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,181 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/firdanbash/go-clean-boiler/pkg/cache"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"github.com/firdanbash/go-clean-boiler/pkg/response"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// CacheStatusHeader reports whether a response was served from the cache
+const CacheStatusHeader = "X-Cache"
+
+// cachedResponse is a response stored by ResponseCache
+type cachedResponse struct {
+	Status      int         `json:"status"`
+	ContentType string      `json:"content_type"`
+	Headers     http.Header `json:"headers,omitempty"` // pagination headers
+	Body        []byte      `json:"body"`
+}
+
+// ResponseCache serves the GET responses of the routes opting in from a
+// cache. Cached responses carry a tag, the resource they hold; invalidating a
+// tag moves its routes to a new generation of keys, so writes are seen by the
+// next request and the stale entries expire on their own.
+type ResponseCache struct {
+	store cache.Cache
+	ttl   time.Duration
+	log   logger.Logger
+}
+
+// NewResponseCache creates a response cache keeping entries in store for ttl,
+// unless a route sets its own. A nil store disables caching.
+func NewResponseCache(store cache.Cache, ttl time.Duration, log logger.Logger) *ResponseCache {
+	return &ResponseCache{store: store, ttl: ttl, log: log}
+}
+
+// Cache serves the route from the cache entries tagged tag, storing successful
+// responses for ttl, or the default TTL when ttl is 0. Entries are kept per
+// path and query, user, tenant and response language, so it must run after
+// AuthMiddleware and the access checks of the route.
+func (rc *ResponseCache) Cache(tag string, ttl time.Duration) gin.HandlerFunc {
+	if rc.store == nil {
+		return func(c *gin.Context) { c.Next() }
+	}
+	if ttl <= 0 {
+		ttl = rc.ttl
+	}
+
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet {
+			c.Next()
+			return
+		}
+
+		ctx := c.Request.Context()
+		key, err := rc.key(ctx, c, tag)
+		if err != nil {
+			logger.Ctx(ctx, rc.log).Error("Response cache failed", zap.String("tag", tag), zap.Error(err))
+			c.Next()
+			return
+		}
+
+		if !bypassCache(c.GetHeader("Cache-Control")) {
+			if cached, err := cache.GetJSON[cachedResponse](ctx, rc.store, key); err == nil {
+				c.Header(CacheStatusHeader, "HIT")
+				// Replaced rather than added, the stored values include any
+				// Link the route's other middleware just set again
+				for name, values := range cached.Headers {
+					c.Writer.Header()[name] = values
+				}
+				c.Data(cached.Status, cached.ContentType, cached.Body)
+				c.Abort()
+				return
+			} else if !errors.Is(err, cache.ErrMiss) {
+				logger.Ctx(ctx, rc.log).Error("Response cache failed", zap.String("tag", tag), zap.Error(err))
+			}
+		}
+
+		c.Header(CacheStatusHeader, "MISS")
+		writer := &bodyCaptureWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		if c.Writer.Status() != http.StatusOK {
+			return
+		}
+		cached := cachedResponse{
+			Status:      http.StatusOK,
+			ContentType: c.Writer.Header().Get("Content-Type"),
+			Body:        writer.body.Bytes(),
+		}
+		for _, name := range response.PaginationHeaders {
+			if values := c.Writer.Header().Values(name); len(values) > 0 {
+				if cached.Headers == nil {
+					cached.Headers = make(http.Header)
+				}
+				cached.Headers[name] = values
+			}
+		}
+		if err := cache.SetJSON(ctx, rc.store, key, cached, ttl); err != nil {
+			logger.Ctx(ctx, rc.log).Error("Failed to cache response", zap.String("tag", tag), zap.Error(err))
+		}
+	}
+}
+
+// Invalidate drops the cached responses of tags. Failures are logged: the
+// entries then expire after their TTL.
+func (rc *ResponseCache) Invalidate(ctx context.Context, tags ...string) {
+	if rc.store == nil {
+		return
+	}
+	generation := []byte(strconv.FormatInt(time.Now().UnixNano(), 36))
+	for _, tag := range tags {
+		if err := rc.store.Set(ctx, generationKey(tag), generation, 0); err != nil {
+			logger.Ctx(ctx, rc.log).Error("Failed to invalidate cached responses", zap.String("tag", tag), zap.Error(err))
+		}
+	}
+}
+
+// key returns the cache key of the response to the request, in the current
+// generation of tag
+func (rc *ResponseCache) key(ctx context.Context, c *gin.Context, tag string) (string, error) {
+	generation, err := rc.store.Get(ctx, generationKey(tag))
+	if errors.Is(err, cache.ErrMiss) {
+		generation = []byte("0")
+	} else if err != nil {
+		return "", err
+	}
+
+	userID, _ := GetUserID(c)
+	tenantID, _ := GetTenantID(c)
+	parts := []string{
+		c.Request.URL.Path,
+		c.Request.URL.Query().Encode(),
+		strconv.FormatUint(uint64(userID), 10),
+		strconv.FormatUint(uint64(tenantID), 10),
+		c.Writer.Header().Get("Content-Language"),
+		response.Format(c),
+	}
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\n")))
+	return "responses:" + tag + ":" + string(generation) + ":" + hex.EncodeToString(sum[:]), nil
+}
+
+// generationKey is the key holding the current generation of tag
+func generationKey(tag string) string {
+	return "responses:" + tag + ":generation"
+}
+
+// bypassCache reports whether the client asked for a fresh response
+func bypassCache(cacheControl string) bool {
+	cacheControl = strings.ToLower(cacheControl)
+	return strings.Contains(cacheControl, "no-cache") || strings.Contains(cacheControl, "no-store")
+}
+
+// bodyCaptureWriter keeps a copy of the response body
+type bodyCaptureWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyCaptureWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyCaptureWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"github.com/firdanbash/go-clean-boiler/pkg/config"
+	"github.com/firdanbash/go-clean-boiler/pkg/jwt"
+	"github.com/firdanbash/go-clean-boiler/pkg/reqctx"
+	"github.com/firdanbash/go-clean-boiler/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+// TenantResolver resolves a tenant slug to the ID of the tenant
+type TenantResolver interface {
+	Resolve(ctx context.Context, slug string) (uint, error)
+}
+
+// TenantMiddleware resolves the tenant a request acts for and carries it in
+// the request context, where repositories pick it up. The tenant is taken from
+// the subdomain of the base domain, then the tenant header, then the claim of
+// the bearer token, then the default tenant. A request naming no tenant gets a
+// 400 and one naming an unknown tenant a 404.
+func TenantMiddleware(tenants TenantResolver, cfg config.TenancyConfig, jwtManager *jwt.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		var tenantID uint
+		slug := tenantSlug(c, cfg)
+		if slug == "" {
+			tenantID = tokenTenant(c, jwtManager)
+			if tenantID == 0 {
+				slug = cfg.DefaultTenant
+			}
+		}
+		if tenantID == 0 {
+			if slug == "" {
+				response.BadRequest(c, "Tenant is required", nil)
+				c.Abort()
+				return
+			}
+
+			var err error
+			if tenantID, err = tenants.Resolve(ctx, slug); err != nil {
+				response.Error(c, err, "Failed to resolve tenant")
+				c.Abort()
+				return
+			}
+		}
+
+		c.Set("tenant_id", tenantID)
+		c.Request = c.Request.WithContext(reqctx.WithTenantID(ctx, tenantID))
+		c.Next()
+	}
+}
+
+// tenantSlug returns the tenant named by the host or the header of the request
+func tenantSlug(c *gin.Context, cfg config.TenancyConfig) string {
+	if cfg.BaseDomain != "" {
+		host := c.Request.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if sub, ok := strings.CutSuffix(strings.ToLower(host), "."+cfg.BaseDomain); ok && !strings.Contains(sub, ".") {
+			return sub
+		}
+	}
+	return strings.ToLower(strings.TrimSpace(c.GetHeader(cfg.Header)))
+}
+
+// tokenTenant returns the tenant of the bearer token of the request; 0 without
+// a valid token. The token is authenticated again by AuthMiddleware.
+func tokenTenant(c *gin.Context, jwtManager *jwt.Manager) uint {
+	token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if !ok {
+		return 0
+	}
+	claims, err := jwtManager.ValidateToken(token)
+	if err != nil {
+		return 0
+	}
+	return claims.TenantID
+}
+
+// GetTenantID retrieves the tenant ID from context
+func GetTenantID(c *gin.Context) (uint, bool) {
+	tenantID, exists := c.Get("tenant_id")
+	if !exists {
+		return 0, false
+	}
+	return tenantID.(uint), true
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/firdanbash/go-clean-boiler/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+// TimeoutMiddleware gives the request context a deadline of timeout. Queries
+// and calls made with the context are cancelled when it passes, and the
+// request is answered with 504 unless the handler already responded. Handlers
+// run to completion, so work that ignores the context is not interrupted.
+// WebSocket upgrades are long-lived and get no deadline.
+func TimeoutMiddleware(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if timeout <= 0 || strings.EqualFold(c.GetHeader("Upgrade"), "websocket") {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) && !c.Writer.Written() {
+			response.GatewayTimeout(c, "Request timed out")
+			c.Abort()
+		}
+	}
+}
@@ -0,0 +1,13 @@
+package middleware
+
+import (
+	"github.com/firdanbash/go-clean-boiler/pkg/tracing"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+)
+
+// TracingMiddleware starts a server span for every request, continuing any trace
+// propagated by the caller. It is a no-op unless tracing is enabled.
+func TracingMiddleware() gin.HandlerFunc {
+	return otelgin.Middleware(tracing.ServiceName())
+}
@@ -0,0 +1,70 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ../service/activity_service.go
+//
+// Generated by this command:
+//
+//	mockgen -source=../service/activity_service.go -destination=activity_service.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	request "github.com/firdanbash/go-clean-boiler/internal/dto/request"
+	response "github.com/firdanbash/go-clean-boiler/internal/dto/response"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockActivityService is a mock of ActivityService interface.
+type MockActivityService struct {
+	ctrl     *gomock.Controller
+	recorder *MockActivityServiceMockRecorder
+}
+
+// MockActivityServiceMockRecorder is the mock recorder for MockActivityService.
+type MockActivityServiceMockRecorder struct {
+	mock *MockActivityService
+}
+
+// NewMockActivityService creates a new mock instance.
+func NewMockActivityService(ctrl *gomock.Controller) *MockActivityService {
+	mock := &MockActivityService{ctrl: ctrl}
+	mock.recorder = &MockActivityServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockActivityService) EXPECT() *MockActivityServiceMockRecorder {
+	return m.recorder
+}
+
+// ListForUser mocks base method.
+func (m *MockActivityService) ListForUser(ctx context.Context, userID uint, req *request.ListActivityRequest) ([]response.LoginEventResponse, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListForUser", ctx, userID, req)
+	ret0, _ := ret[0].([]response.LoginEventResponse)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListForUser indicates an expected call of ListForUser.
+func (mr *MockActivityServiceMockRecorder) ListForUser(ctx, userID, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListForUser", reflect.TypeOf((*MockActivityService)(nil).ListForUser), ctx, userID, req)
+}
+
+// RecordLogin mocks base method.
+func (m *MockActivityService) RecordLogin(ctx context.Context, userID *uint, email string, success bool, failureReason string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RecordLogin", ctx, userID, email, success, failureReason)
+}
+
+// RecordLogin indicates an expected call of RecordLogin.
+func (mr *MockActivityServiceMockRecorder) RecordLogin(ctx, userID, email, success, failureReason any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordLogin", reflect.TypeOf((*MockActivityService)(nil).RecordLogin), ctx, userID, email, success, failureReason)
+}
@@ -0,0 +1,70 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ../repository/feature_flag_repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=../repository/feature_flag_repository.go -destination=feature_flag_repository.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/firdanbash/go-clean-boiler/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockFeatureFlagRepository is a mock of FeatureFlagRepository interface.
+type MockFeatureFlagRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockFeatureFlagRepositoryMockRecorder
+}
+
+// MockFeatureFlagRepositoryMockRecorder is the mock recorder for MockFeatureFlagRepository.
+type MockFeatureFlagRepositoryMockRecorder struct {
+	mock *MockFeatureFlagRepository
+}
+
+// NewMockFeatureFlagRepository creates a new mock instance.
+func NewMockFeatureFlagRepository(ctrl *gomock.Controller) *MockFeatureFlagRepository {
+	mock := &MockFeatureFlagRepository{ctrl: ctrl}
+	mock.recorder = &MockFeatureFlagRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFeatureFlagRepository) EXPECT() *MockFeatureFlagRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FindAll mocks base method.
+func (m *MockFeatureFlagRepository) FindAll(ctx context.Context) ([]domain.FeatureFlag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAll", ctx)
+	ret0, _ := ret[0].([]domain.FeatureFlag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAll indicates an expected call of FindAll.
+func (mr *MockFeatureFlagRepositoryMockRecorder) FindAll(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAll", reflect.TypeOf((*MockFeatureFlagRepository)(nil).FindAll), ctx)
+}
+
+// Upsert mocks base method.
+func (m *MockFeatureFlagRepository) Upsert(ctx context.Context, flag *domain.FeatureFlag) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Upsert", ctx, flag)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Upsert indicates an expected call of Upsert.
+func (mr *MockFeatureFlagRepositoryMockRecorder) Upsert(ctx, flag any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upsert", reflect.TypeOf((*MockFeatureFlagRepository)(nil).Upsert), ctx, flag)
+}
@@ -0,0 +1,175 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ../service/login_guard.go
+//
+// Generated by this command:
+//
+//	mockgen -source=../service/login_guard.go -destination=login_guard.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	bruteforce "github.com/firdanbash/go-clean-boiler/pkg/bruteforce"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockLoginGuard is a mock of LoginGuard interface.
+type MockLoginGuard struct {
+	ctrl     *gomock.Controller
+	recorder *MockLoginGuardMockRecorder
+}
+
+// MockLoginGuardMockRecorder is the mock recorder for MockLoginGuard.
+type MockLoginGuardMockRecorder struct {
+	mock *MockLoginGuard
+}
+
+// NewMockLoginGuard creates a new mock instance.
+func NewMockLoginGuard(ctrl *gomock.Controller) *MockLoginGuard {
+	mock := &MockLoginGuard{ctrl: ctrl}
+	mock.recorder = &MockLoginGuardMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLoginGuard) EXPECT() *MockLoginGuardMockRecorder {
+	return m.recorder
+}
+
+// Check mocks base method.
+func (m *MockLoginGuard) Check(ctx context.Context, account string) (bruteforce.Status, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Check", ctx, account)
+	ret0, _ := ret[0].(bruteforce.Status)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Check indicates an expected call of Check.
+func (mr *MockLoginGuardMockRecorder) Check(ctx, account any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Check", reflect.TypeOf((*MockLoginGuard)(nil).Check), ctx, account)
+}
+
+// Fail mocks base method.
+func (m *MockLoginGuard) Fail(ctx context.Context, account string) (bruteforce.Status, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Fail", ctx, account)
+	ret0, _ := ret[0].(bruteforce.Status)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Fail indicates an expected call of Fail.
+func (mr *MockLoginGuardMockRecorder) Fail(ctx, account any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Fail", reflect.TypeOf((*MockLoginGuard)(nil).Fail), ctx, account)
+}
+
+// Reset mocks base method.
+func (m *MockLoginGuard) Reset(ctx context.Context, account string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reset", ctx, account)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reset indicates an expected call of Reset.
+func (mr *MockLoginGuardMockRecorder) Reset(ctx, account any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reset", reflect.TypeOf((*MockLoginGuard)(nil).Reset), ctx, account)
+}
+
+// MockMFAAttempts is a mock of MFAAttempts interface.
+type MockMFAAttempts struct {
+	ctrl     *gomock.Controller
+	recorder *MockMFAAttemptsMockRecorder
+}
+
+// MockMFAAttemptsMockRecorder is the mock recorder for MockMFAAttempts.
+type MockMFAAttemptsMockRecorder struct {
+	mock *MockMFAAttempts
+}
+
+// NewMockMFAAttempts creates a new mock instance.
+func NewMockMFAAttempts(ctrl *gomock.Controller) *MockMFAAttempts {
+	mock := &MockMFAAttempts{ctrl: ctrl}
+	mock.recorder = &MockMFAAttemptsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMFAAttempts) EXPECT() *MockMFAAttemptsMockRecorder {
+	return m.recorder
+}
+
+// Fail mocks base method.
+func (m *MockMFAAttempts) Fail(ctx context.Context, challengeID string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Fail", ctx, challengeID)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Fail indicates an expected call of Fail.
+func (mr *MockMFAAttemptsMockRecorder) Fail(ctx, challengeID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Fail", reflect.TypeOf((*MockMFAAttempts)(nil).Fail), ctx, challengeID)
+}
+
+// Reset mocks base method.
+func (m *MockMFAAttempts) Reset(ctx context.Context, challengeID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reset", ctx, challengeID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reset indicates an expected call of Reset.
+func (mr *MockMFAAttemptsMockRecorder) Reset(ctx, challengeID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reset", reflect.TypeOf((*MockMFAAttempts)(nil).Reset), ctx, challengeID)
+}
+
+// MockCaptchaVerifier is a mock of CaptchaVerifier interface.
+type MockCaptchaVerifier struct {
+	ctrl     *gomock.Controller
+	recorder *MockCaptchaVerifierMockRecorder
+}
+
+// MockCaptchaVerifierMockRecorder is the mock recorder for MockCaptchaVerifier.
+type MockCaptchaVerifierMockRecorder struct {
+	mock *MockCaptchaVerifier
+}
+
+// NewMockCaptchaVerifier creates a new mock instance.
+func NewMockCaptchaVerifier(ctrl *gomock.Controller) *MockCaptchaVerifier {
+	mock := &MockCaptchaVerifier{ctrl: ctrl}
+	mock.recorder = &MockCaptchaVerifierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCaptchaVerifier) EXPECT() *MockCaptchaVerifierMockRecorder {
+	return m.recorder
+}
+
+// Verify mocks base method.
+func (m *MockCaptchaVerifier) Verify(ctx context.Context, token string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Verify", ctx, token)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Verify indicates an expected call of Verify.
+func (mr *MockCaptchaVerifierMockRecorder) Verify(ctx, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockCaptchaVerifier)(nil).Verify), ctx, token)
+}
@@ -0,0 +1,129 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ../repository/membership_repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=../repository/membership_repository.go -destination=membership_repository.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/firdanbash/go-clean-boiler/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockMembershipRepository is a mock of MembershipRepository interface.
+type MockMembershipRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockMembershipRepositoryMockRecorder
+}
+
+// MockMembershipRepositoryMockRecorder is the mock recorder for MockMembershipRepository.
+type MockMembershipRepositoryMockRecorder struct {
+	mock *MockMembershipRepository
+}
+
+// NewMockMembershipRepository creates a new mock instance.
+func NewMockMembershipRepository(ctrl *gomock.Controller) *MockMembershipRepository {
+	mock := &MockMembershipRepository{ctrl: ctrl}
+	mock.recorder = &MockMembershipRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMembershipRepository) EXPECT() *MockMembershipRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CountByRole mocks base method.
+func (m *MockMembershipRepository) CountByRole(ctx context.Context, orgID uint, role string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountByRole", ctx, orgID, role)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountByRole indicates an expected call of CountByRole.
+func (mr *MockMembershipRepositoryMockRecorder) CountByRole(ctx, orgID, role any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountByRole", reflect.TypeOf((*MockMembershipRepository)(nil).CountByRole), ctx, orgID, role)
+}
+
+// Create mocks base method.
+func (m *MockMembershipRepository) Create(ctx context.Context, membership *domain.Membership) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, membership)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockMembershipRepositoryMockRecorder) Create(ctx, membership any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockMembershipRepository)(nil).Create), ctx, membership)
+}
+
+// Find mocks base method.
+func (m *MockMembershipRepository) Find(ctx context.Context, orgID, userID uint) (*domain.Membership, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Find", ctx, orgID, userID)
+	ret0, _ := ret[0].(*domain.Membership)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Find indicates an expected call of Find.
+func (mr *MockMembershipRepositoryMockRecorder) Find(ctx, orgID, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockMembershipRepository)(nil).Find), ctx, orgID, userID)
+}
+
+// FindByOrganization mocks base method.
+func (m *MockMembershipRepository) FindByOrganization(ctx context.Context, orgID uint) ([]domain.Membership, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByOrganization", ctx, orgID)
+	ret0, _ := ret[0].([]domain.Membership)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByOrganization indicates an expected call of FindByOrganization.
+func (mr *MockMembershipRepositoryMockRecorder) FindByOrganization(ctx, orgID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByOrganization", reflect.TypeOf((*MockMembershipRepository)(nil).FindByOrganization), ctx, orgID)
+}
+
+// FindByUser mocks base method.
+func (m *MockMembershipRepository) FindByUser(ctx context.Context, userID uint, orgIDs []uint) ([]domain.Membership, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByUser", ctx, userID, orgIDs)
+	ret0, _ := ret[0].([]domain.Membership)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByUser indicates an expected call of FindByUser.
+func (mr *MockMembershipRepositoryMockRecorder) FindByUser(ctx, userID, orgIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByUser", reflect.TypeOf((*MockMembershipRepository)(nil).FindByUser), ctx, userID, orgIDs)
+}
+
+// UpdateRole mocks base method.
+func (m *MockMembershipRepository) UpdateRole(ctx context.Context, id uint, role string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRole", ctx, id, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateRole indicates an expected call of UpdateRole.
+func (mr *MockMembershipRepositoryMockRecorder) UpdateRole(ctx, id, role any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRole", reflect.TypeOf((*MockMembershipRepository)(nil).UpdateRole), ctx, id, role)
+}
@@ -0,0 +1,99 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ../repository/mfa_recovery_code_repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=../repository/mfa_recovery_code_repository.go -destination=mfa_recovery_code_repository.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/firdanbash/go-clean-boiler/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockMFARecoveryCodeRepository is a mock of MFARecoveryCodeRepository interface.
+type MockMFARecoveryCodeRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockMFARecoveryCodeRepositoryMockRecorder
+}
+
+// MockMFARecoveryCodeRepositoryMockRecorder is the mock recorder for MockMFARecoveryCodeRepository.
+type MockMFARecoveryCodeRepositoryMockRecorder struct {
+	mock *MockMFARecoveryCodeRepository
+}
+
+// NewMockMFARecoveryCodeRepository creates a new mock instance.
+func NewMockMFARecoveryCodeRepository(ctrl *gomock.Controller) *MockMFARecoveryCodeRepository {
+	mock := &MockMFARecoveryCodeRepository{ctrl: ctrl}
+	mock.recorder = &MockMFARecoveryCodeRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMFARecoveryCodeRepository) EXPECT() *MockMFARecoveryCodeRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteByUserID mocks base method.
+func (m *MockMFARecoveryCodeRepository) DeleteByUserID(ctx context.Context, userID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByUserID", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteByUserID indicates an expected call of DeleteByUserID.
+func (mr *MockMFARecoveryCodeRepositoryMockRecorder) DeleteByUserID(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByUserID", reflect.TypeOf((*MockMFARecoveryCodeRepository)(nil).DeleteByUserID), ctx, userID)
+}
+
+// FindUnused mocks base method.
+func (m *MockMFARecoveryCodeRepository) FindUnused(ctx context.Context, userID uint, codeHash string) (*domain.MFARecoveryCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindUnused", ctx, userID, codeHash)
+	ret0, _ := ret[0].(*domain.MFARecoveryCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindUnused indicates an expected call of FindUnused.
+func (mr *MockMFARecoveryCodeRepositoryMockRecorder) FindUnused(ctx, userID, codeHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUnused", reflect.TypeOf((*MockMFARecoveryCodeRepository)(nil).FindUnused), ctx, userID, codeHash)
+}
+
+// MarkUsed mocks base method.
+func (m *MockMFARecoveryCodeRepository) MarkUsed(ctx context.Context, id uint) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkUsed", ctx, id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkUsed indicates an expected call of MarkUsed.
+func (mr *MockMFARecoveryCodeRepositoryMockRecorder) MarkUsed(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkUsed", reflect.TypeOf((*MockMFARecoveryCodeRepository)(nil).MarkUsed), ctx, id)
+}
+
+// ReplaceForUser mocks base method.
+func (m *MockMFARecoveryCodeRepository) ReplaceForUser(ctx context.Context, userID uint, codeHashes []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceForUser", ctx, userID, codeHashes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplaceForUser indicates an expected call of ReplaceForUser.
+func (mr *MockMFARecoveryCodeRepositoryMockRecorder) ReplaceForUser(ctx, userID, codeHashes any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceForUser", reflect.TypeOf((*MockMFARecoveryCodeRepository)(nil).ReplaceForUser), ctx, userID, codeHashes)
+}
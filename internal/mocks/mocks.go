@@ -0,0 +1,38 @@
+// Package mocks holds gomock mocks of the repository and service interfaces.
+// Regenerate them with `make mocks` (or `go generate ./internal/mocks`) after
+// changing an interface.
+package mocks
+
+//go:generate go run go.uber.org/mock/mockgen -source=../repository/user_repository.go -destination=user_repository.go -package=mocks
+//go:generate go run go.uber.org/mock/mockgen -source=../repository/revoked_token_repository.go -destination=revoked_token_repository.go -package=mocks
+//go:generate go run go.uber.org/mock/mockgen -source=../repository/password_reset_token_repository.go -destination=password_reset_token_repository.go -package=mocks
+//go:generate go run go.uber.org/mock/mockgen -source=../repository/mfa_recovery_code_repository.go -destination=mfa_recovery_code_repository.go -package=mocks
+//go:generate go run go.uber.org/mock/mockgen -source=../repository/outbox_repository.go -destination=outbox_repository.go -package=mocks
+//go:generate go run go.uber.org/mock/mockgen -source=../repository/notification_preference_repository.go -destination=notification_preference_repository.go -package=mocks
+//go:generate go run go.uber.org/mock/mockgen -source=../repository/file_repository.go -destination=file_repository.go -package=mocks
+//go:generate go run go.uber.org/mock/mockgen -source=../repository/webhook_delivery_repository.go -destination=webhook_delivery_repository.go -package=mocks
+//go:generate go run go.uber.org/mock/mockgen -source=../repository/feature_flag_repository.go -destination=feature_flag_repository.go -package=mocks
+//go:generate go run go.uber.org/mock/mockgen -source=../repository/tenant_repository.go -destination=tenant_repository.go -package=mocks
+//go:generate go run go.uber.org/mock/mockgen -source=../repository/organization_repository.go -destination=organization_repository.go -package=mocks
+//go:generate go run go.uber.org/mock/mockgen -source=../repository/membership_repository.go -destination=membership_repository.go -package=mocks
+//go:generate go run go.uber.org/mock/mockgen -source=../repository/role_repository.go -destination=role_repository.go -package=mocks
+//go:generate go run go.uber.org/mock/mockgen -source=../repository/permission_repository.go -destination=permission_repository.go -package=mocks
+//go:generate go run go.uber.org/mock/mockgen -source=../repository/password_history_repository.go -destination=password_history_repository.go -package=mocks
+//go:generate go run go.uber.org/mock/mockgen -source=../repository/session_repository.go -destination=session_repository.go -package=mocks
+//go:generate go run go.uber.org/mock/mockgen -source=../repository/data_export_repository.go -destination=data_export_repository.go -package=mocks
+//go:generate go run go.uber.org/mock/mockgen -source=../repository/task_repository.go -destination=task_repository.go -package=mocks
+//go:generate go run go.uber.org/mock/mockgen -source=../service/user_service.go -destination=user_service.go -package=mocks
+//go:generate go run go.uber.org/mock/mockgen -source=../service/auth_service.go -destination=auth_service.go -package=mocks
+//go:generate go run go.uber.org/mock/mockgen -source=../service/audit_service.go -destination=audit_service.go -package=mocks
+//go:generate go run go.uber.org/mock/mockgen -source=../service/event_publisher.go -destination=event_publisher.go -package=mocks
+//go:generate go run go.uber.org/mock/mockgen -source=../service/cache_invalidator.go -destination=cache_invalidator.go -package=mocks
+//go:generate go run go.uber.org/mock/mockgen -source=../service/login_guard.go -destination=login_guard.go -package=mocks
+//go:generate go run go.uber.org/mock/mockgen -source=../service/policy_enforcer.go -destination=policy_enforcer.go -package=mocks
+//go:generate go run go.uber.org/mock/mockgen -source=../service/password_history_service.go -destination=password_history_service.go -package=mocks
+//go:generate go run go.uber.org/mock/mockgen -source=../service/activity_service.go -destination=activity_service.go -package=mocks
+//go:generate go run go.uber.org/mock/mockgen -source=../service/data_export_service.go -destination=data_export_service.go -package=mocks
+//go:generate go run go.uber.org/mock/mockgen -source=../service/task_service.go -destination=task_service.go -package=mocks
+//go:generate go run go.uber.org/mock/mockgen -source=../service/search_engine.go -destination=search_engine.go -package=mocks
+//go:generate go run go.uber.org/mock/mockgen -source=../service/search_service.go -destination=search_service.go -package=mocks
+//go:generate go run go.uber.org/mock/mockgen -source=../event/bus.go -destination=event_dispatcher.go -package=mocks
+//go:generate go run go.uber.org/mock/mockgen -source=../notification/notifier.go -destination=notifier.go -package=mocks
@@ -0,0 +1,84 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ../service/password_history_service.go
+//
+// Generated by this command:
+//
+//	mockgen -source=../service/password_history_service.go -destination=password_history_service.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/firdanbash/go-clean-boiler/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockPasswordHistoryService is a mock of PasswordHistoryService interface.
+type MockPasswordHistoryService struct {
+	ctrl     *gomock.Controller
+	recorder *MockPasswordHistoryServiceMockRecorder
+}
+
+// MockPasswordHistoryServiceMockRecorder is the mock recorder for MockPasswordHistoryService.
+type MockPasswordHistoryServiceMockRecorder struct {
+	mock *MockPasswordHistoryService
+}
+
+// NewMockPasswordHistoryService creates a new mock instance.
+func NewMockPasswordHistoryService(ctrl *gomock.Controller) *MockPasswordHistoryService {
+	mock := &MockPasswordHistoryService{ctrl: ctrl}
+	mock.recorder = &MockPasswordHistoryServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPasswordHistoryService) EXPECT() *MockPasswordHistoryServiceMockRecorder {
+	return m.recorder
+}
+
+// Check mocks base method.
+func (m *MockPasswordHistoryService) Check(ctx context.Context, user *domain.User, password string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Check", ctx, user, password)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Check indicates an expected call of Check.
+func (mr *MockPasswordHistoryServiceMockRecorder) Check(ctx, user, password any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Check", reflect.TypeOf((*MockPasswordHistoryService)(nil).Check), ctx, user, password)
+}
+
+// Prune mocks base method.
+func (m *MockPasswordHistoryService) Prune(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Prune", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Prune indicates an expected call of Prune.
+func (mr *MockPasswordHistoryServiceMockRecorder) Prune(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Prune", reflect.TypeOf((*MockPasswordHistoryService)(nil).Prune), ctx)
+}
+
+// Record mocks base method.
+func (m *MockPasswordHistoryService) Record(ctx context.Context, user *domain.User) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Record", ctx, user)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Record indicates an expected call of Record.
+func (mr *MockPasswordHistoryServiceMockRecorder) Record(ctx, user any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Record", reflect.TypeOf((*MockPasswordHistoryService)(nil).Record), ctx, user)
+}
@@ -0,0 +1,78 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ../service/policy_enforcer.go
+//
+// Generated by this command:
+//
+//	mockgen -source=../service/policy_enforcer.go -destination=policy_enforcer.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockPolicyEnforcer is a mock of PolicyEnforcer interface.
+type MockPolicyEnforcer struct {
+	ctrl     *gomock.Controller
+	recorder *MockPolicyEnforcerMockRecorder
+}
+
+// MockPolicyEnforcerMockRecorder is the mock recorder for MockPolicyEnforcer.
+type MockPolicyEnforcerMockRecorder struct {
+	mock *MockPolicyEnforcer
+}
+
+// NewMockPolicyEnforcer creates a new mock instance.
+func NewMockPolicyEnforcer(ctrl *gomock.Controller) *MockPolicyEnforcer {
+	mock := &MockPolicyEnforcer{ctrl: ctrl}
+	mock.recorder = &MockPolicyEnforcerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPolicyEnforcer) EXPECT() *MockPolicyEnforcerMockRecorder {
+	return m.recorder
+}
+
+// Enforce mocks base method.
+func (m *MockPolicyEnforcer) Enforce(rvals ...any) (bool, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{}
+	for _, a := range rvals {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Enforce", varargs...)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Enforce indicates an expected call of Enforce.
+func (mr *MockPolicyEnforcerMockRecorder) Enforce(rvals ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enforce", reflect.TypeOf((*MockPolicyEnforcer)(nil).Enforce), rvals...)
+}
+
+// GetImplicitPermissionsForUser mocks base method.
+func (m *MockPolicyEnforcer) GetImplicitPermissionsForUser(user string, domain ...string) ([][]string, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{user}
+	for _, a := range domain {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetImplicitPermissionsForUser", varargs...)
+	ret0, _ := ret[0].([][]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetImplicitPermissionsForUser indicates an expected call of GetImplicitPermissionsForUser.
+func (mr *MockPolicyEnforcerMockRecorder) GetImplicitPermissionsForUser(user any, domain ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{user}, domain...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetImplicitPermissionsForUser", reflect.TypeOf((*MockPolicyEnforcer)(nil).GetImplicitPermissionsForUser), varargs...)
+}
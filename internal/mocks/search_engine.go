@@ -0,0 +1,141 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ../service/search_engine.go
+//
+// Generated by this command:
+//
+//	mockgen -source=../service/search_engine.go -destination=search_engine.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	json "encoding/json"
+	reflect "reflect"
+
+	search "github.com/firdanbash/go-clean-boiler/pkg/search"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockSearchEngine is a mock of SearchEngine interface.
+type MockSearchEngine struct {
+	ctrl     *gomock.Controller
+	recorder *MockSearchEngineMockRecorder
+}
+
+// MockSearchEngineMockRecorder is the mock recorder for MockSearchEngine.
+type MockSearchEngineMockRecorder struct {
+	mock *MockSearchEngine
+}
+
+// NewMockSearchEngine creates a new mock instance.
+func NewMockSearchEngine(ctrl *gomock.Controller) *MockSearchEngine {
+	mock := &MockSearchEngine{ctrl: ctrl}
+	mock.recorder = &MockSearchEngineMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSearchEngine) EXPECT() *MockSearchEngineMockRecorder {
+	return m.recorder
+}
+
+// Bulk mocks base method.
+func (m *MockSearchEngine) Bulk(ctx context.Context, index string, docs []search.Document) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Bulk", ctx, index, docs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Bulk indicates an expected call of Bulk.
+func (mr *MockSearchEngineMockRecorder) Bulk(ctx, index, docs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Bulk", reflect.TypeOf((*MockSearchEngine)(nil).Bulk), ctx, index, docs)
+}
+
+// Delete mocks base method.
+func (m *MockSearchEngine) Delete(ctx context.Context, index, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, index, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockSearchEngineMockRecorder) Delete(ctx, index, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockSearchEngine)(nil).Delete), ctx, index, id)
+}
+
+// DeleteIndex mocks base method.
+func (m *MockSearchEngine) DeleteIndex(ctx context.Context, index string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteIndex", ctx, index)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteIndex indicates an expected call of DeleteIndex.
+func (mr *MockSearchEngineMockRecorder) DeleteIndex(ctx, index any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteIndex", reflect.TypeOf((*MockSearchEngine)(nil).DeleteIndex), ctx, index)
+}
+
+// EnsureIndex mocks base method.
+func (m *MockSearchEngine) EnsureIndex(ctx context.Context, index string, definition json.RawMessage) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnsureIndex", ctx, index, definition)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnsureIndex indicates an expected call of EnsureIndex.
+func (mr *MockSearchEngineMockRecorder) EnsureIndex(ctx, index, definition any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnsureIndex", reflect.TypeOf((*MockSearchEngine)(nil).EnsureIndex), ctx, index, definition)
+}
+
+// Ping mocks base method.
+func (m *MockSearchEngine) Ping(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockSearchEngineMockRecorder) Ping(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockSearchEngine)(nil).Ping), ctx)
+}
+
+// Put mocks base method.
+func (m *MockSearchEngine) Put(ctx context.Context, index, id string, doc any) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Put", ctx, index, id, doc)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Put indicates an expected call of Put.
+func (mr *MockSearchEngineMockRecorder) Put(ctx, index, id, doc any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Put", reflect.TypeOf((*MockSearchEngine)(nil).Put), ctx, index, id, doc)
+}
+
+// Search mocks base method.
+func (m *MockSearchEngine) Search(ctx context.Context, index string, q search.Query) (*search.Result, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", ctx, index, q)
+	ret0, _ := ret[0].(*search.Result)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockSearchEngineMockRecorder) Search(ctx, index, q any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockSearchEngine)(nil).Search), ctx, index, q)
+}
@@ -0,0 +1,115 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ../service/search_service.go
+//
+// Generated by this command:
+//
+//	mockgen -source=../service/search_service.go -destination=search_service.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	request "github.com/firdanbash/go-clean-boiler/internal/dto/request"
+	response "github.com/firdanbash/go-clean-boiler/internal/dto/response"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockSearchService is a mock of SearchService interface.
+type MockSearchService struct {
+	ctrl     *gomock.Controller
+	recorder *MockSearchServiceMockRecorder
+}
+
+// MockSearchServiceMockRecorder is the mock recorder for MockSearchService.
+type MockSearchServiceMockRecorder struct {
+	mock *MockSearchService
+}
+
+// NewMockSearchService creates a new mock instance.
+func NewMockSearchService(ctrl *gomock.Controller) *MockSearchService {
+	mock := &MockSearchService{ctrl: ctrl}
+	mock.recorder = &MockSearchServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSearchService) EXPECT() *MockSearchServiceMockRecorder {
+	return m.recorder
+}
+
+// EnsureIndexes mocks base method.
+func (m *MockSearchService) EnsureIndexes(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnsureIndexes", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnsureIndexes indicates an expected call of EnsureIndexes.
+func (mr *MockSearchServiceMockRecorder) EnsureIndexes(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnsureIndexes", reflect.TypeOf((*MockSearchService)(nil).EnsureIndexes), ctx)
+}
+
+// IndexUser mocks base method.
+func (m *MockSearchService) IndexUser(ctx context.Context, user *response.UserResponse) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IndexUser", ctx, user)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IndexUser indicates an expected call of IndexUser.
+func (mr *MockSearchServiceMockRecorder) IndexUser(ctx, user any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IndexUser", reflect.TypeOf((*MockSearchService)(nil).IndexUser), ctx, user)
+}
+
+// ReindexUsers mocks base method.
+func (m *MockSearchService) ReindexUsers(ctx context.Context, recreate bool) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReindexUsers", ctx, recreate)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReindexUsers indicates an expected call of ReindexUsers.
+func (mr *MockSearchServiceMockRecorder) ReindexUsers(ctx, recreate any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReindexUsers", reflect.TypeOf((*MockSearchService)(nil).ReindexUsers), ctx, recreate)
+}
+
+// RemoveUser mocks base method.
+func (m *MockSearchService) RemoveUser(ctx context.Context, userID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveUser", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveUser indicates an expected call of RemoveUser.
+func (mr *MockSearchServiceMockRecorder) RemoveUser(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveUser", reflect.TypeOf((*MockSearchService)(nil).RemoveUser), ctx, userID)
+}
+
+// SearchUsers mocks base method.
+func (m *MockSearchService) SearchUsers(ctx context.Context, req *request.SearchUsersRequest) ([]response.UserSearchHit, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchUsers", ctx, req)
+	ret0, _ := ret[0].([]response.UserSearchHit)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// SearchUsers indicates an expected call of SearchUsers.
+func (mr *MockSearchServiceMockRecorder) SearchUsers(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchUsers", reflect.TypeOf((*MockSearchService)(nil).SearchUsers), ctx, req)
+}
@@ -0,0 +1,129 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ../service/task_service.go
+//
+// Generated by this command:
+//
+//	mockgen -source=../service/task_service.go -destination=task_service.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	response "github.com/firdanbash/go-clean-boiler/internal/dto/response"
+	service "github.com/firdanbash/go-clean-boiler/internal/service"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockTaskService is a mock of TaskService interface.
+type MockTaskService struct {
+	ctrl     *gomock.Controller
+	recorder *MockTaskServiceMockRecorder
+}
+
+// MockTaskServiceMockRecorder is the mock recorder for MockTaskService.
+type MockTaskServiceMockRecorder struct {
+	mock *MockTaskService
+}
+
+// NewMockTaskService creates a new mock instance.
+func NewMockTaskService(ctrl *gomock.Controller) *MockTaskService {
+	mock := &MockTaskService{ctrl: ctrl}
+	mock.recorder = &MockTaskServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTaskService) EXPECT() *MockTaskServiceMockRecorder {
+	return m.recorder
+}
+
+// Enqueue mocks base method.
+func (m *MockTaskService) Enqueue(ctx context.Context, userID uint, taskType string, payload any) (*response.TaskResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enqueue", ctx, userID, taskType, payload)
+	ret0, _ := ret[0].(*response.TaskResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Enqueue indicates an expected call of Enqueue.
+func (mr *MockTaskServiceMockRecorder) Enqueue(ctx, userID, taskType, payload any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enqueue", reflect.TypeOf((*MockTaskService)(nil).Enqueue), ctx, userID, taskType, payload)
+}
+
+// FailStuck mocks base method.
+func (m *MockTaskService) FailStuck(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FailStuck", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FailStuck indicates an expected call of FailStuck.
+func (mr *MockTaskServiceMockRecorder) FailStuck(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailStuck", reflect.TypeOf((*MockTaskService)(nil).FailStuck), ctx)
+}
+
+// Get mocks base method.
+func (m *MockTaskService) Get(ctx context.Context, userID, id uint) (*response.TaskResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, userID, id)
+	ret0, _ := ret[0].(*response.TaskResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockTaskServiceMockRecorder) Get(ctx, userID, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockTaskService)(nil).Get), ctx, userID, id)
+}
+
+// PurgeCompleted mocks base method.
+func (m *MockTaskService) PurgeCompleted(ctx context.Context, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeCompleted", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeCompleted indicates an expected call of PurgeCompleted.
+func (mr *MockTaskServiceMockRecorder) PurgeCompleted(ctx, before any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeCompleted", reflect.TypeOf((*MockTaskService)(nil).PurgeCompleted), ctx, before)
+}
+
+// Register mocks base method.
+func (m *MockTaskService) Register(taskType string, runner service.TaskRunner) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Register", taskType, runner)
+}
+
+// Register indicates an expected call of Register.
+func (mr *MockTaskServiceMockRecorder) Register(taskType, runner any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockTaskService)(nil).Register), taskType, runner)
+}
+
+// Run mocks base method.
+func (m *MockTaskService) Run(ctx context.Context, id uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Run", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Run indicates an expected call of Run.
+func (mr *MockTaskServiceMockRecorder) Run(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockTaskService)(nil).Run), ctx, id)
+}
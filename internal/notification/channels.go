@@ -0,0 +1,118 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/firdanbash/go-clean-boiler/pkg/config"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"github.com/firdanbash/go-clean-boiler/pkg/mailer"
+	"go.uber.org/zap"
+)
+
+// emailChannel sends notifications with the mailer, rendering the email
+// template named after the type when there is one
+type emailChannel struct {
+	mailer    mailer.Mailer
+	templates *mailer.Templates
+}
+
+// NewEmailChannel creates the email channel
+func NewEmailChannel(m mailer.Mailer, templates *mailer.Templates) Channel {
+	return &emailChannel{mailer: m, templates: templates}
+}
+
+// Send emails n to the recipient
+func (c *emailChannel) Send(ctx context.Context, to *Recipient, n *Notification) error {
+	if to.Email == "" {
+		return ErrNoAddress
+	}
+
+	msg := &mailer.Message{To: []string{to.Email}, Subject: n.Title, Body: n.Body}
+	if c.templates.Has(n.Type) {
+		var err error
+		if msg, err = c.templates.Render(n.Type, []string{to.Email}, n.Data); err != nil {
+			return err
+		}
+	}
+
+	return c.mailer.Send(ctx, msg)
+}
+
+// SMSSender sends text messages. Implement it to add an SMS provider.
+type SMSSender interface {
+	SendSMS(ctx context.Context, phone, text string) error
+}
+
+// smsChannel sends the body of notifications as text messages
+type smsChannel struct {
+	sender SMSSender
+}
+
+// Send texts the body of n to the phone of the recipient
+func (c *smsChannel) Send(ctx context.Context, to *Recipient, n *Notification) error {
+	if to.Phone == "" {
+		return ErrNoAddress
+	}
+	return c.sender.SendSMS(ctx, to.Phone, n.Body)
+}
+
+// PushSender sends push notifications to the devices of a user. Providers
+// such as FCM topics or OneSignal external IDs address users, not devices.
+type PushSender interface {
+	Push(ctx context.Context, userID uint, title, body string) error
+}
+
+// pushChannel sends notifications as push notifications
+type pushChannel struct {
+	sender PushSender
+}
+
+// Send pushes n to the devices of the recipient
+func (c *pushChannel) Send(ctx context.Context, to *Recipient, n *Notification) error {
+	return c.sender.Push(ctx, to.UserID, n.Title, n.Body)
+}
+
+// logSender writes text messages and push notifications to the log
+type logSender struct {
+	log logger.Logger
+}
+
+// SendSMS logs the text message instead of sending it
+func (s *logSender) SendSMS(ctx context.Context, phone, text string) error {
+	logger.Ctx(ctx, s.log).Info("SMS sent", zap.String("phone", phone), zap.String("text", text))
+	return nil
+}
+
+// Push logs the push notification instead of sending it
+func (s *logSender) Push(ctx context.Context, userID uint, title, body string) error {
+	logger.Ctx(ctx, s.log).Info("Push notification sent",
+		zap.Uint("recipient_id", userID),
+		zap.String("title", title),
+		zap.String("body", body),
+	)
+	return nil
+}
+
+// NewChannels creates the channels enabled in cfg, by name. Email is always enabled.
+func NewChannels(cfg config.NotificationConfig, m mailer.Mailer, templates *mailer.Templates, log logger.Logger) (map[string]Channel, error) {
+	channels := map[string]Channel{ChannelEmail: NewEmailChannel(m, templates)}
+
+	switch cfg.SMS.Driver {
+	case "", "none":
+	case "log":
+		channels[ChannelSMS] = &smsChannel{sender: &logSender{log: log}}
+	default:
+		return nil, fmt.Errorf("unsupported sms driver: %s", cfg.SMS.Driver)
+	}
+
+	switch cfg.Push.Driver {
+	case "", "none":
+	case "log":
+		channels[ChannelPush] = &pushChannel{sender: &logSender{log: log}}
+	default:
+		return nil, fmt.Errorf("unsupported push driver: %s", cfg.Push.Driver)
+	}
+
+	return channels, nil
+}
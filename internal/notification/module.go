@@ -0,0 +1,21 @@
+package notification
+
+import (
+	"github.com/firdanbash/go-clean-boiler/pkg/config"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"github.com/firdanbash/go-clean-boiler/pkg/mailer"
+	"go.uber.org/fx"
+)
+
+// Module provides the notifier with the channels enabled in the configuration
+var Module = fx.Module("notification",
+	fx.Provide(
+		newChannels,
+		NewNotifier,
+	),
+)
+
+// newChannels creates the channels enabled in the configuration
+func newChannels(cfg *config.Config, m mailer.Mailer, templates *mailer.Templates, log logger.Logger) (map[string]Channel, error) {
+	return NewChannels(cfg.Notification, m, templates, log)
+}
@@ -0,0 +1,66 @@
+// Package notification sends notifications to users over the channels they
+// chose: email, SMS and push. Services call Notify with the type of the
+// notification; the preferences of the user, stored in the database, and the
+// defaults of the type decide the channels it goes out on.
+package notification
+
+import (
+	"context"
+	"errors"
+)
+
+// Channels
+const (
+	ChannelEmail = "email"
+	ChannelSMS   = "sms"
+	ChannelPush  = "push"
+)
+
+// Notification types
+const (
+	TypeWelcome            = "welcome"
+	TypePasswordChanged    = "password_changed"
+	TypeOrganizationInvite = "organization_invite"
+	TypeDataExportReady    = "data_export_ready"
+)
+
+// Type describes a kind of notification
+type Type struct {
+	Channels []string // channels used unless the user turned them off
+	Required bool     // users cannot turn off the default channels, e.g. for security notices
+}
+
+// Types are the notification types services send. Users may turn on the
+// other channels for a type, and turn off the default ones unless it is Required.
+var Types = map[string]Type{
+	TypeWelcome:            {Channels: []string{ChannelEmail}},
+	TypePasswordChanged:    {Channels: []string{ChannelEmail, ChannelPush}, Required: true},
+	TypeOrganizationInvite: {Channels: []string{ChannelEmail, ChannelPush}},
+	TypeDataExportReady:    {Channels: []string{ChannelEmail, ChannelPush}},
+}
+
+// Notification is a message to a user
+type Notification struct {
+	Type   string
+	UserID uint
+	Title  string      // subject of the email and title of the push notification
+	Body   string      // text of the SMS and push notification, and of the email without a template
+	Data   interface{} // data of the email template named after the type
+}
+
+// Recipient is the user a notification is addressed to
+type Recipient struct {
+	UserID uint
+	Name   string
+	Email  string
+	Phone  string // empty when the user has no phone number
+}
+
+// ErrNoAddress is returned by a channel that cannot reach the recipient, such
+// as SMS to a user without a phone number. The channel is skipped.
+var ErrNoAddress = errors.New("recipient has no address on this channel")
+
+// Channel delivers notifications
+type Channel interface {
+	Send(ctx context.Context, to *Recipient, n *Notification) error
+}
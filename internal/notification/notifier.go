@@ -0,0 +1,167 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/repository"
+	"github.com/firdanbash/go-clean-boiler/pkg/apperror"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"github.com/firdanbash/go-clean-boiler/pkg/tracing"
+	"go.uber.org/zap"
+)
+
+// Errors returned when changing preferences
+var (
+	ErrUnknownType     = apperror.Validation("unknown notification type").WithCode(apperror.CodeNotificationTypeUnknown)
+	ErrUnknownChannel  = apperror.Validation("unknown or disabled notification channel").WithCode(apperror.CodeNotificationChannelUnknown)
+	ErrRequiredChannel = apperror.Validation("this notification cannot be turned off on this channel").WithCode(apperror.CodeNotificationChannelRequired)
+)
+
+// channelOrder is the order channels are listed and used in
+var channelOrder = []string{ChannelEmail, ChannelSMS, ChannelPush}
+
+// Preference is whether a user receives a type of notification on a channel
+type Preference struct {
+	Type     string
+	Channel  string
+	Enabled  bool
+	Required bool // the user cannot turn it off
+}
+
+// Notifier sends notifications to users on the channels they chose
+type Notifier interface {
+	// Notify sends n on the channels enabled for its type and user. Failures
+	// are logged rather than returned so notifying never breaks the operation
+	// it is about.
+	Notify(ctx context.Context, n Notification)
+	Preferences(ctx context.Context, userID uint) ([]Preference, error)
+	SetPreferences(ctx context.Context, userID uint, preferences []Preference) error
+}
+
+type notifier struct {
+	channels    map[string]Channel
+	preferences repository.NotificationPreferenceRepository
+	users       repository.UserRepository
+	log         logger.Logger
+}
+
+// NewNotifier creates a notifier sending on channels, keyed by channel name
+func NewNotifier(
+	channels map[string]Channel,
+	preferences repository.NotificationPreferenceRepository,
+	users repository.UserRepository,
+	log logger.Logger,
+) Notifier {
+	return &notifier{channels: channels, preferences: preferences, users: users, log: log}
+}
+
+// Notify sends n on the channels enabled for its type and user
+func (s *notifier) Notify(ctx context.Context, n Notification) {
+	ctx, span := tracing.Start(ctx, "notification."+n.Type)
+	defer span.End()
+
+	// Detach from request cancellation so the notification goes out even if the client went away
+	ctx = context.WithoutCancel(ctx)
+	log := logger.Ctx(ctx, s.log).With(zap.String("notification", n.Type), zap.Uint("recipient_id", n.UserID))
+
+	if _, ok := Types[n.Type]; !ok {
+		log.Error("Unknown notification type")
+		return
+	}
+
+	preferences, err := s.Preferences(ctx, n.UserID)
+	if err != nil {
+		log.Error("Failed to load notification preferences", zap.Error(err))
+		return
+	}
+
+	user, err := s.users.FindByID(ctx, n.UserID)
+	if err != nil {
+		log.Error("Failed to load notification recipient", zap.Error(err))
+		return
+	}
+	to := &Recipient{UserID: user.ID, Name: user.Name, Email: user.Email}
+
+	for _, p := range preferences {
+		if p.Type != n.Type || !p.Enabled {
+			continue
+		}
+		err := s.channels[p.Channel].Send(ctx, to, &n)
+		switch {
+		case errors.Is(err, ErrNoAddress):
+			log.Debug("Notification channel skipped, recipient has no address", zap.String("channel", p.Channel))
+		case err != nil:
+			log.Error("Failed to send notification", zap.String("channel", p.Channel), zap.Error(err))
+		}
+	}
+}
+
+// Preferences lists, for every notification type and enabled channel, whether
+// the user receives it: the stored choice of the user, or the default of the type
+func (s *notifier) Preferences(ctx context.Context, userID uint) ([]Preference, error) {
+	stored, err := s.preferences.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	choices := make(map[[2]string]bool, len(stored))
+	for _, p := range stored {
+		choices[[2]string{p.Type, p.Channel}] = p.Enabled
+	}
+
+	names := make([]string, 0, len(Types))
+	for name := range Types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var preferences []Preference
+	for _, name := range names {
+		t := Types[name]
+		for _, channel := range channelOrder {
+			if _, ok := s.channels[channel]; !ok {
+				continue
+			}
+			p := Preference{Type: name, Channel: channel, Enabled: contains(t.Channels, channel)}
+			p.Required = p.Enabled && t.Required
+			if enabled, ok := choices[[2]string{name, channel}]; ok && !p.Required {
+				p.Enabled = enabled
+			}
+			preferences = append(preferences, p)
+		}
+	}
+
+	return preferences, nil
+}
+
+// SetPreferences stores the choices of a user. Required notifications cannot be turned off.
+func (s *notifier) SetPreferences(ctx context.Context, userID uint, preferences []Preference) error {
+	rows := make([]domain.NotificationPreference, 0, len(preferences))
+	for _, p := range preferences {
+		t, ok := Types[p.Type]
+		if !ok {
+			return ErrUnknownType
+		}
+		if _, ok := s.channels[p.Channel]; !ok {
+			return ErrUnknownChannel
+		}
+		if t.Required && contains(t.Channels, p.Channel) && !p.Enabled {
+			return ErrRequiredChannel
+		}
+		rows = append(rows, domain.NotificationPreference{UserID: userID, Type: p.Type, Channel: p.Channel, Enabled: p.Enabled})
+	}
+
+	return s.preferences.Upsert(ctx, rows)
+}
+
+// contains reports whether values holds value
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,165 @@
+package notification_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/mocks"
+	"github.com/firdanbash/go-clean-boiler/internal/notification"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"go.uber.org/mock/gomock"
+)
+
+// channelStub records the notifications sent on a channel
+type channelStub struct {
+	sent []string
+	err  error
+}
+
+func (c *channelStub) Send(_ context.Context, to *notification.Recipient, n *notification.Notification) error {
+	if c.err != nil {
+		return c.err
+	}
+	c.sent = append(c.sent, n.Type+":"+to.Email)
+	return nil
+}
+
+type notifierDeps struct {
+	preferences *mocks.MockNotificationPreferenceRepository
+	users       *mocks.MockUserRepository
+	email       *channelStub
+	sms         *channelStub
+	push        *channelStub
+}
+
+func newNotifier(t *testing.T) (notification.Notifier, notifierDeps) {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+	deps := notifierDeps{
+		preferences: mocks.NewMockNotificationPreferenceRepository(ctrl),
+		users:       mocks.NewMockUserRepository(ctrl),
+		email:       &channelStub{},
+		sms:         &channelStub{err: notification.ErrNoAddress},
+		push:        &channelStub{},
+	}
+	channels := map[string]notification.Channel{
+		notification.ChannelEmail: deps.email,
+		notification.ChannelSMS:   deps.sms,
+		notification.ChannelPush:  deps.push,
+	}
+	return notification.NewNotifier(channels, deps.preferences, deps.users, logger.Nop()), deps
+}
+
+func TestNotifierNotify(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("sends on the default channels of the type", func(t *testing.T) {
+		notifier, deps := newNotifier(t)
+
+		deps.preferences.EXPECT().FindByUserID(gomock.Any(), uint(3)).Return(nil, nil)
+		deps.users.EXPECT().FindByID(gomock.Any(), uint(3)).Return(&domain.User{ID: 3, Email: "jane@example.com"}, nil)
+
+		notifier.Notify(ctx, notification.Notification{Type: notification.TypePasswordChanged, UserID: 3})
+
+		if len(deps.email.sent) != 1 || len(deps.push.sent) != 1 {
+			t.Fatalf("sent %v by email and %v by push, want one each", deps.email.sent, deps.push.sent)
+		}
+	})
+
+	t.Run("follows the choices of the user", func(t *testing.T) {
+		notifier, deps := newNotifier(t)
+
+		deps.preferences.EXPECT().FindByUserID(gomock.Any(), uint(3)).Return([]domain.NotificationPreference{
+			{UserID: 3, Type: notification.TypeWelcome, Channel: notification.ChannelEmail, Enabled: false},
+			{UserID: 3, Type: notification.TypeWelcome, Channel: notification.ChannelPush, Enabled: true},
+		}, nil)
+		deps.users.EXPECT().FindByID(gomock.Any(), uint(3)).Return(&domain.User{ID: 3, Email: "jane@example.com"}, nil)
+
+		notifier.Notify(ctx, notification.Notification{Type: notification.TypeWelcome, UserID: 3})
+
+		if len(deps.email.sent) != 0 || len(deps.push.sent) != 1 {
+			t.Fatalf("sent %v by email and %v by push, want push only", deps.email.sent, deps.push.sent)
+		}
+	})
+}
+
+func TestNotifierPreferences(t *testing.T) {
+	notifier, deps := newNotifier(t)
+
+	// Turning off a required channel is ignored
+	deps.preferences.EXPECT().FindByUserID(gomock.Any(), uint(3)).Return([]domain.NotificationPreference{
+		{UserID: 3, Type: notification.TypePasswordChanged, Channel: notification.ChannelEmail, Enabled: false},
+		{UserID: 3, Type: notification.TypePasswordChanged, Channel: notification.ChannelSMS, Enabled: true},
+	}, nil)
+
+	preferences, err := notifier.Preferences(context.Background(), 3)
+	if err != nil {
+		t.Fatalf("Preferences() error = %v", err)
+	}
+
+	got := map[string]notification.Preference{}
+	for _, p := range preferences {
+		got[p.Type+"/"+p.Channel] = p
+	}
+	want := map[string]notification.Preference{
+		"data_export_ready/email":   {Type: "data_export_ready", Channel: "email", Enabled: true},
+		"data_export_ready/sms":     {Type: "data_export_ready", Channel: "sms"},
+		"data_export_ready/push":    {Type: "data_export_ready", Channel: "push", Enabled: true},
+		"organization_invite/email": {Type: "organization_invite", Channel: "email", Enabled: true},
+		"organization_invite/sms":   {Type: "organization_invite", Channel: "sms"},
+		"organization_invite/push":  {Type: "organization_invite", Channel: "push", Enabled: true},
+		"password_changed/email":    {Type: "password_changed", Channel: "email", Enabled: true, Required: true},
+		"password_changed/sms":      {Type: "password_changed", Channel: "sms", Enabled: true},
+		"password_changed/push":     {Type: "password_changed", Channel: "push", Enabled: true, Required: true},
+		"welcome/email":             {Type: "welcome", Channel: "email", Enabled: true},
+		"welcome/sms":               {Type: "welcome", Channel: "sms"},
+		"welcome/push":              {Type: "welcome", Channel: "push"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Preferences() = %+v, want %+v", preferences, want)
+	}
+	for key, p := range want {
+		if got[key] != p {
+			t.Errorf("preference %s = %+v, want %+v", key, got[key], p)
+		}
+	}
+}
+
+func TestNotifierSetPreferences(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("stores the choices", func(t *testing.T) {
+		notifier, deps := newNotifier(t)
+
+		deps.preferences.EXPECT().Upsert(gomock.Any(), []domain.NotificationPreference{
+			{UserID: 3, Type: notification.TypeWelcome, Channel: notification.ChannelEmail, Enabled: false},
+		}).Return(nil)
+
+		err := notifier.SetPreferences(ctx, 3, []notification.Preference{
+			{Type: notification.TypeWelcome, Channel: notification.ChannelEmail, Enabled: false},
+		})
+		if err != nil {
+			t.Fatalf("SetPreferences() error = %v", err)
+		}
+	})
+
+	for name, tc := range map[string]struct {
+		preference notification.Preference
+		want       error
+	}{
+		"an unknown type":                {notification.Preference{Type: "newsletter", Channel: "email"}, notification.ErrUnknownType},
+		"an unknown channel":             {notification.Preference{Type: "welcome", Channel: "fax"}, notification.ErrUnknownChannel},
+		"turning off a required channel": {notification.Preference{Type: "password_changed", Channel: "email"}, notification.ErrRequiredChannel},
+	} {
+		t.Run("rejects "+name, func(t *testing.T) {
+			notifier, _ := newNotifier(t)
+
+			err := notifier.SetPreferences(ctx, 3, []notification.Preference{tc.preference})
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("SetPreferences() error = %v, want %v", err, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,8 @@
+package outbox
+
+import "go.uber.org/fx"
+
+// Module provides the relay of the outbox
+var Module = fx.Module("outbox",
+	fx.Provide(NewRelay),
+)
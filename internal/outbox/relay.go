@@ -0,0 +1,141 @@
+// Package outbox relays the domain events stored in the outbox table to the
+// message broker. Services store an event in the same transaction as the change
+// it describes, so an event is published at least once for every committed
+// change, even when the process stops right after the commit.
+package outbox
+
+import (
+	"context"
+	"expvar"
+	"strconv"
+	"time"
+
+	"github.com/firdanbash/go-clean-boiler/internal/repository"
+	"github.com/firdanbash/go-clean-boiler/pkg/config"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"github.com/firdanbash/go-clean-boiler/pkg/messaging"
+	"go.uber.org/zap"
+)
+
+// metrics counts the relayed messages, served at /debug/vars
+var metrics = expvar.NewMap("outbox")
+
+// Relay polls the outbox and publishes pending messages in insertion order
+type Relay struct {
+	repo      repository.OutboxRepository
+	tx        repository.Transactor
+	publisher messaging.Publisher
+	cfg       config.OutboxConfig
+	log       logger.Logger
+	cancel    context.CancelFunc
+	done      chan struct{}
+}
+
+// NewRelay creates a relay publishing outbox messages with publisher
+func NewRelay(
+	repo repository.OutboxRepository,
+	tx repository.Transactor,
+	publisher messaging.Publisher,
+	cfg *config.Config,
+	log logger.Logger,
+) *Relay {
+	return &Relay{repo: repo, tx: tx, publisher: publisher, cfg: cfg.Outbox, log: log}
+}
+
+// Start polls the outbox in the background until Stop is called
+func (r *Relay) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	r.done = make(chan struct{})
+
+	go func() {
+		defer close(r.done)
+		ticker := time.NewTicker(r.cfg.PollInterval)
+		defer ticker.Stop()
+
+		for {
+			// Drain the backlog batch by batch, then wait for the next poll
+			published, err := r.RelayPending(ctx)
+			if err != nil && ctx.Err() == nil {
+				r.log.Error("Failed to relay outbox messages", zap.Error(err))
+			}
+			if err == nil && published == r.cfg.BatchSize {
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops polling and waits for the batch in flight or for ctx to be done
+func (r *Relay) Stop(ctx context.Context) error {
+	if r.cancel == nil {
+		return nil
+	}
+	r.cancel()
+
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// RelayPending publishes one batch of pending messages and returns how many
+// were published. It stops at the first message the broker rejects, so
+// messages are published in order; that message is retried on the next poll
+// until it has failed MaxAttempts times.
+func (r *Relay) RelayPending(ctx context.Context) (int, error) {
+	published := 0
+	err := r.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		messages, err := r.repo.FindPending(ctx, r.cfg.BatchSize, r.cfg.MaxAttempts)
+		if err != nil {
+			return err
+		}
+
+		for i := range messages {
+			message := &messages[i]
+			err := r.publisher.Publish(ctx, &messaging.Message{
+				ID:      strconv.FormatUint(uint64(message.ID), 10),
+				Topic:   message.Topic,
+				Key:     message.Key,
+				Body:    message.Payload,
+				Headers: map[string]string{"content-type": "application/json"},
+			})
+			if err != nil {
+				metrics.Add("failed", 1)
+				r.logFailure(message.ID, message.Topic, message.Attempts+1, err)
+				return r.repo.MarkFailed(ctx, message.ID, err.Error())
+			}
+
+			if err := r.repo.MarkPublished(ctx, message.ID); err != nil {
+				return err
+			}
+			metrics.Add("published", 1)
+			published++
+		}
+		return nil
+	})
+	return published, err
+}
+
+// logFailure logs a rejected message, as an error once it will not be retried
+func (r *Relay) logFailure(id uint, topic string, attempts int, err error) {
+	fields := []zap.Field{
+		zap.Uint("id", id),
+		zap.String("topic", topic),
+		zap.Int("attempts", attempts),
+		zap.Error(err),
+	}
+	if r.cfg.MaxAttempts > 0 && attempts >= r.cfg.MaxAttempts {
+		r.log.Error("Giving up on outbox message", fields...)
+		return
+	}
+	r.log.Warn("Failed to publish outbox message, will retry", fields...)
+}
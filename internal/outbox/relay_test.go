@@ -0,0 +1,85 @@
+package outbox_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/mocks"
+	"github.com/firdanbash/go-clean-boiler/internal/outbox"
+	"github.com/firdanbash/go-clean-boiler/internal/testutil"
+	"github.com/firdanbash/go-clean-boiler/pkg/config"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"github.com/firdanbash/go-clean-boiler/pkg/messaging"
+	"go.uber.org/mock/gomock"
+)
+
+// brokerStub records published messages and rejects the topics in fail
+type brokerStub struct {
+	published []*messaging.Message
+	fail      map[string]error
+}
+
+func (b *brokerStub) Publish(_ context.Context, msg *messaging.Message) error {
+	if err := b.fail[msg.Topic]; err != nil {
+		return err
+	}
+	b.published = append(b.published, msg)
+	return nil
+}
+
+func (b *brokerStub) Close() error { return nil }
+
+func newRelay(t *testing.T) (*outbox.Relay, *mocks.MockOutboxRepository, *brokerStub) {
+	t.Helper()
+	repo := mocks.NewMockOutboxRepository(gomock.NewController(t))
+	broker := &brokerStub{fail: map[string]error{}}
+	cfg := &config.Config{Outbox: config.OutboxConfig{BatchSize: 10, MaxAttempts: 3}}
+	return outbox.NewRelay(repo, testutil.Transactor(), broker, cfg, logger.Nop()), repo, broker
+}
+
+func TestRelayPublishesPendingMessages(t *testing.T) {
+	relay, repo, broker := newRelay(t)
+
+	repo.EXPECT().FindPending(gomock.Any(), 10, 3).Return([]domain.OutboxMessage{
+		{ID: 1, Topic: domain.EventUserCreated, Key: "7", Payload: []byte(`{"type":"user.created"}`)},
+		{ID: 2, Topic: domain.EventUserUpdated, Key: "7", Payload: []byte(`{"type":"user.updated"}`)},
+	}, nil)
+	gomock.InOrder(
+		repo.EXPECT().MarkPublished(gomock.Any(), uint(1)).Return(nil),
+		repo.EXPECT().MarkPublished(gomock.Any(), uint(2)).Return(nil),
+	)
+
+	published, err := relay.RelayPending(context.Background())
+	if err != nil {
+		t.Fatalf("RelayPending() error = %v", err)
+	}
+	if published != 2 || len(broker.published) != 2 {
+		t.Fatalf("published %d messages (broker got %d), want 2", published, len(broker.published))
+	}
+	if msg := broker.published[0]; msg.ID != "1" || msg.Topic != domain.EventUserCreated || msg.Key != "7" {
+		t.Errorf("first message = %+v, want id 1 on %s keyed 7", msg, domain.EventUserCreated)
+	}
+}
+
+func TestRelayStopsAtRejectedMessage(t *testing.T) {
+	relay, repo, broker := newRelay(t)
+	broker.fail[domain.EventUserUpdated] = errors.New("channel closed")
+
+	repo.EXPECT().FindPending(gomock.Any(), 10, 3).Return([]domain.OutboxMessage{
+		{ID: 1, Topic: domain.EventUserCreated},
+		{ID: 2, Topic: domain.EventUserUpdated},
+		{ID: 3, Topic: domain.EventUserDeleted},
+	}, nil)
+	repo.EXPECT().MarkPublished(gomock.Any(), uint(1)).Return(nil)
+	repo.EXPECT().MarkFailed(gomock.Any(), uint(2), "channel closed").Return(nil)
+
+	published, err := relay.RelayPending(context.Background())
+	if err != nil {
+		t.Fatalf("RelayPending() error = %v", err)
+	}
+	if published != 1 || len(broker.published) != 1 {
+		t.Errorf("published %d messages (broker got %d), want only the one before the rejected message", published, len(broker.published))
+	}
+}
@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+)
+
+// AuditLogFilter holds filter options for listing audit logs
+type AuditLogFilter struct {
+	ActorID    *uint
+	Action     string
+	EntityType string
+	EntityID   *uint
+	From       *time.Time
+	To         *time.Time
+}
+
+// AuditLogRepository defines the interface for audit log data access
+type AuditLogRepository interface {
+	Create(ctx context.Context, log *domain.AuditLog) error
+	FindAll(ctx context.Context, filter AuditLogFilter, limit, offset int) ([]domain.AuditLog, int64, error)
+}
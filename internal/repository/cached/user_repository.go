@@ -0,0 +1,180 @@
+package cached
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/repository"
+	"github.com/firdanbash/go-clean-boiler/pkg/cache"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"github.com/firdanbash/go-clean-boiler/pkg/reqctx"
+	"go.uber.org/zap"
+)
+
+type userRepository struct {
+	repository.UserRepository
+	cache cache.Cache
+	ttl   time.Duration
+	log   logger.Logger
+}
+
+// NewUserRepository wraps a user repository with a cache-aside layer for FindByID and FindByEmail.
+// Users are cached by ID; the email key only stores the ID, so a changed email never serves a stale user.
+// Cached users leave out the password hash and MFA secret: lookups with a
+// repository.WithSecrets context read the database.
+func NewUserRepository(repo repository.UserRepository, c cache.Cache, ttl time.Duration, log logger.Logger) repository.UserRepository {
+	return &userRepository{UserRepository: repo, cache: c, ttl: ttl, log: log}
+}
+
+// FindByID finds a user by ID, serving from the cache when possible
+func (r *userRepository) FindByID(ctx context.Context, id uint) (*domain.User, error) {
+	if !repository.WantsSecrets(ctx) {
+		if user, ok := r.getUser(ctx, id); ok {
+			return user, nil
+		}
+	}
+
+	user, err := r.UserRepository.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	r.setUser(ctx, user)
+	return user, nil
+}
+
+// FindByEmail finds a user by email, serving from the cache when possible
+func (r *userRepository) FindByEmail(ctx context.Context, email string) (*domain.User, error) {
+	tenantID, _ := reqctx.TenantID(ctx)
+	if !repository.WantsSecrets(ctx) {
+		if id, err := cache.GetJSON[uint](ctx, r.cache, emailKey(tenantID, email)); err == nil {
+			if user, ok := r.getUser(ctx, id); ok && user.Email == email {
+				return user, nil
+			}
+		}
+	}
+
+	user, err := r.UserRepository.FindByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+
+	r.setUser(ctx, user)
+	return user, nil
+}
+
+// Update updates a user and evicts the cached copy
+func (r *userRepository) Update(ctx context.Context, user *domain.User) error {
+	if err := r.UserRepository.Update(ctx, user); err != nil {
+		return err
+	}
+	r.evict(ctx, user.ID)
+	return nil
+}
+
+// Delete soft deletes a user and evicts the cached copy
+func (r *userRepository) Delete(ctx context.Context, id uint) error {
+	if err := r.UserRepository.Delete(ctx, id); err != nil {
+		return err
+	}
+	r.evict(ctx, id)
+	return nil
+}
+
+// Restore restores a soft deleted user and evicts any cached copy
+func (r *userRepository) Restore(ctx context.Context, id uint) error {
+	if err := r.UserRepository.Restore(ctx, id); err != nil {
+		return err
+	}
+	r.evict(ctx, id)
+	return nil
+}
+
+// HardDelete permanently deletes a user and evicts the cached copy
+func (r *userRepository) HardDelete(ctx context.Context, id uint) error {
+	if err := r.UserRepository.HardDelete(ctx, id); err != nil {
+		return err
+	}
+	r.evict(ctx, id)
+	return nil
+}
+
+// getUser loads a user from the cache. Cache failures, and users of another
+// tenant than the one of ctx, are treated as misses.
+func (r *userRepository) getUser(ctx context.Context, id uint) (*domain.User, bool) {
+	data, err := r.cache.Get(ctx, idKey(id))
+	if err != nil {
+		if !errors.Is(err, cache.ErrMiss) {
+			logger.Ctx(ctx, r.log).Warn("User cache read failed", zap.Uint("cached_user_id", id), zap.Error(err))
+		}
+		return nil, false
+	}
+
+	// gob keeps fields hidden from JSON, such as the token version
+	var user domain.User
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&user); err != nil {
+		logger.Ctx(ctx, r.log).Warn("User cache entry is corrupt", zap.Uint("cached_user_id", id), zap.Error(err))
+		return nil, false
+	}
+	if tenantID, ok := reqctx.TenantID(ctx); ok && user.TenantID != tenantID {
+		return nil, false
+	}
+	user.Password, user.MFASecret = "", ""
+	return &user, true
+}
+
+// setUser stores a user, without its secrets, and its email index in the cache
+func (r *userRepository) setUser(ctx context.Context, user *domain.User) {
+	cached := *user
+	cached.Password, cached.MFASecret = "", ""
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&cached); err != nil {
+		logger.Ctx(ctx, r.log).Warn("User cache encode failed", zap.Uint("cached_user_id", user.ID), zap.Error(err))
+		return
+	}
+
+	if err := r.cache.Set(ctx, idKey(user.ID), buf.Bytes(), r.ttl); err != nil {
+		logger.Ctx(ctx, r.log).Warn("User cache write failed", zap.Uint("cached_user_id", user.ID), zap.Error(err))
+		return
+	}
+	if err := cache.SetJSON(ctx, r.cache, emailKey(user.TenantID, user.Email), user.ID, r.ttl); err != nil {
+		logger.Ctx(ctx, r.log).Warn("User cache write failed", zap.Uint("cached_user_id", user.ID), zap.Error(err))
+	}
+}
+
+// IncrementTokenVersion invalidates the tokens of a user and evicts the cached
+// copy, so new tokens carry the new version
+func (r *userRepository) IncrementTokenVersion(ctx context.Context, id uint) error {
+	if err := r.UserRepository.IncrementTokenVersion(ctx, id); err != nil {
+		return err
+	}
+	r.evict(ctx, id)
+	return nil
+}
+
+// evict removes a cached user once the transaction of ctx commits, so lookups
+// made before then cannot cache the old user again. Email index entries are
+// left to expire, as lookups verify them.
+func (r *userRepository) evict(ctx context.Context, id uint) {
+	repository.AfterCommit(ctx, func() {
+		if err := r.cache.Delete(ctx, idKey(id)); err != nil {
+			logger.Ctx(ctx, r.log).Warn("User cache eviction failed", zap.Uint("cached_user_id", id), zap.Error(err))
+		}
+	})
+}
+
+// idKey returns the cache key of a user
+func idKey(id uint) string {
+	return fmt.Sprintf("users:id:%d", id)
+}
+
+// emailKey returns the cache key mapping an email of a tenant to a user ID
+func emailKey(tenantID uint, email string) string {
+	return fmt.Sprintf("users:email:%d:%s", tenantID, email)
+}
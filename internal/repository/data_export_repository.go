@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+)
+
+// DataExportRepository defines the interface for personal data export access
+type DataExportRepository interface {
+	Create(ctx context.Context, export *domain.DataExport) error
+	FindByID(ctx context.Context, userID, id uint) (*domain.DataExport, error)
+	FindLatest(ctx context.Context, userID uint) (*domain.DataExport, error)
+	FindExpired(ctx context.Context, before time.Time, limit int) ([]domain.DataExport, error)
+	Update(ctx context.Context, export *domain.DataExport) error
+	Delete(ctx context.Context, id uint) error
+}
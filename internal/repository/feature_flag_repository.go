@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+)
+
+// FeatureFlagRepository defines the interface for runtime feature flag data access
+type FeatureFlagRepository interface {
+	FindAll(ctx context.Context) ([]domain.FeatureFlag, error)
+	Upsert(ctx context.Context, flag *domain.FeatureFlag) error
+}
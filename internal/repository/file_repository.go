@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+)
+
+// FileSortFields whitelists the fields files can be sorted by, mapped to their columns
+var FileSortFields = map[string]string{
+	"id":         "id",
+	"name":       "name",
+	"size":       "size",
+	"created_at": "created_at",
+}
+
+// FileFilter holds filter and sort options for listing files
+type FileFilter struct {
+	OwnerID *uint
+	Sort    []SortField
+}
+
+// FileRepository defines the interface for file metadata access
+type FileRepository interface {
+	Create(ctx context.Context, file *domain.File) error
+	FindByID(ctx context.Context, id uint) (*domain.File, error)
+	FindByKey(ctx context.Context, key string) (*domain.File, error)
+	FindAll(ctx context.Context, filter FileFilter, limit, offset int) ([]domain.File, int64, error)
+	Delete(ctx context.Context, id uint) error
+}
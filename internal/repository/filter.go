@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"strings"
+
+	"github.com/firdanbash/go-clean-boiler/pkg/apperror"
+)
+
+// ErrInvalidSortField is returned when a sort field is not in the whitelist
+var ErrInvalidSortField = apperror.Validation("invalid sort field").WithCode(apperror.CodeSortFieldInvalid)
+
+// SortField represents a single ORDER BY column
+type SortField struct {
+	Column string
+	Desc   bool
+}
+
+// ParseSort parses a comma separated sort expression such as "name,-created_at".
+// A leading "-" sorts descending. Only fields present in allowed are accepted and
+// are mapped to their column name, so user input never reaches the SQL directly.
+func ParseSort(sort string, allowed map[string]string) ([]SortField, error) {
+	if strings.TrimSpace(sort) == "" {
+		return nil, nil
+	}
+
+	var fields []SortField
+	for _, part := range strings.Split(sort, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		desc := strings.HasPrefix(part, "-")
+		name := strings.TrimPrefix(strings.TrimPrefix(part, "-"), "+")
+
+		column, ok := allowed[name]
+		if !ok {
+			return nil, ErrInvalidSortField
+		}
+
+		fields = append(fields, SortField{Column: column, Desc: desc})
+	}
+
+	return fields, nil
+}
@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+)
+
+// LoginEventRepository defines the interface for login history data access
+type LoginEventRepository interface {
+	Create(ctx context.Context, event *domain.LoginEvent) error
+	FindByUserID(ctx context.Context, userID uint, limit, offset int) ([]domain.LoginEvent, int64, error)
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+)
+
+// MembershipRepository defines the interface for organization membership data access
+type MembershipRepository interface {
+	Create(ctx context.Context, membership *domain.Membership) error
+	Find(ctx context.Context, orgID, userID uint) (*domain.Membership, error)
+	FindByUser(ctx context.Context, userID uint, orgIDs []uint) ([]domain.Membership, error)
+	FindByOrganization(ctx context.Context, orgID uint) ([]domain.Membership, error)
+	CountByRole(ctx context.Context, orgID uint, role string) (int64, error)
+	UpdateRole(ctx context.Context, id uint, role string) error
+}
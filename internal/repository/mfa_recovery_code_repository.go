@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+)
+
+// MFARecoveryCodeRepository defines the interface for MFA recovery code data access
+type MFARecoveryCodeRepository interface {
+	ReplaceForUser(ctx context.Context, userID uint, codeHashes []string) error
+	FindUnused(ctx context.Context, userID uint, codeHash string) (*domain.MFARecoveryCode, error)
+	MarkUsed(ctx context.Context, id uint) (bool, error)
+	DeleteByUserID(ctx context.Context, userID uint) error
+}
@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+)
+
+// NotificationPreferenceRepository defines the interface for notification preference data access
+type NotificationPreferenceRepository interface {
+	FindByUserID(ctx context.Context, userID uint) ([]domain.NotificationPreference, error)
+	Upsert(ctx context.Context, preferences []domain.NotificationPreference) error
+}
@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+)
+
+// OrganizationRepository defines the interface for organization data access
+type OrganizationRepository interface {
+	Create(ctx context.Context, org *domain.Organization) error
+	FindByID(ctx context.Context, id uint) (*domain.Organization, error)
+	FindByMember(ctx context.Context, userID uint, limit, offset int) ([]domain.Organization, int64, error)
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+)
+
+// OutboxRepository defines the interface for the transactional outbox
+type OutboxRepository interface {
+	Create(ctx context.Context, message *domain.OutboxMessage) error
+	FindPending(ctx context.Context, limit, maxAttempts int) ([]domain.OutboxMessage, error)
+	MarkPublished(ctx context.Context, id uint) error
+	MarkFailed(ctx context.Context, id uint, reason string) error
+	DeletePublishedBefore(ctx context.Context, before time.Time) (int64, error)
+}
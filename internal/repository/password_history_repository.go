@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+)
+
+// PasswordHistoryRepository defines the interface for password history data access
+type PasswordHistoryRepository interface {
+	Create(ctx context.Context, entry *domain.PasswordHistory) error
+	FindRecent(ctx context.Context, userID uint, limit int) ([]domain.PasswordHistory, error)
+	Prune(ctx context.Context, keep int) (int64, error)
+}
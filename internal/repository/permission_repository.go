@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+)
+
+// PermissionRepository defines the interface for permission data access
+type PermissionRepository interface {
+	Create(ctx context.Context, permission *domain.Permission) error
+	FindByID(ctx context.Context, id uint) (*domain.Permission, error)
+	FindByName(ctx context.Context, name string) (*domain.Permission, error)
+	FindByNames(ctx context.Context, names []string) ([]domain.Permission, error)
+	FindAll(ctx context.Context) ([]domain.Permission, error)
+	Delete(ctx context.Context, id uint) error
+}
@@ -0,0 +1,94 @@
+package postgres
+
+import (
+	"reflect"
+	"slices"
+
+	"github.com/firdanbash/go-clean-boiler/pkg/reqctx"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// Columns of the models embedding domain.Auditable
+const (
+	createdByColumn = "created_by"
+	updatedByColumn = "updated_by"
+)
+
+// RegisterAuditable registers the callbacks stamping the user of the request
+// context on the rows of models with created_by and updated_by columns: both
+// are set on create, unless created_by was set by the caller, and updated_by
+// on update. Statements without a user in context, UpdateColumn and Raw or
+// Exec SQL leave them untouched.
+func RegisterAuditable(db *gorm.DB) error {
+	callbacks := db.Callback()
+	if err := callbacks.Create().Before("gorm:create").Register("auditable:create", stampCreator); err != nil {
+		return err
+	}
+	return callbacks.Update().Before("gorm:update").Register("auditable:update", stampUpdater)
+}
+
+// auditableActor returns the user of the statement; false when the statement
+// is not stamped
+func auditableActor(db *gorm.DB) (uint, bool) {
+	if db.Error != nil || db.Statement.Schema == nil || db.Statement.SkipHooks {
+		return 0, false
+	}
+	return reqctx.UserID(db.Statement.Context)
+}
+
+// stampCreator sets the creator and last updater of the rows being created
+func stampCreator(db *gorm.DB) {
+	actorID, ok := auditableActor(db)
+	if !ok {
+		return
+	}
+	createdBy := db.Statement.Schema.LookUpField(createdByColumn)
+	updatedBy := db.Statement.Schema.LookUpField(updatedByColumn)
+	if createdBy == nil && updatedBy == nil {
+		return
+	}
+
+	stamp := func(row reflect.Value) {
+		for _, field := range []*schema.Field{createdBy, updatedBy} {
+			if field == nil {
+				continue
+			}
+			if _, zero := field.ValueOf(db.Statement.Context, row); !zero {
+				continue
+			}
+			if err := field.Set(db.Statement.Context, row, actorID); err != nil {
+				db.AddError(err)
+				return
+			}
+		}
+	}
+
+	rv := db.Statement.ReflectValue
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			stamp(reflect.Indirect(rv.Index(i)))
+		}
+	case reflect.Struct:
+		stamp(rv)
+	}
+}
+
+// stampUpdater sets the last updater of the rows being updated, adding the
+// column to the statement when it only updates selected columns
+func stampUpdater(db *gorm.DB) {
+	actorID, ok := auditableActor(db)
+	if !ok {
+		return
+	}
+	field := db.Statement.Schema.LookUpField(updatedByColumn)
+	if field == nil {
+		return
+	}
+
+	db.Statement.SetColumn(field.DBName, actorID, true)
+	if selects := db.Statement.Selects; len(selects) > 0 && !slices.Contains(selects, "*") && !slices.Contains(selects, field.DBName) {
+		db.Statement.Selects = append(selects, field.DBName)
+	}
+}
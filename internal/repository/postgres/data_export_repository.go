@@ -0,0 +1,65 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/repository"
+	"gorm.io/gorm"
+)
+
+type dataExportRepository struct {
+	db *gorm.DB
+}
+
+// NewDataExportRepository creates a new instance of data export repository
+func NewDataExportRepository(db *gorm.DB) repository.DataExportRepository {
+	return &dataExportRepository{db: db}
+}
+
+// Create creates a new data export
+func (r *dataExportRepository) Create(ctx context.Context, export *domain.DataExport) error {
+	return conn(ctx, r.db).Create(export).Error
+}
+
+// FindByID finds a data export of a user by ID
+func (r *dataExportRepository) FindByID(ctx context.Context, userID, id uint) (*domain.DataExport, error) {
+	var export domain.DataExport
+	err := conn(ctx, r.db).Where("user_id = ?", userID).First(&export, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+// FindLatest finds the most recently requested data export of a user
+func (r *dataExportRepository) FindLatest(ctx context.Context, userID uint) (*domain.DataExport, error) {
+	var export domain.DataExport
+	err := conn(ctx, r.db).Where("user_id = ?", userID).Order("created_at DESC, id DESC").First(&export).Error
+	if err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+// FindExpired finds up to limit data exports that expired before the given time
+func (r *dataExportRepository) FindExpired(ctx context.Context, before time.Time, limit int) ([]domain.DataExport, error) {
+	var exports []domain.DataExport
+	err := conn(ctx, r.db).
+		Where("expires_at < ?", before).
+		Order("expires_at").
+		Limit(limit).
+		Find(&exports).Error
+	return exports, err
+}
+
+// Update updates a data export
+func (r *dataExportRepository) Update(ctx context.Context, export *domain.DataExport) error {
+	return conn(ctx, r.db).Save(export).Error
+}
+
+// Delete deletes a data export
+func (r *dataExportRepository) Delete(ctx context.Context, id uint) error {
+	return conn(ctx, r.db).Delete(&domain.DataExport{}, id).Error
+}
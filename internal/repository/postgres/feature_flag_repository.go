@@ -0,0 +1,34 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/repository"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type featureFlagRepository struct {
+	db *gorm.DB
+}
+
+// NewFeatureFlagRepository creates a new instance of feature flag repository
+func NewFeatureFlagRepository(db *gorm.DB) repository.FeatureFlagRepository {
+	return &featureFlagRepository{db: db}
+}
+
+// FindAll returns every flag set at runtime
+func (r *featureFlagRepository) FindAll(ctx context.Context) ([]domain.FeatureFlag, error) {
+	var flags []domain.FeatureFlag
+	err := conn(ctx, r.db).Order("name").Find(&flags).Error
+	return flags, err
+}
+
+// Upsert stores the state of a flag, replacing the state already stored
+func (r *featureFlagRepository) Upsert(ctx context.Context, flag *domain.FeatureFlag) error {
+	return conn(ctx, r.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"enabled", "updated_at"}),
+	}).Create(flag).Error
+}
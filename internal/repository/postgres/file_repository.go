@@ -0,0 +1,78 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/repository"
+	"gorm.io/gorm"
+)
+
+type fileRepository struct {
+	db *gorm.DB
+}
+
+// NewFileRepository creates a new instance of file repository
+func NewFileRepository(db *gorm.DB) repository.FileRepository {
+	return &fileRepository{db: db}
+}
+
+// Create creates a new file
+func (r *fileRepository) Create(ctx context.Context, file *domain.File) error {
+	return conn(ctx, r.db).Create(file).Error
+}
+
+// FindByID finds a file by ID
+func (r *fileRepository) FindByID(ctx context.Context, id uint) (*domain.File, error) {
+	var file domain.File
+	err := conn(ctx, r.db).First(&file, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+// FindByKey finds a file by its storage key
+func (r *fileRepository) FindByKey(ctx context.Context, key string) (*domain.File, error) {
+	var file domain.File
+	// A struct condition quotes the column name, which MySQL reserves
+	err := conn(ctx, r.db).Where(&domain.File{Key: key}).First(&file).Error
+	if err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+// FindAll finds all files matching the filter with pagination, newest first unless sorted
+func (r *fileRepository) FindAll(ctx context.Context, filter repository.FileFilter, limit, offset int) ([]domain.File, int64, error) {
+	var files []domain.File
+	var total int64
+
+	query := conn(ctx, r.db).Model(&domain.File{})
+	if filter.OwnerID != nil {
+		query = query.Where("owner_id = ?", *filter.OwnerID)
+	}
+
+	// Count total records
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	sort := filter.Sort
+	if len(sort) == 0 {
+		sort = []repository.SortField{{Column: "created_at", Desc: true}, {Column: "id", Desc: true}}
+	}
+
+	// Get paginated results
+	err := query.Scopes(sortScope(sort)).Limit(limit).Offset(offset).Find(&files).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return files, total, nil
+}
+
+// Delete deletes a file
+func (r *fileRepository) Delete(ctx context.Context, id uint) error {
+	return conn(ctx, r.db).Delete(&domain.File{}, id).Error
+}
@@ -0,0 +1,44 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/repository"
+	"gorm.io/gorm"
+)
+
+type loginEventRepository struct {
+	db *gorm.DB
+}
+
+// NewLoginEventRepository creates a new instance of login event repository
+func NewLoginEventRepository(db *gorm.DB) repository.LoginEventRepository {
+	return &loginEventRepository{db: db}
+}
+
+// Create creates a new login event
+func (r *loginEventRepository) Create(ctx context.Context, event *domain.LoginEvent) error {
+	return conn(ctx, r.db).Create(event).Error
+}
+
+// FindByUserID finds a user's login events, newest first
+func (r *loginEventRepository) FindByUserID(ctx context.Context, userID uint, limit, offset int) ([]domain.LoginEvent, int64, error) {
+	var events []domain.LoginEvent
+	var total int64
+
+	query := conn(ctx, r.db).Model(&domain.LoginEvent{}).Where("user_id = ?", userID)
+
+	// Count total records
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Get paginated results
+	err := query.Order("created_at DESC, id DESC").Limit(limit).Offset(offset).Find(&events).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return events, total, nil
+}
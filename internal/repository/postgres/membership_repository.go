@@ -0,0 +1,62 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/repository"
+	"gorm.io/gorm"
+)
+
+type membershipRepository struct {
+	db *gorm.DB
+}
+
+// NewMembershipRepository creates a new instance of membership repository
+func NewMembershipRepository(db *gorm.DB) repository.MembershipRepository {
+	return &membershipRepository{db: db}
+}
+
+// Create creates a new membership
+func (r *membershipRepository) Create(ctx context.Context, membership *domain.Membership) error {
+	return conn(ctx, r.db).Create(membership).Error
+}
+
+// Find finds the membership of a user in an organization
+func (r *membershipRepository) Find(ctx context.Context, orgID, userID uint) (*domain.Membership, error) {
+	var membership domain.Membership
+	err := conn(ctx, r.db).Where("organization_id = ? AND user_id = ?", orgID, userID).First(&membership).Error
+	if err != nil {
+		return nil, err
+	}
+	return &membership, nil
+}
+
+// FindByUser finds the memberships of a user in the given organizations
+func (r *membershipRepository) FindByUser(ctx context.Context, userID uint, orgIDs []uint) ([]domain.Membership, error) {
+	var memberships []domain.Membership
+	err := conn(ctx, r.db).Where("user_id = ? AND organization_id IN ?", userID, orgIDs).Find(&memberships).Error
+	return memberships, err
+}
+
+// FindByOrganization finds the members of an organization with their user,
+// in the order they joined
+func (r *membershipRepository) FindByOrganization(ctx context.Context, orgID uint) ([]domain.Membership, error) {
+	var memberships []domain.Membership
+	err := conn(ctx, r.db).Preload("User").Where("organization_id = ?", orgID).Order("id").Find(&memberships).Error
+	return memberships, err
+}
+
+// CountByRole counts the members of an organization with a role
+func (r *membershipRepository) CountByRole(ctx context.Context, orgID uint, role string) (int64, error) {
+	var count int64
+	err := conn(ctx, r.db).Model(&domain.Membership{}).
+		Where("organization_id = ? AND role = ?", orgID, role).
+		Count(&count).Error
+	return count, err
+}
+
+// UpdateRole changes the role of a membership
+func (r *membershipRepository) UpdateRole(ctx context.Context, id uint, role string) error {
+	return conn(ctx, r.db).Model(&domain.Membership{}).Where("id = ?", id).Update("role", role).Error
+}
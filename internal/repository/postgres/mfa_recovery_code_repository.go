@@ -0,0 +1,62 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/repository"
+	"gorm.io/gorm"
+)
+
+type mfaRecoveryCodeRepository struct {
+	db *gorm.DB
+}
+
+// NewMFARecoveryCodeRepository creates a new instance of MFA recovery code repository
+func NewMFARecoveryCodeRepository(db *gorm.DB) repository.MFARecoveryCodeRepository {
+	return &mfaRecoveryCodeRepository{db: db}
+}
+
+// ReplaceForUser replaces all recovery codes of a user in a single transaction
+func (r *mfaRecoveryCodeRepository) ReplaceForUser(ctx context.Context, userID uint, codeHashes []string) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&domain.MFARecoveryCode{}).Error; err != nil {
+			return err
+		}
+
+		codes := make([]domain.MFARecoveryCode, len(codeHashes))
+		for i, hash := range codeHashes {
+			codes[i] = domain.MFARecoveryCode{UserID: userID, CodeHash: hash}
+		}
+
+		return tx.Create(&codes).Error
+	})
+}
+
+// FindUnused finds an unused recovery code of a user by its hash
+func (r *mfaRecoveryCodeRepository) FindUnused(ctx context.Context, userID uint, codeHash string) (*domain.MFARecoveryCode, error) {
+	var code domain.MFARecoveryCode
+	err := conn(ctx, r.db).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		First(&code).Error
+	if err != nil {
+		return nil, err
+	}
+	return &code, nil
+}
+
+// MarkUsed marks a recovery code as consumed. It reports false when the code
+// was already used, by a concurrent request for instance.
+func (r *mfaRecoveryCodeRepository) MarkUsed(ctx context.Context, id uint) (bool, error) {
+	result := conn(ctx, r.db).
+		Model(&domain.MFARecoveryCode{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	return result.RowsAffected > 0, result.Error
+}
+
+// DeleteByUserID deletes all recovery codes of a user
+func (r *mfaRecoveryCodeRepository) DeleteByUserID(ctx context.Context, userID uint) error {
+	return conn(ctx, r.db).Where("user_id = ?", userID).Delete(&domain.MFARecoveryCode{}).Error
+}
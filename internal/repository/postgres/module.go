@@ -0,0 +1,32 @@
+package postgres
+
+import "go.uber.org/fx"
+
+// Module provides the GORM implementations of the repository interfaces
+var Module = fx.Module("repositories",
+	fx.Provide(
+		NewUserRepository,
+		NewPasswordResetTokenRepository,
+		NewMFARecoveryCodeRepository,
+		NewPasswordHistoryRepository,
+		NewRevokedTokenRepository,
+		NewSessionRepository,
+		NewAuditLogRepository,
+		NewLoginEventRepository,
+		NewOutboxRepository,
+		NewNotificationPreferenceRepository,
+		NewFileRepository,
+		NewDataExportRepository,
+		NewTaskRepository,
+		NewWebhookDeliveryRepository,
+		NewFeatureFlagRepository,
+		NewTenantRepository,
+		NewOrganizationRepository,
+		NewMembershipRepository,
+		NewRoleRepository,
+		NewPermissionRepository,
+		NewTransactor,
+		// gen:repositories
+	),
+	fx.Invoke(RegisterTenantScope, RegisterAuditable),
+)
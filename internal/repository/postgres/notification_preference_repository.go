@@ -0,0 +1,38 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/repository"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type notificationPreferenceRepository struct {
+	db *gorm.DB
+}
+
+// NewNotificationPreferenceRepository creates a new instance of notification preference repository
+func NewNotificationPreferenceRepository(db *gorm.DB) repository.NotificationPreferenceRepository {
+	return &notificationPreferenceRepository{db: db}
+}
+
+// FindByUserID finds the stored preferences of a user
+func (r *notificationPreferenceRepository) FindByUserID(ctx context.Context, userID uint) ([]domain.NotificationPreference, error) {
+	var preferences []domain.NotificationPreference
+	err := conn(ctx, r.db).Where("user_id = ?", userID).Order("type, channel").Find(&preferences).Error
+	return preferences, err
+}
+
+// Upsert stores preferences, replacing the choice already stored for the same
+// user, type and channel
+func (r *notificationPreferenceRepository) Upsert(ctx context.Context, preferences []domain.NotificationPreference) error {
+	if len(preferences) == 0 {
+		return nil
+	}
+	return conn(ctx, r.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "type"}, {Name: "channel"}},
+		DoUpdates: clause.AssignmentColumns([]string{"enabled", "updated_at"}),
+	}).Create(&preferences).Error
+}
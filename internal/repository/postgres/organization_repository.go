@@ -0,0 +1,53 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/repository"
+	"gorm.io/gorm"
+)
+
+type organizationRepository struct {
+	db *gorm.DB
+}
+
+// NewOrganizationRepository creates a new instance of organization repository
+func NewOrganizationRepository(db *gorm.DB) repository.OrganizationRepository {
+	return &organizationRepository{db: db}
+}
+
+// Create creates a new organization
+func (r *organizationRepository) Create(ctx context.Context, org *domain.Organization) error {
+	return conn(ctx, r.db).Create(org).Error
+}
+
+// FindByID finds an organization by ID
+func (r *organizationRepository) FindByID(ctx context.Context, id uint) (*domain.Organization, error) {
+	var org domain.Organization
+	err := conn(ctx, r.db).First(&org, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &org, nil
+}
+
+// FindByMember finds the organizations a user is a member of, ordered by name, with pagination
+func (r *organizationRepository) FindByMember(ctx context.Context, userID uint, limit, offset int) ([]domain.Organization, int64, error) {
+	var orgs []domain.Organization
+	var total int64
+
+	query := conn(ctx, r.db).Model(&domain.Organization{}).
+		Where("id IN (?)", conn(ctx, r.db).Model(&domain.Membership{}).Select("organization_id").Where("user_id = ?", userID))
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order("name").Order("id").Limit(limit).Offset(offset).Find(&orgs).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return orgs, total, nil
+}
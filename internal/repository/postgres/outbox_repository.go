@@ -0,0 +1,73 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/repository"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxLastErrorLength bounds the stored reason of the last failed publish
+const maxLastErrorLength = 500
+
+type outboxRepository struct {
+	db *gorm.DB
+}
+
+// NewOutboxRepository creates a new instance of outbox repository
+func NewOutboxRepository(db *gorm.DB) repository.OutboxRepository {
+	return &outboxRepository{db: db}
+}
+
+// Create stores a message, in the transaction of ctx when there is one
+func (r *outboxRepository) Create(ctx context.Context, message *domain.OutboxMessage) error {
+	return conn(ctx, r.db).Create(message).Error
+}
+
+// FindPending finds up to limit unpublished messages that have failed fewer than
+// maxAttempts times (0 for no limit), oldest first. Within a transaction the rows
+// are locked and rows locked by other relays are skipped (SQLite ignores the lock).
+func (r *outboxRepository) FindPending(ctx context.Context, limit, maxAttempts int) ([]domain.OutboxMessage, error) {
+	var messages []domain.OutboxMessage
+	query := conn(ctx, r.db).Where("published_at IS NULL")
+	if maxAttempts > 0 {
+		query = query.Where("attempts < ?", maxAttempts)
+	}
+	err := query.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Order("id").Limit(limit).Find(&messages).Error
+	if err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// MarkPublished marks a message as delivered to the broker
+func (r *outboxRepository) MarkPublished(ctx context.Context, id uint) error {
+	return conn(ctx, r.db).
+		Model(&domain.OutboxMessage{}).
+		Where("id = ?", id).
+		Update("published_at", time.Now()).Error
+}
+
+// MarkFailed counts a failed publish of a message and keeps its reason
+func (r *outboxRepository) MarkFailed(ctx context.Context, id uint, reason string) error {
+	if len(reason) > maxLastErrorLength {
+		reason = reason[:maxLastErrorLength]
+	}
+	return conn(ctx, r.db).
+		Model(&domain.OutboxMessage{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"attempts":   gorm.Expr("attempts + 1"),
+			"last_error": reason,
+		}).Error
+}
+
+// DeletePublishedBefore removes messages published before the given time
+func (r *outboxRepository) DeletePublishedBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := conn(ctx, r.db).Where("published_at < ?", before).Delete(&domain.OutboxMessage{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,50 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/repository"
+	"gorm.io/gorm"
+)
+
+type passwordHistoryRepository struct {
+	db *gorm.DB
+}
+
+// NewPasswordHistoryRepository creates a new instance of password history repository
+func NewPasswordHistoryRepository(db *gorm.DB) repository.PasswordHistoryRepository {
+	return &passwordHistoryRepository{db: db}
+}
+
+// Create stores a password hash of a user
+func (r *passwordHistoryRepository) Create(ctx context.Context, entry *domain.PasswordHistory) error {
+	return conn(ctx, r.db).Create(entry).Error
+}
+
+// FindRecent finds the limit most recent passwords of a user, newest first
+func (r *passwordHistoryRepository) FindRecent(ctx context.Context, userID uint, limit int) ([]domain.PasswordHistory, error) {
+	var entries []domain.PasswordHistory
+	err := conn(ctx, r.db).
+		Where("user_id = ?", userID).
+		Order("id DESC").
+		Limit(limit).
+		Find(&entries).Error
+	return entries, err
+}
+
+// Prune deletes all but the keep most recent passwords of every user. The
+// ranked rows are selected through a derived table, which MySQL requires to
+// delete from the table it reads.
+func (r *passwordHistoryRepository) Prune(ctx context.Context, keep int) (int64, error) {
+	ranked := conn(ctx, r.db).
+		Model(&domain.PasswordHistory{}).
+		Select("id, ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY id DESC) AS recency")
+	stale := conn(ctx, r.db).
+		Table("(?) AS ranked", ranked).
+		Select("id").
+		Where("recency > ?", keep)
+
+	result := conn(ctx, r.db).Where("id IN (?)", stale).Delete(&domain.PasswordHistory{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,57 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/repository"
+	"gorm.io/gorm"
+)
+
+type passwordResetTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewPasswordResetTokenRepository creates a new instance of password reset token repository
+func NewPasswordResetTokenRepository(db *gorm.DB) repository.PasswordResetTokenRepository {
+	return &passwordResetTokenRepository{db: db}
+}
+
+// Create creates a new password reset token
+func (r *passwordResetTokenRepository) Create(ctx context.Context, token *domain.PasswordResetToken) error {
+	return conn(ctx, r.db).Create(token).Error
+}
+
+// FindByTokenHash finds a password reset token by its hash
+func (r *passwordResetTokenRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*domain.PasswordResetToken, error) {
+	var token domain.PasswordResetToken
+	err := conn(ctx, r.db).Where("token_hash = ?", tokenHash).First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// MarkUsed marks a token as consumed so it cannot be reused. It reports false
+// when the token was already used, by a concurrent request for instance.
+func (r *passwordResetTokenRepository) MarkUsed(ctx context.Context, id uint) (bool, error) {
+	result := conn(ctx, r.db).
+		Model(&domain.PasswordResetToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	return result.RowsAffected > 0, result.Error
+}
+
+// DeleteByUserID deletes all password reset tokens of a user
+func (r *passwordResetTokenRepository) DeleteByUserID(ctx context.Context, userID uint) error {
+	return conn(ctx, r.db).Where("user_id = ?", userID).Delete(&domain.PasswordResetToken{}).Error
+}
+
+// DeleteExpired removes tokens that have expired or were already used
+func (r *passwordResetTokenRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	result := conn(ctx, r.db).
+		Where("expires_at < ? OR used_at IS NOT NULL", time.Now()).
+		Delete(&domain.PasswordResetToken{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,67 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/repository"
+	"gorm.io/gorm"
+)
+
+type permissionRepository struct {
+	db *gorm.DB
+}
+
+// NewPermissionRepository creates a new instance of permission repository
+func NewPermissionRepository(db *gorm.DB) repository.PermissionRepository {
+	return &permissionRepository{db: db}
+}
+
+// Create creates a new permission
+func (r *permissionRepository) Create(ctx context.Context, permission *domain.Permission) error {
+	return conn(ctx, r.db).Create(permission).Error
+}
+
+// FindByID finds a permission by ID
+func (r *permissionRepository) FindByID(ctx context.Context, id uint) (*domain.Permission, error) {
+	var permission domain.Permission
+	err := conn(ctx, r.db).First(&permission, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &permission, nil
+}
+
+// FindByName finds a permission by name
+func (r *permissionRepository) FindByName(ctx context.Context, name string) (*domain.Permission, error) {
+	var permission domain.Permission
+	err := conn(ctx, r.db).Where("name = ?", name).First(&permission).Error
+	if err != nil {
+		return nil, err
+	}
+	return &permission, nil
+}
+
+// FindByNames finds the permissions with the given names
+func (r *permissionRepository) FindByNames(ctx context.Context, names []string) ([]domain.Permission, error) {
+	var permissions []domain.Permission
+	err := conn(ctx, r.db).Where("name IN ?", names).Find(&permissions).Error
+	return permissions, err
+}
+
+// FindAll finds every permission, ordered by name
+func (r *permissionRepository) FindAll(ctx context.Context) ([]domain.Permission, error) {
+	var permissions []domain.Permission
+	err := conn(ctx, r.db).Order("name").Find(&permissions).Error
+	return permissions, err
+}
+
+// Delete deletes a permission and takes it away from every role
+func (r *permissionRepository) Delete(ctx context.Context, id uint) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("permission_id = ?", id).Delete(&domain.RolePermission{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&domain.Permission{}, id).Error
+	})
+}
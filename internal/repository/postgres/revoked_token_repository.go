@@ -0,0 +1,63 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/repository"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type revokedTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewRevokedTokenRepository creates a new instance of revoked token repository
+func NewRevokedTokenRepository(db *gorm.DB) repository.RevokedTokenRepository {
+	return &revokedTokenRepository{db: db}
+}
+
+// Revoke adds a token ID to the denylist
+func (r *revokedTokenRepository) Revoke(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	return conn(ctx, r.db).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&domain.RevokedToken{TokenID: tokenID, ExpiresAt: expiresAt}).Error
+}
+
+// IsRevoked checks whether a token ID is on the denylist
+func (r *revokedTokenRepository) IsRevoked(ctx context.Context, tokenID string) (bool, error) {
+	var count int64
+	err := conn(ctx, r.db).
+		Model(&domain.RevokedToken{}).
+		Where("token_id = ?", tokenID).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// IsRevokedForUser checks whether a token carrying tokenVersion predates the
+// current token version of the user. Tokens of deleted users are revoked.
+func (r *revokedTokenRepository) IsRevokedForUser(ctx context.Context, userID uint, tokenVersion uint) (bool, error) {
+	var user domain.User
+	err := conn(ctx, r.db).
+		Select("id", "token_version").
+		First(&user, userID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return true, nil
+		}
+		return false, err
+	}
+	return tokenVersion != user.TokenVersion, nil
+}
+
+// DeleteExpired removes denylist entries whose tokens have expired anyway
+func (r *revokedTokenRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	result := conn(ctx, r.db).Where("expires_at < ?", time.Now()).Delete(&domain.RevokedToken{})
+	return result.RowsAffected, result.Error
+}
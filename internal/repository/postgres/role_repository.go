@@ -0,0 +1,122 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/repository"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type roleRepository struct {
+	db *gorm.DB
+}
+
+// NewRoleRepository creates a new instance of role repository
+func NewRoleRepository(db *gorm.DB) repository.RoleRepository {
+	return &roleRepository{db: db}
+}
+
+// Create creates a new role; its permissions are set with SetPermissions
+func (r *roleRepository) Create(ctx context.Context, role *domain.Role) error {
+	return conn(ctx, r.db).Omit(clause.Associations).Create(role).Error
+}
+
+// FindByID finds a role by ID
+func (r *roleRepository) FindByID(ctx context.Context, id uint) (*domain.Role, error) {
+	var role domain.Role
+	err := conn(ctx, r.db).Preload("Permissions").First(&role, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+// FindByName finds a role by name
+func (r *roleRepository) FindByName(ctx context.Context, name string) (*domain.Role, error) {
+	var role domain.Role
+	err := conn(ctx, r.db).Preload("Permissions").Where("name = ?", name).First(&role).Error
+	if err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+// FindAll finds every role, ordered by name
+func (r *roleRepository) FindAll(ctx context.Context) ([]domain.Role, error) {
+	var roles []domain.Role
+	err := conn(ctx, r.db).Preload("Permissions").Order("name").Find(&roles).Error
+	return roles, err
+}
+
+// Update updates the name and description of a role
+func (r *roleRepository) Update(ctx context.Context, role *domain.Role) error {
+	return conn(ctx, r.db).Model(role).Select("name", "description").Updates(role).Error
+}
+
+// Delete deletes a role along with its permission grants and assignments
+func (r *roleRepository) Delete(ctx context.Context, id uint) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("role_id = ?", id).Delete(&domain.UserRole{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("role_id = ?", id).Delete(&domain.RolePermission{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&domain.Role{}, id).Error
+	})
+}
+
+// SetPermissions replaces the permissions granted to a role
+func (r *roleRepository) SetPermissions(ctx context.Context, roleID uint, permissionIDs []uint) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("role_id = ?", roleID).Delete(&domain.RolePermission{}).Error; err != nil {
+			return err
+		}
+		if len(permissionIDs) == 0 {
+			return nil
+		}
+
+		grants := make([]domain.RolePermission, len(permissionIDs))
+		for i, id := range permissionIDs {
+			grants[i] = domain.RolePermission{RoleID: roleID, PermissionID: id}
+		}
+		return tx.Create(&grants).Error
+	})
+}
+
+// FindByUser finds the roles assigned to a user, ordered by name
+func (r *roleRepository) FindByUser(ctx context.Context, userID uint) ([]domain.Role, error) {
+	var roles []domain.Role
+	err := conn(ctx, r.db).Preload("Permissions").
+		Joins("JOIN user_roles ON user_roles.role_id = roles.id").
+		Where("user_roles.user_id = ?", userID).
+		Order("name").
+		Find(&roles).Error
+	return roles, err
+}
+
+// Assign assigns a role to a user; assigning it again has no effect
+func (r *roleRepository) Assign(ctx context.Context, userID, roleID uint) error {
+	return conn(ctx, r.db).Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&domain.UserRole{UserID: userID, RoleID: roleID}).Error
+}
+
+// Unassign takes a role away from a user. It reports whether the user had it.
+func (r *roleRepository) Unassign(ctx context.Context, userID, roleID uint) (bool, error) {
+	result := conn(ctx, r.db).Where("user_id = ? AND role_id = ?", userID, roleID).Delete(&domain.UserRole{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// HasPermission reports whether one of the roles assigned to a user grants
+// the permission
+func (r *roleRepository) HasPermission(ctx context.Context, userID uint, permission string) (bool, error) {
+	var count int64
+	err := conn(ctx, r.db).Model(&domain.Permission{}).
+		Joins("JOIN role_permissions ON role_permissions.permission_id = permissions.id").
+		Joins("JOIN user_roles ON user_roles.role_id = role_permissions.role_id").
+		Where("user_roles.user_id = ? AND permissions.name = ?", userID, permission).
+		Count(&count).Error
+	return count > 0, err
+}
@@ -0,0 +1,52 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/repository"
+	"gorm.io/gorm"
+)
+
+type sessionRepository struct {
+	db *gorm.DB
+}
+
+// NewSessionRepository creates a new instance of session repository
+func NewSessionRepository(db *gorm.DB) repository.SessionRepository {
+	return &sessionRepository{db: db}
+}
+
+// Create creates a new session
+func (r *sessionRepository) Create(ctx context.Context, session *domain.Session) error {
+	return conn(ctx, r.db).Create(session).Error
+}
+
+// FindActive finds the sessions of a user whose tokens are still valid at now:
+// not expired, issued with the current token version and not revoked. Newest first.
+func (r *sessionRepository) FindActive(ctx context.Context, userID, tokenVersion uint, now time.Time) ([]domain.Session, error) {
+	var sessions []domain.Session
+	err := conn(ctx, r.db).
+		Where("user_id = ? AND token_version = ? AND expires_at > ?", userID, tokenVersion, now).
+		Where("NOT EXISTS (SELECT 1 FROM revoked_tokens WHERE revoked_tokens.token_id = sessions.token_id)").
+		Order("created_at DESC, id DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// FindByID finds a session of a user by ID
+func (r *sessionRepository) FindByID(ctx context.Context, userID, id uint) (*domain.Session, error) {
+	var session domain.Session
+	err := conn(ctx, r.db).Where("user_id = ?", userID).First(&session, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// DeleteExpired removes the sessions whose tokens have expired
+func (r *sessionRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	result := conn(ctx, r.db).Where("expires_at < ?", time.Now()).Delete(&domain.Session{})
+	return result.RowsAffected, result.Error
+}
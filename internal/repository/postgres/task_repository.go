@@ -0,0 +1,77 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/repository"
+	"gorm.io/gorm"
+)
+
+type taskRepository struct {
+	db *gorm.DB
+}
+
+// NewTaskRepository creates a new instance of task repository
+func NewTaskRepository(db *gorm.DB) repository.TaskRepository {
+	return &taskRepository{db: db}
+}
+
+// Create creates a new task
+func (r *taskRepository) Create(ctx context.Context, task *domain.Task) error {
+	return conn(ctx, r.db).Create(task).Error
+}
+
+// FindByID finds a task by ID
+func (r *taskRepository) FindByID(ctx context.Context, id uint) (*domain.Task, error) {
+	var task domain.Task
+	if err := conn(ctx, r.db).First(&task, id).Error; err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// FindByUser finds a task of a user by ID
+func (r *taskRepository) FindByUser(ctx context.Context, userID, id uint) (*domain.Task, error) {
+	var task domain.Task
+	if err := conn(ctx, r.db).Where("user_id = ?", userID).First(&task, id).Error; err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// Update updates a task
+func (r *taskRepository) Update(ctx context.Context, task *domain.Task) error {
+	return conn(ctx, r.db).Save(task).Error
+}
+
+// Start marks a pending task as running. Of concurrent calls, only one
+// finds the task pending.
+func (r *taskRepository) Start(ctx context.Context, id uint, startedAt time.Time) (bool, error) {
+	result := conn(ctx, r.db).Model(&domain.Task{}).
+		Where("id = ? AND status = ?", id, domain.TaskPending).
+		Updates(map[string]interface{}{"status": domain.TaskRunning, "started_at": startedAt})
+	return result.RowsAffected > 0, result.Error
+}
+
+// UpdateProgress sets the progress of a task, leaving the rest of it as is
+func (r *taskRepository) UpdateProgress(ctx context.Context, id uint, progress int) error {
+	return conn(ctx, r.db).Model(&domain.Task{}).Where("id = ?", id).Update("progress", progress).Error
+}
+
+// FailStuck marks as failed the tasks created before the given time that are
+// still pending, and those started before it that are still running
+func (r *taskRepository) FailStuck(ctx context.Context, before time.Time, message string) (int64, error) {
+	result := conn(ctx, r.db).Model(&domain.Task{}).
+		Where("(status = ? AND created_at < ?) OR (status = ? AND started_at < ?)",
+			domain.TaskPending, before, domain.TaskRunning, before).
+		Updates(map[string]interface{}{"status": domain.TaskFailed, "error": message, "completed_at": time.Now()})
+	return result.RowsAffected, result.Error
+}
+
+// DeleteCompletedBefore deletes the tasks completed before the given time
+func (r *taskRepository) DeleteCompletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := conn(ctx, r.db).Where("completed_at < ?", before).Delete(&domain.Task{})
+	return result.RowsAffected, result.Error
+}
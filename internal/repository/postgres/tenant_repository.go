@@ -0,0 +1,40 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/repository"
+	"gorm.io/gorm"
+)
+
+type tenantRepository struct {
+	db *gorm.DB
+}
+
+// NewTenantRepository creates a new instance of tenant repository
+func NewTenantRepository(db *gorm.DB) repository.TenantRepository {
+	return &tenantRepository{db: db}
+}
+
+// Create creates a new tenant
+func (r *tenantRepository) Create(ctx context.Context, tenant *domain.Tenant) error {
+	return conn(ctx, r.db).Create(tenant).Error
+}
+
+// FindBySlug finds a tenant by slug
+func (r *tenantRepository) FindBySlug(ctx context.Context, slug string) (*domain.Tenant, error) {
+	var tenant domain.Tenant
+	err := conn(ctx, r.db).Where("slug = ?", slug).First(&tenant).Error
+	if err != nil {
+		return nil, err
+	}
+	return &tenant, nil
+}
+
+// FindAll returns every tenant, ordered by slug
+func (r *tenantRepository) FindAll(ctx context.Context) ([]domain.Tenant, error) {
+	var tenants []domain.Tenant
+	err := conn(ctx, r.db).Order("slug").Find(&tenants).Error
+	return tenants, err
+}
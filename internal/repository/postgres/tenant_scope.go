@@ -0,0 +1,84 @@
+package postgres
+
+import (
+	"reflect"
+
+	"github.com/firdanbash/go-clean-boiler/pkg/reqctx"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// tenantColumn is the column holding the tenant of tenant-scoped models
+const tenantColumn = "tenant_id"
+
+// RegisterTenantScope registers the callbacks isolating the data of tenants.
+// When the context of a statement carries a tenant ID, rows created for a model
+// with a tenant_id column are assigned to that tenant, and queries, updates and
+// deletes of such a model only reach the rows of that tenant, even Unscoped.
+// Statements without a tenant in context, and Raw or Exec SQL, are not scoped.
+func RegisterTenantScope(db *gorm.DB) error {
+	callbacks := db.Callback()
+	if err := callbacks.Create().Before("gorm:create").Register("tenant:create", assignTenant); err != nil {
+		return err
+	}
+	if err := callbacks.Query().Before("gorm:query").Register("tenant:query", scopeTenant); err != nil {
+		return err
+	}
+	if err := callbacks.Update().Before("gorm:update").Register("tenant:update", scopeTenant); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().Before("gorm:delete").Register("tenant:delete", scopeTenant); err != nil {
+		return err
+	}
+	return callbacks.Row().Before("gorm:row").Register("tenant:row", scopeTenant)
+}
+
+// tenantField returns the tenant of the statement and the tenant field of its
+// model; a nil field when the statement is not tenant-scoped
+func tenantField(db *gorm.DB) (uint, *schema.Field) {
+	if db.Error != nil || db.Statement.Schema == nil {
+		return 0, nil
+	}
+	tenantID, ok := reqctx.TenantID(db.Statement.Context)
+	if !ok {
+		return 0, nil
+	}
+	return tenantID, db.Statement.Schema.LookUpField(tenantColumn)
+}
+
+// assignTenant sets the tenant of the rows being created
+func assignTenant(db *gorm.DB) {
+	tenantID, field := tenantField(db)
+	if field == nil {
+		return
+	}
+
+	ctx := db.Statement.Context
+	rv := db.Statement.ReflectValue
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			if err := field.Set(ctx, reflect.Indirect(rv.Index(i)), tenantID); err != nil {
+				db.AddError(err)
+				return
+			}
+		}
+	case reflect.Struct:
+		if err := field.Set(ctx, rv, tenantID); err != nil {
+			db.AddError(err)
+		}
+	}
+}
+
+// scopeTenant restricts the statement to the rows of its tenant
+func scopeTenant(db *gorm.DB) {
+	tenantID, field := tenantField(db)
+	if field == nil {
+		return
+	}
+
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: tenantID},
+	}})
+}
@@ -1,9 +1,14 @@
 package postgres
 
 import (
+	"context"
+	"time"
+
 	"github.com/firdanbash/go-clean-boiler/internal/domain"
 	"github.com/firdanbash/go-clean-boiler/internal/repository"
+	"github.com/firdanbash/go-clean-boiler/pkg/query"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type userRepository struct {
@@ -16,42 +21,58 @@ func NewUserRepository(db *gorm.DB) repository.UserRepository {
 }
 
 // Create creates a new user
-func (r *userRepository) Create(user *domain.User) error {
-	return r.db.Create(user).Error
+func (r *userRepository) Create(ctx context.Context, user *domain.User) error {
+	return conn(ctx, r.db).Create(user).Error
 }
 
 // FindByID finds a user by ID
-func (r *userRepository) FindByID(id uint) (*domain.User, error) {
+func (r *userRepository) FindByID(ctx context.Context, id uint) (*domain.User, error) {
 	var user domain.User
-	err := r.db.First(&user, id).Error
+	err := conn(ctx, r.db).First(&user, id).Error
 	if err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
+// FindByIDs finds the users with the given IDs, in no particular order
+func (r *userRepository) FindByIDs(ctx context.Context, ids []uint) ([]domain.User, error) {
+	var users []domain.User
+	err := conn(ctx, r.db).Where("id IN ?", ids).Find(&users).Error
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
 // FindByEmail finds a user by email
-func (r *userRepository) FindByEmail(email string) (*domain.User, error) {
+func (r *userRepository) FindByEmail(ctx context.Context, email string) (*domain.User, error) {
 	var user domain.User
-	err := r.db.Where("email = ?", email).First(&user).Error
+	err := conn(ctx, r.db).Where("email = ?", email).First(&user).Error
 	if err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
-// FindAll finds all users with pagination
-func (r *userRepository) FindAll(limit, offset int) ([]domain.User, int64, error) {
+// FindAll finds all users matching the filter with pagination
+func (r *userRepository) FindAll(ctx context.Context, filter repository.UserFilter, limit, offset int) ([]domain.User, int64, error) {
 	var users []domain.User
 	var total int64
 
+	query := conn(ctx, r.db).Model(&domain.User{}).Scopes(userFilterScope(filter))
+
 	// Count total records
-	if err := r.db.Model(&domain.User{}).Count(&total).Error; err != nil {
+	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
-	// Get paginated results
-	err := r.db.Limit(limit).Offset(offset).Find(&users).Error
+	// Get paginated results, the best matches of a search first unless sorted otherwise
+	order := sortScope(filter.Sort)
+	if filter.Query != "" && len(filter.Sort) == 0 {
+		order = userRankScope(filter.Query)
+	}
+	err := query.Scopes(order, userColumnsScope(filter.Columns)).Limit(limit).Offset(offset).Find(&users).Error
 	if err != nil {
 		return nil, 0, err
 	}
@@ -59,12 +80,130 @@ func (r *userRepository) FindAll(limit, offset int) ([]domain.User, int64, error
 	return users, total, nil
 }
 
-// Update updates a user
-func (r *userRepository) Update(user *domain.User) error {
-	return r.db.Save(user).Error
+// FindAllInBatches iterates over all users matching the filter, batchSize rows at a time
+func (r *userRepository) FindAllInBatches(ctx context.Context, filter repository.UserFilter, batchSize int, fn func(users []domain.User) error) error {
+	var users []domain.User
+	return conn(ctx, r.db).
+		Scopes(userFilterScope(filter), userColumnsScope(filter.Columns)).
+		FindInBatches(&users, batchSize, func(tx *gorm.DB, batch int) error {
+			return fn(users)
+		}).Error
+}
+
+// Update updates a user. token_version is only written by IncrementTokenVersion,
+// so saving a stale copy of the user cannot undo a revocation. Selecting the
+// columns keeps Save from inserting the user when no row of its tenant matches.
+func (r *userRepository) Update(ctx context.Context, user *domain.User) error {
+	return conn(ctx, r.db).Select("*").Omit("token_version").Save(user).Error
+}
+
+// IncrementTokenVersion invalidates every token issued to a user so far
+func (r *userRepository) IncrementTokenVersion(ctx context.Context, id uint) error {
+	return conn(ctx, r.db).
+		Model(&domain.User{}).
+		Where("id = ?", id).
+		UpdateColumn("token_version", gorm.Expr("token_version + 1")).Error
 }
 
 // Delete soft deletes a user
-func (r *userRepository) Delete(id uint) error {
-	return r.db.Delete(&domain.User{}, id).Error
+func (r *userRepository) Delete(ctx context.Context, id uint) error {
+	return conn(ctx, r.db).Delete(&domain.User{}, id).Error
+}
+
+// FindDeletedByID finds a soft deleted user by ID
+func (r *userRepository) FindDeletedByID(ctx context.Context, id uint) (*domain.User, error) {
+	var user domain.User
+	err := conn(ctx, r.db).Unscoped().Where("deleted_at IS NOT NULL").First(&user, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// FindDeletedBefore finds up to limit users soft deleted before the given time, oldest first
+func (r *userRepository) FindDeletedBefore(ctx context.Context, before time.Time, limit int) ([]domain.User, error) {
+	var users []domain.User
+	err := conn(ctx, r.db).Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Order("deleted_at").
+		Limit(limit).
+		Find(&users).Error
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// Restore restores a soft deleted user
+func (r *userRepository) Restore(ctx context.Context, id uint) error {
+	return conn(ctx, r.db).Unscoped().
+		Model(&domain.User{}).
+		Where("id = ?", id).
+		Update("deleted_at", nil).Error
+}
+
+// HardDelete permanently deletes a user, including soft deleted ones
+func (r *userRepository) HardDelete(ctx context.Context, id uint) error {
+	return conn(ctx, r.db).Unscoped().Delete(&domain.User{}, id).Error
+}
+
+// userFilterScope applies search and field filters
+func userFilterScope(filter repository.UserFilter) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if filter.IncludeDeleted {
+			db = db.Unscoped()
+		}
+		if len(filter.IDs) > 0 {
+			db = db.Where("id IN ?", filter.IDs)
+		}
+		if filter.Search != "" {
+			// Wildcards in the search match themselves, as in the filters of pkg/query
+			pattern := query.ContainsPattern(filter.Search)
+			db = db.Where("LOWER(name) LIKE ? ESCAPE '"+query.LikeEscape+"' OR LOWER(email) LIKE ? ESCAPE '"+query.LikeEscape+"'", pattern, pattern)
+		}
+		if filter.Query != "" {
+			db = userSearchScope(filter.Query)(db)
+		}
+		if filter.Email != "" {
+			db = db.Where("email = ?", filter.Email)
+		}
+		if filter.CreatedAfter != nil {
+			db = db.Where("created_at >= ?", *filter.CreatedAfter)
+		}
+		if filter.CreatedBefore != nil {
+			db = db.Where("created_at < ?", *filter.CreatedBefore)
+		}
+		return db
+	}
+}
+
+// userColumnsScope loads only the given columns, and the ID, which batches
+// are paged by. It is kept out of userFilterScope, so that counts are not
+// affected.
+func userColumnsScope(columns []string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if len(columns) == 0 {
+			return db
+		}
+		selected := []string{"id"}
+		for _, column := range columns {
+			if column != "id" {
+				selected = append(selected, column)
+			}
+		}
+		return db.Select(selected)
+	}
+}
+
+// sortScope applies whitelisted sort fields, defaulting to ID order for stable pagination
+func sortScope(fields []repository.SortField) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if len(fields) == 0 {
+			return db.Order("id")
+		}
+		for _, f := range fields {
+			db = db.Order(clause.OrderByColumn{Column: clause.Column{Name: f.Column}, Desc: f.Desc})
+		}
+		return db
+	}
 }
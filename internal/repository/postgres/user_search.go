@@ -0,0 +1,120 @@
+package postgres
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/pkg/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// userSearchIndex is the full-text index of the name and email of users
+const userSearchIndex = "idx_users_search"
+
+// maxSearchTerms caps the words of a search query
+const maxSearchTerms = 8
+
+// MigrateUserSearch creates the full-text search column and index of users,
+// which auto migration cannot declare, like the SQL migrations do. On
+// PostgreSQL search_vector is a generated tsvector column weighting the name
+// over the words of the email, with a GIN index; on MySQL it is a FULLTEXT
+// index. SQLite has no index and falls back to LIKE.
+func MigrateUserSearch(db *gorm.DB) error {
+	switch db.Dialector.Name() {
+	case database.DriverPostgres:
+		if err := db.Exec(`ALTER TABLE users ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (` +
+			`setweight(to_tsvector('simple', coalesce(name, '')), 'A') || ` +
+			`setweight(to_tsvector('simple', translate(coalesce(email, ''), '@.-_+', '     ')), 'B')) STORED`).Error; err != nil {
+			return err
+		}
+		return db.Exec("CREATE INDEX IF NOT EXISTS " + userSearchIndex + " ON users USING GIN (search_vector)").Error
+	case database.DriverMySQL:
+		if db.Migrator().HasIndex(&domain.User{}, userSearchIndex) {
+			return nil
+		}
+		return db.Exec("CREATE FULLTEXT INDEX " + userSearchIndex + " ON users (name, email)").Error
+	}
+	return nil
+}
+
+// searchTerms splits a search query into lowercase words of letters and
+// digits, the only characters passed on to the full-text query syntax or LIKE
+func searchTerms(q string) []string {
+	terms := strings.FieldsFunc(strings.ToLower(q), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(terms) > maxSearchTerms {
+		terms = terms[:maxSearchTerms]
+	}
+	return terms
+}
+
+// userSearchScope restricts users to those matching every word of q, each
+// word matching as a prefix, e.g. "jo do" matches John Doe. On SQLite words
+// match anywhere in the name or email.
+func userSearchScope(q string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		terms := searchTerms(q)
+		if len(terms) == 0 {
+			return db
+		}
+
+		switch db.Dialector.Name() {
+		case database.DriverPostgres:
+			return db.Where("search_vector @@ to_tsquery('simple', ?)", tsQuery(terms))
+		case database.DriverMySQL:
+			return db.Where("MATCH (name, email) AGAINST (? IN BOOLEAN MODE)", booleanQuery(terms))
+		default:
+			for _, term := range terms {
+				pattern := "%" + term + "%"
+				db = db.Where("(LOWER(name) LIKE ? OR LOWER(email) LIKE ?)", pattern, pattern)
+			}
+			return db
+		}
+	}
+}
+
+// userRankScope orders users by how well they match q, best first. SQLite
+// does not rank and keeps the ID order.
+func userRankScope(q string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		terms := searchTerms(q)
+		if len(terms) > 0 {
+			switch db.Dialector.Name() {
+			case database.DriverPostgres:
+				db = db.Order(clause.OrderBy{Expression: clause.Expr{
+					SQL:                "ts_rank(search_vector, to_tsquery('simple', ?)) DESC",
+					Vars:               []interface{}{tsQuery(terms)},
+					WithoutParentheses: true,
+				}})
+			case database.DriverMySQL:
+				db = db.Order(clause.OrderBy{Expression: clause.Expr{
+					SQL:                "MATCH (name, email) AGAINST (? IN BOOLEAN MODE) DESC",
+					Vars:               []interface{}{booleanQuery(terms)},
+					WithoutParentheses: true,
+				}})
+			}
+		}
+		return db.Order("id")
+	}
+}
+
+// tsQuery builds a PostgreSQL tsquery matching every term as a prefix
+func tsQuery(terms []string) string {
+	parts := make([]string, len(terms))
+	for i, term := range terms {
+		parts[i] = term + ":*"
+	}
+	return strings.Join(parts, " & ")
+}
+
+// booleanQuery builds a MySQL boolean mode query requiring every term as a prefix
+func booleanQuery(terms []string) string {
+	parts := make([]string, len(terms))
+	for i, term := range terms {
+		parts[i] = "+" + term + "*"
+	}
+	return strings.Join(parts, " ")
+}
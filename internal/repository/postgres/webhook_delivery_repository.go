@@ -0,0 +1,39 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/repository"
+	"gorm.io/gorm"
+)
+
+type webhookDeliveryRepository struct {
+	db *gorm.DB
+}
+
+// NewWebhookDeliveryRepository creates a new instance of webhook delivery repository
+func NewWebhookDeliveryRepository(db *gorm.DB) repository.WebhookDeliveryRepository {
+	return &webhookDeliveryRepository{db: db}
+}
+
+// Create records a processed delivery
+func (r *webhookDeliveryRepository) Create(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	return conn(ctx, r.db).Create(delivery).Error
+}
+
+// Exists reports whether a delivery of the provider was processed
+func (r *webhookDeliveryRepository) Exists(ctx context.Context, provider, deliveryID string) (bool, error) {
+	var count int64
+	err := conn(ctx, r.db).Model(&domain.WebhookDelivery{}).
+		Where("provider = ? AND delivery_id = ?", provider, deliveryID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// DeleteBefore removes deliveries processed before the given time
+func (r *webhookDeliveryRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := conn(ctx, r.db).Where("created_at < ?", before).Delete(&domain.WebhookDelivery{})
+	return result.RowsAffected, result.Error
+}
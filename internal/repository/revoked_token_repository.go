@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// RevokedTokenRepository defines the interface for the token denylist store
+type RevokedTokenRepository interface {
+	Revoke(ctx context.Context, tokenID string, expiresAt time.Time) error
+	IsRevoked(ctx context.Context, tokenID string) (bool, error)
+	IsRevokedForUser(ctx context.Context, userID uint, tokenVersion uint) (bool, error)
+	DeleteExpired(ctx context.Context) (int64, error)
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+)
+
+// RoleRepository defines the interface for role data access. Roles are loaded
+// with their permissions.
+type RoleRepository interface {
+	Create(ctx context.Context, role *domain.Role) error
+	FindByID(ctx context.Context, id uint) (*domain.Role, error)
+	FindByName(ctx context.Context, name string) (*domain.Role, error)
+	FindAll(ctx context.Context) ([]domain.Role, error)
+	Update(ctx context.Context, role *domain.Role) error
+	Delete(ctx context.Context, id uint) error
+	SetPermissions(ctx context.Context, roleID uint, permissionIDs []uint) error
+	FindByUser(ctx context.Context, userID uint) ([]domain.Role, error)
+	Assign(ctx context.Context, userID, roleID uint) error
+	Unassign(ctx context.Context, userID, roleID uint) (bool, error)
+	HasPermission(ctx context.Context, userID uint, permission string) (bool, error)
+}
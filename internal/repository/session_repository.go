@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+)
+
+// SessionRepository defines the interface for session data access
+type SessionRepository interface {
+	Create(ctx context.Context, session *domain.Session) error
+	FindActive(ctx context.Context, userID, tokenVersion uint, now time.Time) ([]domain.Session, error)
+	FindByID(ctx context.Context, userID, id uint) (*domain.Session, error)
+	DeleteExpired(ctx context.Context) (int64, error)
+}
@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+)
+
+// TaskRepository defines the interface for background task access
+type TaskRepository interface {
+	Create(ctx context.Context, task *domain.Task) error
+	FindByID(ctx context.Context, id uint) (*domain.Task, error)
+	FindByUser(ctx context.Context, userID, id uint) (*domain.Task, error)
+	Update(ctx context.Context, task *domain.Task) error
+	// Start marks a pending task as running, reporting false when it is no
+	// longer pending, e.g. started by another instance
+	Start(ctx context.Context, id uint, startedAt time.Time) (bool, error)
+	UpdateProgress(ctx context.Context, id uint, progress int) error
+	// FailStuck marks as failed with message the tasks pending or running
+	// since before, returning how many were failed
+	FailStuck(ctx context.Context, before time.Time, message string) (int64, error)
+	DeleteCompletedBefore(ctx context.Context, before time.Time) (int64, error)
+}
@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+)
+
+// TenantRepository defines the interface for tenant data access
+type TenantRepository interface {
+	Create(ctx context.Context, tenant *domain.Tenant) error
+	FindBySlug(ctx context.Context, slug string) (*domain.Tenant, error)
+	FindAll(ctx context.Context) ([]domain.Tenant, error)
+}
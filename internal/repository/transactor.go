@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"sync"
+)
+
+// Transactor runs a function in a database transaction. Repositories called
+// with the context passed to fn join the transaction; it commits when fn
+// returns nil and rolls back otherwise.
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// commitHooksKey is the context key of the functions to run once the
+// transaction of the context commits
+type commitHooksKey struct{}
+
+// commitHooks holds the functions AfterCommit registered in a transaction
+type commitHooks struct {
+	mu  sync.Mutex
+	fns []func()
+}
+
+// WithCommitHooks returns a context whose AfterCommit functions are held until
+// run is called, for a transactor to call once its transaction commits
+func WithCommitHooks(ctx context.Context) (hooked context.Context, run func()) {
+	hooks := &commitHooks{}
+	run = func() {
+		hooks.mu.Lock()
+		fns := hooks.fns
+		hooks.fns = nil
+		hooks.mu.Unlock()
+		for _, fn := range fns {
+			fn()
+		}
+	}
+	return context.WithValue(ctx, commitHooksKey{}, hooks), run
+}
+
+// AfterCommit runs fn once the transaction of ctx commits, or right away when
+// ctx has none. fn never runs when the transaction rolls back.
+func AfterCommit(ctx context.Context, fn func()) {
+	hooks, ok := ctx.Value(commitHooksKey{}).(*commitHooks)
+	if !ok {
+		fn()
+		return
+	}
+	hooks.mu.Lock()
+	hooks.fns = append(hooks.fns, fn)
+	hooks.mu.Unlock()
+}
@@ -1,13 +1,63 @@
 package repository
 
-import "github.com/firdanbash/go-clean-boiler/internal/domain"
+import (
+	"context"
+	"time"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+)
+
+// secretsKey is the context key asking user lookups for the secret fields
+type secretsKey struct{}
+
+// WithSecrets returns a context whose user lookups load the password hash and
+// MFA secret, which cached lookups leave out. Use it to check credentials, or
+// to update the user found, as Update saves every field.
+func WithSecrets(ctx context.Context) context.Context {
+	return context.WithValue(ctx, secretsKey{}, true)
+}
+
+// WantsSecrets reports whether user lookups with ctx must load the secret fields
+func WantsSecrets(ctx context.Context) bool {
+	wants, _ := ctx.Value(secretsKey{}).(bool)
+	return wants
+}
+
+// UserSortFields whitelists the fields users can be sorted by, mapped to their columns
+var UserSortFields = map[string]string{
+	"id":         "id",
+	"name":       "name",
+	"email":      "email",
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+}
+
+// UserFilter holds search, filter and sort options for listing users
+type UserFilter struct {
+	IDs            []uint // only these users, when not empty
+	Search         string
+	Query          string // full-text search, ranking the results
+	Email          string
+	CreatedAfter   *time.Time
+	CreatedBefore  *time.Time
+	IncludeDeleted bool
+	Sort           []SortField
+	Columns        []string // columns to load, every column when empty; the ID is always loaded
+}
 
 // UserRepository defines the interface for user data access
 type UserRepository interface {
-	Create(user *domain.User) error
-	FindByID(id uint) (*domain.User, error)
-	FindByEmail(email string) (*domain.User, error)
-	FindAll(limit, offset int) ([]domain.User, int64, error)
-	Update(user *domain.User) error
-	Delete(id uint) error
+	Create(ctx context.Context, user *domain.User) error
+	FindByID(ctx context.Context, id uint) (*domain.User, error)
+	FindByIDs(ctx context.Context, ids []uint) ([]domain.User, error)
+	FindByEmail(ctx context.Context, email string) (*domain.User, error)
+	FindAll(ctx context.Context, filter UserFilter, limit, offset int) ([]domain.User, int64, error)
+	FindAllInBatches(ctx context.Context, filter UserFilter, batchSize int, fn func(users []domain.User) error) error
+	Update(ctx context.Context, user *domain.User) error
+	Delete(ctx context.Context, id uint) error
+	FindDeletedByID(ctx context.Context, id uint) (*domain.User, error)
+	FindDeletedBefore(ctx context.Context, before time.Time, limit int) ([]domain.User, error)
+	Restore(ctx context.Context, id uint) error
+	HardDelete(ctx context.Context, id uint) error
+	IncrementTokenVersion(ctx context.Context, id uint) error
 }
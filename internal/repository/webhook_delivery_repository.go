@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+)
+
+// WebhookDeliveryRepository defines the interface for processed webhook data access
+type WebhookDeliveryRepository interface {
+	Create(ctx context.Context, delivery *domain.WebhookDelivery) error
+	Exists(ctx context.Context, provider, deliveryID string) (bool, error)
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+}
@@ -0,0 +1,15 @@
+package router
+
+import (
+	"github.com/firdanbash/go-clean-boiler/internal/handler"
+	"github.com/gin-gonic/gin"
+)
+
+// DataExportRoutes registers the personal data export routes. Downloads are
+// authorized by the token of the download URL instead of a session.
+func DataExportRoutes(h *handler.DataExportHandler) RouteRegistrar {
+	return func(api *gin.RouterGroup, authMiddleware gin.HandlerFunc) {
+		api.GET("/users/me/export", authMiddleware, h.ExportMyData)
+		api.GET("/data-exports/download", h.Download)
+	}
+}
@@ -0,0 +1,26 @@
+package router
+
+import (
+	"expvar"
+	"net/http/pprof"
+
+	"github.com/gin-gonic/gin"
+)
+
+// registerDebugRoutes exposes net/http/pprof profiles under /debug/pprof and
+// runtime metrics (memstats, cmdline and any published expvars) under /debug/vars
+func registerDebugRoutes(debug *gin.RouterGroup) {
+	debug.GET("/vars", gin.WrapH(expvar.Handler()))
+
+	profiles := debug.Group("/pprof")
+	{
+		profiles.GET("/", gin.WrapF(pprof.Index))
+		profiles.GET("/cmdline", gin.WrapF(pprof.Cmdline))
+		profiles.GET("/profile", gin.WrapF(pprof.Profile))
+		profiles.GET("/symbol", gin.WrapF(pprof.Symbol))
+		profiles.POST("/symbol", gin.WrapF(pprof.Symbol))
+		profiles.GET("/trace", gin.WrapF(pprof.Trace))
+		// heap, goroutine, allocs, block, mutex, threadcreate
+		profiles.GET("/:profile", gin.WrapF(pprof.Index))
+	}
+}
@@ -0,0 +1,20 @@
+package router
+
+import (
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/handler"
+	"github.com/firdanbash/go-clean-boiler/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// FeatureFlagRoutes registers the admin routes listing and toggling feature flags
+func FeatureFlagRoutes(h *handler.FeatureFlagHandler) RouteRegistrar {
+	return func(api *gin.RouterGroup, authMiddleware gin.HandlerFunc) {
+		flags := api.Group("/admin/feature-flags")
+		flags.Use(authMiddleware, middleware.RequireRole(domain.RoleAdmin))
+		{
+			flags.GET("", h.List)
+			flags.PUT("/:name", h.Update)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package router
+
+import (
+	"github.com/firdanbash/go-clean-boiler/internal/handler"
+	"github.com/gin-gonic/gin"
+)
+
+// FileRoutes registers the file upload routes. Users only see their own files.
+func FileRoutes(h *handler.FileHandler) RouteRegistrar {
+	return func(api *gin.RouterGroup, authMiddleware gin.HandlerFunc) {
+		files := api.Group("/files")
+		files.Use(authMiddleware)
+		{
+			files.GET("", h.GetAll)
+			files.POST("", h.Upload)
+			files.POST("/uploads", h.PresignUpload)
+			files.POST("/uploads/complete", h.CompleteUpload)
+			files.GET("/:id", h.GetByID)
+			files.GET("/:id/download", h.Download)
+			files.GET("/:id/download-url", h.GetDownloadURL)
+			files.DELETE("/:id", h.Delete)
+		}
+	}
+}
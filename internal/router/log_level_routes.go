@@ -0,0 +1,20 @@
+package router
+
+import (
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/handler"
+	"github.com/firdanbash/go-clean-boiler/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// LogLevelRoutes registers the admin routes reading and changing the log level
+func LogLevelRoutes(h *handler.LogLevelHandler) RouteRegistrar {
+	return func(api *gin.RouterGroup, authMiddleware gin.HandlerFunc) {
+		level := api.Group("/admin/log-level")
+		level.Use(authMiddleware, middleware.RequireRole(domain.RoleAdmin))
+		{
+			level.GET("", h.Get)
+			level.PUT("", h.Update)
+		}
+	}
+}
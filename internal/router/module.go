@@ -0,0 +1,113 @@
+package router
+
+import (
+	"github.com/casbin/casbin/v2"
+	"github.com/firdanbash/go-clean-boiler/internal/graph"
+	"github.com/firdanbash/go-clean-boiler/internal/handler"
+	"github.com/firdanbash/go-clean-boiler/internal/middleware"
+	"github.com/firdanbash/go-clean-boiler/internal/service"
+	"github.com/firdanbash/go-clean-boiler/internal/webhook"
+	"github.com/firdanbash/go-clean-boiler/internal/ws"
+	"github.com/firdanbash/go-clean-boiler/pkg/config"
+	"github.com/firdanbash/go-clean-boiler/pkg/i18n"
+	"github.com/firdanbash/go-clean-boiler/pkg/jwt"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/fx"
+)
+
+// Module provides the gin engine with every route registered. Resources add
+// their routes by providing a RouteRegistrar to the "routes" group.
+var Module = fx.Module("router",
+	fx.Provide(
+		New,
+		fx.Annotate(NotificationRoutes, fx.ResultTags(`group:"routes"`)),
+		fx.Annotate(SessionRoutes, fx.ResultTags(`group:"routes"`)),
+		fx.Annotate(FileRoutes, fx.ResultTags(`group:"routes"`)),
+		fx.Annotate(FeatureFlagRoutes, fx.ResultTags(`group:"routes"`)),
+		fx.Annotate(OrganizationRoutes, fx.ResultTags(`group:"routes"`)),
+		fx.Annotate(RoleRoutes, fx.ResultTags(`group:"routes"`)),
+		fx.Annotate(DataExportRoutes, fx.ResultTags(`group:"routes"`)),
+		fx.Annotate(TaskRoutes, fx.ResultTags(`group:"routes"`)),
+		fx.Annotate(SearchRoutes, fx.ResultTags(`group:"routes"`)),
+		fx.Annotate(LogLevelRoutes, fx.ResultTags(`group:"routes"`)),
+		// gen:routes
+	),
+)
+
+// Params are the dependencies of the router
+type Params struct {
+	fx.In
+
+	AuthHandler     *handler.AuthHandler
+	UserHandler     *handler.UserHandler
+	JWKSHandler     *handler.JWKSHandler
+	AuditHandler    *handler.AuditHandler
+	ActivityHandler *handler.ActivityHandler
+	HealthHandler   *handler.HealthHandler
+	GraphQLHandler  *graph.Handler
+	WSHandler       *ws.Handler
+	Webhooks        *webhook.Receiver
+	RateLimiter     *middleware.RateLimiter
+	ResponseCache   *middleware.ResponseCache
+	OpenAPI         *middleware.OpenAPIValidator
+	Tenants         service.TenantService
+	Enforcer        *casbin.SyncedEnforcer
+	JWTManager      *jwt.Manager
+	Denylist        jwt.Denylist
+	Bundle          *i18n.Bundle
+	Config          *config.Config
+	Logger          logger.Logger
+	Resources       []RouteRegistrar `group:"routes"`
+}
+
+// New builds the router from the injected handlers and middleware
+func New(p Params) *gin.Engine {
+	uploadsDir := ""
+	if p.Config.Storage.Driver == "local" {
+		uploadsDir = p.Config.Storage.Local.Path
+	}
+
+	metricsPath := ""
+	if p.Config.Metrics.Enabled {
+		metricsPath = p.Config.Metrics.Path
+	}
+
+	var tenant gin.HandlerFunc
+	if p.Config.Tenancy.Enabled {
+		tenant = middleware.TenantMiddleware(p.Tenants, p.Config.Tenancy, p.JWTManager)
+	}
+
+	return SetupRouter(
+		p.AuthHandler,
+		p.UserHandler,
+		p.JWKSHandler,
+		p.AuditHandler,
+		p.ActivityHandler,
+		p.HealthHandler,
+		p.GraphQLHandler,
+		p.WSHandler,
+		p.Webhooks,
+		p.RateLimiter,
+		p.ResponseCache,
+		p.OpenAPI,
+		tenant,
+		p.Enforcer,
+		p.JWTManager,
+		p.Denylist,
+		p.Bundle,
+		uploadsDir,
+		metricsPath,
+		p.Logger,
+		p.Config.Log.Access,
+		p.Config.CORS,
+		p.Config.Compression,
+		p.Config.Server.Body,
+		p.Config.Server.Proxy,
+		p.Config.API,
+		p.Config.Server.RequestTimeout,
+		p.Config.Swagger.Enabled,
+		p.Config.App.Env == "production",
+		p.Resources...,
+	)
+}
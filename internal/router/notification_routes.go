@@ -0,0 +1,18 @@
+package router
+
+import (
+	"github.com/firdanbash/go-clean-boiler/internal/handler"
+	"github.com/gin-gonic/gin"
+)
+
+// NotificationRoutes registers the notification preference routes of the current user
+func NotificationRoutes(h *handler.NotificationHandler) RouteRegistrar {
+	return func(api *gin.RouterGroup, authMiddleware gin.HandlerFunc) {
+		preferences := api.Group("/users/me/notification-preferences")
+		preferences.Use(authMiddleware)
+		{
+			preferences.GET("", h.GetMyPreferences)
+			preferences.PUT("", h.UpdateMyPreferences)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package router
+
+import (
+	"github.com/firdanbash/go-clean-boiler/internal/handler"
+	"github.com/gin-gonic/gin"
+)
+
+// OrganizationRoutes registers the organization routes. Users only see the
+// organizations they are members of; the service checks their role in each.
+func OrganizationRoutes(h *handler.OrganizationHandler) RouteRegistrar {
+	return func(api *gin.RouterGroup, authMiddleware gin.HandlerFunc) {
+		orgs := api.Group("/organizations")
+		orgs.Use(authMiddleware)
+		{
+			orgs.GET("", h.GetAll)
+			orgs.POST("", h.Create)
+			orgs.GET("/:id", h.GetByID)
+			orgs.GET("/:id/members", h.GetMembers)
+			orgs.POST("/:id/members", h.InviteMember)
+			orgs.PUT("/:id/members/:userId", h.UpdateMemberRole)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package router
+
+import (
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/handler"
+	"github.com/firdanbash/go-clean-boiler/internal/middleware"
+	"github.com/firdanbash/go-clean-boiler/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// RoleRoutes registers the routes managing roles, permissions and the roles of
+// users. They require the roles:manage permission, which admins always hold.
+func RoleRoutes(h *handler.RoleHandler, roles service.RoleService) RouteRegistrar {
+	return func(api *gin.RouterGroup, authMiddleware gin.HandlerFunc) {
+		manage := middleware.RequirePermission(roles, domain.PermissionRolesManage, domain.RoleAdmin)
+
+		admin := api.Group("/admin")
+		admin.Use(authMiddleware, manage)
+		{
+			admin.GET("/roles", h.ListRoles)
+			admin.POST("/roles", h.CreateRole)
+			admin.GET("/roles/:id", h.GetRole)
+			admin.PUT("/roles/:id", h.UpdateRole)
+			admin.DELETE("/roles/:id", h.DeleteRole)
+			admin.GET("/permissions", h.ListPermissions)
+			admin.POST("/permissions", h.CreatePermission)
+			admin.DELETE("/permissions/:id", h.DeletePermission)
+		}
+
+		users := api.Group("/users")
+		users.Use(authMiddleware, manage)
+		{
+			users.GET("/:id/roles", h.GetUserRoles)
+			users.POST("/:id/roles", h.AssignRole)
+			users.DELETE("/:id/roles/:roleId", h.UnassignRole)
+		}
+	}
+}
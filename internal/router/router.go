@@ -1,54 +1,262 @@
 package router
 
 import (
+	"path/filepath"
+	"time"
+
+	"github.com/firdanbash/go-clean-boiler/docs"
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/graph"
 	"github.com/firdanbash/go-clean-boiler/internal/handler"
 	"github.com/firdanbash/go-clean-boiler/internal/middleware"
+	"github.com/firdanbash/go-clean-boiler/internal/service"
+	"github.com/firdanbash/go-clean-boiler/internal/webhook"
+	"github.com/firdanbash/go-clean-boiler/internal/ws"
+	"github.com/firdanbash/go-clean-boiler/pkg/config"
+	"github.com/firdanbash/go-clean-boiler/pkg/i18n"
+	"github.com/firdanbash/go-clean-boiler/pkg/jwt"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"github.com/firdanbash/go-clean-boiler/pkg/version"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	swaggerFiles "github.com/swaggo/files"
+	ginSwagger "github.com/swaggo/gin-swagger"
+	"go.uber.org/zap"
 )
 
+// APIVersions are the versions of the API, each served under /api/<version>
+var APIVersions = []string{"v1", "v2"}
+
+// RouteRegistrar registers the routes of a resource on the group of every API
+// version
+type RouteRegistrar func(api *gin.RouterGroup, authMiddleware gin.HandlerFunc)
+
 // SetupRouter sets up all routes
 func SetupRouter(
 	authHandler *handler.AuthHandler,
 	userHandler *handler.UserHandler,
-	jwtSecret string,
+	jwksHandler *handler.JWKSHandler,
+	auditHandler *handler.AuditHandler,
+	activityHandler *handler.ActivityHandler,
+	healthHandler *handler.HealthHandler,
+	graphQLHandler *graph.Handler,
+	wsHandler *ws.Handler,
+	webhookReceiver *webhook.Receiver,
+	rateLimiter *middleware.RateLimiter,
+	responseCache *middleware.ResponseCache,
+	openAPIValidator *middleware.OpenAPIValidator,
+	tenant gin.HandlerFunc,
+	enforcer middleware.Enforcer,
+	jwtManager *jwt.Manager,
+	denylist jwt.Denylist,
+	bundle *i18n.Bundle,
+	uploadsDir string,
+	metricsPath string,
+	log logger.Logger,
+	accessLog config.AccessLogConfig,
+	corsConfig config.CORSConfig,
+	compression config.CompressionConfig,
+	bodyLimit config.BodyLimitConfig,
+	proxy config.ProxyConfig,
+	apiConfig config.APIConfig,
+	requestTimeout time.Duration,
+	swagger bool,
+	production bool,
+	resources ...RouteRegistrar,
 ) *gin.Engine {
 	router := gin.New()
 
+	// Client IPs are taken from the headers of trusted proxies only; the
+	// entries are checked by the config validation
+	if err := router.SetTrustedProxies(proxy.TrustedProxies); err != nil {
+		log.Error("Invalid trusted proxies", zap.Error(err))
+	}
+	router.RemoteIPHeaders = middleware.RemoteIPHeaders(proxy.RemoteIPHeaders)
+
 	// Global middlewares
 	router.Use(gin.Recovery())
-	router.Use(middleware.ErrorMiddleware())
-	router.Use(middleware.LoggerMiddleware())
-	router.Use(middleware.CORSMiddleware())
-
-	// Health check
-	router.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{
-			"status":  "ok",
-			"message": "Server is running",
-		})
-	})
-
-	// API v1 routes
-	v1 := router.Group("/api/v1")
+	router.Use(middleware.ForwardedMiddleware())
+	router.Use(middleware.TracingMiddleware())
+	router.Use(middleware.RequestContextMiddleware())
+	router.Use(middleware.LocaleMiddleware(bundle))
+	router.Use(middleware.FormatMiddleware(apiConfig.XML))
+	router.Use(middleware.CompressionMiddleware(compression))
+	router.Use(middleware.ErrorMiddleware(log))
+	router.Use(middleware.LoggerMiddleware(log, accessLog))
+	router.Use(middleware.CORSMiddleware(corsConfig))
+	router.Use(middleware.BodyLimitMiddleware(bodyLimit))
+	router.Use(middleware.EnforcerMiddleware(enforcer))
+	if openAPIValidator != nil {
+		router.Use(openAPIValidator.Middleware())
+	}
+
+	// Health checks; /health is kept as an alias of the liveness probe
+	router.GET("/health", healthHandler.Live)
+	router.GET("/health/live", healthHandler.Live)
+	router.GET("/health/ready", healthHandler.Ready)
+
+	// Build information of the running instance
+	router.GET("/version", healthHandler.Version)
+
+	// Prometheus metrics, when enabled
+	if metricsPath != "" {
+		router.GET(metricsPath, gin.WrapH(promhttp.Handler()))
+	}
+
+	// Public key discovery, only when tokens are signed with asymmetric keys
+	if jwtManager.HasPublicKeys() {
+		router.GET("/.well-known/jwks.json", jwksHandler.GetJWKS)
+	}
+
+	// Avatars, when stored on local disk. Files and data exports are private
+	// and only downloaded through the authorized API routes.
+	if uploadsDir != "" {
+		router.Static("/uploads/"+service.AvatarKeyPrefix, filepath.Join(uploadsDir, service.AvatarKeyPrefix))
+	}
+
+	// API documentation generated by swag from the handler annotations
+	if swagger {
+		docs.SwaggerInfo.Version = version.Version
+		router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	}
+
+	authMiddleware := middleware.AuthMiddleware(jwtManager, denylist)
+	timeout := middleware.TimeoutMiddleware(requestTimeout)
+
+	// Middleware of the routes reaching the data of a tenant: the tenant is
+	// resolved, when tenancy is enabled, before the token is checked against it
+	scoped := []gin.HandlerFunc{timeout}
+	if tenant != nil {
+		scoped = append(scoped, tenant)
+	}
+
+	// Profiling and runtime metrics; in production only admins may capture profiles
+	debug := router.Group("/debug")
+	if production {
+		debug.Use(authMiddleware, middleware.RequireRole(domain.RoleAdmin))
+	}
+	registerDebugRoutes(debug)
+
+	// GraphQL API; operations check access themselves, so the token is optional
+	if graphQLHandler != nil {
+		gql := router.Group("/graphql")
+		gql.Use(scoped...)
+		gql.Use(rateLimiter.Policy("api"))
+		{
+			gql.GET("", middleware.OptionalAuthMiddleware(jwtManager, denylist), graphQLHandler.Query)
+			gql.POST("", middleware.OptionalAuthMiddleware(jwtManager, denylist), graphQLHandler.Query)
+			if graphQLHandler.PlaygroundEnabled() {
+				gql.GET("/playground", graphQLHandler.Playground)
+			}
+		}
+	}
+
+	// Real-time events of the authenticated user, who only receives the
+	// events of their own tenant
+	if wsHandler != nil {
+		router.GET("/ws", rateLimiter.Policy("api"), middleware.OptionalAuthMiddleware(jwtManager, denylist), wsHandler.Serve)
+	}
+
+	// Webhooks of third parties, authenticated by their signatures
+	if webhookReceiver != nil {
+		webhookReceiver.Register(router.Group("", timeout))
+	}
+
+	// API routes, one group per version. The versions share the handlers and
+	// services; when a request or response changes incompatibly, give the
+	// newer version its own handler for that route and schedule the
+	// retirement of the older one under api.versions.
+	for _, version := range APIVersions {
+		api := router.Group("/api/" + version)
+		api.Use(scoped...)
+		api.Use(middleware.DeprecationMiddleware(apiConfig.Versions[version]), rateLimiter.Policy("api"))
+		api.Use(middleware.EnvelopeMiddleware(apiConfig.Envelope))
+		registerAPIRoutes(api, authMiddleware, authHandler, userHandler, auditHandler, activityHandler, rateLimiter, responseCache, resources)
+	}
+
+	return router
+}
+
+// registerAPIRoutes registers the routes of one API version on api
+func registerAPIRoutes(
+	api *gin.RouterGroup,
+	authMiddleware gin.HandlerFunc,
+	authHandler *handler.AuthHandler,
+	userHandler *handler.UserHandler,
+	auditHandler *handler.AuditHandler,
+	activityHandler *handler.ActivityHandler,
+	rateLimiter *middleware.RateLimiter,
+	responseCache *middleware.ResponseCache,
+	resources []RouteRegistrar,
+) {
+	cachedUsers := responseCache.Cache(service.CacheTagUsers, 0)
+
+	// Public routes
+	auth := api.Group("/auth")
+	auth.Use(rateLimiter.Policy("auth"))
 	{
-		// Public routes
-		auth := v1.Group("/auth")
+		auth.POST("/register", authHandler.Register)
+		auth.POST("/login", authHandler.Login)
+		auth.POST("/forgot-password", authHandler.ForgotPassword)
+		auth.POST("/reset-password", authHandler.ResetPassword)
+		auth.POST("/mfa/verify", authHandler.VerifyMFA)
+	}
+
+	// Protected auth routes
+	authProtected := api.Group("/auth")
+	authProtected.Use(authMiddleware)
+	{
+		authProtected.POST("/logout", authHandler.Logout)
+		authProtected.POST("/logout-all", authHandler.LogoutAll)
+		authProtected.POST("/mfa/enable", authHandler.EnableMFA)
+		authProtected.POST("/mfa/confirm", authHandler.ConfirmMFA)
+		authProtected.POST("/mfa/disable", authHandler.DisableMFA)
+	}
+
+	// Protected routes
+	users := api.Group("/users")
+	users.Use(authMiddleware, rateLimiter.Policy("users"))
+	{
+		// Self-service routes
+		users.GET("/me", cachedUsers, userHandler.GetMe)
+		users.PUT("/me", userHandler.UpdateMe)
+		users.DELETE("/me", userHandler.DeleteMe)
+		users.GET("/me/activity", activityHandler.GetMyActivity)
+		users.PUT("/me/password", userHandler.ChangePassword)
+		users.POST("/me/avatar", userHandler.UploadAvatar)
+		users.GET("/:id/avatar", userHandler.GetAvatar)
+
+		// Owner-scoped routes
+		owner := users.Group("", middleware.RequireSelfOrRole(domain.RoleAdmin))
 		{
-			auth.POST("/register", authHandler.Register)
-			auth.POST("/login", authHandler.Login)
+			owner.GET("/:id", cachedUsers, userHandler.GetByID)
+			owner.PUT("/:id", userHandler.Update)
 		}
 
-		// Protected routes
-		users := v1.Group("/users")
-		users.Use(middleware.AuthMiddleware(jwtSecret))
+		// Admin routes
+		admin := users.Group("", middleware.RequireRole(domain.RoleAdmin))
 		{
-			users.GET("", userHandler.GetAll)
-			users.GET("/:id", userHandler.GetByID)
-			users.POST("", userHandler.Create)
-			users.PUT("/:id", userHandler.Update)
-			users.DELETE("/:id", userHandler.Delete)
+			admin.GET("", cachedUsers, userHandler.GetAll)
+			admin.GET("/export", userHandler.Export)
+			admin.POST("", userHandler.Create)
+			admin.POST("/bulk-delete", userHandler.BulkDelete)
+			admin.DELETE("/:id", userHandler.Delete)
+			admin.POST("/:id/restore", userHandler.Restore)
+			admin.DELETE("/:id/permanent", userHandler.HardDelete)
 		}
 	}
 
-	return router
+	// Admin routes, checked against the authorization policies: the admin
+	// role may do anything, other subjects need a policy for the object
+	admin := api.Group("/admin")
+	admin.Use(authMiddleware)
+	{
+		admin.GET("/audit-logs", middleware.Authorize("audit_logs", "read"), auditHandler.List)
+		admin.POST("/users/:id/impersonate", middleware.Authorize("users", "impersonate"), authHandler.Impersonate)
+	}
+
+	// Generated resources
+	for _, register := range resources {
+		register(api, authMiddleware)
+	}
 }
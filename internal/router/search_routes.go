@@ -0,0 +1,19 @@
+package router
+
+import (
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/handler"
+	"github.com/firdanbash/go-clean-boiler/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// SearchRoutes registers the search routes, for admins only
+func SearchRoutes(h *handler.SearchHandler) RouteRegistrar {
+	return func(api *gin.RouterGroup, authMiddleware gin.HandlerFunc) {
+		search := api.Group("/admin/search")
+		search.Use(authMiddleware, middleware.RequireRole(domain.RoleAdmin))
+		{
+			search.GET("/users", h.SearchUsers)
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package router
+
+import (
+	"github.com/firdanbash/go-clean-boiler/internal/handler"
+	"github.com/gin-gonic/gin"
+)
+
+// SessionRoutes registers the routes listing and revoking the sessions of the current user
+func SessionRoutes(h *handler.SessionHandler) RouteRegistrar {
+	return func(api *gin.RouterGroup, authMiddleware gin.HandlerFunc) {
+		sessions := api.Group("/users/me/sessions")
+		sessions.Use(authMiddleware)
+		{
+			sessions.GET("", h.GetMySessions)
+			sessions.DELETE("/:id", h.RevokeMySession)
+		}
+	}
+}
@@ -0,0 +1,13 @@
+package router
+
+import (
+	"github.com/firdanbash/go-clean-boiler/internal/handler"
+	"github.com/gin-gonic/gin"
+)
+
+// TaskRoutes registers the route reporting the status of background tasks
+func TaskRoutes(h *handler.TaskHandler) RouteRegistrar {
+	return func(api *gin.RouterGroup, authMiddleware gin.HandlerFunc) {
+		api.GET("/tasks/:id", authMiddleware, h.Get)
+	}
+}
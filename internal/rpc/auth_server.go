@@ -0,0 +1,89 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/firdanbash/go-clean-boiler/internal/dto/request"
+	"github.com/firdanbash/go-clean-boiler/internal/service"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	boilerv1 "github.com/firdanbash/go-clean-boiler/pkg/pb/boiler/v1"
+)
+
+// PublicMethods can be called without an access token
+var PublicMethods = []string{
+	boilerv1.AuthService_Register_FullMethodName,
+	boilerv1.AuthService_Login_FullMethodName,
+	boilerv1.AuthService_VerifyMFA_FullMethodName,
+}
+
+// AuthServer serves boilerv1.AuthService with the auth service
+type AuthServer struct {
+	boilerv1.UnimplementedAuthServiceServer
+	authService service.AuthService
+	log         logger.Logger
+}
+
+// NewAuthServer creates a new auth gRPC server
+func NewAuthServer(authService service.AuthService, log logger.Logger) *AuthServer {
+	return &AuthServer{authService: authService, log: log}
+}
+
+// Register creates an account and returns an access token for it
+func (s *AuthServer) Register(ctx context.Context, req *boilerv1.RegisterRequest) (*boilerv1.RegisterResponse, error) {
+	registerReq := request.RegisterRequest{Email: req.GetEmail(), Password: req.GetPassword(), Name: req.GetName()}
+	if err := validate(ctx, &registerReq); err != nil {
+		return nil, err
+	}
+
+	auth, err := s.authService.Register(ctx, &registerReq)
+	if err != nil {
+		return nil, statusError(ctx, s.log, "Failed to register", err)
+	}
+	return &boilerv1.RegisterResponse{Session: toSession(auth)}, nil
+}
+
+// Login exchanges credentials for an access token or an MFA token
+func (s *AuthServer) Login(ctx context.Context, req *boilerv1.LoginRequest) (*boilerv1.LoginResponse, error) {
+	loginReq := request.LoginRequest{Email: req.GetEmail(), Password: req.GetPassword(), RememberMe: req.GetRememberMe()}
+	if err := validate(ctx, &loginReq); err != nil {
+		return nil, err
+	}
+
+	auth, err := s.authService.Login(ctx, &loginReq)
+	if err != nil {
+		return nil, statusError(ctx, s.log, "Failed to login", err)
+	}
+	return &boilerv1.LoginResponse{Session: toSession(auth)}, nil
+}
+
+// VerifyMFA completes an MFA login
+func (s *AuthServer) VerifyMFA(ctx context.Context, req *boilerv1.VerifyMFARequest) (*boilerv1.VerifyMFAResponse, error) {
+	verifyReq := request.MFAVerifyRequest{MFAToken: req.GetMfaToken(), Code: req.GetCode()}
+	if err := validate(ctx, &verifyReq); err != nil {
+		return nil, err
+	}
+
+	auth, err := s.authService.VerifyMFA(ctx, &verifyReq)
+	if err != nil {
+		return nil, statusError(ctx, s.log, "Failed to verify MFA", err)
+	}
+	return &boilerv1.VerifyMFAResponse{Session: toSession(auth)}, nil
+}
+
+// Logout revokes the access token of the call
+func (s *AuthServer) Logout(ctx context.Context, _ *boilerv1.LogoutRequest) (*boilerv1.LogoutResponse, error) {
+	claims, _ := claimsFrom(ctx)
+	if err := s.authService.Logout(ctx, claims.ID, claims.ExpiresAt.Time); err != nil {
+		return nil, statusError(ctx, s.log, "Failed to logout", err)
+	}
+	return &boilerv1.LogoutResponse{}, nil
+}
+
+// LogoutAll revokes every access token of the caller
+func (s *AuthServer) LogoutAll(ctx context.Context, _ *boilerv1.LogoutAllRequest) (*boilerv1.LogoutAllResponse, error) {
+	claims, _ := claimsFrom(ctx)
+	if err := s.authService.LogoutAll(ctx, claims.UserID); err != nil {
+		return nil, statusError(ctx, s.log, "Failed to logout everywhere", err)
+	}
+	return &boilerv1.LogoutAllResponse{}, nil
+}
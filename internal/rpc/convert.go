@@ -0,0 +1,42 @@
+package rpc
+
+import (
+	"github.com/firdanbash/go-clean-boiler/internal/dto/response"
+	boilerv1 "github.com/firdanbash/go-clean-boiler/pkg/pb/boiler/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func toUser(u *response.UserResponse) *boilerv1.User {
+	if u == nil {
+		return nil
+	}
+	user := &boilerv1.User{
+		Id:         uint64(u.ID),
+		Email:      u.Email,
+		Name:       u.Name,
+		Role:       u.Role,
+		AvatarUrl:  u.AvatarURL,
+		MfaEnabled: u.MFAEnabled,
+		CreatedAt:  timestamppb.New(u.CreatedAt),
+		UpdatedAt:  timestamppb.New(u.UpdatedAt),
+	}
+	if u.DeletedAt != nil {
+		user.DeletedAt = timestamppb.New(*u.DeletedAt)
+	}
+	return user
+}
+
+func toSession(auth *response.AuthResponse) *boilerv1.Session {
+	session := &boilerv1.Session{
+		User:        toUser(auth.User),
+		Token:       auth.Token,
+		ExpiresIn:   auth.ExpiresIn,
+		RememberMe:  auth.RememberMe,
+		MfaRequired: auth.MFARequired,
+		MfaToken:    auth.MFAToken,
+	}
+	if auth.ExpiresAt != nil {
+		session.ExpiresAt = timestamppb.New(*auth.ExpiresAt)
+	}
+	return session
+}
@@ -0,0 +1,90 @@
+package rpc
+
+import (
+	"context"
+	"sort"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/pkg/apperror"
+	"github.com/firdanbash/go-clean-boiler/pkg/i18n"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"github.com/firdanbash/go-clean-boiler/pkg/validator"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errorCodes maps the kinds of application errors to gRPC codes
+var errorCodes = map[apperror.Kind]codes.Code{
+	apperror.KindValidation:      codes.InvalidArgument,
+	apperror.KindUnauthorized:    codes.Unauthenticated,
+	apperror.KindForbidden:       codes.PermissionDenied,
+	apperror.KindNotFound:        codes.NotFound,
+	apperror.KindConflict:        codes.AlreadyExists,
+	apperror.KindTooManyRequests: codes.ResourceExhausted,
+}
+
+// statusError converts a service error to a gRPC status carrying its message,
+// and its apperror code as the reason of an ErrorInfo detail. Other errors are
+// logged and reported as Internal with message only.
+func statusError(ctx context.Context, log logger.Logger, message string, err error) error {
+	if code, ok := errorCodes[apperror.KindOf(err)]; ok {
+		st := status.New(code, i18n.FromContext(ctx).Error(err))
+		if detailed, detailErr := st.WithDetails(&errdetails.ErrorInfo{Reason: string(apperror.CodeOf(err))}); detailErr == nil {
+			st = detailed
+		}
+		return st.Err()
+	}
+	logger.Ctx(ctx, log).Error(message, zap.Error(err))
+	return status.Error(codes.Internal, i18n.T(ctx, message))
+}
+
+// validate checks req with its validate tags and reports the invalid fields
+// as BadRequest details of an InvalidArgument status
+func validate(ctx context.Context, req interface{}) error {
+	err := validator.ValidateStructCtx(ctx, req)
+	if err == nil {
+		return nil
+	}
+
+	fields := validator.FormatValidationErrors(ctx, err)
+	details := &errdetails.BadRequest{}
+	for field, description := range fields {
+		details.FieldViolations = append(details.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       field,
+			Description: description,
+		})
+	}
+	sort.Slice(details.FieldViolations, func(i, j int) bool {
+		return details.FieldViolations[i].Field < details.FieldViolations[j].Field
+	})
+
+	st, detailErr := status.New(codes.InvalidArgument, i18n.T(ctx, "Validation failed")).WithDetails(details)
+	if detailErr != nil {
+		return status.Error(codes.InvalidArgument, i18n.T(ctx, "Validation failed"))
+	}
+	return st.Err()
+}
+
+// requireRole allows the call only if the caller has one of roles
+func requireRole(ctx context.Context, roles ...string) error {
+	claims, ok := claimsFrom(ctx)
+	if ok {
+		for _, role := range roles {
+			if claims.Role == role {
+				return nil
+			}
+		}
+	}
+	return status.Error(codes.PermissionDenied, i18n.T(ctx, "You do not have permission to access this resource"))
+}
+
+// requireSelfOrAdmin allows the call only if the caller is the user with id
+// or an admin
+func requireSelfOrAdmin(ctx context.Context, id uint) error {
+	if claims, ok := claimsFrom(ctx); ok && claims.UserID == id {
+		return nil
+	}
+	return requireRole(ctx, domain.RoleAdmin)
+}
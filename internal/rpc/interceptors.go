@@ -0,0 +1,180 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/firdanbash/go-clean-boiler/internal/service"
+	"github.com/firdanbash/go-clean-boiler/pkg/config"
+	"github.com/firdanbash/go-clean-boiler/pkg/i18n"
+	"github.com/firdanbash/go-clean-boiler/pkg/jwt"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"github.com/firdanbash/go-clean-boiler/pkg/reqctx"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const maxRequestIDLength = 128
+
+type claimsKey struct{}
+
+// RecoveryInterceptor turns a panic in a handler into an Internal error
+func RecoveryInterceptor(log logger.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				logger.Ctx(ctx, log).Error("gRPC handler panicked",
+					zap.String("method", info.FullMethod),
+					zap.Any("panic", r),
+					zap.ByteString("stack", debug.Stack()),
+				)
+				err = status.Error(codes.Internal, i18n.T(ctx, "An error occurred"))
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// ContextInterceptor copies the call metadata into the context the way the
+// HTTP middleware does: the x-request-id (generated when missing), client
+// address, user agent and the locale picked from accept-language
+func ContextInterceptor(bundle *i18n.Bundle) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		requestID := firstMetadata(ctx, "x-request-id")
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			requestID = uuid.NewString()
+		}
+		_ = grpc.SetHeader(ctx, metadata.Pairs("x-request-id", requestID))
+
+		var clientIP string
+		if p, ok := peer.FromContext(ctx); ok {
+			clientIP, _, _ = strings.Cut(p.Addr.String(), ":")
+		}
+
+		ctx = reqctx.WithRequestID(ctx, requestID)
+		ctx = reqctx.WithClient(ctx, clientIP, firstMetadata(ctx, "user-agent"))
+		ctx = i18n.WithLocalizer(ctx, bundle.Localizer(bundle.Match(firstMetadata(ctx, "accept-language"))))
+		return handler(ctx, req)
+	}
+}
+
+// LoggingInterceptor logs one entry per call with its status code and latency
+func LoggingInterceptor(log logger.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+
+		code := status.Code(err)
+		fields := []logger.Field{
+			zap.String("method", info.FullMethod),
+			zap.String("code", code.String()),
+			zap.Duration("latency", time.Since(start)),
+		}
+
+		callLog := logger.Ctx(ctx, log)
+		switch code {
+		case codes.OK:
+			callLog.Info("gRPC request", fields...)
+		case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unavailable:
+			callLog.Error("gRPC request", fields...)
+		default:
+			callLog.Warn("gRPC request", fields...)
+		}
+		return resp, err
+	}
+}
+
+// AuthInterceptor requires a valid access token in the authorization metadata
+// ("Bearer <token>") for every method except the public ones, rejecting
+// tokens present in the denylist
+func AuthInterceptor(jwtManager *jwt.Manager, denylist jwt.Denylist, public ...string) grpc.UnaryServerInterceptor {
+	isPublic := make(map[string]bool, len(public))
+	for _, method := range public {
+		isPublic[method] = true
+	}
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if isPublic[info.FullMethod] {
+			return handler(ctx, req)
+		}
+
+		token, found := strings.CutPrefix(firstMetadata(ctx, "authorization"), "Bearer ")
+		if !found || token == "" {
+			return nil, status.Error(codes.Unauthenticated, i18n.T(ctx, "Authorization header required"))
+		}
+
+		claims, err := jwtManager.ValidateTokenWithDenylist(ctx, token, denylist)
+		if err != nil {
+			if errors.Is(err, jwt.ErrRevokedToken) {
+				return nil, status.Error(codes.Unauthenticated, i18n.T(ctx, "Token has been revoked"))
+			}
+			if errors.Is(err, jwt.ErrWrongTenant) {
+				return nil, status.Error(codes.Unauthenticated, i18n.T(ctx, "Token is not valid for this tenant"))
+			}
+			return nil, status.Error(codes.Unauthenticated, i18n.T(ctx, "Invalid or expired token"))
+		}
+
+		ctx = context.WithValue(ctx, claimsKey{}, claims)
+		ctx = reqctx.WithUserID(ctx, claims.UserID)
+		if claims.ImpersonatorID != 0 {
+			ctx = reqctx.WithImpersonatorID(ctx, claims.ImpersonatorID)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// TenantInterceptor resolves the tenant a call acts for like the HTTP
+// middleware: from the tenant header metadata, then the claim of the access
+// token, then the default tenant
+func TenantInterceptor(tenants service.TenantService, cfg config.TenancyConfig, jwtManager *jwt.Manager, log logger.Logger) grpc.UnaryServerInterceptor {
+	header := strings.ToLower(cfg.Header)
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		var tenantID uint
+		slug := strings.ToLower(strings.TrimSpace(firstMetadata(ctx, header)))
+		if slug == "" {
+			if token, found := strings.CutPrefix(firstMetadata(ctx, "authorization"), "Bearer "); found {
+				if claims, err := jwtManager.ValidateToken(token); err == nil {
+					tenantID = claims.TenantID
+				}
+			}
+			if tenantID == 0 {
+				slug = cfg.DefaultTenant
+			}
+		}
+		if tenantID == 0 {
+			if slug == "" {
+				return nil, status.Error(codes.InvalidArgument, i18n.T(ctx, "Tenant is required"))
+			}
+
+			var err error
+			if tenantID, err = tenants.Resolve(ctx, slug); err != nil {
+				return nil, statusError(ctx, log, "Failed to resolve tenant", err)
+			}
+		}
+
+		return handler(reqctx.WithTenantID(ctx, tenantID), req)
+	}
+}
+
+// claimsFrom returns the claims of the access token of the call
+func claimsFrom(ctx context.Context) (*jwt.Claims, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(*jwt.Claims)
+	return claims, ok
+}
+
+// firstMetadata returns the first value of an incoming metadata key
+func firstMetadata(ctx context.Context, key string) string {
+	if values := metadata.ValueFromIncomingContext(ctx, key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
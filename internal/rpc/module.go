@@ -0,0 +1,12 @@
+package rpc
+
+import "go.uber.org/fx"
+
+// Module provides the gRPC server and the services it serves
+var Module = fx.Module("rpc",
+	fx.Provide(
+		NewUserServer,
+		NewAuthServer,
+		NewServer,
+	),
+)
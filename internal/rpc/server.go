@@ -0,0 +1,57 @@
+package rpc
+
+import (
+	"fmt"
+
+	"github.com/firdanbash/go-clean-boiler/internal/service"
+	"github.com/firdanbash/go-clean-boiler/pkg/config"
+	"github.com/firdanbash/go-clean-boiler/pkg/i18n"
+	"github.com/firdanbash/go-clean-boiler/pkg/jwt"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	boilerv1 "github.com/firdanbash/go-clean-boiler/pkg/pb/boiler/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/reflection"
+)
+
+// NewServer creates the gRPC server with the user and auth services
+// registered. It uses the certificate files of the HTTP server when TLS is
+// enabled without autocert, and plaintext otherwise.
+func NewServer(
+	users *UserServer,
+	auth *AuthServer,
+	tenants service.TenantService,
+	jwtManager *jwt.Manager,
+	denylist jwt.Denylist,
+	bundle *i18n.Bundle,
+	cfg *config.Config,
+	log logger.Logger,
+) (*grpc.Server, error) {
+	interceptors := []grpc.UnaryServerInterceptor{
+		ContextInterceptor(bundle),
+		LoggingInterceptor(log),
+		RecoveryInterceptor(log),
+	}
+	if cfg.Tenancy.Enabled {
+		interceptors = append(interceptors, TenantInterceptor(tenants, cfg.Tenancy, jwtManager, log))
+	}
+	interceptors = append(interceptors, AuthInterceptor(jwtManager, denylist, PublicMethods...))
+	opts := []grpc.ServerOption{grpc.ChainUnaryInterceptor(interceptors...)}
+
+	tls := cfg.Server.TLS
+	if tls.Enabled && !tls.Autocert.Enabled {
+		creds, err := credentials.NewServerTLSFromFile(tls.CertFile, tls.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load gRPC TLS certificate: %w", err)
+		}
+		opts = append(opts, grpc.Creds(creds))
+	}
+
+	srv := grpc.NewServer(opts...)
+	boilerv1.RegisterUserServiceServer(srv, users)
+	boilerv1.RegisterAuthServiceServer(srv, auth)
+	if cfg.GRPC.Reflection {
+		reflection.Register(srv)
+	}
+	return srv, nil
+}
@@ -0,0 +1,136 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/dto/request"
+	"github.com/firdanbash/go-clean-boiler/internal/service"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	boilerv1 "github.com/firdanbash/go-clean-boiler/pkg/pb/boiler/v1"
+)
+
+// UserServer serves boilerv1.UserService with the user service
+type UserServer struct {
+	boilerv1.UnimplementedUserServiceServer
+	userService service.UserService
+	log         logger.Logger
+}
+
+// NewUserServer creates a new user gRPC server
+func NewUserServer(userService service.UserService, log logger.Logger) *UserServer {
+	return &UserServer{userService: userService, log: log}
+}
+
+// GetMe returns the authenticated user
+func (s *UserServer) GetMe(ctx context.Context, _ *boilerv1.GetMeRequest) (*boilerv1.GetMeResponse, error) {
+	claims, _ := claimsFrom(ctx)
+	user, err := s.userService.GetByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, statusError(ctx, s.log, "Failed to fetch user", err)
+	}
+	return &boilerv1.GetMeResponse{User: toUser(user)}, nil
+}
+
+// GetUser returns a user by ID
+func (s *UserServer) GetUser(ctx context.Context, req *boilerv1.GetUserRequest) (*boilerv1.GetUserResponse, error) {
+	id := uint(req.GetId())
+	if err := requireSelfOrAdmin(ctx, id); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userService.GetByID(ctx, id)
+	if err != nil {
+		return nil, statusError(ctx, s.log, "Failed to fetch user", err)
+	}
+	return &boilerv1.GetUserResponse{User: toUser(user)}, nil
+}
+
+// ListUsers returns a page of users
+func (s *UserServer) ListUsers(ctx context.Context, req *boilerv1.ListUsersRequest) (*boilerv1.ListUsersResponse, error) {
+	if err := requireRole(ctx, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	listReq := request.ListUsersRequest{
+		Page:           int(req.GetPage()),
+		PerPage:        int(req.GetPerPage()),
+		Search:         req.GetSearch(),
+		Sort:           req.GetSort(),
+		IncludeDeleted: req.GetIncludeDeleted(),
+	}
+	if err := validate(ctx, &listReq); err != nil {
+		return nil, err
+	}
+	if listReq.Page < 1 {
+		listReq.Page = 1
+	}
+	if listReq.PerPage < 1 || listReq.PerPage > 100 {
+		listReq.PerPage = 10
+	}
+
+	users, total, err := s.userService.GetAll(ctx, &listReq)
+	if err != nil {
+		return nil, statusError(ctx, s.log, "Failed to fetch users", err)
+	}
+
+	resp := &boilerv1.ListUsersResponse{Total: total, Users: make([]*boilerv1.User, len(users))}
+	for i := range users {
+		resp.Users[i] = toUser(&users[i])
+	}
+	return resp, nil
+}
+
+// CreateUser creates a user
+func (s *UserServer) CreateUser(ctx context.Context, req *boilerv1.CreateUserRequest) (*boilerv1.CreateUserResponse, error) {
+	if err := requireRole(ctx, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	createReq := request.CreateUserRequest{
+		Email:    req.GetEmail(),
+		Password: req.GetPassword(),
+		Name:     req.GetName(),
+		Role:     req.GetRole(),
+	}
+	if err := validate(ctx, &createReq); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userService.Create(ctx, &createReq)
+	if err != nil {
+		return nil, statusError(ctx, s.log, "Failed to create user", err)
+	}
+	return &boilerv1.CreateUserResponse{User: toUser(user)}, nil
+}
+
+// UpdateUser changes the email and/or name of a user
+func (s *UserServer) UpdateUser(ctx context.Context, req *boilerv1.UpdateUserRequest) (*boilerv1.UpdateUserResponse, error) {
+	id := uint(req.GetId())
+	if err := requireSelfOrAdmin(ctx, id); err != nil {
+		return nil, err
+	}
+
+	updateReq := request.UpdateUserRequest{Email: req.Email, Name: req.Name}
+	if err := validate(ctx, &updateReq); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userService.Update(ctx, id, &updateReq)
+	if err != nil {
+		return nil, statusError(ctx, s.log, "Failed to update user", err)
+	}
+	return &boilerv1.UpdateUserResponse{User: toUser(user)}, nil
+}
+
+// DeleteUser soft deletes a user
+func (s *UserServer) DeleteUser(ctx context.Context, req *boilerv1.DeleteUserRequest) (*boilerv1.DeleteUserResponse, error) {
+	if err := requireRole(ctx, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	if err := s.userService.Delete(ctx, uint(req.GetId())); err != nil {
+		return nil, statusError(ctx, s.log, "Failed to delete user", err)
+	}
+	return &boilerv1.DeleteUserResponse{}, nil
+}
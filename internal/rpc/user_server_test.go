@@ -0,0 +1,156 @@
+package rpc_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/firdanbash/go-clean-boiler/internal/dto/response"
+	"github.com/firdanbash/go-clean-boiler/internal/mocks"
+	"github.com/firdanbash/go-clean-boiler/internal/rpc"
+	"github.com/firdanbash/go-clean-boiler/internal/service"
+	"github.com/firdanbash/go-clean-boiler/internal/testutil"
+	"github.com/firdanbash/go-clean-boiler/pkg/apperror"
+	"github.com/firdanbash/go-clean-boiler/pkg/i18n"
+	"github.com/firdanbash/go-clean-boiler/pkg/jwt"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	boilerv1 "github.com/firdanbash/go-clean-boiler/pkg/pb/boiler/v1"
+	"go.uber.org/mock/gomock"
+	"golang.org/x/text/language"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newUserClient serves the user service over an in-memory connection with a
+// mocked service behind the real interceptors
+func newUserClient(t *testing.T) (boilerv1.UserServiceClient, *mocks.MockUserService, *jwt.Manager) {
+	t.Helper()
+	svc := mocks.NewMockUserService(gomock.NewController(t))
+	m := testutil.JWTManager(t)
+
+	srv := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		rpc.ContextInterceptor(i18n.NewBundle(language.English)),
+		rpc.RecoveryInterceptor(logger.Nop()),
+		rpc.AuthInterceptor(m, nil, rpc.PublicMethods...),
+	))
+	boilerv1.RegisterUserServiceServer(srv, rpc.NewUserServer(svc, logger.Nop()))
+
+	lis := bufconn.Listen(1 << 20)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return boilerv1.NewUserServiceClient(conn), svc, m
+}
+
+// withToken returns a context sending an access token for a user with id and role
+func withToken(t *testing.T, m *jwt.Manager, opts ...testutil.UserOption) context.Context {
+	t.Helper()
+	token := testutil.Token(t, m, testutil.NewUser(opts...))
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+func TestUserServerRequiresToken(t *testing.T) {
+	client, _, _ := newUserClient(t)
+
+	_, err := client.GetMe(context.Background(), &boilerv1.GetMeRequest{})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("code = %v, want Unauthenticated", status.Code(err))
+	}
+}
+
+func TestUserServerGetUser(t *testing.T) {
+	client, svc, m := newUserClient(t)
+	ctx := withToken(t, m, testutil.WithID(5))
+
+	svc.EXPECT().GetByID(gomock.Any(), uint(5)).Return(&response.UserResponse{ID: 5, Email: "me@example.com"}, nil)
+	resp, err := client.GetUser(ctx, &boilerv1.GetUserRequest{Id: 5})
+	if err != nil {
+		t.Fatalf("GetUser: %v", err)
+	}
+	if resp.GetUser().GetEmail() != "me@example.com" {
+		t.Errorf("email = %q, want me@example.com", resp.GetUser().GetEmail())
+	}
+
+	_, err = client.GetUser(ctx, &boilerv1.GetUserRequest{Id: 6})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("other user: code = %v, want PermissionDenied", status.Code(err))
+	}
+}
+
+func TestUserServerMapsErrors(t *testing.T) {
+	client, svc, m := newUserClient(t)
+	ctx := withToken(t, m, testutil.WithID(1), testutil.AsAdmin())
+
+	tests := []struct {
+		name   string
+		err    error
+		code   codes.Code
+		reason apperror.Code
+	}{
+		{"missing user", service.ErrUserNotFound, codes.NotFound, apperror.CodeUserNotFound},
+		{"taken email", service.ErrEmailExists, codes.AlreadyExists, apperror.CodeUserEmailTaken},
+		{"unexpected failure", errors.New("deadlock detected"), codes.Internal, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc.EXPECT().Update(gomock.Any(), uint(2), gomock.Any()).Return(nil, tt.err)
+			_, err := client.UpdateUser(ctx, &boilerv1.UpdateUserRequest{Id: 2, Name: testutil.Ptr("Renamed")})
+			if status.Code(err) != tt.code {
+				t.Errorf("code = %v, want %v", status.Code(err), tt.code)
+			}
+			var reason apperror.Code
+			for _, detail := range status.Convert(err).Details() {
+				if info, ok := detail.(*errdetails.ErrorInfo); ok {
+					reason = apperror.Code(info.Reason)
+				}
+			}
+			if reason != tt.reason {
+				t.Errorf("reason = %q, want %q", reason, tt.reason)
+			}
+		})
+	}
+}
+
+func TestUserServerCreateValidates(t *testing.T) {
+	client, _, m := newUserClient(t)
+
+	_, err := client.CreateUser(withToken(t, m, testutil.WithID(1)), &boilerv1.CreateUserRequest{Email: "new@example.com"})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("as user: code = %v, want PermissionDenied", status.Code(err))
+	}
+
+	_, err = client.CreateUser(withToken(t, m, testutil.WithID(1), testutil.AsAdmin()), &boilerv1.CreateUserRequest{Email: "not-an-email"})
+	st := status.Convert(err)
+	if st.Code() != codes.InvalidArgument {
+		t.Fatalf("code = %v, want InvalidArgument", st.Code())
+	}
+
+	fields := map[string]bool{}
+	for _, detail := range st.Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			for _, v := range badRequest.GetFieldViolations() {
+				fields[v.GetField()] = true
+			}
+		}
+	}
+	for _, field := range []string{"email", "password", "name"} {
+		if !fields[field] {
+			t.Errorf("no violation for %s in %v", field, fields)
+		}
+	}
+}
@@ -0,0 +1,447 @@
+// Package scaffold generates the files of a new CRUD resource (domain model,
+// repository, service, DTOs, handler, routes and migrations) from templates that
+// follow the layout of the user module, and wires them into the application.
+package scaffold
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+//go:embed templates/*.tmpl
+var templates embed.FS
+
+// wireFiles receive the generated wiring at their "// gen:" markers: the model
+// list of auto migration and the dependency injection modules
+var wireFiles = []string{
+	"internal/app/models.go",
+	"internal/repository/postgres/module.go",
+	"internal/service/module.go",
+	"internal/handler/module.go",
+	"internal/router/module.go",
+}
+
+// Options describes the resource to generate
+type Options struct {
+	Name   string // singular name, e.g. Product or order_item
+	Plural string // optional plural override, e.g. people
+	Fields string // comma separated name:type pairs, e.g. "name:string,price:float64"
+	Dir    string // repository root
+	Force  bool   // overwrite existing files
+}
+
+// Result lists what was generated
+type Result struct {
+	Created []string
+	Wired   []string
+}
+
+// Field is one column of the generated model
+type Field struct {
+	Name  string // Go name, e.g. UnitPrice
+	Snake string // column and JSON name, e.g. unit_price
+	Type  fieldType
+}
+
+// fieldType maps a --fields type onto Go and SQL types
+type fieldType struct {
+	Go       string
+	Postgres string
+	MySQL    string
+	Validate string // create request validation
+	Update   string // update request validation, applied to set fields only
+	Default  string // SQL default, empty for none
+}
+
+var fieldTypes = map[string]fieldType{
+	"string":  {Go: "string", Postgres: "VARCHAR(255)", MySQL: "VARCHAR(255)", Validate: "required,max=255", Update: "omitnil,max=255"},
+	"text":    {Go: "string", Postgres: "TEXT", MySQL: "TEXT", Validate: "required"},
+	"int":     {Go: "int", Postgres: "INTEGER", MySQL: "INT", Default: "0"},
+	"int64":   {Go: "int64", Postgres: "BIGINT", MySQL: "BIGINT", Default: "0"},
+	"uint":    {Go: "uint", Postgres: "BIGINT", MySQL: "BIGINT UNSIGNED", Default: "0"},
+	"float64": {Go: "float64", Postgres: "DOUBLE PRECISION", MySQL: "DOUBLE", Default: "0"},
+	"bool":    {Go: "bool", Postgres: "BOOLEAN", MySQL: "TINYINT(1)", Default: "FALSE"},
+	"time":    {Go: "time.Time", Postgres: "TIMESTAMP", MySQL: "DATETIME(3)", Validate: "required"},
+}
+
+// data is passed to every template
+type data struct {
+	Module      string
+	Name        string // Product
+	PluralName  string // Products
+	Var         string // product
+	PluralVar   string // products
+	Snake       string // product
+	Table       string // products
+	Path        string // products, order-items
+	Human       string // product, order item
+	Title       string // Product, Order item
+	TitlePlural string // Products, Order items
+	HumanPlural string // products, order items
+	Fields      []Field
+	HasTime     bool
+	Version     string // migration number, e.g. 000011
+}
+
+// file is one generated file
+type file struct {
+	template string
+	path     string
+	goSource bool
+}
+
+// Generate renders the resource files and wires them into the application
+func Generate(opts Options) (*Result, error) {
+	d, err := newData(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []file{
+		{"domain.go.tmpl", "internal/domain/" + d.Snake + ".go", true},
+		{"repository.go.tmpl", "internal/repository/" + d.Snake + "_repository.go", true},
+		{"repository_postgres.go.tmpl", "internal/repository/postgres/" + d.Snake + "_repository.go", true},
+		{"service.go.tmpl", "internal/service/" + d.Snake + "_service.go", true},
+		{"request.go.tmpl", "internal/dto/request/" + d.Snake + "_request.go", true},
+		{"response.go.tmpl", "internal/dto/response/" + d.Snake + "_response.go", true},
+		{"handler.go.tmpl", "internal/handler/" + d.Snake + "_handler.go", true},
+		{"routes.go.tmpl", "internal/router/" + d.Snake + "_routes.go", true},
+		{"migration_postgres.up.sql.tmpl", "migrations/postgres/" + d.Version + "_create_" + d.Table + "_table.up.sql", false},
+		{"migration_postgres.down.sql.tmpl", "migrations/postgres/" + d.Version + "_create_" + d.Table + "_table.down.sql", false},
+		{"migration_mysql.up.sql.tmpl", "migrations/mysql/" + d.Version + "_create_" + d.Table + "_table.up.sql", false},
+		{"migration_mysql.down.sql.tmpl", "migrations/mysql/" + d.Version + "_create_" + d.Table + "_table.down.sql", false},
+	}
+
+	// Render everything first so a template error leaves the tree untouched
+	rendered := make(map[string][]byte, len(files))
+	for _, f := range files {
+		path := filepath.Join(opts.Dir, f.path)
+		if _, err := os.Stat(path); err == nil && !opts.Force {
+			return nil, fmt.Errorf("%s already exists, use --force to overwrite", f.path)
+		}
+		out, err := render(f.template, d)
+		if err != nil {
+			return nil, err
+		}
+		if f.goSource {
+			if out, err = format.Source(out); err != nil {
+				return nil, fmt.Errorf("format %s: %w", f.path, err)
+			}
+		}
+		rendered[f.path] = out
+	}
+
+	result := &Result{}
+	for _, f := range files {
+		if err := os.WriteFile(filepath.Join(opts.Dir, f.path), rendered[f.path], 0o644); err != nil {
+			return nil, err
+		}
+		result.Created = append(result.Created, f.path)
+	}
+
+	wired, err := wire(opts.Dir, d)
+	if err != nil {
+		return result, fmt.Errorf("wire: %w", err)
+	}
+	result.Wired = wired
+	return result, nil
+}
+
+func newData(opts Options) (*data, error) {
+	words := splitWords(opts.Name)
+	if len(words) == 0 {
+		return nil, errors.New("resource name is required")
+	}
+	pluralWords := append([]string(nil), words...)
+	if opts.Plural != "" {
+		pluralWords = splitWords(opts.Plural)
+	} else {
+		pluralWords[len(pluralWords)-1] = pluralize(words[len(words)-1])
+	}
+
+	module, err := modulePath(opts.Dir)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := parseFields(opts.Fields)
+	if err != nil {
+		return nil, err
+	}
+	version, err := nextMigrationVersion(opts.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &data{
+		Module:      module,
+		Name:        pascal(words),
+		PluralName:  pascal(pluralWords),
+		Var:         camel(words),
+		PluralVar:   camel(pluralWords),
+		Snake:       strings.Join(words, "_"),
+		Table:       strings.Join(pluralWords, "_"),
+		Path:        strings.Join(pluralWords, "-"),
+		Human:       strings.Join(words, " "),
+		Title:       sentence(words),
+		TitlePlural: sentence(pluralWords),
+		HumanPlural: strings.Join(pluralWords, " "),
+		Fields:      fields,
+		Version:     version,
+	}
+	for _, f := range fields {
+		if f.Type.Go == "time.Time" {
+			d.HasTime = true
+		}
+	}
+	return d, nil
+}
+
+// parseFields parses "name:string,price:float64"
+func parseFields(spec string) ([]Field, error) {
+	if strings.TrimSpace(spec) == "" {
+		spec = "name:string"
+	}
+
+	var fields []Field
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(spec, ",") {
+		name, typ, ok := strings.Cut(strings.TrimSpace(part), ":")
+		if !ok {
+			return nil, fmt.Errorf("field %q must be name:type", part)
+		}
+		ft, ok := fieldTypes[typ]
+		if !ok {
+			return nil, fmt.Errorf("field %q has unknown type %q (%s)", name, typ, strings.Join(fieldTypeNames(), ", "))
+		}
+		words := splitWords(name)
+		if len(words) == 0 {
+			return nil, fmt.Errorf("field %q has no name", part)
+		}
+		snake := strings.Join(words, "_")
+		switch snake {
+		case "id", "created_at", "updated_at", "deleted_at":
+			return nil, fmt.Errorf("field %q is added automatically", snake)
+		}
+		if seen[snake] {
+			return nil, fmt.Errorf("field %q is listed twice", snake)
+		}
+		seen[snake] = true
+		fields = append(fields, Field{Name: pascal(words), Snake: snake, Type: ft})
+	}
+	return fields, nil
+}
+
+func fieldTypeNames() []string {
+	names := make([]string, 0, len(fieldTypes))
+	for name := range fieldTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func render(name string, d *data) ([]byte, error) {
+	tmpl, err := template.ParseFS(templates, "templates/"+name)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, d); err != nil {
+		return nil, fmt.Errorf("render %s: %w", name, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// wire inserts the rendered wiring snippets above their "// gen:<name>" markers.
+// Every file is updated in memory first so a missing marker changes nothing.
+func wire(dir string, d *data) ([]string, error) {
+	out, err := render("wire.tmpl", d)
+	if err != nil {
+		return nil, err
+	}
+
+	snippets := make(map[string]string)
+	for _, block := range strings.Split(string(out), "--- ")[1:] {
+		marker, snippet, _ := strings.Cut(block, "\n")
+		snippets[marker] = strings.TrimRight(snippet, "\n")
+	}
+
+	updated := make(map[string][]byte)
+	var wired []string
+	for _, name := range wireFiles {
+		src, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		out, changed, err := insertSnippets(src, snippets)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if changed {
+			updated[name] = out
+			wired = append(wired, name)
+		}
+	}
+
+	if len(snippets) > 0 {
+		var missing []string
+		for marker := range snippets {
+			missing = append(missing, "// gen:"+marker)
+		}
+		sort.Strings(missing)
+		return nil, fmt.Errorf("markers not found: %s", strings.Join(missing, ", "))
+	}
+
+	for _, name := range wired {
+		if err := os.WriteFile(filepath.Join(dir, name), updated[name], 0o644); err != nil {
+			return nil, err
+		}
+	}
+	return wired, nil
+}
+
+// insertSnippets inserts the snippets of the markers found in src, keeping the
+// marker indentation, and removes them from snippets
+func insertSnippets(src []byte, snippets map[string]string) ([]byte, bool, error) {
+	var result []string
+	changed := false
+	for _, line := range strings.Split(string(src), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if marker, ok := strings.CutPrefix(trimmed, "// gen:"); ok {
+			snippet, ok := snippets[marker]
+			if !ok {
+				return nil, false, fmt.Errorf("no snippet for marker %q", marker)
+			}
+			indent := line[:len(line)-len(strings.TrimLeft(line, "\t "))]
+			for _, s := range strings.Split(snippet, "\n") {
+				if s == "" {
+					result = append(result, "")
+					continue
+				}
+				result = append(result, indent+s)
+			}
+			delete(snippets, marker)
+			changed = true
+		}
+		result = append(result, line)
+	}
+	if !changed {
+		return src, false, nil
+	}
+
+	formatted, err := format.Source([]byte(strings.Join(result, "\n")))
+	if err != nil {
+		return nil, false, err
+	}
+	return formatted, true, nil
+}
+
+var moduleLine = regexp.MustCompile(`(?m)^module\s+(\S+)`)
+
+func modulePath(dir string) (string, error) {
+	src, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return "", fmt.Errorf("run from the repository root: %w", err)
+	}
+	m := moduleLine.FindSubmatch(src)
+	if m == nil {
+		return "", errors.New("go.mod has no module line")
+	}
+	return string(m[1]), nil
+}
+
+// nextMigrationVersion returns the number after the highest existing migration
+func nextMigrationVersion(dir string) (string, error) {
+	highest := 0
+	for _, driver := range []string{"postgres", "mysql"} {
+		entries, err := os.ReadDir(filepath.Join(dir, "migrations", driver))
+		if err != nil {
+			return "", err
+		}
+		for _, entry := range entries {
+			prefix, _, _ := strings.Cut(entry.Name(), "_")
+			if n, err := strconv.Atoi(prefix); err == nil && n > highest {
+				highest = n
+			}
+		}
+	}
+	return fmt.Sprintf("%06d", highest+1), nil
+}
+
+// splitWords splits PascalCase, camelCase, snake_case and kebab-case names into lower case words
+func splitWords(s string) []string {
+	var words []string
+	var current []rune
+	runes := []rune(strings.TrimSpace(s))
+	flush := func() {
+		if len(current) > 0 {
+			words = append(words, strings.ToLower(string(current)))
+			current = current[:0]
+		}
+	}
+	for i, r := range runes {
+		switch {
+		case r == '_' || r == '-' || r == ' ':
+			flush()
+		case unicode.IsUpper(r):
+			// Start a new word at "aB" and at the last capital of "ABc"
+			if i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))) {
+				flush()
+			}
+			current = append(current, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			current = append(current, r)
+		}
+	}
+	flush()
+	return words
+}
+
+// initialisms are kept upper case in Go names
+var initialisms = map[string]string{"id": "ID", "url": "URL", "api": "API", "ip": "IP", "sku": "SKU", "uuid": "UUID"}
+
+func pascal(words []string) string {
+	var b strings.Builder
+	for _, w := range words {
+		if up, ok := initialisms[w]; ok {
+			b.WriteString(up)
+			continue
+		}
+		b.WriteString(strings.ToUpper(w[:1]) + w[1:])
+	}
+	return b.String()
+}
+
+func camel(words []string) string {
+	return words[0] + pascal(words[1:])
+}
+
+// sentence joins words with spaces and capitalises the first one
+func sentence(words []string) string {
+	s := strings.Join(words, " ")
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// pluralize applies the common English plural rules; use --plural for irregular nouns
+func pluralize(word string) string {
+	switch {
+	case strings.HasSuffix(word, "y") && len(word) > 1 && !strings.ContainsRune("aeiou", rune(word[len(word)-2])):
+		return word[:len(word)-1] + "ies"
+	case strings.HasSuffix(word, "s"), strings.HasSuffix(word, "x"), strings.HasSuffix(word, "z"),
+		strings.HasSuffix(word, "ch"), strings.HasSuffix(word, "sh"):
+		return word + "es"
+	default:
+		return word + "s"
+	}
+}
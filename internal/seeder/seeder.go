@@ -0,0 +1,50 @@
+package seeder
+
+import (
+	"context"
+	"errors"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/repository"
+	"github.com/firdanbash/go-clean-boiler/pkg/password"
+	"gorm.io/gorm"
+)
+
+// Seeder inserts the initial data a fresh database needs
+type Seeder struct {
+	users  repository.UserRepository
+	hasher password.Hasher
+}
+
+// New creates a new seeder
+func New(users repository.UserRepository, hasher password.Hasher) *Seeder {
+	return &Seeder{users: users, hasher: hasher}
+}
+
+// Admin creates an admin account unless a user with the email already exists.
+// It reports whether the account was created.
+func (s *Seeder) Admin(ctx context.Context, email, plain, name string) (bool, error) {
+	_, err := s.users.FindByEmail(ctx, email)
+	if err == nil {
+		return false, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, err
+	}
+
+	hashedPassword, err := s.hasher.Hash(plain)
+	if err != nil {
+		return false, err
+	}
+
+	user := &domain.User{
+		Email:    email,
+		Password: hashedPassword,
+		Name:     name,
+		Role:     domain.RoleAdmin,
+	}
+	if err := s.users.Create(ctx, user); err != nil {
+		return false, err
+	}
+	return true, nil
+}
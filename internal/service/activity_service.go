@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/dto/request"
+	"github.com/firdanbash/go-clean-boiler/internal/dto/response"
+	"github.com/firdanbash/go-clean-boiler/internal/repository"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"github.com/firdanbash/go-clean-boiler/pkg/reqctx"
+	"go.uber.org/zap"
+)
+
+type ActivityService interface {
+	RecordLogin(ctx context.Context, userID *uint, email string, success bool, failureReason string)
+	ListForUser(ctx context.Context, userID uint, req *request.ListActivityRequest) ([]response.LoginEventResponse, int64, error)
+}
+
+type activityService struct {
+	repo repository.LoginEventRepository
+	log  logger.Logger
+}
+
+// NewActivityService creates a new activity service
+func NewActivityService(repo repository.LoginEventRepository, log logger.Logger) ActivityService {
+	return &activityService{repo: repo, log: log}
+}
+
+// RecordLogin stores a login attempt with the client IP and user agent taken from ctx.
+// Failures are logged rather than returned so history tracking never blocks sign-in.
+func (s *activityService) RecordLogin(ctx context.Context, userID *uint, email string, success bool, failureReason string) {
+	event := &domain.LoginEvent{
+		UserID:        userID,
+		Email:         email,
+		Success:       success,
+		FailureReason: failureReason,
+		IP:            reqctx.ClientIP(ctx),
+		UserAgent:     reqctx.UserAgent(ctx),
+	}
+
+	if err := s.repo.Create(context.WithoutCancel(ctx), event); err != nil {
+		logger.Ctx(ctx, s.log).Error("Failed to record login event", zap.String("email", email), zap.Error(err))
+	}
+}
+
+// ListForUser lists a user's recent login attempts with pagination
+func (s *activityService) ListForUser(ctx context.Context, userID uint, req *request.ListActivityRequest) ([]response.LoginEventResponse, int64, error) {
+	offset := (req.Page - 1) * req.PerPage
+	events, total, err := s.repo.FindByUserID(ctx, userID, req.PerPage, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	eventResponses := make([]response.LoginEventResponse, len(events))
+	for i, event := range events {
+		eventResponses[i] = response.LoginEventResponse{
+			ID:            event.ID,
+			Success:       event.Success,
+			FailureReason: event.FailureReason,
+			IP:            event.IP,
+			UserAgent:     event.UserAgent,
+			CreatedAt:     event.CreatedAt,
+		}
+	}
+
+	return eventResponses, total, nil
+}
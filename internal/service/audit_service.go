@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/dto/request"
+	"github.com/firdanbash/go-clean-boiler/internal/dto/response"
+	"github.com/firdanbash/go-clean-boiler/internal/repository"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"github.com/firdanbash/go-clean-boiler/pkg/reqctx"
+	"go.uber.org/zap"
+	"gorm.io/datatypes"
+)
+
+// Audited entity types
+const (
+	AuditEntityUser = "user"
+)
+
+type AuditService interface {
+	Record(ctx context.Context, action, entityType string, entityID uint, before, after interface{})
+	List(ctx context.Context, req *request.ListAuditLogsRequest) ([]response.AuditLogResponse, int64, error)
+}
+
+type auditService struct {
+	repo repository.AuditLogRepository
+	log  logger.Logger
+}
+
+// NewAuditService creates a new audit service
+func NewAuditService(repo repository.AuditLogRepository, log logger.Logger) AuditService {
+	return &auditService{repo: repo, log: log}
+}
+
+// Record stores an audit entry for a mutating operation. The actor, the admin
+// impersonating them if any, IP and user agent are taken from ctx. Failures are logged rather than returned so auditing never
+// breaks the operation being audited.
+func (s *auditService) Record(ctx context.Context, action, entityType string, entityID uint, before, after interface{}) {
+	entry := &domain.AuditLog{
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		IP:         reqctx.ClientIP(ctx),
+		UserAgent:  reqctx.UserAgent(ctx),
+	}
+
+	if actorID, ok := reqctx.UserID(ctx); ok {
+		entry.ActorID = &actorID
+	}
+	if impersonatorID, ok := reqctx.ImpersonatorID(ctx); ok {
+		entry.ImpersonatorID = &impersonatorID
+	}
+
+	var err error
+	if entry.Before, err = marshalSnapshot(before); err != nil {
+		logger.Ctx(ctx, s.log).Error("Failed to encode audit snapshot", zap.String("action", action), zap.Error(err))
+		return
+	}
+	if entry.After, err = marshalSnapshot(after); err != nil {
+		logger.Ctx(ctx, s.log).Error("Failed to encode audit snapshot", zap.String("action", action), zap.Error(err))
+		return
+	}
+
+	// Detach from request cancellation so the entry is stored even if the client went away
+	if err := s.repo.Create(context.WithoutCancel(ctx), entry); err != nil {
+		logger.Ctx(ctx, s.log).Error("Failed to record audit log",
+			zap.String("action", action),
+			zap.String("entity_type", entityType),
+			zap.Uint("entity_id", entityID),
+			zap.Error(err),
+		)
+	}
+}
+
+// List lists audit log entries matching the filters with pagination
+func (s *auditService) List(ctx context.Context, req *request.ListAuditLogsRequest) ([]response.AuditLogResponse, int64, error) {
+	filter := repository.AuditLogFilter{
+		Action:     req.Action,
+		EntityType: req.EntityType,
+	}
+	if req.ActorID != 0 {
+		filter.ActorID = &req.ActorID
+	}
+	if req.EntityID != 0 {
+		filter.EntityID = &req.EntityID
+	}
+	if !req.From.IsZero() {
+		filter.From = &req.From
+	}
+	if !req.To.IsZero() {
+		// Include the whole "to" day
+		to := req.To.AddDate(0, 0, 1)
+		filter.To = &to
+	}
+
+	offset := (req.Page - 1) * req.PerPage
+	logs, total, err := s.repo.FindAll(ctx, filter, req.PerPage, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	logResponses := make([]response.AuditLogResponse, len(logs))
+	for i, entry := range logs {
+		logResponses[i] = response.AuditLogResponse{
+			ID:             entry.ID,
+			ActorID:        entry.ActorID,
+			ImpersonatorID: entry.ImpersonatorID,
+			Action:         entry.Action,
+			EntityType:     entry.EntityType,
+			EntityID:       entry.EntityID,
+			Before:         json.RawMessage(entry.Before),
+			After:          json.RawMessage(entry.After),
+			IP:             entry.IP,
+			UserAgent:      entry.UserAgent,
+			CreatedAt:      entry.CreatedAt,
+		}
+	}
+
+	return logResponses, total, nil
+}
+
+// marshalSnapshot encodes an entity snapshot, returning nil for a missing snapshot
+func marshalSnapshot(v interface{}) (datatypes.JSON, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return datatypes.JSON(data), nil
+}
@@ -1,50 +1,155 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"net/url"
+	"time"
 
 	"github.com/firdanbash/go-clean-boiler/internal/domain"
 	"github.com/firdanbash/go-clean-boiler/internal/dto/request"
 	"github.com/firdanbash/go-clean-boiler/internal/dto/response"
+	"github.com/firdanbash/go-clean-boiler/internal/event"
+	"github.com/firdanbash/go-clean-boiler/internal/notification"
 	"github.com/firdanbash/go-clean-boiler/internal/repository"
+	"github.com/firdanbash/go-clean-boiler/pkg/authz"
+	"github.com/firdanbash/go-clean-boiler/pkg/config"
 	"github.com/firdanbash/go-clean-boiler/pkg/jwt"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"github.com/firdanbash/go-clean-boiler/pkg/mailer"
+	"github.com/firdanbash/go-clean-boiler/pkg/password"
+	"github.com/firdanbash/go-clean-boiler/pkg/reqctx"
+	"github.com/firdanbash/go-clean-boiler/pkg/tracing"
+	"github.com/pquerna/otp/totp"
+	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
+// mfaRecoveryCodeCount is the number of recovery codes issued when MFA is enabled
+const mfaRecoveryCodeCount = 10
+
 type AuthService interface {
-	Register(req *request.RegisterRequest) (*response.AuthResponse, error)
-	Login(req *request.LoginRequest) (*response.AuthResponse, error)
+	Register(ctx context.Context, req *request.RegisterRequest) (*response.AuthResponse, error)
+	Login(ctx context.Context, req *request.LoginRequest) (*response.AuthResponse, error)
+	ForgotPassword(ctx context.Context, req *request.ForgotPasswordRequest) error
+	ResetPassword(ctx context.Context, req *request.ResetPasswordRequest) error
+	EnableMFA(ctx context.Context, userID uint) (*response.MFASetupResponse, error)
+	ConfirmMFA(ctx context.Context, userID uint, req *request.MFACodeRequest) (*response.MFARecoveryCodesResponse, error)
+	DisableMFA(ctx context.Context, userID uint, req *request.MFACodeRequest) error
+	VerifyMFA(ctx context.Context, req *request.MFAVerifyRequest) (*response.AuthResponse, error)
+	Logout(ctx context.Context, tokenID string, expiresAt time.Time) error
+	LogoutAll(ctx context.Context, userID uint) error
+	Impersonate(ctx context.Context, impersonatorID, userID uint) (*response.AuthResponse, error)
 }
 
 type authService struct {
-	userRepo  repository.UserRepository
-	jwtSecret string
-	jwtExpiry string
+	userRepo       repository.UserRepository
+	resetTokenRepo repository.PasswordResetTokenRepository
+	recoveryRepo   repository.MFARecoveryCodeRepository
+	denylist       repository.RevokedTokenRepository
+	sessions       repository.SessionRepository
+	roleRepo       repository.RoleRepository
+	hasher         password.Hasher
+	passwords      PasswordHistoryService
+	activity       ActivityService
+	audit          AuditService
+	policies       PolicyEnforcer
+	guard          LoginGuard
+	mfaAttempts    MFAAttempts
+	captcha        CaptchaVerifier
+	events         EventPublisher
+	responses      CacheInvalidator
+	dispatcher     event.Dispatcher
+	tx             repository.Transactor
+	outbox         repository.OutboxRepository
+	mailer         mailer.Mailer
+	templates      *mailer.Templates
+	notifier       notification.Notifier
+	authCfg        config.AuthConfig
+	jwtManager     *jwt.Manager
+	jwtExpiry      string
+	rememberExpiry string
+	log            logger.Logger
 }
 
 // NewAuthService creates a new auth service
-func NewAuthService(userRepo repository.UserRepository, jwtSecret, jwtExpiry string) AuthService {
+func NewAuthService(
+	userRepo repository.UserRepository,
+	resetTokenRepo repository.PasswordResetTokenRepository,
+	recoveryRepo repository.MFARecoveryCodeRepository,
+	denylist repository.RevokedTokenRepository,
+	sessions repository.SessionRepository,
+	roleRepo repository.RoleRepository,
+	hasher password.Hasher,
+	passwords PasswordHistoryService,
+	activity ActivityService,
+	audit AuditService,
+	policies PolicyEnforcer,
+	guard LoginGuard,
+	mfaAttempts MFAAttempts,
+	captcha CaptchaVerifier,
+	events EventPublisher,
+	responses CacheInvalidator,
+	dispatcher event.Dispatcher,
+	tx repository.Transactor,
+	outbox repository.OutboxRepository,
+	m mailer.Mailer,
+	templates *mailer.Templates,
+	notifier notification.Notifier,
+	authCfg config.AuthConfig,
+	jwtManager *jwt.Manager,
+	jwtExpiry string,
+	rememberExpiry string,
+	log logger.Logger,
+) AuthService {
 	return &authService{
-		userRepo:  userRepo,
-		jwtSecret: jwtSecret,
-		jwtExpiry: jwtExpiry,
+		userRepo:       userRepo,
+		resetTokenRepo: resetTokenRepo,
+		recoveryRepo:   recoveryRepo,
+		denylist:       denylist,
+		sessions:       sessions,
+		roleRepo:       roleRepo,
+		hasher:         hasher,
+		passwords:      passwords,
+		activity:       activity,
+		audit:          audit,
+		policies:       policies,
+		guard:          guard,
+		mfaAttempts:    mfaAttempts,
+		captcha:        captcha,
+		events:         events,
+		responses:      responses,
+		dispatcher:     dispatcher,
+		tx:             tx,
+		outbox:         outbox,
+		mailer:         m,
+		templates:      templates,
+		notifier:       notifier,
+		authCfg:        authCfg,
+		jwtManager:     jwtManager,
+		jwtExpiry:      jwtExpiry,
+		rememberExpiry: rememberExpiry,
+		log:            log,
 	}
 }
 
 // Register registers a new user
-func (s *authService) Register(req *request.RegisterRequest) (*response.AuthResponse, error) {
+func (s *authService) Register(ctx context.Context, req *request.RegisterRequest) (*response.AuthResponse, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.Register")
+	defer span.End()
+
 	// Check if email already exists
-	_, err := s.userRepo.FindByEmail(req.Email)
+	_, err := s.userRepo.FindByEmail(ctx, req.Email)
 	if err == nil {
-		return nil, errors.New("email already exists")
+		return nil, ErrEmailExists
 	}
 	if !errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, err
 	}
 
 	// Hash password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	hashedPassword, err := s.hasher.Hash(req.Password)
 	if err != nil {
 		return nil, err
 	}
@@ -52,73 +157,696 @@ func (s *authService) Register(req *request.RegisterRequest) (*response.AuthResp
 	// Create user
 	user := &domain.User{
 		Email:    req.Email,
-		Password: string(hashedPassword),
+		Password: hashedPassword,
 		Name:     req.Name,
+		Role:     domain.RoleUser,
 	}
 
-	if err := s.userRepo.Create(user); err != nil {
+	var change domain.Event
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.Create(ctx, user); err != nil {
+			return err
+		}
+		if err := s.passwords.Record(ctx, user); err != nil {
+			return err
+		}
+		change = domain.NewEvent(domain.EventUserCreated, user, toUserResponse(user))
+		return enqueue(ctx, s.outbox, change)
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	// Generate JWT token
-	token, err := s.generateToken(user)
+	// Self-registration: the new user is its own actor
+	ctx = reqctx.WithUserID(ctx, user.ID)
+	s.events.Publish(ctx, change)
+	s.responses.Invalidate(ctx, CacheTagUsers)
+	s.dispatcher.Dispatch(ctx, event.UserRegistered{User: toUserResponse(user), SelfService: true})
+
+	return s.issueAuthResponse(ctx, user, false)
+}
+
+// Login authenticates a user and returns a token. Failed logins on an account
+// delay its next logins, and require a captcha when a verifier is set up.
+func (s *authService) Login(ctx context.Context, req *request.LoginRequest) (*response.AuthResponse, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.Login")
+	defer span.End()
+
+	if err := s.checkLoginAttempt(ctx, req); err != nil {
+		return nil, err
+	}
+
+	// Find user by email
+	user, err := s.userRepo.FindByEmail(repository.WithSecrets(ctx), req.Email)
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			s.activity.RecordLogin(ctx, nil, req.Email, false, domain.LoginFailureUnknownEmail)
+			s.failLogin(ctx, req.Email)
+			return nil, ErrInvalidCredentials
+		}
 		return nil, err
 	}
 
-	return &response.AuthResponse{
-		User: response.UserResponse{
-			ID:        user.ID,
-			Email:     user.Email,
-			Name:      user.Name,
-			CreatedAt: user.CreatedAt,
-			UpdatedAt: user.UpdatedAt,
-		},
-		Token: token,
+	// Verify password
+	match, err := s.hasher.Verify(user.Password, req.Password)
+	if err != nil {
+		return nil, err
+	}
+	if !match {
+		s.activity.RecordLogin(ctx, &user.ID, user.Email, false, domain.LoginFailureInvalidPassword)
+		s.failLogin(ctx, req.Email)
+		return nil, ErrInvalidCredentials
+	}
+	s.rehashPassword(ctx, user, req.Password)
+
+	// Require a second factor before issuing the final token; failed logins
+	// are only forgotten once it is verified
+	if user.MFAEnabled {
+		mfaToken, err := s.jwtManager.GenerateMFAToken(user.ID, user.TenantID, user.Email, req.RememberMe, s.authCfg.MFAChallengeExpiration)
+		if err != nil {
+			return nil, err
+		}
+
+		return &response.AuthResponse{
+			MFARequired: true,
+			MFAToken:    mfaToken,
+		}, nil
+	}
+
+	s.resetLogin(ctx, req.Email)
+	s.activity.RecordLogin(ctx, &user.ID, user.Email, true, "")
+
+	return s.issueAuthResponse(ctx, user, req.RememberMe)
+}
+
+// ForgotPassword issues a password reset token and emails it to the user.
+// It returns nil for unknown emails so callers cannot probe for registered accounts.
+func (s *authService) ForgotPassword(ctx context.Context, req *request.ForgotPasswordRequest) error {
+	ctx, span := tracing.Start(ctx, "AuthService.ForgotPassword")
+	defer span.End()
+
+	user, err := s.userRepo.FindByEmail(ctx, req.Email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	// Invalidate previously issued tokens
+	if err := s.resetTokenRepo.DeleteByUserID(ctx, user.ID); err != nil {
+		return err
+	}
+
+	token, err := generateRandomToken(32)
+	if err != nil {
+		return err
+	}
+
+	resetToken := &domain.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(s.authCfg.PasswordResetExpiration),
+	}
+
+	if err := s.resetTokenRepo.Create(ctx, resetToken); err != nil {
+		return err
+	}
+
+	resetLink := fmt.Sprintf("%s?token=%s", s.authCfg.PasswordResetURL, url.QueryEscape(token))
+	msg, err := s.templates.Render(mailer.TemplatePasswordReset, []string{user.Email}, mailer.PasswordResetData{
+		Name:      user.Name,
+		ResetURL:  resetLink,
+		ExpiresIn: s.authCfg.PasswordResetExpiration.String(),
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		logger.Ctx(ctx, s.log).Error("Failed to send password reset email", zap.Uint("user_id", user.ID), zap.Error(err))
+		return errors.New("failed to send password reset email")
+	}
+
+	return nil
+}
+
+// ResetPassword sets a new password using a valid, unused reset token
+func (s *authService) ResetPassword(ctx context.Context, req *request.ResetPasswordRequest) error {
+	ctx, span := tracing.Start(ctx, "AuthService.ResetPassword")
+	defer span.End()
+
+	resetToken, err := s.resetTokenRepo.FindByTokenHash(ctx, hashToken(req.Token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+
+	if resetToken.IsUsed() || resetToken.IsExpired() {
+		return ErrInvalidResetToken
+	}
+
+	user, err := s.userRepo.FindByID(repository.WithSecrets(ctx), resetToken.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+
+	if err := s.passwords.Check(ctx, user, req.Password); err != nil {
+		return err
+	}
+
+	// Hash password
+	hashedPassword, err := s.hasher.Hash(req.Password)
+	if err != nil {
+		return err
+	}
+
+	user.Password = hashedPassword
+	changedAt := time.Now()
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		// Claim the token first, so that of concurrent requests using it
+		// only one sets a password
+		claimed, err := s.resetTokenRepo.MarkUsed(ctx, resetToken.ID)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			return ErrInvalidResetToken
+		}
+		if err := s.userRepo.Update(ctx, user); err != nil {
+			return err
+		}
+		if err := s.passwords.Record(ctx, user); err != nil {
+			return err
+		}
+		// Sign out every session that used the old password
+		return s.userRepo.IncrementTokenVersion(ctx, user.ID)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.notifier.Notify(ctx, passwordChangedNotice(user, changedAt))
+	return nil
+}
+
+// EnableMFA generates a new TOTP secret for the user. MFA stays inactive until ConfirmMFA succeeds.
+func (s *authService) EnableMFA(ctx context.Context, userID uint) (*response.MFASetupResponse, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.EnableMFA")
+	defer span.End()
+
+	if err := rejectImpersonation(ctx); err != nil {
+		return nil, err
+	}
+
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.MFAEnabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      s.authCfg.MFAIssuer,
+		AccountName: user.Email,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	user.MFASecret = key.Secret()
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return &response.MFASetupResponse{
+		Secret:          key.Secret(),
+		ProvisioningURI: key.URL(),
 	}, nil
 }
 
-// Login authenticates a user and returns a token
-func (s *authService) Login(req *request.LoginRequest) (*response.AuthResponse, error) {
-	// Find user by email
-	user, err := s.userRepo.FindByEmail(req.Email)
+// ConfirmMFA activates MFA after verifying a code from the enrolled authenticator
+func (s *authService) ConfirmMFA(ctx context.Context, userID uint, req *request.MFACodeRequest) (*response.MFARecoveryCodesResponse, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.ConfirmMFA")
+	defer span.End()
+
+	if err := rejectImpersonation(ctx); err != nil {
+		return nil, err
+	}
+
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.MFAEnabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	if user.MFASecret == "" {
+		return nil, ErrMFANotStarted
+	}
+
+	if !totp.Validate(req.Code, user.MFASecret) {
+		return nil, ErrInvalidMFACode
+	}
+
+	codes, err := s.regenerateRecoveryCodes(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	user.MFAEnabled = true
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+	s.responses.Invalidate(ctx, CacheTagUsers)
+
+	return &response.MFARecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// DisableMFA turns off MFA after verifying a current TOTP code
+func (s *authService) DisableMFA(ctx context.Context, userID uint, req *request.MFACodeRequest) error {
+	ctx, span := tracing.Start(ctx, "AuthService.DisableMFA")
+	defer span.End()
+
+	if err := rejectImpersonation(ctx); err != nil {
+		return err
+	}
+
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if !user.MFAEnabled {
+		return ErrMFANotEnabled
+	}
+
+	if !totp.Validate(req.Code, user.MFASecret) {
+		return ErrInvalidMFACode
+	}
+
+	user.MFAEnabled = false
+	user.MFASecret = ""
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+	s.responses.Invalidate(ctx, CacheTagUsers)
+
+	return s.recoveryRepo.DeleteByUserID(ctx, user.ID)
+}
+
+// VerifyMFA exchanges an MFA challenge token and a TOTP or recovery code for an
+// access token. A challenge is single-use: it is revoked once verified, or once
+// auth.mfa_max_attempts codes were rejected for it.
+func (s *authService) VerifyMFA(ctx context.Context, req *request.MFAVerifyRequest) (*response.AuthResponse, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.VerifyMFA")
+	defer span.End()
+
+	claims, err := s.jwtManager.ValidateMFAToken(req.MFAToken)
+	if err != nil {
+		return nil, ErrInvalidMFAToken
+	}
+	revoked, err := s.denylist.IsRevoked(ctx, claims.ID)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, ErrInvalidMFAToken
+	}
+
+	user, err := s.userRepo.FindByID(repository.WithSecrets(ctx), claims.UserID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("invalid credentials")
+			return nil, ErrInvalidMFAToken
 		}
 		return nil, err
 	}
 
-	// Verify password
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
-		return nil, errors.New("invalid credentials")
+	if !user.MFAEnabled {
+		return nil, ErrInvalidMFAToken
+	}
+
+	valid, err := s.checkMFACode(ctx, user, req.Code)
+	if err != nil {
+		return nil, err
+	}
+	if !valid {
+		s.activity.RecordLogin(ctx, &user.ID, user.Email, false, domain.LoginFailureInvalidMFACode)
+		s.failMFAChallenge(ctx, claims)
+		s.failLogin(ctx, user.Email)
+		return nil, ErrMFACodeRejected
+	}
+
+	if err := s.denylist.Revoke(ctx, claims.ID, claims.ExpiresAt.Time); err != nil {
+		return nil, err
+	}
+	if err := s.mfaAttempts.Reset(ctx, claims.ID); err != nil {
+		logger.Ctx(ctx, s.log).Error("Failed to reset MFA attempts", zap.Error(err))
+	}
+	s.resetLogin(ctx, user.Email)
+
+	s.activity.RecordLogin(ctx, &user.ID, user.Email, true, "")
+
+	return s.issueAuthResponse(ctx, user, claims.RememberMe)
+}
+
+// Logout revokes the given token so it can no longer be used
+func (s *authService) Logout(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	ctx, span := tracing.Start(ctx, "AuthService.Logout")
+	defer span.End()
+
+	if tokenID == "" {
+		return ErrTokenNotRevocable
+	}
+
+	return s.denylist.Revoke(ctx, tokenID, expiresAt)
+}
+
+// LogoutAll signs the user out everywhere by invalidating every token issued
+// to them so far, the one of the request included
+func (s *authService) LogoutAll(ctx context.Context, userID uint) error {
+	ctx, span := tracing.Start(ctx, "AuthService.LogoutAll")
+	defer span.End()
+
+	if err := rejectImpersonation(ctx); err != nil {
+		return err
+	}
+
+	if _, err := s.findUser(ctx, userID); err != nil {
+		return err
+	}
+	return s.userRepo.IncrementTokenVersion(ctx, userID)
+}
+
+// checkLoginAttempt refuses a login while the account is throttled, or without
+// a valid captcha once one is required. Errors of the guard let logins through,
+// so an outage of its store does not lock everyone out.
+func (s *authService) checkLoginAttempt(ctx context.Context, req *request.LoginRequest) error {
+	status, err := s.guard.Check(ctx, req.Email)
+	if err != nil {
+		logger.Ctx(ctx, s.log).Error("Failed to check login attempts", zap.Error(err))
+		return nil
+	}
+
+	if status.RetryAfter > 0 {
+		s.activity.RecordLogin(ctx, nil, req.Email, false, domain.LoginFailureThrottled)
+		return &LoginThrottledError{RetryAfter: status.RetryAfter}
+	}
+
+	if status.CaptchaRequired && s.captcha != nil {
+		valid := false
+		if req.CaptchaToken != "" {
+			if valid, err = s.captcha.Verify(ctx, req.CaptchaToken); err != nil {
+				return err
+			}
+		}
+		if !valid {
+			s.activity.RecordLogin(ctx, nil, req.Email, false, domain.LoginFailureCaptcha)
+			return ErrCaptchaRequired
+		}
+	}
+	return nil
+}
+
+// rehashPassword replaces the password hash of user when it was made with
+// other settings than the configured ones, now that the password is known.
+// Failures are logged: the old hash keeps working.
+func (s *authService) rehashPassword(ctx context.Context, user *domain.User, plain string) {
+	if !s.hasher.NeedsRehash(user.Password) {
+		return
+	}
+
+	hashedPassword, err := s.hasher.Hash(plain)
+	if err != nil {
+		logger.Ctx(ctx, s.log).Error("Failed to rehash password", zap.Uint("user_id", user.ID), zap.Error(err))
+		return
+	}
+	previous := user.Password
+	user.Password = hashedPassword
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		user.Password = previous
+		logger.Ctx(ctx, s.log).Error("Failed to rehash password", zap.Uint("user_id", user.ID), zap.Error(err))
+	}
+}
+
+// failLogin counts a failed login on the account of email
+func (s *authService) failLogin(ctx context.Context, email string) {
+	if _, err := s.guard.Fail(ctx, email); err != nil {
+		logger.Ctx(ctx, s.log).Error("Failed to count login attempt", zap.Error(err))
+	}
+}
+
+// resetLogin forgets the failed logins on the account of email
+func (s *authService) resetLogin(ctx context.Context, email string) {
+	if err := s.guard.Reset(ctx, email); err != nil {
+		logger.Ctx(ctx, s.log).Error("Failed to reset login attempts", zap.Error(err))
+	}
+}
+
+// checkMFACode reports whether code is the current TOTP code of user or one of
+// their unused recovery codes, which is then used up
+func (s *authService) checkMFACode(ctx context.Context, user *domain.User, code string) (bool, error) {
+	if totp.Validate(code, user.MFASecret) {
+		return true, nil
+	}
+
+	// Fall back to single-use recovery codes
+	recovery, err := s.recoveryRepo.FindUnused(ctx, user.ID, hashToken(code))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	// Of concurrent requests with the same code, only one uses it
+	return s.recoveryRepo.MarkUsed(ctx, recovery.ID)
+}
+
+// failMFAChallenge counts a code rejected for an MFA challenge, revoking the
+// challenge once it has had auth.mfa_max_attempts rejected codes
+func (s *authService) failMFAChallenge(ctx context.Context, claims *jwt.Claims) {
+	failures, err := s.mfaAttempts.Fail(ctx, claims.ID)
+	if err != nil {
+		logger.Ctx(ctx, s.log).Error("Failed to count MFA attempt", zap.Error(err))
+		return
+	}
+	if failures < s.authCfg.MFAMaxAttempts {
+		return
+	}
+	if err := s.denylist.Revoke(ctx, claims.ID, claims.ExpiresAt.Time); err != nil {
+		logger.Ctx(ctx, s.log).Error("Failed to revoke MFA challenge", zap.Error(err))
+	}
+}
+
+// findUser finds a user by ID, with the secrets the MFA flows check and
+// update, and maps a missing record to a user-facing error
+func (s *authService) findUser(ctx context.Context, userID uint) (*domain.User, error) {
+	user, err := s.userRepo.FindByID(repository.WithSecrets(ctx), userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// regenerateRecoveryCodes replaces the user's recovery codes and returns the plaintext values
+func (s *authService) regenerateRecoveryCodes(ctx context.Context, userID uint) ([]string, error) {
+	codes := make([]string, mfaRecoveryCodeCount)
+	hashes := make([]string, mfaRecoveryCodeCount)
+	for i := range codes {
+		code, err := generateRandomToken(5)
+		if err != nil {
+			return nil, err
+		}
+		codes[i] = code
+		hashes[i] = hashToken(code)
+	}
+
+	if err := s.recoveryRepo.ReplaceForUser(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// Impersonate issues a short-lived token of a user to an impersonator allowed
+// the users impersonate policy, for support. The token names the impersonator,
+// so the audit log attributes what is done with it to both. Admins cannot be
+// impersonated, nor can users with permissions the impersonator lacks, which
+// would let the impersonator borrow them, and impersonation tokens cannot
+// start another impersonation.
+func (s *authService) Impersonate(ctx context.Context, impersonatorID, userID uint) (*response.AuthResponse, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.Impersonate")
+	defer span.End()
+
+	if err := rejectImpersonation(ctx); err != nil {
+		return nil, err
+	}
+	if impersonatorID == userID {
+		return nil, ErrImpersonateSelf
+	}
+
+	impersonator, err := s.findUser(ctx, impersonatorID)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkImpersonation(ctx, impersonator, user); err != nil {
+		return nil, err
+	}
+
+	duration := s.authCfg.ImpersonationExpiration
+	token, claims, err := s.jwtManager.IssueImpersonationToken(user.ID, user.TenantID, user.Email, user.Role, user.TokenVersion, impersonatorID, duration)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.recordSession(ctx, user, claims); err != nil {
+		return nil, err
+	}
+
+	expiresAt := claims.ExpiresAt.Time
+	s.audit.Record(ctx, domain.AuditActionImpersonate, AuditEntityUser, user.ID, nil, map[string]interface{}{
+		"token_id":   claims.ID,
+		"expires_at": expiresAt,
+	})
+
+	return &response.AuthResponse{
+		User:      toUserResponse(user),
+		Token:     token,
+		ExpiresAt: &expiresAt,
+		ExpiresIn: int64(duration.Seconds()),
+	}, nil
+}
+
+// checkImpersonation refuses to let impersonator act as user when user is an
+// admin or has a permission the impersonator lacks: a policy of their role or
+// their own, or a permission of an assigned role. Admins may do anything, so
+// they may impersonate every other user.
+func (s *authService) checkImpersonation(ctx context.Context, impersonator, user *domain.User) error {
+	if user.Role == domain.RoleAdmin {
+		return ErrImpersonateAdmin
+	}
+	if impersonator.Role == domain.RoleAdmin {
+		return nil
+	}
+
+	for _, sub := range []string{authz.RoleSubject(user.Role), authz.UserSubject(user.ID)} {
+		policies, err := s.policies.GetImplicitPermissionsForUser(sub)
+		if err != nil {
+			return err
+		}
+		for _, policy := range policies {
+			// Wildcards of the policy are checked as is, so only as broad
+			// policies of the impersonator cover them
+			allowed, err := authz.Allowed(s.policies, impersonator.ID, impersonator.Role, policy[1], policy[2])
+			if err != nil {
+				return err
+			}
+			if !allowed {
+				return ErrImpersonateAbove
+			}
+		}
+	}
+
+	roles, err := s.roleRepo.FindByUser(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	for _, role := range roles {
+		for _, permission := range role.Permissions {
+			allowed, err := s.roleRepo.HasPermission(ctx, impersonator.ID, permission.Name)
+			if err != nil {
+				return err
+			}
+			if !allowed {
+				return ErrImpersonateAbove
+			}
+		}
+	}
+	return nil
+}
+
+// rejectImpersonation fails with ErrImpersonating when ctx carries an
+// impersonation, for the operations that could take over the account
+func rejectImpersonation(ctx context.Context) error {
+	if _, ok := reqctx.ImpersonatorID(ctx); ok {
+		return ErrImpersonating
+	}
+	return nil
+}
+
+// issueAuthResponse generates an access token, records it as a session of
+// the device of the request and builds the auth response. rememberMe asks for
+// the long remember me lifetime, granted when it is configured.
+func (s *authService) issueAuthResponse(ctx context.Context, user *domain.User, rememberMe bool) (*response.AuthResponse, error) {
+	duration, rememberMe, err := s.tokenLifetime(rememberMe)
+	if err != nil {
+		return nil, err
 	}
 
 	// Generate JWT token
-	token, err := s.generateToken(user)
+	token, claims, err := s.jwtManager.IssueToken(user.ID, user.TenantID, user.Email, user.Role, user.TokenVersion, duration)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := s.recordSession(ctx, user, claims); err != nil {
+		return nil, err
+	}
+
+	expiresAt := claims.ExpiresAt.Time
 	return &response.AuthResponse{
-		User: response.UserResponse{
-			ID:        user.ID,
-			Email:     user.Email,
-			Name:      user.Name,
-			CreatedAt: user.CreatedAt,
-			UpdatedAt: user.UpdatedAt,
-		},
-		Token: token,
+		User:       toUserResponse(user),
+		Token:      token,
+		ExpiresAt:  &expiresAt,
+		ExpiresIn:  int64(duration.Seconds()),
+		RememberMe: rememberMe,
 	}, nil
 }
 
-// generateToken generates a JWT token for the user
-func (s *authService) generateToken(user *domain.User) (string, error) {
+// recordSession records a token issued to user as a session of the device of the request
+func (s *authService) recordSession(ctx context.Context, user *domain.User, claims *jwt.Claims) error {
+	return s.sessions.Create(ctx, &domain.Session{
+		UserID:       user.ID,
+		TokenID:      claims.ID,
+		TokenVersion: user.TokenVersion,
+		IP:           reqctx.ClientIP(ctx),
+		UserAgent:    reqctx.UserAgent(ctx),
+		ExpiresAt:    claims.ExpiresAt.Time,
+	})
+}
+
+// tokenLifetime returns the lifetime of a new token and whether it is the
+// remember me one, which is only granted when jwt.remember_me_expiration is set
+func (s *authService) tokenLifetime(rememberMe bool) (time.Duration, bool, error) {
 	// Parse JWT expiration duration
 	duration, err := jwt.ParseDuration(s.jwtExpiry)
 	if err != nil {
-		return "", err
+		return 0, false, err
+	}
+	if !rememberMe {
+		return duration, false, nil
 	}
 
-	return jwt.GenerateToken(user.ID, user.Email, s.jwtSecret, duration)
+	remember, err := jwt.ParseDuration(s.rememberExpiry)
+	if err != nil {
+		return 0, false, err
+	}
+	if remember <= 0 {
+		return duration, false, nil
+	}
+	return remember, true, nil
 }
@@ -0,0 +1,325 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/dto/request"
+	"github.com/firdanbash/go-clean-boiler/internal/mocks"
+	"github.com/firdanbash/go-clean-boiler/internal/service"
+	"github.com/firdanbash/go-clean-boiler/internal/testutil"
+	"github.com/firdanbash/go-clean-boiler/pkg/bruteforce"
+	"github.com/firdanbash/go-clean-boiler/pkg/config"
+	"github.com/firdanbash/go-clean-boiler/pkg/jwt"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"github.com/firdanbash/go-clean-boiler/pkg/reqctx"
+	"github.com/pquerna/otp/totp"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+)
+
+// authServiceDeps holds the mocked dependencies of the auth service under test
+type authServiceDeps struct {
+	users         *mocks.MockUserRepository
+	resetTokens   *mocks.MockPasswordResetTokenRepository
+	recoveryCodes *mocks.MockMFARecoveryCodeRepository
+	revoked       *mocks.MockRevokedTokenRepository
+	sessions      *mocks.MockSessionRepository
+	roles         *mocks.MockRoleRepository
+	passwords     *mocks.MockPasswordHistoryService
+	activity      *mocks.MockActivityService
+	audit         *mocks.MockAuditService
+	policies      *mocks.MockPolicyEnforcer
+	guard         *mocks.MockLoginGuard
+	mfaAttempts   *mocks.MockMFAAttempts
+	notifier      *mocks.MockNotifier
+	jwt           *jwt.Manager
+}
+
+// newAuthService creates an auth service with the dependencies impersonation,
+// password resets and MFA use mocked; the others are left nil
+func newAuthService(t *testing.T) (service.AuthService, authServiceDeps) {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+	deps := authServiceDeps{
+		users:         mocks.NewMockUserRepository(ctrl),
+		resetTokens:   mocks.NewMockPasswordResetTokenRepository(ctrl),
+		recoveryCodes: mocks.NewMockMFARecoveryCodeRepository(ctrl),
+		revoked:       mocks.NewMockRevokedTokenRepository(ctrl),
+		sessions:      mocks.NewMockSessionRepository(ctrl),
+		roles:         mocks.NewMockRoleRepository(ctrl),
+		passwords:     mocks.NewMockPasswordHistoryService(ctrl),
+		activity:      mocks.NewMockActivityService(ctrl),
+		audit:         mocks.NewMockAuditService(ctrl),
+		policies:      mocks.NewMockPolicyEnforcer(ctrl),
+		guard:         mocks.NewMockLoginGuard(ctrl),
+		mfaAttempts:   mocks.NewMockMFAAttempts(ctrl),
+		notifier:      mocks.NewMockNotifier(ctrl),
+		jwt:           testutil.JWTManager(t),
+	}
+	svc := service.NewAuthService(
+		deps.users, deps.resetTokens, deps.recoveryCodes, deps.revoked, deps.sessions, deps.roles, testHasher, deps.passwords,
+		deps.activity, deps.audit, deps.policies, deps.guard, deps.mfaAttempts, nil, nil, nil, nil, testutil.Transactor(), nil, nil, nil, deps.notifier,
+		config.AuthConfig{ImpersonationExpiration: 15 * time.Minute, MFAMaxAttempts: 3},
+		deps.jwt, "1h", "0s", logger.Nop(),
+	)
+	return svc, deps
+}
+
+func TestAuthServiceImpersonate(t *testing.T) {
+	ctx := context.Background()
+	admin := testutil.NewUser(testutil.WithID(1), testutil.AsAdmin())
+	support := testutil.NewUser(testutil.WithID(1), testutil.WithRole("support"))
+
+	t.Run("issues a short-lived token naming the impersonator and audits it", func(t *testing.T) {
+		svc, deps := newAuthService(t)
+		user := testutil.NewUser(testutil.WithID(2))
+		deps.users.EXPECT().FindByID(gomock.Any(), uint(1)).Return(admin, nil)
+		deps.users.EXPECT().FindByID(gomock.Any(), uint(2)).Return(user, nil)
+		deps.sessions.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+		deps.audit.EXPECT().Record(gomock.Any(), domain.AuditActionImpersonate, service.AuditEntityUser, uint(2), nil, gomock.Any())
+
+		auth, err := svc.Impersonate(ctx, 1, 2)
+		if err != nil {
+			t.Fatalf("Impersonate() error = %v", err)
+		}
+		if auth.ExpiresIn != int64((15 * time.Minute).Seconds()) {
+			t.Errorf("ExpiresIn = %d, want %d", auth.ExpiresIn, int64((15 * time.Minute).Seconds()))
+		}
+
+		claims, err := deps.jwt.ValidateToken(auth.Token)
+		if err != nil {
+			t.Fatalf("ValidateToken() error = %v", err)
+		}
+		if claims.UserID != 2 || claims.ImpersonatorID != 1 {
+			t.Errorf("claims user %d impersonator %d, want 2 and 1", claims.UserID, claims.ImpersonatorID)
+		}
+	})
+
+	t.Run("lets a non-admin impersonate a user with no more permissions", func(t *testing.T) {
+		svc, deps := newAuthService(t)
+		deps.users.EXPECT().FindByID(gomock.Any(), uint(1)).Return(support, nil)
+		deps.users.EXPECT().FindByID(gomock.Any(), uint(2)).Return(testutil.NewUser(testutil.WithID(2)), nil)
+		deps.policies.EXPECT().GetImplicitPermissionsForUser("role:user").Return([][]string{{"role:user", "tasks", "read"}}, nil)
+		deps.policies.EXPECT().GetImplicitPermissionsForUser("user:2").Return(nil, nil)
+		deps.policies.EXPECT().Enforce("role:support", "tasks", "read").Return(true, nil)
+		deps.roles.EXPECT().FindByUser(gomock.Any(), uint(2)).Return([]domain.Role{
+			{Name: "reader", Permissions: []domain.Permission{{Name: "reports.read"}}},
+		}, nil)
+		deps.roles.EXPECT().HasPermission(gomock.Any(), uint(1), "reports.read").Return(true, nil)
+		deps.sessions.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+		deps.audit.EXPECT().Record(gomock.Any(), domain.AuditActionImpersonate, service.AuditEntityUser, uint(2), nil, gomock.Any())
+
+		if _, err := svc.Impersonate(ctx, 1, 2); err != nil {
+			t.Fatalf("Impersonate() error = %v", err)
+		}
+	})
+
+	t.Run("rejects a non-admin impersonating a user with more policies", func(t *testing.T) {
+		svc, deps := newAuthService(t)
+		deps.users.EXPECT().FindByID(gomock.Any(), uint(1)).Return(support, nil)
+		deps.users.EXPECT().FindByID(gomock.Any(), uint(2)).Return(testutil.NewUser(testutil.WithID(2), testutil.WithRole("manager")), nil)
+		deps.policies.EXPECT().GetImplicitPermissionsForUser("role:manager").Return([][]string{{"role:manager", "users", "delete"}}, nil)
+		deps.policies.EXPECT().Enforce("role:support", "users", "delete").Return(false, nil)
+		deps.policies.EXPECT().Enforce("user:1", "users", "delete").Return(false, nil)
+
+		if _, err := svc.Impersonate(ctx, 1, 2); !errors.Is(err, service.ErrImpersonateAbove) {
+			t.Fatalf("Impersonate() error = %v, want ErrImpersonateAbove", err)
+		}
+	})
+
+	t.Run("rejects a non-admin impersonating a user with more role permissions", func(t *testing.T) {
+		svc, deps := newAuthService(t)
+		deps.users.EXPECT().FindByID(gomock.Any(), uint(1)).Return(support, nil)
+		deps.users.EXPECT().FindByID(gomock.Any(), uint(2)).Return(testutil.NewUser(testutil.WithID(2)), nil)
+		deps.policies.EXPECT().GetImplicitPermissionsForUser(gomock.Any()).Return(nil, nil).Times(2)
+		deps.roles.EXPECT().FindByUser(gomock.Any(), uint(2)).Return([]domain.Role{
+			{Name: "billing", Permissions: []domain.Permission{{Name: "invoices.refund"}}},
+		}, nil)
+		deps.roles.EXPECT().HasPermission(gomock.Any(), uint(1), "invoices.refund").Return(false, nil)
+
+		if _, err := svc.Impersonate(ctx, 1, 2); !errors.Is(err, service.ErrImpersonateAbove) {
+			t.Fatalf("Impersonate() error = %v, want ErrImpersonateAbove", err)
+		}
+	})
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		userID uint
+		user   *domain.User
+		want   error
+	}{
+		{"rejects impersonating oneself", ctx, 1, nil, service.ErrImpersonateSelf},
+		{"rejects impersonating an admin", ctx, 2, testutil.NewUser(testutil.WithID(2), testutil.AsAdmin()), service.ErrImpersonateAdmin},
+		{"rejects impersonating while impersonating", reqctx.WithImpersonatorID(ctx, 3), 2, nil, service.ErrImpersonating},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, deps := newAuthService(t)
+			if tt.user != nil {
+				deps.users.EXPECT().FindByID(gomock.Any(), uint(1)).Return(support, nil)
+				deps.users.EXPECT().FindByID(gomock.Any(), tt.userID).Return(tt.user, nil)
+			}
+
+			if _, err := svc.Impersonate(tt.ctx, 1, tt.userID); !errors.Is(err, tt.want) {
+				t.Fatalf("Impersonate() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthServiceResetPassword(t *testing.T) {
+	ctx := context.Background()
+	req := &request.ResetPasswordRequest{Token: "token", Password: "N3w-Secret!x"}
+	resetToken := &domain.PasswordResetToken{ID: 5, UserID: 2, ExpiresAt: time.Now().Add(time.Hour)}
+
+	t.Run("sets the password and signs out every session", func(t *testing.T) {
+		svc, deps := newAuthService(t)
+		deps.resetTokens.EXPECT().FindByTokenHash(gomock.Any(), gomock.Any()).Return(resetToken, nil)
+		deps.users.EXPECT().FindByID(gomock.Any(), uint(2)).Return(testutil.NewUser(testutil.WithID(2)), nil)
+		deps.passwords.EXPECT().Check(gomock.Any(), gomock.Any(), req.Password).Return(nil)
+		gomock.InOrder(
+			deps.resetTokens.EXPECT().MarkUsed(gomock.Any(), uint(5)).Return(true, nil),
+			deps.users.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil),
+			deps.passwords.EXPECT().Record(gomock.Any(), gomock.Any()).Return(nil),
+			deps.users.EXPECT().IncrementTokenVersion(gomock.Any(), uint(2)).Return(nil),
+		)
+		deps.notifier.EXPECT().Notify(gomock.Any(), gomock.Any())
+
+		if err := svc.ResetPassword(ctx, req); err != nil {
+			t.Fatalf("ResetPassword() error = %v", err)
+		}
+	})
+
+	t.Run("rejects a token claimed by a concurrent request", func(t *testing.T) {
+		svc, deps := newAuthService(t)
+		deps.resetTokens.EXPECT().FindByTokenHash(gomock.Any(), gomock.Any()).Return(resetToken, nil)
+		deps.users.EXPECT().FindByID(gomock.Any(), uint(2)).Return(testutil.NewUser(testutil.WithID(2)), nil)
+		deps.passwords.EXPECT().Check(gomock.Any(), gomock.Any(), req.Password).Return(nil)
+		deps.resetTokens.EXPECT().MarkUsed(gomock.Any(), uint(5)).Return(false, nil)
+
+		if err := svc.ResetPassword(ctx, req); !errors.Is(err, service.ErrInvalidResetToken) {
+			t.Fatalf("ResetPassword() error = %v, want ErrInvalidResetToken", err)
+		}
+	})
+}
+
+func TestAuthServiceLoginWithMFA(t *testing.T) {
+	svc, deps := newAuthService(t)
+	user := testutil.NewUser(testutil.WithID(2), testutil.WithEmail("user@example.com"), testutil.WithPassword("Secret123!x"))
+	user.MFAEnabled = true
+
+	// Failed logins are kept until the second factor is verified
+	deps.guard.EXPECT().Check(gomock.Any(), "user@example.com").Return(bruteforce.Status{}, nil)
+	deps.users.EXPECT().FindByEmail(gomock.Any(), "user@example.com").Return(user, nil)
+
+	resp, err := svc.Login(context.Background(), &request.LoginRequest{Email: "user@example.com", Password: "Secret123!x"})
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	if !resp.MFARequired || resp.MFAToken == "" {
+		t.Fatalf("Login() = %+v, want an MFA challenge", resp)
+	}
+}
+
+func TestAuthServiceVerifyMFA(t *testing.T) {
+	ctx := context.Background()
+	secret := "JBSWY3DPEHPK3PXP"
+	mfaUser := func() *domain.User {
+		user := testutil.NewUser(testutil.WithID(2), testutil.WithEmail("user@example.com"))
+		user.MFAEnabled = true
+		user.MFASecret = secret
+		return user
+	}
+	// challenge returns an MFA challenge token of user 2 and its ID
+	challenge := func(t *testing.T, deps authServiceDeps) (string, string) {
+		t.Helper()
+		token, err := deps.jwt.GenerateMFAToken(2, 0, "user@example.com", false, 5*time.Minute)
+		if err != nil {
+			t.Fatalf("GenerateMFAToken() error = %v", err)
+		}
+		claims, err := deps.jwt.ValidateMFAToken(token)
+		if err != nil {
+			t.Fatalf("ValidateMFAToken() error = %v", err)
+		}
+		return token, claims.ID
+	}
+
+	t.Run("issues a token and uses up the challenge", func(t *testing.T) {
+		svc, deps := newAuthService(t)
+		token, id := challenge(t, deps)
+		code, err := totp.GenerateCode(secret, time.Now())
+		if err != nil {
+			t.Fatalf("GenerateCode() error = %v", err)
+		}
+		deps.revoked.EXPECT().IsRevoked(gomock.Any(), id).Return(false, nil)
+		deps.users.EXPECT().FindByID(gomock.Any(), uint(2)).Return(mfaUser(), nil)
+		deps.revoked.EXPECT().Revoke(gomock.Any(), id, gomock.Any()).Return(nil)
+		deps.mfaAttempts.EXPECT().Reset(gomock.Any(), id).Return(nil)
+		deps.guard.EXPECT().Reset(gomock.Any(), "user@example.com").Return(nil)
+		deps.activity.EXPECT().RecordLogin(gomock.Any(), gomock.Any(), gomock.Any(), true, "")
+		deps.sessions.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+
+		if _, err := svc.VerifyMFA(ctx, &request.MFAVerifyRequest{MFAToken: token, Code: code}); err != nil {
+			t.Fatalf("VerifyMFA() error = %v", err)
+		}
+	})
+
+	t.Run("rejects a used up challenge", func(t *testing.T) {
+		svc, deps := newAuthService(t)
+		token, id := challenge(t, deps)
+		deps.revoked.EXPECT().IsRevoked(gomock.Any(), id).Return(true, nil)
+
+		if _, err := svc.VerifyMFA(ctx, &request.MFAVerifyRequest{MFAToken: token, Code: "123456"}); !errors.Is(err, service.ErrInvalidMFAToken) {
+			t.Fatalf("VerifyMFA() error = %v, want ErrInvalidMFAToken", err)
+		}
+	})
+
+	tests := []struct {
+		name     string
+		failures int
+		revoke   bool
+	}{
+		{"counts a rejected code", 2, false},
+		{"revokes the challenge after too many rejected codes", 3, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, deps := newAuthService(t)
+			token, id := challenge(t, deps)
+			deps.revoked.EXPECT().IsRevoked(gomock.Any(), id).Return(false, nil)
+			deps.users.EXPECT().FindByID(gomock.Any(), uint(2)).Return(mfaUser(), nil)
+			deps.recoveryCodes.EXPECT().FindUnused(gomock.Any(), uint(2), gomock.Any()).Return(nil, gorm.ErrRecordNotFound)
+			deps.activity.EXPECT().RecordLogin(gomock.Any(), gomock.Any(), gomock.Any(), false, domain.LoginFailureInvalidMFACode)
+			deps.mfaAttempts.EXPECT().Fail(gomock.Any(), id).Return(tt.failures, nil)
+			deps.guard.EXPECT().Fail(gomock.Any(), "user@example.com").Return(bruteforce.Status{}, nil)
+			if tt.revoke {
+				deps.revoked.EXPECT().Revoke(gomock.Any(), id, gomock.Any()).Return(nil)
+			}
+
+			if _, err := svc.VerifyMFA(ctx, &request.MFAVerifyRequest{MFAToken: token, Code: "not-a-code"}); !errors.Is(err, service.ErrMFACodeRejected) {
+				t.Fatalf("VerifyMFA() error = %v, want ErrMFACodeRejected", err)
+			}
+		})
+	}
+
+	t.Run("rejects a recovery code used by a concurrent request", func(t *testing.T) {
+		svc, deps := newAuthService(t)
+		token, id := challenge(t, deps)
+		deps.revoked.EXPECT().IsRevoked(gomock.Any(), id).Return(false, nil)
+		deps.users.EXPECT().FindByID(gomock.Any(), uint(2)).Return(mfaUser(), nil)
+		deps.recoveryCodes.EXPECT().FindUnused(gomock.Any(), uint(2), gomock.Any()).Return(&domain.MFARecoveryCode{ID: 9, UserID: 2}, nil)
+		deps.recoveryCodes.EXPECT().MarkUsed(gomock.Any(), uint(9)).Return(false, nil)
+		deps.activity.EXPECT().RecordLogin(gomock.Any(), gomock.Any(), gomock.Any(), false, domain.LoginFailureInvalidMFACode)
+		deps.mfaAttempts.EXPECT().Fail(gomock.Any(), id).Return(1, nil)
+		deps.guard.EXPECT().Fail(gomock.Any(), "user@example.com").Return(bruteforce.Status{}, nil)
+
+		if _, err := svc.VerifyMFA(ctx, &request.MFAVerifyRequest{MFAToken: token, Code: "recovery-code"}); !errors.Is(err, service.ErrMFACodeRejected) {
+			t.Fatalf("VerifyMFA() error = %v, want ErrMFACodeRejected", err)
+		}
+	})
+}
@@ -0,0 +1,12 @@
+package service
+
+import "context"
+
+// CacheTagUsers tags the cached responses holding users
+const CacheTagUsers = "users"
+
+// CacheInvalidator drops the cached responses of the given tags once a change
+// is stored. Invalidate must not fail the change.
+type CacheInvalidator interface {
+	Invalidate(ctx context.Context, tags ...string)
+}
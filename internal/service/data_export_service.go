@@ -0,0 +1,397 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/dto/request"
+	"github.com/firdanbash/go-clean-boiler/internal/dto/response"
+	"github.com/firdanbash/go-clean-boiler/internal/event"
+	"github.com/firdanbash/go-clean-boiler/internal/notification"
+	"github.com/firdanbash/go-clean-boiler/internal/repository"
+	"github.com/firdanbash/go-clean-boiler/pkg/jwt"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"github.com/firdanbash/go-clean-boiler/pkg/mailer"
+	"github.com/firdanbash/go-clean-boiler/pkg/reqctx"
+	"github.com/firdanbash/go-clean-boiler/pkg/storage"
+	"github.com/firdanbash/go-clean-boiler/pkg/tracing"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// dataExportFileName is the name archives are downloaded as
+const dataExportFileName = "personal-data.zip"
+
+// dataExportTimeout is how long an export may stay pending before it is
+// considered lost, e.g. to a restart, and a new one is started on request
+const dataExportTimeout = time.Hour
+
+// dataExportBatchSize is the page size personal data is read in
+const dataExportBatchSize = 500
+
+// DataExportDownload is an archive streamed to the user
+type DataExportDownload struct {
+	Content io.ReadCloser
+	Name    string
+	Size    int64
+}
+
+type DataExportService interface {
+	Export(ctx context.Context, userID uint, downloadPath string) (*response.DataExportResponse, error)
+	Build(ctx context.Context, userID, exportID uint) error
+	Open(ctx context.Context, token string) (*DataExportDownload, error)
+	PurgeExpired(ctx context.Context) (int, error)
+}
+
+type dataExportService struct {
+	repo          repository.DataExportRepository
+	userRepo      repository.UserRepository
+	activity      ActivityService
+	audit         AuditService
+	storage       storage.Storage
+	dispatcher    event.Dispatcher
+	notifier      notification.Notifier
+	jwtManager    *jwt.Manager
+	expiry        time.Duration
+	presignExpiry time.Duration
+	log           logger.Logger
+}
+
+// NewDataExportService creates a new data export service. Archives are kept
+// for expiry; download URLs last presignExpiry at most.
+func NewDataExportService(
+	repo repository.DataExportRepository,
+	userRepo repository.UserRepository,
+	activity ActivityService,
+	audit AuditService,
+	store storage.Storage,
+	dispatcher event.Dispatcher,
+	notifier notification.Notifier,
+	jwtManager *jwt.Manager,
+	expiry time.Duration,
+	presignExpiry time.Duration,
+	log logger.Logger,
+) DataExportService {
+	return &dataExportService{
+		repo:          repo,
+		userRepo:      userRepo,
+		activity:      activity,
+		audit:         audit,
+		storage:       store,
+		dispatcher:    dispatcher,
+		notifier:      notifier,
+		jwtManager:    jwtManager,
+		expiry:        expiry,
+		presignExpiry: presignExpiry,
+		log:           log,
+	}
+}
+
+// Export returns the latest export of the user, starting a new one when there
+// is none in progress or ready to download. The archive is built in the
+// background. Storage backends that cannot presign URLs stream the archive
+// from downloadPath, the download route on the API version of the request.
+func (s *dataExportService) Export(ctx context.Context, userID uint, downloadPath string) (*response.DataExportResponse, error) {
+	ctx, span := tracing.Start(ctx, "DataExportService.Export")
+	defer span.End()
+
+	now := time.Now()
+	latest, err := s.repo.FindLatest(ctx, userID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if err == nil && dataExportUsable(latest, now) {
+		return s.toResponse(ctx, latest, now, downloadPath)
+	}
+
+	export := &domain.DataExport{UserID: userID, Status: domain.DataExportPending}
+	if err := s.repo.Create(ctx, export); err != nil {
+		return nil, err
+	}
+
+	s.dispatcher.Dispatch(ctx, event.DataExportRequested{ExportID: export.ID, UserID: userID})
+
+	return s.toResponse(ctx, export, now, downloadPath)
+}
+
+// Build assembles the personal data of the user into the archive of a pending
+// export and notifies the user once it is ready
+func (s *dataExportService) Build(ctx context.Context, userID, exportID uint) error {
+	ctx, span := tracing.Start(ctx, "DataExportService.Build")
+	defer span.End()
+
+	export, err := s.repo.FindByID(ctx, userID, exportID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrDataExportNotFound
+		}
+		return err
+	}
+	if export.Status != domain.DataExportPending {
+		return nil
+	}
+
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return s.fail(ctx, export, err)
+	}
+
+	archive, err := s.archive(ctx, user)
+	if err != nil {
+		return s.fail(ctx, export, err)
+	}
+
+	suffix, err := generateRandomToken(16)
+	if err != nil {
+		return s.fail(ctx, export, err)
+	}
+	key := fmt.Sprintf("exports/%d/%s.zip", userID, suffix)
+	if err := s.storage.Put(ctx, key, bytes.NewReader(archive), int64(len(archive)), "application/zip"); err != nil {
+		return s.fail(ctx, export, err)
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(s.expiry)
+	export.Status = domain.DataExportReady
+	export.Key = key
+	export.Size = int64(len(archive))
+	export.CompletedAt = &now
+	export.ExpiresAt = &expiresAt
+	if err := s.repo.Update(ctx, export); err != nil {
+		if delErr := s.storage.Delete(ctx, key); delErr != nil {
+			logger.Ctx(ctx, s.log).Warn("Failed to delete orphaned data export", zap.String("key", key), zap.Error(delErr))
+		}
+		return err
+	}
+
+	s.notifier.Notify(ctx, notification.Notification{
+		Type:   notification.TypeDataExportReady,
+		UserID: user.ID,
+		Title:  "Your data export is ready",
+		Body:   "The export of your personal data is ready to download until " + expiresAt.UTC().Format(time.RFC1123) + ".",
+		Data:   mailer.DataExportReadyData{Name: user.Name, ExpiresAt: expiresAt.UTC().Format(time.RFC1123)},
+	})
+
+	return nil
+}
+
+// Open opens the archive of a ready export for the holder of a download token
+func (s *dataExportService) Open(ctx context.Context, token string) (*DataExportDownload, error) {
+	ctx, span := tracing.Start(ctx, "DataExportService.Open")
+	defer span.End()
+
+	claims, err := s.jwtManager.ValidateDownloadToken(token)
+	if err != nil {
+		return nil, ErrDataExportNotFound
+	}
+	exportID, err := strconv.ParseUint(claims.Subject, 10, 0)
+	if err != nil {
+		return nil, ErrDataExportNotFound
+	}
+
+	export, err := s.repo.FindByID(ctx, claims.UserID, uint(exportID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDataExportNotFound
+		}
+		return nil, err
+	}
+	if export.Status != domain.DataExportReady || !export.ExpiresAt.After(time.Now()) {
+		return nil, ErrDataExportNotFound
+	}
+
+	content, err := s.storage.Get(ctx, export.Key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, ErrDataExportNotFound
+		}
+		return nil, err
+	}
+
+	return &DataExportDownload{Content: content, Name: dataExportFileName, Size: export.Size}, nil
+}
+
+// PurgeExpired deletes expired exports and their archives, returning how many were removed
+func (s *dataExportService) PurgeExpired(ctx context.Context) (int, error) {
+	removed := 0
+	for {
+		exports, err := s.repo.FindExpired(ctx, time.Now(), dataExportBatchSize)
+		if err != nil {
+			return removed, err
+		}
+
+		for _, export := range exports {
+			if export.Key != "" {
+				if err := s.storage.Delete(ctx, export.Key); err != nil && !errors.Is(err, storage.ErrNotFound) {
+					return removed, err
+				}
+			}
+			if err := s.repo.Delete(ctx, export.ID); err != nil {
+				return removed, err
+			}
+			removed++
+		}
+
+		if len(exports) < dataExportBatchSize {
+			return removed, nil
+		}
+	}
+}
+
+// fail marks an export as failed, so the next request starts over, and returns err.
+// Failed exports expire right away and are purged with the others.
+func (s *dataExportService) fail(ctx context.Context, export *domain.DataExport, err error) error {
+	now := time.Now()
+	export.Status = domain.DataExportFailed
+	export.Error = err.Error()
+	export.CompletedAt = &now
+	export.ExpiresAt = &now
+	if updateErr := s.repo.Update(ctx, export); updateErr != nil {
+		logger.Ctx(ctx, s.log).Error("Failed to mark data export as failed", zap.Uint("export_id", export.ID), zap.Error(updateErr))
+	}
+	return err
+}
+
+// archive builds the ZIP archive of the personal data of a user: the profile,
+// the login activity and the audit entries the user made or that concern them
+func (s *dataExportService) archive(ctx context.Context, user *domain.User) ([]byte, error) {
+	activity, err := s.loginActivity(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	auditLog, err := s.auditLog(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{"profile.json", toUserResponse(user)},
+		{"login_activity.json", activity},
+		{"audit_log.json", auditLog},
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, file := range files {
+		w, err := zw.Create(file.name)
+		if err != nil {
+			return nil, err
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(file.data); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// loginActivity lists every login event of a user
+func (s *dataExportService) loginActivity(ctx context.Context, userID uint) ([]response.LoginEventResponse, error) {
+	events := []response.LoginEventResponse{}
+	for page := 1; ; page++ {
+		batch, _, err := s.activity.ListForUser(ctx, userID, &request.ListActivityRequest{Page: page, PerPage: dataExportBatchSize})
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, batch...)
+		if len(batch) < dataExportBatchSize {
+			return events, nil
+		}
+	}
+}
+
+// auditLog lists the audit entries made by a user or about their account,
+// each entry once
+func (s *dataExportService) auditLog(ctx context.Context, userID uint) ([]response.AuditLogResponse, error) {
+	filters := []request.ListAuditLogsRequest{
+		{ActorID: userID},
+		{EntityType: AuditEntityUser, EntityID: userID},
+	}
+
+	entries := []response.AuditLogResponse{}
+	seen := make(map[uint]bool)
+	for _, filter := range filters {
+		filter.PerPage = dataExportBatchSize
+		for filter.Page = 1; ; filter.Page++ {
+			batch, _, err := s.audit.List(ctx, &filter)
+			if err != nil {
+				return nil, err
+			}
+			for _, entry := range batch {
+				if !seen[entry.ID] {
+					seen[entry.ID] = true
+					entries = append(entries, entry)
+				}
+			}
+			if len(batch) < dataExportBatchSize {
+				break
+			}
+		}
+	}
+
+	return entries, nil
+}
+
+// toResponse maps an export to its response, with a download URL when ready
+func (s *dataExportService) toResponse(ctx context.Context, export *domain.DataExport, now time.Time, downloadPath string) (*response.DataExportResponse, error) {
+	resp := &response.DataExportResponse{
+		ID:          export.ID,
+		Status:      export.Status,
+		Size:        export.Size,
+		CreatedAt:   export.CreatedAt,
+		CompletedAt: export.CompletedAt,
+		ExpiresAt:   export.ExpiresAt,
+	}
+	if export.Status != domain.DataExportReady {
+		return resp, nil
+	}
+
+	expiry := min(s.presignExpiry, export.ExpiresAt.Sub(now))
+	if presigner, ok := s.storage.(storage.Presigner); ok {
+		downloadURL, err := presigner.PresignGet(ctx, export.Key, dataExportFileName, expiry)
+		if err != nil {
+			return nil, err
+		}
+		resp.DownloadURL = downloadURL
+	} else {
+		tenantID, _ := reqctx.TenantID(ctx)
+		token, err := s.jwtManager.GenerateDownloadToken(export.UserID, tenantID, strconv.FormatUint(uint64(export.ID), 10), expiry)
+		if err != nil {
+			return nil, err
+		}
+		resp.DownloadURL = downloadPath + "?token=" + url.QueryEscape(token)
+	}
+	downloadExpiresAt := now.Add(expiry)
+	resp.DownloadExpiresAt = &downloadExpiresAt
+
+	return resp, nil
+}
+
+// dataExportUsable reports whether an export is still being built or can be
+// downloaded, so no new one needs to be started
+func dataExportUsable(export *domain.DataExport, now time.Time) bool {
+	switch export.Status {
+	case domain.DataExportPending:
+		return now.Sub(export.CreatedAt) < dataExportTimeout
+	case domain.DataExportReady:
+		return export.ExpiresAt != nil && export.ExpiresAt.After(now)
+	}
+	return false
+}
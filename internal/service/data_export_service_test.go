@@ -0,0 +1,272 @@
+package service_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/dto/response"
+	"github.com/firdanbash/go-clean-boiler/internal/event"
+	"github.com/firdanbash/go-clean-boiler/internal/mocks"
+	"github.com/firdanbash/go-clean-boiler/internal/notification"
+	"github.com/firdanbash/go-clean-boiler/internal/service"
+	"github.com/firdanbash/go-clean-boiler/internal/testutil"
+	"github.com/firdanbash/go-clean-boiler/pkg/jwt"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"github.com/firdanbash/go-clean-boiler/pkg/storage"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+)
+
+// dataExportServiceDeps holds the dependencies of the data export service under test
+type dataExportServiceDeps struct {
+	repo     *mocks.MockDataExportRepository
+	users    *mocks.MockUserRepository
+	activity *mocks.MockActivityService
+	audit    *mocks.MockAuditService
+	storage  *memStorage
+	bus      *mocks.MockDispatcher
+	notifier *mocks.MockNotifier
+	jwt      *jwt.Manager
+}
+
+func newDataExportService(t *testing.T, store storage.Storage) (service.DataExportService, dataExportServiceDeps) {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+	deps := dataExportServiceDeps{
+		repo:     mocks.NewMockDataExportRepository(ctrl),
+		users:    mocks.NewMockUserRepository(ctrl),
+		activity: mocks.NewMockActivityService(ctrl),
+		audit:    mocks.NewMockAuditService(ctrl),
+		storage:  newMemStorage(),
+		bus:      mocks.NewMockDispatcher(ctrl),
+		notifier: mocks.NewMockNotifier(ctrl),
+		jwt:      testutil.JWTManager(t),
+	}
+	if store == nil {
+		store = deps.storage
+	}
+	svc := service.NewDataExportService(deps.repo, deps.users, deps.activity, deps.audit, store, deps.bus, deps.notifier, deps.jwt, 72*time.Hour, 15*time.Minute, logger.Nop())
+	return svc, deps
+}
+
+func TestDataExportServiceExport(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("starts an export in the background when there is none", func(t *testing.T) {
+		svc, deps := newDataExportService(t, nil)
+		deps.repo.EXPECT().FindLatest(gomock.Any(), uint(1)).Return(nil, gorm.ErrRecordNotFound)
+		deps.repo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, export *domain.DataExport) error {
+			export.ID = 9
+			return nil
+		})
+		deps.bus.EXPECT().Dispatch(gomock.Any(), event.DataExportRequested{ExportID: 9, UserID: 1})
+
+		export, err := svc.Export(ctx, 1, "/api/v2/data-exports/download")
+		if err != nil {
+			t.Fatalf("Export() error = %v", err)
+		}
+		if export.Status != domain.DataExportPending || export.DownloadURL != "" {
+			t.Errorf("Export() = %+v, want a pending export without download URL", export)
+		}
+	})
+
+	t.Run("returns the export in progress", func(t *testing.T) {
+		svc, deps := newDataExportService(t, nil)
+		deps.repo.EXPECT().FindLatest(gomock.Any(), uint(1)).Return(&domain.DataExport{
+			ID: 9, UserID: 1, Status: domain.DataExportPending, CreatedAt: time.Now().Add(-time.Minute),
+		}, nil)
+
+		export, err := svc.Export(ctx, 1, "/api/v2/data-exports/download")
+		if err != nil {
+			t.Fatalf("Export() error = %v", err)
+		}
+		if export.ID != 9 {
+			t.Errorf("ID = %d, want 9", export.ID)
+		}
+	})
+
+	t.Run("starts over when the latest export failed", func(t *testing.T) {
+		svc, deps := newDataExportService(t, nil)
+		deps.repo.EXPECT().FindLatest(gomock.Any(), uint(1)).Return(&domain.DataExport{ID: 9, UserID: 1, Status: domain.DataExportFailed}, nil)
+		deps.repo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+		deps.bus.EXPECT().Dispatch(gomock.Any(), gomock.Any())
+
+		if _, err := svc.Export(ctx, 1, "/api/v2/data-exports/download"); err != nil {
+			t.Fatalf("Export() error = %v", err)
+		}
+	})
+
+	t.Run("returns a download token URL that opens the ready archive", func(t *testing.T) {
+		svc, deps := newDataExportService(t, nil)
+		expiresAt := time.Now().Add(time.Hour)
+		ready := &domain.DataExport{ID: 9, UserID: 1, Status: domain.DataExportReady, Key: "exports/1/abc.zip", Size: 3, ExpiresAt: &expiresAt}
+		deps.storage.objects[ready.Key] = []byte("zip")
+		deps.repo.EXPECT().FindLatest(gomock.Any(), uint(1)).Return(ready, nil)
+		deps.repo.EXPECT().FindByID(gomock.Any(), uint(1), uint(9)).Return(ready, nil)
+
+		export, err := svc.Export(ctx, 1, "/api/v2/data-exports/download")
+		if err != nil {
+			t.Fatalf("Export() error = %v", err)
+		}
+		u, err := url.Parse(export.DownloadURL)
+		if err != nil || u.Path != "/api/v2/data-exports/download" {
+			t.Fatalf("DownloadURL = %q, want the download route", export.DownloadURL)
+		}
+
+		download, err := svc.Open(ctx, u.Query().Get("token"))
+		if err != nil {
+			t.Fatalf("Open() error = %v", err)
+		}
+		defer download.Content.Close()
+		content, _ := io.ReadAll(download.Content)
+		if string(content) != "zip" {
+			t.Errorf("content = %q, want %q", content, "zip")
+		}
+	})
+
+	t.Run("presigns the download URL when the storage supports it", func(t *testing.T) {
+		svc, deps := newDataExportService(t, presignStorage{newMemStorage()})
+		expiresAt := time.Now().Add(time.Hour)
+		deps.repo.EXPECT().FindLatest(gomock.Any(), uint(1)).Return(&domain.DataExport{
+			ID: 9, UserID: 1, Status: domain.DataExportReady, Key: "exports/1/abc.zip", ExpiresAt: &expiresAt,
+		}, nil)
+
+		export, err := svc.Export(ctx, 1, "/api/v2/data-exports/download")
+		if err != nil {
+			t.Fatalf("Export() error = %v", err)
+		}
+		if !strings.HasPrefix(export.DownloadURL, "https://bucket.example.com/exports/1/abc.zip") {
+			t.Errorf("DownloadURL = %q, want a presigned URL", export.DownloadURL)
+		}
+	})
+}
+
+func TestDataExportServiceBuild(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("archives the personal data and notifies the user", func(t *testing.T) {
+		svc, deps := newDataExportService(t, nil)
+		user := testutil.NewUser(testutil.WithID(1))
+		deps.repo.EXPECT().FindByID(gomock.Any(), uint(1), uint(9)).Return(&domain.DataExport{ID: 9, UserID: 1, Status: domain.DataExportPending}, nil)
+		deps.users.EXPECT().FindByID(gomock.Any(), uint(1)).Return(user, nil)
+		deps.activity.EXPECT().ListForUser(gomock.Any(), uint(1), gomock.Any()).Return([]response.LoginEventResponse{{ID: 1, Success: true}}, int64(1), nil)
+		// The entry made by the user about their own account is exported once
+		deps.audit.EXPECT().List(gomock.Any(), gomock.Any()).Return([]response.AuditLogResponse{{ID: 4}, {ID: 5}}, int64(2), nil)
+		deps.audit.EXPECT().List(gomock.Any(), gomock.Any()).Return([]response.AuditLogResponse{{ID: 5}, {ID: 6}}, int64(2), nil)
+		var ready *domain.DataExport
+		deps.repo.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, export *domain.DataExport) error {
+			ready = export
+			return nil
+		})
+		deps.notifier.EXPECT().Notify(gomock.Any(), gomock.Cond(func(x interface{}) bool {
+			n, ok := x.(notification.Notification)
+			return ok && n.Type == notification.TypeDataExportReady && n.UserID == 1
+		}))
+
+		if err := svc.Build(ctx, 1, 9); err != nil {
+			t.Fatalf("Build() error = %v", err)
+		}
+		if ready.Status != domain.DataExportReady || ready.ExpiresAt == nil {
+			t.Fatalf("export = %+v, want ready with an expiry", ready)
+		}
+
+		archive := deps.storage.objects[ready.Key]
+		zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+		if err != nil {
+			t.Fatalf("archive is not a zip: %v", err)
+		}
+		files := map[string]string{}
+		for _, f := range zr.File {
+			r, _ := f.Open()
+			content, _ := io.ReadAll(r)
+			r.Close()
+			files[f.Name] = string(content)
+		}
+		if !strings.Contains(files["profile.json"], user.Email) {
+			t.Errorf("profile.json = %s, want the email of the user", files["profile.json"])
+		}
+		if n := strings.Count(files["audit_log.json"], `"id"`); n != 3 {
+			t.Errorf("audit_log.json has %d entries, want 3", n)
+		}
+		if _, ok := files["login_activity.json"]; !ok {
+			t.Error("archive has no login_activity.json")
+		}
+	})
+
+	t.Run("marks the export failed when the data cannot be read", func(t *testing.T) {
+		svc, deps := newDataExportService(t, nil)
+		deps.repo.EXPECT().FindByID(gomock.Any(), uint(1), uint(9)).Return(&domain.DataExport{ID: 9, UserID: 1, Status: domain.DataExportPending}, nil)
+		deps.users.EXPECT().FindByID(gomock.Any(), uint(1)).Return(testutil.NewUser(testutil.WithID(1)), nil)
+		deps.activity.EXPECT().ListForUser(gomock.Any(), uint(1), gomock.Any()).Return(nil, int64(0), errors.New("db down"))
+		deps.repo.EXPECT().Update(gomock.Any(), gomock.Cond(func(x interface{}) bool {
+			export, ok := x.(*domain.DataExport)
+			return ok && export.Status == domain.DataExportFailed
+		})).Return(nil)
+
+		if err := svc.Build(ctx, 1, 9); err == nil {
+			t.Fatal("Build() error = nil, want the read error")
+		}
+	})
+}
+
+func TestDataExportServiceOpen(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("rejects tokens that are not download tokens", func(t *testing.T) {
+		svc, deps := newDataExportService(t, nil)
+		token, err := deps.jwt.GenerateToken(1, 0, "user@example.com", "user", 0, time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := svc.Open(ctx, token); !errors.Is(err, service.ErrDataExportNotFound) {
+			t.Errorf("Open() error = %v, want %v", err, service.ErrDataExportNotFound)
+		}
+	})
+
+	t.Run("rejects expired exports", func(t *testing.T) {
+		svc, deps := newDataExportService(t, nil)
+		expiredAt := time.Now().Add(-time.Minute)
+		deps.repo.EXPECT().FindByID(gomock.Any(), uint(1), uint(9)).Return(&domain.DataExport{
+			ID: 9, UserID: 1, Status: domain.DataExportReady, Key: "exports/1/abc.zip", ExpiresAt: &expiredAt,
+		}, nil)
+		token, err := deps.jwt.GenerateDownloadToken(1, 0, "9", time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := svc.Open(ctx, token); !errors.Is(err, service.ErrDataExportNotFound) {
+			t.Errorf("Open() error = %v, want %v", err, service.ErrDataExportNotFound)
+		}
+	})
+}
+
+func TestDataExportServicePurgeExpired(t *testing.T) {
+	svc, deps := newDataExportService(t, nil)
+	deps.storage.objects["exports/1/abc.zip"] = []byte("zip")
+	deps.repo.EXPECT().FindExpired(gomock.Any(), gomock.Any(), gomock.Any()).Return([]domain.DataExport{
+		{ID: 9, Key: "exports/1/abc.zip"},
+		{ID: 10, Status: domain.DataExportFailed},
+	}, nil)
+	deps.repo.EXPECT().Delete(gomock.Any(), uint(9)).Return(nil)
+	deps.repo.EXPECT().Delete(gomock.Any(), uint(10)).Return(nil)
+
+	removed, err := svc.PurgeExpired(context.Background())
+	if err != nil {
+		t.Fatalf("PurgeExpired() error = %v", err)
+	}
+	if removed != 2 {
+		t.Errorf("PurgeExpired() = %d, want 2", removed)
+	}
+	if _, ok := deps.storage.objects["exports/1/abc.zip"]; ok {
+		t.Error("archive was not deleted")
+	}
+}
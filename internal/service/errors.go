@@ -0,0 +1,61 @@
+package service
+
+import "github.com/firdanbash/go-clean-boiler/pkg/apperror"
+
+// Errors returned by the services. Handlers map their kind to a status code;
+// anything else is treated as an internal error.
+var (
+	ErrUserNotFound         = apperror.NotFound("user not found").WithCode(apperror.CodeUserNotFound)
+	ErrDeletedUserNotFound  = apperror.NotFound("deleted user not found").WithCode(apperror.CodeUserDeletedNotFound)
+	ErrAvatarNotFound       = apperror.NotFound("avatar not found").WithCode(apperror.CodeAvatarNotFound)
+	ErrFileNotFound         = apperror.NotFound("file not found").WithCode(apperror.CodeFileNotFound)
+	ErrEmailExists          = apperror.Conflict("email already exists").WithCode(apperror.CodeUserEmailTaken)
+	ErrInvalidCredentials   = apperror.Unauthorized("invalid credentials").WithCode(apperror.CodeAuthInvalidCredentials)
+	ErrTooManyLoginAttempts = apperror.TooManyRequests("too many failed login attempts, please try again later").WithCode(apperror.CodeAuthTooManyAttempts)
+	ErrCaptchaRequired      = apperror.Validation("a valid captcha is required").WithCode(apperror.CodeAuthCaptchaRequired)
+	ErrWrongPassword        = apperror.Validation("current password is incorrect").WithCode(apperror.CodePasswordIncorrect)
+	ErrPasswordUnchanged    = apperror.Validation("new password must be different from the current password").WithCode(apperror.CodePasswordUnchanged)
+	ErrPasswordReused       = apperror.Validation("new password must not be one of your recent passwords").WithCode(apperror.CodePasswordReused)
+	ErrInvalidAvatarType    = apperror.Validation("avatar must be a JPEG, PNG or GIF image").WithCode(apperror.CodeAvatarInvalidType)
+	ErrInvalidFileType      = apperror.Validation("file type is not allowed").WithCode(apperror.CodeFileTypeNotAllowed)
+	ErrEmptyFile            = apperror.Validation("file is empty").WithCode(apperror.CodeFileEmpty)
+	ErrPresignUnsupported   = apperror.Validation("presigned URLs are not supported by the storage driver").WithCode(apperror.CodeFilePresignUnsupported)
+	ErrUploadNotFound       = apperror.NotFound("upload not found").WithCode(apperror.CodeUploadNotFound)
+	ErrUploadCompleted      = apperror.Conflict("upload is already completed").WithCode(apperror.CodeUploadCompleted)
+	ErrInvalidExportField   = apperror.Validation("invalid export field").WithCode(apperror.CodeUserExportFieldInvalid)
+	ErrInvalidResetToken    = apperror.Validation("invalid or expired reset token").WithCode(apperror.CodeAuthResetTokenInvalid)
+	ErrMFAAlreadyEnabled    = apperror.Conflict("mfa is already enabled").WithCode(apperror.CodeMFAAlreadyEnabled)
+	ErrMFANotStarted        = apperror.Validation("mfa enrollment has not been started").WithCode(apperror.CodeMFANotStarted)
+	ErrMFANotEnabled        = apperror.Validation("mfa is not enabled").WithCode(apperror.CodeMFANotEnabled)
+	ErrInvalidMFACode       = apperror.Validation("invalid mfa code").WithCode(apperror.CodeMFACodeInvalid)
+	ErrMFACodeRejected      = apperror.Unauthorized("invalid mfa code").WithCode(apperror.CodeMFACodeInvalid)
+	ErrInvalidMFAToken      = apperror.Unauthorized("invalid or expired mfa token").WithCode(apperror.CodeMFATokenInvalid)
+	ErrTokenNotRevocable    = apperror.Validation("token cannot be revoked").WithCode(apperror.CodeAuthTokenNotRevocable)
+	ErrSessionNotFound      = apperror.NotFound("session not found").WithCode(apperror.CodeSessionNotFound)
+	ErrImpersonateSelf      = apperror.Validation("you cannot impersonate yourself").WithCode(apperror.CodeAuthImpersonateSelf)
+	ErrImpersonateAdmin     = apperror.Forbidden("administrators cannot be impersonated").WithCode(apperror.CodeAuthImpersonateAdmin)
+	ErrImpersonateAbove     = apperror.Forbidden("users with permissions you lack cannot be impersonated").WithCode(apperror.CodeAuthImpersonateAbove)
+	ErrImpersonating        = apperror.Forbidden("not allowed while impersonating a user").WithCode(apperror.CodeAuthImpersonating)
+	ErrDataExportNotFound   = apperror.NotFound("data export not found").WithCode(apperror.CodeDataExportNotFound)
+	ErrTaskNotFound         = apperror.NotFound("task not found").WithCode(apperror.CodeTaskNotFound)
+	ErrFeatureFlagNotFound  = apperror.NotFound("feature flag not found").WithCode(apperror.CodeFeatureFlagNotFound)
+	ErrFeatureFlagsReadOnly = apperror.Conflict("feature flags cannot be changed without a store").WithCode(apperror.CodeFeatureFlagsReadOnly)
+	ErrTenantNotFound       = apperror.NotFound("tenant not found").WithCode(apperror.CodeTenantNotFound)
+	ErrTenantExists         = apperror.Conflict("tenant already exists").WithCode(apperror.CodeTenantExists)
+	ErrInvalidTenantSlug    = apperror.Validation("tenant slug must be lowercase letters, digits and hyphens").WithCode(apperror.CodeTenantSlugInvalid)
+	ErrOrganizationNotFound = apperror.NotFound("organization not found").WithCode(apperror.CodeOrganizationNotFound)
+	ErrMemberNotFound       = apperror.NotFound("member not found").WithCode(apperror.CodeOrganizationMemberNotFound)
+	ErrAlreadyMember        = apperror.Conflict("user is already a member of the organization").WithCode(apperror.CodeOrganizationAlreadyMember)
+	ErrLastOwner            = apperror.Conflict("an organization must keep at least one owner").WithCode(apperror.CodeOrganizationLastOwner)
+	ErrNotOrganizationAdmin = apperror.Forbidden("you do not have permission to manage this organization").WithCode(apperror.CodeOrganizationAdminRequired)
+	ErrRoleNotFound         = apperror.NotFound("role not found").WithCode(apperror.CodeRoleNotFound)
+	ErrRoleExists           = apperror.Conflict("role already exists").WithCode(apperror.CodeRoleExists)
+	ErrRoleNotAssigned      = apperror.NotFound("role is not assigned to the user").WithCode(apperror.CodeRoleNotAssigned)
+	ErrPermissionNotFound   = apperror.NotFound("permission not found").WithCode(apperror.CodePermissionNotFound)
+	ErrPermissionExists     = apperror.Conflict("permission already exists").WithCode(apperror.CodePermissionExists)
+	ErrInvalidPermission    = apperror.Validation("permission name must be lowercase resource:action").WithCode(apperror.CodePermissionInvalid)
+	ErrUnknownPermission    = apperror.Validation("role grants an unknown permission").WithCode(apperror.CodePermissionUnknown)
+	ErrSearchDisabled       = apperror.NotFound("search is not enabled").WithCode(apperror.CodeSearchDisabled)
+	ErrInvalidUserIDs       = apperror.Validation("ids must be a comma separated list of at most 100 user IDs").WithCode(apperror.CodeUserIDsInvalid)
+	ErrSearchTooDeep        = apperror.Validation("search results can only be paged through up to the 10000th").WithCode(apperror.CodeSearchTooDeep)
+)
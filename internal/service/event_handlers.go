@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/event"
+	"github.com/firdanbash/go-clean-boiler/internal/notification"
+	"github.com/firdanbash/go-clean-boiler/pkg/mailer"
+)
+
+// eventHandlers are the reactions of the services to domain events
+type eventHandlers struct {
+	audit    AuditService
+	notifier notification.Notifier
+	exports  DataExportService
+	tasks    TaskService
+}
+
+// RegisterEventHandlers subscribes the audit trail, the welcome notification,
+// the data export builds and the background tasks to the events dispatched by
+// the services
+func RegisterEventHandlers(bus *event.Bus, audit AuditService, notifier notification.Notifier, exports DataExportService, tasks TaskService) {
+	h := &eventHandlers{audit: audit, notifier: notifier, exports: exports, tasks: tasks}
+
+	event.On(bus, h.auditRegistered)
+	event.On(bus, h.auditDeleted)
+	event.OnAsync(bus, h.welcome)
+	event.OnAsync(bus, h.buildDataExport)
+	event.OnAsync(bus, h.runTask)
+}
+
+// auditRegistered records the creation of a user
+func (h *eventHandlers) auditRegistered(ctx context.Context, e event.UserRegistered) error {
+	h.audit.Record(ctx, domain.AuditActionCreate, AuditEntityUser, e.User.ID, nil, e.User)
+	return nil
+}
+
+// auditDeleted records the deletion of a user
+func (h *eventHandlers) auditDeleted(ctx context.Context, e event.UserDeleted) error {
+	action := domain.AuditActionDelete
+	if e.Permanent {
+		action = domain.AuditActionHardDelete
+	}
+	h.audit.Record(ctx, action, AuditEntityUser, e.User.ID, e.User, nil)
+	return nil
+}
+
+// welcome welcomes a new user
+func (h *eventHandlers) welcome(ctx context.Context, e event.UserRegistered) error {
+	h.notifier.Notify(ctx, notification.Notification{
+		Type:   notification.TypeWelcome,
+		UserID: e.User.ID,
+		Title:  "Welcome",
+		Body:   "Your account has been created.",
+		Data:   mailer.WelcomeData{Name: e.User.Name, Email: e.User.Email},
+	})
+	return nil
+}
+
+// buildDataExport builds a requested personal data export
+func (h *eventHandlers) buildDataExport(ctx context.Context, e event.DataExportRequested) error {
+	return h.exports.Build(ctx, e.UserID, e.ExportID)
+}
+
+// runTask runs a queued task
+func (h *eventHandlers) runTask(ctx context.Context, e event.TaskQueued) error {
+	return h.tasks.Run(ctx, e.TaskID)
+}
@@ -0,0 +1,13 @@
+package service
+
+import (
+	"context"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+)
+
+// EventPublisher delivers domain events once a change is stored. Publish must
+// not block the caller nor fail the change.
+type EventPublisher interface {
+	Publish(ctx context.Context, event domain.Event)
+}
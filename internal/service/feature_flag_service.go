@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/dto/request"
+	"github.com/firdanbash/go-clean-boiler/internal/dto/response"
+	"github.com/firdanbash/go-clean-boiler/pkg/featureflag"
+)
+
+// AuditEntityFeatureFlag is the audit log entity type of feature flags. Flags
+// have no numeric ID, so their entries carry the name in the snapshots.
+const AuditEntityFeatureFlag = "feature_flag"
+
+type FeatureFlagService interface {
+	List(ctx context.Context) []response.FeatureFlagResponse
+	Set(ctx context.Context, name string, req *request.SetFeatureFlagRequest) (*response.FeatureFlagResponse, error)
+}
+
+type featureFlagService struct {
+	flags *featureflag.Flags
+	audit AuditService
+}
+
+// NewFeatureFlagService creates a new feature flag service
+func NewFeatureFlagService(flags *featureflag.Flags, audit AuditService) FeatureFlagService {
+	return &featureFlagService{flags: flags, audit: audit}
+}
+
+// List lists the declared flags with their current state
+func (s *featureFlagService) List(ctx context.Context) []response.FeatureFlagResponse {
+	flags := s.flags.List()
+	result := make([]response.FeatureFlagResponse, len(flags))
+	for i, flag := range flags {
+		result[i] = toFeatureFlagResponse(flag)
+	}
+	return result
+}
+
+// Set turns a declared flag on or off for every instance
+func (s *featureFlagService) Set(ctx context.Context, name string, req *request.SetFeatureFlagRequest) (*response.FeatureFlagResponse, error) {
+	before, err := s.flags.Get(name)
+	if err != nil {
+		return nil, featureFlagError(err)
+	}
+
+	flag, err := s.flags.Set(ctx, name, *req.Enabled)
+	if err != nil {
+		return nil, featureFlagError(err)
+	}
+
+	result := toFeatureFlagResponse(flag)
+	s.audit.Record(ctx, domain.AuditActionUpdate, AuditEntityFeatureFlag, 0, toFeatureFlagResponse(before), result)
+	return &result, nil
+}
+
+// featureFlagError maps the errors of the flags to service errors
+func featureFlagError(err error) error {
+	switch {
+	case errors.Is(err, featureflag.ErrUnknownFlag):
+		return ErrFeatureFlagNotFound
+	case errors.Is(err, featureflag.ErrReadOnly):
+		return ErrFeatureFlagsReadOnly
+	default:
+		return err
+	}
+}
+
+func toFeatureFlagResponse(flag featureflag.Flag) response.FeatureFlagResponse {
+	return response.FeatureFlagResponse{
+		Name:       flag.Name,
+		Enabled:    flag.Enabled,
+		Default:    flag.Default,
+		Overridden: flag.Overridden,
+	}
+}
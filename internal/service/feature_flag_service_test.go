@@ -0,0 +1,88 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/dto/request"
+	"github.com/firdanbash/go-clean-boiler/internal/dto/response"
+	"github.com/firdanbash/go-clean-boiler/internal/mocks"
+	"github.com/firdanbash/go-clean-boiler/internal/service"
+	"github.com/firdanbash/go-clean-boiler/pkg/config"
+	"github.com/firdanbash/go-clean-boiler/pkg/featureflag"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"go.uber.org/mock/gomock"
+)
+
+// memFlagStore is an in-memory feature flag store
+type memFlagStore map[string]bool
+
+func (s memFlagStore) Load(context.Context) (map[string]bool, error) {
+	flags := make(map[string]bool, len(s))
+	for name, enabled := range s {
+		flags[name] = enabled
+	}
+	return flags, nil
+}
+
+func (s memFlagStore) Save(_ context.Context, name string, enabled bool) error {
+	s[name] = enabled
+	return nil
+}
+
+func newFlags(store featureflag.Store) *featureflag.Flags {
+	cfg := config.FeatureFlagConfig{Flags: map[string]bool{"new_dashboard": false, "exports": true}}
+	return featureflag.New(cfg, store, logger.Nop())
+}
+
+func TestFeatureFlagServiceSet(t *testing.T) {
+	ctx := context.Background()
+	store := memFlagStore{}
+	flags := newFlags(store)
+	audit := mocks.NewMockAuditService(gomock.NewController(t))
+	svc := service.NewFeatureFlagService(flags, audit)
+
+	enabled := true
+	audit.EXPECT().Record(gomock.Any(), domain.AuditActionUpdate, service.AuditEntityFeatureFlag, uint(0),
+		response.FeatureFlagResponse{Name: "new_dashboard"},
+		response.FeatureFlagResponse{Name: "new_dashboard", Enabled: true, Overridden: true},
+	)
+
+	flag, err := svc.Set(ctx, "new_dashboard", &request.SetFeatureFlagRequest{Enabled: &enabled})
+	if err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if !flag.Enabled || !flag.Overridden || flag.Default {
+		t.Fatalf("Set() = %+v, want an enabled override of a disabled default", flag)
+	}
+	if !flags.Enabled("new_dashboard") || !store["new_dashboard"] {
+		t.Fatal("flag not enabled in the flags and the store")
+	}
+
+	if _, err := svc.Set(ctx, "unknown", &request.SetFeatureFlagRequest{Enabled: &enabled}); !errors.Is(err, service.ErrFeatureFlagNotFound) {
+		t.Fatalf("Set() of an undeclared flag error = %v, want ErrFeatureFlagNotFound", err)
+	}
+	if flags.Enabled("unknown") {
+		t.Fatal("undeclared flag is enabled")
+	}
+}
+
+func TestFeatureFlagServiceWithoutStore(t *testing.T) {
+	svc := service.NewFeatureFlagService(newFlags(nil), mocks.NewMockAuditService(gomock.NewController(t)))
+
+	got := svc.List(context.Background())
+	want := []response.FeatureFlagResponse{
+		{Name: "exports", Enabled: true, Default: true},
+		{Name: "new_dashboard"},
+	}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("List() = %+v, want %+v", got, want)
+	}
+
+	disabled := false
+	if _, err := svc.Set(context.Background(), "exports", &request.SetFeatureFlagRequest{Enabled: &disabled}); !errors.Is(err, service.ErrFeatureFlagsReadOnly) {
+		t.Fatalf("Set() error = %v, want ErrFeatureFlagsReadOnly", err)
+	}
+}
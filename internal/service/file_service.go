@@ -0,0 +1,462 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/dto/request"
+	"github.com/firdanbash/go-clean-boiler/internal/dto/response"
+	"github.com/firdanbash/go-clean-boiler/internal/repository"
+	"github.com/firdanbash/go-clean-boiler/pkg/apperror"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"github.com/firdanbash/go-clean-boiler/pkg/storage"
+	"github.com/firdanbash/go-clean-boiler/pkg/tracing"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// AuditEntityFile is the audit log entity type of files
+const AuditEntityFile = "file"
+
+// fileExtensions maps content types to the extension of their storage key.
+// The extension never comes from the client, so a file cannot be served as
+// a type other than the one detected.
+var fileExtensions = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/gif":       ".gif",
+	"image/webp":      ".webp",
+	"application/pdf": ".pdf",
+	"text/plain":      ".txt",
+	"application/zip": ".zip",
+}
+
+// fileKeyPattern matches the storage keys of files: files/<owner ID>/<random><extension>
+var fileKeyPattern = regexp.MustCompile(`^files/(\d+)/[0-9a-f]{32}(\.[a-z]+)?$`)
+
+// FileDownload is a file streamed to its owner
+type FileDownload struct {
+	Content     io.ReadCloser
+	Name        string
+	ContentType string
+	Size        int64
+}
+
+type FileService interface {
+	Upload(ctx context.Context, ownerID uint, name string, file io.Reader, size int64) (*response.FileResponse, error)
+	PresignUpload(ctx context.Context, ownerID uint, req *request.PresignUploadRequest) (*response.PresignedUploadResponse, error)
+	CompleteUpload(ctx context.Context, ownerID uint, req *request.CompleteUploadRequest) (*response.FileResponse, error)
+	PresignDownload(ctx context.Context, ownerID, id uint) (*response.PresignedDownloadResponse, error)
+	Open(ctx context.Context, ownerID, id uint) (*FileDownload, error)
+	GetByID(ctx context.Context, ownerID, id uint) (*response.FileResponse, error)
+	GetAll(ctx context.Context, ownerID uint, req *request.ListFilesRequest) ([]response.FileResponse, int64, error)
+	Delete(ctx context.Context, ownerID, id uint) error
+}
+
+type fileService struct {
+	repo          repository.FileRepository
+	storage       storage.Storage
+	audit         AuditService
+	maxSize       int64
+	allowedTypes  map[string]bool
+	presignExpiry time.Duration
+	log           logger.Logger
+}
+
+// NewFileService creates a new file service accepting files of at most
+// maxSize bytes whose detected media type is in allowedTypes. Presigned URLs
+// are valid for presignExpiry.
+func NewFileService(
+	repo repository.FileRepository,
+	store storage.Storage,
+	audit AuditService,
+	maxSize int64,
+	allowedTypes []string,
+	presignExpiry time.Duration,
+	log logger.Logger,
+) FileService {
+	allowed := make(map[string]bool, len(allowedTypes))
+	for _, t := range allowedTypes {
+		allowed[strings.ToLower(t)] = true
+	}
+
+	return &fileService{
+		repo:          repo,
+		storage:       store,
+		audit:         audit,
+		maxSize:       maxSize,
+		allowedTypes:  allowed,
+		presignExpiry: presignExpiry,
+		log:           log,
+	}
+}
+
+// Upload stores the content of a file and records its metadata
+func (s *fileService) Upload(ctx context.Context, ownerID uint, name string, file io.Reader, size int64) (*response.FileResponse, error) {
+	ctx, span := tracing.Start(ctx, "FileService.Upload")
+	defer span.End()
+
+	if err := s.checkSize(size); err != nil {
+		return nil, err
+	}
+
+	// Detect the content type from the file itself rather than trusting the client
+	reader := bufio.NewReader(file)
+	head, err := reader.Peek(512)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	contentType := http.DetectContentType(head)
+	mediaType, err := s.checkType(contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := newFileKey(ownerID, mediaType)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.storage.Put(ctx, key, io.LimitReader(reader, s.maxSize), size, contentType); err != nil {
+		return nil, err
+	}
+
+	return s.create(ctx, &domain.File{
+		OwnerID:     ownerID,
+		Key:         key,
+		Name:        cleanFileName(name, path.Base(key)),
+		ContentType: contentType,
+		Size:        size,
+	})
+}
+
+// PresignUpload returns a URL the owner uploads a file to directly. The
+// upload is not a file until CompleteUpload registers it.
+func (s *fileService) PresignUpload(ctx context.Context, ownerID uint, req *request.PresignUploadRequest) (*response.PresignedUploadResponse, error) {
+	ctx, span := tracing.Start(ctx, "FileService.PresignUpload")
+	defer span.End()
+
+	presigner, ok := s.storage.(storage.Presigner)
+	if !ok {
+		return nil, ErrPresignUnsupported
+	}
+
+	if err := s.checkSize(req.Size); err != nil {
+		return nil, err
+	}
+
+	mediaType, params, err := mime.ParseMediaType(req.ContentType)
+	if err != nil {
+		return nil, ErrInvalidFileType
+	}
+	if _, err := s.checkType(mediaType); err != nil {
+		return nil, err
+	}
+	contentType := mime.FormatMediaType(mediaType, params)
+
+	key, err := newFileKey(ownerID, mediaType)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(s.presignExpiry)
+	url, err := presigner.PresignPut(ctx, key, contentType, req.Size, s.presignExpiry)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.PresignedUploadResponse{
+		Key:    key,
+		Method: http.MethodPut,
+		URL:    url,
+		Headers: map[string]string{
+			"Content-Type":   contentType,
+			"Content-Length": strconv.FormatInt(req.Size, 10),
+		},
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// CompleteUpload registers a file the owner uploaded with a presigned URL,
+// once its content passes the checks of Upload. Rejected content is removed.
+func (s *fileService) CompleteUpload(ctx context.Context, ownerID uint, req *request.CompleteUploadRequest) (*response.FileResponse, error) {
+	ctx, span := tracing.Start(ctx, "FileService.CompleteUpload")
+	defer span.End()
+
+	presigner, ok := s.storage.(storage.Presigner)
+	if !ok {
+		return nil, ErrPresignUnsupported
+	}
+
+	// Keys embed the ID of the user they were presigned for
+	match := fileKeyPattern.FindStringSubmatch(req.Key)
+	if match == nil || match[1] != strconv.FormatUint(uint64(ownerID), 10) {
+		return nil, ErrUploadNotFound
+	}
+
+	if _, err := s.repo.FindByKey(ctx, req.Key); err == nil {
+		return nil, ErrUploadCompleted
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	info, err := presigner.Stat(ctx, req.Key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, ErrUploadNotFound
+		}
+		return nil, err
+	}
+
+	if err := s.checkUpload(ctx, req.Key, info); err != nil {
+		s.remove(ctx, req.Key)
+		return nil, err
+	}
+
+	return s.create(ctx, &domain.File{
+		OwnerID:     ownerID,
+		Key:         req.Key,
+		Name:        cleanFileName(req.Name, path.Base(req.Key)),
+		ContentType: info.ContentType,
+		Size:        info.Size,
+	})
+}
+
+// PresignDownload returns a short-lived URL to download a file of the owner
+func (s *fileService) PresignDownload(ctx context.Context, ownerID, id uint) (*response.PresignedDownloadResponse, error) {
+	ctx, span := tracing.Start(ctx, "FileService.PresignDownload")
+	defer span.End()
+
+	presigner, ok := s.storage.(storage.Presigner)
+	if !ok {
+		return nil, ErrPresignUnsupported
+	}
+
+	file, err := s.find(ctx, ownerID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(s.presignExpiry)
+	url, err := presigner.PresignGet(ctx, file.Key, file.Name, s.presignExpiry)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.PresignedDownloadResponse{URL: url, ExpiresAt: expiresAt}, nil
+}
+
+// Open opens the content of a file of the owner, to stream it through the API
+// whatever the storage backend
+func (s *fileService) Open(ctx context.Context, ownerID, id uint) (*FileDownload, error) {
+	ctx, span := tracing.Start(ctx, "FileService.Open")
+	defer span.End()
+
+	file, err := s.find(ctx, ownerID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := s.storage.Get(ctx, file.Key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, ErrFileNotFound
+		}
+		return nil, err
+	}
+
+	return &FileDownload{Content: content, Name: file.Name, ContentType: file.ContentType, Size: file.Size}, nil
+}
+
+// GetByID gets a file of the owner by ID
+func (s *fileService) GetByID(ctx context.Context, ownerID, id uint) (*response.FileResponse, error) {
+	ctx, span := tracing.Start(ctx, "FileService.GetByID")
+	defer span.End()
+
+	file, err := s.find(ctx, ownerID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return toFileResponse(file), nil
+}
+
+// GetAll gets the files of the owner with pagination
+func (s *fileService) GetAll(ctx context.Context, ownerID uint, req *request.ListFilesRequest) ([]response.FileResponse, int64, error) {
+	ctx, span := tracing.Start(ctx, "FileService.GetAll")
+	defer span.End()
+
+	sort, err := repository.ParseSort(req.Sort, repository.FileSortFields)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	offset := (req.Page - 1) * req.PerPage
+	files, total, err := s.repo.FindAll(ctx, repository.FileFilter{OwnerID: &ownerID, Sort: sort}, req.PerPage, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	responses := make([]response.FileResponse, len(files))
+	for i := range files {
+		responses[i] = *toFileResponse(&files[i])
+	}
+
+	return responses, total, nil
+}
+
+// Delete deletes a file of the owner and its content
+func (s *fileService) Delete(ctx context.Context, ownerID, id uint) error {
+	ctx, span := tracing.Start(ctx, "FileService.Delete")
+	defer span.End()
+
+	file, err := s.find(ctx, ownerID, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	s.remove(ctx, file.Key)
+
+	s.audit.Record(ctx, domain.AuditActionDelete, AuditEntityFile, id, toFileResponse(file), nil)
+
+	return nil
+}
+
+// checkSize rejects empty files and files over the size limit
+func (s *fileService) checkSize(size int64) error {
+	if size <= 0 {
+		return ErrEmptyFile
+	}
+	if size > s.maxSize {
+		return apperror.Validation(fmt.Sprintf("file must not exceed %d bytes", s.maxSize)).WithCode(apperror.CodeFileTooLarge)
+	}
+	return nil
+}
+
+// checkType returns the media type of contentType if files of that type are allowed
+func (s *fileService) checkType(contentType string) (string, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || !s.allowedTypes[mediaType] {
+		return "", ErrInvalidFileType
+	}
+	return mediaType, nil
+}
+
+// checkUpload checks the size of an uploaded object and that its content is
+// of the type it was presigned for, which its key extension reflects
+func (s *fileService) checkUpload(ctx context.Context, key string, info *storage.ObjectInfo) error {
+	if err := s.checkSize(info.Size); err != nil {
+		return err
+	}
+
+	object, err := s.storage.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer object.Close()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(object, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return err
+	}
+
+	detected, err := s.checkType(http.DetectContentType(head[:n]))
+	if err != nil {
+		return err
+	}
+	declared, _, err := mime.ParseMediaType(info.ContentType)
+	if err != nil || declared != detected || path.Ext(key) != fileExtensions[detected] {
+		return ErrInvalidFileType
+	}
+	return nil
+}
+
+// create records a stored file, removing its content if that fails
+func (s *fileService) create(ctx context.Context, file *domain.File) (*response.FileResponse, error) {
+	if err := s.repo.Create(ctx, file); err != nil {
+		s.remove(ctx, file.Key)
+		return nil, err
+	}
+
+	created := toFileResponse(file)
+	s.audit.Record(ctx, domain.AuditActionCreate, AuditEntityFile, file.ID, nil, created)
+
+	return created, nil
+}
+
+// remove deletes stored content no file refers to. Failures are only logged,
+// as a leftover object is wasted space rather than an inconsistency.
+func (s *fileService) remove(ctx context.Context, key string) {
+	if err := s.storage.Delete(ctx, key); err != nil {
+		logger.Ctx(ctx, s.log).Warn("Failed to remove file content", zap.String("key", key), zap.Error(err))
+	}
+}
+
+// find loads a file, hiding the files of other users as not found
+func (s *fileService) find(ctx context.Context, ownerID, id uint) (*domain.File, error) {
+	file, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrFileNotFound
+		}
+		return nil, err
+	}
+	if file.OwnerID != ownerID {
+		return nil, ErrFileNotFound
+	}
+	return file, nil
+}
+
+// toFileResponse converts a file to its response. Files are private, so it
+// has no public URL: their content is downloaded through the API.
+func toFileResponse(file *domain.File) *response.FileResponse {
+	return &response.FileResponse{
+		ID:          file.ID,
+		Name:        file.Name,
+		ContentType: file.ContentType,
+		Size:        file.Size,
+		CreatedAt:   file.CreatedAt,
+	}
+}
+
+// newFileKey returns a new random storage key for a file of the owner, with
+// the extension of its media type
+func newFileKey(ownerID uint, mediaType string) (string, error) {
+	suffix, err := generateRandomToken(16)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("files/%d/%s%s", ownerID, suffix, fileExtensions[mediaType]), nil
+}
+
+// cleanFileName keeps the base name of a client supplied file name, valid
+// UTF-8 and at most 255 bytes long, falling back to fallback when nothing is left
+func cleanFileName(name, fallback string) string {
+	name = strings.TrimSpace(path.Base(strings.ReplaceAll(name, "\\", "/")))
+	name = strings.ToValidUTF8(name, "")
+	for len(name) > 255 {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	if name == "" || name == "." || name == "/" {
+		return fallback
+	}
+	return name
+}
@@ -0,0 +1,331 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/dto/request"
+	"github.com/firdanbash/go-clean-boiler/internal/mocks"
+	"github.com/firdanbash/go-clean-boiler/internal/service"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"github.com/firdanbash/go-clean-boiler/pkg/storage"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+)
+
+// memStorage is an in-memory storage backend
+type memStorage struct {
+	objects map[string][]byte
+	types   map[string]string
+}
+
+func newMemStorage() *memStorage {
+	return &memStorage{objects: map[string][]byte{}, types: map[string]string{}}
+}
+
+func (s *memStorage) Put(_ context.Context, key string, r io.Reader, _ int64, contentType string) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	s.objects[key] = data
+	s.types[key] = contentType
+	return nil
+}
+
+func (s *memStorage) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	data, ok := s.objects[key]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (s *memStorage) Delete(_ context.Context, key string) error {
+	delete(s.objects, key)
+	return nil
+}
+
+func (s *memStorage) URL(key string) string {
+	return "/uploads/" + key
+}
+
+// presignStorage is an in-memory storage backend supporting presigned URLs
+type presignStorage struct {
+	*memStorage
+}
+
+func (s presignStorage) PresignPut(_ context.Context, key, _ string, _ int64, _ time.Duration) (string, error) {
+	return "https://bucket.example.com/" + key + "?signature=put", nil
+}
+
+func (s presignStorage) PresignGet(_ context.Context, key, _ string, _ time.Duration) (string, error) {
+	return "https://bucket.example.com/" + key + "?signature=get", nil
+}
+
+func (s presignStorage) Stat(_ context.Context, key string) (*storage.ObjectInfo, error) {
+	data, ok := s.objects[key]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+	return &storage.ObjectInfo{Size: int64(len(data)), ContentType: s.types[key]}, nil
+}
+
+// fileServiceDeps holds the dependencies of the file service under test
+type fileServiceDeps struct {
+	repo    *mocks.MockFileRepository
+	audit   *mocks.MockAuditService
+	storage *memStorage
+}
+
+func newFileService(t *testing.T) (service.FileService, fileServiceDeps) {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+	deps := fileServiceDeps{
+		repo:    mocks.NewMockFileRepository(ctrl),
+		audit:   mocks.NewMockAuditService(ctrl),
+		storage: newMemStorage(),
+	}
+	svc := service.NewFileService(deps.repo, presignStorage{deps.storage}, deps.audit, 1024, []string{"text/plain", "image/png"}, time.Minute, logger.Nop())
+	return svc, deps
+}
+
+func TestFileServiceUpload(t *testing.T) {
+	ctx := context.Background()
+	content := "quarterly numbers"
+
+	t.Run("stores the file under a key with the detected extension", func(t *testing.T) {
+		svc, deps := newFileService(t)
+
+		var stored *domain.File
+		deps.repo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, file *domain.File) error {
+			file.ID = 3
+			stored = file
+			return nil
+		})
+		deps.audit.EXPECT().Record(gomock.Any(), domain.AuditActionCreate, service.AuditEntityFile, uint(3), nil, gomock.Any())
+
+		result, err := svc.Upload(ctx, 7, `C:\reports\report.html`, strings.NewReader(content), int64(len(content)))
+		if err != nil {
+			t.Fatalf("Upload() error = %v", err)
+		}
+		if !strings.HasPrefix(stored.Key, "files/7/") || !strings.HasSuffix(stored.Key, ".txt") {
+			t.Errorf("key = %q, want files/7/<random>.txt", stored.Key)
+		}
+		if result.Name != "report.html" || result.ContentType != "text/plain; charset=utf-8" {
+			t.Errorf("Upload() = %+v", result)
+		}
+		if got := string(deps.storage.objects[stored.Key]); got != content {
+			t.Errorf("stored content = %q, want %q", got, content)
+		}
+	})
+
+	t.Run("rejects types that are not allowed", func(t *testing.T) {
+		svc, deps := newFileService(t)
+		pdf := "%PDF-1.7\n"
+
+		_, err := svc.Upload(ctx, 7, "report.txt", strings.NewReader(pdf), int64(len(pdf)))
+		if !errors.Is(err, service.ErrInvalidFileType) {
+			t.Fatalf("Upload() error = %v, want ErrInvalidFileType", err)
+		}
+		if len(deps.storage.objects) != 0 {
+			t.Error("rejected file was stored")
+		}
+	})
+
+	t.Run("rejects files over the size limit", func(t *testing.T) {
+		svc, _ := newFileService(t)
+
+		_, err := svc.Upload(ctx, 7, "big.txt", strings.NewReader(content), 2048)
+		if err == nil {
+			t.Fatal("Upload() error = nil, want a size error")
+		}
+	})
+
+	t.Run("removes the content when the record cannot be saved", func(t *testing.T) {
+		svc, deps := newFileService(t)
+		deps.repo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(errors.New("db down"))
+
+		if _, err := svc.Upload(ctx, 7, "notes.txt", strings.NewReader(content), int64(len(content))); err == nil {
+			t.Fatal("Upload() error = nil, want the repository error")
+		}
+		if len(deps.storage.objects) != 0 {
+			t.Error("orphaned content was not removed")
+		}
+	})
+}
+
+func TestFileServiceOwnership(t *testing.T) {
+	ctx := context.Background()
+	file := &domain.File{ID: 3, OwnerID: 7, Key: "files/7/abc.txt", Name: "notes.txt"}
+
+	t.Run("hides the files of other users", func(t *testing.T) {
+		svc, deps := newFileService(t)
+		deps.repo.EXPECT().FindByID(gomock.Any(), uint(3)).Return(file, nil).Times(3)
+
+		if _, err := svc.GetByID(ctx, 8, 3); !errors.Is(err, service.ErrFileNotFound) {
+			t.Errorf("GetByID() error = %v, want ErrFileNotFound", err)
+		}
+		if _, err := svc.Open(ctx, 8, 3); !errors.Is(err, service.ErrFileNotFound) {
+			t.Errorf("Open() error = %v, want ErrFileNotFound", err)
+		}
+		if err := svc.Delete(ctx, 8, 3); !errors.Is(err, service.ErrFileNotFound) {
+			t.Errorf("Delete() error = %v, want ErrFileNotFound", err)
+		}
+	})
+
+	t.Run("streams the content to the owner", func(t *testing.T) {
+		svc, deps := newFileService(t)
+		deps.storage.objects[file.Key] = []byte("notes")
+		deps.repo.EXPECT().FindByID(gomock.Any(), uint(3)).Return(file, nil)
+
+		download, err := svc.Open(ctx, 7, 3)
+		if err != nil {
+			t.Fatalf("Open() error = %v", err)
+		}
+		defer download.Content.Close()
+		content, err := io.ReadAll(download.Content)
+		if err != nil {
+			t.Fatalf("ReadAll() error = %v", err)
+		}
+		if string(content) != "notes" || download.Name != "notes.txt" {
+			t.Errorf("Open() = %q named %q, want %q named notes.txt", content, download.Name, "notes")
+		}
+	})
+
+	t.Run("deletes the record and the content of the owner", func(t *testing.T) {
+		svc, deps := newFileService(t)
+		deps.storage.objects[file.Key] = []byte("notes")
+		deps.repo.EXPECT().FindByID(gomock.Any(), uint(3)).Return(file, nil)
+		deps.repo.EXPECT().Delete(gomock.Any(), uint(3)).Return(nil)
+		deps.audit.EXPECT().Record(gomock.Any(), domain.AuditActionDelete, service.AuditEntityFile, uint(3), gomock.Any(), nil)
+
+		if err := svc.Delete(ctx, 7, 3); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+		if _, ok := deps.storage.objects[file.Key]; ok {
+			t.Error("content was not removed")
+		}
+	})
+
+	t.Run("reports missing files as not found", func(t *testing.T) {
+		svc, deps := newFileService(t)
+		deps.repo.EXPECT().FindByID(gomock.Any(), uint(4)).Return(nil, gorm.ErrRecordNotFound)
+
+		if _, err := svc.GetByID(ctx, 7, 4); !errors.Is(err, service.ErrFileNotFound) {
+			t.Errorf("GetByID() error = %v, want ErrFileNotFound", err)
+		}
+	})
+}
+
+func TestFileServicePresignedUploads(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("requires a storage driver that presigns URLs", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		svc := service.NewFileService(mocks.NewMockFileRepository(ctrl), newMemStorage(), mocks.NewMockAuditService(ctrl), 1024, []string{"text/plain"}, time.Minute, logger.Nop())
+
+		_, err := svc.PresignUpload(ctx, 7, &request.PresignUploadRequest{ContentType: "text/plain", Size: 10})
+		if !errors.Is(err, service.ErrPresignUnsupported) {
+			t.Fatalf("PresignUpload() error = %v, want ErrPresignUnsupported", err)
+		}
+	})
+
+	t.Run("signs an upload of an allowed type for the owner", func(t *testing.T) {
+		svc, _ := newFileService(t)
+
+		result, err := svc.PresignUpload(ctx, 7, &request.PresignUploadRequest{ContentType: "image/PNG", Size: 100})
+		if err != nil {
+			t.Fatalf("PresignUpload() error = %v", err)
+		}
+		if !strings.HasPrefix(result.Key, "files/7/") || !strings.HasSuffix(result.Key, ".png") {
+			t.Errorf("key = %q, want files/7/<random>.png", result.Key)
+		}
+		if result.Method != "PUT" || result.Headers["Content-Type"] != "image/png" || result.Headers["Content-Length"] != "100" {
+			t.Errorf("PresignUpload() = %+v", result)
+		}
+	})
+
+	t.Run("rejects types that are not allowed", func(t *testing.T) {
+		svc, _ := newFileService(t)
+
+		_, err := svc.PresignUpload(ctx, 7, &request.PresignUploadRequest{ContentType: "text/html", Size: 100})
+		if !errors.Is(err, service.ErrInvalidFileType) {
+			t.Fatalf("PresignUpload() error = %v, want ErrInvalidFileType", err)
+		}
+	})
+
+	t.Run("does not complete the uploads of other users", func(t *testing.T) {
+		svc, deps := newFileService(t)
+		key := "files/8/0123456789abcdef0123456789abcdef.txt"
+		deps.storage.objects[key] = []byte("notes")
+
+		_, err := svc.CompleteUpload(ctx, 7, &request.CompleteUploadRequest{Key: key, Name: "notes.txt"})
+		if !errors.Is(err, service.ErrUploadNotFound) {
+			t.Fatalf("CompleteUpload() error = %v, want ErrUploadNotFound", err)
+		}
+		if _, ok := deps.storage.objects[key]; !ok {
+			t.Error("the upload of another user was removed")
+		}
+	})
+
+	t.Run("removes content that is not of the presigned type", func(t *testing.T) {
+		svc, deps := newFileService(t)
+		key := "files/7/0123456789abcdef0123456789abcdef.png"
+		deps.storage.objects[key] = []byte("<html><script>alert(1)</script></html>")
+		deps.storage.types[key] = "image/png"
+		deps.repo.EXPECT().FindByKey(gomock.Any(), key).Return(nil, gorm.ErrRecordNotFound)
+
+		_, err := svc.CompleteUpload(ctx, 7, &request.CompleteUploadRequest{Key: key, Name: "cat.png"})
+		if !errors.Is(err, service.ErrInvalidFileType) {
+			t.Fatalf("CompleteUpload() error = %v, want ErrInvalidFileType", err)
+		}
+		if _, ok := deps.storage.objects[key]; ok {
+			t.Error("rejected content was not removed")
+		}
+	})
+
+	t.Run("registers the uploaded file", func(t *testing.T) {
+		svc, deps := newFileService(t)
+		key := "files/7/0123456789abcdef0123456789abcdef.txt"
+		deps.storage.objects[key] = []byte("quarterly numbers")
+		deps.storage.types[key] = "text/plain"
+		deps.repo.EXPECT().FindByKey(gomock.Any(), key).Return(nil, gorm.ErrRecordNotFound)
+		deps.repo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, file *domain.File) error {
+			file.ID = 5
+			return nil
+		})
+		deps.audit.EXPECT().Record(gomock.Any(), domain.AuditActionCreate, service.AuditEntityFile, uint(5), nil, gomock.Any())
+
+		result, err := svc.CompleteUpload(ctx, 7, &request.CompleteUploadRequest{Key: key, Name: "numbers.txt"})
+		if err != nil {
+			t.Fatalf("CompleteUpload() error = %v", err)
+		}
+		if result.ID != 5 || result.Name != "numbers.txt" || result.Size != 17 || result.ContentType != "text/plain" {
+			t.Errorf("CompleteUpload() = %+v", result)
+		}
+	})
+
+	t.Run("signs downloads of the owner only", func(t *testing.T) {
+		svc, deps := newFileService(t)
+		file := &domain.File{ID: 3, OwnerID: 7, Key: "files/7/abc.txt", Name: "notes.txt"}
+		deps.repo.EXPECT().FindByID(gomock.Any(), uint(3)).Return(file, nil).Times(2)
+
+		if _, err := svc.PresignDownload(ctx, 8, 3); !errors.Is(err, service.ErrFileNotFound) {
+			t.Errorf("PresignDownload() error = %v, want ErrFileNotFound", err)
+		}
+		result, err := svc.PresignDownload(ctx, 7, 3)
+		if err != nil {
+			t.Fatalf("PresignDownload() error = %v", err)
+		}
+		if !strings.Contains(result.URL, file.Key) {
+			t.Errorf("PresignDownload() URL = %q, want a URL of %s", result.URL, file.Key)
+		}
+	})
+}
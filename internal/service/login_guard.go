@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/firdanbash/go-clean-boiler/pkg/bruteforce"
+)
+
+// LoginGuard throttles the login attempts on an account after failures,
+// whatever IP they come from. It is implemented by bruteforce.Guard.
+type LoginGuard interface {
+	Check(ctx context.Context, account string) (bruteforce.Status, error)
+	Fail(ctx context.Context, account string) (bruteforce.Status, error)
+	Reset(ctx context.Context, account string) error
+}
+
+// MFAAttempts counts the codes rejected for each MFA challenge
+type MFAAttempts interface {
+	Fail(ctx context.Context, challengeID string) (int, error)
+	Reset(ctx context.Context, challengeID string) error
+}
+
+// CaptchaVerifier checks the captcha solved by a client, e.g. against
+// reCAPTCHA, hCaptcha or Turnstile. None is provided: supply one to require a
+// captcha after auth.brute_force.captcha_after failed logins.
+type CaptchaVerifier interface {
+	Verify(ctx context.Context, token string) (bool, error)
+}
+
+// LoginThrottledError refuses a login until RetryAfter has passed. It matches
+// ErrTooManyLoginAttempts.
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string {
+	return ErrTooManyLoginAttempts.Error()
+}
+
+func (e *LoginThrottledError) Unwrap() error {
+	return ErrTooManyLoginAttempts
+}
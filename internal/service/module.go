@@ -0,0 +1,153 @@
+package service
+
+import (
+	"github.com/casbin/casbin/v2"
+	"github.com/firdanbash/go-clean-boiler/internal/event"
+	"github.com/firdanbash/go-clean-boiler/internal/notification"
+	"github.com/firdanbash/go-clean-boiler/internal/repository"
+	"github.com/firdanbash/go-clean-boiler/pkg/config"
+	"github.com/firdanbash/go-clean-boiler/pkg/jwt"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"github.com/firdanbash/go-clean-boiler/pkg/mailer"
+	"github.com/firdanbash/go-clean-boiler/pkg/password"
+	"github.com/firdanbash/go-clean-boiler/pkg/storage"
+	"go.uber.org/fx"
+)
+
+// Module provides the services
+var Module = fx.Module("services",
+	fx.Provide(
+		NewAuditService,
+		NewActivityService,
+		NewSessionService,
+		NewNotificationService,
+		NewFeatureFlagService,
+		NewTenantService,
+		NewOrganizationService,
+		NewRoleService,
+		NewSearchService,
+		NewTaskService,
+		providePasswordHistoryService,
+		provideUserService,
+		provideAuthService,
+		provideFileService,
+		provideDataExportService,
+		// gen:services
+	),
+	fx.Invoke(RegisterEventHandlers, RegisterSearchIndexing, RegisterTaskRunners),
+)
+
+// providePasswordHistoryService passes the configured history size to NewPasswordHistoryService
+func providePasswordHistoryService(repo repository.PasswordHistoryRepository, hasher password.Hasher, cfg *config.Config) PasswordHistoryService {
+	return NewPasswordHistoryService(repo, hasher, cfg.Auth.PasswordHistory)
+}
+
+// provideUserService passes the configured avatar size limit to NewUserService
+func provideUserService(
+	repo repository.UserRepository,
+	hasher password.Hasher,
+	passwords PasswordHistoryService,
+	store storage.Storage,
+	audit AuditService,
+	events EventPublisher,
+	responses CacheInvalidator,
+	dispatcher event.Dispatcher,
+	notifier notification.Notifier,
+	tx repository.Transactor,
+	outbox repository.OutboxRepository,
+	cfg *config.Config,
+	log logger.Logger,
+) UserService {
+	return NewUserService(repo, hasher, passwords, store, audit, events, responses, dispatcher, notifier, tx, outbox, cfg.Storage.MaxAvatarSize, log)
+}
+
+// provideFileService passes the configured upload limits and URL lifetime to NewFileService
+func provideFileService(
+	repo repository.FileRepository,
+	store storage.Storage,
+	audit AuditService,
+	cfg *config.Config,
+	log logger.Logger,
+) FileService {
+	return NewFileService(repo, store, audit, cfg.Storage.MaxFileSize, cfg.Storage.AllowedFileTypes, cfg.Storage.PresignExpiry, log)
+}
+
+// provideDataExportService passes the archive and download URL lifetimes to NewDataExportService
+func provideDataExportService(
+	repo repository.DataExportRepository,
+	userRepo repository.UserRepository,
+	activity ActivityService,
+	audit AuditService,
+	store storage.Storage,
+	dispatcher event.Dispatcher,
+	notifier notification.Notifier,
+	jwtManager *jwt.Manager,
+	cfg *config.Config,
+	log logger.Logger,
+) DataExportService {
+	return NewDataExportService(repo, userRepo, activity, audit, store, dispatcher, notifier, jwtManager, cfg.Storage.DataExportExpiry, cfg.Storage.PresignExpiry, log)
+}
+
+// authServiceParams are the dependencies of the auth service
+type authServiceParams struct {
+	fx.In
+
+	Users         repository.UserRepository
+	ResetTokens   repository.PasswordResetTokenRepository
+	RecoveryCodes repository.MFARecoveryCodeRepository
+	RevokedTokens repository.RevokedTokenRepository
+	Sessions      repository.SessionRepository
+	Roles         repository.RoleRepository
+	Hasher        password.Hasher
+	Passwords     PasswordHistoryService
+	Activity      ActivityService
+	Audit         AuditService
+	Policies      *casbin.SyncedEnforcer
+	Guard         LoginGuard
+	MFAAttempts   MFAAttempts
+	Captcha       CaptchaVerifier `optional:"true"`
+	Events        EventPublisher
+	Responses     CacheInvalidator
+	Dispatcher    event.Dispatcher
+	Tx            repository.Transactor
+	Outbox        repository.OutboxRepository
+	Mailer        mailer.Mailer
+	MailTemplates *mailer.Templates
+	Notifier      notification.Notifier
+	JWTManager    *jwt.Manager
+	Config        *config.Config
+	Logger        logger.Logger
+}
+
+// provideAuthService passes the auth and token settings to NewAuthService
+func provideAuthService(p authServiceParams) AuthService {
+	return NewAuthService(
+		p.Users,
+		p.ResetTokens,
+		p.RecoveryCodes,
+		p.RevokedTokens,
+		p.Sessions,
+		p.Roles,
+		p.Hasher,
+		p.Passwords,
+		p.Activity,
+		p.Audit,
+		p.Policies,
+		p.Guard,
+		p.MFAAttempts,
+		p.Captcha,
+		p.Events,
+		p.Responses,
+		p.Dispatcher,
+		p.Tx,
+		p.Outbox,
+		p.Mailer,
+		p.MailTemplates,
+		p.Notifier,
+		p.Config.Auth,
+		p.JWTManager,
+		p.Config.JWT.Expiration.String(),
+		p.Config.JWT.RememberMeExpiration.String(),
+		p.Logger,
+	)
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/dto/request"
+	"github.com/firdanbash/go-clean-boiler/internal/dto/response"
+	"github.com/firdanbash/go-clean-boiler/internal/notification"
+	"github.com/firdanbash/go-clean-boiler/pkg/mailer"
+)
+
+type NotificationService interface {
+	GetPreferences(ctx context.Context, userID uint) ([]response.NotificationPreferenceResponse, error)
+	UpdatePreferences(ctx context.Context, userID uint, req *request.UpdateNotificationPreferencesRequest) ([]response.NotificationPreferenceResponse, error)
+}
+
+type notificationService struct {
+	notifier notification.Notifier
+}
+
+// NewNotificationService creates a new notification service
+func NewNotificationService(notifier notification.Notifier) NotificationService {
+	return &notificationService{notifier: notifier}
+}
+
+// GetPreferences lists the notification types and channels with whether the user receives them
+func (s *notificationService) GetPreferences(ctx context.Context, userID uint) ([]response.NotificationPreferenceResponse, error) {
+	preferences, err := s.notifier.Preferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]response.NotificationPreferenceResponse, len(preferences))
+	for i, p := range preferences {
+		result[i] = response.NotificationPreferenceResponse{
+			Type:     p.Type,
+			Channel:  p.Channel,
+			Enabled:  p.Enabled,
+			Required: p.Required,
+		}
+	}
+	return result, nil
+}
+
+// UpdatePreferences stores the choices of the user and returns all their preferences
+func (s *notificationService) UpdatePreferences(ctx context.Context, userID uint, req *request.UpdateNotificationPreferencesRequest) ([]response.NotificationPreferenceResponse, error) {
+	preferences := make([]notification.Preference, len(req.Preferences))
+	for i, p := range req.Preferences {
+		preferences[i] = notification.Preference{Type: p.Type, Channel: p.Channel, Enabled: *p.Enabled}
+	}
+
+	if err := s.notifier.SetPreferences(ctx, userID, preferences); err != nil {
+		return nil, err
+	}
+
+	return s.GetPreferences(ctx, userID)
+}
+
+// passwordChangedNotice is the security notice sent to a user whose password changed at
+func passwordChangedNotice(user *domain.User, at time.Time) notification.Notification {
+	return notification.Notification{
+		Type:   notification.TypePasswordChanged,
+		UserID: user.ID,
+		Title:  "Your password was changed",
+		Body:   "The password of your account was changed. If this was not you, reset it right away.",
+		Data:   mailer.PasswordChangedData{Name: user.Name, ChangedAt: at.UTC().Format(time.RFC1123)},
+	}
+}
@@ -0,0 +1,303 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/dto/request"
+	"github.com/firdanbash/go-clean-boiler/internal/dto/response"
+	"github.com/firdanbash/go-clean-boiler/internal/notification"
+	"github.com/firdanbash/go-clean-boiler/internal/repository"
+	"github.com/firdanbash/go-clean-boiler/pkg/mailer"
+	"github.com/firdanbash/go-clean-boiler/pkg/tracing"
+	"gorm.io/gorm"
+)
+
+// Audit log entity types of organizations and their memberships
+const (
+	AuditEntityOrganization = "organization"
+	AuditEntityMembership   = "membership"
+)
+
+type OrganizationService interface {
+	Create(ctx context.Context, userID uint, req *request.CreateOrganizationRequest) (*response.OrganizationResponse, error)
+	GetByID(ctx context.Context, userID, id uint) (*response.OrganizationResponse, error)
+	GetAll(ctx context.Context, userID uint, req *request.ListOrganizationsRequest) ([]response.OrganizationResponse, int64, error)
+	ListMembers(ctx context.Context, userID, id uint) ([]response.MemberResponse, error)
+	InviteMember(ctx context.Context, userID, id uint, req *request.InviteMemberRequest) (*response.MemberResponse, error)
+	UpdateMemberRole(ctx context.Context, userID, id, memberID uint, req *request.UpdateMemberRoleRequest) (*response.MemberResponse, error)
+}
+
+type organizationService struct {
+	orgs        repository.OrganizationRepository
+	memberships repository.MembershipRepository
+	users       repository.UserRepository
+	audit       AuditService
+	notifier    notification.Notifier
+	tx          repository.Transactor
+}
+
+// NewOrganizationService creates a new organization service
+func NewOrganizationService(
+	orgs repository.OrganizationRepository,
+	memberships repository.MembershipRepository,
+	users repository.UserRepository,
+	audit AuditService,
+	notifier notification.Notifier,
+	tx repository.Transactor,
+) OrganizationService {
+	return &organizationService{
+		orgs:        orgs,
+		memberships: memberships,
+		users:       users,
+		audit:       audit,
+		notifier:    notifier,
+		tx:          tx,
+	}
+}
+
+// Create creates an organization owned by the user creating it
+func (s *organizationService) Create(ctx context.Context, userID uint, req *request.CreateOrganizationRequest) (*response.OrganizationResponse, error) {
+	ctx, span := tracing.Start(ctx, "OrganizationService.Create")
+	defer span.End()
+
+	org := &domain.Organization{Name: req.Name}
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.orgs.Create(ctx, org); err != nil {
+			return err
+		}
+		return s.memberships.Create(ctx, &domain.Membership{OrganizationID: org.ID, UserID: userID, Role: domain.OrgRoleOwner})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	created := toOrganizationResponse(org, domain.OrgRoleOwner)
+	s.audit.Record(ctx, domain.AuditActionCreate, AuditEntityOrganization, org.ID, nil, created)
+	return &created, nil
+}
+
+// GetByID gets an organization the user is a member of
+func (s *organizationService) GetByID(ctx context.Context, userID, id uint) (*response.OrganizationResponse, error) {
+	org, membership, err := s.member(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := toOrganizationResponse(org, membership.Role)
+	return &result, nil
+}
+
+// GetAll lists the organizations the user is a member of with pagination
+func (s *organizationService) GetAll(ctx context.Context, userID uint, req *request.ListOrganizationsRequest) ([]response.OrganizationResponse, int64, error) {
+	offset := (req.Page - 1) * req.PerPage
+	orgs, total, err := s.orgs.FindByMember(ctx, userID, req.PerPage, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ids := make([]uint, len(orgs))
+	for i, org := range orgs {
+		ids[i] = org.ID
+	}
+	roles := make(map[uint]string, len(orgs))
+	if len(ids) > 0 {
+		memberships, err := s.memberships.FindByUser(ctx, userID, ids)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, membership := range memberships {
+			roles[membership.OrganizationID] = membership.Role
+		}
+	}
+
+	result := make([]response.OrganizationResponse, len(orgs))
+	for i := range orgs {
+		result[i] = toOrganizationResponse(&orgs[i], roles[orgs[i].ID])
+	}
+	return result, total, nil
+}
+
+// ListMembers lists the members of an organization the user is a member of
+func (s *organizationService) ListMembers(ctx context.Context, userID, id uint) ([]response.MemberResponse, error) {
+	if _, _, err := s.member(ctx, id, userID); err != nil {
+		return nil, err
+	}
+
+	memberships, err := s.memberships.FindByOrganization(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]response.MemberResponse, 0, len(memberships))
+	for i := range memberships {
+		// Members whose account is deleted are not listed
+		if memberships[i].User != nil {
+			result = append(result, toMemberResponse(&memberships[i], memberships[i].User))
+		}
+	}
+	return result, nil
+}
+
+// InviteMember adds an existing user, found by email, to an organization and
+// notifies them. Owners and admins invite members; only owners invite owners.
+func (s *organizationService) InviteMember(ctx context.Context, userID, id uint, req *request.InviteMemberRequest) (*response.MemberResponse, error) {
+	ctx, span := tracing.Start(ctx, "OrganizationService.InviteMember")
+	defer span.End()
+
+	org, caller, err := s.member(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !canManageMembers(caller.Role) || (req.Role == domain.OrgRoleOwner && caller.Role != domain.OrgRoleOwner) {
+		return nil, ErrNotOrganizationAdmin
+	}
+
+	user, err := s.users.FindByEmail(ctx, req.Email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	if _, err := s.memberships.Find(ctx, org.ID, user.ID); err == nil {
+		return nil, ErrAlreadyMember
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	membership := &domain.Membership{OrganizationID: org.ID, UserID: user.ID, Role: req.Role}
+	if err := s.memberships.Create(ctx, membership); err != nil {
+		return nil, err
+	}
+
+	member := toMemberResponse(membership, user)
+	s.audit.Record(ctx, domain.AuditActionCreate, AuditEntityMembership, membership.ID, nil, member)
+	s.notifyInvite(ctx, org, user, userID, req.Role)
+	return &member, nil
+}
+
+// UpdateMemberRole changes the role of a member. Owners and admins change the
+// roles of admins and members; only owners promote to or demote from owner,
+// and the last owner cannot be demoted.
+func (s *organizationService) UpdateMemberRole(ctx context.Context, userID, id, memberID uint, req *request.UpdateMemberRoleRequest) (*response.MemberResponse, error) {
+	ctx, span := tracing.Start(ctx, "OrganizationService.UpdateMemberRole")
+	defer span.End()
+
+	org, caller, err := s.member(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !canManageMembers(caller.Role) {
+		return nil, ErrNotOrganizationAdmin
+	}
+
+	membership, err := s.memberships.Find(ctx, org.ID, memberID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMemberNotFound
+		}
+		return nil, err
+	}
+	if caller.Role != domain.OrgRoleOwner && (membership.Role == domain.OrgRoleOwner || req.Role == domain.OrgRoleOwner) {
+		return nil, ErrNotOrganizationAdmin
+	}
+
+	user, err := s.users.FindByID(ctx, memberID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMemberNotFound
+		}
+		return nil, err
+	}
+
+	before := toMemberResponse(membership, user)
+	if membership.Role == req.Role {
+		return &before, nil
+	}
+
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if membership.Role == domain.OrgRoleOwner {
+			owners, err := s.memberships.CountByRole(ctx, org.ID, domain.OrgRoleOwner)
+			if err != nil {
+				return err
+			}
+			if owners <= 1 {
+				return ErrLastOwner
+			}
+		}
+		return s.memberships.UpdateRole(ctx, membership.ID, req.Role)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	membership.Role = req.Role
+	updated := toMemberResponse(membership, user)
+	s.audit.Record(ctx, domain.AuditActionUpdate, AuditEntityMembership, membership.ID, before, updated)
+	return &updated, nil
+}
+
+// member returns an organization with the membership of the user in it.
+// Organizations the user does not belong to are reported as not found.
+func (s *organizationService) member(ctx context.Context, id, userID uint) (*domain.Organization, *domain.Membership, error) {
+	org, err := s.orgs.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrOrganizationNotFound
+		}
+		return nil, nil, err
+	}
+
+	membership, err := s.memberships.Find(ctx, id, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrOrganizationNotFound
+		}
+		return nil, nil, err
+	}
+	return org, membership, nil
+}
+
+// notifyInvite tells user they were added to org by the user with inviterID
+func (s *organizationService) notifyInvite(ctx context.Context, org *domain.Organization, user *domain.User, inviterID uint, role string) {
+	invitedBy := "Someone"
+	if inviter, err := s.users.FindByID(ctx, inviterID); err == nil {
+		invitedBy = inviter.Name
+	}
+
+	s.notifier.Notify(ctx, notification.Notification{
+		Type:   notification.TypeOrganizationInvite,
+		UserID: user.ID,
+		Title:  "You were added to " + org.Name,
+		Body:   invitedBy + " added you to the organization " + org.Name + ".",
+		Data:   mailer.OrganizationInviteData{Name: user.Name, Organization: org.Name, InvitedBy: invitedBy, Role: role},
+	})
+}
+
+// canManageMembers reports whether an organization role may invite members
+// and change their roles
+func canManageMembers(role string) bool {
+	return role == domain.OrgRoleOwner || role == domain.OrgRoleAdmin
+}
+
+func toOrganizationResponse(org *domain.Organization, role string) response.OrganizationResponse {
+	return response.OrganizationResponse{
+		ID:        org.ID,
+		Name:      org.Name,
+		Role:      role,
+		CreatedAt: org.CreatedAt,
+	}
+}
+
+func toMemberResponse(membership *domain.Membership, user *domain.User) response.MemberResponse {
+	return response.MemberResponse{
+		UserID:   user.ID,
+		Email:    user.Email,
+		Name:     user.Name,
+		Role:     membership.Role,
+		JoinedAt: membership.CreatedAt,
+	}
+}
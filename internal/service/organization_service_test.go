@@ -0,0 +1,199 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/dto/request"
+	"github.com/firdanbash/go-clean-boiler/internal/mocks"
+	"github.com/firdanbash/go-clean-boiler/internal/notification"
+	"github.com/firdanbash/go-clean-boiler/internal/service"
+	"github.com/firdanbash/go-clean-boiler/internal/testutil"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+)
+
+// organizationServiceDeps holds the mocked dependencies of the organization service under test
+type organizationServiceDeps struct {
+	orgs        *mocks.MockOrganizationRepository
+	memberships *mocks.MockMembershipRepository
+	users       *mocks.MockUserRepository
+	audit       *mocks.MockAuditService
+	notifier    *mocks.MockNotifier
+}
+
+func newOrganizationService(t *testing.T) (service.OrganizationService, organizationServiceDeps) {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+	deps := organizationServiceDeps{
+		orgs:        mocks.NewMockOrganizationRepository(ctrl),
+		memberships: mocks.NewMockMembershipRepository(ctrl),
+		users:       mocks.NewMockUserRepository(ctrl),
+		audit:       mocks.NewMockAuditService(ctrl),
+		notifier:    mocks.NewMockNotifier(ctrl),
+	}
+	svc := service.NewOrganizationService(deps.orgs, deps.memberships, deps.users, deps.audit, deps.notifier, testutil.Transactor())
+	return svc, deps
+}
+
+// expectMember sets up organization 5 with user 1 as a member of the given role
+func (d organizationServiceDeps) expectMember(role string) {
+	d.orgs.EXPECT().FindByID(gomock.Any(), uint(5)).Return(&domain.Organization{ID: 5, Name: "Acme"}, nil)
+	d.memberships.EXPECT().Find(gomock.Any(), uint(5), uint(1)).Return(&domain.Membership{ID: 10, OrganizationID: 5, UserID: 1, Role: role}, nil)
+}
+
+func TestOrganizationServiceCreate(t *testing.T) {
+	svc, deps := newOrganizationService(t)
+
+	deps.orgs.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, org *domain.Organization) error {
+		org.ID = 5
+		return nil
+	})
+	deps.memberships.EXPECT().Create(gomock.Any(), &domain.Membership{OrganizationID: 5, UserID: 1, Role: domain.OrgRoleOwner}).Return(nil)
+	deps.audit.EXPECT().Record(gomock.Any(), domain.AuditActionCreate, service.AuditEntityOrganization, uint(5), nil, gomock.Any())
+
+	result, err := svc.Create(context.Background(), 1, &request.CreateOrganizationRequest{Name: "Acme"})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if result.ID != 5 || result.Name != "Acme" || result.Role != domain.OrgRoleOwner {
+		t.Errorf("Create() = %+v, want organization 5 owned by the creator", result)
+	}
+}
+
+func TestOrganizationServiceGetByID(t *testing.T) {
+	t.Run("hides organizations the user is not a member of", func(t *testing.T) {
+		svc, deps := newOrganizationService(t)
+
+		deps.orgs.EXPECT().FindByID(gomock.Any(), uint(5)).Return(&domain.Organization{ID: 5}, nil)
+		deps.memberships.EXPECT().Find(gomock.Any(), uint(5), uint(1)).Return(nil, gorm.ErrRecordNotFound)
+
+		_, err := svc.GetByID(context.Background(), 1, 5)
+		if !errors.Is(err, service.ErrOrganizationNotFound) {
+			t.Fatalf("GetByID() error = %v, want %v", err, service.ErrOrganizationNotFound)
+		}
+	})
+}
+
+func TestOrganizationServiceInviteMember(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("adds the user and notifies them", func(t *testing.T) {
+		svc, deps := newOrganizationService(t)
+		deps.expectMember(domain.OrgRoleAdmin)
+
+		invitee := &domain.User{ID: 2, Email: "jane@example.com", Name: "Jane"}
+		deps.users.EXPECT().FindByEmail(gomock.Any(), invitee.Email).Return(invitee, nil)
+		deps.memberships.EXPECT().Find(gomock.Any(), uint(5), uint(2)).Return(nil, gorm.ErrRecordNotFound)
+		deps.memberships.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+		deps.audit.EXPECT().Record(gomock.Any(), domain.AuditActionCreate, service.AuditEntityMembership, gomock.Any(), nil, gomock.Any())
+		deps.users.EXPECT().FindByID(gomock.Any(), uint(1)).Return(&domain.User{ID: 1, Name: "John"}, nil)
+		deps.notifier.EXPECT().Notify(gomock.Any(), gomock.Cond(func(x interface{}) bool {
+			n, ok := x.(notification.Notification)
+			return ok && n.Type == notification.TypeOrganizationInvite && n.UserID == 2
+		}))
+
+		result, err := svc.InviteMember(ctx, 1, 5, &request.InviteMemberRequest{Email: invitee.Email, Role: domain.OrgRoleMember})
+		if err != nil {
+			t.Fatalf("InviteMember() error = %v", err)
+		}
+		if result.UserID != 2 || result.Role != domain.OrgRoleMember {
+			t.Errorf("InviteMember() = %+v, want user 2 as a member", result)
+		}
+	})
+
+	t.Run("rejects members inviting", func(t *testing.T) {
+		svc, deps := newOrganizationService(t)
+		deps.expectMember(domain.OrgRoleMember)
+
+		_, err := svc.InviteMember(ctx, 1, 5, &request.InviteMemberRequest{Email: "jane@example.com", Role: domain.OrgRoleMember})
+		if !errors.Is(err, service.ErrNotOrganizationAdmin) {
+			t.Fatalf("InviteMember() error = %v, want %v", err, service.ErrNotOrganizationAdmin)
+		}
+	})
+
+	t.Run("rejects admins inviting owners", func(t *testing.T) {
+		svc, deps := newOrganizationService(t)
+		deps.expectMember(domain.OrgRoleAdmin)
+
+		_, err := svc.InviteMember(ctx, 1, 5, &request.InviteMemberRequest{Email: "jane@example.com", Role: domain.OrgRoleOwner})
+		if !errors.Is(err, service.ErrNotOrganizationAdmin) {
+			t.Fatalf("InviteMember() error = %v, want %v", err, service.ErrNotOrganizationAdmin)
+		}
+	})
+
+	t.Run("rejects existing members", func(t *testing.T) {
+		svc, deps := newOrganizationService(t)
+		deps.expectMember(domain.OrgRoleOwner)
+
+		deps.users.EXPECT().FindByEmail(gomock.Any(), "jane@example.com").Return(&domain.User{ID: 2}, nil)
+		deps.memberships.EXPECT().Find(gomock.Any(), uint(5), uint(2)).Return(&domain.Membership{ID: 11}, nil)
+
+		_, err := svc.InviteMember(ctx, 1, 5, &request.InviteMemberRequest{Email: "jane@example.com", Role: domain.OrgRoleMember})
+		if !errors.Is(err, service.ErrAlreadyMember) {
+			t.Fatalf("InviteMember() error = %v, want %v", err, service.ErrAlreadyMember)
+		}
+	})
+}
+
+func TestOrganizationServiceUpdateMemberRole(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("changes the role", func(t *testing.T) {
+		svc, deps := newOrganizationService(t)
+		deps.expectMember(domain.OrgRoleAdmin)
+
+		deps.memberships.EXPECT().Find(gomock.Any(), uint(5), uint(2)).Return(&domain.Membership{ID: 11, OrganizationID: 5, UserID: 2, Role: domain.OrgRoleMember}, nil)
+		deps.users.EXPECT().FindByID(gomock.Any(), uint(2)).Return(&domain.User{ID: 2}, nil)
+		deps.memberships.EXPECT().UpdateRole(gomock.Any(), uint(11), domain.OrgRoleAdmin).Return(nil)
+		deps.audit.EXPECT().Record(gomock.Any(), domain.AuditActionUpdate, service.AuditEntityMembership, uint(11), gomock.Any(), gomock.Any())
+
+		result, err := svc.UpdateMemberRole(ctx, 1, 5, 2, &request.UpdateMemberRoleRequest{Role: domain.OrgRoleAdmin})
+		if err != nil {
+			t.Fatalf("UpdateMemberRole() error = %v", err)
+		}
+		if result.Role != domain.OrgRoleAdmin {
+			t.Errorf("UpdateMemberRole() role = %s, want %s", result.Role, domain.OrgRoleAdmin)
+		}
+	})
+
+	t.Run("rejects admins demoting owners", func(t *testing.T) {
+		svc, deps := newOrganizationService(t)
+		deps.expectMember(domain.OrgRoleAdmin)
+
+		deps.memberships.EXPECT().Find(gomock.Any(), uint(5), uint(2)).Return(&domain.Membership{ID: 11, Role: domain.OrgRoleOwner}, nil)
+
+		_, err := svc.UpdateMemberRole(ctx, 1, 5, 2, &request.UpdateMemberRoleRequest{Role: domain.OrgRoleMember})
+		if !errors.Is(err, service.ErrNotOrganizationAdmin) {
+			t.Fatalf("UpdateMemberRole() error = %v, want %v", err, service.ErrNotOrganizationAdmin)
+		}
+	})
+
+	t.Run("keeps the last owner", func(t *testing.T) {
+		svc, deps := newOrganizationService(t)
+		deps.expectMember(domain.OrgRoleOwner)
+
+		deps.memberships.EXPECT().Find(gomock.Any(), uint(5), uint(1)).Return(&domain.Membership{ID: 10, OrganizationID: 5, UserID: 1, Role: domain.OrgRoleOwner}, nil)
+		deps.users.EXPECT().FindByID(gomock.Any(), uint(1)).Return(&domain.User{ID: 1}, nil)
+		deps.memberships.EXPECT().CountByRole(gomock.Any(), uint(5), domain.OrgRoleOwner).Return(int64(1), nil)
+
+		_, err := svc.UpdateMemberRole(ctx, 1, 5, 1, &request.UpdateMemberRoleRequest{Role: domain.OrgRoleAdmin})
+		if !errors.Is(err, service.ErrLastOwner) {
+			t.Fatalf("UpdateMemberRole() error = %v, want %v", err, service.ErrLastOwner)
+		}
+	})
+
+	t.Run("reports a missing member", func(t *testing.T) {
+		svc, deps := newOrganizationService(t)
+		deps.expectMember(domain.OrgRoleOwner)
+
+		deps.memberships.EXPECT().Find(gomock.Any(), uint(5), uint(9)).Return(nil, gorm.ErrRecordNotFound)
+
+		_, err := svc.UpdateMemberRole(ctx, 1, 5, 9, &request.UpdateMemberRoleRequest{Role: domain.OrgRoleAdmin})
+		if !errors.Is(err, service.ErrMemberNotFound) {
+			t.Fatalf("UpdateMemberRole() error = %v, want %v", err, service.ErrMemberNotFound)
+		}
+	})
+}
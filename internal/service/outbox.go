@@ -0,0 +1,25 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/repository"
+)
+
+// enqueue stores event in the outbox for the relay to publish to the broker.
+// Call it with the context of the transaction that stores the change, so the
+// event is kept exactly when the change is.
+func enqueue(ctx context.Context, outbox repository.OutboxRepository, event domain.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return outbox.Create(ctx, &domain.OutboxMessage{
+		Topic:   event.Type,
+		Key:     strconv.FormatUint(uint64(event.UserID), 10),
+		Payload: payload,
+	})
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/repository"
+	"github.com/firdanbash/go-clean-boiler/pkg/password"
+)
+
+// PasswordHistoryService keeps the last passwords of every user so they are
+// not set again
+type PasswordHistoryService interface {
+	Check(ctx context.Context, user *domain.User, password string) error
+	Record(ctx context.Context, user *domain.User) error
+	Prune(ctx context.Context) (int64, error)
+}
+
+type passwordHistoryService struct {
+	repo   repository.PasswordHistoryRepository
+	hasher password.Hasher
+	size   int
+}
+
+// NewPasswordHistoryService creates a password history remembering size
+// passwords per user, the current one included. A size of 0 disables it.
+func NewPasswordHistoryService(repo repository.PasswordHistoryRepository, hasher password.Hasher, size int) PasswordHistoryService {
+	return &passwordHistoryService{repo: repo, hasher: hasher, size: size}
+}
+
+// Check returns ErrPasswordReused when password is the current password of
+// user or one of the passwords it had before
+func (s *passwordHistoryService) Check(ctx context.Context, user *domain.User, plain string) error {
+	if s.size <= 0 {
+		return nil
+	}
+
+	entries, err := s.repo.FindRecent(ctx, user.ID, s.size)
+	if err != nil {
+		return err
+	}
+
+	// The current password predates the history for users created before it
+	hashes := []string{user.Password}
+	for _, entry := range entries {
+		if entry.PasswordHash != user.Password {
+			hashes = append(hashes, entry.PasswordHash)
+		}
+	}
+	for _, hash := range hashes {
+		match, err := s.hasher.Verify(hash, plain)
+		if err != nil {
+			return err
+		}
+		if match {
+			return ErrPasswordReused
+		}
+	}
+	return nil
+}
+
+// Record adds the current password of user to its history
+func (s *passwordHistoryService) Record(ctx context.Context, user *domain.User) error {
+	if s.size <= 0 {
+		return nil
+	}
+	return s.repo.Create(ctx, &domain.PasswordHistory{UserID: user.ID, PasswordHash: user.Password})
+}
+
+// Prune forgets the passwords beyond the history size of every user, and all
+// of them when the history is disabled
+func (s *passwordHistoryService) Prune(ctx context.Context) (int64, error) {
+	return s.repo.Prune(ctx, max(s.size, 0))
+}
@@ -0,0 +1,98 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/mocks"
+	"github.com/firdanbash/go-clean-boiler/internal/service"
+	"github.com/firdanbash/go-clean-boiler/internal/testutil"
+	"github.com/firdanbash/go-clean-boiler/pkg/password"
+	"go.uber.org/mock/gomock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testHasher hashes passwords quickly, with the lowest bcrypt cost
+var testHasher = password.NewBcryptHasher(bcrypt.MinCost)
+
+func passwordHash(t *testing.T, plain string) string {
+	t.Helper()
+	hash, err := testHasher.Hash(plain)
+	if err != nil {
+		t.Fatalf("hash password: %v", err)
+	}
+	return hash
+}
+
+func TestPasswordHistoryServiceCheck(t *testing.T) {
+	ctx := context.Background()
+	user := testutil.NewUser(testutil.WithID(1), testutil.WithPassword("current1"))
+	history := []domain.PasswordHistory{
+		{UserID: 1, PasswordHash: user.Password},
+		{UserID: 1, PasswordHash: passwordHash(t, "previous1")},
+	}
+
+	tests := []struct {
+		name     string
+		password string
+		want     error
+	}{
+		{"rejects the current password", "current1", service.ErrPasswordReused},
+		{"rejects a previous password", "previous1", service.ErrPasswordReused},
+		{"accepts a new password", "brandnew1", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewMockPasswordHistoryRepository(gomock.NewController(t))
+			repo.EXPECT().FindRecent(gomock.Any(), uint(1), 5).Return(history, nil)
+
+			svc := service.NewPasswordHistoryService(repo, testHasher, 5)
+			if err := svc.Check(ctx, user, tt.password); !errors.Is(err, tt.want) {
+				t.Fatalf("Check() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	t.Run("checks the current password of users without history", func(t *testing.T) {
+		repo := mocks.NewMockPasswordHistoryRepository(gomock.NewController(t))
+		repo.EXPECT().FindRecent(gomock.Any(), uint(1), 5).Return(nil, nil)
+
+		svc := service.NewPasswordHistoryService(repo, testHasher, 5)
+		if err := svc.Check(ctx, user, "current1"); !errors.Is(err, service.ErrPasswordReused) {
+			t.Fatalf("Check() error = %v, want %v", err, service.ErrPasswordReused)
+		}
+	})
+
+	t.Run("accepts anything when disabled", func(t *testing.T) {
+		repo := mocks.NewMockPasswordHistoryRepository(gomock.NewController(t))
+
+		svc := service.NewPasswordHistoryService(repo, testHasher, 0)
+		if err := svc.Check(ctx, user, "current1"); err != nil {
+			t.Fatalf("Check() error = %v", err)
+		}
+	})
+}
+
+func TestPasswordHistoryServiceRecord(t *testing.T) {
+	ctx := context.Background()
+	user := testutil.NewUser(testutil.WithID(1), testutil.WithPassword("current1"))
+
+	t.Run("stores the current password hash", func(t *testing.T) {
+		repo := mocks.NewMockPasswordHistoryRepository(gomock.NewController(t))
+		repo.EXPECT().Create(gomock.Any(), &domain.PasswordHistory{UserID: 1, PasswordHash: user.Password}).Return(nil)
+
+		if err := service.NewPasswordHistoryService(repo, testHasher, 5).Record(ctx, user); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	})
+
+	t.Run("stores nothing when disabled", func(t *testing.T) {
+		repo := mocks.NewMockPasswordHistoryRepository(gomock.NewController(t))
+
+		if err := service.NewPasswordHistoryService(repo, testHasher, 0).Record(ctx, user); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	})
+}
@@ -0,0 +1,12 @@
+package service
+
+import "github.com/firdanbash/go-clean-boiler/pkg/authz"
+
+// PolicyEnforcer checks and lists the casbin authorization policies, as
+// *casbin.SyncedEnforcer does
+type PolicyEnforcer interface {
+	authz.Enforcer
+	// GetImplicitPermissionsForUser lists the policies of a subject,
+	// including those of the subjects it is grouped with, as sub, obj, act
+	GetImplicitPermissionsForUser(user string, domain ...string) ([][]string, error)
+}
@@ -0,0 +1,401 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"regexp"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/dto/request"
+	"github.com/firdanbash/go-clean-boiler/internal/dto/response"
+	"github.com/firdanbash/go-clean-boiler/internal/repository"
+	"github.com/firdanbash/go-clean-boiler/pkg/tracing"
+	"gorm.io/gorm"
+)
+
+// Audit log entity types of roles and permissions
+const (
+	AuditEntityRole       = "role"
+	AuditEntityPermission = "permission"
+)
+
+// permissionName matches "resource:action" names such as "users:read"
+var permissionName = regexp.MustCompile(`^[a-z0-9_-]+:[a-z0-9_*-]+$`)
+
+type RoleService interface {
+	CreateRole(ctx context.Context, req *request.CreateRoleRequest) (*response.RoleResponse, error)
+	GetRoles(ctx context.Context) ([]response.RoleResponse, error)
+	GetRole(ctx context.Context, id uint) (*response.RoleResponse, error)
+	UpdateRole(ctx context.Context, id uint, req *request.UpdateRoleRequest) (*response.RoleResponse, error)
+	DeleteRole(ctx context.Context, id uint) error
+	CreatePermission(ctx context.Context, req *request.CreatePermissionRequest) (*response.PermissionResponse, error)
+	GetPermissions(ctx context.Context) ([]response.PermissionResponse, error)
+	DeletePermission(ctx context.Context, id uint) error
+	GetUserRoles(ctx context.Context, userID uint) ([]response.RoleResponse, error)
+	AssignRole(ctx context.Context, userID, roleID uint) ([]response.RoleResponse, error)
+	UnassignRole(ctx context.Context, userID, roleID uint) ([]response.RoleResponse, error)
+	HasPermission(ctx context.Context, userID uint, permission string) (bool, error)
+}
+
+type roleService struct {
+	roles       repository.RoleRepository
+	permissions repository.PermissionRepository
+	users       repository.UserRepository
+	audit       AuditService
+	tx          repository.Transactor
+}
+
+// NewRoleService creates a new role service
+func NewRoleService(
+	roles repository.RoleRepository,
+	permissions repository.PermissionRepository,
+	users repository.UserRepository,
+	audit AuditService,
+	tx repository.Transactor,
+) RoleService {
+	return &roleService{
+		roles:       roles,
+		permissions: permissions,
+		users:       users,
+		audit:       audit,
+		tx:          tx,
+	}
+}
+
+// CreateRole creates a role granting the named permissions
+func (s *roleService) CreateRole(ctx context.Context, req *request.CreateRoleRequest) (*response.RoleResponse, error) {
+	ctx, span := tracing.Start(ctx, "RoleService.CreateRole")
+	defer span.End()
+
+	if err := s.checkRoleName(ctx, req.Name, 0); err != nil {
+		return nil, err
+	}
+	permissions, err := s.findPermissions(ctx, req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	role := &domain.Role{Name: req.Name, Description: req.Description}
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.roles.Create(ctx, role); err != nil {
+			return err
+		}
+		return s.roles.SetPermissions(ctx, role.ID, permissionIDs(permissions))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	role.Permissions = permissions
+	created := toRoleResponse(role)
+	s.audit.Record(ctx, domain.AuditActionCreate, AuditEntityRole, role.ID, nil, created)
+	return &created, nil
+}
+
+// GetRoles lists every role
+func (s *roleService) GetRoles(ctx context.Context) ([]response.RoleResponse, error) {
+	roles, err := s.roles.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return toRoleResponses(roles), nil
+}
+
+// GetRole gets a role by ID
+func (s *roleService) GetRole(ctx context.Context, id uint) (*response.RoleResponse, error) {
+	role, err := s.findRole(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	result := toRoleResponse(role)
+	return &result, nil
+}
+
+// UpdateRole updates a role, replacing its permissions when they are given
+func (s *roleService) UpdateRole(ctx context.Context, id uint, req *request.UpdateRoleRequest) (*response.RoleResponse, error) {
+	ctx, span := tracing.Start(ctx, "RoleService.UpdateRole")
+	defer span.End()
+
+	role, err := s.findRole(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	before := toRoleResponse(role)
+
+	if req.Name != "" && req.Name != role.Name {
+		if err := s.checkRoleName(ctx, req.Name, role.ID); err != nil {
+			return nil, err
+		}
+		role.Name = req.Name
+	}
+	if req.Description != "" {
+		role.Description = req.Description
+	}
+
+	permissions := role.Permissions
+	if req.Permissions != nil {
+		if permissions, err = s.findPermissions(ctx, req.Permissions); err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.roles.Update(ctx, role); err != nil {
+			return err
+		}
+		if req.Permissions == nil {
+			return nil
+		}
+		return s.roles.SetPermissions(ctx, role.ID, permissionIDs(permissions))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	role.Permissions = permissions
+	updated := toRoleResponse(role)
+	s.audit.Record(ctx, domain.AuditActionUpdate, AuditEntityRole, role.ID, before, updated)
+	return &updated, nil
+}
+
+// DeleteRole deletes a role, taking it away from the users it was assigned to
+func (s *roleService) DeleteRole(ctx context.Context, id uint) error {
+	role, err := s.findRole(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.roles.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, domain.AuditActionDelete, AuditEntityRole, id, toRoleResponse(role), nil)
+	return nil
+}
+
+// CreatePermission creates a permission
+func (s *roleService) CreatePermission(ctx context.Context, req *request.CreatePermissionRequest) (*response.PermissionResponse, error) {
+	if !permissionName.MatchString(req.Name) {
+		return nil, ErrInvalidPermission
+	}
+	if _, err := s.permissions.FindByName(ctx, req.Name); err == nil {
+		return nil, ErrPermissionExists
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	permission := &domain.Permission{Name: req.Name, Description: req.Description}
+	if err := s.permissions.Create(ctx, permission); err != nil {
+		return nil, err
+	}
+
+	created := toPermissionResponse(permission)
+	s.audit.Record(ctx, domain.AuditActionCreate, AuditEntityPermission, permission.ID, nil, created)
+	return &created, nil
+}
+
+// GetPermissions lists every permission
+func (s *roleService) GetPermissions(ctx context.Context) ([]response.PermissionResponse, error) {
+	permissions, err := s.permissions.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]response.PermissionResponse, len(permissions))
+	for i := range permissions {
+		result[i] = toPermissionResponse(&permissions[i])
+	}
+	return result, nil
+}
+
+// DeletePermission deletes a permission, taking it away from every role
+func (s *roleService) DeletePermission(ctx context.Context, id uint) error {
+	permission, err := s.permissions.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrPermissionNotFound
+		}
+		return err
+	}
+	if err := s.permissions.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, domain.AuditActionDelete, AuditEntityPermission, id, toPermissionResponse(permission), nil)
+	return nil
+}
+
+// GetUserRoles lists the roles assigned to a user
+func (s *roleService) GetUserRoles(ctx context.Context, userID uint) ([]response.RoleResponse, error) {
+	if err := s.checkUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	roles, err := s.roles.FindByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return toRoleResponses(roles), nil
+}
+
+// AssignRole assigns a role to a user and returns the roles of the user
+func (s *roleService) AssignRole(ctx context.Context, userID, roleID uint) ([]response.RoleResponse, error) {
+	before, err := s.GetUserRoles(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.findRole(ctx, roleID); err != nil {
+		return nil, err
+	}
+
+	if err := s.roles.Assign(ctx, userID, roleID); err != nil {
+		return nil, err
+	}
+	return s.recordAssignment(ctx, userID, before)
+}
+
+// UnassignRole takes a role away from a user and returns the roles left
+func (s *roleService) UnassignRole(ctx context.Context, userID, roleID uint) ([]response.RoleResponse, error) {
+	before, err := s.GetUserRoles(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.findRole(ctx, roleID); err != nil {
+		return nil, err
+	}
+
+	removed, err := s.roles.Unassign(ctx, userID, roleID)
+	if err != nil {
+		return nil, err
+	}
+	if !removed {
+		return nil, ErrRoleNotAssigned
+	}
+	return s.recordAssignment(ctx, userID, before)
+}
+
+// HasPermission reports whether one of the roles assigned to a user grants
+// the permission
+func (s *roleService) HasPermission(ctx context.Context, userID uint, permission string) (bool, error) {
+	return s.roles.HasPermission(ctx, userID, permission)
+}
+
+// recordAssignment records the change of the roles of a user in the audit log
+// and returns their current roles
+func (s *roleService) recordAssignment(ctx context.Context, userID uint, before []response.RoleResponse) ([]response.RoleResponse, error) {
+	roles, err := s.roles.FindByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	after := toRoleResponses(roles)
+	s.audit.Record(ctx, domain.AuditActionUpdate, AuditEntityUser, userID,
+		map[string]interface{}{"roles": roleNames(before)},
+		map[string]interface{}{"roles": roleNames(after)})
+	return after, nil
+}
+
+func (s *roleService) findRole(ctx context.Context, id uint) (*domain.Role, error) {
+	role, err := s.roles.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRoleNotFound
+		}
+		return nil, err
+	}
+	return role, nil
+}
+
+// checkRoleName fails when another role than the one with exceptID has the name
+func (s *roleService) checkRoleName(ctx context.Context, name string, exceptID uint) error {
+	existing, err := s.roles.FindByName(ctx, name)
+	if err == nil && existing.ID != exceptID {
+		return ErrRoleExists
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return nil
+}
+
+// findPermissions finds the permissions with the given names, all of which must exist
+func (s *roleService) findPermissions(ctx context.Context, names []string) ([]domain.Permission, error) {
+	if len(names) == 0 {
+		return []domain.Permission{}, nil
+	}
+
+	permissions, err := s.permissions.FindByNames(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[string]bool, len(permissions))
+	for _, permission := range permissions {
+		found[permission.Name] = true
+	}
+	for _, name := range names {
+		if !found[name] {
+			return nil, ErrUnknownPermission
+		}
+	}
+	return permissions, nil
+}
+
+func (s *roleService) checkUser(ctx context.Context, userID uint) error {
+	if _, err := s.users.FindByID(ctx, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func permissionIDs(permissions []domain.Permission) []uint {
+	ids := make([]uint, len(permissions))
+	for i, permission := range permissions {
+		ids[i] = permission.ID
+	}
+	return ids
+}
+
+func roleNames(roles []response.RoleResponse) []string {
+	names := make([]string, len(roles))
+	for i, role := range roles {
+		names[i] = role.Name
+	}
+	return names
+}
+
+func toRoleResponse(role *domain.Role) response.RoleResponse {
+	permissions := make([]string, len(role.Permissions))
+	for i, permission := range role.Permissions {
+		permissions[i] = permission.Name
+	}
+
+	return response.RoleResponse{
+		ID:          role.ID,
+		Name:        role.Name,
+		Description: role.Description,
+		Permissions: permissions,
+		CreatedAt:   role.CreatedAt,
+		UpdatedAt:   role.UpdatedAt,
+	}
+}
+
+func toRoleResponses(roles []domain.Role) []response.RoleResponse {
+	result := make([]response.RoleResponse, len(roles))
+	for i := range roles {
+		result[i] = toRoleResponse(&roles[i])
+	}
+	return result
+}
+
+func toPermissionResponse(permission *domain.Permission) response.PermissionResponse {
+	return response.PermissionResponse{
+		ID:          permission.ID,
+		Name:        permission.Name,
+		Description: permission.Description,
+		CreatedAt:   permission.CreatedAt,
+	}
+}
@@ -0,0 +1,176 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/dto/request"
+	"github.com/firdanbash/go-clean-boiler/internal/mocks"
+	"github.com/firdanbash/go-clean-boiler/internal/service"
+	"github.com/firdanbash/go-clean-boiler/internal/testutil"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+)
+
+// roleServiceDeps holds the mocked dependencies of the role service under test
+type roleServiceDeps struct {
+	roles       *mocks.MockRoleRepository
+	permissions *mocks.MockPermissionRepository
+	users       *mocks.MockUserRepository
+	audit       *mocks.MockAuditService
+}
+
+func newRoleService(t *testing.T) (service.RoleService, roleServiceDeps) {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+	deps := roleServiceDeps{
+		roles:       mocks.NewMockRoleRepository(ctrl),
+		permissions: mocks.NewMockPermissionRepository(ctrl),
+		users:       mocks.NewMockUserRepository(ctrl),
+		audit:       mocks.NewMockAuditService(ctrl),
+	}
+	svc := service.NewRoleService(deps.roles, deps.permissions, deps.users, deps.audit, testutil.Transactor())
+	return svc, deps
+}
+
+func TestRoleServiceCreateRole(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("grants the named permissions", func(t *testing.T) {
+		svc, deps := newRoleService(t)
+		permissions := []domain.Permission{{ID: 1, Name: "users:read"}, {ID: 2, Name: "users:write"}}
+
+		deps.roles.EXPECT().FindByName(gomock.Any(), "editor").Return(nil, gorm.ErrRecordNotFound)
+		deps.permissions.EXPECT().FindByNames(gomock.Any(), []string{"users:read", "users:write"}).Return(permissions, nil)
+		deps.roles.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, role *domain.Role) error {
+			role.ID = 4
+			return nil
+		})
+		deps.roles.EXPECT().SetPermissions(gomock.Any(), uint(4), []uint{1, 2}).Return(nil)
+		deps.audit.EXPECT().Record(gomock.Any(), domain.AuditActionCreate, service.AuditEntityRole, uint(4), nil, gomock.Any())
+
+		result, err := svc.CreateRole(ctx, &request.CreateRoleRequest{Name: "editor", Permissions: []string{"users:read", "users:write"}})
+		if err != nil {
+			t.Fatalf("CreateRole() error = %v", err)
+		}
+		if result.ID != 4 || len(result.Permissions) != 2 {
+			t.Errorf("CreateRole() = %+v, want role 4 with 2 permissions", result)
+		}
+	})
+
+	t.Run("rejects unknown permissions", func(t *testing.T) {
+		svc, deps := newRoleService(t)
+
+		deps.roles.EXPECT().FindByName(gomock.Any(), "editor").Return(nil, gorm.ErrRecordNotFound)
+		deps.permissions.EXPECT().FindByNames(gomock.Any(), []string{"users:read", "users:fly"}).
+			Return([]domain.Permission{{ID: 1, Name: "users:read"}}, nil)
+
+		_, err := svc.CreateRole(ctx, &request.CreateRoleRequest{Name: "editor", Permissions: []string{"users:read", "users:fly"}})
+		if !errors.Is(err, service.ErrUnknownPermission) {
+			t.Fatalf("CreateRole() error = %v, want %v", err, service.ErrUnknownPermission)
+		}
+	})
+
+	t.Run("rejects a taken name", func(t *testing.T) {
+		svc, deps := newRoleService(t)
+
+		deps.roles.EXPECT().FindByName(gomock.Any(), "editor").Return(&domain.Role{ID: 2, Name: "editor"}, nil)
+
+		_, err := svc.CreateRole(ctx, &request.CreateRoleRequest{Name: "editor"})
+		if !errors.Is(err, service.ErrRoleExists) {
+			t.Fatalf("CreateRole() error = %v, want %v", err, service.ErrRoleExists)
+		}
+	})
+}
+
+func TestRoleServiceUpdateRole(t *testing.T) {
+	t.Run("keeps the permissions when none are given", func(t *testing.T) {
+		svc, deps := newRoleService(t)
+		role := &domain.Role{ID: 4, Name: "editor", Permissions: []domain.Permission{{ID: 1, Name: "users:read"}}}
+
+		deps.roles.EXPECT().FindByID(gomock.Any(), uint(4)).Return(role, nil)
+		deps.roles.EXPECT().Update(gomock.Any(), role).Return(nil)
+		deps.audit.EXPECT().Record(gomock.Any(), domain.AuditActionUpdate, service.AuditEntityRole, uint(4), gomock.Any(), gomock.Any())
+
+		result, err := svc.UpdateRole(context.Background(), 4, &request.UpdateRoleRequest{Description: "Edits users"})
+		if err != nil {
+			t.Fatalf("UpdateRole() error = %v", err)
+		}
+		if result.Description != "Edits users" || len(result.Permissions) != 1 {
+			t.Errorf("UpdateRole() = %+v, want the new description and the old permission", result)
+		}
+	})
+}
+
+func TestRoleServiceCreatePermission(t *testing.T) {
+	svc, _ := newRoleService(t)
+
+	_, err := svc.CreatePermission(context.Background(), &request.CreatePermissionRequest{Name: "Users Read"})
+	if !errors.Is(err, service.ErrInvalidPermission) {
+		t.Fatalf("CreatePermission() error = %v, want %v", err, service.ErrInvalidPermission)
+	}
+}
+
+func TestRoleServiceAssignRole(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("assigns the role", func(t *testing.T) {
+		svc, deps := newRoleService(t)
+		editor := domain.Role{ID: 4, Name: "editor"}
+
+		deps.users.EXPECT().FindByID(gomock.Any(), uint(3)).Return(&domain.User{ID: 3}, nil)
+		deps.roles.EXPECT().FindByUser(gomock.Any(), uint(3)).Return(nil, nil)
+		deps.roles.EXPECT().FindByID(gomock.Any(), uint(4)).Return(&editor, nil)
+		deps.roles.EXPECT().Assign(gomock.Any(), uint(3), uint(4)).Return(nil)
+		deps.roles.EXPECT().FindByUser(gomock.Any(), uint(3)).Return([]domain.Role{editor}, nil)
+		deps.audit.EXPECT().Record(gomock.Any(), domain.AuditActionUpdate, service.AuditEntityUser, uint(3), gomock.Any(), gomock.Any())
+
+		roles, err := svc.AssignRole(ctx, 3, 4)
+		if err != nil {
+			t.Fatalf("AssignRole() error = %v", err)
+		}
+		if len(roles) != 1 || roles[0].Name != "editor" {
+			t.Errorf("AssignRole() = %+v, want the editor role", roles)
+		}
+	})
+
+	t.Run("reports a missing user", func(t *testing.T) {
+		svc, deps := newRoleService(t)
+
+		deps.users.EXPECT().FindByID(gomock.Any(), uint(9)).Return(nil, gorm.ErrRecordNotFound)
+
+		_, err := svc.AssignRole(ctx, 9, 4)
+		if !errors.Is(err, service.ErrUserNotFound) {
+			t.Fatalf("AssignRole() error = %v, want %v", err, service.ErrUserNotFound)
+		}
+	})
+
+	t.Run("reports a missing role", func(t *testing.T) {
+		svc, deps := newRoleService(t)
+
+		deps.users.EXPECT().FindByID(gomock.Any(), uint(3)).Return(&domain.User{ID: 3}, nil)
+		deps.roles.EXPECT().FindByUser(gomock.Any(), uint(3)).Return(nil, nil)
+		deps.roles.EXPECT().FindByID(gomock.Any(), uint(8)).Return(nil, gorm.ErrRecordNotFound)
+
+		_, err := svc.AssignRole(ctx, 3, 8)
+		if !errors.Is(err, service.ErrRoleNotFound) {
+			t.Fatalf("AssignRole() error = %v, want %v", err, service.ErrRoleNotFound)
+		}
+	})
+}
+
+func TestRoleServiceUnassignRole(t *testing.T) {
+	svc, deps := newRoleService(t)
+
+	deps.users.EXPECT().FindByID(gomock.Any(), uint(3)).Return(&domain.User{ID: 3}, nil)
+	deps.roles.EXPECT().FindByUser(gomock.Any(), uint(3)).Return(nil, nil)
+	deps.roles.EXPECT().FindByID(gomock.Any(), uint(4)).Return(&domain.Role{ID: 4}, nil)
+	deps.roles.EXPECT().Unassign(gomock.Any(), uint(3), uint(4)).Return(false, nil)
+
+	_, err := svc.UnassignRole(context.Background(), 3, 4)
+	if !errors.Is(err, service.ErrRoleNotAssigned) {
+		t.Fatalf("UnassignRole() error = %v, want %v", err, service.ErrRoleNotAssigned)
+	}
+}
@@ -0,0 +1,20 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/firdanbash/go-clean-boiler/pkg/search"
+)
+
+// SearchEngine indexes and searches documents, implemented by *search.Client.
+// It is nil when search is disabled.
+type SearchEngine interface {
+	Ping(ctx context.Context) error
+	EnsureIndex(ctx context.Context, index string, definition json.RawMessage) error
+	DeleteIndex(ctx context.Context, index string) error
+	Put(ctx context.Context, index, id string, doc interface{}) error
+	Delete(ctx context.Context, index, id string) error
+	Bulk(ctx context.Context, index string, docs []search.Document) error
+	Search(ctx context.Context, index string, q search.Query) (*search.Result, error)
+}
@@ -0,0 +1,276 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/dto/request"
+	"github.com/firdanbash/go-clean-boiler/internal/dto/response"
+	"github.com/firdanbash/go-clean-boiler/internal/event"
+	"github.com/firdanbash/go-clean-boiler/internal/repository"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"github.com/firdanbash/go-clean-boiler/pkg/reqctx"
+	"github.com/firdanbash/go-clean-boiler/pkg/search"
+	"github.com/firdanbash/go-clean-boiler/pkg/tracing"
+	"go.uber.org/zap"
+)
+
+// UserSearchIndex is the index users are searched in, named in the cluster
+// with search.index_prefix
+const UserSearchIndex = "users"
+
+// searchBatchSize is the page size users are reindexed in
+const searchBatchSize = 500
+
+// maxSearchWindow is how deep results can be paged, the default
+// index.max_result_window of Elasticsearch and OpenSearch
+const maxSearchWindow = 10000
+
+// userIndexDefinition declares the settings and mappings of the user index.
+// Names and emails are split into lowercase words of letters and digits, so
+// that "jo do" matches John Doe and john.doe@example.com alike.
+var userIndexDefinition = json.RawMessage(`{
+  "settings": {
+    "analysis": {
+      "tokenizer": {
+        "words": {"type": "pattern", "pattern": "[^\\p{L}\\p{N}]+"}
+      },
+      "analyzer": {
+        "words": {"type": "custom", "tokenizer": "words", "filter": ["lowercase", "asciifolding"]}
+      }
+    }
+  },
+  "mappings": {
+    "dynamic": "strict",
+    "properties": {
+      "tenant_id": {"type": "long"},
+      "name": {"type": "text", "analyzer": "words"},
+      "email": {"type": "text", "analyzer": "words"},
+      "role": {"type": "keyword"},
+      "created_at": {"type": "date"}
+    }
+  }
+}`)
+
+// userSearchFields are the fields the text of a search matches, a name
+// match counting twice an email match
+var userSearchFields = []string{"name^2", "email"}
+
+// userDocument is the indexed form of a user
+type userDocument struct {
+	TenantID  uint      `json:"tenant_id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type SearchService interface {
+	SearchUsers(ctx context.Context, req *request.SearchUsersRequest) ([]response.UserSearchHit, int64, error)
+	IndexUser(ctx context.Context, user *response.UserResponse) error
+	RemoveUser(ctx context.Context, userID uint) error
+	ReindexUsers(ctx context.Context, recreate bool) (int, error)
+	EnsureIndexes(ctx context.Context) error
+}
+
+type searchService struct {
+	engine   SearchEngine
+	userRepo repository.UserRepository
+	log      logger.Logger
+	ensured  atomic.Bool // the indexes are known to exist
+}
+
+// NewSearchService creates a new search service. With a nil engine searches
+// fail with ErrSearchDisabled and indexing does nothing.
+func NewSearchService(engine SearchEngine, userRepo repository.UserRepository, log logger.Logger) SearchService {
+	return &searchService{engine: engine, userRepo: userRepo, log: log}
+}
+
+// RegisterSearchIndexing keeps the user index up to date with the events
+// dispatched by the user service, in the background. Nothing is registered
+// when search is disabled.
+func RegisterSearchIndexing(bus *event.Bus, engine SearchEngine, search SearchService) {
+	if engine == nil {
+		return
+	}
+	event.OnAsync(bus, func(ctx context.Context, e event.UserRegistered) error {
+		return search.IndexUser(ctx, e.User)
+	})
+	event.OnAsync(bus, func(ctx context.Context, e event.UserUpdated) error {
+		return search.IndexUser(ctx, e.User)
+	})
+	event.OnAsync(bus, func(ctx context.Context, e event.UserDeleted) error {
+		return search.RemoveUser(ctx, e.User.ID)
+	})
+}
+
+// SearchUsers finds the users matching the query and filters, best matches
+// first, in the tenant of the context. The users are read back from the
+// database, so users deleted since they were indexed are left out.
+func (s *searchService) SearchUsers(ctx context.Context, req *request.SearchUsersRequest) ([]response.UserSearchHit, int64, error) {
+	ctx, span := tracing.Start(ctx, "SearchService.SearchUsers")
+	defer span.End()
+
+	if s.engine == nil {
+		return nil, 0, ErrSearchDisabled
+	}
+	offset := (req.Page - 1) * req.PerPage
+	if offset+req.PerPage > maxSearchWindow {
+		return nil, 0, ErrSearchTooDeep
+	}
+
+	q := search.Query{
+		Text:      req.Query,
+		Fields:    userSearchFields,
+		Highlight: []string{"name", "email"},
+		From:      offset,
+		Size:      req.PerPage,
+	}
+	if tenantID, ok := reqctx.TenantID(ctx); ok {
+		q.Filters = append(q.Filters, search.Term("tenant_id", tenantID))
+	}
+	if req.Role != "" {
+		q.Filters = append(q.Filters, search.Term("role", req.Role))
+	}
+	if !req.CreatedFrom.IsZero() || !req.CreatedTo.IsZero() {
+		var from, to interface{}
+		if !req.CreatedFrom.IsZero() {
+			from = req.CreatedFrom
+		}
+		if !req.CreatedTo.IsZero() {
+			// created_to is an inclusive date
+			to = req.CreatedTo.AddDate(0, 0, 1)
+		}
+		q.Filters = append(q.Filters, search.Range("created_at", from, to))
+	}
+
+	result, err := s.engine.Search(ctx, UserSearchIndex, q)
+	if err != nil {
+		// Nothing was indexed yet
+		if search.IsIndexNotFound(err) {
+			return []response.UserSearchHit{}, 0, nil
+		}
+		return nil, 0, err
+	}
+
+	ids := make([]uint, 0, len(result.Hits))
+	for _, hit := range result.Hits {
+		if id, err := strconv.ParseUint(hit.ID, 10, 64); err == nil {
+			ids = append(ids, uint(id))
+		}
+	}
+	users, err := s.userRepo.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, 0, err
+	}
+	byID := make(map[string]*domain.User, len(users))
+	for i := range users {
+		byID[strconv.FormatUint(uint64(users[i].ID), 10)] = &users[i]
+	}
+
+	hits := make([]response.UserSearchHit, 0, len(result.Hits))
+	for _, hit := range result.Hits {
+		user, ok := byID[hit.ID]
+		if !ok {
+			continue
+		}
+		hits = append(hits, response.UserSearchHit{User: *toUserResponse(user), Score: hit.Score, Highlight: hit.Highlight})
+	}
+	return hits, result.Total, nil
+}
+
+// IndexUser indexes user, in the tenant of the context
+func (s *searchService) IndexUser(ctx context.Context, user *response.UserResponse) error {
+	if s.engine == nil {
+		return nil
+	}
+	ctx, span := tracing.Start(ctx, "SearchService.IndexUser")
+	defer span.End()
+
+	// The cluster would otherwise create the index from the document, without its definition
+	if err := s.EnsureIndexes(ctx); err != nil {
+		return err
+	}
+
+	tenantID, _ := reqctx.TenantID(ctx)
+	doc := userDocument{TenantID: tenantID, Name: user.Name, Email: user.Email, Role: user.Role, CreatedAt: user.CreatedAt}
+	return s.engine.Put(ctx, UserSearchIndex, userDocumentID(user.ID), doc)
+}
+
+// RemoveUser removes a user from the index
+func (s *searchService) RemoveUser(ctx context.Context, userID uint) error {
+	if s.engine == nil {
+		return nil
+	}
+	ctx, span := tracing.Start(ctx, "SearchService.RemoveUser")
+	defer span.End()
+
+	return s.engine.Delete(ctx, UserSearchIndex, userDocumentID(userID))
+}
+
+// ReindexUsers indexes every user of every tenant, e.g. once search is
+// enabled or to catch up with events missed while the cluster was down. With
+// recreate the index is dropped first, which also applies a changed
+// definition and drops the users deleted in the meantime, but leaves searches
+// short of results until the reindex is done. It returns the users indexed.
+func (s *searchService) ReindexUsers(ctx context.Context, recreate bool) (int, error) {
+	ctx, span := tracing.Start(ctx, "SearchService.ReindexUsers")
+	defer span.End()
+
+	if s.engine == nil {
+		return 0, ErrSearchDisabled
+	}
+	if recreate {
+		if err := s.engine.DeleteIndex(ctx, UserSearchIndex); err != nil {
+			return 0, err
+		}
+		s.ensured.Store(false)
+	}
+	if err := s.EnsureIndexes(ctx); err != nil {
+		return 0, err
+	}
+
+	indexed := 0
+	err := s.userRepo.FindAllInBatches(ctx, repository.UserFilter{}, searchBatchSize, func(users []domain.User) error {
+		docs := make([]search.Document, len(users))
+		for i, user := range users {
+			docs[i] = search.Document{
+				ID:     userDocumentID(user.ID),
+				Source: userDocument{TenantID: user.TenantID, Name: user.Name, Email: user.Email, Role: user.Role, CreatedAt: user.CreatedAt},
+			}
+		}
+		if err := s.engine.Bulk(ctx, UserSearchIndex, docs); err != nil {
+			return err
+		}
+		indexed += len(users)
+		return nil
+	})
+	if err != nil {
+		return indexed, err
+	}
+
+	logger.Ctx(ctx, s.log).Info("Users reindexed", zap.Int("count", indexed))
+	return indexed, nil
+}
+
+// EnsureIndexes creates the indexes missing from the cluster. Once they
+// exist it does nothing.
+func (s *searchService) EnsureIndexes(ctx context.Context) error {
+	if s.engine == nil || s.ensured.Load() {
+		return nil
+	}
+	if err := s.engine.EnsureIndex(ctx, UserSearchIndex, userIndexDefinition); err != nil {
+		return err
+	}
+	s.ensured.Store(true)
+	return nil
+}
+
+// userDocumentID is the ID of the document of a user
+func userDocumentID(userID uint) string {
+	return strconv.FormatUint(uint64(userID), 10)
+}
@@ -0,0 +1,195 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/dto/request"
+	"github.com/firdanbash/go-clean-boiler/internal/dto/response"
+	"github.com/firdanbash/go-clean-boiler/internal/mocks"
+	"github.com/firdanbash/go-clean-boiler/internal/repository"
+	"github.com/firdanbash/go-clean-boiler/internal/service"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"github.com/firdanbash/go-clean-boiler/pkg/reqctx"
+	"github.com/firdanbash/go-clean-boiler/pkg/search"
+	"go.uber.org/mock/gomock"
+)
+
+func newSearchService(t *testing.T) (service.SearchService, *mocks.MockSearchEngine, *mocks.MockUserRepository) {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+	engine := mocks.NewMockSearchEngine(ctrl)
+	users := mocks.NewMockUserRepository(ctrl)
+	return service.NewSearchService(engine, users, logger.Nop()), engine, users
+}
+
+func TestSearchServiceSearchUsers(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("returns the hits in rank order, read back from the database", func(t *testing.T) {
+		svc, engine, users := newSearchService(t)
+		ctx := reqctx.WithTenantID(ctx, 2)
+		from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		to := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+
+		engine.EXPECT().Search(gomock.Any(), service.UserSearchIndex, gomock.Any()).DoAndReturn(
+			func(_ context.Context, _ string, q search.Query) (*search.Result, error) {
+				if q.Text != "jo" || q.From != 10 || q.Size != 10 {
+					t.Errorf("Search() query = %+v, want the text and the second page", q)
+				}
+				want := []search.Filter{
+					search.Term("tenant_id", uint(2)),
+					search.Term("role", "admin"),
+					search.Range("created_at", from, to.AddDate(0, 0, 1)),
+				}
+				if !reflect.DeepEqual(q.Filters, want) {
+					t.Errorf("Search() filters = %v, want %v", q.Filters, want)
+				}
+				return &search.Result{Total: 13, Hits: []search.Hit{
+					{ID: "7", Score: 2.5, Highlight: map[string][]string{"name": {"<em>Jo</em>hn"}}},
+					{ID: "9", Score: 1.5}, // deleted since it was indexed
+					{ID: "4", Score: 1},
+				}}, nil
+			})
+		users.EXPECT().FindByIDs(gomock.Any(), []uint{7, 9, 4}).Return([]domain.User{
+			{ID: 4, Name: "Joan"},
+			{ID: 7, Name: "John"},
+		}, nil)
+
+		hits, total, err := svc.SearchUsers(ctx, &request.SearchUsersRequest{
+			Page: 2, PerPage: 10, Query: "jo", Role: "admin", CreatedFrom: from, CreatedTo: to,
+		})
+		if err != nil {
+			t.Fatalf("SearchUsers() error = %v", err)
+		}
+		if total != 13 || len(hits) != 2 {
+			t.Fatalf("SearchUsers() = %d hits of %d, want 2 of 13", len(hits), total)
+		}
+		if hits[0].User.ID != 7 || hits[0].Score != 2.5 || hits[0].Highlight["name"][0] != "<em>Jo</em>hn" {
+			t.Errorf("SearchUsers() first hit = %+v, want user 7 with its highlight", hits[0])
+		}
+		if hits[1].User.ID != 4 {
+			t.Errorf("SearchUsers() second hit = %+v, want user 4", hits[1])
+		}
+	})
+
+	t.Run("finds nothing before anything is indexed", func(t *testing.T) {
+		svc, engine, _ := newSearchService(t)
+		engine.EXPECT().Search(gomock.Any(), service.UserSearchIndex, gomock.Any()).
+			Return(nil, &search.Error{Status: 404, Type: "index_not_found_exception"})
+
+		hits, total, err := svc.SearchUsers(ctx, &request.SearchUsersRequest{Page: 1, PerPage: 10})
+		if err != nil || total != 0 || len(hits) != 0 {
+			t.Errorf("SearchUsers() = %v, %d, %v, want no hits", hits, total, err)
+		}
+	})
+
+	t.Run("rejects pages beyond the result window", func(t *testing.T) {
+		svc, _, _ := newSearchService(t)
+
+		_, _, err := svc.SearchUsers(ctx, &request.SearchUsersRequest{Page: 101, PerPage: 100})
+		if !errors.Is(err, service.ErrSearchTooDeep) {
+			t.Errorf("SearchUsers() error = %v, want ErrSearchTooDeep", err)
+		}
+	})
+
+	t.Run("fails when search is disabled", func(t *testing.T) {
+		svc := service.NewSearchService(nil, nil, logger.Nop())
+
+		_, _, err := svc.SearchUsers(ctx, &request.SearchUsersRequest{Page: 1, PerPage: 10})
+		if !errors.Is(err, service.ErrSearchDisabled) {
+			t.Errorf("SearchUsers() error = %v, want ErrSearchDisabled", err)
+		}
+	})
+}
+
+func TestSearchServiceIndexUser(t *testing.T) {
+	t.Run("creates the index once and indexes the user in the tenant of the context", func(t *testing.T) {
+		svc, engine, _ := newSearchService(t)
+		ctx := reqctx.WithTenantID(context.Background(), 3)
+
+		engine.EXPECT().EnsureIndex(gomock.Any(), service.UserSearchIndex, gomock.Any()).Return(nil)
+		engine.EXPECT().Put(gomock.Any(), service.UserSearchIndex, "5", gomock.Any()).DoAndReturn(
+			func(_ context.Context, _, _ string, doc interface{}) error {
+				if got := toMap(t, doc); got["tenant_id"] != float64(3) || got["email"] != "jane@example.com" {
+					t.Errorf("Put() doc = %v, want tenant 3 and the email", got)
+				}
+				return nil
+			}).Times(2)
+
+		user := &response.UserResponse{ID: 5, Email: "jane@example.com", Name: "Jane", Role: domain.RoleUser}
+		for i := 0; i < 2; i++ {
+			if err := svc.IndexUser(ctx, user); err != nil {
+				t.Fatalf("IndexUser() error = %v", err)
+			}
+		}
+	})
+
+	t.Run("does not index before the index is created", func(t *testing.T) {
+		svc, engine, _ := newSearchService(t)
+		unreachable := errors.New("connection refused")
+		engine.EXPECT().EnsureIndex(gomock.Any(), service.UserSearchIndex, gomock.Any()).Return(unreachable)
+
+		if err := svc.IndexUser(context.Background(), &response.UserResponse{ID: 5}); !errors.Is(err, unreachable) {
+			t.Errorf("IndexUser() error = %v, want %v", err, unreachable)
+		}
+	})
+
+	t.Run("does nothing when search is disabled", func(t *testing.T) {
+		svc := service.NewSearchService(nil, nil, logger.Nop())
+
+		if err := svc.IndexUser(context.Background(), &response.UserResponse{ID: 5}); err != nil {
+			t.Errorf("IndexUser() error = %v", err)
+		}
+	})
+}
+
+func TestSearchServiceReindexUsers(t *testing.T) {
+	svc, engine, users := newSearchService(t)
+
+	gomock.InOrder(
+		engine.EXPECT().DeleteIndex(gomock.Any(), service.UserSearchIndex).Return(nil),
+		engine.EXPECT().EnsureIndex(gomock.Any(), service.UserSearchIndex, gomock.Any()).Return(nil),
+	)
+	users.EXPECT().FindAllInBatches(gomock.Any(), repository.UserFilter{}, gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ repository.UserFilter, _ int, fn func([]domain.User) error) error {
+			if err := fn([]domain.User{{ID: 1, TenantID: 1}, {ID: 2, TenantID: 2}}); err != nil {
+				return err
+			}
+			return fn([]domain.User{{ID: 3, TenantID: 1}})
+		})
+	engine.EXPECT().Bulk(gomock.Any(), service.UserSearchIndex, gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ string, docs []search.Document) error {
+			if docs[0].ID != "1" && docs[0].ID != "3" {
+				t.Errorf("Bulk() first document = %q", docs[0].ID)
+			}
+			return nil
+		}).Times(2)
+
+	indexed, err := svc.ReindexUsers(context.Background(), true)
+	if err != nil {
+		t.Fatalf("ReindexUsers() error = %v", err)
+	}
+	if indexed != 3 {
+		t.Errorf("ReindexUsers() = %d, want 3", indexed)
+	}
+}
+
+// toMap returns doc as it is sent to the engine
+func toMap(t *testing.T, doc interface{}) map[string]interface{} {
+	t.Helper()
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	return m
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/firdanbash/go-clean-boiler/internal/dto/response"
+	"github.com/firdanbash/go-clean-boiler/internal/repository"
+	"gorm.io/gorm"
+)
+
+type SessionService interface {
+	ListForUser(ctx context.Context, userID uint, currentTokenID string) ([]response.SessionResponse, error)
+	Revoke(ctx context.Context, userID, sessionID uint) error
+}
+
+type sessionService struct {
+	repo     repository.SessionRepository
+	userRepo repository.UserRepository
+	denylist repository.RevokedTokenRepository
+}
+
+// NewSessionService creates a new session service
+func NewSessionService(repo repository.SessionRepository, userRepo repository.UserRepository, denylist repository.RevokedTokenRepository) SessionService {
+	return &sessionService{repo: repo, userRepo: userRepo, denylist: denylist}
+}
+
+// ListForUser lists the active sessions of a user, newest first. The session
+// of currentTokenID, the token of the request, is flagged as current.
+func (s *sessionService) ListForUser(ctx context.Context, userID uint, currentTokenID string) ([]response.SessionResponse, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	sessions, err := s.repo.FindActive(ctx, userID, user.TokenVersion, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	sessionResponses := make([]response.SessionResponse, len(sessions))
+	for i, session := range sessions {
+		sessionResponses[i] = response.SessionResponse{
+			ID:        session.ID,
+			IP:        session.IP,
+			UserAgent: session.UserAgent,
+			Current:   session.TokenID == currentTokenID,
+			CreatedAt: session.CreatedAt,
+			ExpiresAt: session.ExpiresAt,
+		}
+	}
+
+	return sessionResponses, nil
+}
+
+// Revoke signs a device out by revoking the token of one of the user's sessions.
+// Revoking a session that has already ended is a no-op.
+func (s *sessionService) Revoke(ctx context.Context, userID, sessionID uint) error {
+	if err := rejectImpersonation(ctx); err != nil {
+		return err
+	}
+
+	session, err := s.repo.FindByID(ctx, userID, sessionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrSessionNotFound
+		}
+		return err
+	}
+
+	return s.denylist.Revoke(ctx, session.TokenID, session.ExpiresAt)
+}
@@ -0,0 +1,96 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/mocks"
+	"github.com/firdanbash/go-clean-boiler/internal/service"
+	"github.com/firdanbash/go-clean-boiler/internal/testutil"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+)
+
+// sessionServiceDeps holds the dependencies of the session service under test
+type sessionServiceDeps struct {
+	repo     *mocks.MockSessionRepository
+	users    *mocks.MockUserRepository
+	denylist *mocks.MockRevokedTokenRepository
+}
+
+func newSessionService(t *testing.T) (service.SessionService, sessionServiceDeps) {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+	deps := sessionServiceDeps{
+		repo:     mocks.NewMockSessionRepository(ctrl),
+		users:    mocks.NewMockUserRepository(ctrl),
+		denylist: mocks.NewMockRevokedTokenRepository(ctrl),
+	}
+	return service.NewSessionService(deps.repo, deps.users, deps.denylist), deps
+}
+
+func TestSessionServiceListForUser(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("lists the sessions of the current token version and flags the current one", func(t *testing.T) {
+		svc, deps := newSessionService(t)
+		user := testutil.NewUser(testutil.WithID(1))
+		user.TokenVersion = 2
+		deps.users.EXPECT().FindByID(gomock.Any(), uint(1)).Return(user, nil)
+		deps.repo.EXPECT().FindActive(gomock.Any(), uint(1), uint(2), gomock.Any()).Return([]domain.Session{
+			{ID: 7, UserID: 1, TokenID: "phone", UserAgent: "Mobile Safari"},
+			{ID: 5, UserID: 1, TokenID: "laptop", UserAgent: "Firefox"},
+		}, nil)
+
+		sessions, err := svc.ListForUser(ctx, 1, "laptop")
+		if err != nil {
+			t.Fatalf("ListForUser() error = %v", err)
+		}
+		if len(sessions) != 2 {
+			t.Fatalf("ListForUser() returned %d sessions, want 2", len(sessions))
+		}
+		if sessions[0].Current || !sessions[1].Current {
+			t.Errorf("current flags = %v, %v, want false, true", sessions[0].Current, sessions[1].Current)
+		}
+		if sessions[0].UserAgent != "Mobile Safari" {
+			t.Errorf("UserAgent = %q, want %q", sessions[0].UserAgent, "Mobile Safari")
+		}
+	})
+
+	t.Run("fails for an unknown user", func(t *testing.T) {
+		svc, deps := newSessionService(t)
+		deps.users.EXPECT().FindByID(gomock.Any(), uint(1)).Return(nil, gorm.ErrRecordNotFound)
+
+		if _, err := svc.ListForUser(ctx, 1, "laptop"); !errors.Is(err, service.ErrUserNotFound) {
+			t.Fatalf("ListForUser() error = %v, want %v", err, service.ErrUserNotFound)
+		}
+	})
+}
+
+func TestSessionServiceRevoke(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("revokes the token of the session until it expires", func(t *testing.T) {
+		svc, deps := newSessionService(t)
+		expiresAt := time.Now().Add(time.Hour)
+		deps.repo.EXPECT().FindByID(gomock.Any(), uint(1), uint(5)).
+			Return(&domain.Session{ID: 5, UserID: 1, TokenID: "laptop", ExpiresAt: expiresAt}, nil)
+		deps.denylist.EXPECT().Revoke(gomock.Any(), "laptop", expiresAt).Return(nil)
+
+		if err := svc.Revoke(ctx, 1, 5); err != nil {
+			t.Fatalf("Revoke() error = %v", err)
+		}
+	})
+
+	t.Run("fails for a session of another user", func(t *testing.T) {
+		svc, deps := newSessionService(t)
+		deps.repo.EXPECT().FindByID(gomock.Any(), uint(1), uint(9)).Return(nil, gorm.ErrRecordNotFound)
+
+		if err := svc.Revoke(ctx, 1, 9); !errors.Is(err, service.ErrSessionNotFound) {
+			t.Fatalf("Revoke() error = %v, want %v", err, service.ErrSessionNotFound)
+		}
+	})
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/firdanbash/go-clean-boiler/internal/dto/request"
+	"github.com/firdanbash/go-clean-boiler/internal/dto/response"
+)
+
+// RegisterTaskRunners registers the runners of the task types of the services
+func RegisterTaskRunners(tasks TaskService, users UserService) {
+	tasks.Register(TaskDeleteUsers, deleteUsersTask(users))
+}
+
+// deleteUsersTask returns the runner soft deleting the users of a
+// request.BulkDeleteUsersRequest one at a time, so that every deletion is
+// audited and published like a single one. Users that cannot be deleted, e.g.
+// because they no longer exist, are listed in the result with the reason.
+func deleteUsersTask(users UserService) TaskRunner {
+	return func(ctx context.Context, payload []byte, progress TaskProgress) (interface{}, error) {
+		var req request.BulkDeleteUsersRequest
+		if err := json.Unmarshal(payload, &req); err != nil {
+			return nil, err
+		}
+
+		result := response.BulkDeleteUsersResult{Failed: []response.BulkDeleteFailure{}}
+		for i, id := range req.IDs {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			if err := users.Delete(ctx, id); err != nil {
+				result.Failed = append(result.Failed, response.BulkDeleteFailure{ID: id, Error: err.Error()})
+			} else {
+				result.Deleted++
+			}
+			progress((i + 1) * 100 / len(req.IDs))
+		}
+		return result, nil
+	}
+}
@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/dto/response"
+	"github.com/firdanbash/go-clean-boiler/internal/event"
+	"github.com/firdanbash/go-clean-boiler/internal/repository"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"github.com/firdanbash/go-clean-boiler/pkg/tracing"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// taskTimeout is how long a task may stay pending or running before it is
+// considered lost, e.g. to a restart, and failed by the fail_stuck_tasks job
+const taskTimeout = time.Hour
+
+// Errors stored on failed tasks and shown to their owner. The cause is logged.
+const (
+	taskFailedMessage      = "The task failed"
+	taskInterruptedMessage = "The task was interrupted before it completed"
+)
+
+// Task types
+const (
+	TaskDeleteUsers = "delete_users"
+)
+
+// TaskProgress reports how far a running task is, in percent
+type TaskProgress func(percent int)
+
+// TaskRunner runs the tasks of one type. It reads the input of a task from
+// payload, the JSON it was enqueued with, reports its progress and returns its
+// result, which is stored as JSON.
+type TaskRunner func(ctx context.Context, payload []byte, progress TaskProgress) (interface{}, error)
+
+type TaskService interface {
+	Register(taskType string, runner TaskRunner)
+	Enqueue(ctx context.Context, userID uint, taskType string, payload interface{}) (*response.TaskResponse, error)
+	Get(ctx context.Context, userID, id uint) (*response.TaskResponse, error)
+	Run(ctx context.Context, id uint) error
+	FailStuck(ctx context.Context) (int64, error)
+	PurgeCompleted(ctx context.Context, before time.Time) (int64, error)
+}
+
+type taskService struct {
+	repo       repository.TaskRepository
+	dispatcher event.Dispatcher
+	log        logger.Logger
+
+	mu      sync.RWMutex
+	runners map[string]TaskRunner
+}
+
+// NewTaskService creates a new task service without runners. Tasks run in the
+// background, on the event bus, once their TaskQueued event is dispatched.
+func NewTaskService(repo repository.TaskRepository, dispatcher event.Dispatcher, log logger.Logger) TaskService {
+	return &taskService{
+		repo:       repo,
+		dispatcher: dispatcher,
+		log:        log,
+		runners:    make(map[string]TaskRunner),
+	}
+}
+
+// Register sets the runner of the tasks of a type
+func (s *taskService) Register(taskType string, runner TaskRunner) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.runners[taskType] = runner
+}
+
+// Enqueue creates a pending task of a user and starts running it in the
+// background. The payload is stored as JSON for the runner of the type.
+func (s *taskService) Enqueue(ctx context.Context, userID uint, taskType string, payload interface{}) (*response.TaskResponse, error) {
+	ctx, span := tracing.Start(ctx, "TaskService.Enqueue")
+	defer span.End()
+
+	if s.runner(taskType) == nil {
+		return nil, fmt.Errorf("no runner registered for task type %q", taskType)
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	task := &domain.Task{UserID: userID, Type: taskType, Status: domain.TaskPending, Payload: data}
+	if err := s.repo.Create(ctx, task); err != nil {
+		return nil, err
+	}
+
+	s.dispatcher.Dispatch(ctx, event.TaskQueued{TaskID: task.ID, Type: taskType})
+
+	return toTaskResponse(task), nil
+}
+
+// Get returns a task of a user
+func (s *taskService) Get(ctx context.Context, userID, id uint) (*response.TaskResponse, error) {
+	ctx, span := tracing.Start(ctx, "TaskService.Get")
+	defer span.End()
+
+	task, err := s.repo.FindByUser(ctx, userID, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTaskNotFound
+		}
+		return nil, err
+	}
+	return toTaskResponse(task), nil
+}
+
+// Run runs a pending task with the runner of its type and stores its result,
+// or a generic error when it fails. Tasks no longer pending are left as they
+// are, so a redelivered event or another instance does not run a task twice.
+func (s *taskService) Run(ctx context.Context, id uint) error {
+	ctx, span := tracing.Start(ctx, "TaskService.Run")
+	defer span.End()
+
+	task, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrTaskNotFound
+		}
+		return err
+	}
+	if task.Status != domain.TaskPending {
+		return nil
+	}
+
+	runner := s.runner(task.Type)
+	if runner == nil {
+		return s.fail(ctx, task, fmt.Errorf("no runner registered for task type %q", task.Type))
+	}
+
+	now := time.Now()
+	started, err := s.repo.Start(ctx, task.ID, now)
+	if err != nil {
+		return err
+	}
+	if !started {
+		return nil
+	}
+	task.Status = domain.TaskRunning
+	task.StartedAt = &now
+
+	result, err := s.call(ctx, runner, task)
+	if err != nil {
+		return s.fail(ctx, task, err)
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return s.fail(ctx, task, err)
+	}
+
+	completedAt := time.Now()
+	task.Status = domain.TaskSucceeded
+	task.Progress = 100
+	task.Result = data
+	task.CompletedAt = &completedAt
+	return s.repo.Update(ctx, task)
+}
+
+// FailStuck fails the tasks pending or running for longer than taskTimeout,
+// whose instance most likely stopped before completing them
+func (s *taskService) FailStuck(ctx context.Context) (int64, error) {
+	ctx, span := tracing.Start(ctx, "TaskService.FailStuck")
+	defer span.End()
+
+	return s.repo.FailStuck(ctx, time.Now().Add(-taskTimeout), taskInterruptedMessage)
+}
+
+// PurgeCompleted deletes the tasks completed before the given time
+func (s *taskService) PurgeCompleted(ctx context.Context, before time.Time) (int64, error) {
+	ctx, span := tracing.Start(ctx, "TaskService.PurgeCompleted")
+	defer span.End()
+
+	return s.repo.DeleteCompletedBefore(ctx, before)
+}
+
+// runner returns the runner of a task type, nil if none is registered
+func (s *taskService) runner(taskType string) TaskRunner {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.runners[taskType]
+}
+
+// call runs task with runner, turning a panic into an error so that the task
+// does not stay running
+func (s *taskService) call(ctx context.Context, runner TaskRunner, task *domain.Task) (result interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return runner(ctx, task.Payload, s.progress(ctx, task))
+}
+
+// progress returns the progress reporter of a running task, which stores the
+// progress when it changes. 100 percent is stored with the result.
+func (s *taskService) progress(ctx context.Context, task *domain.Task) TaskProgress {
+	return func(percent int) {
+		percent = max(percent, 0)
+		if percent >= 100 || percent == task.Progress {
+			return
+		}
+		task.Progress = percent
+		if err := s.repo.UpdateProgress(ctx, task.ID, percent); err != nil {
+			logger.Ctx(ctx, s.log).Warn("Failed to update task progress", zap.Uint("task_id", task.ID), zap.Error(err))
+		}
+	}
+}
+
+// fail marks a task as failed with a generic error, logging err, which is
+// returned. Errors of runners can hold internal details not meant for clients.
+func (s *taskService) fail(ctx context.Context, task *domain.Task, err error) error {
+	logger.Ctx(ctx, s.log).Error("Task failed", zap.Uint("task_id", task.ID), zap.String("type", task.Type), zap.Error(err))
+
+	now := time.Now()
+	task.Status = domain.TaskFailed
+	task.Error = taskFailedMessage
+	task.CompletedAt = &now
+	if updateErr := s.repo.Update(ctx, task); updateErr != nil {
+		logger.Ctx(ctx, s.log).Error("Failed to mark task as failed", zap.Uint("task_id", task.ID), zap.Error(updateErr))
+	}
+	return err
+}
+
+// toTaskResponse converts a task to its response
+func toTaskResponse(task *domain.Task) *response.TaskResponse {
+	resp := &response.TaskResponse{
+		ID:          task.ID,
+		Type:        task.Type,
+		Status:      task.Status,
+		Progress:    task.Progress,
+		Error:       task.Error,
+		CreatedAt:   task.CreatedAt,
+		StartedAt:   task.StartedAt,
+		CompletedAt: task.CompletedAt,
+	}
+	if len(task.Result) > 0 {
+		resp.Result = json.RawMessage(task.Result)
+	}
+	return resp
+}
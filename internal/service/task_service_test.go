@@ -0,0 +1,203 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/dto/request"
+	"github.com/firdanbash/go-clean-boiler/internal/event"
+	"github.com/firdanbash/go-clean-boiler/internal/mocks"
+	"github.com/firdanbash/go-clean-boiler/internal/service"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+)
+
+func newTaskService(t *testing.T) (service.TaskService, *mocks.MockTaskRepository, *mocks.MockDispatcher) {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+	repo := mocks.NewMockTaskRepository(ctrl)
+	bus := mocks.NewMockDispatcher(ctrl)
+	return service.NewTaskService(repo, bus, logger.Nop()), repo, bus
+}
+
+// storeUpdates records the states a task is updated to
+func storeUpdates(repo *mocks.MockTaskRepository) *[]domain.Task {
+	var updates []domain.Task
+	repo.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, task *domain.Task) error {
+		updates = append(updates, *task)
+		return nil
+	}).AnyTimes()
+	return &updates
+}
+
+func TestTaskServiceEnqueue(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("stores the task and queues it", func(t *testing.T) {
+		svc, repo, bus := newTaskService(t)
+		svc.Register("echo", func(context.Context, []byte, service.TaskProgress) (interface{}, error) { return nil, nil })
+
+		repo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, task *domain.Task) error {
+			if task.UserID != 1 || task.Status != domain.TaskPending || string(task.Payload) != `{"n":3}` {
+				t.Errorf("created task = %+v, want a pending task of user 1 with the payload", task)
+			}
+			task.ID = 7
+			return nil
+		})
+		bus.EXPECT().Dispatch(gomock.Any(), event.TaskQueued{TaskID: 7, Type: "echo"})
+
+		task, err := svc.Enqueue(ctx, 1, "echo", map[string]int{"n": 3})
+		if err != nil {
+			t.Fatalf("Enqueue() error = %v", err)
+		}
+		if task.ID != 7 || task.Status != domain.TaskPending {
+			t.Errorf("task = %+v, want pending task 7", task)
+		}
+	})
+
+	t.Run("rejects a type without a runner", func(t *testing.T) {
+		svc, _, _ := newTaskService(t)
+
+		if _, err := svc.Enqueue(ctx, 1, "unknown", nil); err == nil {
+			t.Fatal("Enqueue() error = nil, want an error")
+		}
+	})
+}
+
+func TestTaskServiceRun(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("stores the progress and result", func(t *testing.T) {
+		svc, repo, _ := newTaskService(t)
+		svc.Register("echo", func(_ context.Context, payload []byte, progress service.TaskProgress) (interface{}, error) {
+			progress(50)
+			progress(50)
+			return json.RawMessage(payload), nil
+		})
+		repo.EXPECT().FindByID(gomock.Any(), uint(7)).Return(&domain.Task{ID: 7, Type: "echo", Status: domain.TaskPending, Payload: []byte(`{"n":3}`)}, nil)
+		repo.EXPECT().Start(gomock.Any(), uint(7), gomock.Any()).Return(true, nil)
+		repo.EXPECT().UpdateProgress(gomock.Any(), uint(7), 50)
+		updates := storeUpdates(repo)
+
+		if err := svc.Run(ctx, 7); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		if len(*updates) != 1 || (*updates)[0].StartedAt == nil {
+			t.Fatalf("updates = %+v, want the started task succeeded", *updates)
+		}
+		done := (*updates)[0]
+		if done.Status != domain.TaskSucceeded || done.Progress != 100 || string(done.Result) != `{"n":3}` || done.CompletedAt == nil {
+			t.Errorf("task = %+v, want succeeded with the result", done)
+		}
+	})
+
+	t.Run("stores a generic error for a failed task", func(t *testing.T) {
+		svc, repo, _ := newTaskService(t)
+		svc.Register("fail", func(context.Context, []byte, service.TaskProgress) (interface{}, error) {
+			return nil, errors.New("pq: relation \"users\" does not exist")
+		})
+		repo.EXPECT().FindByID(gomock.Any(), uint(7)).Return(&domain.Task{ID: 7, Type: "fail", Status: domain.TaskPending}, nil)
+		repo.EXPECT().Start(gomock.Any(), uint(7), gomock.Any()).Return(true, nil)
+		updates := storeUpdates(repo)
+
+		if err := svc.Run(ctx, 7); err == nil {
+			t.Fatal("Run() error = nil, want the error of the runner")
+		}
+		done := (*updates)[len(*updates)-1]
+		if done.Status != domain.TaskFailed || done.Error != "The task failed" || done.CompletedAt == nil {
+			t.Errorf("task = %+v, want failed with a generic error", done)
+		}
+	})
+
+	t.Run("fails a task whose runner panics", func(t *testing.T) {
+		svc, repo, _ := newTaskService(t)
+		svc.Register("panic", func(context.Context, []byte, service.TaskProgress) (interface{}, error) {
+			panic("boom")
+		})
+		repo.EXPECT().FindByID(gomock.Any(), uint(7)).Return(&domain.Task{ID: 7, Type: "panic", Status: domain.TaskPending}, nil)
+		repo.EXPECT().Start(gomock.Any(), uint(7), gomock.Any()).Return(true, nil)
+		updates := storeUpdates(repo)
+
+		if err := svc.Run(ctx, 7); err == nil {
+			t.Fatal("Run() error = nil, want an error")
+		}
+		if done := (*updates)[len(*updates)-1]; done.Status != domain.TaskFailed {
+			t.Errorf("status = %q, want failed", done.Status)
+		}
+	})
+
+	t.Run("leaves a task started by another instance", func(t *testing.T) {
+		svc, repo, _ := newTaskService(t)
+		svc.Register("echo", func(context.Context, []byte, service.TaskProgress) (interface{}, error) {
+			t.Error("runner called for a task started elsewhere")
+			return nil, nil
+		})
+		repo.EXPECT().FindByID(gomock.Any(), uint(7)).Return(&domain.Task{ID: 7, Type: "echo", Status: domain.TaskPending}, nil)
+		repo.EXPECT().Start(gomock.Any(), uint(7), gomock.Any()).Return(false, nil)
+
+		if err := svc.Run(ctx, 7); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+	})
+
+	t.Run("leaves a task that already ran", func(t *testing.T) {
+		svc, repo, _ := newTaskService(t)
+		repo.EXPECT().FindByID(gomock.Any(), uint(7)).Return(&domain.Task{ID: 7, Type: "echo", Status: domain.TaskSucceeded}, nil)
+
+		if err := svc.Run(ctx, 7); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+	})
+}
+
+func TestTaskServiceFailStuck(t *testing.T) {
+	svc, repo, _ := newTaskService(t)
+	repo.EXPECT().FailStuck(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, before time.Time, message string) (int64, error) {
+		if age := time.Since(before); age < 59*time.Minute || age > 61*time.Minute {
+			t.Errorf("before = %v ago, want an hour ago", age)
+		}
+		return 2, nil
+	})
+
+	failed, err := svc.FailStuck(context.Background())
+	if err != nil || failed != 2 {
+		t.Fatalf("FailStuck() = %d, %v, want 2", failed, err)
+	}
+}
+
+func TestTaskServiceGet(t *testing.T) {
+	svc, repo, _ := newTaskService(t)
+	repo.EXPECT().FindByUser(gomock.Any(), uint(2), uint(7)).Return(nil, gorm.ErrRecordNotFound)
+
+	if _, err := svc.Get(context.Background(), 2, 7); !errors.Is(err, service.ErrTaskNotFound) {
+		t.Errorf("Get() error = %v, want ErrTaskNotFound", err)
+	}
+}
+
+func TestDeleteUsersTask(t *testing.T) {
+	svc, repo, _ := newTaskService(t)
+	users := mocks.NewMockUserService(gomock.NewController(t))
+	service.RegisterTaskRunners(svc, users)
+
+	payload, _ := json.Marshal(request.BulkDeleteUsersRequest{IDs: []uint{2, 3}})
+	repo.EXPECT().FindByID(gomock.Any(), uint(7)).Return(&domain.Task{ID: 7, Type: service.TaskDeleteUsers, Status: domain.TaskPending, Payload: payload}, nil)
+	repo.EXPECT().Start(gomock.Any(), uint(7), gomock.Any()).Return(true, nil)
+	repo.EXPECT().UpdateProgress(gomock.Any(), uint(7), 50)
+	updates := storeUpdates(repo)
+	users.EXPECT().Delete(gomock.Any(), uint(2)).Return(nil)
+	users.EXPECT().Delete(gomock.Any(), uint(3)).Return(service.ErrUserNotFound)
+
+	if err := svc.Run(context.Background(), 7); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	done := (*updates)[len(*updates)-1]
+	want := `{"deleted":1,"failed":[{"id":3,"error":"user not found"}]}`
+	if done.Status != domain.TaskSucceeded || string(done.Result) != want {
+		t.Errorf("task = %s %s, want succeeded with %s", done.Status, done.Result, want)
+	}
+}
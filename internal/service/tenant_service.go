@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"sync"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/dto/response"
+	"github.com/firdanbash/go-clean-boiler/internal/repository"
+	"gorm.io/gorm"
+)
+
+// tenantSlug matches a DNS label, so every slug can be used as a subdomain
+var tenantSlug = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+type TenantService interface {
+	Create(ctx context.Context, slug, name string) (*response.TenantResponse, error)
+	List(ctx context.Context) ([]response.TenantResponse, error)
+	Resolve(ctx context.Context, slug string) (uint, error)
+}
+
+type tenantService struct {
+	repo repository.TenantRepository
+	ids  sync.Map // slug: tenant ID, as tenants are never renamed or deleted
+}
+
+// NewTenantService creates a new tenant service
+func NewTenantService(repo repository.TenantRepository) TenantService {
+	return &tenantService{repo: repo}
+}
+
+// Create creates a tenant
+func (s *tenantService) Create(ctx context.Context, slug, name string) (*response.TenantResponse, error) {
+	if !tenantSlug.MatchString(slug) {
+		return nil, ErrInvalidTenantSlug
+	}
+	if _, err := s.repo.FindBySlug(ctx, slug); err == nil {
+		return nil, ErrTenantExists
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if name == "" {
+		name = slug
+	}
+	tenant := &domain.Tenant{Slug: slug, Name: name}
+	if err := s.repo.Create(ctx, tenant); err != nil {
+		return nil, err
+	}
+
+	result := toTenantResponse(tenant)
+	return &result, nil
+}
+
+// List lists every tenant
+func (s *tenantService) List(ctx context.Context) ([]response.TenantResponse, error) {
+	tenants, err := s.repo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]response.TenantResponse, len(tenants))
+	for i := range tenants {
+		result[i] = toTenantResponse(&tenants[i])
+	}
+	return result, nil
+}
+
+// Resolve returns the ID of the tenant with slug. Found tenants are cached.
+func (s *tenantService) Resolve(ctx context.Context, slug string) (uint, error) {
+	if id, ok := s.ids.Load(slug); ok {
+		return id.(uint), nil
+	}
+
+	tenant, err := s.repo.FindBySlug(ctx, slug)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, ErrTenantNotFound
+		}
+		return 0, err
+	}
+
+	s.ids.Store(slug, tenant.ID)
+	return tenant.ID, nil
+}
+
+func toTenantResponse(tenant *domain.Tenant) response.TenantResponse {
+	return response.TenantResponse{
+		ID:        tenant.ID,
+		Slug:      tenant.Slug,
+		Name:      tenant.Name,
+		CreatedAt: tenant.CreatedAt,
+	}
+}
@@ -0,0 +1,62 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/mocks"
+	"github.com/firdanbash/go-clean-boiler/internal/service"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+)
+
+func TestTenantServiceCreate(t *testing.T) {
+	ctx := context.Background()
+	repo := mocks.NewMockTenantRepository(gomock.NewController(t))
+	svc := service.NewTenantService(repo)
+
+	for _, slug := range []string{"", "Acme", "acme.corp", "-acme", "acme-"} {
+		if _, err := svc.Create(ctx, slug, ""); !errors.Is(err, service.ErrInvalidTenantSlug) {
+			t.Errorf("Create(%q) error = %v, want ErrInvalidTenantSlug", slug, err)
+		}
+	}
+
+	repo.EXPECT().FindBySlug(ctx, "taken").Return(&domain.Tenant{ID: 1, Slug: "taken"}, nil)
+	if _, err := svc.Create(ctx, "taken", ""); !errors.Is(err, service.ErrTenantExists) {
+		t.Errorf("Create() of an existing slug error = %v, want ErrTenantExists", err)
+	}
+
+	repo.EXPECT().FindBySlug(ctx, "acme").Return(nil, gorm.ErrRecordNotFound)
+	repo.EXPECT().Create(ctx, &domain.Tenant{Slug: "acme", Name: "acme"}).DoAndReturn(
+		func(_ context.Context, tenant *domain.Tenant) error {
+			tenant.ID = 2
+			return nil
+		})
+	tenant, err := svc.Create(ctx, "acme", "")
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if tenant.ID != 2 || tenant.Name != "acme" {
+		t.Errorf("Create() = %+v, want tenant 2 named after its slug", tenant)
+	}
+}
+
+func TestTenantServiceResolve(t *testing.T) {
+	ctx := context.Background()
+	repo := mocks.NewMockTenantRepository(gomock.NewController(t))
+	svc := service.NewTenantService(repo)
+
+	repo.EXPECT().FindBySlug(ctx, "acme").Return(&domain.Tenant{ID: 7, Slug: "acme"}, nil).Times(1)
+	for i := 0; i < 2; i++ {
+		if id, err := svc.Resolve(ctx, "acme"); err != nil || id != 7 {
+			t.Fatalf("Resolve() = %d, %v, want 7", id, err)
+		}
+	}
+
+	repo.EXPECT().FindBySlug(ctx, "unknown").Return(nil, gorm.ErrRecordNotFound)
+	if _, err := svc.Resolve(ctx, "unknown"); !errors.Is(err, service.ErrTenantNotFound) {
+		t.Errorf("Resolve() of an unknown slug error = %v, want ErrTenantNotFound", err)
+	}
+}
@@ -1,144 +1,762 @@
 package service
 
 import (
+	"bufio"
+	"context"
 	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/firdanbash/go-clean-boiler/internal/domain"
 	"github.com/firdanbash/go-clean-boiler/internal/dto/request"
 	"github.com/firdanbash/go-clean-boiler/internal/dto/response"
+	"github.com/firdanbash/go-clean-boiler/internal/event"
+	"github.com/firdanbash/go-clean-boiler/internal/notification"
 	"github.com/firdanbash/go-clean-boiler/internal/repository"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/firdanbash/go-clean-boiler/pkg/apperror"
+	"github.com/firdanbash/go-clean-boiler/pkg/export"
+	"github.com/firdanbash/go-clean-boiler/pkg/fieldset"
+	"github.com/firdanbash/go-clean-boiler/pkg/imageutil"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"github.com/firdanbash/go-clean-boiler/pkg/password"
+	"github.com/firdanbash/go-clean-boiler/pkg/storage"
+	"github.com/firdanbash/go-clean-boiler/pkg/tracing"
+	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
+// AvatarKeyPrefix is the storage prefix of avatars, the only public objects
+const AvatarKeyPrefix = "avatars"
+
+// avatarExtensions maps accepted avatar content types to file extensions
+var avatarExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+// MaxUserIDs is the most users that can be listed by ID at once, the
+// largest page size
+const MaxUserIDs = 100
+
+// exportBatchSize is the number of users loaded per query while exporting
+const exportBatchSize = 500
+
+// purgeBatchSize is the number of soft deleted users loaded per query while purging
+const purgeBatchSize = 100
+
+// userExportFields maps export column names to value getters
+var userExportFields = map[string]func(u *domain.User) interface{}{
+	"id":          func(u *domain.User) interface{} { return u.ID },
+	"email":       func(u *domain.User) interface{} { return u.Email },
+	"name":        func(u *domain.User) interface{} { return u.Name },
+	"avatar_url":  func(u *domain.User) interface{} { return u.AvatarURL },
+	"role":        func(u *domain.User) interface{} { return u.Role },
+	"mfa_enabled": func(u *domain.User) interface{} { return u.MFAEnabled },
+	"created_at":  func(u *domain.User) interface{} { return u.CreatedAt.Format(time.RFC3339) },
+	"updated_at":  func(u *domain.User) interface{} { return u.UpdatedAt.Format(time.RFC3339) },
+}
+
+// UserFields whitelists the fields user responses can be trimmed to with
+// fields=, mapped to their columns
+var UserFields = map[string]string{
+	"id":          "id",
+	"email":       "email",
+	"name":        "name",
+	"role":        "role",
+	"avatar_url":  "avatar_url",
+	"mfa_enabled": "mfa_enabled",
+	"created_at":  "created_at",
+	"updated_at":  "updated_at",
+	"deleted_at":  "deleted_at",
+}
+
+// defaultUserExportColumns is the column order used when no fields are requested
+var defaultUserExportColumns = []string{"id", "email", "name", "role", "avatar_url", "mfa_enabled", "created_at", "updated_at"}
+
 type UserService interface {
-	Create(req *request.CreateUserRequest) (*response.UserResponse, error)
-	GetByID(id uint) (*response.UserResponse, error)
-	GetAll(page, perPage int) ([]response.UserResponse, int64, error)
-	Update(id uint, req *request.UpdateUserRequest) (*response.UserResponse, error)
-	Delete(id uint) error
+	Create(ctx context.Context, req *request.CreateUserRequest) (*response.UserResponse, error)
+	GetByID(ctx context.Context, id uint) (*response.UserResponse, error)
+	GetByIDs(ctx context.Context, ids []uint) (map[uint]*response.UserResponse, error)
+	GetAll(ctx context.Context, req *request.ListUsersRequest) ([]response.UserResponse, int64, error)
+	Update(ctx context.Context, id uint, req *request.UpdateUserRequest) (*response.UserResponse, error)
+	Delete(ctx context.Context, id uint) error
+	ChangePassword(ctx context.Context, id uint, currentPassword, newPassword string) error
+	Restore(ctx context.Context, id uint) (*response.UserResponse, error)
+	HardDelete(ctx context.Context, id uint) error
+	PurgeDeleted(ctx context.Context, before time.Time) (int, error)
+	UpdateAvatar(ctx context.Context, id uint, file io.Reader, size int64) (*response.UserResponse, error)
+	GetAvatar(ctx context.Context, id uint, size int) ([]byte, string, error)
+	Export(ctx context.Context, req *request.ExportUsersRequest, w export.Writer) error
 }
 
 type userService struct {
-	repo repository.UserRepository
+	repo          repository.UserRepository
+	hasher        password.Hasher
+	passwords     PasswordHistoryService
+	storage       storage.Storage
+	audit         AuditService
+	events        EventPublisher
+	responses     CacheInvalidator
+	dispatcher    event.Dispatcher
+	notifier      notification.Notifier
+	tx            repository.Transactor
+	outbox        repository.OutboxRepository
+	maxAvatarSize int64
+	log           logger.Logger
 }
 
 // NewUserService creates a new user service
-func NewUserService(repo repository.UserRepository) UserService {
-	return &userService{repo: repo}
+func NewUserService(
+	repo repository.UserRepository,
+	hasher password.Hasher,
+	passwords PasswordHistoryService,
+	store storage.Storage,
+	audit AuditService,
+	events EventPublisher,
+	responses CacheInvalidator,
+	dispatcher event.Dispatcher,
+	notifier notification.Notifier,
+	tx repository.Transactor,
+	outbox repository.OutboxRepository,
+	maxAvatarSize int64,
+	log logger.Logger,
+) UserService {
+	return &userService{
+		repo:          repo,
+		hasher:        hasher,
+		passwords:     passwords,
+		storage:       store,
+		audit:         audit,
+		events:        events,
+		responses:     responses,
+		dispatcher:    dispatcher,
+		notifier:      notifier,
+		tx:            tx,
+		outbox:        outbox,
+		maxAvatarSize: maxAvatarSize,
+		log:           log,
+	}
 }
 
 // Create creates a new user
-func (s *userService) Create(req *request.CreateUserRequest) (*response.UserResponse, error) {
+func (s *userService) Create(ctx context.Context, req *request.CreateUserRequest) (*response.UserResponse, error) {
+	ctx, span := tracing.Start(ctx, "UserService.Create")
+	defer span.End()
+
 	// Check if email already exists
-	_, err := s.repo.FindByEmail(req.Email)
+	_, err := s.repo.FindByEmail(ctx, req.Email)
 	if err == nil {
-		return nil, errors.New("email already exists")
+		return nil, ErrEmailExists
 	}
 	if !errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, err
 	}
 
 	// Hash password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	hashedPassword, err := s.hasher.Hash(req.Password)
 	if err != nil {
 		return nil, err
 	}
 
+	role := req.Role
+	if role == "" {
+		role = domain.RoleUser
+	}
+
 	// Create user
 	user := &domain.User{
 		Email:    req.Email,
-		Password: string(hashedPassword),
+		Password: hashedPassword,
 		Name:     req.Name,
+		Role:     role,
 	}
 
-	if err := s.repo.Create(user); err != nil {
+	var change domain.Event
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Create(ctx, user); err != nil {
+			return err
+		}
+		if err := s.passwords.Record(ctx, user); err != nil {
+			return err
+		}
+		change = domain.NewEvent(domain.EventUserCreated, user, toUserResponse(user))
+		return enqueue(ctx, s.outbox, change)
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	return s.toUserResponse(user), nil
+	created := toUserResponse(user)
+	s.events.Publish(ctx, change)
+	s.responses.Invalidate(ctx, CacheTagUsers)
+	s.dispatcher.Dispatch(ctx, event.UserRegistered{User: created})
+
+	return created, nil
 }
 
 // GetByID gets a user by ID
-func (s *userService) GetByID(id uint) (*response.UserResponse, error) {
-	user, err := s.repo.FindByID(id)
+func (s *userService) GetByID(ctx context.Context, id uint) (*response.UserResponse, error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetByID")
+	defer span.End()
+
+	user, err := s.repo.FindByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("user not found")
+			return nil, ErrUserNotFound
 		}
 		return nil, err
 	}
 
-	return s.toUserResponse(user), nil
+	return toUserResponse(user), nil
+}
+
+// GetByIDs gets the users with the given IDs in one query, keyed by ID. IDs
+// without a user are missing from the map.
+func (s *userService) GetByIDs(ctx context.Context, ids []uint) (map[uint]*response.UserResponse, error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetByIDs")
+	defer span.End()
+
+	users, err := s.repo.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[uint]*response.UserResponse, len(users))
+	for i := range users {
+		result[users[i].ID] = toUserResponse(&users[i])
+	}
+	return result, nil
 }
 
-// GetAll gets all users with pagination
-func (s *userService) GetAll(page, perPage int) ([]response.UserResponse, int64, error) {
-	offset := (page - 1) * perPage
-	users, total, err := s.repo.FindAll(perPage, offset)
+// GetAll gets all users matching the filters with pagination
+func (s *userService) GetAll(ctx context.Context, req *request.ListUsersRequest) ([]response.UserResponse, int64, error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetAll")
+	defer span.End()
+
+	filter, err := buildUserFilter(req)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	offset := (req.Page - 1) * req.PerPage
+	users, total, err := s.repo.FindAll(ctx, filter, req.PerPage, offset)
 	if err != nil {
 		return nil, 0, err
 	}
 
 	userResponses := make([]response.UserResponse, len(users))
-	for i, user := range users {
-		userResponses[i] = *s.toUserResponse(&user)
+	for i := range users {
+		userResponses[i] = *toUserResponse(&users[i])
 	}
 
 	return userResponses, total, nil
 }
 
 // Update updates a user
-func (s *userService) Update(id uint, req *request.UpdateUserRequest) (*response.UserResponse, error) {
-	user, err := s.repo.FindByID(id)
+func (s *userService) Update(ctx context.Context, id uint, req *request.UpdateUserRequest) (*response.UserResponse, error) {
+	ctx, span := tracing.Start(ctx, "UserService.Update")
+	defer span.End()
+
+	user, err := s.repo.FindByID(repository.WithSecrets(ctx), id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("user not found")
+			return nil, ErrUserNotFound
 		}
 		return nil, err
 	}
 
+	before := toUserResponse(user)
+
 	// Update fields if provided
-	if req.Email != "" {
+	if req.Email != nil {
+		// The email receives password resets, so it may not be changed by an impersonator
+		if *req.Email != user.Email {
+			if err := rejectImpersonation(ctx); err != nil {
+				return nil, err
+			}
+		}
+
 		// Check if email is already taken by another user
-		existingUser, err := s.repo.FindByEmail(req.Email)
+		existingUser, err := s.repo.FindByEmail(ctx, *req.Email)
 		if err == nil && existingUser.ID != id {
-			return nil, errors.New("email already exists")
+			return nil, ErrEmailExists
 		}
-		user.Email = req.Email
+		user.Email = *req.Email
 	}
 
-	if req.Name != "" {
-		user.Name = req.Name
+	if req.Name != nil {
+		user.Name = *req.Name
 	}
 
-	if err := s.repo.Update(user); err != nil {
+	// Built once saved, so they carry the updated_at the update set
+	var updated *response.UserResponse
+	var change domain.Event
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Update(ctx, user); err != nil {
+			return err
+		}
+		updated = toUserResponse(user)
+		change = domain.NewEvent(domain.EventUserUpdated, user, updated)
+		return enqueue(ctx, s.outbox, change)
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	return s.toUserResponse(user), nil
+	s.audit.Record(ctx, domain.AuditActionUpdate, AuditEntityUser, user.ID, before, updated)
+	s.events.Publish(ctx, change)
+	s.responses.Invalidate(ctx, CacheTagUsers)
+	s.dispatcher.Dispatch(ctx, event.UserUpdated{User: updated})
+
+	return updated, nil
 }
 
 // Delete deletes a user
-func (s *userService) Delete(id uint) error {
-	_, err := s.repo.FindByID(id)
+func (s *userService) Delete(ctx context.Context, id uint) error {
+	ctx, span := tracing.Start(ctx, "UserService.Delete")
+	defer span.End()
+
+	if err := rejectImpersonation(ctx); err != nil {
+		return err
+	}
+
+	user, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+
+	change := domain.NewEvent(domain.EventUserDeleted, user, toUserResponse(user))
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Delete(ctx, id); err != nil {
+			return err
+		}
+		return enqueue(ctx, s.outbox, change)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.events.Publish(ctx, change)
+	s.responses.Invalidate(ctx, CacheTagUsers)
+	s.dispatcher.Dispatch(ctx, event.UserDeleted{User: toUserResponse(user)})
+
+	return nil
+}
+
+// Restore restores a soft deleted user
+func (s *userService) Restore(ctx context.Context, id uint) (*response.UserResponse, error) {
+	ctx, span := tracing.Start(ctx, "UserService.Restore")
+	defer span.End()
+
+	deleted, err := s.repo.FindDeletedByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("user not found")
+			return nil, ErrDeletedUserNotFound
+		}
+		return nil, err
+	}
+
+	var restored *response.UserResponse
+	var change domain.Event
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Restore(ctx, id); err != nil {
+			return err
+		}
+		if restored, err = s.GetByID(ctx, id); err != nil {
+			return err
+		}
+		change = domain.NewEvent(domain.EventUserUpdated, deleted, restored)
+		return enqueue(ctx, s.outbox, change)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit.Record(ctx, domain.AuditActionRestore, AuditEntityUser, id, toUserResponse(deleted), restored)
+	s.events.Publish(ctx, change)
+	s.responses.Invalidate(ctx, CacheTagUsers)
+	s.dispatcher.Dispatch(ctx, event.UserUpdated{User: restored})
+
+	return restored, nil
+}
+
+// HardDelete permanently deletes a user, whether soft deleted or not
+func (s *userService) HardDelete(ctx context.Context, id uint) error {
+	ctx, span := tracing.Start(ctx, "UserService.HardDelete")
+	defer span.End()
+
+	user, err := s.repo.FindByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		user, err = s.repo.FindDeletedByID(ctx, id)
+	}
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+
+	if err := s.hardDelete(ctx, user); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// PurgeDeleted permanently deletes users soft deleted before the given time
+// and returns how many were removed
+func (s *userService) PurgeDeleted(ctx context.Context, before time.Time) (int, error) {
+	ctx, span := tracing.Start(ctx, "UserService.PurgeDeleted")
+	defer span.End()
+
+	purged := 0
+	for {
+		users, err := s.repo.FindDeletedBefore(ctx, before, purgeBatchSize)
+		if err != nil {
+			return purged, err
+		}
+
+		for i := range users {
+			if err := s.hardDelete(ctx, &users[i]); err != nil {
+				return purged, err
+			}
+			purged++
+		}
+
+		if len(users) < purgeBatchSize {
+			return purged, nil
+		}
+	}
+}
+
+// hardDelete permanently deletes user with its deletion event, then publishes it
+func (s *userService) hardDelete(ctx context.Context, user *domain.User) error {
+	change := domain.NewEvent(domain.EventUserDeleted, user, toUserResponse(user))
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.HardDelete(ctx, user.ID); err != nil {
+			return err
 		}
+		return enqueue(ctx, s.outbox, change)
+	})
+	if err != nil {
 		return err
 	}
 
-	return s.repo.Delete(id)
+	s.events.Publish(ctx, change)
+	s.responses.Invalidate(ctx, CacheTagUsers)
+	s.dispatcher.Dispatch(ctx, event.UserDeleted{User: toUserResponse(user), Permanent: true})
+	return nil
+}
+
+// ChangePassword verifies the current password, sets a new one and invalidates existing tokens
+func (s *userService) ChangePassword(ctx context.Context, id uint, currentPassword, newPassword string) error {
+	ctx, span := tracing.Start(ctx, "UserService.ChangePassword")
+	defer span.End()
+
+	if err := rejectImpersonation(ctx); err != nil {
+		return err
+	}
+
+	user, err := s.repo.FindByID(repository.WithSecrets(ctx), id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+
+	// Verify current password
+	match, err := s.hasher.Verify(user.Password, currentPassword)
+	if err != nil {
+		return err
+	}
+	if !match {
+		return ErrWrongPassword
+	}
+
+	if currentPassword == newPassword {
+		return ErrPasswordUnchanged
+	}
+	if err := s.passwords.Check(ctx, user, newPassword); err != nil {
+		return err
+	}
+
+	// Hash password
+	hashedPassword, err := s.hasher.Hash(newPassword)
+	if err != nil {
+		return err
+	}
+
+	user.Password = hashedPassword
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Update(ctx, user); err != nil {
+			return err
+		}
+		return s.passwords.Record(ctx, user)
+	})
+	if err != nil {
+		return err
+	}
+
+	// Sign out every session that used the old password
+	changedAt := time.Now()
+	if err := s.repo.IncrementTokenVersion(ctx, user.ID); err != nil {
+		return err
+	}
+
+	s.notifier.Notify(ctx, passwordChangedNotice(user, changedAt))
+	return nil
+}
+
+// UpdateAvatar stores a new avatar image and replaces the previous one
+func (s *userService) UpdateAvatar(ctx context.Context, id uint, file io.Reader, size int64) (*response.UserResponse, error) {
+	ctx, span := tracing.Start(ctx, "UserService.UpdateAvatar")
+	defer span.End()
+
+	user, err := s.repo.FindByID(repository.WithSecrets(ctx), id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	if size > s.maxAvatarSize {
+		return nil, apperror.Validation(fmt.Sprintf("avatar must not exceed %d bytes", s.maxAvatarSize)).WithCode(apperror.CodeAvatarTooLarge)
+	}
+
+	// Detect the content type from the file itself rather than trusting the client
+	reader := bufio.NewReader(file)
+	head, err := reader.Peek(512)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	contentType := http.DetectContentType(head)
+	if !imageutil.SupportedContentTypes[contentType] {
+		return nil, ErrInvalidAvatarType
+	}
+
+	suffix, err := generateRandomToken(8)
+	if err != nil {
+		return nil, err
+	}
+
+	key := fmt.Sprintf("%s/%d/%s%s", AvatarKeyPrefix, user.ID, suffix, avatarExtensions[contentType])
+	if err := s.storage.Put(ctx, key, io.LimitReader(reader, s.maxAvatarSize), size, contentType); err != nil {
+		return nil, err
+	}
+
+	before := toUserResponse(user)
+	oldKey := user.AvatarKey
+	user.AvatarKey = key
+	user.AvatarURL = s.storage.URL(key)
+
+	var updated *response.UserResponse
+	var change domain.Event
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Update(ctx, user); err != nil {
+			return err
+		}
+		updated = toUserResponse(user)
+		change = domain.NewEvent(domain.EventUserUpdated, user, updated)
+		return enqueue(ctx, s.outbox, change)
+	})
+	if err != nil {
+		if delErr := s.storage.Delete(ctx, key); delErr != nil {
+			logger.Ctx(ctx, s.log).Warn("Failed to remove orphaned avatar", zap.String("key", key), zap.Error(delErr))
+		}
+		return nil, err
+	}
+
+	if oldKey != "" {
+		if err := s.storage.Delete(ctx, oldKey); err != nil {
+			logger.Ctx(ctx, s.log).Warn("Failed to remove previous avatar", zap.String("key", oldKey), zap.Error(err))
+		}
+	}
+
+	s.audit.Record(ctx, domain.AuditActionUpdate, AuditEntityUser, user.ID, before, updated)
+	s.events.Publish(ctx, change)
+	s.responses.Invalidate(ctx, CacheTagUsers)
+
+	return updated, nil
+}
+
+// GetAvatar returns the user's avatar, resized so its longest side is at most size pixels when size > 0
+func (s *userService) GetAvatar(ctx context.Context, id uint, size int) ([]byte, string, error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetAvatar")
+	defer span.End()
+
+	user, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, "", ErrUserNotFound
+		}
+		return nil, "", err
+	}
+
+	if user.AvatarKey == "" {
+		return nil, "", ErrAvatarNotFound
+	}
+
+	file, err := s.storage.Get(ctx, user.AvatarKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, "", ErrAvatarNotFound
+		}
+		return nil, "", err
+	}
+	defer file.Close()
+
+	if size > 0 {
+		return imageutil.Resize(file, size)
+	}
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return data, http.DetectContentType(data), nil
+}
+
+// Export writes all users matching the filters to the export writer, with the requested columns
+func (s *userService) Export(ctx context.Context, req *request.ExportUsersRequest, w export.Writer) error {
+	ctx, span := tracing.Start(ctx, "UserService.Export")
+	defer span.End()
+
+	filter, err := buildUserFilter(&req.ListUsersRequest)
+	if err != nil {
+		return err
+	}
+
+	columns, err := parseExportFields(req.Fields)
+	if err != nil {
+		return err
+	}
+
+	if err := w.WriteHeader(columns); err != nil {
+		return err
+	}
+
+	err = s.repo.FindAllInBatches(ctx, filter, exportBatchSize, func(users []domain.User) error {
+		for i := range users {
+			row := make([]interface{}, len(columns))
+			for j, column := range columns {
+				row[j] = userExportFields[column](&users[i])
+			}
+			if err := w.WriteRow(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return w.Close()
+}
+
+// buildUserFilter converts list query parameters into a repository filter
+func buildUserFilter(req *request.ListUsersRequest) (repository.UserFilter, error) {
+	sort, err := repository.ParseSort(req.Sort, repository.UserSortFields)
+	if err != nil {
+		return repository.UserFilter{}, err
+	}
+
+	ids, err := parseUserIDs(req.IDs)
+	if err != nil {
+		return repository.UserFilter{}, err
+	}
+
+	fields, err := fieldset.Parse(req.Fields, UserFields)
+	if err != nil {
+		return repository.UserFilter{}, err
+	}
+
+	filter := repository.UserFilter{
+		IDs:            ids,
+		Search:         req.Search,
+		Query:          req.Query,
+		Email:          req.Email,
+		IncludeDeleted: req.IncludeDeleted,
+		Sort:           sort,
+		Columns:        fields.Columns(),
+	}
+	if !req.CreatedFrom.IsZero() {
+		filter.CreatedAfter = &req.CreatedFrom
+	}
+	if !req.CreatedTo.IsZero() {
+		// created_to is an inclusive date
+		createdBefore := req.CreatedTo.AddDate(0, 0, 1)
+		filter.CreatedBefore = &createdBefore
+	}
+
+	return filter, nil
+}
+
+// parseUserIDs parses a comma separated list of user IDs, at most MaxUserIDs
+func parseUserIDs(ids string) ([]uint, error) {
+	if strings.TrimSpace(ids) == "" {
+		return nil, nil
+	}
+
+	parts := strings.Split(ids, ",")
+	if len(parts) > MaxUserIDs {
+		return nil, ErrInvalidUserIDs
+	}
+	parsed := make([]uint, len(parts))
+	for i, part := range parts {
+		id, err := strconv.ParseUint(strings.TrimSpace(part), 10, 32)
+		if err != nil || id == 0 {
+			return nil, ErrInvalidUserIDs
+		}
+		parsed[i] = uint(id)
+	}
+	return parsed, nil
+}
+
+// parseExportFields parses a comma separated column list, defaulting to all columns
+func parseExportFields(fields string) ([]string, error) {
+	if strings.TrimSpace(fields) == "" {
+		return defaultUserExportColumns, nil
+	}
+
+	var columns []string
+	for _, field := range strings.Split(fields, ",") {
+		field = strings.TrimSpace(field)
+		if _, ok := userExportFields[field]; !ok {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidExportField, field)
+		}
+		columns = append(columns, field)
+	}
+
+	return columns, nil
 }
 
 // toUserResponse converts domain.User to response.UserResponse
-func (s *userService) toUserResponse(user *domain.User) *response.UserResponse {
-	return &response.UserResponse{
-		ID:        user.ID,
-		Email:     user.Email,
-		Name:      user.Name,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
+func toUserResponse(user *domain.User) *response.UserResponse {
+	resp := &response.UserResponse{
+		ID:         user.ID,
+		Email:      user.Email,
+		Name:       user.Name,
+		Role:       user.Role,
+		AvatarURL:  user.AvatarURL,
+		MFAEnabled: user.MFAEnabled,
+		CreatedAt:  user.CreatedAt,
+		UpdatedAt:  user.UpdatedAt,
+	}
+	if user.DeletedAt.Valid {
+		resp.DeletedAt = &user.DeletedAt.Time
 	}
+	return resp
 }
@@ -0,0 +1,415 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/dto/request"
+	"github.com/firdanbash/go-clean-boiler/internal/dto/response"
+	"github.com/firdanbash/go-clean-boiler/internal/event"
+	"github.com/firdanbash/go-clean-boiler/internal/mocks"
+	"github.com/firdanbash/go-clean-boiler/internal/notification"
+	"github.com/firdanbash/go-clean-boiler/internal/repository"
+	"github.com/firdanbash/go-clean-boiler/internal/service"
+	"github.com/firdanbash/go-clean-boiler/internal/testutil"
+	"github.com/firdanbash/go-clean-boiler/pkg/fieldset"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"github.com/firdanbash/go-clean-boiler/pkg/reqctx"
+	"go.uber.org/mock/gomock"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// userServiceDeps holds the mocked dependencies of the user service under test
+type userServiceDeps struct {
+	repo      *mocks.MockUserRepository
+	passwords *mocks.MockPasswordHistoryService
+	audit     *mocks.MockAuditService
+	events    *mocks.MockEventPublisher
+	responses *mocks.MockCacheInvalidator
+	bus       *mocks.MockDispatcher
+	notifier  *mocks.MockNotifier
+	outbox    *mocks.MockOutboxRepository
+}
+
+func newUserService(t *testing.T) (service.UserService, userServiceDeps) {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+	deps := userServiceDeps{
+		repo:      mocks.NewMockUserRepository(ctrl),
+		passwords: mocks.NewMockPasswordHistoryService(ctrl),
+		audit:     mocks.NewMockAuditService(ctrl),
+		events:    mocks.NewMockEventPublisher(ctrl),
+		responses: mocks.NewMockCacheInvalidator(ctrl),
+		bus:       mocks.NewMockDispatcher(ctrl),
+		notifier:  mocks.NewMockNotifier(ctrl),
+		outbox:    mocks.NewMockOutboxRepository(ctrl),
+	}
+	svc := service.NewUserService(deps.repo, testHasher, deps.passwords, nil, deps.audit, deps.events, deps.responses, deps.bus, deps.notifier, testutil.Transactor(), deps.outbox, 0, logger.Nop())
+	return svc, deps
+}
+
+func TestUserServiceCreate(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("hashes the password and defaults the role", func(t *testing.T) {
+		svc, deps := newUserService(t)
+		req := &request.CreateUserRequest{Email: "jane@example.com", Password: "secret123", Name: "Jane"}
+
+		var stored *domain.User
+		deps.repo.EXPECT().FindByEmail(gomock.Any(), req.Email).Return(nil, gorm.ErrRecordNotFound)
+		deps.repo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, user *domain.User) error {
+			user.ID = 7
+			stored = user
+			return nil
+		})
+		deps.passwords.EXPECT().Record(gomock.Any(), gomock.Any()).Return(nil)
+		deps.outbox.EXPECT().Create(gomock.Any(), outboxOf(domain.EventUserCreated, 7)).Return(nil)
+		deps.events.EXPECT().Publish(gomock.Any(), eventOf(domain.EventUserCreated, 7))
+		deps.responses.EXPECT().Invalidate(gomock.Any(), service.CacheTagUsers)
+		deps.bus.EXPECT().Dispatch(gomock.Any(), gomock.Cond(func(x interface{}) bool {
+			e, ok := x.(event.UserRegistered)
+			return ok && e.User.ID == 7 && !e.SelfService
+		}))
+
+		result, err := svc.Create(ctx, req)
+		if err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		if result.ID != 7 || result.Email != req.Email || result.Role != domain.RoleUser {
+			t.Errorf("Create() = %+v, want id 7, email %s and role %s", result, req.Email, domain.RoleUser)
+		}
+		if stored.Password == req.Password {
+			t.Fatal("password was stored in plain text")
+		}
+		if err := bcrypt.CompareHashAndPassword([]byte(stored.Password), []byte(req.Password)); err != nil {
+			t.Errorf("stored hash does not match the password: %v", err)
+		}
+	})
+
+	t.Run("rejects a duplicate email", func(t *testing.T) {
+		svc, deps := newUserService(t)
+		req := &request.CreateUserRequest{Email: "jane@example.com", Password: "secret123", Name: "Jane"}
+
+		deps.repo.EXPECT().FindByEmail(gomock.Any(), req.Email).Return(&domain.User{ID: 1, Email: req.Email}, nil)
+
+		if _, err := svc.Create(ctx, req); !errors.Is(err, service.ErrEmailExists) {
+			t.Fatalf("Create() error = %v, want %v", err, service.ErrEmailExists)
+		}
+	})
+
+	t.Run("fails when the event cannot be stored", func(t *testing.T) {
+		svc, deps := newUserService(t)
+		outboxErr := errors.New("disk full")
+
+		deps.repo.EXPECT().FindByEmail(gomock.Any(), gomock.Any()).Return(nil, gorm.ErrRecordNotFound)
+		deps.repo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+		deps.passwords.EXPECT().Record(gomock.Any(), gomock.Any()).Return(nil)
+		deps.outbox.EXPECT().Create(gomock.Any(), outboxOf(domain.EventUserCreated, 0)).Return(outboxErr)
+
+		_, err := svc.Create(ctx, &request.CreateUserRequest{Email: "jane@example.com", Password: "secret123", Name: "Jane"})
+		if !errors.Is(err, outboxErr) {
+			t.Fatalf("Create() error = %v, want %v", err, outboxErr)
+		}
+	})
+
+	t.Run("returns lookup errors", func(t *testing.T) {
+		svc, deps := newUserService(t)
+		lookupErr := errors.New("connection refused")
+
+		deps.repo.EXPECT().FindByEmail(gomock.Any(), gomock.Any()).Return(nil, lookupErr)
+
+		_, err := svc.Create(ctx, &request.CreateUserRequest{Email: "jane@example.com", Password: "secret123", Name: "Jane"})
+		if !errors.Is(err, lookupErr) {
+			t.Fatalf("Create() error = %v, want %v", err, lookupErr)
+		}
+	})
+}
+
+// eventOf matches a domain event of eventType for the user with userID
+func eventOf(eventType string, userID uint) gomock.Matcher {
+	return gomock.Cond(func(x interface{}) bool {
+		event, ok := x.(domain.Event)
+		return ok && event.Type == eventType && event.UserID == userID
+	})
+}
+
+// outboxOf matches an outbox message of eventType keyed by userID
+func outboxOf(eventType string, userID uint) gomock.Matcher {
+	return gomock.Cond(func(x interface{}) bool {
+		message, ok := x.(*domain.OutboxMessage)
+		return ok && message.Topic == eventType && message.Key == strconv.FormatUint(uint64(userID), 10)
+	})
+}
+
+func TestUserServiceGetByIDs(t *testing.T) {
+	svc, deps := newUserService(t)
+
+	deps.repo.EXPECT().FindByIDs(gomock.Any(), []uint{1, 2, 3}).Return([]domain.User{
+		{ID: 3, Email: "three@example.com"},
+		{ID: 1, Email: "one@example.com"},
+	}, nil)
+
+	users, err := svc.GetByIDs(context.Background(), []uint{1, 2, 3})
+	if err != nil {
+		t.Fatalf("GetByIDs() error = %v", err)
+	}
+	if len(users) != 2 || users[1].Email != "one@example.com" || users[3].Email != "three@example.com" {
+		t.Errorf("GetByIDs() = %+v, want users 1 and 3 keyed by ID", users)
+	}
+	if _, ok := users[2]; ok {
+		t.Error("GetByIDs() has an entry for the missing user 2")
+	}
+}
+
+func TestUserServiceGetAllByIDs(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("lists the users with the given IDs", func(t *testing.T) {
+		svc, deps := newUserService(t)
+
+		deps.repo.EXPECT().FindAll(gomock.Any(), repository.UserFilter{IDs: []uint{4, 2, 9}}, 100, 0).
+			Return([]domain.User{{ID: 2}, {ID: 4}}, int64(2), nil)
+
+		users, total, err := svc.GetAll(ctx, &request.ListUsersRequest{Page: 1, PerPage: 100, IDs: "4, 2,9"})
+		if err != nil {
+			t.Fatalf("GetAll() error = %v", err)
+		}
+		if total != 2 || len(users) != 2 {
+			t.Errorf("GetAll() = %d users of %d, want 2 of 2", len(users), total)
+		}
+	})
+
+	for name, ids := range map[string]string{
+		"not a number": "1,x",
+		"zero":         "0",
+		"empty":        "1,,2",
+		"too many":     strings.Repeat("1,", service.MaxUserIDs) + "1",
+	} {
+		t.Run("rejects "+name, func(t *testing.T) {
+			svc, _ := newUserService(t)
+
+			_, _, err := svc.GetAll(ctx, &request.ListUsersRequest{Page: 1, PerPage: 10, IDs: ids})
+			if !errors.Is(err, service.ErrInvalidUserIDs) {
+				t.Errorf("GetAll() error = %v, want ErrInvalidUserIDs", err)
+			}
+		})
+	}
+}
+
+func TestUserServiceGetAllSelectsFields(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("loads only the columns of the requested fields", func(t *testing.T) {
+		svc, deps := newUserService(t)
+
+		deps.repo.EXPECT().FindAll(gomock.Any(), repository.UserFilter{Columns: []string{"name", "email"}}, 10, 0).
+			Return([]domain.User{{ID: 2, Name: "Jane", Email: "jane@example.com"}}, int64(1), nil)
+
+		if _, _, err := svc.GetAll(ctx, &request.ListUsersRequest{Page: 1, PerPage: 10, Fields: "name, email,name"}); err != nil {
+			t.Fatalf("GetAll() error = %v", err)
+		}
+	})
+
+	t.Run("rejects an unknown field", func(t *testing.T) {
+		svc, _ := newUserService(t)
+
+		_, _, err := svc.GetAll(ctx, &request.ListUsersRequest{Page: 1, PerPage: 10, Fields: "id,password"})
+		if !errors.Is(err, fieldset.ErrUnknownField) {
+			t.Errorf("GetAll() error = %v, want ErrUnknownField", err)
+		}
+	})
+}
+
+func TestUserServiceUpdate(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("updates the provided fields", func(t *testing.T) {
+		svc, deps := newUserService(t)
+		user := &domain.User{ID: 3, Email: "old@example.com", Name: "Old", Role: domain.RoleUser}
+		savedAt := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+
+		deps.repo.EXPECT().FindByID(gomock.Any(), uint(3)).Return(user, nil)
+		deps.repo.EXPECT().FindByEmail(gomock.Any(), "new@example.com").Return(nil, gorm.ErrRecordNotFound)
+		deps.repo.EXPECT().Update(gomock.Any(), user).DoAndReturn(func(_ context.Context, u *domain.User) error {
+			u.UpdatedAt = savedAt
+			return nil
+		})
+		deps.audit.EXPECT().Record(gomock.Any(), domain.AuditActionUpdate, service.AuditEntityUser, uint(3), gomock.Any(), gomock.Any())
+		deps.outbox.EXPECT().Create(gomock.Any(), outboxOf(domain.EventUserUpdated, 3)).Return(nil)
+		deps.events.EXPECT().Publish(gomock.Any(), gomock.Cond(func(x interface{}) bool {
+			e, ok := x.(domain.Event)
+			data, _ := e.Data.(*response.UserResponse)
+			return ok && e.Type == domain.EventUserUpdated && data != nil && data.UpdatedAt.Equal(savedAt)
+		}))
+		deps.responses.EXPECT().Invalidate(gomock.Any(), service.CacheTagUsers)
+		deps.bus.EXPECT().Dispatch(gomock.Any(), gomock.Cond(func(x interface{}) bool {
+			e, ok := x.(event.UserUpdated)
+			return ok && e.User.ID == 3 && e.User.Email == "new@example.com"
+		}))
+
+		result, err := svc.Update(ctx, 3, &request.UpdateUserRequest{Email: testutil.Ptr("new@example.com")})
+		if err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+		if result.Email != "new@example.com" || result.Name != "Old" {
+			t.Errorf("Update() = %+v, want the new email and the old name", result)
+		}
+		if !result.UpdatedAt.Equal(savedAt) {
+			t.Errorf("UpdatedAt = %v, want the %v set by the update", result.UpdatedAt, savedAt)
+		}
+	})
+
+	t.Run("allows keeping the same email", func(t *testing.T) {
+		svc, deps := newUserService(t)
+		user := &domain.User{ID: 3, Email: "jane@example.com", Name: "Jane"}
+
+		deps.repo.EXPECT().FindByID(gomock.Any(), uint(3)).Return(user, nil)
+		deps.repo.EXPECT().FindByEmail(gomock.Any(), user.Email).Return(user, nil)
+		deps.repo.EXPECT().Update(gomock.Any(), user).Return(nil)
+		deps.audit.EXPECT().Record(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+		deps.outbox.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+		deps.events.EXPECT().Publish(gomock.Any(), gomock.Any())
+		deps.responses.EXPECT().Invalidate(gomock.Any(), service.CacheTagUsers)
+		deps.bus.EXPECT().Dispatch(gomock.Any(), gomock.Any())
+
+		if _, err := svc.Update(ctx, 3, &request.UpdateUserRequest{Email: testutil.Ptr(user.Email), Name: testutil.Ptr("Janet")}); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+	})
+
+	t.Run("rejects an email taken by another user", func(t *testing.T) {
+		svc, deps := newUserService(t)
+
+		deps.repo.EXPECT().FindByID(gomock.Any(), uint(3)).Return(&domain.User{ID: 3, Email: "jane@example.com"}, nil)
+		deps.repo.EXPECT().FindByEmail(gomock.Any(), "john@example.com").Return(&domain.User{ID: 4, Email: "john@example.com"}, nil)
+
+		_, err := svc.Update(ctx, 3, &request.UpdateUserRequest{Email: testutil.Ptr("john@example.com")})
+		if !errors.Is(err, service.ErrEmailExists) {
+			t.Fatalf("Update() error = %v, want %v", err, service.ErrEmailExists)
+		}
+	})
+
+	t.Run("reports a missing user", func(t *testing.T) {
+		svc, deps := newUserService(t)
+
+		deps.repo.EXPECT().FindByID(gomock.Any(), uint(9)).Return(nil, gorm.ErrRecordNotFound)
+
+		_, err := svc.Update(ctx, 9, &request.UpdateUserRequest{Name: testutil.Ptr("Nobody")})
+		if !errors.Is(err, service.ErrUserNotFound) {
+			t.Fatalf("Update() error = %v, want %v", err, service.ErrUserNotFound)
+		}
+	})
+}
+
+func TestUserServicePurgeDeleted(t *testing.T) {
+	ctx := context.Background()
+	before := time.Now().Add(-30 * 24 * time.Hour)
+
+	t.Run("hard deletes each user and dispatches its deletion", func(t *testing.T) {
+		svc, deps := newUserService(t)
+
+		deps.repo.EXPECT().FindDeletedBefore(gomock.Any(), before, gomock.Any()).Return([]domain.User{{ID: 4}, {ID: 9}}, nil)
+		for _, id := range []uint{4, 9} {
+			deps.repo.EXPECT().HardDelete(gomock.Any(), id).Return(nil)
+			deps.outbox.EXPECT().Create(gomock.Any(), outboxOf(domain.EventUserDeleted, id)).Return(nil)
+			deps.events.EXPECT().Publish(gomock.Any(), eventOf(domain.EventUserDeleted, id))
+			deps.responses.EXPECT().Invalidate(gomock.Any(), service.CacheTagUsers)
+			deps.bus.EXPECT().Dispatch(gomock.Any(), event.UserDeleted{User: &response.UserResponse{ID: id}, Permanent: true})
+		}
+
+		purged, err := svc.PurgeDeleted(ctx, before)
+		if err != nil {
+			t.Fatalf("PurgeDeleted() error = %v", err)
+		}
+		if purged != 2 {
+			t.Errorf("PurgeDeleted() = %d, want 2", purged)
+		}
+	})
+
+	t.Run("stops at the first failure", func(t *testing.T) {
+		svc, deps := newUserService(t)
+		deleteErr := errors.New("foreign key violation")
+
+		deps.repo.EXPECT().FindDeletedBefore(gomock.Any(), before, gomock.Any()).Return([]domain.User{{ID: 4}, {ID: 9}}, nil)
+		deps.repo.EXPECT().HardDelete(gomock.Any(), uint(4)).Return(deleteErr)
+
+		purged, err := svc.PurgeDeleted(ctx, before)
+		if !errors.Is(err, deleteErr) || purged != 0 {
+			t.Fatalf("PurgeDeleted() = %d, %v, want 0, %v", purged, err, deleteErr)
+		}
+	})
+}
+
+func TestUserServiceChangePassword(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("rejects a wrong current password", func(t *testing.T) {
+		svc, deps := newUserService(t)
+
+		deps.repo.EXPECT().FindByID(gomock.Any(), uint(1)).Return(testutil.NewUser(testutil.WithID(1), testutil.WithPassword("secret123")), nil)
+
+		err := svc.ChangePassword(ctx, 1, "wrong", "newsecret")
+		if !errors.Is(err, service.ErrWrongPassword) {
+			t.Fatalf("ChangePassword() error = %v, want %v", err, service.ErrWrongPassword)
+		}
+	})
+
+	t.Run("rejects an impersonator", func(t *testing.T) {
+		svc, _ := newUserService(t)
+
+		err := svc.ChangePassword(reqctx.WithImpersonatorID(ctx, 9), 1, "secret123", "newsecret")
+		if !errors.Is(err, service.ErrImpersonating) {
+			t.Fatalf("ChangePassword() error = %v, want %v", err, service.ErrImpersonating)
+		}
+	})
+
+	t.Run("rejects reusing the current password", func(t *testing.T) {
+		svc, deps := newUserService(t)
+
+		deps.repo.EXPECT().FindByID(gomock.Any(), uint(1)).Return(testutil.NewUser(testutil.WithID(1), testutil.WithPassword("secret123")), nil)
+
+		if err := svc.ChangePassword(ctx, 1, "secret123", "secret123"); !errors.Is(err, service.ErrPasswordUnchanged) {
+			t.Fatalf("ChangePassword() error = %v, want %v", err, service.ErrPasswordUnchanged)
+		}
+	})
+
+	t.Run("rejects a recent password", func(t *testing.T) {
+		svc, deps := newUserService(t)
+		user := testutil.NewUser(testutil.WithID(1), testutil.WithPassword("secret123"))
+
+		deps.repo.EXPECT().FindByID(gomock.Any(), uint(1)).Return(user, nil)
+		deps.passwords.EXPECT().Check(gomock.Any(), user, "oldsecret").Return(service.ErrPasswordReused)
+
+		if err := svc.ChangePassword(ctx, 1, "secret123", "oldsecret"); !errors.Is(err, service.ErrPasswordReused) {
+			t.Fatalf("ChangePassword() error = %v, want %v", err, service.ErrPasswordReused)
+		}
+	})
+
+	t.Run("rehashes the password, signs out sessions and notifies the user", func(t *testing.T) {
+		svc, deps := newUserService(t)
+		user := testutil.NewUser(testutil.WithID(1), testutil.WithPassword("secret123"))
+
+		deps.repo.EXPECT().FindByID(gomock.Any(), uint(1)).Return(user, nil)
+		deps.passwords.EXPECT().Check(gomock.Any(), user, "newsecret").Return(nil)
+		deps.repo.EXPECT().Update(gomock.Any(), user).Return(nil)
+		deps.passwords.EXPECT().Record(gomock.Any(), user).Return(nil)
+		deps.repo.EXPECT().IncrementTokenVersion(gomock.Any(), uint(1)).Return(nil)
+		deps.notifier.EXPECT().Notify(gomock.Any(), gomock.Cond(func(x interface{}) bool {
+			n, ok := x.(notification.Notification)
+			return ok && n.Type == notification.TypePasswordChanged && n.UserID == 1
+		}))
+
+		if err := svc.ChangePassword(ctx, 1, "secret123", "newsecret"); err != nil {
+			t.Fatalf("ChangePassword() error = %v", err)
+		}
+		if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte("newsecret")); err != nil {
+			t.Errorf("stored hash does not match the new password: %v", err)
+		}
+	})
+}
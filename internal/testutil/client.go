@@ -0,0 +1,123 @@
+package testutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/pkg/jwt"
+)
+
+// Client sends requests to an http.Handler, or to a server when constructed
+// with NewServerClient, and wraps the responses for assertions
+type Client struct {
+	t       testing.TB
+	handler http.Handler
+	baseURL string
+	header  http.Header
+}
+
+// NewClient returns a client that serves requests in process with handler
+func NewClient(t testing.TB, handler http.Handler) *Client {
+	return &Client{t: t, handler: handler, header: http.Header{}}
+}
+
+// NewServerClient returns a client that sends requests to a running server
+func NewServerClient(t testing.TB, baseURL string) *Client {
+	return &Client{t: t, baseURL: strings.TrimRight(baseURL, "/"), header: http.Header{}}
+}
+
+// WithHeader returns a copy of the client that sends an extra header
+func (c *Client) WithHeader(key, value string) *Client {
+	clone := *c
+	clone.header = c.header.Clone()
+	clone.header.Set(key, value)
+	return &clone
+}
+
+// WithToken returns a copy of the client that authenticates with token
+func (c *Client) WithToken(token string) *Client {
+	return c.WithHeader("Authorization", "Bearer "+token)
+}
+
+// AsUser returns a copy of the client authenticated as user with a token issued by m
+func (c *Client) AsUser(m *jwt.Manager, user *domain.User) *Client {
+	c.t.Helper()
+	return c.WithToken(Token(c.t, m, user))
+}
+
+// Get sends a GET request
+func (c *Client) Get(path string) *Response {
+	c.t.Helper()
+	return c.Do(http.MethodGet, path, nil)
+}
+
+// Post sends a POST request with a JSON body
+func (c *Client) Post(path string, body interface{}) *Response {
+	c.t.Helper()
+	return c.Do(http.MethodPost, path, body)
+}
+
+// Put sends a PUT request with a JSON body
+func (c *Client) Put(path string, body interface{}) *Response {
+	c.t.Helper()
+	return c.Do(http.MethodPut, path, body)
+}
+
+// Delete sends a DELETE request
+func (c *Client) Delete(path string) *Response {
+	c.t.Helper()
+	return c.Do(http.MethodDelete, path, nil)
+}
+
+// Do sends a request. A non-nil body is encoded as JSON unless it is already
+// an io.Reader, which is sent as is.
+func (c *Client) Do(method, path string, body interface{}) *Response {
+	c.t.Helper()
+
+	var reader io.Reader
+	switch b := body.(type) {
+	case nil:
+	case io.Reader:
+		reader = b
+	default:
+		payload, err := json.Marshal(b)
+		if err != nil {
+			c.t.Fatalf("testutil: encode %s %s body: %v", method, path, err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req := httptest.NewRequest(method, c.baseURL+path, reader)
+	if c.baseURL != "" {
+		req.RequestURI = ""
+	}
+	for key, values := range c.header {
+		req.Header[key] = values
+	}
+	if body != nil && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if c.handler != nil {
+		rec := httptest.NewRecorder()
+		c.handler.ServeHTTP(rec, req)
+		return newResponse(c.t, method+" "+path, rec.Code, rec.Header(), rec.Body.Bytes())
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		c.t.Fatalf("testutil: %s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		c.t.Fatalf("testutil: %s %s: read body: %v", method, path, err)
+	}
+	return newResponse(c.t, method+" "+path, resp.StatusCode, resp.Header, raw)
+}
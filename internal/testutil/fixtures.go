@@ -0,0 +1,102 @@
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/repository"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// DefaultPassword is the plain text password of users built by NewUser
+const DefaultPassword = "password123"
+
+var sequence atomic.Uint64
+
+// Sequence returns a number unique within the test binary, for unique fixture values
+func Sequence() uint64 {
+	return sequence.Add(1)
+}
+
+// Ptr returns a pointer to v, for the optional fields of requests
+func Ptr[T any](v T) *T {
+	return &v
+}
+
+// UserOption customizes a user fixture
+type UserOption func(*domain.User)
+
+// WithEmail sets the email
+func WithEmail(email string) UserOption {
+	return func(u *domain.User) { u.Email = email }
+}
+
+// WithName sets the name
+func WithName(name string) UserOption {
+	return func(u *domain.User) { u.Name = name }
+}
+
+// WithRole sets the role
+func WithRole(role string) UserOption {
+	return func(u *domain.User) { u.Role = role }
+}
+
+// WithPassword sets the password, hashed as the services store it
+func WithPassword(password string) UserOption {
+	return func(u *domain.User) { u.Password = hashPassword(password) }
+}
+
+// WithID sets the ID, for fixtures returned by mocks instead of a database
+func WithID(id uint) UserOption {
+	return func(u *domain.User) { u.ID = id }
+}
+
+// WithTenant sets the tenant the user belongs to
+func WithTenant(tenantID uint) UserOption {
+	return func(u *domain.User) { u.TenantID = tenantID }
+}
+
+// AsAdmin makes the user an admin
+func AsAdmin() UserOption {
+	return WithRole(domain.RoleAdmin)
+}
+
+// NewUser builds a user with a unique email, the user role and DefaultPassword
+func NewUser(opts ...UserOption) *domain.User {
+	n := Sequence()
+	user := &domain.User{
+		Email:    fmt.Sprintf("user%d@example.com", n),
+		Name:     fmt.Sprintf("User %d", n),
+		Role:     domain.RoleUser,
+		Password: defaultPasswordHash,
+	}
+	for _, opt := range opts {
+		opt(user)
+	}
+	return user
+}
+
+// CreateUser builds a user with NewUser and stores it with repo
+func CreateUser(t testing.TB, repo repository.UserRepository, opts ...UserOption) *domain.User {
+	t.Helper()
+	user := NewUser(opts...)
+	if err := repo.Create(context.Background(), user); err != nil {
+		t.Fatalf("testutil: create user %s: %v", user.Email, err)
+	}
+	return user
+}
+
+// defaultPasswordHash is computed once; bcrypt is slow by design
+var defaultPasswordHash = hashPassword(DefaultPassword)
+
+// hashPassword hashes with the minimum cost to keep tests fast
+func hashPassword(password string) string {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		panic(err)
+	}
+	return string(hash)
+}
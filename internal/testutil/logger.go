@@ -0,0 +1,15 @@
+package testutil
+
+import (
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// Logger returns a logger that records every entry, for asserting on what the
+// code under test logged
+func Logger() (logger.Logger, *observer.ObservedLogs) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	return logger.NewZap(zap.New(core)), logs
+}
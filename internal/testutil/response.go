@@ -0,0 +1,117 @@
+package testutil
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/firdanbash/go-clean-boiler/pkg/apperror"
+	"github.com/firdanbash/go-clean-boiler/pkg/response"
+)
+
+// Response is a received response, decoded from the standard response.Response
+// envelope when the body is JSON. Assertions fail the test and return the
+// response so they can be chained.
+type Response struct {
+	t       testing.TB
+	request string
+
+	Code       int
+	Header     http.Header
+	Body       []byte
+	Success    bool
+	ErrorCode  string
+	Message    string
+	Data       json.RawMessage
+	Error      json.RawMessage
+	Pagination *response.PaginationMeta
+}
+
+func newResponse(t testing.TB, request string, code int, header http.Header, body []byte) *Response {
+	r := &Response{t: t, request: request, Code: code, Header: header, Body: body}
+
+	var envelope struct {
+		Success    bool                     `json:"success"`
+		ErrorCode  string                   `json:"code"`
+		Message    string                   `json:"message"`
+		Data       json.RawMessage          `json:"data"`
+		Error      json.RawMessage          `json:"error"`
+		Pagination *response.PaginationMeta `json:"pagination"`
+	}
+	if json.Unmarshal(body, &envelope) == nil {
+		r.Success = envelope.Success
+		r.ErrorCode = envelope.ErrorCode
+		r.Message = envelope.Message
+		r.Data = envelope.Data
+		r.Error = envelope.Error
+		r.Pagination = envelope.Pagination
+	}
+	return r
+}
+
+// AssertStatus checks the status code
+func (r *Response) AssertStatus(code int) *Response {
+	r.t.Helper()
+	if r.Code != code {
+		r.t.Fatalf("%s: status %d, want %d: %s", r.request, r.Code, code, r.Body)
+	}
+	return r
+}
+
+// AssertSuccess checks for a 2xx status and a successful envelope
+func (r *Response) AssertSuccess() *Response {
+	r.t.Helper()
+	if r.Code < 200 || r.Code > 299 || !r.Success {
+		r.t.Fatalf("%s: want success, got status %d: %s", r.request, r.Code, r.Body)
+	}
+	return r
+}
+
+// AssertError checks the status code and that the envelope reports a failure
+func (r *Response) AssertError(code int) *Response {
+	r.t.Helper()
+	r.AssertStatus(code)
+	if r.Success {
+		r.t.Fatalf("%s: want success false: %s", r.request, r.Body)
+	}
+	return r
+}
+
+// AssertMessage checks the envelope message
+func (r *Response) AssertMessage(message string) *Response {
+	r.t.Helper()
+	if r.Message != message {
+		r.t.Fatalf("%s: message %q, want %q", r.request, r.Message, message)
+	}
+	return r
+}
+
+// AssertCode checks the error code of the envelope
+func (r *Response) AssertCode(code apperror.Code) *Response {
+	r.t.Helper()
+	if r.ErrorCode != string(code) {
+		r.t.Fatalf("%s: code %q, want %q", r.request, r.ErrorCode, code)
+	}
+	return r
+}
+
+// AssertTotal checks the total of a paginated response
+func (r *Response) AssertTotal(total int64) *Response {
+	r.t.Helper()
+	if r.Pagination == nil {
+		r.t.Fatalf("%s: response is not paginated: %s", r.request, r.Body)
+	}
+	if r.Pagination.Total != total {
+		r.t.Fatalf("%s: total %d, want %d", r.request, r.Pagination.Total, total)
+	}
+	return r
+}
+
+// Decode unmarshals the envelope data into v
+func (r *Response) Decode(v interface{}) *Response {
+	r.t.Helper()
+	if err := json.Unmarshal(r.Data, v); err != nil {
+		r.t.Fatalf("%s: decode data %s: %v", r.request, r.Data, err)
+	}
+	return r
+}
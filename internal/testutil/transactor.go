@@ -0,0 +1,19 @@
+package testutil
+
+import (
+	"context"
+
+	"github.com/firdanbash/go-clean-boiler/internal/repository"
+)
+
+// Transactor returns a transactor that runs functions without a transaction,
+// for services tested against mocked repositories
+func Transactor() repository.Transactor {
+	return passThrough{}
+}
+
+type passThrough struct{}
+
+func (passThrough) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
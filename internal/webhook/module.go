@@ -0,0 +1,30 @@
+package webhook
+
+import (
+	"github.com/firdanbash/go-clean-boiler/internal/repository"
+	"github.com/firdanbash/go-clean-boiler/pkg/config"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"go.uber.org/fx"
+)
+
+// Module provides the receiver of the providers in the "webhooks" group.
+// Integrations add a provider with
+//
+//	fx.Provide(fx.Annotate(NewPaymentsWebhook, fx.ResultTags(`group:"webhooks"`)))
+var Module = fx.Module("webhook",
+	fx.Provide(newReceiver),
+)
+
+type receiverParams struct {
+	fx.In
+
+	Providers  []Provider `group:"webhooks"`
+	Deliveries repository.WebhookDeliveryRepository
+	Tx         repository.Transactor
+	Config     *config.Config
+	Logger     logger.Logger
+}
+
+func newReceiver(p receiverParams) (*Receiver, error) {
+	return NewReceiver(p.Providers, p.Deliveries, p.Tx, p.Config.Webhook, p.Logger)
+}
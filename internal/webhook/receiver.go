@@ -0,0 +1,185 @@
+package webhook
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/middleware"
+	"github.com/firdanbash/go-clean-boiler/internal/repository"
+	"github.com/firdanbash/go-clean-boiler/pkg/config"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"github.com/firdanbash/go-clean-boiler/pkg/response"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// metrics counts the received deliveries by outcome, served at /debug/vars
+var metrics = expvar.NewMap("webhooks")
+
+// provider is a registered provider with its verifier
+type provider struct {
+	Provider
+	verifier Verifier
+}
+
+// Receiver verifies and processes the deliveries of the registered providers
+type Receiver struct {
+	providers   map[string]*provider
+	deliveries  repository.WebhookDeliveryRepository
+	tx          repository.Transactor
+	maxBodySize int64
+	log         logger.Logger
+}
+
+// NewReceiver creates a receiver for providers, each verified with its secret
+// from cfg. A provider without a secret is a configuration error.
+func NewReceiver(
+	providers []Provider,
+	deliveries repository.WebhookDeliveryRepository,
+	tx repository.Transactor,
+	cfg config.WebhookConfig,
+	log logger.Logger,
+) (*Receiver, error) {
+	r := &Receiver{
+		providers:   make(map[string]*provider, len(providers)),
+		deliveries:  deliveries,
+		tx:          tx,
+		maxBodySize: cfg.MaxBodySize,
+		log:         log,
+	}
+
+	for _, p := range providers {
+		if _, exists := r.providers[p.Name]; exists {
+			return nil, fmt.Errorf("webhook provider %q is registered twice", p.Name)
+		}
+		if p.Scheme == nil || p.Handle == nil {
+			return nil, fmt.Errorf("webhook provider %q needs a scheme and a handler", p.Name)
+		}
+		secret := cfg.Secrets[p.Name]
+		if secret == "" {
+			return nil, fmt.Errorf("webhook.secrets.%s is required", p.Name)
+		}
+		verifier, err := p.Scheme(secret, cfg.Tolerance)
+		if err != nil {
+			return nil, fmt.Errorf("webhook provider %q: %w", p.Name, err)
+		}
+		if p.Parse == nil {
+			p.Parse = ParseJSON
+		}
+		r.providers[p.Name] = &provider{Provider: p, verifier: verifier}
+	}
+
+	return r, nil
+}
+
+// Register serves the deliveries of the providers at POST /webhooks/:provider.
+// The routes are authenticated by the signatures, not by tokens.
+func (r *Receiver) Register(router gin.IRouter) {
+	router.POST("/webhooks/:provider", middleware.RawBodyMiddleware(r.maxBodySize), r.Verify(), r.Handle)
+}
+
+// Verify rejects deliveries to unknown providers or with an invalid signature.
+// It must run after middleware.RawBodyMiddleware.
+func (r *Receiver) Verify() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		p, ok := r.providers[c.Param("provider")]
+		if !ok {
+			response.NotFound(c, "Unknown webhook provider")
+			c.Abort()
+			return
+		}
+
+		body, _ := middleware.GetRawBody(c)
+		if err := p.verifier.Verify(c.Request.Header, body); err != nil {
+			metrics.Add("rejected", 1)
+			logger.Ctx(c.Request.Context(), r.log).Warn("Rejected webhook",
+				zap.String("provider", p.Name),
+				zap.Error(err),
+			)
+			response.Unauthorized(c, "Invalid webhook signature")
+			c.Abort()
+			return
+		}
+
+		c.Set("webhook_provider", p)
+		c.Next()
+	}
+}
+
+// Handle processes a verified delivery once. Redeliveries are acknowledged
+// without processing; failures answer 500 so the provider retries.
+func (r *Receiver) Handle(c *gin.Context) {
+	value, ok := c.Get("webhook_provider")
+	if !ok {
+		response.NotFound(c, "Unknown webhook provider")
+		return
+	}
+	p := value.(*provider)
+	body, _ := middleware.GetRawBody(c)
+
+	id, eventType, err := p.Parse(c.Request.Header, body)
+	if err != nil {
+		response.BadRequest(c, "Invalid webhook payload", err.Error())
+		return
+	}
+
+	delivery := &Delivery{
+		Provider: p.Name,
+		ID:       id,
+		Type:     eventType,
+		Header:   c.Request.Header,
+		Body:     body,
+	}
+	log := logger.Ctx(c.Request.Context(), r.log).With(
+		zap.String("provider", p.Name),
+		zap.String("delivery_id", id),
+		zap.String("event_type", eventType),
+	)
+
+	processed, err := r.process(c.Request.Context(), p, delivery)
+	if err != nil {
+		metrics.Add("failed", 1)
+		log.Error("Failed to process webhook", zap.Error(err))
+		response.InternalServerError(c, "Failed to process webhook", nil)
+		return
+	}
+	if !processed {
+		metrics.Add("duplicate", 1)
+		log.Info("Skipped redelivered webhook")
+		response.Success(c, "Webhook already processed", nil)
+		return
+	}
+
+	metrics.Add("processed", 1)
+	log.Info("Processed webhook")
+	response.Success(c, "Webhook processed successfully", nil)
+}
+
+// process records and handles a delivery in one transaction, reporting false
+// when it was processed before. The insert holds the unique key of the
+// delivery, so concurrent redeliveries wait for it and then fail.
+func (r *Receiver) process(ctx context.Context, p *provider, d *Delivery) (bool, error) {
+	processed := false
+	err := r.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		exists, err := r.deliveries.Exists(ctx, d.Provider, d.ID)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return nil
+		}
+
+		record := &domain.WebhookDelivery{Provider: d.Provider, DeliveryID: d.ID, EventType: d.Type}
+		if err := r.deliveries.Create(ctx, record); err != nil {
+			return err
+		}
+		if err := p.Handle(ctx, d); err != nil {
+			return err
+		}
+		processed = true
+		return nil
+	})
+	return processed, err
+}
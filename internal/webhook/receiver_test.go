@@ -0,0 +1,137 @@
+package webhook_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/mocks"
+	"github.com/firdanbash/go-clean-boiler/internal/testutil"
+	"github.com/firdanbash/go-clean-boiler/internal/webhook"
+	"github.com/firdanbash/go-clean-boiler/pkg/config"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/mock/gomock"
+)
+
+const testSecret = "secret"
+
+var webhookConfig = config.WebhookConfig{
+	MaxBodySize: 1024,
+	Tolerance:   5 * time.Minute,
+	Secrets:     map[string]string{"payments": testSecret},
+}
+
+func newReceiver(t *testing.T, handle webhook.Handler) (*gin.Engine, *mocks.MockWebhookDeliveryRepository) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	deliveries := mocks.NewMockWebhookDeliveryRepository(gomock.NewController(t))
+	provider := webhook.Provider{
+		Name:   "payments",
+		Scheme: webhook.HMAC("X-Signature", ""),
+		Handle: handle,
+	}
+	receiver, err := webhook.NewReceiver([]webhook.Provider{provider}, deliveries, testutil.Transactor(), webhookConfig, logger.Nop())
+	if err != nil {
+		t.Fatalf("NewReceiver() error = %v", err)
+	}
+
+	router := gin.New()
+	receiver.Register(router)
+	return router, deliveries
+}
+
+func deliver(router *gin.Engine, provider string, body []byte, secret string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/"+provider, bytes.NewReader(body))
+	req.Header.Set("X-Signature", hex.EncodeToString(hmacSHA256([]byte(secret), string(body))))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestReceiverProcessesDeliveryOnce(t *testing.T) {
+	var handled []*webhook.Delivery
+	router, deliveries := newReceiver(t, func(_ context.Context, d *webhook.Delivery) error {
+		handled = append(handled, d)
+		return nil
+	})
+	body := []byte(`{"id":"evt_1","type":"invoice.paid"}`)
+
+	gomock.InOrder(
+		deliveries.EXPECT().Exists(gomock.Any(), "payments", "evt_1").Return(false, nil),
+		deliveries.EXPECT().Create(gomock.Any(), &domain.WebhookDelivery{Provider: "payments", DeliveryID: "evt_1", EventType: "invoice.paid"}).Return(nil),
+		deliveries.EXPECT().Exists(gomock.Any(), "payments", "evt_1").Return(true, nil),
+	)
+
+	for i := 0; i < 2; i++ {
+		if w := deliver(router, "payments", body, testSecret); w.Code != http.StatusOK {
+			t.Fatalf("delivery %d status = %d, want 200: %s", i+1, w.Code, w.Body)
+		}
+	}
+	if len(handled) != 1 {
+		t.Fatalf("handled %d deliveries, want 1", len(handled))
+	}
+	if d := handled[0]; d.ID != "evt_1" || d.Type != "invoice.paid" || !bytes.Equal(d.Body, body) {
+		t.Fatalf("handled delivery = %+v", d)
+	}
+}
+
+func TestReceiverRejectsDeliveries(t *testing.T) {
+	router, _ := newReceiver(t, func(context.Context, *webhook.Delivery) error {
+		t.Fatal("handler called for a rejected delivery")
+		return nil
+	})
+
+	tests := []struct {
+		name     string
+		provider string
+		body     string
+		secret   string
+		want     int
+	}{
+		{"unknown provider", "unknown", `{"id":"1"}`, testSecret, http.StatusNotFound},
+		{"invalid signature", "payments", `{"id":"1"}`, "other", http.StatusUnauthorized},
+		{"missing id", "payments", `{"type":"invoice.paid"}`, testSecret, http.StatusBadRequest},
+		{"too large", "payments", `{"id":"` + string(bytes.Repeat([]byte("a"), 2048)) + `"}`, testSecret, http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := deliver(router, tt.provider, []byte(tt.body), tt.secret); w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+		})
+	}
+}
+
+func TestReceiverAnswersFailuresForRetry(t *testing.T) {
+	router, deliveries := newReceiver(t, func(context.Context, *webhook.Delivery) error {
+		return errors.New("downstream unavailable")
+	})
+
+	deliveries.EXPECT().Exists(gomock.Any(), "payments", "evt_1").Return(false, nil)
+	deliveries.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+
+	if w := deliver(router, "payments", []byte(`{"id":"evt_1"}`), testSecret); w.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500: %s", w.Code, w.Body)
+	}
+}
+
+func TestNewReceiverRequiresSecret(t *testing.T) {
+	provider := webhook.Provider{
+		Name:   "identity",
+		Scheme: webhook.StandardWebhooks(),
+		Handle: func(context.Context, *webhook.Delivery) error { return nil },
+	}
+	deliveries := mocks.NewMockWebhookDeliveryRepository(gomock.NewController(t))
+
+	if _, err := webhook.NewReceiver([]webhook.Provider{provider}, deliveries, testutil.Transactor(), webhookConfig, logger.Nop()); err == nil {
+		t.Fatal("NewReceiver() error = nil, want a missing secret error")
+	}
+}
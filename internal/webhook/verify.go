@@ -0,0 +1,136 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidSignature is returned when a delivery is not signed with the
+// secret of its provider, or its signature is too old
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// Verifier checks that a delivery was sent by its provider
+type Verifier interface {
+	Verify(header http.Header, body []byte) error
+}
+
+// VerifierFunc adapts a function to a Verifier
+type VerifierFunc func(header http.Header, body []byte) error
+
+// Verify calls f
+func (f VerifierFunc) Verify(header http.Header, body []byte) error {
+	return f(header, body)
+}
+
+// Scheme creates the verifier of a provider from its secret. Timestamped
+// schemes reject signatures older than tolerance.
+type Scheme func(secret string, tolerance time.Duration) (Verifier, error)
+
+// HMAC verifies the hex encoded HMAC-SHA256 of the body sent in header after
+// prefix, e.g. HMAC("X-Hub-Signature-256", "sha256=") for GitHub. The
+// signature does not cover a timestamp, so replays are only caught by the
+// delivery ID.
+func HMAC(header, prefix string) Scheme {
+	return func(secret string, _ time.Duration) (Verifier, error) {
+		return VerifierFunc(func(h http.Header, body []byte) error {
+			signature, ok := strings.CutPrefix(h.Get(header), prefix)
+			if !ok {
+				return ErrInvalidSignature
+			}
+			got, err := hex.DecodeString(signature)
+			if err != nil || !hmac.Equal(got, sign([]byte(secret), body)) {
+				return ErrInvalidSignature
+			}
+			return nil
+		}), nil
+	}
+}
+
+// StandardWebhooks verifies deliveries signed as specified by Standard
+// Webhooks (https://www.standardwebhooks.com), as sent by Svix and its users.
+// The secret is the whsec_ prefixed base64 key.
+func StandardWebhooks() Scheme {
+	return func(secret string, tolerance time.Duration) (Verifier, error) {
+		key, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(secret, "whsec_"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid standard webhooks secret: %w", err)
+		}
+
+		return VerifierFunc(func(h http.Header, body []byte) error {
+			id, timestamp := h.Get("Webhook-Id"), h.Get("Webhook-Timestamp")
+			if id == "" || !fresh(timestamp, tolerance) {
+				return ErrInvalidSignature
+			}
+
+			expected := sign(key, []byte(id+"."+timestamp+"."), body)
+			// Several space separated signatures are sent while keys rotate
+			for _, signature := range strings.Fields(h.Get("Webhook-Signature")) {
+				version, value, _ := strings.Cut(signature, ",")
+				got, err := base64.StdEncoding.DecodeString(value)
+				if version == "v1" && err == nil && hmac.Equal(got, expected) {
+					return nil
+				}
+			}
+			return ErrInvalidSignature
+		}), nil
+	}
+}
+
+// Stripe verifies the Stripe-Signature header of Stripe webhooks. The secret
+// is the whsec_ signing secret of the endpoint.
+func Stripe() Scheme {
+	return func(secret string, tolerance time.Duration) (Verifier, error) {
+		return VerifierFunc(func(h http.Header, body []byte) error {
+			var timestamp string
+			var signatures []string
+			for _, part := range strings.Split(h.Get("Stripe-Signature"), ",") {
+				key, value, _ := strings.Cut(part, "=")
+				switch key {
+				case "t":
+					timestamp = value
+				case "v1":
+					signatures = append(signatures, value)
+				}
+			}
+			if !fresh(timestamp, tolerance) {
+				return ErrInvalidSignature
+			}
+
+			expected := sign([]byte(secret), []byte(timestamp+"."), body)
+			for _, signature := range signatures {
+				got, err := hex.DecodeString(signature)
+				if err == nil && hmac.Equal(got, expected) {
+					return nil
+				}
+			}
+			return ErrInvalidSignature
+		}), nil
+	}
+}
+
+// sign returns the HMAC-SHA256 of the concatenated parts
+func sign(key []byte, parts ...[]byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	for _, part := range parts {
+		mac.Write(part)
+	}
+	return mac.Sum(nil)
+}
+
+// fresh reports whether a Unix timestamp is within tolerance of now, either way
+func fresh(timestamp string, tolerance time.Duration) bool {
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	age := time.Since(time.Unix(seconds, 0))
+	return age <= tolerance && age >= -tolerance
+}
@@ -0,0 +1,120 @@
+package webhook_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/firdanbash/go-clean-boiler/internal/webhook"
+)
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func verifier(t *testing.T, scheme webhook.Scheme, secret string) webhook.Verifier {
+	t.Helper()
+	v, err := scheme(secret, 5*time.Minute)
+	if err != nil {
+		t.Fatalf("scheme() error = %v", err)
+	}
+	return v
+}
+
+func TestHMAC(t *testing.T) {
+	v := verifier(t, webhook.HMAC("X-Hub-Signature-256", "sha256="), "secret")
+	body := []byte(`{"id":"1"}`)
+	valid := "sha256=" + hex.EncodeToString(hmacSHA256([]byte("secret"), string(body)))
+
+	tests := []struct {
+		name      string
+		signature string
+		wantErr   bool
+	}{
+		{"valid", valid, false},
+		{"missing", "", true},
+		{"missing prefix", valid[len("sha256="):], true},
+		{"other secret", "sha256=" + hex.EncodeToString(hmacSHA256([]byte("other"), string(body))), true},
+		{"not hex", "sha256=zz", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			header.Set("X-Hub-Signature-256", tt.signature)
+			if err := v.Verify(header, body); (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStandardWebhooks(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	v := verifier(t, webhook.StandardWebhooks(), "whsec_"+base64.StdEncoding.EncodeToString(key))
+	body := []byte(`{"type":"invoice.paid"}`)
+
+	signed := func(timestamp time.Time, signingKey []byte) http.Header {
+		ts := strconv.FormatInt(timestamp.Unix(), 10)
+		header := http.Header{}
+		header.Set("Webhook-Id", "msg_1")
+		header.Set("Webhook-Timestamp", ts)
+		header.Set("Webhook-Signature", "v1,"+base64.StdEncoding.EncodeToString(hmacSHA256(signingKey, "msg_1."+ts+"."+string(body))))
+		return header
+	}
+
+	if err := v.Verify(signed(time.Now(), key), body); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+
+	rotated := signed(time.Now(), key)
+	rotated.Set("Webhook-Signature", "v1,b2xk "+rotated.Get("Webhook-Signature"))
+	if err := v.Verify(rotated, body); err != nil {
+		t.Fatalf("Verify() with several signatures error = %v", err)
+	}
+
+	if err := v.Verify(signed(time.Now().Add(-time.Hour), key), body); err == nil {
+		t.Fatal("Verify() of an old signature error = nil")
+	}
+	if err := v.Verify(signed(time.Now(), []byte("other")), body); err == nil {
+		t.Fatal("Verify() of another key error = nil")
+	}
+	if err := v.Verify(signed(time.Now(), key), []byte(`{"type":"invoice.void"}`)); err == nil {
+		t.Fatal("Verify() of a changed body error = nil")
+	}
+
+	if _, err := webhook.StandardWebhooks()("whsec_not base64", time.Minute); err == nil {
+		t.Fatal("StandardWebhooks() with an invalid secret error = nil")
+	}
+}
+
+func TestStripe(t *testing.T) {
+	v := verifier(t, webhook.Stripe(), "whsec_test")
+	body := []byte(`{"id":"evt_1","type":"charge.succeeded"}`)
+
+	signed := func(timestamp time.Time, secret string) http.Header {
+		ts := strconv.FormatInt(timestamp.Unix(), 10)
+		header := http.Header{}
+		header.Set("Stripe-Signature", "t="+ts+",v1="+hex.EncodeToString(hmacSHA256([]byte(secret), ts+"."+string(body)))+",v0=ignored")
+		return header
+	}
+
+	if err := v.Verify(signed(time.Now(), "whsec_test"), body); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if err := v.Verify(signed(time.Now().Add(-time.Hour), "whsec_test"), body); err == nil {
+		t.Fatal("Verify() of an old signature error = nil")
+	}
+	if err := v.Verify(signed(time.Now(), "whsec_other"), body); err == nil {
+		t.Fatal("Verify() of another secret error = nil")
+	}
+	if err := v.Verify(http.Header{}, body); err == nil {
+		t.Fatal("Verify() without a signature error = nil")
+	}
+}
@@ -0,0 +1,71 @@
+// Package webhook receives the webhooks of third parties, such as payment or
+// identity providers. The receiver checks the signature of each delivery with
+// the scheme and secret of its provider and processes a delivery once, however
+// often the provider sends it. Integrations only provide the processing.
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// ErrMissingID is returned when a delivery has no ID to detect redeliveries with
+var ErrMissingID = errors.New("webhook delivery has no id")
+
+// Provider is a third party sending webhooks to POST /webhooks/<Name>
+type Provider struct {
+	// Name is the path segment of the provider and the key of its secret
+	// under webhook.secrets
+	Name string
+	// Scheme is how the provider signs deliveries
+	Scheme Scheme
+	// Parse returns the ID and event type of a delivery; ParseJSON by default
+	Parse func(header http.Header, body []byte) (id, eventType string, err error)
+	// Handle processes a delivery
+	Handle Handler
+}
+
+// Delivery is a webhook whose signature was verified
+type Delivery struct {
+	Provider string
+	ID       string
+	Type     string
+	Header   http.Header
+	Body     []byte
+}
+
+// Handler processes a delivery. It runs in the transaction recording the
+// delivery: repositories called with ctx join it, and returning an error rolls
+// both back, so the retry of the provider processes the delivery again. Work
+// outside the database should be idempotent or deferred, e.g. to the outbox.
+type Handler func(ctx context.Context, d *Delivery) error
+
+// ParseJSON reads the delivery ID from the Webhook-Id header of Standard
+// Webhooks or the "id" field of the JSON body, and the event type from its
+// "type" field, as most providers send them
+func ParseJSON(header http.Header, body []byte) (string, string, error) {
+	var payload struct {
+		ID   json.RawMessage `json:"id"`
+		Type string          `json:"type"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return "", "", err
+	}
+
+	id := header.Get("Webhook-Id")
+	if id == "" && len(payload.ID) > 0 {
+		// IDs are strings or numbers
+		var s string
+		if err := json.Unmarshal(payload.ID, &s); err != nil {
+			s = string(payload.ID)
+		}
+		id = s
+	}
+	if id == "" || id == "null" {
+		return "", "", ErrMissingID
+	}
+
+	return id, payload.Type, nil
+}
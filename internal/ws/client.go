@@ -0,0 +1,120 @@
+package ws
+
+import (
+	"sync"
+	"time"
+
+	"github.com/firdanbash/go-clean-boiler/pkg/config"
+	"github.com/firdanbash/go-clean-boiler/pkg/jwt"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// sendBuffer is how many events may wait for a client before it is
+	// considered too slow and disconnected
+	sendBuffer = 32
+	// maxMessageSize limits what clients may send; they are only expected to
+	// answer pings
+	maxMessageSize = 512
+)
+
+// Client is a WebSocket connection of an authenticated user
+type Client struct {
+	hub       *Hub
+	conn      *websocket.Conn
+	channels  []string
+	expiresAt time.Time
+	cfg       config.WebSocketConfig
+
+	send        chan []byte
+	done        chan struct{}
+	once        sync.Once
+	closeCode   int
+	closeReason string
+}
+
+// newClient creates a client for conn subscribed to the channels of the
+// user the claims belong to
+func newClient(hub *Hub, conn *websocket.Conn, claims *jwt.Claims, cfg config.WebSocketConfig) *Client {
+	return &Client{
+		hub:       hub,
+		conn:      conn,
+		channels:  []string{userChannel(claims.UserID), roleChannel(claims.TenantID, claims.Role)},
+		expiresAt: claims.ExpiresAt.Time,
+		cfg:       cfg,
+		send:      make(chan []byte, sendBuffer),
+		done:      make(chan struct{}),
+	}
+}
+
+// enqueue queues message for sending, disconnecting the client if its queue is full
+func (c *Client) enqueue(message []byte) {
+	select {
+	case c.send <- message:
+	default:
+		c.stop(websocket.ClosePolicyViolation, "client too slow")
+	}
+}
+
+// stop asks the connection to close with code and reason
+func (c *Client) stop(code int, reason string) {
+	c.once.Do(func() {
+		c.closeCode, c.closeReason = code, reason
+		close(c.done)
+	})
+}
+
+// readPump reads until the connection fails, keeping it alive on pongs.
+// Messages sent by the client are ignored.
+func (c *Client) readPump() {
+	pongWait := c.cfg.PingInterval + c.cfg.WriteTimeout
+	c.conn.SetReadLimit(maxMessageSize)
+	_ = c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		if _, _, err := c.conn.ReadMessage(); err != nil {
+			c.stop(websocket.CloseNormalClosure, "")
+			return
+		}
+	}
+}
+
+// writePump owns the writes to the connection: queued events, pings, and the
+// close message when the client is stopped or its token expires
+func (c *Client) writePump() {
+	ticker := time.NewTicker(c.cfg.PingInterval)
+	expiry := time.NewTimer(time.Until(c.expiresAt))
+	defer func() {
+		ticker.Stop()
+		expiry.Stop()
+		c.hub.unregister(c)
+		c.conn.Close()
+	}()
+
+	for {
+		select {
+		case message := <-c.send:
+			if err := c.write(websocket.TextMessage, message); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if err := c.write(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		case <-expiry.C:
+			_ = c.write(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "token expired"))
+			return
+		case <-c.done:
+			_ = c.write(websocket.CloseMessage, websocket.FormatCloseMessage(c.closeCode, c.closeReason))
+			return
+		}
+	}
+}
+
+func (c *Client) write(messageType int, data []byte) error {
+	_ = c.conn.SetWriteDeadline(time.Now().Add(c.cfg.WriteTimeout))
+	return c.conn.WriteMessage(messageType, data)
+}
@@ -0,0 +1,103 @@
+package ws
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/firdanbash/go-clean-boiler/internal/middleware"
+	"github.com/firdanbash/go-clean-boiler/pkg/config"
+	"github.com/firdanbash/go-clean-boiler/pkg/jwt"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"github.com/firdanbash/go-clean-boiler/pkg/response"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+// Handler upgrades authenticated requests to WebSocket connections on the hub
+type Handler struct {
+	hub        *Hub
+	upgrader   websocket.Upgrader
+	jwtManager *jwt.Manager
+	denylist   jwt.Denylist
+	cfg        config.WebSocketConfig
+	log        logger.Logger
+}
+
+// NewHandler creates the WebSocket handler, or returns nil when WebSocket is
+// disabled
+func NewHandler(hub *Hub, jwtManager *jwt.Manager, denylist jwt.Denylist, cfg *config.Config, log logger.Logger) *Handler {
+	if !cfg.WebSocket.Enabled {
+		return nil
+	}
+	return &Handler{
+		hub:        hub,
+		upgrader:   websocket.Upgrader{CheckOrigin: checkOrigin(cfg.WebSocket.AllowedOrigins)},
+		jwtManager: jwtManager,
+		denylist:   denylist,
+		cfg:        cfg.WebSocket,
+		log:        log,
+	}
+}
+
+// Serve opens a connection that receives the events of the authenticated
+// user. Browsers, which cannot set headers on WebSocket requests, pass the
+// access token in the access_token query parameter instead of the
+// Authorization header.
+func (h *Handler) Serve(c *gin.Context) {
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		token := c.Query("access_token")
+		if token == "" {
+			response.Error(c, middleware.ErrTokenMissing, "")
+			return
+		}
+		var err error
+		claims, err = h.jwtManager.ValidateTokenWithDenylist(c.Request.Context(), token, h.denylist)
+		if err != nil {
+			if errors.Is(err, jwt.ErrRevokedToken) {
+				response.Error(c, middleware.ErrTokenRevoked, "")
+			} else {
+				response.Error(c, middleware.ErrTokenInvalid, "")
+			}
+			return
+		}
+	}
+
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// The upgrader has already answered with the error
+		logger.Ctx(c.Request.Context(), h.log).Debug("WebSocket upgrade failed", zap.Error(err))
+		return
+	}
+
+	client := newClient(h.hub, conn, claims, h.cfg)
+	if !h.hub.register(client) {
+		_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down"))
+		conn.Close()
+		return
+	}
+	go client.writePump()
+	go client.readPump()
+}
+
+// checkOrigin allows the origins in allowed, or any origin for "*". Without
+// allowed origins only same-origin requests are allowed.
+func checkOrigin(allowed []string) func(r *http.Request) bool {
+	if len(allowed) == 0 {
+		return nil
+	}
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			return true
+		}
+		for _, o := range allowed {
+			if o == "*" || strings.EqualFold(o, origin) {
+				return true
+			}
+		}
+		return false
+	}
+}
@@ -0,0 +1,133 @@
+// Package ws pushes domain events to clients connected over WebSocket. Each
+// connection belongs to the user of the access token it was opened with and
+// receives the events about that user; admins also receive the events about
+// every user of their tenant.
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+// userChannel is the channel of the events about the user with id
+func userChannel(id uint) string {
+	return fmt.Sprintf("user:%d", id)
+}
+
+// roleChannel is the channel of the events for the users of tenantID with role
+func roleChannel(tenantID uint, role string) string {
+	return fmt.Sprintf("tenant:%d:role:%s", tenantID, role)
+}
+
+// Hub tracks the connected clients by channel and delivers events to them.
+// It only knows the clients connected to this instance.
+type Hub struct {
+	mu       sync.RWMutex
+	channels map[string]map[*Client]struct{}
+	closed   bool
+	clients  sync.WaitGroup
+	log      logger.Logger
+}
+
+// NewHub creates a new hub without clients
+func NewHub(log logger.Logger) *Hub {
+	return &Hub{channels: make(map[string]map[*Client]struct{}), log: log}
+}
+
+// register subscribes c to its channels. It returns false once the hub is closed.
+func (h *Hub) register(c *Client) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return false
+	}
+	for _, channel := range c.channels {
+		if h.channels[channel] == nil {
+			h.channels[channel] = make(map[*Client]struct{})
+		}
+		h.channels[channel][c] = struct{}{}
+	}
+	h.clients.Add(1)
+	return true
+}
+
+// unregister removes c from its channels
+func (h *Hub) unregister(c *Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, channel := range c.channels {
+		delete(h.channels[channel], c)
+		if len(h.channels[channel]) == 0 {
+			delete(h.channels, channel)
+		}
+	}
+	h.clients.Done()
+}
+
+// Publish delivers event to the clients of the user it is about and of the
+// admins of their tenant.
+// Clients too slow to keep up are disconnected rather than blocking.
+func (h *Hub) Publish(ctx context.Context, event domain.Event) {
+	message, err := json.Marshal(event)
+	if err != nil {
+		logger.Ctx(ctx, h.log).Error("Failed to encode event", zap.String("type", event.Type), zap.Error(err))
+		return
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	recipients := make(map[*Client]struct{})
+	for _, channel := range []string{userChannel(event.UserID), roleChannel(event.TenantID, domain.RoleAdmin)} {
+		for c := range h.channels[channel] {
+			recipients[c] = struct{}{}
+		}
+	}
+	for c := range recipients {
+		c.enqueue(message)
+	}
+}
+
+// Clients returns the number of connected clients
+func (h *Hub) Clients() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	clients := make(map[*Client]struct{})
+	for _, members := range h.channels {
+		for c := range members {
+			clients[c] = struct{}{}
+		}
+	}
+	return len(clients)
+}
+
+// Close disconnects every client and refuses new ones, then waits until the
+// connections are closed or ctx is done
+func (h *Hub) Close(ctx context.Context) error {
+	h.mu.Lock()
+	h.closed = true
+	for _, members := range h.channels {
+		for c := range members {
+			c.stop(websocket.CloseGoingAway, "server shutting down")
+		}
+	}
+	h.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		h.clients.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
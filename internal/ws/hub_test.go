@@ -0,0 +1,140 @@
+package ws_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/firdanbash/go-clean-boiler/internal/domain"
+	"github.com/firdanbash/go-clean-boiler/internal/middleware"
+	"github.com/firdanbash/go-clean-boiler/internal/testutil"
+	"github.com/firdanbash/go-clean-boiler/internal/ws"
+	"github.com/firdanbash/go-clean-boiler/pkg/config"
+	"github.com/firdanbash/go-clean-boiler/pkg/jwt"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"github.com/gorilla/websocket"
+)
+
+// newHubServer serves /ws on a test server and returns its WebSocket URL
+func newHubServer(t *testing.T) (*ws.Hub, string, *jwt.Manager) {
+	t.Helper()
+	m := testutil.JWTManager(t)
+	hub := ws.NewHub(logger.Nop())
+	cfg := &config.Config{WebSocket: config.WebSocketConfig{Enabled: true, PingInterval: time.Minute, WriteTimeout: time.Second}}
+	h := ws.NewHandler(hub, m, nil, cfg, logger.Nop())
+
+	r, _ := testutil.Router(m)
+	r.GET("/ws", middleware.OptionalAuthMiddleware(m, nil), h.Serve)
+	srv := httptest.NewServer(r)
+	t.Cleanup(func() {
+		_ = hub.Close(context.Background())
+		srv.Close()
+	})
+	return hub, "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws", m
+}
+
+// connect opens a connection as user and waits until the hub knows it
+func connect(t *testing.T, hub *ws.Hub, url string, m *jwt.Manager, user *domain.User) *websocket.Conn {
+	t.Helper()
+	before := hub.Clients()
+	conn, _, err := websocket.DefaultDialer.Dial(url+"?access_token="+testutil.Token(t, m, user), nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	for deadline := time.Now().Add(time.Second); hub.Clients() == before; {
+		if time.Now().After(deadline) {
+			t.Fatal("client not registered")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return conn
+}
+
+// nextEvent reads the next event, or returns "" when none arrives shortly
+func nextEvent(t *testing.T, conn *websocket.Conn) string {
+	t.Helper()
+	_ = conn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+	_, message, err := conn.ReadMessage()
+	if err != nil {
+		return ""
+	}
+	var event struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(message, &event); err != nil {
+		t.Fatalf("decode %s: %v", message, err)
+	}
+	return event.Type
+}
+
+func TestHubRequiresToken(t *testing.T) {
+	_, url, _ := newHubServer(t)
+
+	for _, target := range []string{url, url + "?access_token=not-a-token"} {
+		_, resp, err := websocket.DefaultDialer.Dial(target, nil)
+		if err == nil || resp == nil || resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("%s: want a 401, got %v", target, err)
+		}
+	}
+}
+
+func TestHubDeliversEventsToOwnerAndAdmins(t *testing.T) {
+	hub, url, m := newHubServer(t)
+	owner := connect(t, hub, url, m, testutil.NewUser(testutil.WithID(2)))
+	other := connect(t, hub, url, m, testutil.NewUser(testutil.WithID(3)))
+	admin := connect(t, hub, url, m, testutil.NewUser(testutil.WithID(1), testutil.AsAdmin()))
+
+	hub.Publish(context.Background(), domain.NewEvent(domain.EventUserUpdated, testutil.NewUser(testutil.WithID(2)), map[string]uint{"id": 2}))
+
+	if got := nextEvent(t, owner); got != domain.EventUserUpdated {
+		t.Errorf("owner got %q, want %q", got, domain.EventUserUpdated)
+	}
+	if got := nextEvent(t, admin); got != domain.EventUserUpdated {
+		t.Errorf("admin got %q, want %q", got, domain.EventUserUpdated)
+	}
+	if got := nextEvent(t, other); got != "" {
+		t.Errorf("another user got %q, want nothing", got)
+	}
+}
+
+func TestHubKeepsTenantsApart(t *testing.T) {
+	hub, url, m := newHubServer(t)
+	owner := connect(t, hub, url, m, testutil.NewUser(testutil.WithID(2), testutil.WithTenant(1)))
+	admin := connect(t, hub, url, m, testutil.NewUser(testutil.WithID(1), testutil.WithTenant(1), testutil.AsAdmin()))
+	otherAdmin := connect(t, hub, url, m, testutil.NewUser(testutil.WithID(3), testutil.WithTenant(2), testutil.AsAdmin()))
+
+	user := testutil.NewUser(testutil.WithID(2), testutil.WithTenant(1))
+	hub.Publish(context.Background(), domain.NewEvent(domain.EventUserUpdated, user, map[string]uint{"id": 2}))
+
+	if got := nextEvent(t, owner); got != domain.EventUserUpdated {
+		t.Errorf("owner got %q, want %q", got, domain.EventUserUpdated)
+	}
+	if got := nextEvent(t, admin); got != domain.EventUserUpdated {
+		t.Errorf("admin of the tenant got %q, want %q", got, domain.EventUserUpdated)
+	}
+	if got := nextEvent(t, otherAdmin); got != "" {
+		t.Errorf("admin of another tenant got %q, want nothing", got)
+	}
+}
+
+func TestHubCloseDisconnectsClients(t *testing.T) {
+	hub, url, m := newHubServer(t)
+	conn := connect(t, hub, url, m, testutil.NewUser(testutil.WithID(2)))
+
+	if err := hub.Close(context.Background()); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	_, _, err := conn.ReadMessage()
+	if !websocket.IsCloseError(err, websocket.CloseGoingAway) {
+		t.Errorf("read error = %v, want a going away close", err)
+	}
+	if hub.Clients() != 0 {
+		t.Errorf("Clients() = %d after Close, want 0", hub.Clients())
+	}
+}
@@ -0,0 +1,34 @@
+package ws
+
+import (
+	"context"
+
+	"github.com/firdanbash/go-clean-boiler/internal/service"
+	"go.uber.org/fx"
+)
+
+// Module provides the hub, as the publisher of the domain events of the
+// services, and the handler clients connect to
+var Module = fx.Module("ws",
+	fx.Provide(
+		NewHub,
+		newEventPublisher,
+		NewHandler,
+	),
+	fx.Invoke(closeOnStop),
+)
+
+// newEventPublisher publishes the domain events to the hub
+func newEventPublisher(hub *Hub) service.EventPublisher {
+	return hub
+}
+
+// closeOnStop disconnects the clients on shutdown; the HTTP server does not
+// wait for upgraded connections
+func closeOnStop(lc fx.Lifecycle, hub *Hub) {
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			return hub.Close(ctx)
+		},
+	})
+}
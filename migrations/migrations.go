@@ -0,0 +1,10 @@
+// Package migrations embeds the versioned SQL migrations so the binary can apply
+// them without the migration files on disk.
+package migrations
+
+import "embed"
+
+// FS holds one directory of migrations per database driver (postgres, mysql)
+//
+//go:embed postgres/*.sql mysql/*.sql
+var FS embed.FS
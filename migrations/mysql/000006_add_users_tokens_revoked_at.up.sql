@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN tokens_revoked_at DATETIME(3) NULL;
@@ -0,0 +1,2 @@
+ALTER TABLE users ADD COLUMN avatar_key VARCHAR(255) NULL;
+ALTER TABLE users ADD COLUMN avatar_url VARCHAR(1024) NULL;
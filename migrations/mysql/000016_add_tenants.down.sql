@@ -0,0 +1,10 @@
+ALTER TABLE audit_logs
+    DROP INDEX idx_audit_logs_tenant_id,
+    DROP COLUMN tenant_id;
+
+ALTER TABLE users
+    DROP INDEX idx_users_tenant_email,
+    DROP COLUMN tenant_id,
+    ADD UNIQUE KEY idx_users_email (email);
+
+DROP TABLE IF EXISTS tenants;
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS tenants (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    slug VARCHAR(63) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    created_at DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    updated_at DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    UNIQUE KEY idx_tenants_slug (slug)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Emails are unique per tenant; 0 is the tenant of rows created without one
+ALTER TABLE users
+    ADD COLUMN tenant_id BIGINT UNSIGNED NOT NULL DEFAULT 0 AFTER id,
+    DROP INDEX idx_users_email,
+    ADD UNIQUE KEY idx_users_tenant_email (tenant_id, email);
+
+ALTER TABLE audit_logs
+    ADD COLUMN tenant_id BIGINT UNSIGNED NOT NULL DEFAULT 0 AFTER id,
+    ADD KEY idx_audit_logs_tenant_id (tenant_id);
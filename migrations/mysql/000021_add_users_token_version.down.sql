@@ -0,0 +1,2 @@
+ALTER TABLE users ADD COLUMN tokens_revoked_at DATETIME(3) NULL;
+ALTER TABLE users DROP COLUMN token_version;
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS sessions (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    user_id BIGINT UNSIGNED NOT NULL,
+    token_id VARCHAR(64) NOT NULL,
+    token_version BIGINT UNSIGNED NOT NULL DEFAULT 0,
+    ip VARCHAR(64) NULL,
+    user_agent TEXT NULL,
+    expires_at DATETIME(3) NOT NULL,
+    created_at DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    UNIQUE KEY idx_sessions_token_id (token_id),
+    KEY idx_sessions_user_id (user_id),
+    KEY idx_sessions_expires_at (expires_at),
+    CONSTRAINT fk_sessions_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
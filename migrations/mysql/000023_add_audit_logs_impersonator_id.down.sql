@@ -0,0 +1,3 @@
+DROP INDEX idx_audit_logs_impersonator_id ON audit_logs;
+
+ALTER TABLE audit_logs DROP COLUMN impersonator_id;
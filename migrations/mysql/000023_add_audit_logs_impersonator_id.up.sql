@@ -0,0 +1,3 @@
+ALTER TABLE audit_logs ADD COLUMN impersonator_id BIGINT UNSIGNED NULL AFTER actor_id;
+
+CREATE INDEX idx_audit_logs_impersonator_id ON audit_logs(impersonator_id);
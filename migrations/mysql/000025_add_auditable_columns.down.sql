@@ -0,0 +1,9 @@
+DROP INDEX idx_permissions_created_by ON permissions;
+DROP INDEX idx_roles_created_by ON roles;
+DROP INDEX idx_organizations_created_by ON organizations;
+DROP INDEX idx_users_created_by ON users;
+
+ALTER TABLE permissions DROP COLUMN updated_by, DROP COLUMN created_by;
+ALTER TABLE roles DROP COLUMN updated_by, DROP COLUMN created_by;
+ALTER TABLE organizations DROP COLUMN updated_by, DROP COLUMN created_by;
+ALTER TABLE users DROP COLUMN updated_by, DROP COLUMN created_by;
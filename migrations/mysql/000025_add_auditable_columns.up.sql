@@ -0,0 +1,17 @@
+ALTER TABLE users
+    ADD COLUMN created_by BIGINT UNSIGNED NULL,
+    ADD COLUMN updated_by BIGINT UNSIGNED NULL;
+ALTER TABLE organizations
+    ADD COLUMN created_by BIGINT UNSIGNED NULL,
+    ADD COLUMN updated_by BIGINT UNSIGNED NULL;
+ALTER TABLE roles
+    ADD COLUMN created_by BIGINT UNSIGNED NULL,
+    ADD COLUMN updated_by BIGINT UNSIGNED NULL;
+ALTER TABLE permissions
+    ADD COLUMN created_by BIGINT UNSIGNED NULL,
+    ADD COLUMN updated_by BIGINT UNSIGNED NULL;
+
+CREATE INDEX idx_users_created_by ON users(created_by);
+CREATE INDEX idx_organizations_created_by ON organizations(created_by);
+CREATE INDEX idx_roles_created_by ON roles(created_by);
+CREATE INDEX idx_permissions_created_by ON permissions(created_by);
@@ -0,0 +1 @@
+CREATE FULLTEXT INDEX idx_users_search ON users (name, email);
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_audit_logs_tenant_id;
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS tenant_id;
+
+DROP INDEX IF EXISTS idx_users_tenant_email;
+ALTER TABLE users DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE (email);
+CREATE INDEX IF NOT EXISTS idx_users_email ON users(email);
+
+DROP TABLE IF EXISTS tenants;
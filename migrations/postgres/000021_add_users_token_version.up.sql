@@ -0,0 +1,6 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS token_version INTEGER NOT NULL DEFAULT 0;
+
+-- Tokens issued before a revocation carry no version; start revoked users at 1 so they stay invalid
+UPDATE users SET token_version = 1 WHERE tokens_revoked_at IS NOT NULL;
+
+ALTER TABLE users DROP COLUMN IF EXISTS tokens_revoked_at;
@@ -0,0 +1,3 @@
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS impersonator_id BIGINT;
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_impersonator_id ON audit_logs(impersonator_id);
@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS idx_permissions_created_by;
+DROP INDEX IF EXISTS idx_roles_created_by;
+DROP INDEX IF EXISTS idx_organizations_created_by;
+DROP INDEX IF EXISTS idx_users_created_by;
+
+ALTER TABLE permissions DROP COLUMN IF EXISTS updated_by;
+ALTER TABLE permissions DROP COLUMN IF EXISTS created_by;
+ALTER TABLE roles DROP COLUMN IF EXISTS updated_by;
+ALTER TABLE roles DROP COLUMN IF EXISTS created_by;
+ALTER TABLE organizations DROP COLUMN IF EXISTS updated_by;
+ALTER TABLE organizations DROP COLUMN IF EXISTS created_by;
+ALTER TABLE users DROP COLUMN IF EXISTS updated_by;
+ALTER TABLE users DROP COLUMN IF EXISTS created_by;
@@ -0,0 +1,13 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS created_by BIGINT;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS updated_by BIGINT;
+ALTER TABLE organizations ADD COLUMN IF NOT EXISTS created_by BIGINT;
+ALTER TABLE organizations ADD COLUMN IF NOT EXISTS updated_by BIGINT;
+ALTER TABLE roles ADD COLUMN IF NOT EXISTS created_by BIGINT;
+ALTER TABLE roles ADD COLUMN IF NOT EXISTS updated_by BIGINT;
+ALTER TABLE permissions ADD COLUMN IF NOT EXISTS created_by BIGINT;
+ALTER TABLE permissions ADD COLUMN IF NOT EXISTS updated_by BIGINT;
+
+CREATE INDEX IF NOT EXISTS idx_users_created_by ON users(created_by);
+CREATE INDEX IF NOT EXISTS idx_organizations_created_by ON organizations(created_by);
+CREATE INDEX IF NOT EXISTS idx_roles_created_by ON roles(created_by);
+CREATE INDEX IF NOT EXISTS idx_permissions_created_by ON permissions(created_by);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_users_search;
+
+ALTER TABLE users DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,179 @@
+// Package apperror defines the errors services return to describe what went
+// wrong independently of the transport. Each error has a Kind that handlers
+// map to a status code, so a failed lookup and a failed query no longer end
+// up with the same response.
+package apperror
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Kind classifies an error
+type Kind int
+
+// Error kinds
+const (
+	KindInternal Kind = iota
+	KindValidation
+	KindUnauthorized
+	KindForbidden
+	KindNotFound
+	KindConflict
+	KindTooManyRequests
+)
+
+// String returns the name of the kind
+func (k Kind) String() string {
+	switch k {
+	case KindValidation:
+		return "validation"
+	case KindUnauthorized:
+		return "unauthorized"
+	case KindForbidden:
+		return "forbidden"
+	case KindNotFound:
+		return "not found"
+	case KindConflict:
+		return "conflict"
+	case KindTooManyRequests:
+		return "too many requests"
+	default:
+		return "internal"
+	}
+}
+
+// HTTPStatus returns the status code responses for the kind use
+func (k Kind) HTTPStatus() int {
+	switch k {
+	case KindValidation:
+		return http.StatusBadRequest
+	case KindUnauthorized:
+		return http.StatusUnauthorized
+	case KindForbidden:
+		return http.StatusForbidden
+	case KindNotFound:
+		return http.StatusNotFound
+	case KindConflict:
+		return http.StatusConflict
+	case KindTooManyRequests:
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// Error is an error of a known kind. Message is safe to show to clients; Err,
+// when set, is the underlying cause and is only logged. Code, when set,
+// identifies the error more precisely than its kind.
+type Error struct {
+	Kind    Kind
+	Code    Code
+	Message string
+	Err     error
+}
+
+// Sentinels matching any error of their kind with errors.Is
+var (
+	ErrValidation      = &Error{Kind: KindValidation}
+	ErrUnauthorized    = &Error{Kind: KindUnauthorized}
+	ErrForbidden       = &Error{Kind: KindForbidden}
+	ErrNotFound        = &Error{Kind: KindNotFound}
+	ErrConflict        = &Error{Kind: KindConflict}
+	ErrTooManyRequests = &Error{Kind: KindTooManyRequests}
+)
+
+// New creates an error of the given kind
+func New(kind Kind, message string) *Error {
+	return &Error{Kind: kind, Message: message}
+}
+
+// Wrap creates an error of the given kind caused by err
+func Wrap(err error, kind Kind, message string) *Error {
+	return &Error{Kind: kind, Message: message, Err: err}
+}
+
+// Validation creates an error for input the operation cannot accept
+func Validation(message string) *Error {
+	return New(KindValidation, message)
+}
+
+// Unauthorized creates an error for missing or invalid credentials
+func Unauthorized(message string) *Error {
+	return New(KindUnauthorized, message)
+}
+
+// Forbidden creates an error for an operation the caller may not perform
+func Forbidden(message string) *Error {
+	return New(KindForbidden, message)
+}
+
+// NotFound creates an error for a missing resource
+func NotFound(message string) *Error {
+	return New(KindNotFound, message)
+}
+
+// Conflict creates an error for a request that clashes with existing state
+func Conflict(message string) *Error {
+	return New(KindConflict, message)
+}
+
+// TooManyRequests creates an error for an operation refused until the caller
+// slows down
+func TooManyRequests(message string) *Error {
+	return New(KindTooManyRequests, message)
+}
+
+// WithCode returns a copy of the error with code
+func (e *Error) WithCode(code Code) *Error {
+	copied := *e
+	copied.Code = code
+	return &copied
+}
+
+// Error returns the client-facing message
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return e.Kind.String()
+	}
+	return e.Message
+}
+
+// Unwrap returns the cause
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is the sentinel of the error's kind
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Message == "" && t.Err == nil && t.Kind == e.Kind
+}
+
+// KindOf returns the kind of the first Error in err's chain, or KindInternal
+// when there is none
+func KindOf(err error) Kind {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr.Kind
+	}
+	return KindInternal
+}
+
+// HTTPStatus returns the status code of err's kind
+func HTTPStatus(err error) int {
+	return KindOf(err).HTTPStatus()
+}
+
+// CodeOf returns the code of the first Error in err's chain, or the code of
+// its kind when it has none, or CodeInternal when there is none
+func CodeOf(err error) Code {
+	var appErr *Error
+	if !errors.As(err, &appErr) {
+		return CodeInternal
+	}
+	if appErr.Code != "" {
+		return appErr.Code
+	}
+	return appErr.Kind.Code()
+}
@@ -0,0 +1,133 @@
+package apperror
+
+// Code is a stable, machine-readable identifier of an error, sent as the code
+// of error responses. Clients branch on codes rather than on messages, which
+// are translated and may be reworded. Codes are never renamed once released.
+type Code string
+
+// Generic codes, of errors without a more specific one
+const (
+	CodeBadRequest       Code = "BAD_REQUEST"
+	CodeValidationFailed Code = "VALIDATION_FAILED"
+	CodeUnauthorized     Code = "UNAUTHORIZED"
+	CodeForbidden        Code = "FORBIDDEN"
+	CodeNotFound         Code = "NOT_FOUND"
+	CodeConflict         Code = "CONFLICT"
+	CodeGone             Code = "GONE"
+	CodePayloadTooLarge  Code = "PAYLOAD_TOO_LARGE"
+	CodeTooManyRequests  Code = "TOO_MANY_REQUESTS"
+	CodeInternal         Code = "INTERNAL_ERROR"
+	CodeTimeout          Code = "REQUEST_TIMEOUT"
+)
+
+// Authentication
+const (
+	CodeAuthInvalidCredentials Code = "AUTH_INVALID_CREDENTIALS"
+	CodeAuthTooManyAttempts    Code = "AUTH_TOO_MANY_ATTEMPTS"
+	CodeAuthCaptchaRequired    Code = "AUTH_CAPTCHA_REQUIRED"
+	CodeAuthTokenMissing       Code = "AUTH_TOKEN_MISSING"
+	CodeAuthTokenMalformed     Code = "AUTH_TOKEN_MALFORMED"
+	CodeAuthTokenInvalid       Code = "AUTH_TOKEN_INVALID"
+	CodeAuthTokenRevoked       Code = "AUTH_TOKEN_REVOKED"
+	CodeAuthTokenWrongTenant   Code = "AUTH_TOKEN_WRONG_TENANT"
+	CodeAuthTokenNotRevocable  Code = "AUTH_TOKEN_NOT_REVOCABLE"
+	CodeAuthResetTokenInvalid  Code = "AUTH_RESET_TOKEN_INVALID"
+	CodeAuthImpersonateSelf    Code = "AUTH_IMPERSONATE_SELF"
+	CodeAuthImpersonateAdmin   Code = "AUTH_IMPERSONATE_ADMIN"
+	CodeAuthImpersonateAbove   Code = "AUTH_IMPERSONATE_ABOVE"
+	CodeAuthImpersonating      Code = "AUTH_IMPERSONATING"
+	CodeMFAAlreadyEnabled      Code = "MFA_ALREADY_ENABLED"
+	CodeMFANotStarted          Code = "MFA_NOT_STARTED"
+	CodeMFANotEnabled          Code = "MFA_NOT_ENABLED"
+	CodeMFACodeInvalid         Code = "MFA_CODE_INVALID"
+	CodeMFATokenInvalid        Code = "MFA_TOKEN_INVALID"
+	CodePasswordIncorrect      Code = "PASSWORD_INCORRECT"
+	CodePasswordUnchanged      Code = "PASSWORD_UNCHANGED"
+	CodePasswordReused         Code = "PASSWORD_REUSED"
+	CodeSessionNotFound        Code = "SESSION_NOT_FOUND"
+)
+
+// Users
+const (
+	CodeUserNotFound           Code = "USER_NOT_FOUND"
+	CodeUserDeletedNotFound    Code = "USER_DELETED_NOT_FOUND"
+	CodeUserEmailTaken         Code = "USER_EMAIL_TAKEN"
+	CodeUserIDsInvalid         Code = "USER_IDS_INVALID"
+	CodeUserExportFieldInvalid Code = "USER_EXPORT_FIELD_INVALID"
+	CodeAvatarNotFound         Code = "AVATAR_NOT_FOUND"
+	CodeAvatarInvalidType      Code = "AVATAR_INVALID_TYPE"
+	CodeAvatarTooLarge         Code = "AVATAR_TOO_LARGE"
+	CodeDataExportNotFound     Code = "DATA_EXPORT_NOT_FOUND"
+	CodeTaskNotFound           Code = "TASK_NOT_FOUND"
+)
+
+// Files
+const (
+	CodeFileNotFound           Code = "FILE_NOT_FOUND"
+	CodeFileTypeNotAllowed     Code = "FILE_TYPE_NOT_ALLOWED"
+	CodeFileEmpty              Code = "FILE_EMPTY"
+	CodeFileTooLarge           Code = "FILE_TOO_LARGE"
+	CodeFilePresignUnsupported Code = "FILE_PRESIGN_UNSUPPORTED"
+	CodeUploadNotFound         Code = "UPLOAD_NOT_FOUND"
+	CodeUploadCompleted        Code = "UPLOAD_COMPLETED"
+)
+
+// Tenants, organizations, roles and permissions
+const (
+	CodeTenantNotFound             Code = "TENANT_NOT_FOUND"
+	CodeTenantExists               Code = "TENANT_EXISTS"
+	CodeTenantSlugInvalid          Code = "TENANT_SLUG_INVALID"
+	CodeOrganizationNotFound       Code = "ORGANIZATION_NOT_FOUND"
+	CodeOrganizationMemberNotFound Code = "ORGANIZATION_MEMBER_NOT_FOUND"
+	CodeOrganizationAlreadyMember  Code = "ORGANIZATION_ALREADY_MEMBER"
+	CodeOrganizationLastOwner      Code = "ORGANIZATION_LAST_OWNER"
+	CodeOrganizationAdminRequired  Code = "ORGANIZATION_ADMIN_REQUIRED"
+	CodeRoleNotFound               Code = "ROLE_NOT_FOUND"
+	CodeRoleExists                 Code = "ROLE_EXISTS"
+	CodeRoleNotAssigned            Code = "ROLE_NOT_ASSIGNED"
+	CodePermissionNotFound         Code = "PERMISSION_NOT_FOUND"
+	CodePermissionExists           Code = "PERMISSION_EXISTS"
+	CodePermissionInvalid          Code = "PERMISSION_INVALID"
+	CodePermissionUnknown          Code = "PERMISSION_UNKNOWN"
+)
+
+// Feature flags, notifications and search
+const (
+	CodeFeatureFlagNotFound         Code = "FEATURE_FLAG_NOT_FOUND"
+	CodeFeatureFlagsReadOnly        Code = "FEATURE_FLAGS_READ_ONLY"
+	CodeNotificationTypeUnknown     Code = "NOTIFICATION_TYPE_UNKNOWN"
+	CodeNotificationChannelUnknown  Code = "NOTIFICATION_CHANNEL_UNKNOWN"
+	CodeNotificationChannelRequired Code = "NOTIFICATION_CHANNEL_REQUIRED"
+	CodeSearchDisabled              Code = "SEARCH_DISABLED"
+	CodeSearchTooDeep               Code = "SEARCH_TOO_DEEP"
+)
+
+// Lists: sorting, filtering and sparse fieldsets
+const (
+	CodeSortFieldInvalid      Code = "SORT_FIELD_INVALID"
+	CodeFilterInvalid         Code = "FILTER_INVALID"
+	CodeFilterFieldUnknown    Code = "FILTER_FIELD_UNKNOWN"
+	CodeFilterOperatorInvalid Code = "FILTER_OPERATOR_INVALID"
+	CodeFilterValueInvalid    Code = "FILTER_VALUE_INVALID"
+	CodeFieldUnknown          Code = "FIELD_UNKNOWN"
+)
+
+// Code returns the generic code of the kind
+func (k Kind) Code() Code {
+	switch k {
+	case KindValidation:
+		return CodeValidationFailed
+	case KindUnauthorized:
+		return CodeUnauthorized
+	case KindForbidden:
+		return CodeForbidden
+	case KindNotFound:
+		return CodeNotFound
+	case KindConflict:
+		return CodeConflict
+	case KindTooManyRequests:
+		return CodeTooManyRequests
+	default:
+		return CodeInternal
+	}
+}
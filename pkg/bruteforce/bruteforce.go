@@ -0,0 +1,146 @@
+// Package bruteforce throttles repeated failed attempts on an account, such
+// as password guesses, independently of the IP they come from
+package bruteforce
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+// Policy decides how failed attempts are throttled
+type Policy struct {
+	FreeAttempts int           // failures allowed before attempts are delayed
+	BaseDelay    time.Duration // delay after the first failure past the free ones, doubled by every further failure
+	MaxDelay     time.Duration // longest delay
+	Window       time.Duration // failures are forgotten after this long without a new one
+	CaptchaAfter int           // failures after which a captcha is required; 0 never requires one
+}
+
+// Status describes the failed attempts on an account
+type Status struct {
+	Failures        int
+	RetryAfter      time.Duration // positive while attempts are refused
+	CaptchaRequired bool
+}
+
+// Store keeps the failed attempts per key
+type Store interface {
+	// Get returns the failures of key and how long it is still locked
+	Get(ctx context.Context, key string) (failures int, lockedFor time.Duration, err error)
+	// Fail counts a failure of key, kept for window after the last one, and
+	// returns the failures
+	Fail(ctx context.Context, key string, window time.Duration) (int, error)
+	// Lock refuses attempts on key for d
+	Lock(ctx context.Context, key string, d time.Duration) error
+	// Reset forgets the failures of key
+	Reset(ctx context.Context, key string) error
+}
+
+// Guard applies a Policy to the accounts, keeping their failures in a Store.
+// A Guard without a store throttles nothing.
+type Guard struct {
+	store  Store
+	policy Policy
+}
+
+// NewGuard creates a guard counting failures in store
+func NewGuard(store Store, policy Policy) *Guard {
+	return &Guard{store: store, policy: policy}
+}
+
+// Check returns the status of account before an attempt
+func (g *Guard) Check(ctx context.Context, account string) (Status, error) {
+	if g.store == nil {
+		return Status{}, nil
+	}
+	failures, lockedFor, err := g.store.Get(ctx, key(account))
+	if err != nil {
+		return Status{}, err
+	}
+	return g.status(failures, lockedFor), nil
+}
+
+// Fail counts a failed attempt on account, delaying the next attempts once
+// the free ones are used up, and returns the new status
+func (g *Guard) Fail(ctx context.Context, account string) (Status, error) {
+	if g.store == nil {
+		return Status{}, nil
+	}
+	k := key(account)
+	failures, err := g.store.Fail(ctx, k, g.policy.Window)
+	if err != nil {
+		return Status{}, err
+	}
+
+	delay := g.delay(failures)
+	if delay > 0 {
+		if err := g.store.Lock(ctx, k, delay); err != nil {
+			return Status{}, err
+		}
+	}
+	return g.status(failures, delay), nil
+}
+
+// Reset forgets the failures of account, after a successful attempt
+func (g *Guard) Reset(ctx context.Context, account string) error {
+	if g.store == nil {
+		return nil
+	}
+	return g.store.Reset(ctx, key(account))
+}
+
+// Counter counts the failures of keys within a window, without delaying
+// attempts, for callers enforcing their own limit
+type Counter struct {
+	store  Store
+	window time.Duration
+}
+
+// NewCounter creates a counter keeping failures in store for window after the last one
+func NewCounter(store Store, window time.Duration) *Counter {
+	return &Counter{store: store, window: window}
+}
+
+// Fail counts a failure of account and returns its failures
+func (c *Counter) Fail(ctx context.Context, account string) (int, error) {
+	return c.store.Fail(ctx, key(account), c.window)
+}
+
+// Reset forgets the failures of account
+func (c *Counter) Reset(ctx context.Context, account string) error {
+	return c.store.Reset(ctx, key(account))
+}
+
+// delay returns how long attempts are refused after failures failures
+func (g *Guard) delay(failures int) time.Duration {
+	over := failures - g.policy.FreeAttempts
+	if over <= 0 {
+		return 0
+	}
+	delay := g.policy.BaseDelay
+	for i := 1; i < over && delay < g.policy.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > g.policy.MaxDelay {
+		delay = g.policy.MaxDelay
+	}
+	return delay
+}
+
+func (g *Guard) status(failures int, lockedFor time.Duration) Status {
+	return Status{
+		Failures:        failures,
+		RetryAfter:      lockedFor,
+		CaptchaRequired: g.policy.CaptchaAfter > 0 && failures >= g.policy.CaptchaAfter,
+	}
+}
+
+// key identifies account in the store without keeping the account itself,
+// e.g. an email address, in it
+func key(account string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(account))))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,105 @@
+package bruteforce
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// memorySweepInterval is how often forgotten accounts are dropped from memory
+const memorySweepInterval = time.Minute
+
+type memoryEntry struct {
+	failures    int
+	expiresAt   time.Time
+	lockedUntil time.Time
+}
+
+type memoryStore struct {
+	mu        sync.Mutex
+	entries   map[string]*memoryEntry
+	lastSweep time.Time
+}
+
+// NewMemoryStore creates a store held in process memory. Failures are counted
+// per instance, so it suits single-node deployments where Redis is not configured.
+func NewMemoryStore() Store {
+	return &memoryStore{
+		entries:   make(map[string]*memoryEntry),
+		lastSweep: time.Now(),
+	}
+}
+
+// Get returns the failures of key and how long it is still locked
+func (s *memoryStore) Get(ctx context.Context, key string) (int, time.Duration, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok || !now.Before(entry.expiresAt) {
+		return 0, 0, nil
+	}
+	return entry.failures, lockedFor(entry, now), nil
+}
+
+// Fail counts a failure of key, kept for window after the last one
+func (s *memoryStore) Fail(ctx context.Context, key string, window time.Duration) (int, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+
+	entry, ok := s.entries[key]
+	if !ok || !now.Before(entry.expiresAt) {
+		entry = &memoryEntry{}
+		s.entries[key] = entry
+	}
+	entry.failures++
+	entry.expiresAt = now.Add(window)
+	return entry.failures, nil
+}
+
+// Lock refuses attempts on key for d
+func (s *memoryStore) Lock(ctx context.Context, key string, d time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, ok := s.entries[key]; ok {
+		entry.lockedUntil = time.Now().Add(d)
+	}
+	return nil
+}
+
+// Reset forgets the failures of key
+func (s *memoryStore) Reset(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
+
+// sweep drops the entries whose failures are forgotten
+func (s *memoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < memorySweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, entry := range s.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+}
+
+func lockedFor(entry *memoryEntry, now time.Time) time.Duration {
+	if now.Before(entry.lockedUntil) {
+		return entry.lockedUntil.Sub(now)
+	}
+	return 0
+}
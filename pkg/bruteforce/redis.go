@@ -0,0 +1,70 @@
+package bruteforce
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type redisStore struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisStore creates a store shared by every replica using the same Redis,
+// so failures count across instances
+func NewRedisStore(client *redis.Client, prefix string) Store {
+	return &redisStore{client: client, prefix: prefix}
+}
+
+func (s *redisStore) failuresKey(key string) string {
+	return s.prefix + key + ":failures"
+}
+
+func (s *redisStore) lockKey(key string) string {
+	return s.prefix + key + ":lock"
+}
+
+// Get returns the failures of key and how long it is still locked
+func (s *redisStore) Get(ctx context.Context, key string) (int, time.Duration, error) {
+	pipe := s.client.Pipeline()
+	failures := pipe.Get(ctx, s.failuresKey(key))
+	lock := pipe.PTTL(ctx, s.lockKey(key))
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return 0, 0, err
+	}
+
+	count, err := failures.Int()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return 0, 0, err
+	}
+	// Redis reports a negative TTL for a missing key
+	lockedFor := lock.Val()
+	if lockedFor < 0 {
+		lockedFor = 0
+	}
+	return count, lockedFor, nil
+}
+
+// Fail counts a failure of key, kept for window after the last one
+func (s *redisStore) Fail(ctx context.Context, key string, window time.Duration) (int, error) {
+	pipe := s.client.TxPipeline()
+	incr := pipe.Incr(ctx, s.failuresKey(key))
+	pipe.PExpire(ctx, s.failuresKey(key), window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return int(incr.Val()), nil
+}
+
+// Lock refuses attempts on key for d
+func (s *redisStore) Lock(ctx context.Context, key string, d time.Duration) error {
+	return s.client.Set(ctx, s.lockKey(key), 1, d).Err()
+}
+
+// Reset forgets the failures of key
+func (s *redisStore) Reset(ctx context.Context, key string) error {
+	return s.client.Del(ctx, s.failuresKey(key), s.lockKey(key)).Err()
+}
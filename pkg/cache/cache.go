@@ -0,0 +1,43 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// ErrMiss is returned when a key is not present in the cache
+var ErrMiss = errors.New("cache miss")
+
+// Cache defines the interface for key/value caches used by services
+type Cache interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+	TTL(ctx context.Context, key string) (time.Duration, error)
+}
+
+// GetJSON loads key and decodes it into a value of type T
+func GetJSON[T any](ctx context.Context, c Cache, key string) (T, error) {
+	var value T
+
+	data, err := c.Get(ctx, key)
+	if err != nil {
+		return value, err
+	}
+
+	if err := json.Unmarshal(data, &value); err != nil {
+		return value, err
+	}
+	return value, nil
+}
+
+// SetJSON encodes value as JSON and stores it under key
+func SetJSON[T any](ctx context.Context, c Cache, key string, value T, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return c.Set(ctx, key, data, ttl)
+}
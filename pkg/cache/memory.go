@@ -0,0 +1,105 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// memorySweepInterval is how often expired entries are dropped from memory
+const memorySweepInterval = time.Minute
+
+type memoryEntry struct {
+	value     []byte
+	expiresAt time.Time // zero when the entry does not expire
+}
+
+func (e memoryEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+type memoryCache struct {
+	mu        sync.Mutex
+	entries   map[string]memoryEntry
+	lastSweep time.Time
+}
+
+// NewMemoryCache creates a cache held in process memory. Entries are per
+// instance, so it suits single-node deployments where Redis is not configured.
+func NewMemoryCache() Cache {
+	return &memoryCache{
+		entries:   make(map[string]memoryEntry),
+		lastSweep: time.Now(),
+	}
+}
+
+// Get returns the value stored under key, or ErrMiss
+func (c *memoryCache) Get(ctx context.Context, key string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || entry.expired(time.Now()) {
+		return nil, ErrMiss
+	}
+	return entry.value, nil
+}
+
+// Set stores value under key; a zero ttl keeps it until deleted
+func (c *memoryCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	now := time.Now()
+	entry := memoryEntry{value: value}
+	if ttl > 0 {
+		entry.expiresAt = now.Add(ttl)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.sweep(now)
+	c.entries[key] = entry
+	return nil
+}
+
+// Delete removes the given keys
+func (c *memoryCache) Delete(ctx context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		delete(c.entries, key)
+	}
+	return nil
+}
+
+// TTL returns the remaining time to live of key, or ErrMiss.
+// Keys stored without expiry report a negative duration.
+func (c *memoryCache) TTL(ctx context.Context, key string) (time.Duration, error) {
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || entry.expired(now) {
+		return 0, ErrMiss
+	}
+	if entry.expiresAt.IsZero() {
+		return -1, nil
+	}
+	return entry.expiresAt.Sub(now), nil
+}
+
+// sweep drops the expired entries
+func (c *memoryCache) sweep(now time.Time) {
+	if now.Sub(c.lastSweep) < memorySweepInterval {
+		return
+	}
+	c.lastSweep = now
+
+	for key, entry := range c.entries {
+		if entry.expired(now) {
+			delete(c.entries, key)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+type noopCache struct{}
+
+// NewNoopCache creates a cache that stores nothing, used when Redis is not configured
+func NewNoopCache() Cache {
+	return noopCache{}
+}
+
+// Get always reports a miss
+func (noopCache) Get(ctx context.Context, key string) ([]byte, error) {
+	return nil, ErrMiss
+}
+
+// Set discards the value
+func (noopCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return nil
+}
+
+// Delete does nothing
+func (noopCache) Delete(ctx context.Context, keys ...string) error {
+	return nil
+}
+
+// TTL always reports a miss
+func (noopCache) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return 0, ErrMiss
+}
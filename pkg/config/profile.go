@@ -0,0 +1,59 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/viper"
+)
+
+// configDirs are searched, in order, for the base config file and its overlays
+var configDirs = []string{"./config", "."}
+
+// profileAliases maps APP_ENV values to the short overlay names also accepted,
+// so APP_ENV=production loads config.production.yaml or config.prod.yaml
+var profileAliases = map[string]string{
+	"development": "dev",
+	"production":  "prod",
+}
+
+// profileFile is the env-specific overlay merged over the base config, if any
+var profileFile string
+
+// findProfile locates config.<env>.yaml for the environment selected by APP_ENV
+// (or app.env in the base file)
+func findProfile() string {
+	env := viper.GetString("app.env")
+	if env == "" {
+		return ""
+	}
+
+	names := []string{env}
+	if alias, ok := profileAliases[env]; ok {
+		names = append(names, alias)
+	}
+	for _, dir := range configDirs {
+		for _, name := range names {
+			path := filepath.Join(dir, "config."+name+".yaml")
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				return path
+			}
+		}
+	}
+	return ""
+}
+
+// mergeProfile merges the env-specific overlay over the base config held by viper
+func mergeProfile() error {
+	if profileFile == "" {
+		return nil
+	}
+
+	overlay := viper.New()
+	overlay.SetConfigFile(profileFile)
+	if err := overlay.ReadInConfig(); err != nil {
+		return fmt.Errorf("read config overlay %s: %w", profileFile, err)
+	}
+	return viper.MergeConfigMap(overlay.AllSettings())
+}
@@ -0,0 +1,482 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"golang.org/x/text/language"
+)
+
+// DefaultJWTSecret is the placeholder secret shipped in config.yaml and the defaults
+const DefaultJWTSecret = "your-secret-key-change-this-in-production"
+
+// minProductionSecretLength is the shortest HMAC secret accepted in production
+const minProductionSecretLength = 32
+
+// minPepperLength is the shortest password pepper accepted
+const minPepperLength = 32
+
+// ValidationError lists every problem found in a Config
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// Validate checks the configuration for missing, malformed and insecure values.
+// All problems are reported together in a *ValidationError.
+func (c *Config) Validate() error {
+	v := &validation{}
+	production := c.App.Env == "production"
+
+	// App and server
+	v.port("app.port", c.App.Port)
+	v.positive("server.read_timeout", c.Server.ReadTimeout)
+	v.positive("server.write_timeout", c.Server.WriteTimeout)
+	v.positive("server.idle_timeout", c.Server.IdleTimeout)
+	v.positive("server.shutdown_timeout", c.Server.ShutdownTimeout)
+	v.positive("server.health_check_timeout", c.Server.HealthCheckTimeout)
+	v.check(c.Server.RequestTimeout >= 0, "server.request_timeout must not be negative")
+	v.check(c.Server.RequestTimeout < c.Server.WriteTimeout, "server.request_timeout must be shorter than server.write_timeout, so the timeout response can still be written")
+	v.check(c.Server.Body.MaxSize > 0, "server.body.max_size must be positive")
+	v.check(c.Server.Body.MaxUploadSize >= c.Server.Body.MaxSize, "server.body.max_upload_size must not be smaller than server.body.max_size")
+	v.check(c.Server.Body.MaxJSONDepth > 0, "server.body.max_json_depth must be positive")
+	for _, proxy := range c.Server.Proxy.TrustedProxies {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				v.add("server.proxy.trusted_proxies %q is neither an IP nor a CIDR", proxy)
+			}
+		}
+	}
+	for _, header := range c.Server.Proxy.RemoteIPHeaders {
+		v.check(strings.TrimSpace(header) != "", "server.proxy.remote_ip_headers must not contain empty names")
+	}
+	if c.Server.TLS.Enabled {
+		if c.Server.TLS.Autocert.Enabled {
+			v.check(len(c.Server.TLS.Autocert.Hosts) > 0, "server.tls.autocert.hosts is required when autocert is enabled")
+		} else {
+			v.check(c.Server.TLS.CertFile != "" && c.Server.TLS.KeyFile != "", "server.tls.cert_file and server.tls.key_file are required when TLS is enabled")
+		}
+		if c.Server.TLS.RedirectHTTP || c.Server.TLS.Autocert.Enabled {
+			v.port("server.tls.http_port", c.Server.TLS.HTTPPort)
+		}
+	}
+
+	// CORS
+	for _, origin := range c.CORS.AllowedOrigins {
+		switch {
+		case origin == "*":
+			v.check(!production, "cors.allowed_origins must list the allowed origins instead of * in production")
+			v.check(!c.CORS.AllowCredentials, "cors.allow_credentials cannot be used with the * origin")
+		case !strings.HasPrefix(origin, "http://") && !strings.HasPrefix(origin, "https://"):
+			v.add("cors.allowed_origins %q must start with http:// or https://", origin)
+		case strings.Count(origin, "*") > 1:
+			v.add("cors.allowed_origins %q may contain only one *", origin)
+		}
+	}
+	v.check(c.CORS.MaxAge >= 0, "cors.max_age must not be negative")
+
+	// Compression
+	if c.Compression.Enabled {
+		v.check(c.Compression.GzipLevel == -1 || (c.Compression.GzipLevel >= 1 && c.Compression.GzipLevel <= 9), "compression.gzip_level must be between 1 and 9, or -1")
+		v.check(c.Compression.BrotliLevel >= 0 && c.Compression.BrotliLevel <= 11, "compression.brotli_level must be between 0 and 11")
+		v.check(c.Compression.MinSize >= 0, "compression.min_size must not be negative")
+		v.check(len(c.Compression.ContentTypes) > 0, "compression.content_types must list at least one media type")
+	}
+
+	// gRPC
+	if c.GRPC.Enabled {
+		v.port("grpc.port", c.GRPC.Port)
+		v.check(c.GRPC.Port != c.App.Port, "grpc.port must differ from app.port")
+	}
+
+	// GraphQL
+	if c.GraphQL.Enabled {
+		v.check(c.GraphQL.ComplexityLimit > 0, "graphql.complexity_limit must be greater than 0")
+	}
+
+	// WebSocket
+	if c.WebSocket.Enabled {
+		v.positive("websocket.ping_interval", c.WebSocket.PingInterval)
+		v.positive("websocket.write_timeout", c.WebSocket.WriteTimeout)
+	}
+
+	// Database
+	switch c.Database.Driver {
+	case "sqlite":
+		v.check(c.Database.Name != "", "database.name is required (the SQLite file path or :memory:)")
+	case "postgres", "mysql":
+		v.check(c.Database.Host != "", "database.host is required")
+		v.port("database.port", c.Database.Port)
+		v.check(c.Database.User != "", "database.user is required")
+		v.check(c.Database.Password != "", "database.password is required (set DB_PASSWORD)")
+		v.check(c.Database.Name != "", "database.name is required")
+		if production {
+			v.check(c.Database.SSLMode != "disable", "database.sslmode must not be disable in production")
+		}
+	default:
+		v.add("database.driver %q is not supported (postgres, mysql or sqlite)", c.Database.Driver)
+	}
+	v.check(c.Database.MaxOpenConns >= 0 && c.Database.MaxIdleConns >= 0, "database.max_open_conns and database.max_idle_conns must not be negative")
+	v.check(c.Database.ConnMaxLifetime >= 0, "database.conn_max_lifetime must not be negative")
+	v.check(c.Database.ConnectTimeout >= 0, "database.connect_timeout must not be negative")
+	if c.Database.ConnectTimeout > 0 {
+		v.positive("database.connect_retry_wait_min", c.Database.ConnectRetryWaitMin)
+		v.check(c.Database.ConnectRetryWaitMax >= c.Database.ConnectRetryWaitMin,
+			"database.connect_retry_wait_max must not be less than database.connect_retry_wait_min")
+	}
+	v.positive("database.stats_interval", c.Database.StatsInterval)
+	v.check(c.Database.SlowQueryThreshold >= 0, "database.slow_query_threshold must not be negative")
+	v.check(c.Database.PoolSaturationThreshold >= 0 && c.Database.PoolSaturationThreshold <= 1,
+		"database.pool_saturation_threshold must be between 0 (disabled) and 1")
+
+	// Metrics
+	if c.Metrics.Enabled {
+		v.check(strings.HasPrefix(c.Metrics.Path, "/"), "metrics.path must start with /")
+	}
+
+	// JWT
+	v.positive("jwt.expiration", c.JWT.Expiration)
+	v.check(c.JWT.RememberMeExpiration == 0 || c.JWT.RememberMeExpiration >= c.JWT.Expiration,
+		"jwt.remember_me_expiration must be 0 (disabled) or at least jwt.expiration")
+	if len(c.JWT.Keys) == 0 {
+		v.secret("jwt.secret", c.JWT.Algorithm, c.JWT.Secret, production)
+	} else {
+		current := 0
+		for i, key := range c.JWT.Keys {
+			name := fmt.Sprintf("jwt.keys[%d]", i)
+			v.check(key.ID != "", name+".id is required")
+			algorithm := key.Algorithm
+			if algorithm == "" {
+				algorithm = c.JWT.Algorithm
+			}
+			v.secret(name+".secret", algorithm, key.Secret, production)
+			if key.Current {
+				current++
+			}
+		}
+		v.check(current == 1, "exactly one of jwt.keys must be marked current")
+	}
+
+	// API versions
+	for name, version := range c.API.Versions {
+		if !version.DeprecatedAt.IsZero() && !version.SunsetAt.IsZero() {
+			v.check(version.SunsetAt.After(version.DeprecatedAt), fmt.Sprintf("api.versions.%s.sunset_at must be after deprecated_at", name))
+		}
+	}
+
+	// Auth
+	v.positive("auth.password_reset_expiration", c.Auth.PasswordResetExpiration)
+	v.positive("auth.mfa_challenge_expiration", c.Auth.MFAChallengeExpiration)
+	v.check(c.Auth.MFAMaxAttempts > 0, "auth.mfa_max_attempts must be positive")
+	v.positive("auth.impersonation_expiration", c.Auth.ImpersonationExpiration)
+	v.check(c.Auth.PasswordResetURL != "", "auth.password_reset_url is required")
+	v.check(c.Auth.PasswordPolicy.MinLength >= 6, "auth.password_policy.min_length must be at least 6")
+	switch hash := c.Auth.PasswordHash; hash.Algorithm {
+	case "bcrypt":
+		v.check(hash.BcryptCost >= 4 && hash.BcryptCost <= 31, "auth.password_hash.bcrypt_cost must be between 4 and 31")
+	case "argon2id":
+		argon := hash.Argon2id
+		v.check(argon.Iterations >= 1, "auth.password_hash.argon2id.iterations must be at least 1")
+		v.check(argon.Parallelism >= 1 && argon.Parallelism <= 255, "auth.password_hash.argon2id.parallelism must be between 1 and 255")
+		v.check(argon.Memory >= 8*argon.Parallelism, "auth.password_hash.argon2id.memory must be at least 8 KiB per thread of parallelism")
+		v.check(argon.SaltLength >= 8, "auth.password_hash.argon2id.salt_length must be at least 8 bytes")
+		v.check(argon.KeyLength >= 16, "auth.password_hash.argon2id.key_length must be at least 16 bytes")
+	default:
+		v.add("auth.password_hash.algorithm %q is not supported (bcrypt or argon2id)", hash.Algorithm)
+	}
+	for version, pepper := range c.Auth.PasswordHash.Peppers {
+		v.check(!strings.Contains(version, "$"), fmt.Sprintf("auth.password_hash.peppers version %q must not contain $", version))
+		v.check(len(pepper) >= minPepperLength, fmt.Sprintf("auth.password_hash.peppers.%s must be at least %d characters", version, minPepperLength))
+	}
+	if version := c.Auth.PasswordHash.PepperVersion; version != "" {
+		_, ok := c.Auth.PasswordHash.Peppers[version]
+		v.check(ok, fmt.Sprintf("auth.password_hash.pepper_version %q has no pepper in auth.password_hash.peppers", version))
+	}
+	v.check(c.Auth.PasswordHistory >= 0, "auth.password_history must not be negative")
+	if c.Auth.BruteForce.Enabled {
+		v.check(c.Auth.BruteForce.FreeAttempts >= 0, "auth.brute_force.free_attempts must not be negative")
+		v.positive("auth.brute_force.base_delay", c.Auth.BruteForce.BaseDelay)
+		v.check(c.Auth.BruteForce.MaxDelay >= c.Auth.BruteForce.BaseDelay, "auth.brute_force.max_delay must not be shorter than auth.brute_force.base_delay")
+		v.check(c.Auth.BruteForce.Window >= c.Auth.BruteForce.MaxDelay, "auth.brute_force.window must not be shorter than auth.brute_force.max_delay")
+		v.check(c.Auth.BruteForce.CaptchaAfter >= 0, "auth.brute_force.captcha_after must not be negative")
+	}
+
+	// Mail
+	switch c.Mail.Driver {
+	case "", "log":
+	case "smtp":
+		smtp := c.Mail.SMTP
+		v.check(smtp.Host != "", "mail.smtp.host is required (set MAIL_SMTP_HOST)")
+		v.check(smtp.Port > 0 && smtp.Port <= 65535, "mail.smtp.port must be between 1 and 65535")
+		switch smtp.Encryption {
+		case "starttls", "tls", "none":
+		default:
+			v.add("mail.smtp.encryption %q is not supported (starttls, tls or none)", smtp.Encryption)
+		}
+		v.positive("mail.smtp.timeout", smtp.Timeout)
+	default:
+		v.add("mail.driver %q is not supported (smtp or log)", c.Mail.Driver)
+	}
+	v.check(c.Mail.From != "", "mail.from is required")
+
+	// Notifications
+	for _, channel := range []struct{ name, driver string }{
+		{"sms", c.Notification.SMS.Driver},
+		{"push", c.Notification.Push.Driver},
+	} {
+		switch channel.driver {
+		case "log", "none":
+		default:
+			v.add("notification.%s.driver %q is not supported (log or none)", channel.name, channel.driver)
+		}
+	}
+
+	// Messaging and outbox
+	switch c.Messaging.Driver {
+	case "", "log", "none":
+	case "rabbitmq":
+		rabbit := c.Messaging.RabbitMQ
+		v.check(rabbit.URL != "", "messaging.rabbitmq.url is required (set MESSAGING_RABBITMQ_URL)")
+		v.check(rabbit.Exchange != "", "messaging.rabbitmq.exchange is required")
+		switch rabbit.ExchangeType {
+		case "topic", "direct", "fanout", "headers":
+		default:
+			v.add("messaging.rabbitmq.exchange_type %q is not supported (topic, direct, fanout or headers)", rabbit.ExchangeType)
+		}
+		v.positive("messaging.rabbitmq.publish_timeout", rabbit.PublishTimeout)
+		v.check(rabbit.Prefetch >= 0, "messaging.rabbitmq.prefetch must not be negative")
+		switch rabbit.Requeue {
+		case "never", "once", "always":
+		default:
+			v.add("messaging.rabbitmq.requeue %q is not supported (never, once or always)", rabbit.Requeue)
+		}
+		for name, queue := range rabbit.Queues {
+			if queue.DeadLetter {
+				v.check(rabbit.DeadLetterExchange != "", fmt.Sprintf("messaging.rabbitmq.dead_letter_exchange is required by queue %s", name))
+			}
+		}
+	case "nats":
+		n := c.Messaging.NATS
+		v.check(n.URL != "", "messaging.nats.url is required (set MESSAGING_NATS_URL)")
+		v.check(n.Stream != "" && !strings.ContainsAny(n.Stream, ". *>"), "messaging.nats.stream is required and must not contain spaces, dots or wildcards")
+		v.check(n.SubjectPrefix != "" && !strings.ContainsAny(n.SubjectPrefix, " *>"), "messaging.nats.subject_prefix is required and must not contain spaces or wildcards")
+		v.check(n.Replicas >= 1, "messaging.nats.replicas must be at least 1")
+		v.check(n.MaxAge >= 0, "messaging.nats.max_age must not be negative")
+		v.positive("messaging.nats.duplicate_window", n.DuplicateWindow)
+		v.positive("messaging.nats.publish_timeout", n.PublishTimeout)
+		v.positive("messaging.nats.ack_wait", n.AckWait)
+		v.check(n.MaxDeliver == -1 || n.MaxDeliver > 0, "messaging.nats.max_deliver must be positive, or -1 for no limit")
+		v.check(n.Prefetch >= 0, "messaging.nats.prefetch must not be negative")
+		for name, consumer := range n.Consumers {
+			v.check(len(consumer.Topics) > 0, fmt.Sprintf("messaging.nats.consumers.%s.topics must list at least one topic", name))
+		}
+	default:
+		v.add("messaging.driver %q is not supported (rabbitmq, nats, log or none)", c.Messaging.Driver)
+	}
+	if c.Outbox.Enabled {
+		v.positive("outbox.poll_interval", c.Outbox.PollInterval)
+		v.check(c.Outbox.BatchSize > 0, "outbox.batch_size must be greater than 0")
+		v.check(c.Outbox.MaxAttempts >= 0, "outbox.max_attempts must not be negative")
+	}
+
+	// Webhook
+	v.check(c.Webhook.MaxBodySize > 0, "webhook.max_body_size must be positive")
+	v.check(c.Webhook.MaxBodySize <= c.Server.Body.MaxSize, "webhook.max_body_size must not exceed server.body.max_size, which applies to webhooks as well")
+	v.positive("webhook.tolerance", c.Webhook.Tolerance)
+
+	// Feature flags
+	switch c.FeatureFlags.Store {
+	case "none":
+	case "database":
+		v.positive("feature_flags.refresh_interval", c.FeatureFlags.RefreshInterval)
+	case "redis":
+		v.check(c.Redis.Addr != "", "feature_flags.store redis requires redis.addr")
+		v.positive("feature_flags.refresh_interval", c.FeatureFlags.RefreshInterval)
+	default:
+		v.add("feature_flags.store %q is not supported (none, database or redis)", c.FeatureFlags.Store)
+	}
+
+	// Tenancy
+	if c.Tenancy.Enabled {
+		v.check(c.Tenancy.Header != "", "tenancy.header is required when tenancy is enabled")
+	}
+
+	// Authorization
+	v.positive("authorization.refresh_interval", c.Authz.RefreshInterval)
+
+	// HTTP client
+	v.positive("http_client.timeout", c.HTTPClient.Timeout)
+	v.positive("http_client.dial_timeout", c.HTTPClient.DialTimeout)
+	v.positive("http_client.response_header_timeout", c.HTTPClient.ResponseHeaderTimeout)
+	v.check(c.HTTPClient.MaxRetries >= 0, "http_client.max_retries must not be negative")
+	if c.HTTPClient.MaxRetries > 0 {
+		v.positive("http_client.retry_wait_min", c.HTTPClient.RetryWaitMin)
+		v.check(c.HTTPClient.RetryWaitMax >= c.HTTPClient.RetryWaitMin, "http_client.retry_wait_max must not be less than http_client.retry_wait_min")
+	}
+	if c.HTTPClient.Breaker.Enabled {
+		v.check(c.HTTPClient.Breaker.FailureThreshold > 0, "http_client.breaker.failure_threshold must be greater than 0")
+		v.positive("http_client.breaker.open_timeout", c.HTTPClient.Breaker.OpenTimeout)
+	}
+
+	// Search
+	if c.Search.URL != "" {
+		if u, err := url.Parse(c.Search.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			v.add("search.url %q must be an http:// or https:// URL", c.Search.URL)
+		}
+		v.check(c.Search.APIKey == "" || c.Search.Username == "", "search.api_key and search.username cannot be used together")
+		v.check(c.Search.IndexPrefix == strings.ToLower(c.Search.IndexPrefix) && !strings.ContainsAny(c.Search.IndexPrefix, `\/*?"<>| ,#:`),
+			"search.index_prefix must be lowercase, without spaces or any of \\/*?\"<>|,#:")
+	}
+
+	// I18n
+	if _, err := language.Parse(c.I18n.DefaultLocale); err != nil {
+		v.add("i18n.default_locale %q is not a valid language tag (e.g. en or id)", c.I18n.DefaultLocale)
+	}
+
+	// Storage
+	v.check(c.Storage.MaxAvatarSize > 0, "storage.max_avatar_size must be positive")
+	v.check(c.Storage.MaxFileSize > 0, "storage.max_file_size must be positive")
+	v.check(c.Storage.MaxFileSize < c.Server.Body.MaxUploadSize, "storage.max_file_size must be smaller than server.body.max_upload_size, which also holds the other form fields")
+	v.check(len(c.Storage.AllowedFileTypes) > 0, "storage.allowed_file_types must not be empty")
+	v.positive("storage.presign_expiry", c.Storage.PresignExpiry)
+	v.positive("storage.data_export_expiry", c.Storage.DataExportExpiry)
+	// S3 rejects presigned URLs valid for more than a week
+	v.check(c.Storage.PresignExpiry <= 7*24*time.Hour, "storage.presign_expiry must not exceed 168h")
+	switch c.Storage.Driver {
+	case "local":
+		v.check(c.Storage.Local.Path != "", "storage.local.path is required")
+	case "s3":
+		v.check(c.Storage.S3.Endpoint != "", "storage.s3.endpoint is required")
+		v.check(c.Storage.S3.Bucket != "", "storage.s3.bucket is required")
+	default:
+		v.add("storage.driver %q is not supported (local or s3)", c.Storage.Driver)
+	}
+
+	// Redis, cache and rate limiting
+	if c.Redis.Addr != "" {
+		v.positive("redis.dial_timeout", c.Redis.DialTimeout)
+		v.positive("redis.read_timeout", c.Redis.ReadTimeout)
+		v.positive("redis.write_timeout", c.Redis.WriteTimeout)
+	}
+	if c.Cache.Users.Enabled {
+		v.positive("cache.users.ttl", c.Cache.Users.TTL)
+	}
+	if c.Cache.Responses.Enabled {
+		v.positive("cache.responses.ttl", c.Cache.Responses.TTL)
+		switch c.Cache.Responses.Store {
+		case "memory":
+		case "redis":
+			v.check(c.Redis.Addr != "", "cache.responses.store redis requires redis.addr")
+		default:
+			v.add("cache.responses.store %q is not supported (memory or redis)", c.Cache.Responses.Store)
+		}
+	}
+	if c.RateLimit.Enabled {
+		for name, policy := range c.RateLimit.Policies {
+			v.check(policy.Requests > 0, fmt.Sprintf("rate_limit.policies.%s.requests must be positive", name))
+			v.positive("rate_limit.policies."+name+".window", policy.Window)
+			switch policy.Key {
+			case "", "ip", "user", "api_key":
+			default:
+				v.add("rate_limit.policies.%s.key %q is not supported (ip, user or api_key)", name, policy.Key)
+			}
+			for _, digest := range policy.Keys {
+				v.check(isSHA256Hex(digest), fmt.Sprintf("rate_limit.policies.%s.keys must be SHA-256 hex digests", name))
+			}
+		}
+	}
+
+	// Scheduler
+	for name, job := range c.Scheduler.Jobs {
+		if job.Schedule == "" {
+			continue
+		}
+		if _, err := cron.ParseStandard(job.Schedule); err != nil {
+			v.add("scheduler.jobs.%s.schedule %q is not a valid cron expression: %v", name, job.Schedule, err)
+		}
+		v.check(job.Retention >= 0, fmt.Sprintf("scheduler.jobs.%s.retention must not be negative", name))
+	}
+
+	// Tracing and logging
+	if c.Tracing.Enabled {
+		v.check(c.Tracing.Exporter == "otlp-grpc" || c.Tracing.Exporter == "otlp-http", "tracing.exporter must be otlp-grpc or otlp-http")
+		v.check(c.Tracing.Endpoint != "", "tracing.endpoint is required when tracing is enabled")
+		v.check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio must be between 0 and 1")
+	}
+	switch strings.ToLower(c.Log.Level) {
+	case "debug", "info", "warn", "error", "dpanic", "panic", "fatal":
+	default:
+		v.add("log.level %q is not a valid level", c.Log.Level)
+	}
+	v.check(c.Log.Encoding == "json" || c.Log.Encoding == "console", "log.encoding must be json or console")
+
+	if len(v.problems) > 0 {
+		return &ValidationError{Problems: v.problems}
+	}
+	return nil
+}
+
+// validation collects problems while a Config is checked
+type validation struct {
+	problems []string
+}
+
+func (v *validation) add(format string, args ...interface{}) {
+	v.problems = append(v.problems, fmt.Sprintf(format, args...))
+}
+
+func (v *validation) check(ok bool, problem string) {
+	if !ok {
+		v.problems = append(v.problems, problem)
+	}
+}
+
+func (v *validation) positive(name string, d time.Duration) {
+	if d <= 0 {
+		v.add("%s must be a positive duration (e.g. 30s, 5m), got %s", name, d)
+	}
+}
+
+func (v *validation) port(name, port string) {
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		v.add("%s must be a port number between 1 and 65535, got %q", name, port)
+	}
+}
+
+// secret checks the HMAC secret of a key; asymmetric algorithms use key files instead
+func (v *validation) secret(name, algorithm, secret string, production bool) {
+	if algorithm != "" && !strings.HasPrefix(algorithm, "HS") {
+		return
+	}
+	switch {
+	case secret == "":
+		v.add("%s is required for %s (set JWT_SECRET)", name, algorithmOrDefault(algorithm))
+	case production && secret == DefaultJWTSecret:
+		v.add("%s must be changed from the default value in production", name)
+	case production && len(secret) < minProductionSecretLength:
+		v.add("%s must be at least %d characters in production", name, minProductionSecretLength)
+	}
+}
+
+func algorithmOrDefault(algorithm string) string {
+	if algorithm == "" {
+		return "HS256"
+	}
+	return algorithm
+}
+
+// isSHA256Hex reports whether s is a SHA-256 digest in hex
+func isSHA256Hex(s string) bool {
+	b, err := hex.DecodeString(s)
+	return err == nil && len(b) == sha256.Size
+}
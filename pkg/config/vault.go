@@ -0,0 +1,234 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	vaultapi "github.com/hashicorp/vault/api"
+)
+
+const (
+	vaultAuthToken   = "token"
+	vaultAuthAppRole = "approle"
+
+	vaultRequestTimeout = 10 * time.Second
+	vaultRetryInterval  = 30 * time.Second
+)
+
+// vault is set by Load when Vault is enabled; build then applies its secrets on
+// every load and reload, so rotated secrets are picked up with the config file
+var vault *vaultProvider
+
+// vaultSecretSetters lists the config keys that can be resolved from Vault
+var vaultSecretSetters = map[string]func(*Config, string){
+	"database.user":         func(c *Config, v string) { c.Database.User = v },
+	"database.password":     func(c *Config, v string) { c.Database.Password = v },
+	"jwt.secret":            func(c *Config, v string) { c.JWT.Secret = v },
+	"jwt.private_key":       func(c *Config, v string) { c.JWT.PrivateKey = v },
+	"redis.username":        func(c *Config, v string) { c.Redis.Username = v },
+	"redis.password":        func(c *Config, v string) { c.Redis.Password = v },
+	"storage.s3.access_key": func(c *Config, v string) { c.Storage.S3.AccessKey = v },
+	"storage.s3.secret_key": func(c *Config, v string) { c.Storage.S3.SecretKey = v },
+}
+
+// vaultPepperPrefix starts the keys of password peppers, followed by their
+// version: auth.password_hash.peppers.v2
+const vaultPepperPrefix = "auth.password_hash.peppers."
+
+// vaultSecretSetter returns the setter of a config key resolved from Vault
+func vaultSecretSetter(key string) (func(*Config, string), bool) {
+	if version, ok := strings.CutPrefix(key, vaultPepperPrefix); ok && version != "" {
+		return func(c *Config, v string) {
+			if c.Auth.PasswordHash.Peppers == nil {
+				c.Auth.PasswordHash.Peppers = make(map[string]string)
+			}
+			c.Auth.PasswordHash.Peppers[version] = v
+		}, true
+	}
+	setter, ok := vaultSecretSetters[key]
+	return setter, ok
+}
+
+// vaultProvider reads secrets from a KV engine and keeps its login token alive
+type vaultProvider struct {
+	client *vaultapi.Client
+	cfg    VaultConfig
+}
+
+// newVaultProvider logs in to Vault and starts renewing the login token
+func newVaultProvider(cfg VaultConfig) (*vaultProvider, error) {
+	for _, secret := range cfg.Secrets {
+		if _, ok := vaultSecretSetter(secret.Key); !ok {
+			return nil, fmt.Errorf("vault.secrets: %q cannot be loaded from Vault", secret.Key)
+		}
+		if secret.Path == "" || secret.Field == "" {
+			return nil, fmt.Errorf("vault.secrets: %q needs a path and a field", secret.Key)
+		}
+	}
+	if cfg.KVVersion != 1 && cfg.KVVersion != 2 {
+		return nil, fmt.Errorf("vault.kv_version must be 1 or 2, got %d", cfg.KVVersion)
+	}
+
+	// DefaultConfig picks up VAULT_CACERT and the other standard TLS variables
+	clientCfg := vaultapi.DefaultConfig()
+	if clientCfg.Error != nil {
+		return nil, fmt.Errorf("vault client config: %w", clientCfg.Error)
+	}
+	clientCfg.Address = cfg.Address
+	clientCfg.Timeout = vaultRequestTimeout
+
+	client, err := vaultapi.NewClient(clientCfg)
+	if err != nil {
+		return nil, fmt.Errorf("vault client: %w", err)
+	}
+
+	p := &vaultProvider{client: client, cfg: cfg}
+	auth, err := p.login()
+	if err != nil {
+		return nil, err
+	}
+	if auth != nil {
+		go p.keepAlive(auth)
+	}
+	return p, nil
+}
+
+// login authenticates with the configured method. It returns the login secret when
+// the token is renewable, or nil when there is nothing to renew.
+func (p *vaultProvider) login() (*vaultapi.Secret, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), vaultRequestTimeout)
+	defer cancel()
+
+	switch p.cfg.AuthMethod {
+	case vaultAuthToken:
+		if p.cfg.Token == "" {
+			return nil, errors.New("vault token auth needs vault.token or VAULT_TOKEN")
+		}
+		p.client.SetToken(p.cfg.Token)
+
+		// Renewing reports the token's lease; root and periodic-less tokens fail here
+		secret, err := p.client.Auth().Token().RenewSelfWithContext(ctx, 0)
+		if err != nil || secret == nil || secret.Auth == nil || !secret.Auth.Renewable {
+			return nil, nil
+		}
+		return secret, nil
+
+	case vaultAuthAppRole:
+		if p.cfg.RoleID == "" || p.cfg.SecretID == "" {
+			return nil, errors.New("vault approle auth needs vault.role_id and vault.secret_id (or VAULT_ROLE_ID and VAULT_SECRET_ID)")
+		}
+		secret, err := p.client.Logical().WriteWithContext(ctx, "auth/"+p.cfg.AppRoleMount+"/login", map[string]interface{}{
+			"role_id":   p.cfg.RoleID,
+			"secret_id": p.cfg.SecretID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("vault approle login: %w", err)
+		}
+		if secret == nil || secret.Auth == nil {
+			return nil, errors.New("vault approle login returned no token")
+		}
+		p.client.SetToken(secret.Auth.ClientToken)
+		if !secret.Auth.Renewable {
+			return nil, nil
+		}
+		return secret, nil
+
+	default:
+		return nil, fmt.Errorf("vault.auth_method %q is not supported (token or approle)", p.cfg.AuthMethod)
+	}
+}
+
+// keepAlive renews the login token until it reaches its max TTL. AppRole then logs
+// in again; a static token cannot be replaced and has to be rotated by the operator.
+func (p *vaultProvider) keepAlive(auth *vaultapi.Secret) {
+	for {
+		watcher, err := p.client.NewLifetimeWatcher(&vaultapi.LifetimeWatcherInput{Secret: auth})
+		if err != nil {
+			log.Printf("Warning: Vault token renewal disabled: %v", err)
+			return
+		}
+		go watcher.Start()
+
+	renew:
+		for {
+			select {
+			case err := <-watcher.DoneCh():
+				if err != nil {
+					log.Printf("Warning: Vault token renewal failed: %v", err)
+				}
+				break renew
+			case <-watcher.RenewCh():
+			}
+		}
+		watcher.Stop()
+
+		if p.cfg.AuthMethod != vaultAuthAppRole {
+			log.Printf("Warning: Vault token can no longer be renewed, secrets will fail to load once it expires")
+			return
+		}
+		for {
+			auth, err = p.login()
+			if err == nil {
+				break
+			}
+			log.Printf("Warning: Vault login failed, retrying in %s: %v", vaultRetryInterval, err)
+			time.Sleep(vaultRetryInterval)
+		}
+		if auth == nil {
+			return
+		}
+	}
+}
+
+// apply reads the configured secrets and writes them over cfg
+func (p *vaultProvider) apply(cfg *Config) error {
+	ctx, cancel := context.WithTimeout(context.Background(), vaultRequestTimeout)
+	defer cancel()
+
+	// Read each secret path once, however many fields come from it
+	byPath := make(map[string][]VaultSecretConfig)
+	for _, secret := range p.cfg.Secrets {
+		byPath[secret.Path] = append(byPath[secret.Path], secret)
+	}
+	paths := make([]string, 0, len(byPath))
+	for path := range byPath {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		data, err := p.read(ctx, path)
+		if err != nil {
+			return fmt.Errorf("vault secret %q: %w", path, err)
+		}
+		for _, secret := range byPath[path] {
+			value, ok := data[secret.Field].(string)
+			if !ok {
+				return fmt.Errorf("vault secret %q has no string field %q", path, secret.Field)
+			}
+			setter, _ := vaultSecretSetter(secret.Key)
+			setter(cfg, value)
+		}
+	}
+	return nil
+}
+
+// read returns the data of a KV secret
+func (p *vaultProvider) read(ctx context.Context, path string) (map[string]interface{}, error) {
+	var secret *vaultapi.KVSecret
+	var err error
+	if p.cfg.KVVersion == 1 {
+		secret, err = p.client.KVv1(p.cfg.KVMount).Get(ctx, path)
+	} else {
+		secret, err = p.client.KVv2(p.cfg.KVMount).Get(ctx, path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return secret.Data, nil
+}
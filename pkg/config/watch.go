@@ -0,0 +1,86 @@
+package config
+
+import (
+	"log"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+// ChangeFunc is notified with the previous and the reloaded configuration
+type ChangeFunc func(old, new *Config)
+
+var (
+	reloadMu  sync.Mutex
+	watchMu   sync.Mutex
+	current   *Config
+	listeners []ChangeFunc
+)
+
+// OnChange subscribes fn to configuration reloads. Subscribers pick the settings
+// they can apply at runtime (log level, rate limits, ...); structural settings such
+// as the database or the listen port still need a restart.
+func OnChange(fn ChangeFunc) {
+	watchMu.Lock()
+	defer watchMu.Unlock()
+	listeners = append(listeners, fn)
+}
+
+// Watch starts watching the config file loaded by Load, and its environment
+// overlay. After each successful reload every subscriber is called, in
+// subscription order. cfg is the configuration currently in use and becomes
+// the first "old" value.
+func Watch(cfg *Config) {
+	if viper.ConfigFileUsed() == "" {
+		log.Printf("Warning: No config file loaded, config watching disabled")
+		return
+	}
+
+	watchMu.Lock()
+	current = cfg
+	watchMu.Unlock()
+
+	viper.OnConfigChange(func(fsnotify.Event) { reload() })
+	viper.WatchConfig()
+
+	if profileFile != "" {
+		overlay := viper.New()
+		overlay.SetConfigFile(profileFile)
+		overlay.OnConfigChange(func(fsnotify.Event) { reload() })
+		overlay.WatchConfig()
+	}
+}
+
+// reload re-reads the base file and overlay, then notifies subscribers
+func reload() {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	// Re-reading the base file drops the previously merged overlay values
+	err := viper.ReadInConfig()
+	if err == nil {
+		err = mergeProfile()
+	}
+	var next *Config
+	if err == nil {
+		next, err = build()
+	}
+	if err == nil {
+		err = next.Validate()
+	}
+	if err != nil {
+		log.Printf("Warning: Failed to reload config, keeping the previous one: %v", err)
+		return
+	}
+
+	watchMu.Lock()
+	old := current
+	current = next
+	subscribers := append([]ChangeFunc(nil), listeners...)
+	watchMu.Unlock()
+
+	for _, fn := range subscribers {
+		fn(old, next)
+	}
+}
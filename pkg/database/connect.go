@@ -0,0 +1,59 @@
+package database
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/firdanbash/go-clean-boiler/pkg/config"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// open connects to the database, retrying with exponential backoff until
+// cfg.ConnectTimeout has passed, since the server often comes up after the
+// application in docker-compose and Kubernetes. SQLite opens a local file and
+// is not retried.
+func open(dialector gorm.Dialector, gormConfig *gorm.Config, cfg config.DatabaseConfig, log logger.Logger) (*gorm.DB, error) {
+	// GORM logs every failed attempt; the retries are logged here instead
+	quiet := *gormConfig
+	quiet.Logger = gormConfig.Logger.LogMode(gormlogger.Silent)
+
+	deadline := time.Now().Add(cfg.ConnectTimeout)
+	for attempt := 0; ; attempt++ {
+		db, err := gorm.Open(dialector, &quiet)
+		if err == nil {
+			db.Logger = gormConfig.Logger
+			return db, nil
+		}
+		// The pool is left open when the connection is made but the ping fails
+		if db != nil {
+			_ = Close(db)
+		}
+
+		// The last attempt is made at the deadline
+		remaining := time.Until(deadline)
+		if cfg.Driver == DriverSQLite || remaining <= 0 {
+			return nil, err
+		}
+		wait := min(connectBackoff(cfg, attempt), remaining)
+		log.Warn("Database not reachable, retrying",
+			zap.Int("attempt", attempt+1),
+			zap.Duration("retry_in", wait),
+			zap.Error(err),
+		)
+		time.Sleep(wait)
+	}
+}
+
+// connectBackoff doubles the wait on each attempt up to the maximum and
+// randomizes its upper half, so replicas starting together do not retry together
+func connectBackoff(cfg config.DatabaseConfig, attempt int) time.Duration {
+	wait := cfg.ConnectRetryWaitMin << attempt
+	if wait <= 0 || wait > cfg.ConnectRetryWaitMax {
+		wait = cfg.ConnectRetryWaitMax
+	}
+	half := wait / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
@@ -1,47 +1,55 @@
 package database
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/firdanbash/go-clean-boiler/pkg/config"
 	"github.com/firdanbash/go-clean-boiler/pkg/logger"
 	"go.uber.org/zap"
-	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	gormlogger "gorm.io/gorm/logger"
+	"gorm.io/plugin/opentelemetry/tracing"
+	gormprometheus "gorm.io/plugin/prometheus"
 )
 
-var DB *gorm.DB
-
-// Init initializes the database connection
-func Init(cfg *config.Config) error {
-	dsn := cfg.GetDSN()
-
-	// Configure GORM logger
-	var gormLogLevel gormlogger.LogLevel
-	switch cfg.Log.Level {
-	case "debug":
-		gormLogLevel = gormlogger.Info
-	case "info":
-		gormLogLevel = gormlogger.Warn
-	default:
-		gormLogLevel = gormlogger.Error
+// New opens a connection pool to the configured database
+func New(cfg *config.Config, log logger.Logger) (*gorm.DB, error) {
+	dialector, err := Dialector(cfg.Database)
+	if err != nil {
+		return nil, err
 	}
 
 	gormConfig := &gorm.Config{
-		Logger: gormlogger.Default.LogMode(gormLogLevel),
+		Logger: newGormLogger(log, cfg.Database.SlowQueryThreshold),
 	}
 
-	// Connect to database
-	db, err := gorm.Open(postgres.Open(dsn), gormConfig)
+	// Connect to database, waiting for it to come up
+	db, err := open(dialector, gormConfig, cfg.Database, log)
 	if err != nil {
-		return fmt.Errorf("failed to connect to database: %w", err)
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	// Trace every query as a child of the request span
+	if cfg.Tracing.Enabled {
+		if err := db.Use(tracing.NewPlugin(tracing.WithoutMetrics())); err != nil {
+			return nil, fmt.Errorf("failed to enable database tracing: %w", err)
+		}
+	}
+
+	// Export the connection pool statistics, and the server status when
+	// enabled, to Prometheus
+	if cfg.Metrics.Enabled {
+		if err := db.Use(gormprometheus.New(prometheusConfig(cfg.Database))); err != nil {
+			return nil, fmt.Errorf("failed to enable database metrics: %w", err)
+		}
 	}
 
 	// Get generic database object sql.DB to configure connection pool
 	sqlDB, err := db.DB()
 	if err != nil {
-		return fmt.Errorf("failed to get database instance: %w", err)
+		return nil, fmt.Errorf("failed to get database instance: %w", err)
 	}
 
 	// Set connection pool settings
@@ -49,33 +57,81 @@ func Init(cfg *config.Config) error {
 	sqlDB.SetMaxIdleConns(cfg.Database.MaxIdleConns)
 	sqlDB.SetConnMaxLifetime(cfg.Database.ConnMaxLifetime)
 
+	// SQLite allows a single writer, and an in-memory database only lives
+	// as long as its connection, so keep exactly one long-lived connection
+	if cfg.Database.Driver == DriverSQLite {
+		sqlDB.SetMaxOpenConns(1)
+		sqlDB.SetMaxIdleConns(1)
+		sqlDB.SetConnMaxLifetime(0)
+	}
+
 	// Test connection
 	if err := sqlDB.Ping(); err != nil {
-		return fmt.Errorf("failed to ping database: %w", err)
+		_ = sqlDB.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	DB = db
-	logger.Info("Database connected successfully",
+	log.Info("Database connected successfully",
+		zap.String("driver", cfg.Database.Driver),
 		zap.String("host", cfg.Database.Host),
 		zap.String("database", cfg.Database.Name),
 	)
 
-	return nil
+	return db, nil
 }
 
-// Close closes the database connection
-func Close() error {
-	if DB != nil {
-		sqlDB, err := DB.DB()
-		if err != nil {
-			return err
+// mysqlStatusVariables are the MySQL status variables exported as metrics
+var mysqlStatusVariables = []string{
+	"Threads_connected",
+	"Threads_running",
+	"Connections",
+	"Aborted_connects",
+	"Questions",
+	"Slow_queries",
+	"Innodb_row_lock_waits",
+}
+
+// prometheusConfig configures the metrics of the database, labelled with its
+// name and refreshed every stats interval
+func prometheusConfig(cfg config.DatabaseConfig) gormprometheus.Config {
+	c := gormprometheus.Config{
+		DBName:          cfg.Name,
+		RefreshInterval: uint32(max(cfg.StatsInterval/time.Second, 1)),
+	}
+	if cfg.ServerMetrics {
+		switch cfg.Driver {
+		case DriverPostgres:
+			c.MetricsCollector = []gormprometheus.MetricsCollector{&gormprometheus.Postgres{}}
+		case DriverMySQL:
+			c.MetricsCollector = []gormprometheus.MetricsCollector{&gormprometheus.MySQL{VariableNames: mysqlStatusVariables}}
 		}
-		return sqlDB.Close()
 	}
-	return nil
+	return c
+}
+
+// Close closes the connection pool of db
+func Close(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
 }
 
 // AutoMigrate runs auto migration for given models
-func AutoMigrate(models ...interface{}) error {
-	return DB.AutoMigrate(models...)
+func AutoMigrate(db *gorm.DB, models ...interface{}) error {
+	return db.AutoMigrate(models...)
+}
+
+// Ping verifies the database connection is alive
+func Ping(ctx context.Context, db *gorm.DB) error {
+	if db == nil {
+		return errors.New("database not initialized")
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
 }
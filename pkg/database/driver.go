@@ -0,0 +1,103 @@
+package database
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+
+	"github.com/firdanbash/go-clean-boiler/pkg/config"
+	"github.com/glebarez/sqlite"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// Supported database drivers
+const (
+	DriverPostgres = "postgres"
+	DriverMySQL    = "mysql"
+	DriverSQLite   = "sqlite"
+)
+
+// sqliteMemory is the database name that selects an in-memory SQLite database
+const sqliteMemory = ":memory:"
+
+// Dialector returns the GORM dialector for the configured driver
+func Dialector(cfg config.DatabaseConfig) (gorm.Dialector, error) {
+	dsn, err := DSN(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	switch cfg.Driver {
+	case DriverPostgres, "":
+		return postgres.Open(dsn), nil
+	case DriverMySQL:
+		return mysql.Open(dsn), nil
+	case DriverSQLite:
+		return sqlite.Open(dsn), nil
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q", cfg.Driver)
+	}
+}
+
+// DSN builds the connection string for the configured driver
+func DSN(cfg config.DatabaseConfig) (string, error) {
+	switch cfg.Driver {
+	case DriverPostgres, "":
+		return fmt.Sprintf(
+			"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+			cfg.Host,
+			cfg.Port,
+			cfg.User,
+			cfg.Password,
+			cfg.Name,
+			cfg.SSLMode,
+		), nil
+	case DriverMySQL:
+		params := url.Values{}
+		params.Set("charset", "utf8mb4")
+		params.Set("parseTime", "true")
+		params.Set("loc", "UTC")
+		if tls := mysqlTLSMode(cfg.SSLMode); tls != "" {
+			params.Set("tls", tls)
+		}
+
+		return fmt.Sprintf(
+			"%s:%s@tcp(%s)/%s?%s",
+			cfg.User,
+			cfg.Password,
+			net.JoinHostPort(cfg.Host, cfg.Port),
+			cfg.Name,
+			params.Encode(),
+		), nil
+	case DriverSQLite:
+		// The database name is the file path, or ":memory:" for a throwaway database
+		params := url.Values{}
+		params.Add("_pragma", "foreign_keys(1)")
+		params.Add("_pragma", "busy_timeout(5000)")
+
+		if cfg.Name == sqliteMemory {
+			params.Set("mode", "memory")
+			params.Set("cache", "shared")
+			return "file::memory:?" + params.Encode(), nil
+		}
+		return "file:" + cfg.Name + "?" + params.Encode(), nil
+	default:
+		return "", fmt.Errorf("unsupported database driver %q", cfg.Driver)
+	}
+}
+
+// mysqlTLSMode maps the Postgres-style sslmode setting onto the MySQL driver's tls parameter
+func mysqlTLSMode(sslMode string) string {
+	switch sslMode {
+	case "", "disable":
+		return ""
+	case "allow", "prefer":
+		return "preferred"
+	case "require":
+		return "skip-verify"
+	default:
+		return "true"
+	}
+}
@@ -0,0 +1,105 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+	"gorm.io/gorm/utils"
+)
+
+// gormLogger logs the queries of GORM with the application logger: failed
+// queries as errors, queries slower than slowThreshold as warnings and every
+// query while the default logger is at debug level, so changing the level at
+// runtime also changes which queries are logged
+type gormLogger struct {
+	log           logger.Logger
+	slowThreshold time.Duration
+	// mode is set by LogMode, as db.Debug() does; 0 follows the default logger
+	mode gormlogger.LogLevel
+}
+
+// newGormLogger returns a GORM logger writing to log. A zero slowThreshold
+// disables slow query logging.
+func newGormLogger(log logger.Logger, slowThreshold time.Duration) gormlogger.Interface {
+	return &gormLogger{log: log, slowThreshold: slowThreshold}
+}
+
+// LogMode returns a copy of the logger fixed at level
+func (l *gormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	fixed := *l
+	fixed.mode = level
+	return &fixed
+}
+
+func (l *gormLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	if l.enabled(gormlogger.Info) {
+		logger.Ctx(ctx, l.log).Info(fmt.Sprintf(msg, data...))
+	}
+}
+
+func (l *gormLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	if l.enabled(gormlogger.Warn) {
+		logger.Ctx(ctx, l.log).Warn(fmt.Sprintf(msg, data...))
+	}
+}
+
+func (l *gormLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	if l.enabled(gormlogger.Error) {
+		logger.Ctx(ctx, l.log).Error(fmt.Sprintf(msg, data...))
+	}
+}
+
+// Trace logs a query once it has run. Missing records are not failures.
+func (l *gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	elapsed := time.Since(begin)
+
+	var level gormlogger.LogLevel
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound):
+		level = gormlogger.Error
+	case l.slowThreshold > 0 && elapsed > l.slowThreshold:
+		level = gormlogger.Warn
+	case l.mode == gormlogger.Info || (l.mode == 0 && logger.Level() == "debug"):
+		level = gormlogger.Info
+	default:
+		return
+	}
+	if !l.enabled(level) {
+		return
+	}
+
+	sql, rows := fc()
+	fields := []logger.Field{
+		zap.String("sql", sql),
+		zap.Int64("rows", rows),
+		zap.Duration("duration", elapsed),
+		// The repository method running the query, past the frames of GORM
+		zap.String("caller", utils.FileWithLineNum()),
+	}
+
+	log := logger.Ctx(ctx, l.log)
+	switch level {
+	case gormlogger.Error:
+		log.Error("Query failed", append(fields, zap.Error(err))...)
+	case gormlogger.Warn:
+		log.Warn("Slow query", append(fields, zap.Duration("threshold", l.slowThreshold))...)
+	default:
+		if l.mode == gormlogger.Info {
+			log.Info("Query", fields...)
+			return
+		}
+		log.Debug("Query", fields...)
+	}
+}
+
+// enabled reports whether messages of level are logged in the mode set by
+// LogMode. Without a mode, the default logger filters them by its own level.
+func (l *gormLogger) enabled(level gormlogger.LogLevel) bool {
+	return l.mode == 0 || level <= l.mode
+}
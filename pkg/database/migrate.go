@@ -0,0 +1,164 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/firdanbash/go-clean-boiler/pkg/config"
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database"
+	migratemysql "github.com/golang-migrate/migrate/v4/database/mysql"
+	migratepgx "github.com/golang-migrate/migrate/v4/database/pgx/v5"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+// ErrNoMigrations is returned for drivers without versioned SQL migrations.
+// The SQLite schema is created by GORM AutoMigrate when the server starts.
+var ErrNoMigrations = errors.New("no SQL migrations for this driver, the schema is created by auto migration")
+
+// MigrationStatus describes one migration file and whether it has been applied
+type MigrationStatus struct {
+	Version uint
+	Name    string
+	Applied bool
+}
+
+// Migrator applies the versioned SQL migrations of the configured driver
+type Migrator struct {
+	m          *migrate.Migrate
+	migrations fs.FS
+}
+
+// NewMigrator opens a dedicated connection for applying the migrations found in
+// migrations/<driver> of the given file system
+func NewMigrator(cfg config.DatabaseConfig, migrations fs.FS) (*Migrator, error) {
+	driverName := cfg.Driver
+	if driverName == "" {
+		driverName = DriverPostgres
+	}
+	if driverName == DriverSQLite {
+		return nil, ErrNoMigrations
+	}
+
+	dir, err := fs.Sub(migrations, driverName)
+	if err != nil {
+		return nil, err
+	}
+	source, err := iofs.New(dir, ".")
+	if err != nil {
+		return nil, fmt.Errorf("read migrations: %w", err)
+	}
+
+	dsn, err := DSN(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var target database.Driver
+	switch driverName {
+	case DriverPostgres:
+		db, err := sql.Open("pgx/v5", dsn)
+		if err != nil {
+			return nil, err
+		}
+		target, err = migratepgx.WithInstance(db, &migratepgx.Config{})
+		if err != nil {
+			db.Close()
+			return nil, fmt.Errorf("connect for migrations: %w", err)
+		}
+	case DriverMySQL:
+		// Some migration files hold several statements
+		db, err := sql.Open("mysql", dsn+"&multiStatements=true")
+		if err != nil {
+			return nil, err
+		}
+		target, err = migratemysql.WithInstance(db, &migratemysql.Config{})
+		if err != nil {
+			db.Close()
+			return nil, fmt.Errorf("connect for migrations: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q", cfg.Driver)
+	}
+
+	m, err := migrate.NewWithInstance("iofs", source, driverName, target)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{m: m, migrations: dir}, nil
+}
+
+// Up applies all pending migrations
+func (m *Migrator) Up() error {
+	if err := m.m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return err
+	}
+	return nil
+}
+
+// Down rolls back the given number of migrations, or all of them when steps is 0
+func (m *Migrator) Down(steps int) error {
+	var err error
+	if steps == 0 {
+		err = m.m.Down()
+	} else {
+		err = m.m.Steps(-steps)
+	}
+	if err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return err
+	}
+	return nil
+}
+
+// Version returns the current schema version, 0 when nothing has been applied
+func (m *Migrator) Version() (version uint, dirty bool, err error) {
+	version, dirty, err = m.m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, false, nil
+	}
+	return version, dirty, err
+}
+
+// Status lists every migration with whether it is applied at the current version
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	current, _, err := m.Version()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := fs.ReadDir(m.migrations, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	var status []MigrationStatus
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".up.sql")
+		if !ok {
+			continue
+		}
+		prefix, rest, _ := strings.Cut(name, "_")
+		version, err := strconv.ParseUint(prefix, 10, 64)
+		if err != nil {
+			continue
+		}
+		status = append(status, MigrationStatus{
+			Version: uint(version),
+			Name:    rest,
+			Applied: uint(version) <= current,
+		})
+	}
+	sort.Slice(status, func(i, j int) bool { return status[i].Version < status[j].Version })
+	return status, nil
+}
+
+// Close releases the migration connection
+func (m *Migrator) Close() error {
+	sourceErr, dbErr := m.m.Close()
+	return errors.Join(sourceErr, dbErr)
+}
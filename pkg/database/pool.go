@@ -0,0 +1,34 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"gorm.io/gorm"
+)
+
+// CheckPool reports an error when the connection pool of db is saturated:
+// at least threshold of its maximum open connections are in use and queries
+// have been waiting for a connection since the previous check. Pools without
+// a maximum are never saturated.
+func CheckPool(db *gorm.DB, threshold float64) func(ctx context.Context) error {
+	var lastWaits atomic.Int64
+	return func(context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		stats := sqlDB.Stats()
+		waits := stats.WaitCount - lastWaits.Swap(stats.WaitCount)
+
+		if stats.MaxOpenConnections <= 0 || waits == 0 {
+			return nil
+		}
+		if float64(stats.InUse) >= threshold*float64(stats.MaxOpenConnections) {
+			return fmt.Errorf("connection pool saturated: %d of %d connections in use, %d queries waited",
+				stats.InUse, stats.MaxOpenConnections, waits)
+		}
+		return nil
+	}
+}
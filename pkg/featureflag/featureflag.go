@@ -0,0 +1,204 @@
+// Package featureflag turns features on and off without a deploy. Flags are
+// declared in the configuration with their default state; with a store, their
+// state can be changed at runtime and is shared by every instance.
+package featureflag
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/firdanbash/go-clean-boiler/pkg/config"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrUnknownFlag is returned for a flag that is not declared
+	ErrUnknownFlag = errors.New("unknown feature flag")
+	// ErrReadOnly is returned when changing a flag without a store
+	ErrReadOnly = errors.New("feature flags cannot be changed without a store")
+)
+
+// Store keeps the state of flags changed at runtime
+type Store interface {
+	Load(ctx context.Context) (map[string]bool, error)
+	Save(ctx context.Context, name string, enabled bool) error
+}
+
+// Flag is a declared flag and its current state
+type Flag struct {
+	Name       string
+	Enabled    bool
+	Default    bool
+	Overridden bool // changed at runtime
+}
+
+// Flags evaluates the declared flags. Evaluation reads an in-memory copy of
+// the stored state, refreshed in the background, so it is cheap enough for
+// every request.
+type Flags struct {
+	defaults map[string]bool
+	store    Store
+	interval time.Duration
+	log      logger.Logger
+
+	mu        sync.RWMutex
+	overrides map[string]bool
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// New creates the flags declared in cfg. store may be nil, which keeps the
+// flags at their defaults.
+func New(cfg config.FeatureFlagConfig, store Store, log logger.Logger) *Flags {
+	defaults := make(map[string]bool, len(cfg.Flags))
+	for name, enabled := range cfg.Flags {
+		defaults[name] = enabled
+	}
+	return &Flags{
+		defaults:  defaults,
+		store:     store,
+		interval:  cfg.RefreshInterval,
+		log:       log,
+		overrides: make(map[string]bool),
+	}
+}
+
+// Enabled reports whether a flag is on. Undeclared flags are off.
+func (f *Flags) Enabled(name string) bool {
+	enabled, declared := f.defaults[name]
+	if !declared {
+		return false
+	}
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if override, ok := f.overrides[name]; ok {
+		return override
+	}
+	return enabled
+}
+
+// List returns every declared flag, sorted by name
+func (f *Flags) List() []Flag {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	flags := make([]Flag, 0, len(f.defaults))
+	for name, enabled := range f.defaults {
+		flags = append(flags, f.flag(name, enabled))
+	}
+	sort.Slice(flags, func(i, j int) bool { return flags[i].Name < flags[j].Name })
+	return flags
+}
+
+// Get returns a declared flag
+func (f *Flags) Get(name string) (Flag, error) {
+	enabled, declared := f.defaults[name]
+	if !declared {
+		return Flag{}, ErrUnknownFlag
+	}
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.flag(name, enabled), nil
+}
+
+// Set changes the state of a declared flag in the store. Other instances see
+// the change on their next refresh.
+func (f *Flags) Set(ctx context.Context, name string, enabled bool) (Flag, error) {
+	if _, declared := f.defaults[name]; !declared {
+		return Flag{}, ErrUnknownFlag
+	}
+	if f.store == nil {
+		return Flag{}, ErrReadOnly
+	}
+
+	if err := f.store.Save(ctx, name, enabled); err != nil {
+		return Flag{}, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.overrides[name] = enabled
+	return f.flag(name, f.defaults[name]), nil
+}
+
+// Refresh loads the state of the flags from the store
+func (f *Flags) Refresh(ctx context.Context) error {
+	if f.store == nil {
+		return nil
+	}
+
+	overrides, err := f.store.Load(ctx)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	f.overrides = overrides
+	f.mu.Unlock()
+	return nil
+}
+
+// Start loads the stored state and refreshes it in the background until Stop
+// is called. Without a store it does nothing.
+func (f *Flags) Start(ctx context.Context) {
+	if f.store == nil {
+		return
+	}
+	// The defaults apply until the store can be read
+	if err := f.Refresh(ctx); err != nil {
+		f.log.Error("Failed to load feature flags", zap.Error(err))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	f.cancel = cancel
+	f.done = make(chan struct{})
+
+	go func() {
+		defer close(f.done)
+		ticker := time.NewTicker(f.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := f.Refresh(ctx); err != nil && ctx.Err() == nil {
+					f.log.Warn("Failed to refresh feature flags", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// Stop stops refreshing and waits for a refresh in flight or for ctx to be done
+func (f *Flags) Stop(ctx context.Context) error {
+	if f.cancel == nil {
+		return nil
+	}
+	f.cancel()
+
+	select {
+	case <-f.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// flag returns the state of a declared flag; f.mu must be held
+func (f *Flags) flag(name string, enabled bool) Flag {
+	flag := Flag{Name: name, Enabled: enabled, Default: enabled}
+	if override, ok := f.overrides[name]; ok {
+		flag.Enabled = override
+		flag.Overridden = true
+	}
+	return flag
+}
@@ -0,0 +1,42 @@
+package featureflag
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisStore keeps the state of flags in a Redis hash
+type RedisStore struct {
+	client *redis.Client
+	key    string
+}
+
+// NewRedisStore creates a store keeping flags in the hash at key
+func NewRedisStore(client *redis.Client, key string) *RedisStore {
+	return &RedisStore{client: client, key: key}
+}
+
+// Load returns the stored state of every flag
+func (s *RedisStore) Load(ctx context.Context) (map[string]bool, error) {
+	values, err := s.client.HGetAll(ctx, s.key).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	flags := make(map[string]bool, len(values))
+	for name, value := range values {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			continue
+		}
+		flags[name] = enabled
+	}
+	return flags, nil
+}
+
+// Save stores the state of a flag
+func (s *RedisStore) Save(ctx context.Context, name string, enabled bool) error {
+	return s.client.HSet(ctx, s.key, name, strconv.FormatBool(enabled)).Err()
+}
@@ -0,0 +1,103 @@
+// Package fieldset parses sparse fieldsets, given as a query parameter such as
+// fields=id,name,email, and trims responses to them, so that clients such as
+// mobile apps only receive the fields they use. Only the fields an entity
+// whitelists can be selected, mapped to their columns like sort fields are.
+package fieldset
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/firdanbash/go-clean-boiler/pkg/apperror"
+)
+
+// ErrUnknownField is returned when a field is not in the whitelist
+var ErrUnknownField = apperror.Validation("unknown field").WithCode(apperror.CodeFieldUnknown)
+
+// Set is a parsed fieldset. The zero Set selects every field.
+type Set struct {
+	fields  []string // JSON names, in the requested order
+	columns []string // database columns of the fields
+}
+
+// Parse parses a comma separated list of fields, each of which must be in
+// allowed, which maps the JSON name of a field to its column. Fields without
+// a column, e.g. computed ones, map to "". Duplicates are ignored.
+func Parse(fields string, allowed map[string]string) (Set, error) {
+	var set Set
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(fields, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		column, ok := allowed[name]
+		if !ok {
+			return Set{}, ErrUnknownField
+		}
+		seen[name] = true
+		set.fields = append(set.fields, name)
+		if column != "" {
+			set.columns = append(set.columns, column)
+		}
+	}
+	return set, nil
+}
+
+// All reports whether the set selects every field
+func (s Set) All() bool {
+	return len(s.fields) == 0
+}
+
+// Columns returns the columns to select, nil for every column
+func (s Set) Columns() []string {
+	return s.columns
+}
+
+// Apply trims v, a struct or a slice of structs, to the fields of the set, as
+// they are named when v is marshaled to JSON. v is returned as is when the
+// set selects every field.
+func (s Set) Apply(v interface{}) (interface{}, error) {
+	if s.All() {
+		return v, nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	// Keep large integers such as IDs exact
+	dec.UseNumber()
+	var decoded interface{}
+	if err := dec.Decode(&decoded); err != nil {
+		return nil, err
+	}
+
+	switch value := decoded.(type) {
+	case map[string]interface{}:
+		return s.trim(value), nil
+	case []interface{}:
+		for i, item := range value {
+			if obj, ok := item.(map[string]interface{}); ok {
+				value[i] = s.trim(obj)
+			}
+		}
+		return value, nil
+	default:
+		return decoded, nil
+	}
+}
+
+// trim keeps the fields of the set in obj. Fields left out of obj, such as
+// empty omitempty ones, stay out.
+func (s Set) trim(obj map[string]interface{}) map[string]interface{} {
+	trimmed := make(map[string]interface{}, len(s.fields))
+	for _, field := range s.fields {
+		if value, ok := obj[field]; ok {
+			trimmed[field] = value
+		}
+	}
+	return trimmed
+}
@@ -0,0 +1,103 @@
+package httpclient
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/firdanbash/go-clean-boiler/pkg/config"
+)
+
+// ErrCircuitOpen is returned without calling a host whose circuit is open
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// breakers holds a circuit breaker per host
+type breakers struct {
+	cfg   config.CircuitBreakerConfig
+	mu    sync.Mutex
+	hosts map[string]*breaker
+}
+
+func newBreakers(cfg config.CircuitBreakerConfig) *breakers {
+	return &breakers{cfg: cfg, hosts: make(map[string]*breaker)}
+}
+
+// get returns the breaker of host; nil, which allows every call, when
+// breakers are disabled
+func (b *breakers) get(host string) *breaker {
+	if !b.cfg.Enabled {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	br, ok := b.hosts[host]
+	if !ok {
+		br = &breaker{threshold: b.cfg.FailureThreshold, openTimeout: b.cfg.OpenTimeout}
+		b.hosts[host] = br
+	}
+	return br
+}
+
+// breaker is closed while calls succeed, opens after threshold consecutive
+// failures and, once openTimeout has passed, lets a single trial call through:
+// its success closes the circuit again, its failure keeps it open
+type breaker struct {
+	threshold   int
+	openTimeout time.Duration
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time // zero while closed
+	trial    bool      // a trial call is in flight
+}
+
+// allow reports whether a call may be made now
+func (b *breaker) allow() bool {
+	if b == nil {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.openedAt.IsZero() {
+		return true
+	}
+	if b.trial || time.Since(b.openedAt) < b.openTimeout {
+		return false
+	}
+	b.trial = true
+	return true
+}
+
+// record records the outcome of an allowed call
+func (b *breaker) record(success bool) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trial = false
+	if success {
+		b.failures = 0
+		b.openedAt = time.Time{}
+		return
+	}
+
+	b.failures++
+	if !b.openedAt.IsZero() || b.failures >= b.threshold {
+		b.openedAt = time.Now()
+	}
+}
+
+// cancel releases an allowed call whose outcome tells nothing about the host
+func (b *breaker) cancel() {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	b.trial = false
+	b.mu.Unlock()
+}
@@ -0,0 +1,207 @@
+// Package httpclient provides the HTTP client services use to call external
+// APIs. Calls are bounded by timeouts, idempotent requests are retried with
+// jittered backoff, a circuit breaker per host fails calls fast while the host
+// keeps failing, and the request ID and trace context of the caller are sent
+// along.
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/firdanbash/go-clean-boiler/pkg/config"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"github.com/firdanbash/go-clean-boiler/pkg/reqctx"
+	"github.com/firdanbash/go-clean-boiler/pkg/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.uber.org/zap"
+)
+
+// RequestIDHeader carries the request ID of the caller to the called API
+const RequestIDHeader = "X-Request-ID"
+
+// metrics counts the outbound calls, served at /debug/vars
+var metrics = expvar.NewMap("httpclient")
+
+// New creates an HTTP client configured by cfg
+func New(cfg config.HTTPClientConfig, log logger.Logger) *http.Client {
+	base := http.DefaultTransport.(*http.Transport).Clone()
+	base.DialContext = (&net.Dialer{Timeout: cfg.DialTimeout, KeepAlive: 30 * time.Second}).DialContext
+	base.ResponseHeaderTimeout = cfg.ResponseHeaderTimeout
+
+	return &http.Client{
+		Timeout:   cfg.Timeout,
+		Transport: NewTransport(base, cfg, log),
+	}
+}
+
+// NewTransport wraps base with the retries, circuit breakers and header
+// propagation of cfg, for clients of SDKs that accept an http.RoundTripper
+func NewTransport(base http.RoundTripper, cfg config.HTTPClientConfig, log logger.Logger) http.RoundTripper {
+	return &transport{base: base, cfg: cfg, breakers: newBreakers(cfg.Breaker), log: log}
+}
+
+type transport struct {
+	base     http.RoundTripper
+	cfg      config.HTTPClientConfig
+	breakers *breakers
+	log      logger.Logger
+}
+
+// RoundTrip sends req, retrying it while the attempt may be repeated safely
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, span := tracing.Start(req.Context(), "HTTP "+req.Method)
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("http.request.method", req.Method),
+		attribute.String("server.address", req.URL.Host),
+		attribute.String("url.full", req.URL.Redacted()),
+	)
+
+	// A round tripper must not modify the request of its caller
+	req = req.Clone(ctx)
+	propagate(ctx, req.Header)
+
+	breaker := t.breakers.get(req.URL.Host)
+	retryable := t.cfg.MaxRetries > 0 && replayable(req)
+
+	for attempt := 0; ; attempt++ {
+		if !breaker.allow() {
+			metrics.Add("rejected", 1)
+			span.SetStatus(codes.Error, ErrCircuitOpen.Error())
+			return nil, fmt.Errorf("%s: %w", req.URL.Host, ErrCircuitOpen)
+		}
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				breaker.cancel()
+				return nil, err
+			}
+			req.Body = body
+		}
+
+		metrics.Add("requests", 1)
+		resp, err := t.base.RoundTrip(req)
+		switch {
+		case err != nil && ctx.Err() != nil:
+			// Cancelled by the caller, which says nothing about the host
+			breaker.cancel()
+		default:
+			breaker.record(err == nil && resp.StatusCode < http.StatusInternalServerError)
+		}
+
+		wait, retry := t.retryAfter(attempt, resp, err)
+		if !retryable || !retry || ctx.Err() != nil {
+			if err != nil {
+				metrics.Add("failures", 1)
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				return nil, err
+			}
+			span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+			if resp.StatusCode >= http.StatusInternalServerError {
+				metrics.Add("failures", 1)
+				span.SetStatus(codes.Error, resp.Status)
+			}
+			return resp, nil
+		}
+
+		fields := []zap.Field{
+			zap.String("method", req.Method),
+			zap.String("host", req.URL.Host),
+			zap.Int("attempt", attempt+1),
+			zap.Duration("wait", wait),
+		}
+		if err != nil {
+			fields = append(fields, zap.Error(err))
+		} else {
+			fields = append(fields, zap.Int("status", resp.StatusCode))
+			// Drain the body so the connection can be reused
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4<<10))
+			resp.Body.Close()
+		}
+		logger.Ctx(ctx, t.log).Warn("Retrying HTTP request", fields...)
+		metrics.Add("retries", 1)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// retryAfter reports whether the outcome of an attempt is worth retrying and
+// how long to wait first: after network errors, 429 and 5xx other than 501,
+// with exponential backoff and jitter, or as told by Retry-After
+func (t *transport) retryAfter(attempt int, resp *http.Response, err error) (time.Duration, bool) {
+	if attempt >= t.cfg.MaxRetries {
+		return 0, false
+	}
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return 0, false
+		}
+		return t.backoff(attempt), true
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+	default:
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+		wait := time.Duration(seconds) * time.Second
+		// Waiting longer than configured is left to the caller
+		return wait, wait <= t.cfg.RetryWaitMax
+	}
+	return t.backoff(attempt), true
+}
+
+// backoff doubles the wait on each attempt up to the maximum and randomizes
+// its upper half, so clients failing together do not retry together
+func (t *transport) backoff(attempt int) time.Duration {
+	wait := t.cfg.RetryWaitMin << attempt
+	if wait <= 0 || wait > t.cfg.RetryWaitMax {
+		wait = t.cfg.RetryWaitMax
+	}
+	half := wait / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// replayable reports whether req can be sent again without side effects:
+// its method is idempotent or it carries an idempotency key, and its body can
+// be read again
+func replayable(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+	default:
+		if req.Header.Get("Idempotency-Key") == "" {
+			return false
+		}
+	}
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// propagate adds the request ID and trace context of ctx to header
+func propagate(ctx context.Context, header http.Header) {
+	if id := reqctx.RequestID(ctx); id != "" && header.Get(RequestIDHeader) == "" {
+		header.Set(RequestIDHeader, id)
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(header))
+}
@@ -0,0 +1,203 @@
+// Package i18n translates user-facing messages. Messages are identified by
+// their English text, so untranslated messages and the fallback locale need
+// no catalog at all. Translations are JSON objects mapping the English text
+// to the translated text, one <locale>.json file per locale.
+package i18n
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+//go:embed locales/*.json
+var builtin embed.FS
+
+// Bundle holds the translations of every available locale
+type Bundle struct {
+	fallback language.Tag
+	tags     []language.Tag
+	messages map[language.Tag]map[string]string
+	matcher  language.Matcher
+}
+
+// NewBundle creates an empty bundle whose fallback locale is used for
+// requests that match no other locale
+func NewBundle(fallback language.Tag) *Bundle {
+	b := &Bundle{
+		fallback: fallback,
+		messages: make(map[language.Tag]map[string]string),
+	}
+	b.add(fallback, nil)
+	return b
+}
+
+// Load creates a bundle with the built-in translations, overridden by the
+// <locale>.json files in dir when dir is not empty
+func Load(defaultLocale, dir string) (*Bundle, error) {
+	fallback, err := language.Parse(defaultLocale)
+	if err != nil {
+		return nil, fmt.Errorf("invalid default locale %q: %w", defaultLocale, err)
+	}
+
+	b := NewBundle(fallback)
+	if err := b.LoadFS(builtin, "locales"); err != nil {
+		return nil, err
+	}
+	if dir != "" {
+		if err := b.LoadFS(os.DirFS(dir), "."); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// LoadFS adds the translations of every <locale>.json file in dir of fsys
+func (b *Bundle) LoadFS(fsys fs.FS, dir string) error {
+	files, err := fs.Glob(fsys, path.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		name := strings.TrimSuffix(path.Base(file), ".json")
+		tag, err := language.Parse(name)
+		if err != nil {
+			return fmt.Errorf("translation file %s: invalid locale: %w", file, err)
+		}
+
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return err
+		}
+		var messages map[string]string
+		if err := json.Unmarshal(data, &messages); err != nil {
+			return fmt.Errorf("translation file %s: %w", file, err)
+		}
+		b.AddMessages(tag, messages)
+	}
+	return nil
+}
+
+// AddMessages adds translations for a locale, replacing existing ones with the
+// same English text
+func (b *Bundle) AddMessages(tag language.Tag, messages map[string]string) {
+	b.add(tag, messages)
+}
+
+func (b *Bundle) add(tag language.Tag, messages map[string]string) {
+	catalog, ok := b.messages[tag]
+	if !ok {
+		catalog = make(map[string]string, len(messages))
+		b.messages[tag] = catalog
+		b.tags = append(b.tags, tag)
+		b.matcher = language.NewMatcher(b.tags)
+	}
+	for msg, translated := range messages {
+		catalog[msg] = translated
+	}
+}
+
+// Locales returns the available locales, the fallback first
+func (b *Bundle) Locales() []language.Tag {
+	return b.tags
+}
+
+// Match returns the available locale that best fits an Accept-Language header,
+// or the fallback locale
+func (b *Bundle) Match(acceptLanguage string) language.Tag {
+	prefs, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(prefs) == 0 {
+		return b.fallback
+	}
+	_, index, confidence := b.matcher.Match(prefs...)
+	if confidence == language.No {
+		return b.fallback
+	}
+	return b.tags[index]
+}
+
+// Localizer returns a localizer for one of the available locales
+func (b *Bundle) Localizer(tag language.Tag) *Localizer {
+	messages, ok := b.messages[tag]
+	if !ok {
+		tag, messages = b.fallback, b.messages[b.fallback]
+	}
+	return &Localizer{tag: tag, messages: messages}
+}
+
+// Localizer translates messages to a single locale. A nil Localizer returns
+// messages untranslated.
+type Localizer struct {
+	tag      language.Tag
+	messages map[string]string
+}
+
+// Locale returns the locale of l, e.g. for a Content-Language header
+func (l *Localizer) Locale() string {
+	if l == nil {
+		return ""
+	}
+	return l.tag.String()
+}
+
+// T translates msg and formats it with args using fmt verbs. Messages without
+// a translation are returned as they are.
+func (l *Localizer) T(msg string, args ...interface{}) string {
+	if l != nil {
+		if translated, ok := l.messages[msg]; ok && translated != "" {
+			msg = translated
+		}
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+// Error translates the message of err. Errors wrapping another error with a
+// "context: cause" message are translated piece by piece.
+func (l *Localizer) Error(err error) string {
+	msg := err.Error()
+	if l == nil {
+		return msg
+	}
+	if translated, ok := l.messages[msg]; ok && translated != "" {
+		return translated
+	}
+	if inner := errors.Unwrap(err); inner != nil {
+		if prefix, ok := strings.CutSuffix(msg, ": "+inner.Error()); ok {
+			return l.T(prefix) + ": " + l.Error(inner)
+		}
+		if suffix, ok := strings.CutPrefix(msg, inner.Error()+": "); ok {
+			return l.Error(inner) + ": " + suffix
+		}
+	}
+	return msg
+}
+
+type contextKey struct{}
+
+// WithLocalizer returns a copy of ctx carrying l
+func WithLocalizer(ctx context.Context, l *Localizer) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the localizer carried by ctx, or nil, which leaves
+// messages untranslated
+func FromContext(ctx context.Context) *Localizer {
+	l, _ := ctx.Value(contextKey{}).(*Localizer)
+	return l
+}
+
+// T translates msg to the locale of ctx
+func T(ctx context.Context, msg string, args ...interface{}) string {
+	return FromContext(ctx).T(msg, args...)
+}
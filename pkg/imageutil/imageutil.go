@@ -0,0 +1,60 @@
+package imageutil
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	_ "image/gif" // register GIF decoder
+	"image/jpeg"
+	"image/png"
+	"io"
+
+	"golang.org/x/image/draw"
+)
+
+// ErrUnsupportedFormat is returned for images that are not JPEG, PNG or GIF
+var ErrUnsupportedFormat = errors.New("unsupported image format")
+
+// SupportedContentTypes lists the image types that can be decoded and resized
+var SupportedContentTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+}
+
+// Resize scales an image so its longest side is at most maxSize pixels, preserving
+// the aspect ratio. JPEG input is re-encoded as JPEG, everything else as PNG.
+func Resize(r io.Reader, maxSize int) ([]byte, string, error) {
+	src, format, err := image.Decode(r)
+	if err != nil {
+		return nil, "", ErrUnsupportedFormat
+	}
+
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width > maxSize || height > maxSize {
+		if width >= height {
+			height = height * maxSize / width
+			width = maxSize
+		} else {
+			width = width * maxSize / height
+			height = maxSize
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, max(width, 1), max(height, 1)))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Over, nil)
+
+	var buf bytes.Buffer
+	if format == "jpeg" {
+		if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 85}); err != nil {
+			return nil, "", err
+		}
+		return buf.Bytes(), "image/jpeg", nil
+	}
+
+	if err := png.Encode(&buf, dst); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), "image/png", nil
+}
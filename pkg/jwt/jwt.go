@@ -1,48 +1,266 @@
 package jwt
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"time"
 
+	"github.com/firdanbash/go-clean-boiler/pkg/config"
+	"github.com/firdanbash/go-clean-boiler/pkg/reqctx"
 	"github.com/golang-jwt/jwt/v5"
 )
 
 var (
 	ErrInvalidToken = errors.New("invalid token")
 	ErrExpiredToken = errors.New("token has expired")
+	ErrRevokedToken = errors.New("token has been revoked")
+	ErrNoSigningKey = errors.New("no signing key configured")
+	ErrWrongTenant  = errors.New("token belongs to another tenant")
+)
+
+// Denylist stores revoked token IDs until their natural expiry, and checks
+// the token version of users, bumped to invalidate all their tokens at once
+type Denylist interface {
+	Revoke(ctx context.Context, tokenID string, expiresAt time.Time) error
+	IsRevoked(ctx context.Context, tokenID string) (bool, error)
+	IsRevokedForUser(ctx context.Context, userID uint, tokenVersion uint) (bool, error)
+}
+
+// Token purposes. Tokens with a purpose never grant API access.
+const (
+	// PurposeMFA marks a challenge token that may only be exchanged for a full token after MFA verification
+	PurposeMFA = "mfa"
+	// PurposeDownload marks a token that only grants the download of the resource named by its subject
+	PurposeDownload = "download"
 )
 
 type Claims struct {
-	UserID uint   `json:"user_id"`
-	Email  string `json:"email"`
+	UserID         uint   `json:"user_id"`
+	TenantID       uint   `json:"tenant_id,omitempty"`
+	Email          string `json:"email"`
+	Role           string `json:"role,omitempty"`
+	TokenVersion   uint   `json:"token_version,omitempty"` // token version of the user when the token was issued
+	Purpose        string `json:"purpose,omitempty"`
+	RememberMe     bool   `json:"remember_me,omitempty"`     // on MFA challenge tokens, whether the login opted into remember me
+	ImpersonatorID uint   `json:"impersonator_id,omitempty"` // on impersonation tokens, the admin acting as UserID
 	jwt.RegisteredClaims
 }
 
-// GenerateToken generates a new JWT token
-func GenerateToken(userID uint, email string, secret string, expiration time.Duration) (string, error) {
+// Manager signs and validates tokens using a set of keys identified by key ID (kid).
+// New tokens are signed with the current key; any key in the set can verify.
+type Manager struct {
+	keys     map[string]*signingKey
+	current  *signingKey
+	issuer   string
+	audience string
+}
+
+// NewManager creates a token manager from JWT config.
+// HMAC algorithms use the shared secret; RSA, ECDSA and EdDSA algorithms use PEM encoded keys.
+// A manager configured with only a public key can validate but not issue tokens.
+func NewManager(cfg config.JWTConfig) (*Manager, error) {
+	keys, current, err := keySetFromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Manager{
+		keys:     keys,
+		current:  current,
+		issuer:   cfg.Issuer,
+		audience: cfg.Audience,
+	}, nil
+}
+
+// Algorithm returns the signing algorithm name of the current key
+func (m *Manager) Algorithm() string {
+	return m.current.method.Alg()
+}
+
+// GenerateToken generates a new JWT token. tenantID is 0 for a user that
+// belongs to no tenant; tokenVersion is the current token version of the user.
+func (m *Manager) GenerateToken(userID, tenantID uint, email, role string, tokenVersion uint, expiration time.Duration) (string, error) {
+	token, _, err := m.IssueToken(userID, tenantID, email, role, tokenVersion, expiration)
+	return token, err
+}
+
+// IssueToken generates a new JWT token like GenerateToken and also returns
+// its claims, for callers that keep track of the tokens they issue
+func (m *Manager) IssueToken(userID, tenantID uint, email, role string, tokenVersion uint, expiration time.Duration) (string, *Claims, error) {
+	return m.generate(Claims{
+		UserID:       userID,
+		TenantID:     tenantID,
+		Email:        email,
+		Role:         role,
+		TokenVersion: tokenVersion,
+	}, expiration)
+}
+
+// IssueImpersonationToken generates a token of the user (the subject) that
+// also names the admin acting as them (the actor) in ImpersonatorID
+func (m *Manager) IssueImpersonationToken(userID, tenantID uint, email, role string, tokenVersion, impersonatorID uint, expiration time.Duration) (string, *Claims, error) {
+	return m.generate(Claims{
+		UserID:         userID,
+		TenantID:       tenantID,
+		Email:          email,
+		Role:           role,
+		TokenVersion:   tokenVersion,
+		ImpersonatorID: impersonatorID,
+	}, expiration)
+}
+
+// GenerateMFAToken generates a short-lived MFA challenge token. rememberMe
+// carries the choice of the login over to the token issued after verification.
+func (m *Manager) GenerateMFAToken(userID, tenantID uint, email string, rememberMe bool, expiration time.Duration) (string, error) {
+	token, _, err := m.generate(Claims{
+		UserID:     userID,
+		TenantID:   tenantID,
+		Email:      email,
+		Purpose:    PurposeMFA,
+		RememberMe: rememberMe,
+	}, expiration)
+	return token, err
+}
+
+// GenerateDownloadToken generates a token granting the download of the
+// resource named by subject, such as a data export of the user, until it expires
+func (m *Manager) GenerateDownloadToken(userID, tenantID uint, subject string, expiration time.Duration) (string, error) {
 	claims := Claims{
-		UserID: userID,
-		Email:  email,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiration)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
-		},
+		UserID:   userID,
+		TenantID: tenantID,
+		Purpose:  PurposeDownload,
+	}
+	claims.Subject = subject
+	token, _, err := m.generate(claims, expiration)
+	return token, err
+}
+
+// generate signs claims with the current key after filling in their
+// registered claims: a new token ID, the issuer, audience and lifetime. The
+// subject of claims is kept.
+func (m *Manager) generate(claims Claims, expiration time.Duration) (string, *Claims, error) {
+	if m.current.signKey == nil {
+		return "", nil, ErrNoSigningKey
+	}
+
+	tokenID, err := newTokenID()
+	if err != nil {
+		return "", nil, err
+	}
+
+	now := time.Now()
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ID:        tokenID,
+		Subject:   claims.Subject,
+		Issuer:    m.issuer,
+		ExpiresAt: jwt.NewNumericDate(now.Add(expiration)),
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+	}
+	if m.audience != "" {
+		claims.Audience = jwt.ClaimStrings{m.audience}
+	}
+
+	token := jwt.NewWithClaims(m.current.method, claims)
+	if m.current.id != "" {
+		token.Header["kid"] = m.current.id
+	}
+	signed, err := token.SignedString(m.current.signKey)
+	if err != nil {
+		return "", nil, err
+	}
+	return signed, &claims, nil
+}
+
+// ValidateToken validates an access token and returns the claims
+func (m *Manager) ValidateToken(tokenString string) (*Claims, error) {
+	claims, err := m.parse(tokenString)
+	if err != nil {
+		return nil, err
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(secret))
+	// Challenge and download tokens must never grant API access
+	if claims.Purpose != "" {
+		return nil, ErrInvalidToken
+	}
+
+	return claims, nil
 }
 
-// ValidateToken validates a JWT token and returns the claims
-func ValidateToken(tokenString string, secret string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		// Validate the signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, ErrInvalidToken
+// ValidateTokenWithDenylist validates an access token and rejects it if it has been revoked,
+// or if ctx carries a tenant other than the one of the token
+func (m *Manager) ValidateTokenWithDenylist(ctx context.Context, tokenString string, denylist Denylist) (*Claims, error) {
+	claims, err := m.ValidateToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	if tenantID, ok := reqctx.TenantID(ctx); ok && claims.TenantID != tenantID {
+		return nil, ErrWrongTenant
+	}
+
+	if denylist == nil {
+		return claims, nil
+	}
+
+	if claims.ID != "" {
+		revoked, err := denylist.IsRevoked(ctx, claims.ID)
+		if err != nil {
+			return nil, err
 		}
-		return []byte(secret), nil
-	})
+		if revoked {
+			return nil, ErrRevokedToken
+		}
+	}
+
+	revoked, err := denylist.IsRevokedForUser(ctx, claims.UserID, claims.TokenVersion)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, ErrRevokedToken
+	}
+
+	return claims, nil
+}
+
+// ValidateMFAToken validates an MFA challenge token and returns the claims
+func (m *Manager) ValidateMFAToken(tokenString string) (*Claims, error) {
+	return m.validatePurpose(tokenString, PurposeMFA)
+}
+
+// ValidateDownloadToken validates a download token and returns the claims;
+// the subject names the resource it grants
+func (m *Manager) ValidateDownloadToken(tokenString string) (*Claims, error) {
+	return m.validatePurpose(tokenString, PurposeDownload)
+}
+
+// validatePurpose validates a token issued for purpose
+func (m *Manager) validatePurpose(tokenString, purpose string) (*Claims, error) {
+	claims, err := m.parse(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.Purpose != purpose {
+		return nil, ErrInvalidToken
+	}
+
+	return claims, nil
+}
+
+func (m *Manager) parse(tokenString string) (*Claims, error) {
+	var opts []jwt.ParserOption
+	if m.issuer != "" {
+		opts = append(opts, jwt.WithIssuer(m.issuer))
+	}
+	if m.audience != "" {
+		opts = append(opts, jwt.WithAudience(m.audience))
+	}
+
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, m.keyFunc, opts...)
 
 	if err != nil {
 		if errors.Is(err, jwt.ErrTokenExpired) {
@@ -59,6 +277,34 @@ func ValidateToken(tokenString string, secret string) (*Claims, error) {
 	return claims, nil
 }
 
+// keyFunc resolves the verification key from the kid header.
+// Tokens without a kid are verified with the current key.
+func (m *Manager) keyFunc(token *jwt.Token) (interface{}, error) {
+	key := m.current
+	if kid, ok := token.Header["kid"].(string); ok {
+		key, ok = m.keys[kid]
+		if !ok {
+			return nil, ErrInvalidToken
+		}
+	}
+
+	// Pin the algorithm so a token cannot downgrade to "none" or HMAC-with-public-key
+	if token.Method.Alg() != key.method.Alg() || key.verifyKey == nil {
+		return nil, ErrInvalidToken
+	}
+
+	return key.verifyKey, nil
+}
+
+// newTokenID generates a random unique token identifier (jti)
+func newTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
 // ParseDuration parses a duration string (e.g., "24h", "30m")
 func ParseDuration(s string) (time.Duration, error) {
 	return time.ParseDuration(s)
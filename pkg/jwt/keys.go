@@ -0,0 +1,182 @@
+package jwt
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/firdanbash/go-clean-boiler/pkg/config"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// signingKey is a single entry of the key set, identified by its key ID (kid)
+type signingKey struct {
+	id        string
+	method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+// keySetFromConfig builds the key set and returns it along with the current signing key.
+// Without an explicit jwt.keys list the top-level algorithm/secret/key settings form a single key.
+func keySetFromConfig(cfg config.JWTConfig) (map[string]*signingKey, *signingKey, error) {
+	keyConfigs := cfg.Keys
+	if len(keyConfigs) == 0 {
+		keyConfigs = []config.JWTKeyConfig{{
+			Algorithm:      cfg.Algorithm,
+			Secret:         cfg.Secret,
+			PrivateKey:     cfg.PrivateKey,
+			PrivateKeyFile: cfg.PrivateKeyFile,
+			PublicKey:      cfg.PublicKey,
+			PublicKeyFile:  cfg.PublicKeyFile,
+			Current:        true,
+		}}
+	}
+
+	keys := make(map[string]*signingKey, len(keyConfigs))
+	var current *signingKey
+
+	for _, kc := range keyConfigs {
+		if _, exists := keys[kc.ID]; exists {
+			return nil, nil, fmt.Errorf("duplicate jwt key id: %q", kc.ID)
+		}
+
+		algorithm := kc.Algorithm
+		if algorithm == "" {
+			algorithm = cfg.Algorithm
+		}
+
+		key, err := newSigningKey(kc, algorithm)
+		if err != nil {
+			return nil, nil, fmt.Errorf("jwt key %q: %w", kc.ID, err)
+		}
+		keys[kc.ID] = key
+
+		if kc.Current {
+			if current != nil {
+				return nil, nil, errors.New("only one jwt key can be marked as current")
+			}
+			current = key
+		}
+	}
+
+	if current == nil {
+		return nil, nil, errors.New("no jwt key is marked as current")
+	}
+
+	return keys, current, nil
+}
+
+// newSigningKey loads the key material of a single key
+func newSigningKey(kc config.JWTKeyConfig, algorithm string) (*signingKey, error) {
+	if algorithm == "" {
+		algorithm = jwt.SigningMethodHS256.Alg()
+	}
+
+	method := jwt.GetSigningMethod(algorithm)
+	if method == nil {
+		return nil, fmt.Errorf("unsupported jwt algorithm: %s", algorithm)
+	}
+
+	signKey, verifyKey, err := loadKeys(method, kc)
+	if err != nil {
+		return nil, err
+	}
+
+	return &signingKey{
+		id:        kc.ID,
+		method:    method,
+		signKey:   signKey,
+		verifyKey: verifyKey,
+	}, nil
+}
+
+// loadKeys returns the signing and verification keys for the given method
+func loadKeys(method jwt.SigningMethod, kc config.JWTKeyConfig) (signKey, verifyKey interface{}, err error) {
+	if _, ok := method.(*jwt.SigningMethodHMAC); ok {
+		if kc.Secret == "" {
+			return nil, nil, errors.New("jwt secret is required for HMAC algorithms")
+		}
+		return []byte(kc.Secret), []byte(kc.Secret), nil
+	}
+
+	privatePEM, err := readPEM(kc.PrivateKey, kc.PrivateKeyFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read jwt private key: %w", err)
+	}
+	publicPEM, err := readPEM(kc.PublicKey, kc.PublicKeyFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read jwt public key: %w", err)
+	}
+
+	if privatePEM == nil && publicPEM == nil {
+		return nil, nil, fmt.Errorf("a private or public key is required for %s", method.Alg())
+	}
+
+	switch method.(type) {
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+		if privatePEM != nil {
+			key, err := jwt.ParseRSAPrivateKeyFromPEM(privatePEM)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid RSA private key: %w", err)
+			}
+			signKey, verifyKey = key, &key.PublicKey
+		}
+		if publicPEM != nil {
+			key, err := jwt.ParseRSAPublicKeyFromPEM(publicPEM)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid RSA public key: %w", err)
+			}
+			verifyKey = key
+		}
+	case *jwt.SigningMethodECDSA:
+		if privatePEM != nil {
+			key, err := jwt.ParseECPrivateKeyFromPEM(privatePEM)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid ECDSA private key: %w", err)
+			}
+			signKey, verifyKey = key, &key.PublicKey
+		}
+		if publicPEM != nil {
+			key, err := jwt.ParseECPublicKeyFromPEM(publicPEM)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid ECDSA public key: %w", err)
+			}
+			verifyKey = key
+		}
+	case *jwt.SigningMethodEd25519:
+		if privatePEM != nil {
+			key, err := jwt.ParseEdPrivateKeyFromPEM(privatePEM)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid Ed25519 private key: %w", err)
+			}
+			signKey = key
+			if signer, ok := key.(interface{ Public() crypto.PublicKey }); ok {
+				verifyKey = signer.Public()
+			}
+		}
+		if publicPEM != nil {
+			key, err := jwt.ParseEdPublicKeyFromPEM(publicPEM)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid Ed25519 public key: %w", err)
+			}
+			verifyKey = key
+		}
+	default:
+		return nil, nil, fmt.Errorf("unsupported jwt algorithm: %s", method.Alg())
+	}
+
+	return signKey, verifyKey, nil
+}
+
+// readPEM returns inline PEM data if set, otherwise the contents of the given file
+func readPEM(inline, path string) ([]byte, error) {
+	if inline != "" {
+		return []byte(inline), nil
+	}
+	if path == "" {
+		return nil, nil
+	}
+	return os.ReadFile(path)
+}
@@ -1,67 +1,163 @@
 package logger
 
 import (
+	"context"
+	"os"
+
+	"github.com/firdanbash/go-clean-boiler/pkg/reqctx"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
-var Log *zap.Logger
+// Field is a strongly typed log field, built with the zap constructors such as
+// zap.String and zap.Error regardless of the Logger implementation
+type Field = zap.Field
 
-// Init initializes the zap logger
-func Init(level string, encoding string) error {
-	var config zap.Config
+// Logger is the structured logger handlers, services and middleware receive
+// through their constructors. Tests can pass a capturing or no-op logger and
+// the backend can be swapped for slog without touching call sites.
+type Logger interface {
+	Debug(msg string, fields ...Field)
+	Info(msg string, fields ...Field)
+	Warn(msg string, fields ...Field)
+	Error(msg string, fields ...Field)
+	// With returns a logger that adds fields to every entry
+	With(fields ...Field) Logger
+}
 
-	// Parse log level
-	logLevel := zapcore.InfoLevel
-	if err := logLevel.UnmarshalText([]byte(level)); err != nil {
-		logLevel = zapcore.InfoLevel
-	}
+// std is the process-wide default logger used by the package-level helpers and by
+// FromContext. It discards everything until Init or SetDefault is called, so
+// tests need no setup.
+var std = Nop()
 
-	if encoding == "json" {
-		config = zap.NewProductionConfig()
-	} else {
-		config = zap.NewDevelopmentConfig()
-	}
+// atomicLevel is the level of loggers built by Init so it can be changed at runtime
+var atomicLevel = zap.NewAtomicLevel()
 
-	config.Level = zap.NewAtomicLevelAt(logLevel)
-	config.EncoderConfig.TimeKey = "timestamp"
-	config.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+type contextKey struct{}
 
-	logger, err := config.Build()
+// ParseLevel parses a level name, falling back to info for unknown names
+func ParseLevel(level string) zapcore.Level {
+	lvl := zapcore.InfoLevel
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return zapcore.InfoLevel
+	}
+	return lvl
+}
+
+// Init builds the default logger
+func Init(level string, encoding string) error {
+	atomicLevel.SetLevel(ParseLevel(level))
+	logger, err := New(atomicLevel, encoding)
 	if err != nil {
 		return err
 	}
 
-	Log = logger
+	std = logger
+	return nil
+}
+
+// Default returns the default logger
+func Default() Logger {
+	return std
+}
+
+// SetDefault replaces the default logger
+func SetDefault(l Logger) {
+	std = l
+}
+
+// SetLevel changes the minimum level of the default logger at runtime
+func SetLevel(lvl string) error {
+	var l zapcore.Level
+	if err := l.UnmarshalText([]byte(lvl)); err != nil {
+		return err
+	}
+	atomicLevel.SetLevel(l)
 	return nil
 }
 
+// Level returns the name of the minimum level of the default logger
+func Level() string {
+	return atomicLevel.String()
+}
+
+// WithContext returns a copy of ctx carrying extra log fields, on top of any
+// fields added earlier in the call chain
+func WithContext(ctx context.Context, fields ...Field) context.Context {
+	base, _ := ctx.Value(contextKey{}).([]Field)
+	merged := make([]Field, 0, len(base)+len(fields))
+	merged = append(append(merged, base...), fields...)
+	return context.WithValue(ctx, contextKey{}, merged)
+}
+
+// Ctx returns l annotated with the request ID, user ID and trace/span IDs found
+// in ctx, plus any fields attached with WithContext
+func Ctx(ctx context.Context, l Logger) Logger {
+	fields, _ := ctx.Value(contextKey{}).([]Field)
+	fields = append(fields[:len(fields):len(fields)], contextFields(ctx)...)
+	if len(fields) == 0 {
+		return l
+	}
+	return l.With(fields...)
+}
+
+// FromContext returns the default logger annotated with the fields of ctx
+func FromContext(ctx context.Context) Logger {
+	return Ctx(ctx, std)
+}
+
+// contextFields returns the request-scoped IDs found in ctx
+func contextFields(ctx context.Context) []Field {
+	var fields []Field
+	if requestID := reqctx.RequestID(ctx); requestID != "" {
+		fields = append(fields, zap.String("request_id", requestID))
+	}
+	if userID, ok := reqctx.UserID(ctx); ok {
+		fields = append(fields, zap.Uint("user_id", userID))
+	}
+	if impersonatorID, ok := reqctx.ImpersonatorID(ctx); ok {
+		fields = append(fields, zap.Uint("impersonator_id", impersonatorID))
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		fields = append(fields,
+			zap.String("trace_id", sc.TraceID().String()),
+			zap.String("span_id", sc.SpanID().String()),
+		)
+	}
+	return fields
+}
+
 // Info logs an info message
-func Info(msg string, fields ...zap.Field) {
-	Log.Info(msg, fields...)
+func Info(msg string, fields ...Field) {
+	std.Info(msg, fields...)
 }
 
 // Error logs an error message
-func Error(msg string, fields ...zap.Field) {
-	Log.Error(msg, fields...)
+func Error(msg string, fields ...Field) {
+	std.Error(msg, fields...)
 }
 
 // Debug logs a debug message
-func Debug(msg string, fields ...zap.Field) {
-	Log.Debug(msg, fields...)
+func Debug(msg string, fields ...Field) {
+	std.Debug(msg, fields...)
 }
 
 // Warn logs a warning message
-func Warn(msg string, fields ...zap.Field) {
-	Log.Warn(msg, fields...)
+func Warn(msg string, fields ...Field) {
+	std.Warn(msg, fields...)
 }
 
-// Fatal logs a fatal message and exits
-func Fatal(msg string, fields ...zap.Field) {
-	Log.Fatal(msg, fields...)
+// Fatal logs an error message, flushes the default logger and exits
+func Fatal(msg string, fields ...Field) {
+	std.Error(msg, fields...)
+	Sync()
+	os.Exit(1)
 }
 
-// Sync flushes any buffered log entries
+// Sync flushes any buffered log entries of the default logger
 func Sync() {
-	_ = Log.Sync()
+	if zl, ok := Zap(std); ok {
+		_ = zl.Sync()
+	}
 }
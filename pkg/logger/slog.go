@@ -0,0 +1,59 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// slogLogger adapts a log/slog logger to Logger
+type slogLogger struct {
+	l *slog.Logger
+}
+
+// NewSlog wraps a log/slog logger, e.g. to use one of its handlers as the backend
+func NewSlog(l *slog.Logger) Logger {
+	return &slogLogger{l: l}
+}
+
+func (s *slogLogger) Debug(msg string, fields ...Field) { s.log(slog.LevelDebug, msg, fields) }
+func (s *slogLogger) Info(msg string, fields ...Field)  { s.log(slog.LevelInfo, msg, fields) }
+func (s *slogLogger) Warn(msg string, fields ...Field)  { s.log(slog.LevelWarn, msg, fields) }
+func (s *slogLogger) Error(msg string, fields ...Field) { s.log(slog.LevelError, msg, fields) }
+
+func (s *slogLogger) With(fields ...Field) Logger {
+	if len(fields) == 0 {
+		return s
+	}
+	return &slogLogger{l: s.l.With(attrs(fields)...)}
+}
+
+func (s *slogLogger) log(level slog.Level, msg string, fields []Field) {
+	ctx := context.Background()
+	if !s.l.Enabled(ctx, level) {
+		return
+	}
+	s.l.Log(ctx, level, msg, attrs(fields)...)
+}
+
+// attrs converts zap fields to slog attributes, encoding each field the way
+// zap's map encoder would so errors, durations and objects keep their values
+func attrs(fields []Field) []any {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	out := make([]any, 0, len(enc.Fields))
+	for _, f := range fields {
+		if v, ok := enc.Fields[f.Key]; ok {
+			out = append(out, slog.Any(f.Key, v))
+			delete(enc.Fields, f.Key)
+		}
+	}
+	// Fields that wrote extra keys, such as errors with a verbose form
+	for k, v := range enc.Fields {
+		out = append(out, slog.Any(k, v))
+	}
+	return out
+}
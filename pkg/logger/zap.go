@@ -0,0 +1,64 @@
+package logger
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// zapLogger adapts a zap logger to Logger
+type zapLogger struct {
+	l *zap.Logger
+}
+
+// New builds a zap-backed logger whose level is controlled by level. The encoding
+// is "json" for production output or "console" for development.
+func New(level zap.AtomicLevel, encoding string) (Logger, error) {
+	var config zap.Config
+	if encoding == "json" {
+		config = zap.NewProductionConfig()
+	} else {
+		config = zap.NewDevelopmentConfig()
+	}
+
+	config.Level = level
+	config.EncoderConfig.TimeKey = "timestamp"
+	config.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+
+	l, err := config.Build(zap.AddCallerSkip(1))
+	if err != nil {
+		return nil, err
+	}
+	return &zapLogger{l: l}, nil
+}
+
+// NewZap wraps an existing zap logger. Caller annotations point at the adapter
+// unless l was built with zap.AddCallerSkip(1).
+func NewZap(l *zap.Logger) Logger {
+	return &zapLogger{l: l}
+}
+
+// Nop returns a logger that discards everything
+func Nop() Logger {
+	return &zapLogger{l: zap.NewNop()}
+}
+
+// Zap returns the zap logger behind l, if l is zap-backed
+func Zap(l Logger) (*zap.Logger, bool) {
+	zl, ok := l.(*zapLogger)
+	if !ok {
+		return nil, false
+	}
+	return zl.l, true
+}
+
+func (z *zapLogger) Debug(msg string, fields ...Field) { z.l.Debug(msg, fields...) }
+func (z *zapLogger) Info(msg string, fields ...Field)  { z.l.Info(msg, fields...) }
+func (z *zapLogger) Warn(msg string, fields ...Field)  { z.l.Warn(msg, fields...) }
+func (z *zapLogger) Error(msg string, fields ...Field) { z.l.Error(msg, fields...) }
+
+func (z *zapLogger) With(fields ...Field) Logger {
+	if len(fields) == 0 {
+		return z
+	}
+	return &zapLogger{l: z.l.With(fields...)}
+}
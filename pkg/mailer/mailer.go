@@ -0,0 +1,61 @@
+// Package mailer sends emails through the configured driver and renders the
+// templated emails of the application. Another provider, such as SES or
+// SendGrid, is added by implementing Mailer and selecting it in New.
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/firdanbash/go-clean-boiler/pkg/config"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Message represents an outgoing email
+type Message struct {
+	To      []string
+	Subject string
+	Body    string // plain text
+	HTML    string // optional HTML alternative of Body
+}
+
+// Mailer defines the interface for sending emails
+type Mailer interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// New creates a mailer for the configured driver
+func New(cfg config.MailConfig, log logger.Logger) (Mailer, error) {
+	switch cfg.Driver {
+	case "", "log":
+		return NewLogMailer(cfg.From, log), nil
+	case "smtp":
+		return NewSMTPMailer(cfg.SMTP, cfg.From)
+	default:
+		return nil, fmt.Errorf("unsupported mail driver: %s", cfg.Driver)
+	}
+}
+
+type logMailer struct {
+	from string
+	log  logger.Logger
+}
+
+// NewLogMailer creates a mailer that writes messages to log
+func NewLogMailer(from string, log logger.Logger) Mailer {
+	return &logMailer{from: from, log: log}
+}
+
+// Send logs the message instead of delivering it. The HTML part is left out.
+func (m *logMailer) Send(ctx context.Context, msg *Message) error {
+	logger.Ctx(ctx, m.log).Info("Email sent",
+		zap.String("from", m.from),
+		zap.String("to", strings.Join(msg.To, ", ")),
+		zap.String("subject", msg.Subject),
+		zap.String("body", msg.Body),
+		zap.Bool("html", msg.HTML != ""),
+	)
+	return nil
+}
@@ -0,0 +1,152 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"path"
+	"strings"
+	texttemplate "text/template"
+)
+
+// Email templates
+const (
+	TemplateWelcome         = "welcome"
+	TemplatePasswordReset   = "password_reset"
+	TemplatePasswordChanged = "password_changed"
+)
+
+// WelcomeData is the data of the welcome email
+type WelcomeData struct {
+	Name  string
+	Email string
+}
+
+// PasswordResetData is the data of the password reset email
+type PasswordResetData struct {
+	Name      string
+	ResetURL  string
+	ExpiresIn string
+}
+
+// PasswordChangedData is the data of the password changed email
+type PasswordChangedData struct {
+	Name      string
+	ChangedAt string
+}
+
+// OrganizationInviteData is the data of the organization invite email
+type OrganizationInviteData struct {
+	Name         string
+	Organization string
+	InvitedBy    string
+	Role         string
+}
+
+// DataExportReadyData is the data of the data export ready email
+type DataExportReadyData struct {
+	Name      string
+	ExpiresAt string
+}
+
+// templateFS holds the emails. Each email is a <name>.txt text template, which
+// defines its "subject", and an optional <name>.html template defining the
+// "content" of layout.html.
+//
+//go:embed templates
+var templateFS embed.FS
+
+// email is a parsed email template
+type email struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template // nil for text only emails
+}
+
+// Templates renders the embedded emails
+type Templates struct {
+	emails map[string]*email
+}
+
+// NewTemplates parses the embedded emails. appName is available to the
+// templates as {{appName}}.
+func NewTemplates(appName string) (*Templates, error) {
+	funcs := map[string]interface{}{"appName": func() string { return appName }}
+
+	layout, err := htmltemplate.New("layout.html").Funcs(funcs).ParseFS(templateFS, "templates/layout.html")
+	if err != nil {
+		return nil, fmt.Errorf("parse email layout: %w", err)
+	}
+
+	texts, err := fs.Glob(templateFS, "templates/*.txt")
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Templates{emails: make(map[string]*email, len(texts))}
+	for _, file := range texts {
+		name := strings.TrimSuffix(path.Base(file), ".txt")
+
+		text, err := texttemplate.New(path.Base(file)).Funcs(funcs).ParseFS(templateFS, file)
+		if err != nil {
+			return nil, fmt.Errorf("parse email %s: %w", name, err)
+		}
+		if text.Lookup("subject") == nil {
+			return nil, fmt.Errorf("email %s does not define a subject", name)
+		}
+		e := &email{text: text}
+
+		htmlFile := "templates/" + name + ".html"
+		if _, err := fs.Stat(templateFS, htmlFile); err == nil {
+			if e.html, err = layout.Clone(); err != nil {
+				return nil, err
+			}
+			if _, err := e.html.ParseFS(templateFS, htmlFile); err != nil {
+				return nil, fmt.Errorf("parse email %s: %w", name, err)
+			}
+		}
+
+		t.emails[name] = e
+	}
+
+	return t, nil
+}
+
+// Render builds the message of the email name for the recipients in to
+func (t *Templates) Render(name string, to []string, data interface{}) (*Message, error) {
+	e, ok := t.emails[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown email template: %s", name)
+	}
+
+	var subject, text bytes.Buffer
+	if err := e.text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return nil, fmt.Errorf("render email %s: %w", name, err)
+	}
+	if err := e.text.Execute(&text, data); err != nil {
+		return nil, fmt.Errorf("render email %s: %w", name, err)
+	}
+
+	msg := &Message{
+		To:      to,
+		Subject: strings.TrimSpace(subject.String()),
+		Body:    strings.TrimSpace(text.String()),
+	}
+
+	if e.html != nil {
+		var html bytes.Buffer
+		if err := e.html.Execute(&html, data); err != nil {
+			return nil, fmt.Errorf("render email %s: %w", name, err)
+		}
+		msg.HTML = html.String()
+	}
+
+	return msg, nil
+}
+
+// Has reports whether there is an email template named name
+func (t *Templates) Has(name string) bool {
+	_, ok := t.emails[name]
+	return ok
+}
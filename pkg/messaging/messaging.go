@@ -0,0 +1,112 @@
+// Package messaging publishes messages to and consumes messages from a
+// message broker. Drivers are selected with messaging.driver: rabbitmq, nats,
+// log, which writes messages to the log, and none, which drops them.
+package messaging
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/firdanbash/go-clean-boiler/pkg/config"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Message is a message published to a topic
+type Message struct {
+	ID      string // unique per message, so consumers can drop redeliveries
+	Topic   string
+	Key     string // routing or ordering key, e.g. the ID of the entity
+	Body    []byte
+	Headers map[string]string
+}
+
+// Publisher publishes messages to the broker. Publish returns once the broker
+// has accepted the message.
+type Publisher interface {
+	Publish(ctx context.Context, msg *Message) error
+	Close() error
+}
+
+// Handler processes a consumed message. Returning nil acknowledges the message;
+// an error rejects it, to be redelivered according to the driver's policy.
+// Messages may be delivered more than once, so handlers should be idempotent.
+type Handler func(ctx context.Context, msg *Message) error
+
+// Subscriber consumes messages from the broker
+type Subscriber interface {
+	// Subscribe passes the messages of queue to handler until ctx is done or
+	// the connection is lost
+	Subscribe(ctx context.Context, queue string, handler Handler) error
+	Close() error
+}
+
+// NewPublisher creates a publisher for the configured driver
+func NewPublisher(cfg config.MessagingConfig, log logger.Logger) (Publisher, error) {
+	switch cfg.Driver {
+	case "rabbitmq":
+		return NewRabbitMQ(cfg.RabbitMQ, log)
+	case "nats":
+		return NewNATS(cfg.NATS, log)
+	case "", "log":
+		return NewLogPublisher(log), nil
+	case "none":
+		return discardPublisher{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported messaging driver: %s", cfg.Driver)
+	}
+}
+
+// NewSubscriber creates a subscriber for the configured driver
+func NewSubscriber(cfg config.MessagingConfig, log logger.Logger) (Subscriber, error) {
+	switch cfg.Driver {
+	case "rabbitmq":
+		return NewRabbitMQ(cfg.RabbitMQ, log)
+	case "nats":
+		return NewNATS(cfg.NATS, log)
+	default:
+		return nil, fmt.Errorf("messaging driver %q cannot consume messages", cfg.Driver)
+	}
+}
+
+// callHandler runs handler, turning a panic into an error
+func callHandler(ctx context.Context, handler Handler, msg *Message) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return handler(ctx, msg)
+}
+
+type logPublisher struct {
+	log logger.Logger
+}
+
+// NewLogPublisher creates a publisher that writes messages to log
+func NewLogPublisher(log logger.Logger) Publisher {
+	return &logPublisher{log: log}
+}
+
+// Publish logs the message instead of delivering it
+func (p *logPublisher) Publish(ctx context.Context, msg *Message) error {
+	logger.Ctx(ctx, p.log).Info("Message published",
+		zap.String("id", msg.ID),
+		zap.String("topic", msg.Topic),
+		zap.String("key", msg.Key),
+		zap.ByteString("body", msg.Body),
+	)
+	return nil
+}
+
+// Close does nothing
+func (p *logPublisher) Close() error {
+	return nil
+}
+
+// discardPublisher drops every message
+type discardPublisher struct{}
+
+func (discardPublisher) Publish(context.Context, *Message) error { return nil }
+
+func (discardPublisher) Close() error { return nil }
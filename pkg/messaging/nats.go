@@ -0,0 +1,200 @@
+package messaging
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/firdanbash/go-clean-boiler/pkg/config"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"go.uber.org/zap"
+)
+
+// natsSetupTimeout bounds creating the stream and consumers on connect
+const natsSetupTimeout = 10 * time.Second
+
+// NATS publishes to and consumes from a NATS JetStream stream. Each topic is
+// published on its own subject, <subject_prefix>.<topic>, and the stream
+// stores every subject under the prefix. The stream and the durable consumers
+// of the configuration are created or updated when connecting.
+type NATS struct {
+	cfg  config.NATSConfig
+	conn *nats.Conn
+	js   jetstream.JetStream
+	log  logger.Logger
+}
+
+// NewNATS connects to NATS and creates the stream and durable consumers
+func NewNATS(cfg config.NATSConfig, log logger.Logger) (*NATS, error) {
+	conn, err := nats.Connect(cfg.URL,
+		nats.Name(cfg.Stream),
+		nats.MaxReconnects(-1),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			if err != nil {
+				log.Warn("Disconnected from NATS", zap.Error(err))
+			}
+		}),
+		nats.ReconnectHandler(func(c *nats.Conn) {
+			log.Info("Reconnected to NATS", zap.String("url", c.ConnectedUrlRedacted()))
+		}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to open JetStream: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), natsSetupTimeout)
+	defer cancel()
+
+	n := &NATS{cfg: cfg, conn: conn, js: js, log: log}
+	if err := n.declare(ctx); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return n, nil
+}
+
+// declare creates or updates the stream and the durable consumers
+func (n *NATS) declare(ctx context.Context) error {
+	_, err := n.js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:       n.cfg.Stream,
+		Subjects:   []string{n.cfg.SubjectPrefix + ".>"},
+		Storage:    jetstream.FileStorage,
+		Replicas:   n.cfg.Replicas,
+		MaxAge:     n.cfg.MaxAge,
+		Duplicates: n.cfg.DuplicateWindow,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create stream %s: %w", n.cfg.Stream, err)
+	}
+
+	for name, consumer := range n.cfg.Consumers {
+		subjects := make([]string, len(consumer.Topics))
+		for i, topic := range consumer.Topics {
+			subjects[i] = n.subject(topic)
+		}
+
+		_, err := n.js.CreateOrUpdateConsumer(ctx, n.cfg.Stream, jetstream.ConsumerConfig{
+			Durable:        name,
+			FilterSubjects: subjects,
+			AckPolicy:      jetstream.AckExplicitPolicy,
+			AckWait:        n.cfg.AckWait,
+			MaxDeliver:     n.cfg.MaxDeliver,
+			MaxAckPending:  n.cfg.Prefetch,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create consumer %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// subject returns the subject a topic is published on
+func (n *NATS) subject(topic string) string {
+	return n.cfg.SubjectPrefix + "." + topic
+}
+
+// Publish publishes msg on the subject of its topic and waits for JetStream to
+// store it, at most PublishTimeout. The message ID is sent as Nats-Msg-Id, so
+// a message published twice within the duplicate window is stored once.
+func (n *NATS) Publish(ctx context.Context, msg *Message) error {
+	ctx, cancel := context.WithTimeout(ctx, n.cfg.PublishTimeout)
+	defer cancel()
+
+	m := nats.NewMsg(n.subject(msg.Topic))
+	m.Data = msg.Body
+	for name, value := range msg.Headers {
+		m.Header.Set(name, value)
+	}
+	if msg.Key != "" {
+		m.Header.Set(keyHeader, msg.Key)
+	}
+
+	var opts []jetstream.PublishOpt
+	if msg.ID != "" {
+		opts = append(opts, jetstream.WithMsgID(msg.ID))
+	}
+	if _, err := n.js.PublishMsg(ctx, m, opts...); err != nil {
+		return fmt.Errorf("failed to publish to NATS: %w", err)
+	}
+	return nil
+}
+
+// Subscribe consumes the durable consumer named queue until ctx is done. A
+// message is acknowledged when handler returns nil and negatively
+// acknowledged otherwise, to be redelivered until it has been delivered
+// MaxDeliver times. The client reconnects on its own when the connection drops.
+func (n *NATS) Subscribe(ctx context.Context, queue string, handler Handler) error {
+	consumer, err := n.js.Consumer(ctx, n.cfg.Stream, queue)
+	if err != nil {
+		return fmt.Errorf("failed to find consumer %s: %w", queue, err)
+	}
+
+	consumeCtx, err := consumer.Consume(func(m jetstream.Msg) {
+		n.handle(ctx, queue, m, handler)
+	},
+		jetstream.PullMaxMessages(n.prefetch()),
+		jetstream.ConsumeErrHandler(func(_ jetstream.ConsumeContext, err error) {
+			n.log.Warn("NATS consumer error", zap.String("consumer", queue), zap.Error(err))
+		}),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to consume %s: %w", queue, err)
+	}
+
+	<-ctx.Done()
+	consumeCtx.Stop()
+	return nil
+}
+
+// prefetch is the number of messages pulled ahead of the handler
+func (n *NATS) prefetch() int {
+	if n.cfg.Prefetch > 0 {
+		return n.cfg.Prefetch
+	}
+	return 1
+}
+
+// handle passes a message to handler and acknowledges it or asks for redelivery
+func (n *NATS) handle(ctx context.Context, queue string, m jetstream.Msg, handler Handler) {
+	msg := &Message{
+		ID:      m.Headers().Get(jetstream.MsgIDHeader),
+		Topic:   strings.TrimPrefix(m.Subject(), n.cfg.SubjectPrefix+"."),
+		Key:     m.Headers().Get(keyHeader),
+		Body:    m.Data(),
+		Headers: make(map[string]string, len(m.Headers())),
+	}
+	for name := range m.Headers() {
+		msg.Headers[name] = m.Headers().Get(name)
+	}
+
+	if err := callHandler(ctx, handler, msg); err != nil {
+		n.log.Warn("Failed to handle message",
+			zap.String("consumer", queue),
+			zap.String("id", msg.ID),
+			zap.String("topic", msg.Topic),
+			zap.Error(err),
+		)
+		if err := m.Nak(); err != nil {
+			n.log.Error("Failed to reject message", zap.String("consumer", queue), zap.Error(err))
+		}
+		return
+	}
+
+	if err := m.Ack(); err != nil {
+		n.log.Error("Failed to acknowledge message", zap.String("consumer", queue), zap.Error(err))
+	}
+}
+
+// Close drains the connection, letting in-flight messages finish
+func (n *NATS) Close() error {
+	return n.conn.Drain()
+}
@@ -0,0 +1,266 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/firdanbash/go-clean-boiler/pkg/config"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.uber.org/zap"
+)
+
+// Requeue policies of a consumer for messages its handler fails to process
+const (
+	RequeueNever  = "never"  // reject, dead-lettering the message when the queue has a dead-letter queue
+	RequeueOnce   = "once"   // requeue the first failure, reject a redelivered message
+	RequeueAlways = "always" // requeue every failure
+)
+
+// keyHeader carries Message.Key, since the routing key is the topic
+const keyHeader = "key"
+
+// RabbitMQ publishes to and consumes from RabbitMQ over AMQP 0-9-1. Messages
+// are published to one exchange with their topic as routing key; the exchange
+// and the queues of the configuration are declared when connecting.
+type RabbitMQ struct {
+	cfg config.RabbitMQConfig
+	log logger.Logger
+
+	mu      sync.Mutex
+	conn    *amqp.Connection
+	channel *amqp.Channel // publishing channel, in confirm mode
+}
+
+// NewRabbitMQ connects to RabbitMQ and declares the exchange and queues
+func NewRabbitMQ(cfg config.RabbitMQConfig, log logger.Logger) (*RabbitMQ, error) {
+	r := &RabbitMQ{cfg: cfg, log: log}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, err := r.connect(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// connect returns the publishing channel, reconnecting when the connection or
+// channel was closed. r.mu must be held.
+func (r *RabbitMQ) connect() (*amqp.Channel, error) {
+	if r.channel != nil && !r.channel.IsClosed() {
+		return r.channel, nil
+	}
+
+	if r.conn == nil || r.conn.IsClosed() {
+		conn, err := amqp.Dial(r.cfg.URL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to RabbitMQ: %w", err)
+		}
+		r.conn = conn
+	}
+
+	ch, err := r.conn.Channel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open RabbitMQ channel: %w", err)
+	}
+	if err := r.declare(ch); err != nil {
+		_ = ch.Close()
+		return nil, err
+	}
+	if err := ch.Confirm(false); err != nil {
+		_ = ch.Close()
+		return nil, fmt.Errorf("failed to enable publisher confirms: %w", err)
+	}
+	r.channel = ch
+	return ch, nil
+}
+
+// declare declares the exchange, the queues with their bindings and the
+// dead-letter queues. Declaring is idempotent as long as the settings match.
+func (r *RabbitMQ) declare(ch *amqp.Channel) error {
+	if err := ch.ExchangeDeclare(r.cfg.Exchange, r.cfg.ExchangeType, true, false, false, false, nil); err != nil {
+		return fmt.Errorf("failed to declare exchange %s: %w", r.cfg.Exchange, err)
+	}
+
+	for name, queue := range r.cfg.Queues {
+		args := amqp.Table{}
+		if queue.DeadLetter {
+			if err := r.declareDeadLetterQueue(ch, name); err != nil {
+				return err
+			}
+			args["x-dead-letter-exchange"] = r.cfg.DeadLetterExchange
+			args["x-dead-letter-routing-key"] = name
+		}
+
+		if _, err := ch.QueueDeclare(name, true, false, false, false, args); err != nil {
+			return fmt.Errorf("failed to declare queue %s: %w", name, err)
+		}
+		for _, key := range queue.Bindings {
+			if err := ch.QueueBind(name, key, r.cfg.Exchange, false, nil); err != nil {
+				return fmt.Errorf("failed to bind queue %s to %s: %w", name, key, err)
+			}
+		}
+	}
+	return nil
+}
+
+// declareDeadLetterQueue declares <queue>.dead, receiving the messages rejected from queue
+func (r *RabbitMQ) declareDeadLetterQueue(ch *amqp.Channel, queue string) error {
+	if err := ch.ExchangeDeclare(r.cfg.DeadLetterExchange, amqp.ExchangeDirect, true, false, false, false, nil); err != nil {
+		return fmt.Errorf("failed to declare exchange %s: %w", r.cfg.DeadLetterExchange, err)
+	}
+	dead := queue + ".dead"
+	if _, err := ch.QueueDeclare(dead, true, false, false, false, nil); err != nil {
+		return fmt.Errorf("failed to declare queue %s: %w", dead, err)
+	}
+	if err := ch.QueueBind(dead, queue, r.cfg.DeadLetterExchange, false, nil); err != nil {
+		return fmt.Errorf("failed to bind queue %s: %w", dead, err)
+	}
+	return nil
+}
+
+// Publish publishes msg as a persistent message and waits for the broker to
+// confirm it, at most PublishTimeout
+func (r *RabbitMQ) Publish(ctx context.Context, msg *Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ch, err := r.connect()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.PublishTimeout)
+	defer cancel()
+
+	headers := amqp.Table{}
+	for name, value := range msg.Headers {
+		headers[name] = value
+	}
+	if msg.Key != "" {
+		headers[keyHeader] = msg.Key
+	}
+
+	confirm, err := ch.PublishWithDeferredConfirmWithContext(ctx, r.cfg.Exchange, msg.Topic, false, false, amqp.Publishing{
+		MessageId:    msg.ID,
+		ContentType:  msg.Headers["content-type"],
+		DeliveryMode: amqp.Persistent,
+		Timestamp:    time.Now(),
+		Headers:      headers,
+		Body:         msg.Body,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to publish to RabbitMQ: %w", err)
+	}
+
+	acked, err := confirm.WaitContext(ctx)
+	if err != nil {
+		return fmt.Errorf("no publisher confirm from RabbitMQ: %w", err)
+	}
+	if !acked {
+		return errors.New("message was rejected by RabbitMQ")
+	}
+	return nil
+}
+
+// Subscribe consumes queue on its own channel until ctx is done or the
+// connection is lost. Up to Prefetch messages are delivered before being
+// acknowledged. A message is acknowledged when handler returns nil, and
+// rejected according to the requeue policy otherwise.
+func (r *RabbitMQ) Subscribe(ctx context.Context, queue string, handler Handler) error {
+	r.mu.Lock()
+	if _, err := r.connect(); err != nil {
+		r.mu.Unlock()
+		return err
+	}
+	ch, err := r.conn.Channel()
+	r.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to open RabbitMQ channel: %w", err)
+	}
+	defer ch.Close()
+
+	if err := ch.Qos(r.cfg.Prefetch, 0, false); err != nil {
+		return fmt.Errorf("failed to set prefetch: %w", err)
+	}
+	deliveries, err := ch.ConsumeWithContext(ctx, queue, "", false, false, false, false, nil)
+	if err != nil {
+		return fmt.Errorf("failed to consume queue %s: %w", queue, err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case delivery, ok := <-deliveries:
+			if !ok {
+				if ctx.Err() != nil {
+					return nil
+				}
+				return fmt.Errorf("RabbitMQ closed the channel consuming %s", queue)
+			}
+			r.handle(ctx, queue, delivery, handler)
+		}
+	}
+}
+
+// handle passes a delivery to handler and acknowledges or rejects it
+func (r *RabbitMQ) handle(ctx context.Context, queue string, delivery amqp.Delivery, handler Handler) {
+	msg := &Message{
+		ID:      delivery.MessageId,
+		Topic:   delivery.RoutingKey,
+		Body:    delivery.Body,
+		Headers: make(map[string]string),
+	}
+	for name, value := range delivery.Headers {
+		if s, ok := value.(string); ok {
+			msg.Headers[name] = s
+		}
+	}
+	msg.Key = msg.Headers[keyHeader]
+
+	if err := callHandler(ctx, handler, msg); err != nil {
+		requeue := r.requeue(delivery.Redelivered)
+		r.log.Warn("Failed to handle message",
+			zap.String("queue", queue),
+			zap.String("id", msg.ID),
+			zap.String("topic", msg.Topic),
+			zap.Bool("requeue", requeue),
+			zap.Error(err),
+		)
+		if err := delivery.Nack(false, requeue); err != nil {
+			r.log.Error("Failed to reject message", zap.String("queue", queue), zap.Error(err))
+		}
+		return
+	}
+
+	if err := delivery.Ack(false); err != nil {
+		r.log.Error("Failed to acknowledge message", zap.String("queue", queue), zap.Error(err))
+	}
+}
+
+// requeue reports whether a failed delivery goes back to the queue
+func (r *RabbitMQ) requeue(redelivered bool) bool {
+	switch r.cfg.Requeue {
+	case RequeueAlways:
+		return true
+	case RequeueNever:
+		return false
+	default:
+		return !redelivered
+	}
+}
+
+// Close closes the publishing channel and the connection
+func (r *RabbitMQ) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.conn == nil || r.conn.IsClosed() {
+		return nil
+	}
+	return r.conn.Close()
+}
@@ -0,0 +1,85 @@
+// Package password hashes and verifies user passwords with bcrypt or Argon2id
+package password
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Hashing algorithms, as set in auth.password_hash.algorithm
+const (
+	AlgorithmBcrypt   = "bcrypt"
+	AlgorithmArgon2id = "argon2id"
+)
+
+// ErrUnknownHash is returned for hashes produced by none of the algorithms
+var ErrUnknownHash = errors.New("password: unknown hash format")
+
+// Hasher hashes passwords and verifies them against stored hashes
+type Hasher interface {
+	// Hash returns the encoded hash of password, including its parameters
+	Hash(password string) (string, error)
+	// Verify reports whether password matches hash
+	Verify(hash, password string) (bool, error)
+	// NeedsRehash reports whether hash was produced with other parameters than
+	// the hasher would use now, so it should be replaced on the next login
+	NeedsRehash(hash string) bool
+}
+
+// New returns a hasher hashing new passwords with algorithm. It verifies the
+// hashes of both algorithms, so existing hashes keep working after a switch and
+// are replaced on the next login.
+func New(algorithm string, bcryptCost int, argon2idParams Argon2idParams) (Hasher, error) {
+	h := &hasher{
+		bcrypt:   NewBcryptHasher(bcryptCost),
+		argon2id: NewArgon2idHasher(argon2idParams),
+	}
+	switch algorithm {
+	case AlgorithmBcrypt:
+		h.current = h.bcrypt
+	case AlgorithmArgon2id:
+		h.current = h.argon2id
+	default:
+		return nil, fmt.Errorf("password: unknown algorithm %q", algorithm)
+	}
+	return h, nil
+}
+
+type hasher struct {
+	current  Hasher
+	bcrypt   *BcryptHasher
+	argon2id *Argon2idHasher
+}
+
+// Hash hashes password with the configured algorithm
+func (h *hasher) Hash(password string) (string, error) {
+	return h.current.Hash(password)
+}
+
+// Verify checks password with the algorithm hash was produced by
+func (h *hasher) Verify(hash, password string) (bool, error) {
+	algorithm, err := h.algorithmOf(hash)
+	if err != nil {
+		return false, err
+	}
+	return algorithm.Verify(hash, password)
+}
+
+// NeedsRehash reports whether hash uses another algorithm or other parameters
+// than the configured ones
+func (h *hasher) NeedsRehash(hash string) bool {
+	algorithm, err := h.algorithmOf(hash)
+	return err != nil || algorithm != h.current || algorithm.NeedsRehash(hash)
+}
+
+func (h *hasher) algorithmOf(hash string) (Hasher, error) {
+	switch {
+	case strings.HasPrefix(hash, argon2idPrefix):
+		return h.argon2id, nil
+	case isBcrypt(hash):
+		return h.bcrypt, nil
+	default:
+		return nil, ErrUnknownHash
+	}
+}
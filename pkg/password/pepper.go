@@ -0,0 +1,93 @@
+package password
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// pepperPrefix starts the hashes of peppered passwords, followed by the
+// version of the pepper and the hash itself: $pepper$v2$argon2id$v=19$...
+const pepperPrefix = "$pepper$"
+
+// ErrUnknownPepper is returned for hashes made with a pepper no longer configured
+var ErrUnknownPepper = errors.New("password: unknown pepper version")
+
+// WithPepper returns a hasher mixing a secret pepper into passwords before h
+// hashes them, so leaked hashes cannot be cracked without the pepper, which is
+// kept out of the database. Peppers are keyed by version: new passwords use
+// current, hashes made with another version or without pepper still verify
+// and need a rehash. An empty current stops peppering new passwords.
+func WithPepper(h Hasher, peppers map[string][]byte, current string) (Hasher, error) {
+	for version := range peppers {
+		if version == "" || strings.Contains(version, "$") {
+			return nil, fmt.Errorf("password: invalid pepper version %q", version)
+		}
+	}
+	if _, ok := peppers[current]; current != "" && !ok {
+		return nil, fmt.Errorf("password: no pepper with version %q", current)
+	}
+	return &pepperedHasher{inner: h, peppers: peppers, current: current}, nil
+}
+
+type pepperedHasher struct {
+	inner   Hasher
+	peppers map[string][]byte
+	current string
+}
+
+// Hash hashes password mixed with the current pepper
+func (h *pepperedHasher) Hash(password string) (string, error) {
+	if h.current == "" {
+		return h.inner.Hash(password)
+	}
+	hash, err := h.inner.Hash(h.mix(h.current, password))
+	if err != nil {
+		return "", err
+	}
+	return pepperPrefix + h.current + hash, nil
+}
+
+// Verify checks password mixed with the pepper hash was made with, if any
+func (h *pepperedHasher) Verify(hash, password string) (bool, error) {
+	version, inner := splitPepper(hash)
+	if version == "" {
+		return h.inner.Verify(hash, password)
+	}
+	if _, ok := h.peppers[version]; !ok {
+		return false, ErrUnknownPepper
+	}
+	return h.inner.Verify(inner, h.mix(version, password))
+}
+
+// NeedsRehash reports whether hash was made with another pepper, or with
+// other settings of the inner hasher
+func (h *pepperedHasher) NeedsRehash(hash string) bool {
+	version, inner := splitPepper(hash)
+	return version != h.current || h.inner.NeedsRehash(inner)
+}
+
+// mix returns the HMAC of password keyed with the pepper of version. Encoded,
+// it stays within the 72 bytes bcrypt reads.
+func (h *pepperedHasher) mix(version, password string) string {
+	mac := hmac.New(sha256.New, h.peppers[version])
+	mac.Write([]byte(password))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// splitPepper returns the pepper version of hash and the hash of the inner
+// hasher. Hashes made without pepper have no version.
+func splitPepper(hash string) (string, string) {
+	rest, ok := strings.CutPrefix(hash, pepperPrefix)
+	if !ok {
+		return "", hash
+	}
+	version, inner, ok := strings.Cut(rest, "$")
+	if !ok {
+		return "", hash
+	}
+	return version, "$" + inner
+}
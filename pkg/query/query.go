@@ -0,0 +1,307 @@
+// Package query parses the filters of list endpoints, given as query
+// parameters such as filter[name][like]=jo&filter[created_at][gte]=2024-01-01,
+// into GORM scopes. Only the fields an entity whitelists can be filtered, and
+// values are bound as parameters, so user input never reaches the SQL directly.
+package query
+
+import (
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/firdanbash/go-clean-boiler/pkg/apperror"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Errors returned by Parse
+var (
+	ErrInvalidFilter         = apperror.Validation("invalid filter").WithCode(apperror.CodeFilterInvalid)
+	ErrUnknownFilterField    = apperror.Validation("unknown filter field").WithCode(apperror.CodeFilterFieldUnknown)
+	ErrInvalidFilterOperator = apperror.Validation("invalid filter operator").WithCode(apperror.CodeFilterOperatorInvalid)
+	ErrInvalidFilterValue    = apperror.Validation("invalid filter value").WithCode(apperror.CodeFilterValueInvalid)
+)
+
+// Filter operators. A filter without operator, filter[field]=value, uses Eq.
+const (
+	Eq   = "eq"   // equal
+	Ne   = "ne"   // not equal
+	Gt   = "gt"   // greater than
+	Gte  = "gte"  // greater than or equal
+	Lt   = "lt"   // less than
+	Lte  = "lte"  // less than or equal
+	Like = "like" // contains, case-insensitive
+	In   = "in"   // one of comma separated values
+	Null = "null" // is null when true, is not null when false
+)
+
+// Limits of a filter expression
+const (
+	MaxConditions = 20
+	MaxInValues   = 100
+)
+
+// notOnDay is the operator of times not on a day, the start and end of which
+// are its value
+const notOnDay = "not_on_day"
+
+// dateLayout is the layout of date-only time values
+const dateLayout = "2006-01-02"
+
+// LikeEscape escapes the wildcards of like values; it is not special in any
+// of the supported databases, unlike the backslash in MySQL. Patterns from
+// ContainsPattern are matched with LIKE ? ESCAPE '!'.
+const LikeEscape = "!"
+
+// Type is the type of a filterable field, which its values are parsed as
+type Type int
+
+// Field types
+const (
+	String Type = iota
+	Int
+	Float
+	Bool
+	Time // RFC 3339 timestamp, or a date covering the whole day
+)
+
+// operators are the operators each type supports
+var operators = map[Type][]string{
+	String: {Eq, Ne, Like, In, Null},
+	Int:    {Eq, Ne, Gt, Gte, Lt, Lte, In, Null},
+	Float:  {Eq, Ne, Gt, Gte, Lt, Lte, In, Null},
+	Bool:   {Eq, Ne, Null},
+	Time:   {Eq, Ne, Gt, Gte, Lt, Lte, Null},
+}
+
+// Field is a filterable field of an entity
+type Field struct {
+	Column string   // database column; the name of the field when empty
+	Type   Type     // type values are parsed as
+	Ops    []string // operators allowed; every operator of the type when empty
+}
+
+// Fields whitelists the filterable fields of an entity by their name in the query
+type Fields map[string]Field
+
+// Condition is a parsed filter on a column
+type Condition struct {
+	Column string
+	Op     string
+	Value  interface{}
+}
+
+// Filter is a parsed filter expression; all its conditions must match
+type Filter []Condition
+
+// Parse parses the filter[field][op]=value parameters of values. Other
+// parameters are ignored. Fields not in fields, operators they do not allow
+// and values of the wrong type are rejected with a validation error.
+func Parse(values url.Values, fields Fields) (Filter, error) {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		if strings.HasPrefix(key, "filter[") {
+			keys = append(keys, key)
+		}
+	}
+	// Keep the order of conditions, and so the SQL, stable
+	sort.Strings(keys)
+
+	var filter Filter
+	for _, key := range keys {
+		name, op, ok := parseKey(key)
+		if !ok {
+			return nil, ErrInvalidFilter
+		}
+
+		field, ok := fields[name]
+		if !ok {
+			return nil, ErrUnknownFilterField
+		}
+		if !field.allows(op) {
+			return nil, ErrInvalidFilterOperator
+		}
+		column := field.Column
+		if column == "" {
+			column = name
+		}
+
+		for _, raw := range values[key] {
+			conditions, err := parseCondition(column, field.Type, op, raw)
+			if err != nil {
+				return nil, err
+			}
+			filter = append(filter, conditions...)
+		}
+		if len(filter) > MaxConditions {
+			return nil, ErrInvalidFilter
+		}
+	}
+
+	return filter, nil
+}
+
+// Scope returns the GORM scope restricting a query to the rows matching the filter
+func (f Filter) Scope() func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		for _, c := range f {
+			db = db.Where(c.expression())
+		}
+		return db
+	}
+}
+
+// expression returns the SQL expression of the condition
+func (c Condition) expression() clause.Expression {
+	column := clause.Column{Table: clause.CurrentTable, Name: c.Column}
+	switch c.Op {
+	case Ne:
+		return clause.Neq{Column: column, Value: c.Value}
+	case Gt:
+		return clause.Gt{Column: column, Value: c.Value}
+	case Gte:
+		return clause.Gte{Column: column, Value: c.Value}
+	case Lt:
+		return clause.Lt{Column: column, Value: c.Value}
+	case Lte:
+		return clause.Lte{Column: column, Value: c.Value}
+	case Like:
+		return clause.Expr{SQL: "LOWER(?) LIKE ? ESCAPE '" + LikeEscape + "'", Vars: []interface{}{column, c.Value}}
+	case In:
+		return clause.IN{Column: column, Values: c.Value.([]interface{})}
+	case notOnDay:
+		day := c.Value.([2]time.Time)
+		return clause.Or(clause.Lt{Column: column, Value: day[0]}, clause.Gte{Column: column, Value: day[1]})
+	case Null:
+		if c.Value.(bool) {
+			return clause.Eq{Column: column, Value: nil}
+		}
+		return clause.Neq{Column: column, Value: nil}
+	default:
+		return clause.Eq{Column: column, Value: c.Value}
+	}
+}
+
+// allows reports whether the field may be filtered with op, which its type must support
+func (f Field) allows(op string) bool {
+	if !slices.Contains(operators[f.Type], op) {
+		return false
+	}
+	return len(f.Ops) == 0 || slices.Contains(f.Ops, op)
+}
+
+// parseKey splits filter[field] and filter[field][op] keys
+func parseKey(key string) (name, op string, ok bool) {
+	rest := strings.TrimPrefix(key, "filter[")
+	name, rest, ok = strings.Cut(rest, "]")
+	if !ok || name == "" {
+		return "", "", false
+	}
+	if rest == "" {
+		return name, Eq, true
+	}
+	if !strings.HasPrefix(rest, "[") || !strings.HasSuffix(rest, "]") {
+		return "", "", false
+	}
+	op = rest[1 : len(rest)-1]
+	if op == "" || strings.ContainsAny(op, "[]") {
+		return "", "", false
+	}
+	return name, op, true
+}
+
+// parseCondition parses the value of a filter on column into its conditions
+func parseCondition(column string, typ Type, op, raw string) ([]Condition, error) {
+	switch op {
+	case Null:
+		isNull, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, ErrInvalidFilterValue
+		}
+		return []Condition{{Column: column, Op: Null, Value: isNull}}, nil
+	case Like:
+		return []Condition{{Column: column, Op: Like, Value: ContainsPattern(raw)}}, nil
+	case In:
+		parts := strings.Split(raw, ",")
+		if len(parts) > MaxInValues {
+			return nil, ErrInvalidFilterValue
+		}
+		values := make([]interface{}, len(parts))
+		for i, part := range parts {
+			value, err := parseValue(typ, strings.TrimSpace(part))
+			if err != nil {
+				return nil, err
+			}
+			values[i] = value
+		}
+		return []Condition{{Column: column, Op: In, Value: values}}, nil
+	}
+
+	if typ == Time {
+		if day, err := time.Parse(dateLayout, raw); err == nil {
+			return dayConditions(column, op, day), nil
+		}
+	}
+
+	value, err := parseValue(typ, raw)
+	if err != nil {
+		return nil, err
+	}
+	return []Condition{{Column: column, Op: op, Value: value}}, nil
+}
+
+// dayConditions compares column to a whole day: a time is equal to a date
+// when it falls on that day, and greater than it when it falls after that day
+func dayConditions(column, op string, day time.Time) []Condition {
+	next := day.AddDate(0, 0, 1)
+	switch op {
+	case Eq:
+		return []Condition{{Column: column, Op: Gte, Value: day}, {Column: column, Op: Lt, Value: next}}
+	case Ne:
+		return []Condition{{Column: column, Op: notOnDay, Value: [2]time.Time{day, next}}}
+	case Gt:
+		return []Condition{{Column: column, Op: Gte, Value: next}}
+	case Lte:
+		return []Condition{{Column: column, Op: Lt, Value: next}}
+	default: // Gte, Lt
+		return []Condition{{Column: column, Op: op, Value: day}}
+	}
+}
+
+// parseValue parses a value of a field of typ
+func parseValue(typ Type, raw string) (interface{}, error) {
+	var (
+		value interface{}
+		err   error
+	)
+	switch typ {
+	case Int:
+		value, err = strconv.ParseInt(raw, 10, 64)
+	case Float:
+		value, err = strconv.ParseFloat(raw, 64)
+	case Bool:
+		value, err = strconv.ParseBool(raw)
+	case Time:
+		value, err = time.Parse(time.RFC3339, raw)
+	default:
+		value = raw
+	}
+	if err != nil {
+		return nil, ErrInvalidFilterValue
+	}
+	return value, nil
+}
+
+// ContainsPattern returns the like pattern matching lowercase values that
+// contain s, its wildcards escaped with LikeEscape
+func ContainsPattern(s string) string {
+	return "%" + escapeLike(strings.ToLower(s)) + "%"
+}
+
+// escapeLike escapes the wildcards of a like value
+func escapeLike(s string) string {
+	return strings.NewReplacer(LikeEscape, LikeEscape+LikeEscape, "%", LikeEscape+"%", "_", LikeEscape+"_").Replace(s)
+}
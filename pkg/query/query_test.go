@@ -0,0 +1,199 @@
+package query_test
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/firdanbash/go-clean-boiler/pkg/query"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// fields are the filterable fields of the entity under test
+var fields = query.Fields{
+	"name":       {Type: query.String},
+	"email":      {Type: query.String, Ops: []string{query.Eq}},
+	"age":        {Column: "age_years", Type: query.Int},
+	"score":      {Type: query.Float},
+	"active":     {Type: query.Bool},
+	"created_at": {Type: query.Time},
+}
+
+// parse parses a raw query string with fields
+func parse(t *testing.T, rawQuery string) (query.Filter, error) {
+	t.Helper()
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		t.Fatalf("ParseQuery(%q) error = %v", rawQuery, err)
+	}
+	return query.Parse(values, fields)
+}
+
+// whereSQL returns the WHERE clause and variables a filter adds to a query
+func whereSQL(t *testing.T, filter query.Filter) (string, []interface{}) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{DryRun: true, Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+	stmt := db.Table("items").Scopes(filter.Scope()).Find(&[]map[string]interface{}{}).Statement
+	_, where, _ := strings.Cut(stmt.SQL.String(), " WHERE ")
+	return where, stmt.Vars
+}
+
+func TestParseOperators(t *testing.T) {
+	day := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	next := day.AddDate(0, 0, 1)
+	at := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		query    string
+		wantSQL  string
+		wantVars []interface{}
+	}{
+		{"eq without operator", "filter[name]=jo", "`items`.`name` = ?", []interface{}{"jo"}},
+		{"eq", "filter[name][eq]=jo", "`items`.`name` = ?", []interface{}{"jo"}},
+		{"ne", "filter[name][ne]=jo", "`items`.`name` <> ?", []interface{}{"jo"}},
+		{"gt on the column of the field", "filter[age][gt]=30", "`items`.`age_years` > ?", []interface{}{int64(30)}},
+		{"gte", "filter[age][gte]=30", "`items`.`age_years` >= ?", []interface{}{int64(30)}},
+		{"lt", "filter[score][lt]=1.5", "`items`.`score` < ?", []interface{}{1.5}},
+		{"lte", "filter[score][lte]=1.5", "`items`.`score` <= ?", []interface{}{1.5}},
+		{"like", "filter[name][like]=Jo", "LOWER(`items`.`name`) LIKE ? ESCAPE '!'", []interface{}{"%jo%"}},
+		{"in", "filter[age][in]=1, 2,3", "`items`.`age_years` IN (?,?,?)", []interface{}{int64(1), int64(2), int64(3)}},
+		{"null", "filter[name][null]=true", "`items`.`name` IS NULL", nil},
+		{"not null", "filter[name][null]=false", "`items`.`name` IS NOT NULL", nil},
+		{"bool", "filter[active]=true", "`items`.`active` = ?", []interface{}{true}},
+		{"timestamp", "filter[created_at][gt]=2024-01-02T15:04:05Z", "`items`.`created_at` > ?", []interface{}{at}},
+		{"date eq covers the day", "filter[created_at]=2024-01-02", "`items`.`created_at` >= ? AND `items`.`created_at` < ?", []interface{}{day, next}},
+		{"date ne excludes the day", "filter[created_at][ne]=2024-01-02", "(`items`.`created_at` < ? OR `items`.`created_at` >= ?)", []interface{}{day, next}},
+		{"date gt starts the next day", "filter[created_at][gt]=2024-01-02", "`items`.`created_at` >= ?", []interface{}{next}},
+		{"date lte ends with the day", "filter[created_at][lte]=2024-01-02", "`items`.`created_at` < ?", []interface{}{next}},
+		{"conditions in key order", "filter[name]=jo&filter[age][gte]=30", "`items`.`age_years` >= ? AND `items`.`name` = ?", []interface{}{int64(30), "jo"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := parse(t, tt.query)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			sql, vars := whereSQL(t, filter)
+			if sql != tt.wantSQL {
+				t.Errorf("SQL = %s, want %s", sql, tt.wantSQL)
+			}
+			if len(vars) != 0 || len(tt.wantVars) != 0 {
+				if !reflect.DeepEqual(vars, tt.wantVars) {
+					t.Errorf("vars = %v, want %v", vars, tt.wantVars)
+				}
+			}
+		})
+	}
+}
+
+func TestParseEscapesLikeWildcards(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"50%", "%50!%%"},
+		{"a_b", "%a!_b%"},
+		{"wow!", "%wow!!%"},
+		{"!%_", "%!!!%!_%"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			filter, err := query.Parse(url.Values{"filter[name][like]": {tt.value}}, fields)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got := filter[0].Value; got != tt.want {
+				t.Errorf("pattern = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		wantErr error
+	}{
+		{"field not whitelisted", "filter[password]=secret", query.ErrUnknownFilterField},
+		{"column instead of field name", "filter[age_years]=3", query.ErrUnknownFilterField},
+		{"operator not allowed for the field", "filter[email][like]=jo", query.ErrInvalidFilterOperator},
+		{"operator not supported by the type", "filter[active][gt]=true", query.ErrInvalidFilterOperator},
+		{"unknown operator", "filter[name][regex]=jo", query.ErrInvalidFilterOperator},
+		{"missing field", "filter[]=jo", query.ErrInvalidFilter},
+		{"unclosed key", "filter[name=jo", query.ErrInvalidFilter},
+		{"unclosed operator", "filter[name][eq=jo", query.ErrInvalidFilter},
+		{"nested operator", "filter[name][eq][x]=jo", query.ErrInvalidFilter},
+		{"bad number", "filter[age]=ten", query.ErrInvalidFilterValue},
+		{"bad number in list", "filter[age][in]=1,two", query.ErrInvalidFilterValue},
+		{"bad float", "filter[score][gt]=high", query.ErrInvalidFilterValue},
+		{"bad bool", "filter[active]=yes", query.ErrInvalidFilterValue},
+		{"bad date", "filter[created_at]=2024-13-01", query.ErrInvalidFilterValue},
+		{"bad timestamp", "filter[created_at][gt]=2024-01-02 15:04", query.ErrInvalidFilterValue},
+		{"bad null", "filter[name][null]=maybe", query.ErrInvalidFilterValue},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parse(t, tt.query); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Parse() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseLimits(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  url.Values
+		wantErr error
+	}{
+		{"most conditions", url.Values{"filter[age][gte]": numbers(query.MaxConditions)}, nil},
+		{"too many conditions", url.Values{"filter[age][gte]": numbers(query.MaxConditions + 1)}, query.ErrInvalidFilter},
+		{"too many conditions across fields", url.Values{
+			"filter[age][gte]": numbers(query.MaxConditions),
+			"filter[name]":     {"jo"},
+		}, query.ErrInvalidFilter},
+		{"dates counting as two conditions", url.Values{"filter[created_at]": copies("2024-01-02", query.MaxConditions/2+1)}, query.ErrInvalidFilter},
+		{"most in values", url.Values{"filter[age][in]": {strings.Join(numbers(query.MaxInValues), ",")}}, nil},
+		{"too many in values", url.Values{"filter[age][in]": {strings.Join(numbers(query.MaxInValues+1), ",")}}, query.ErrInvalidFilterValue},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := query.Parse(tt.values, fields)
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Parse() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// numbers returns the numbers from 1 to n
+func numbers(n int) []string {
+	values := make([]string, n)
+	for i := range values {
+		values[i] = strconv.Itoa(i + 1)
+	}
+	return values
+}
+
+// copies returns n copies of value
+func copies(value string, n int) []string {
+	values := make([]string, n)
+	for i := range values {
+		values[i] = value
+	}
+	return values
+}
@@ -0,0 +1,83 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// memorySweepInterval is how often idle buckets are dropped from memory
+const memorySweepInterval = time.Minute
+
+type memoryEntry struct {
+	limiter  *rate.Limiter
+	window   time.Duration
+	lastSeen time.Time
+}
+
+type memoryLimiter struct {
+	mu        sync.Mutex
+	entries   map[string]*memoryEntry
+	lastSweep time.Time
+}
+
+// NewMemoryLimiter creates a token-bucket limiter held in process memory.
+// Limits are per instance, so it suits single-node deployments where Redis is not configured.
+func NewMemoryLimiter() Limiter {
+	return &memoryLimiter{
+		entries:   make(map[string]*memoryEntry),
+		lastSweep: time.Now(),
+	}
+}
+
+// Allow takes a token from key's bucket, which refills at Requests per Window
+func (l *memoryLimiter) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	now := time.Now()
+	every := limit.Window / time.Duration(limit.Requests)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	entry, ok := l.entries[key]
+	if !ok {
+		entry = &memoryEntry{
+			limiter: rate.NewLimiter(rate.Every(every), limit.Requests),
+			window:  limit.Window,
+		}
+		l.entries[key] = entry
+	}
+	entry.lastSeen = now
+
+	allowed := entry.limiter.AllowN(now, 1)
+	tokens := entry.limiter.TokensAt(now)
+
+	// Time until the bucket is full again
+	missing := float64(limit.Requests) - tokens
+	resetAfter := time.Duration(math.Ceil(missing * float64(every)))
+
+	return Result{
+		Allowed:    allowed,
+		Limit:      limit.Requests,
+		Remaining:  int(math.Max(0, math.Floor(tokens))),
+		ResetAfter: resetAfter,
+	}, nil
+}
+
+// sweep drops buckets that have been idle long enough to be full again
+func (l *memoryLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < memorySweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	for key, entry := range l.entries {
+		if now.Sub(entry.lastSeen) > entry.window {
+			delete(l.entries, key)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Limit is the number of requests allowed per window
+type Limit struct {
+	Requests int
+	Window   time.Duration
+}
+
+// Result describes the state of a rate limit after a request was counted
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// ResetAfter is the time until the window frees up capacity again
+	ResetAfter time.Duration
+}
+
+// Limiter counts requests per key against a limit
+type Limiter interface {
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+}
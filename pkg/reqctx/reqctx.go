@@ -0,0 +1,80 @@
+package reqctx
+
+import "context"
+
+type contextKey int
+
+const (
+	userIDKey contextKey = iota
+	clientIPKey
+	userAgentKey
+	requestIDKey
+	tenantIDKey
+	impersonatorIDKey
+)
+
+// WithRequestID returns a copy of ctx carrying the request ID
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestID returns the request ID carried by ctx
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// WithUserID returns a copy of ctx carrying the authenticated user ID
+func WithUserID(ctx context.Context, userID uint) context.Context {
+	return context.WithValue(ctx, userIDKey, userID)
+}
+
+// UserID returns the authenticated user ID carried by ctx
+func UserID(ctx context.Context) (uint, bool) {
+	userID, ok := ctx.Value(userIDKey).(uint)
+	return userID, ok
+}
+
+// WithImpersonatorID returns a copy of ctx carrying the ID of the admin
+// impersonating the authenticated user
+func WithImpersonatorID(ctx context.Context, impersonatorID uint) context.Context {
+	return context.WithValue(ctx, impersonatorIDKey, impersonatorID)
+}
+
+// ImpersonatorID returns the ID of the admin impersonating the authenticated
+// user, when the request uses an impersonation token
+func ImpersonatorID(ctx context.Context) (uint, bool) {
+	impersonatorID, ok := ctx.Value(impersonatorIDKey).(uint)
+	return impersonatorID, ok
+}
+
+// WithClient returns a copy of ctx carrying the client IP and user agent
+func WithClient(ctx context.Context, ip, userAgent string) context.Context {
+	ctx = context.WithValue(ctx, clientIPKey, ip)
+	return context.WithValue(ctx, userAgentKey, userAgent)
+}
+
+// ClientIP returns the client IP carried by ctx
+func ClientIP(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPKey).(string)
+	return ip
+}
+
+// UserAgent returns the client user agent carried by ctx
+func UserAgent(ctx context.Context) string {
+	ua, _ := ctx.Value(userAgentKey).(string)
+	return ua
+}
+
+// WithTenantID returns a copy of ctx carrying the ID of the tenant a request
+// acts for
+func WithTenantID(ctx context.Context, tenantID uint) context.Context {
+	return context.WithValue(ctx, tenantIDKey, tenantID)
+}
+
+// TenantID returns the tenant ID carried by ctx. Without one, as in jobs and
+// commands, data of every tenant is reachable.
+func TenantID(ctx context.Context) (uint, bool) {
+	tenantID, ok := ctx.Value(tenantIDKey).(uint)
+	return tenantID, ok
+}
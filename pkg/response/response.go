@@ -1,17 +1,44 @@
 package response
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 
+	"github.com/firdanbash/go-clean-boiler/pkg/apperror"
+	"github.com/firdanbash/go-clean-boiler/pkg/i18n"
 	"github.com/gin-gonic/gin"
 )
 
-// Response is the standard API response structure
+// rawKey is the context key marking the requests answered without the envelope
+const rawKey = "response.raw"
+
+// Pagination headers. The total and the links to the other pages are sent
+// with every paginated response, the page, its size and the page count only
+// without the envelope.
+const (
+	TotalCountHeader = "X-Total-Count"
+	LinkHeader       = "Link"
+	PageHeader       = "X-Page"
+	PerPageHeader    = "X-Per-Page"
+	TotalPagesHeader = "X-Total-Pages"
+)
+
+// PaginationHeaders lists the pagination headers, e.g. for caches to keep them
+var PaginationHeaders = []string{TotalCountHeader, LinkHeader, PageHeader, PerPageHeader, TotalPagesHeader}
+
+// Response is the standard API response structure. Error responses carry a
+// machine-readable code from the catalogue of pkg/apperror.
 type Response struct {
-	Success bool        `json:"success"`
-	Message string      `json:"message"`
-	Data    interface{} `json:"data,omitempty"`
-	Error   interface{} `json:"error,omitempty"`
+	Success bool          `json:"success"`
+	Code    apperror.Code `json:"code,omitempty"`
+	Message string        `json:"message"`
+	Data    interface{}   `json:"data,omitempty"`
+	Error   interface{}   `json:"error,omitempty"`
 }
 
 // PaginationMeta contains pagination metadata
@@ -32,70 +59,195 @@ type PaginatedResponse struct {
 
 // Success sends a successful response
 func Success(c *gin.Context, message string, data interface{}) {
-	c.JSON(http.StatusOK, Response{
-		Success: true,
-		Message: message,
-		Data:    data,
-	})
+	send(c, http.StatusOK, message, data)
 }
 
 // Created sends a created response
 func Created(c *gin.Context, message string, data interface{}) {
-	c.JSON(http.StatusCreated, Response{
-		Success: true,
-		Message: message,
-		Data:    data,
-	})
+	send(c, http.StatusCreated, message, data)
+}
+
+// Accepted sends an accepted response, for work that completes in the background
+func Accepted(c *gin.Context, message string, data interface{}) {
+	send(c, http.StatusAccepted, message, data)
 }
 
 // BadRequest sends a bad request error response
 func BadRequest(c *gin.Context, message string, err interface{}) {
-	c.JSON(http.StatusBadRequest, Response{
-		Success: false,
-		Message: message,
-		Error:   err,
-	})
+	fail(c, http.StatusBadRequest, apperror.CodeBadRequest, message, err)
 }
 
 // Unauthorized sends an unauthorized error response
 func Unauthorized(c *gin.Context, message string) {
-	c.JSON(http.StatusUnauthorized, Response{
-		Success: false,
-		Message: message,
-	})
+	fail(c, http.StatusUnauthorized, apperror.CodeUnauthorized, message, nil)
 }
 
 // Forbidden sends a forbidden error response
 func Forbidden(c *gin.Context, message string) {
-	c.JSON(http.StatusForbidden, Response{
-		Success: false,
-		Message: message,
-	})
+	fail(c, http.StatusForbidden, apperror.CodeForbidden, message, nil)
 }
 
 // NotFound sends a not found error response
 func NotFound(c *gin.Context, message string) {
-	c.JSON(http.StatusNotFound, Response{
-		Success: false,
-		Message: message,
-	})
+	fail(c, http.StatusNotFound, apperror.CodeNotFound, message, nil)
+}
+
+// Gone sends a gone error response
+func Gone(c *gin.Context, message string) {
+	fail(c, http.StatusGone, apperror.CodeGone, message, nil)
+}
+
+// RequestEntityTooLarge sends a request body too large error response
+func RequestEntityTooLarge(c *gin.Context, message string) {
+	fail(c, http.StatusRequestEntityTooLarge, apperror.CodePayloadTooLarge, message, nil)
+}
+
+// TooManyRequests sends a rate limit exceeded error response
+func TooManyRequests(c *gin.Context, message string) {
+	fail(c, http.StatusTooManyRequests, apperror.CodeTooManyRequests, message, nil)
 }
 
 // InternalServerError sends an internal server error response
 func InternalServerError(c *gin.Context, message string, err interface{}) {
-	c.JSON(http.StatusInternalServerError, Response{
+	fail(c, http.StatusInternalServerError, apperror.CodeInternal, message, err)
+}
+
+// GatewayTimeout sends a request timed out error response
+func GatewayTimeout(c *gin.Context, message string) {
+	fail(c, http.StatusGatewayTimeout, apperror.CodeTimeout, message, nil)
+}
+
+// Error sends the response for err. Errors from pkg/apperror get the status of
+// their kind and their message; work cut short by the request deadline is a
+// 504; any other error is a 500 with fallback as the message.
+func Error(c *gin.Context, err error, fallback string) {
+	if errors.Is(err, context.DeadlineExceeded) {
+		GatewayTimeout(c, "Request timed out")
+		return
+	}
+
+	status := apperror.HTTPStatus(err)
+	if status == http.StatusInternalServerError {
+		InternalServerError(c, fallback, err.Error())
+		return
+	}
+	render(c, status, Response{
+		Success: false,
+		Code:    apperror.CodeOf(err),
+		Message: i18n.FromContext(requestContext(c)).Error(err),
+	})
+}
+
+// ValidationFailed sends the bad request response of input failing its
+// validation, errs listing the invalid fields
+func ValidationFailed(c *gin.Context, errs interface{}) {
+	fail(c, http.StatusBadRequest, apperror.CodeValidationFailed, "Validation failed", errs)
+}
+
+// fail sends an error response with status and code
+func fail(c *gin.Context, status int, code apperror.Code, message string, err interface{}) {
+	render(c, status, Response{
 		Success: false,
-		Message: message,
+		Code:    code,
+		Message: translate(c, message),
 		Error:   err,
 	})
 }
 
-// Paginated sends a paginated response
+// translate returns message in the locale negotiated for the request
+func translate(c *gin.Context, message string) string {
+	return i18n.T(requestContext(c), message)
+}
+
+func requestContext(c *gin.Context) context.Context {
+	if c.Request == nil {
+		return context.Background()
+	}
+	return c.Request.Context()
+}
+
+// Paginated sends a paginated response, with the total in X-Total-Count and
+// the first, previous, next and last pages linked in the Link header (RFC
+// 8288). Without the envelope the items are sent as is and the rest of the
+// pagination in the pagination headers.
 func Paginated(c *gin.Context, message string, data interface{}, pagination PaginationMeta) {
-	c.JSON(http.StatusOK, PaginatedResponse{
+	c.Header(TotalCountHeader, strconv.FormatInt(pagination.Total, 10))
+	if links := pageLinks(c, pagination); links != "" {
+		// Added, as other middleware may link the response too
+		c.Writer.Header().Add(LinkHeader, links)
+	}
+	if raw(c) {
+		c.Header(PageHeader, strconv.Itoa(pagination.CurrentPage))
+		c.Header(PerPageHeader, strconv.Itoa(pagination.PerPage))
+		c.Header(TotalPagesHeader, strconv.Itoa(pagination.TotalPages))
+		render(c, http.StatusOK, data)
+		return
+	}
+	render(c, http.StatusOK, PaginatedResponse{
 		Success:    true,
-		Message:    message,
+		Message:    translate(c, message),
 		Data:       data,
 		Pagination: pagination,
 	})
 }
+
+// SetEnvelope tells whether the successful responses to the request are
+// wrapped in the standard envelope. Without it resources and lists are sent
+// bare, for generic REST tooling, and a 200 without data becomes a 204.
+// Errors keep their body, which carries the message.
+func SetEnvelope(c *gin.Context, enabled bool) {
+	c.Set(rawKey, !enabled)
+}
+
+// raw reports whether the request is answered without the envelope
+func raw(c *gin.Context) bool {
+	return c.GetBool(rawKey)
+}
+
+// send sends a successful response with status, in the envelope unless the
+// request is answered without it
+func send(c *gin.Context, status int, message string, data interface{}) {
+	if !raw(c) {
+		render(c, status, Response{
+			Success: true,
+			Message: translate(c, message),
+			Data:    data,
+		})
+		return
+	}
+	if data == nil {
+		if status == http.StatusOK {
+			status = http.StatusNoContent
+		}
+		c.Status(status)
+		return
+	}
+	render(c, status, data)
+}
+
+// pageLinks returns the Link header value linking the first, previous, next
+// and last pages of the request, which only differ from it by their page
+// parameter
+func pageLinks(c *gin.Context, pagination PaginationMeta) string {
+	if c.Request == nil || c.Request.URL == nil {
+		return ""
+	}
+	last := max(pagination.TotalPages, 1)
+
+	links := []string{pageLink(c.Request.URL, 1, "first")}
+	if pagination.CurrentPage > 1 {
+		links = append(links, pageLink(c.Request.URL, min(pagination.CurrentPage-1, last), "prev"))
+	}
+	if pagination.CurrentPage < last {
+		links = append(links, pageLink(c.Request.URL, pagination.CurrentPage+1, "next"))
+	}
+	links = append(links, pageLink(c.Request.URL, last, "last"))
+	return strings.Join(links, ", ")
+}
+
+// pageLink links page of the list at u with the relation rel
+func pageLink(u *url.URL, page int, rel string) string {
+	query := u.Query()
+	query.Set("page", strconv.Itoa(page))
+	return fmt.Sprintf(`<%s?%s>; rel="%s"`, u.Path, query.Encode(), rel)
+}
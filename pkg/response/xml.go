@@ -0,0 +1,153 @@
+package response
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// formatKey is the context key holding the format of the responses
+const formatKey = "response.format"
+
+// Formats responses are rendered in
+const (
+	FormatJSON = "json"
+	FormatXML  = "xml"
+)
+
+// xmlContentType is the Content-Type of XML responses
+const xmlContentType = "application/xml; charset=utf-8"
+
+// SetFormat sets the format the responses to the request are rendered in
+func SetFormat(c *gin.Context, format string) {
+	c.Set(formatKey, format)
+}
+
+// Format returns the format the responses to the request are rendered in,
+// FormatJSON unless set otherwise
+func Format(c *gin.Context) string {
+	if format := c.GetString(formatKey); format != "" {
+		return format
+	}
+	return FormatJSON
+}
+
+// render sends obj with status in the format of the request
+func render(c *gin.Context, status int, obj interface{}) {
+	if Format(c) == FormatXML {
+		c.Render(status, xmlDocument{value: obj})
+		return
+	}
+	c.JSON(status, obj)
+}
+
+// xmlDocument renders a value as XML shaped like its JSON, so that both
+// formats name fields alike: objects become elements named by their keys and
+// arrays repeated item elements, under a response root element
+type xmlDocument struct {
+	value interface{}
+}
+
+// Render writes the document to w
+func (d xmlDocument) Render(w http.ResponseWriter) error {
+	d.WriteContentType(w)
+
+	data, err := json.Marshal(d.value)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	// Keep large integers such as IDs exact
+	dec.UseNumber()
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	if err := writeXML(dec, enc, "response"); err != nil {
+		return err
+	}
+	return enc.Flush()
+}
+
+// WriteContentType sets the Content-Type of XML
+func (xmlDocument) WriteContentType(w http.ResponseWriter) {
+	header := w.Header()
+	if header.Get("Content-Type") == "" {
+		header.Set("Content-Type", xmlContentType)
+	}
+}
+
+// writeXML writes the next JSON value of dec to enc as the element name
+func writeXML(dec *json.Decoder, enc *xml.Encoder, name string) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	start := xmlElement(name)
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+	switch value := token.(type) {
+	case json.Delim:
+		for dec.More() {
+			child := "item"
+			if value == '{' {
+				key, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				child = key.(string)
+			}
+			if err := writeXML(dec, enc, child); err != nil {
+				return err
+			}
+		}
+		// The closing delimiter
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+	case nil:
+	default:
+		if err := enc.EncodeToken(xml.CharData(fmt.Sprint(value))); err != nil {
+			return err
+		}
+	}
+	return enc.EncodeToken(start.End())
+}
+
+// xmlElement returns the start of the element named name. Names that are not
+// valid XML names, such as map keys starting with a digit, are given as the
+// key attribute of an entry element.
+func xmlElement(name string) xml.StartElement {
+	if validXMLName(name) {
+		return xml.StartElement{Name: xml.Name{Local: name}}
+	}
+	return xml.StartElement{
+		Name: xml.Name{Local: "entry"},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "key"}, Value: name}},
+	}
+}
+
+// validXMLName reports whether name is a valid XML element name of ASCII
+// letters, digits, '_', '-' and '.', not starting with a digit, '-' or '.'
+func validXMLName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_':
+		case i > 0 && (r >= '0' && r <= '9' || r == '-' || r == '.'):
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,173 @@
+// Package scheduler runs recurring jobs on cron schedules. A run is skipped
+// while the previous run of the same job is still going, and every job
+// publishes its run counts and last result under the "jobs" expvar.
+package scheduler
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"github.com/firdanbash/go-clean-boiler/pkg/tracing"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+)
+
+// metrics holds the per-job counters served at /debug/vars
+var metrics = expvar.NewMap("jobs")
+
+// JobFunc is the work done by a job. The context is cancelled when the scheduler stops.
+type JobFunc func(ctx context.Context) error
+
+// Scheduler runs registered jobs on their schedules
+type Scheduler struct {
+	cron   *cron.Cron
+	ctx    context.Context
+	cancel context.CancelFunc
+	jobs   map[string]*job
+	log    logger.Logger
+}
+
+// New creates a scheduler. Schedules are standard five-field cron expressions or
+// descriptors such as @hourly and @every 10m, evaluated in the local time zone.
+func New(log logger.Logger) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		cron:   cron.New(),
+		ctx:    ctx,
+		cancel: cancel,
+		jobs:   make(map[string]*job),
+		log:    log,
+	}
+}
+
+// Add registers fn to run as name on schedule. Names must be unique.
+func (s *Scheduler) Add(name, schedule string, fn JobFunc) error {
+	if _, ok := s.jobs[name]; ok {
+		return fmt.Errorf("job %s is already registered", name)
+	}
+
+	j := &job{name: name, fn: fn, stats: jobMetrics(name), log: s.log}
+	if _, err := s.cron.AddFunc(schedule, func() { j.run(s.ctx) }); err != nil {
+		return fmt.Errorf("job %s: invalid schedule %q: %w", name, schedule, err)
+	}
+	s.jobs[name] = j
+
+	s.log.Info("Job scheduled", zap.String("job", name), zap.String("schedule", schedule))
+	return nil
+}
+
+// Len returns the number of registered jobs
+func (s *Scheduler) Len() int {
+	return len(s.jobs)
+}
+
+// Start begins running jobs in the background
+func (s *Scheduler) Start() {
+	s.cron.Start()
+}
+
+// Stop stops scheduling new runs, cancels the context of running jobs and waits
+// for them to return or for ctx to be done
+func (s *Scheduler) Stop(ctx context.Context) error {
+	done := s.cron.Stop()
+	s.cancel()
+
+	select {
+	case <-done.Done():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// job is a registered job and its run state
+type job struct {
+	name    string
+	fn      JobFunc
+	running atomic.Bool
+	stats   *expvar.Map
+	log     logger.Logger
+}
+
+// run executes the job unless its previous run is still going
+func (j *job) run(ctx context.Context) {
+	if !j.running.CompareAndSwap(false, true) {
+		j.stats.Add("skipped", 1)
+		j.log.Warn("Job skipped, previous run still in progress", zap.String("job", j.name))
+		return
+	}
+	defer j.running.Store(false)
+
+	ctx, span := tracing.Start(ctx, "job."+j.name)
+	defer span.End()
+
+	start := time.Now()
+	err := j.call(ctx)
+	duration := time.Since(start)
+
+	j.stats.Add("runs", 1)
+	j.stats.Set("last_run", timeVar(start))
+	j.stats.Set("last_duration_ms", intVar(duration.Milliseconds()))
+	if err != nil {
+		j.stats.Add("failures", 1)
+		j.stats.Set("last_error", stringVar(err.Error()))
+		j.log.Error("Job failed", zap.String("job", j.name), zap.Duration("duration", duration), zap.Error(err))
+		return
+	}
+	j.stats.Set("last_error", stringVar(""))
+	j.log.Info("Job finished", zap.String("job", j.name), zap.Duration("duration", duration))
+}
+
+// call runs the job function, turning a panic into an error
+func (j *job) call(ctx context.Context) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return j.fn(ctx)
+}
+
+// metricsMu guards creating the per-job metric maps
+var metricsMu sync.Mutex
+
+// jobMetrics returns the expvar map of a job, reusing it when the job was
+// registered before, for example by another scheduler in the same process
+func jobMetrics(name string) *expvar.Map {
+	metricsMu.Lock()
+	defer metricsMu.Unlock()
+
+	if m, ok := metrics.Get(name).(*expvar.Map); ok {
+		return m
+	}
+	m := new(expvar.Map).Init()
+	for _, key := range []string{"runs", "failures", "skipped"} {
+		m.Add(key, 0)
+	}
+	metrics.Set(name, m)
+	return m
+}
+
+func timeVar(t time.Time) expvar.Var {
+	s := new(expvar.String)
+	s.Set(t.UTC().Format(time.RFC3339))
+	return s
+}
+
+func intVar(n int64) expvar.Var {
+	i := new(expvar.Int)
+	i.Set(n)
+	return i
+}
+
+func stringVar(v string) expvar.Var {
+	s := new(expvar.String)
+	s.Set(v)
+	return s
+}
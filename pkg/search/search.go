@@ -0,0 +1,363 @@
+// Package search indexes documents in and searches Elasticsearch or
+// OpenSearch, through the part of the REST API both share, for deployments
+// that outgrow the full-text search of the database.
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/firdanbash/go-clean-boiler/pkg/config"
+)
+
+// maxErrorBody caps the bytes of an error response that are read
+const maxErrorBody = 64 << 10
+
+// Highlight tags wrapped around the matches in highlighted fragments
+const (
+	HighlightPreTag  = "<em>"
+	HighlightPostTag = "</em>"
+)
+
+// Error is an error response of the cluster
+type Error struct {
+	Status int
+	Type   string // e.g. index_not_found_exception
+	Reason string
+}
+
+func (e *Error) Error() string {
+	if e.Type == "" {
+		return fmt.Sprintf("search: status %d", e.Status)
+	}
+	return fmt.Sprintf("search: status %d: %s: %s", e.Status, e.Type, e.Reason)
+}
+
+// IsIndexNotFound reports whether err is the error of a missing index
+func IsIndexNotFound(err error) bool {
+	var e *Error
+	return errors.As(err, &e) && e.Type == "index_not_found_exception"
+}
+
+// Document is a document to index
+type Document struct {
+	ID     string
+	Source interface{} // marshaled to JSON
+}
+
+// Filter is a clause of the query DSL that documents must match, without
+// affecting their score
+type Filter map[string]interface{}
+
+// Term matches the documents whose field is exactly value
+func Term(field string, value interface{}) Filter {
+	return Filter{"term": map[string]interface{}{field: value}}
+}
+
+// Range matches the documents whose field is at least gte and less than lt;
+// a nil bound is open
+func Range(field string, gte, lt interface{}) Filter {
+	bounds := map[string]interface{}{}
+	if gte != nil {
+		bounds["gte"] = gte
+	}
+	if lt != nil {
+		bounds["lt"] = lt
+	}
+	return Filter{"range": map[string]interface{}{field: bounds}}
+}
+
+// Query is a search of an index
+type Query struct {
+	Text      string   // every word must match one of Fields, the last one as a prefix; every document when empty
+	Fields    []string // fields Text is matched against, with an optional boost, e.g. name^2
+	Filters   []Filter // all must match
+	Highlight []string // fields to return highlighted fragments of, HTML escaped
+	From      int
+	Size      int
+}
+
+// Hit is a document matching a query
+type Hit struct {
+	ID        string
+	Score     float64
+	Source    json.RawMessage
+	Highlight map[string][]string // fragments by field, matches wrapped in the highlight tags
+}
+
+// Result is the page of hits of a query
+type Result struct {
+	Total int64 // documents matching the query
+	Hits  []Hit
+}
+
+// Client calls an Elasticsearch or OpenSearch cluster
+type Client struct {
+	baseURL     string
+	http        *http.Client
+	username    string
+	password    string
+	apiKey      string
+	indexPrefix string
+}
+
+// New creates a client of the cluster at cfg.URL, calling it with httpClient
+func New(cfg config.SearchConfig, httpClient *http.Client) (*Client, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid search url %q", cfg.URL)
+	}
+	return &Client{
+		baseURL:     strings.TrimSuffix(cfg.URL, "/"),
+		http:        httpClient,
+		username:    cfg.Username,
+		password:    cfg.Password,
+		apiKey:      cfg.APIKey,
+		indexPrefix: cfg.IndexPrefix,
+	}, nil
+}
+
+// Ping checks that the cluster is reachable
+func (c *Client) Ping(ctx context.Context) error {
+	return c.do(ctx, http.MethodGet, "/", nil, "", nil)
+}
+
+// EnsureIndex creates index with definition, its settings and mappings,
+// unless it exists
+func (c *Client) EnsureIndex(ctx context.Context, index string, definition json.RawMessage) error {
+	err := c.do(ctx, http.MethodHead, "/"+c.index(index), nil, "", nil)
+	if err == nil {
+		return nil
+	}
+	var e *Error
+	if !errors.As(err, &e) || e.Status != http.StatusNotFound {
+		return err
+	}
+
+	err = c.do(ctx, http.MethodPut, "/"+c.index(index), definition, "application/json", nil)
+	// Another instance created it in the meantime
+	if errors.As(err, &e) && e.Type == "resource_already_exists_exception" {
+		return nil
+	}
+	return err
+}
+
+// DeleteIndex deletes index and its documents. A missing index is not an error.
+func (c *Client) DeleteIndex(ctx context.Context, index string) error {
+	err := c.do(ctx, http.MethodDelete, "/"+c.index(index), nil, "", nil)
+	if IsIndexNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// Put indexes doc under id, replacing the document indexed under it
+func (c *Client) Put(ctx context.Context, index, id string, doc interface{}) error {
+	body, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, http.MethodPut, "/"+c.index(index)+"/_doc/"+url.PathEscape(id), body, "application/json", nil)
+}
+
+// Delete removes the document indexed under id. A missing document is not an error.
+func (c *Client) Delete(ctx context.Context, index, id string) error {
+	err := c.do(ctx, http.MethodDelete, "/"+c.index(index)+"/_doc/"+url.PathEscape(id), nil, "", nil)
+	var e *Error
+	if errors.As(err, &e) && e.Status == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
+
+// Bulk indexes docs in one request
+func (c *Client) Bulk(ctx context.Context, index string, docs []Document) error {
+	if len(docs) == 0 {
+		return nil
+	}
+
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	for _, doc := range docs {
+		action := map[string]interface{}{"index": map[string]string{"_index": c.index(index), "_id": doc.ID}}
+		if err := enc.Encode(action); err != nil {
+			return err
+		}
+		if err := enc.Encode(doc.Source); err != nil {
+			return err
+		}
+	}
+
+	var res struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			ID    string    `json:"_id"`
+			Error *errorDoc `json:"error"`
+		} `json:"items"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/_bulk", body.Bytes(), "application/x-ndjson", &res); err != nil {
+		return err
+	}
+	if !res.Errors {
+		return nil
+	}
+
+	// Report the first failure, a bulk request succeeds even when its items fail
+	failed := 0
+	var first error
+	for _, item := range res.Items {
+		for _, result := range item {
+			if result.Error == nil {
+				continue
+			}
+			failed++
+			if first == nil {
+				first = fmt.Errorf("document %s: %s: %s", result.ID, result.Error.Type, result.Error.Reason)
+			}
+		}
+	}
+	return fmt.Errorf("search: %d of %d documents failed to index: %w", failed, len(docs), first)
+}
+
+// Search runs q against index
+func (c *Client) Search(ctx context.Context, index string, q Query) (*Result, error) {
+	body, err := json.Marshal(q.body())
+	if err != nil {
+		return nil, err
+	}
+
+	var res struct {
+		Hits struct {
+			Total struct {
+				Value int64 `json:"value"`
+			} `json:"total"`
+			Hits []struct {
+				ID        string              `json:"_id"`
+				Score     float64             `json:"_score"`
+				Source    json.RawMessage     `json:"_source"`
+				Highlight map[string][]string `json:"highlight"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/"+c.index(index)+"/_search", body, "application/json", &res); err != nil {
+		return nil, err
+	}
+
+	result := &Result{Total: res.Hits.Total.Value, Hits: make([]Hit, len(res.Hits.Hits))}
+	for i, hit := range res.Hits.Hits {
+		result.Hits[i] = Hit{ID: hit.ID, Score: hit.Score, Source: hit.Source, Highlight: hit.Highlight}
+	}
+	return result, nil
+}
+
+// body builds the request body of the query
+func (q Query) body() map[string]interface{} {
+	must := []interface{}{map[string]interface{}{"match_all": map[string]interface{}{}}}
+	if strings.TrimSpace(q.Text) != "" {
+		must = []interface{}{map[string]interface{}{
+			"multi_match": map[string]interface{}{
+				"query":    q.Text,
+				"fields":   q.Fields,
+				"type":     "bool_prefix",
+				"operator": "and",
+			},
+		}}
+	}
+
+	// An empty filter, not null, when there are none
+	filters := append([]Filter{}, q.Filters...)
+	body := map[string]interface{}{
+		"query":            map[string]interface{}{"bool": map[string]interface{}{"must": must, "filter": filters}},
+		"from":             q.From,
+		"size":             q.Size,
+		"track_total_hits": true,
+		// Ties, and every hit of a query without text, keep the indexing order
+		"sort": []interface{}{"_score", map[string]string{"_doc": "asc"}},
+	}
+
+	if len(q.Highlight) > 0 {
+		fields := make(map[string]interface{}, len(q.Highlight))
+		for _, field := range q.Highlight {
+			fields[field] = map[string]interface{}{}
+		}
+		body["highlight"] = map[string]interface{}{
+			"fields":    fields,
+			"pre_tags":  []string{HighlightPreTag},
+			"post_tags": []string{HighlightPostTag},
+			// The fragments are returned as HTML, so the indexed text is escaped
+			"encoder": "html",
+		}
+	}
+	return body
+}
+
+// index returns the name of index in the cluster
+func (c *Client) index(index string) string {
+	return c.indexPrefix + index
+}
+
+// errorDoc is the error of an error response or of a bulk item
+type errorDoc struct {
+	Type   string `json:"type"`
+	Reason string `json:"reason"`
+}
+
+// do sends a request with body of contentType to path and decodes the JSON
+// response into out, when not nil. Error statuses are returned as *Error.
+func (c *Client) do(ctx context.Context, method, path string, body []byte, contentType string, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Accept", "application/json")
+	switch {
+	case c.apiKey != "":
+		req.Header.Set("Authorization", "ApiKey "+c.apiKey)
+	case c.username != "":
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		e := &Error{Status: resp.StatusCode}
+		var res struct {
+			Error json.RawMessage `json:"error"`
+		}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		if json.Unmarshal(data, &res) == nil && len(res.Error) > 0 {
+			// The error is an object, or a string in some responses
+			var doc errorDoc
+			if json.Unmarshal(res.Error, &doc) == nil {
+				e.Type, e.Reason = doc.Type, doc.Reason
+			} else {
+				_ = json.Unmarshal(res.Error, &e.Reason)
+			}
+		}
+		return e
+	}
+
+	if out == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,139 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/firdanbash/go-clean-boiler/pkg/config"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// Server runs the API over plain HTTP or HTTPS. With TLS enabled it can also run a
+// plain HTTP listener that redirects to HTTPS and answers ACME HTTP-01 challenges.
+type Server struct {
+	srv      *http.Server
+	redirect *http.Server
+	tls      config.TLSConfig
+}
+
+// New creates a server for handler listening on port, configured from cfg
+func New(handler http.Handler, port string, cfg config.ServerConfig) (*Server, error) {
+	s := &Server{
+		srv: &http.Server{
+			Addr:         ":" + port,
+			Handler:      handler,
+			ReadTimeout:  cfg.ReadTimeout,
+			WriteTimeout: cfg.WriteTimeout,
+			IdleTimeout:  cfg.IdleTimeout,
+		},
+		tls: cfg.TLS,
+	}
+	if !cfg.TLS.Enabled {
+		return s, nil
+	}
+
+	var redirect http.Handler = redirectHandler(port)
+	if cfg.TLS.Autocert.Enabled {
+		if len(cfg.TLS.Autocert.Hosts) == 0 {
+			return nil, errors.New("server.tls.autocert.hosts is required when autocert is enabled")
+		}
+		manager := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(cfg.TLS.Autocert.Hosts...),
+			Cache:      autocert.DirCache(cfg.TLS.Autocert.CacheDir),
+			Email:      cfg.TLS.Autocert.Email,
+		}
+		s.srv.TLSConfig = manager.TLSConfig()
+		// HTTP-01 challenges must be answered on port 80 even without redirects
+		redirect = manager.HTTPHandler(redirect)
+	} else {
+		if cfg.TLS.CertFile == "" || cfg.TLS.KeyFile == "" {
+			return nil, errors.New("server.tls.cert_file and server.tls.key_file are required when TLS is enabled")
+		}
+		s.srv.TLSConfig = &tls.Config{}
+	}
+	s.srv.TLSConfig.MinVersion = tls.VersionTLS12
+
+	if cfg.TLS.RedirectHTTP || cfg.TLS.Autocert.Enabled {
+		s.redirect = &http.Server{
+			Addr:              ":" + cfg.TLS.HTTPPort,
+			Handler:           redirect,
+			ReadHeaderTimeout: 10 * time.Second,
+			IdleTimeout:       cfg.IdleTimeout,
+		}
+	}
+	return s, nil
+}
+
+// Addr returns the address the API listens on
+func (s *Server) Addr() string {
+	return s.srv.Addr
+}
+
+// TLS reports whether the API is served over HTTPS
+func (s *Server) TLS() bool {
+	return s.tls.Enabled
+}
+
+// ListenAndServe serves until the server is shut down or a listener fails.
+// It returns http.ErrServerClosed after Shutdown.
+func (s *Server) ListenAndServe() error {
+	errs := make(chan error, 2)
+
+	if s.redirect != nil {
+		go func() {
+			if err := s.redirect.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				errs <- fmt.Errorf("http redirect listener: %w", err)
+			}
+		}()
+	}
+
+	go func() {
+		switch {
+		case !s.tls.Enabled:
+			errs <- s.srv.ListenAndServe()
+		case s.tls.Autocert.Enabled:
+			// Certificates come from the autocert manager through TLSConfig
+			errs <- s.srv.ListenAndServeTLS("", "")
+		default:
+			errs <- s.srv.ListenAndServeTLS(s.tls.CertFile, s.tls.KeyFile)
+		}
+	}()
+
+	return <-errs
+}
+
+// Shutdown gracefully stops the API and redirect listeners
+func (s *Server) Shutdown(ctx context.Context) error {
+	var redirectErr error
+	if s.redirect != nil {
+		redirectErr = s.redirect.Shutdown(ctx)
+	}
+	return errors.Join(s.srv.Shutdown(ctx), redirectErr)
+}
+
+// redirectHandler sends plain HTTP requests to the same URL over HTTPS on httpsPort
+func redirectHandler(httpsPort string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if httpsPort != "443" {
+			host = net.JoinHostPort(host, httpsPort)
+		}
+
+		target := "https://" + host + r.URL.RequestURI()
+		// Only GET and HEAD are safe to redirect with 301; others keep their method and body
+		code := http.StatusMovedPermanently
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			code = http.StatusPermanentRedirect
+		}
+		http.Redirect(w, r, target, code)
+	})
+}
@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/firdanbash/go-clean-boiler/pkg/config"
+)
+
+// ErrNotFound is returned when an object does not exist
+var ErrNotFound = errors.New("object not found")
+
+// Storage defines the interface for file storage backends
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	URL(key string) string
+}
+
+// ObjectInfo describes a stored object
+type ObjectInfo struct {
+	Size        int64
+	ContentType string
+}
+
+// Presigner is implemented by backends that let clients transfer objects
+// directly with short-lived signed URLs, so the content never passes through
+// the API
+type Presigner interface {
+	// PresignPut returns a URL to upload the object with a PUT request. The
+	// request must send the given Content-Type and Content-Length headers.
+	PresignPut(ctx context.Context, key, contentType string, size int64, expiry time.Duration) (string, error)
+	// PresignGet returns a URL to download the object as an attachment named filename
+	PresignGet(ctx context.Context, key, filename string, expiry time.Duration) (string, error)
+	// Stat describes an object, returning ErrNotFound when it does not exist
+	Stat(ctx context.Context, key string) (*ObjectInfo, error)
+}
+
+// New creates a storage backend for the configured driver
+func New(cfg config.StorageConfig) (Storage, error) {
+	switch cfg.Driver {
+	case "", "local":
+		return NewLocalStorage(cfg.Local.Path, cfg.Local.BaseURL)
+	case "s3":
+		return NewS3Storage(cfg.S3)
+	default:
+		return nil, fmt.Errorf("unsupported storage driver: %s", cfg.Driver)
+	}
+}
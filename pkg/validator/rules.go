@@ -0,0 +1,124 @@
+package validator
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Custom validation tags
+const (
+	TagPassword          = "password"
+	TagPhone             = "phone"
+	TagUsername          = "username"
+	TagUsernameAvailable = "username_available"
+)
+
+// usernamePattern allows 3 to 32 letters, digits, dots, underscores and
+// hyphens, starting with a letter or digit
+var usernamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]{2,31}$`)
+
+// PasswordPolicy is the complexity a password tagged with "password" must meet
+type PasswordPolicy struct {
+	MinLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+}
+
+// DefaultPasswordPolicy is used until SetPasswordPolicy is called
+var DefaultPasswordPolicy = PasswordPolicy{MinLength: 8, RequireLower: true, RequireDigit: true}
+
+// UsernameTakenFunc reports whether a username is already in use
+type UsernameTakenFunc func(ctx context.Context, username string) (bool, error)
+
+var (
+	rulesMu        sync.RWMutex
+	passwordPolicy = DefaultPasswordPolicy
+	usernameTaken  UsernameTakenFunc
+)
+
+// SetPasswordPolicy sets the complexity required by the "password" tag
+func SetPasswordPolicy(p PasswordPolicy) {
+	rulesMu.Lock()
+	defer rulesMu.Unlock()
+	passwordPolicy = p
+}
+
+// CurrentPasswordPolicy returns the complexity required by the "password" tag
+func CurrentPasswordPolicy() PasswordPolicy {
+	rulesMu.RLock()
+	defer rulesMu.RUnlock()
+	return passwordPolicy
+}
+
+// SetUsernameTakenFunc sets the lookup used by the "username_available" tag.
+// Without one the tag accepts every username.
+func SetUsernameTakenFunc(fn UsernameTakenFunc) {
+	rulesMu.Lock()
+	defer rulesMu.Unlock()
+	usernameTaken = fn
+}
+
+// Check reports whether password meets the policy
+func (p PasswordPolicy) Check(password string) bool {
+	if len([]rune(password)) < p.MinLength {
+		return false
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			symbol = true
+		}
+	}
+	return (upper || !p.RequireUpper) &&
+		(lower || !p.RequireLower) &&
+		(digit || !p.RequireDigit) &&
+		(symbol || !p.RequireSymbol)
+}
+
+// registerRules adds the custom tags to v
+func registerRules(v *validator.Validate) {
+	must(v.RegisterValidation(TagPassword, func(fl validator.FieldLevel) bool {
+		return CurrentPasswordPolicy().Check(fl.Field().String())
+	}))
+
+	// Phone numbers are stored in E.164 form, e.g. +6281234567890
+	v.RegisterAlias(TagPhone, "e164")
+
+	must(v.RegisterValidation(TagUsername, func(fl validator.FieldLevel) bool {
+		return usernamePattern.MatchString(fl.Field().String())
+	}))
+
+	// The lookup runs last in a tag list, so write it after "username". A failed
+	// lookup accepts the value; a unique index still guards the column.
+	must(v.RegisterValidationCtx(TagUsernameAvailable, func(ctx context.Context, fl validator.FieldLevel) bool {
+		rulesMu.RLock()
+		taken := usernameTaken
+		rulesMu.RUnlock()
+		if taken == nil {
+			return true
+		}
+		inUse, err := taken(ctx, strings.TrimSpace(fl.Field().String()))
+		return err != nil || !inUse
+	}))
+}
+
+func must(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,146 @@
+package validator
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/firdanbash/go-clean-boiler/pkg/i18n"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/id"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
+	id_translations "github.com/go-playground/validator/v10/translations/id"
+)
+
+// uni holds a translator per supported locale, falling back to English
+var uni = ut.New(en.New(), en.New(), id.New())
+
+// customMessages are the messages of the custom tags and the pieces of the
+// password policy description, per locale. {0} is the field name.
+var customMessages = map[string]map[string]string{
+	"en": {
+		"invalid":            "{0} is invalid",
+		TagPassword:          "{0} must be at least {1} characters long",
+		"password_contains":  "{0} must be at least {1} characters long and contain {2}",
+		"password_lower":     "a lowercase letter",
+		"password_upper":     "an uppercase letter",
+		"password_digit":     "a digit",
+		"password_symbol":    "a symbol",
+		TagPhone:             "{0} must be a phone number in international format, e.g. +6281234567890",
+		TagUsername:          "{0} must be 3 to 32 letters, digits, dots, underscores or hyphens, starting with a letter or digit",
+		TagUsernameAvailable: "{0} is already taken",
+	},
+	"id": {
+		"invalid":            "{0} tidak valid",
+		TagPassword:          "{0} minimal {1} karakter",
+		"password_contains":  "{0} minimal {1} karakter dan mengandung {2}",
+		"password_lower":     "huruf kecil",
+		"password_upper":     "huruf besar",
+		"password_digit":     "angka",
+		"password_symbol":    "simbol",
+		TagPhone:             "{0} harus berupa nomor telepon dalam format internasional, mis. +6281234567890",
+		TagUsername:          "{0} harus 3 sampai 32 huruf, angka, titik, garis bawah atau tanda hubung, diawali huruf atau angka",
+		TagUsernameAvailable: "{0} sudah digunakan",
+	},
+}
+
+// registerTranslations reports fields by their JSON (or query) name and adds
+// the built-in and custom messages of every supported locale to v
+func registerTranslations(v *validator.Validate) {
+	v.RegisterTagNameFunc(fieldName)
+
+	defaults := map[string]func(*validator.Validate, ut.Translator) error{
+		"en": en_translations.RegisterDefaultTranslations,
+		"id": id_translations.RegisterDefaultTranslations,
+	}
+	for locale, register := range defaults {
+		trans, _ := uni.GetTranslator(locale)
+		must(register(v, trans))
+
+		for key, text := range customMessages[locale] {
+			must(trans.Add(key, text, true))
+		}
+		for _, tag := range []string{TagPhone, TagUsername, TagUsernameAvailable} {
+			must(v.RegisterTranslation(tag, trans, noopRegister, translateTag))
+		}
+		must(v.RegisterTranslation(TagPassword, trans, noopRegister, translatePassword))
+	}
+}
+
+// fieldName is the name a field is reported under: its json tag, else its
+// form, uri or header tag, else the Go field name
+func fieldName(f reflect.StructField) string {
+	for _, key := range []string{"json", "form", "uri", "header"} {
+		name := strings.SplitN(f.Tag.Get(key), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return f.Name
+}
+
+func noopRegister(ut.Translator) error { return nil }
+
+// translateTag translates the message registered under the tag of fe
+func translateTag(trans ut.Translator, fe validator.FieldError) string {
+	msg, err := trans.T(fe.Tag(), fe.Field())
+	if err != nil {
+		return fe.Error()
+	}
+	return msg
+}
+
+// translatePassword describes what the current password policy requires
+func translatePassword(trans ut.Translator, fe validator.FieldError) string {
+	p := CurrentPasswordPolicy()
+
+	var required []string
+	for _, piece := range []struct {
+		key string
+		on  bool
+	}{
+		{"password_lower", p.RequireLower},
+		{"password_upper", p.RequireUpper},
+		{"password_digit", p.RequireDigit},
+		{"password_symbol", p.RequireSymbol},
+	} {
+		if piece.on {
+			text, _ := trans.T(piece.key)
+			required = append(required, text)
+		}
+	}
+
+	minLength := strconv.Itoa(p.MinLength)
+	if len(required) == 0 {
+		msg, _ := trans.T(TagPassword, fe.Field(), minLength)
+		return msg
+	}
+	msg, _ := trans.T("password_contains", fe.Field(), minLength, strings.Join(required, ", "))
+	return msg
+}
+
+// translator returns the translator for the locale of ctx
+func translator(ctx context.Context) ut.Translator {
+	locale := i18n.FromContext(ctx).Locale()
+	if base, _, found := strings.Cut(locale, "-"); found {
+		locale = base
+	}
+	trans, _ := uni.GetTranslator(locale)
+	return trans
+}
+
+// translate returns the message of fe in the language of trans
+func translate(trans ut.Translator, fe validator.FieldError) string {
+	msg := fe.Translate(trans)
+	if msg == fe.Error() {
+		// No message is registered for the tag
+		msg, _ = trans.T("invalid", fe.Field())
+	}
+	return msg
+}
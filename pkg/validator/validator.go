@@ -1,6 +1,8 @@
 package validator
 
 import (
+	"context"
+
 	"github.com/firdanbash/go-clean-boiler/pkg/response"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -10,6 +12,8 @@ var validate *validator.Validate
 
 func init() {
 	validate = validator.New()
+	registerRules(validate)
+	registerTranslations(validate)
 }
 
 // ValidateStruct validates a struct and returns validation errors
@@ -17,48 +21,84 @@ func ValidateStruct(s interface{}) error {
 	return validate.Struct(s)
 }
 
-// BindAndValidate binds request body and validates it
+// ValidateStructCtx validates a struct, passing ctx to validations that look
+// up data such as "username_available"
+func ValidateStructCtx(ctx context.Context, s interface{}) error {
+	return validate.StructCtx(ctx, s)
+}
+
+// BindAndValidate binds request body and validates it. The body is read in
+// the format of its Content-Type: JSON, XML, or a URL encoded or multipart
+// form, fields named by their json, xml and form tags. A body without a
+// Content-Type is read as JSON.
 func BindAndValidate(c *gin.Context, obj interface{}) bool {
-	if err := c.ShouldBindJSON(obj); err != nil {
+	if err := bindBody(c, obj); err != nil {
 		response.BadRequest(c, "Invalid request body", err.Error())
 		return false
 	}
+	return validateRequest(c, obj)
+}
 
-	if err := ValidateStruct(obj); err != nil {
-		validationErrors := FormatValidationErrors(err)
-		response.BadRequest(c, "Validation failed", validationErrors)
+// bindBody binds the request body in the format of its Content-Type
+func bindBody(c *gin.Context, obj interface{}) error {
+	if c.ContentType() == "" {
+		return c.ShouldBindJSON(obj)
+	}
+	return c.ShouldBind(obj)
+}
+
+// BindQueryAndValidate binds the query parameters, named by form tags, and
+// validates them
+func BindQueryAndValidate(c *gin.Context, obj interface{}) bool {
+	if err := c.ShouldBindQuery(obj); err != nil {
+		response.BadRequest(c, "Invalid query parameters", err.Error())
 		return false
 	}
+	return validateRequest(c, obj)
+}
 
+// BindUriAndValidate binds the path parameters, named by uri tags, and
+// validates them
+func BindUriAndValidate(c *gin.Context, obj interface{}) bool {
+	if err := c.ShouldBindUri(obj); err != nil {
+		response.BadRequest(c, "Invalid path parameters", err.Error())
+		return false
+	}
+	return validateRequest(c, obj)
+}
+
+// BindHeaderAndValidate binds the request headers, named by header tags, and
+// validates them
+func BindHeaderAndValidate(c *gin.Context, obj interface{}) bool {
+	if err := c.ShouldBindHeader(obj); err != nil {
+		response.BadRequest(c, "Invalid request headers", err.Error())
+		return false
+	}
+	return validateRequest(c, obj)
+}
+
+// validateRequest validates a bound request, answering with the formatted
+// errors when it is invalid
+func validateRequest(c *gin.Context, obj interface{}) bool {
+	if err := ValidateStructCtx(c.Request.Context(), obj); err != nil {
+		validationErrors := FormatValidationErrors(c.Request.Context(), err)
+		response.ValidationFailed(c, validationErrors)
+		return false
+	}
 	return true
 }
 
-// FormatValidationErrors formats validator errors into a map
-func FormatValidationErrors(err error) map[string]string {
+// FormatValidationErrors formats validator errors into a map from the JSON
+// name of each invalid field to its message, in the locale of ctx
+func FormatValidationErrors(ctx context.Context, err error) map[string]string {
 	errors := make(map[string]string)
 
 	if validationErrors, ok := err.(validator.ValidationErrors); ok {
+		trans := translator(ctx)
 		for _, e := range validationErrors {
-			errors[e.Field()] = formatErrorMessage(e)
+			errors[e.Field()] = translate(trans, e)
 		}
 	}
 
 	return errors
 }
-
-func formatErrorMessage(e validator.FieldError) string {
-	switch e.Tag() {
-	case "required":
-		return "This field is required"
-	case "email":
-		return "Invalid email format"
-	case "min":
-		return "Minimum length is " + e.Param()
-	case "max":
-		return "Maximum length is " + e.Param()
-	case "eqfield":
-		return "Must match " + e.Param()
-	default:
-		return "Invalid value"
-	}
-}
@@ -0,0 +1,34 @@
+package version
+
+import "runtime"
+
+// Build information, set at build time with -ldflags, e.g.
+//
+//	go build -ldflags "-X github.com/firdanbash/go-clean-boiler/pkg/version.Version=v1.2.0" ./cmd/api
+var (
+	Version   = "dev"
+	Commit    = "unknown"
+	BuildTime = "unknown"
+)
+
+// Info describes the running build, for debugging deployed instances
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time"`
+	GoVersion string `json:"go_version"`
+	OS        string `json:"os"`
+	Arch      string `json:"arch"`
+}
+
+// Get returns the build information and the Go runtime it runs on
+func Get() Info {
+	return Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+	}
+}
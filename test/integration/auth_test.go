@@ -0,0 +1,70 @@
+//go:build integration
+
+package integration
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestRegisterLoginLogout(t *testing.T) {
+	password := "secret123"
+	email := register(t, password)
+
+	client(t).Post("/api/v1/auth/register", map[string]string{
+		"email":    email,
+		"password": password,
+		"name":     "Jane Doe",
+	}).AssertError(http.StatusConflict).AssertMessage("email already exists")
+
+	client(t).Post("/api/v1/auth/login", map[string]string{
+		"email":    email,
+		"password": "wrong-password",
+	}).AssertError(http.StatusUnauthorized).AssertMessage("invalid credentials")
+
+	user := login(t, email, password)
+
+	var me struct {
+		Email string `json:"email"`
+		Role  string `json:"role"`
+	}
+	user.Get("/api/v1/users/me").AssertStatus(http.StatusOK).Decode(&me)
+	if me.Email != email || me.Role != "user" {
+		t.Errorf("get me = %+v, want email %s and role user", me, email)
+	}
+
+	user.Post("/api/v1/auth/logout", nil).AssertStatus(http.StatusOK)
+	user.Get("/api/v1/users/me").AssertError(http.StatusUnauthorized)
+}
+
+func TestLoginRememberMe(t *testing.T) {
+	password := "secret123"
+	email := register(t, password)
+
+	type auth struct {
+		ExpiresIn  int64 `json:"expires_in"`
+		RememberMe bool  `json:"remember_me"`
+	}
+	var short, long auth
+	client(t).Post("/api/v1/auth/login", map[string]interface{}{
+		"email":    email,
+		"password": password,
+	}).AssertStatus(http.StatusOK).Decode(&short)
+	client(t).Post("/api/v1/auth/login", map[string]interface{}{
+		"email":       email,
+		"password":    password,
+		"remember_me": true,
+	}).AssertStatus(http.StatusOK).Decode(&long)
+
+	if short.RememberMe || !long.RememberMe {
+		t.Errorf("remember_me = %v and %v, want false and true", short.RememberMe, long.RememberMe)
+	}
+	if long.ExpiresIn <= short.ExpiresIn {
+		t.Errorf("expires_in with remember me = %d, want more than %d", long.ExpiresIn, short.ExpiresIn)
+	}
+}
+
+func TestProtectedRoutesRequireToken(t *testing.T) {
+	client(t).Get("/api/v1/users/me").AssertError(http.StatusUnauthorized)
+	client(t).WithToken("not-a-token").Get("/api/v1/users/me").AssertError(http.StatusUnauthorized)
+}
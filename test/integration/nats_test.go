@@ -0,0 +1,119 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/firdanbash/go-clean-boiler/pkg/config"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"github.com/firdanbash/go-clean-boiler/pkg/messaging"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+const natsImage = "nats:2.10-alpine"
+
+// startNATS runs a NATS server with JetStream for the test and returns its URL
+func startNATS(t *testing.T) string {
+	t.Helper()
+	testcontainers.SkipIfProviderIsNotHealthy(t)
+	ctx := context.Background()
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        natsImage,
+			Cmd:          []string{"-js"},
+			ExposedPorts: []string{"4222/tcp"},
+			WaitingFor:   wait.ForLog("Server is ready"),
+		},
+		Started: true,
+	})
+	if err != nil {
+		t.Fatalf("start nats: %v", err)
+	}
+	t.Cleanup(func() { _ = container.Terminate(context.Background()) })
+
+	host, err := container.Host(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	port, err := container.MappedPort(ctx, "4222/tcp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fmt.Sprintf("nats://%s:%s", host, port.Port())
+}
+
+func TestNATSPublishAndConsume(t *testing.T) {
+	n, err := messaging.NewNATS(config.NATSConfig{
+		URL:             startNATS(t),
+		Stream:          "INTEGRATION",
+		SubjectPrefix:   "integration",
+		Replicas:        1,
+		DuplicateWindow: time.Minute,
+		PublishTimeout:  5 * time.Second,
+		AckWait:         time.Second,
+		MaxDeliver:      2,
+		Prefetch:        5,
+		Consumers: map[string]config.NATSConsumerConfig{
+			"users": {Topics: []string{"user.*"}},
+		},
+	}, logger.Nop())
+	if err != nil {
+		t.Fatalf("NewNATS() error = %v", err)
+	}
+	t.Cleanup(func() { _ = n.Close() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	for _, msg := range []*messaging.Message{
+		{ID: "1", Topic: "user.created", Key: "7", Body: []byte(`{"id":7}`)},
+		{ID: "1", Topic: "user.created", Key: "7", Body: []byte(`{"id":7}`)}, // republished by the relay
+		{ID: "2", Topic: "user.deleted", Key: "8", Body: []byte(`{"id":8}`)},
+		{ID: "3", Topic: "order.created", Body: []byte(`{}`)}, // not a topic of the consumer
+	} {
+		if err := n.Publish(ctx, msg); err != nil {
+			t.Fatalf("Publish(%s) error = %v", msg.ID, err)
+		}
+	}
+
+	// Message 2 always fails, so it is delivered MaxDeliver times
+	handled := make(chan string, 10)
+	go func() {
+		_ = n.Subscribe(ctx, "users", func(_ context.Context, msg *messaging.Message) error {
+			handled <- msg.ID + ":" + msg.Topic + ":" + msg.Key
+			if msg.ID == "2" {
+				return errors.New("cannot handle")
+			}
+			return nil
+		})
+	}()
+
+	seen := map[string]int{}
+	for seen["1:user.created:7"] < 1 || seen["2:user.deleted:8"] < 2 {
+		select {
+		case id := <-handled:
+			seen[id]++
+		case <-ctx.Done():
+			t.Fatalf("handled %v before timing out", seen)
+		}
+	}
+
+	// Give duplicates and extra redeliveries a chance to show up
+	time.Sleep(2 * time.Second)
+	for len(handled) > 0 {
+		seen[<-handled]++
+	}
+	want := map[string]int{"1:user.created:7": 1, "2:user.deleted:8": 2}
+	for id, count := range seen {
+		if want[id] != count {
+			t.Errorf("%s handled %d times, want %d", id, count, want[id])
+		}
+	}
+}
@@ -0,0 +1,122 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/firdanbash/go-clean-boiler/pkg/config"
+	"github.com/firdanbash/go-clean-boiler/pkg/logger"
+	"github.com/firdanbash/go-clean-boiler/pkg/messaging"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+const rabbitMQImage = "rabbitmq:3.13-alpine"
+
+// startRabbitMQ runs a RabbitMQ container for the test and returns its AMQP URL
+func startRabbitMQ(t *testing.T) string {
+	t.Helper()
+	testcontainers.SkipIfProviderIsNotHealthy(t)
+	ctx := context.Background()
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        rabbitMQImage,
+			ExposedPorts: []string{"5672/tcp"},
+			WaitingFor:   wait.ForLog("Server startup complete").WithStartupTimeout(2 * time.Minute),
+		},
+		Started: true,
+	})
+	if err != nil {
+		t.Fatalf("start rabbitmq: %v", err)
+	}
+	t.Cleanup(func() { _ = container.Terminate(context.Background()) })
+
+	host, err := container.Host(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	port, err := container.MappedPort(ctx, "5672/tcp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fmt.Sprintf("amqp://guest:guest@%s:%s/", host, port.Port())
+}
+
+func TestRabbitMQPublishAndConsume(t *testing.T) {
+	rabbit, err := messaging.NewRabbitMQ(config.RabbitMQConfig{
+		URL:                startRabbitMQ(t),
+		Exchange:           "integration.events",
+		ExchangeType:       "topic",
+		DeadLetterExchange: "integration.dead-letter",
+		PublishTimeout:     5 * time.Second,
+		Prefetch:           5,
+		Requeue:            messaging.RequeueOnce,
+		Queues: map[string]config.RabbitMQQueueConfig{
+			"users": {Bindings: []string{"user.*"}, DeadLetter: true},
+		},
+	}, logger.Nop())
+	if err != nil {
+		t.Fatalf("NewRabbitMQ() error = %v", err)
+	}
+	t.Cleanup(func() { _ = rabbit.Close() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	for _, msg := range []*messaging.Message{
+		{ID: "1", Topic: "user.created", Key: "7", Body: []byte(`{"id":7}`)},
+		{ID: "2", Topic: "user.deleted", Key: "8", Body: []byte(`{"id":8}`)},
+		{ID: "3", Topic: "order.created", Body: []byte(`{}`)}, // matches no binding
+	} {
+		if err := rabbit.Publish(ctx, msg); err != nil {
+			t.Fatalf("Publish(%s) error = %v", msg.ID, err)
+		}
+	}
+
+	// Message 2 always fails: it is requeued once, then dead-lettered
+	handled := make(chan string, 10)
+	go func() {
+		_ = rabbit.Subscribe(ctx, "users", func(_ context.Context, msg *messaging.Message) error {
+			handled <- msg.ID + ":" + msg.Key
+			if msg.ID == "2" {
+				return errors.New("cannot handle")
+			}
+			return nil
+		})
+	}()
+	dead := make(chan string, 10)
+	go func() {
+		_ = rabbit.Subscribe(ctx, "users.dead", func(_ context.Context, msg *messaging.Message) error {
+			dead <- msg.ID
+			return nil
+		})
+	}()
+
+	seen := map[string]int{}
+	for len(seen) < 2 || seen["2:8"] < 2 {
+		select {
+		case id := <-handled:
+			seen[id]++
+		case <-ctx.Done():
+			t.Fatalf("handled %v before timing out, want 1:7 once and 2:8 twice", seen)
+		}
+	}
+	if seen["1:7"] != 1 {
+		t.Errorf("message 1 handled %d times, want 1", seen["1:7"])
+	}
+
+	select {
+	case id := <-dead:
+		if id != "2" {
+			t.Errorf("dead-lettered message %s, want 2", id)
+		}
+	case <-ctx.Done():
+		t.Fatal("rejected message was not dead-lettered")
+	}
+}
@@ -0,0 +1,65 @@
+//go:build integration
+
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+type user struct {
+	ID    uint   `json:"id"`
+	Email string `json:"email"`
+	Name  string `json:"name"`
+	Role  string `json:"role"`
+}
+
+func TestUserCRUD(t *testing.T) {
+	admin := login(t, adminEmail, adminPassword)
+	email := uniqueEmail("crud")
+
+	// Create
+	var created user
+	admin.Post("/api/v1/users", map[string]string{
+		"email":    email,
+		"password": "secret123",
+		"name":     "Created User",
+	}).AssertStatus(http.StatusCreated).Decode(&created)
+	if created.ID == 0 || created.Email != email {
+		t.Fatalf("create = %+v, want an ID and email %s", created, email)
+	}
+	path := fmt.Sprintf("/api/v1/users/%d", created.ID)
+
+	// Read
+	admin.Get(path).AssertStatus(http.StatusOK)
+
+	// List
+	var listed []user
+	admin.Get("/api/v1/users?email=" + email).AssertStatus(http.StatusOK).AssertTotal(1).Decode(&listed)
+	if len(listed) != 1 || listed[0].ID != created.ID {
+		t.Errorf("list by email = %+v, want only user %d", listed, created.ID)
+	}
+
+	// Update
+	var updated user
+	admin.Put(path, map[string]string{"name": "Renamed User"}).AssertStatus(http.StatusOK).Decode(&updated)
+	if updated.Name != "Renamed User" || updated.Email != email {
+		t.Errorf("update = %+v, want the new name and the same email", updated)
+	}
+
+	// Delete
+	admin.Delete(path).AssertStatus(http.StatusOK)
+	admin.Get(path).AssertError(http.StatusNotFound)
+}
+
+func TestUserAdminRoutesRejectUsers(t *testing.T) {
+	member := login(t, register(t, "secret123"), "secret123")
+
+	member.Get("/api/v1/users").AssertError(http.StatusForbidden)
+	member.Post("/api/v1/users", map[string]string{
+		"email":    "blocked@example.com",
+		"password": "secret123",
+		"name":     "Blocked",
+	}).AssertError(http.StatusForbidden)
+}